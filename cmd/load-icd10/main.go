@@ -0,0 +1,53 @@
+// Command load-icd10 loads an ICD-10 code table into diagnosis_codes. It
+// accepts the CMS order file layout (code, whitespace, description) or a
+// code,description CSV, so the full CMS release can replace the starter set
+// in data/. Codes already loaded get their descriptions refreshed.
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+
+	"github.com/joho/godotenv"
+
+	"healthcare-portal/internal/config"
+	"healthcare-portal/internal/database"
+	"healthcare-portal/internal/repository"
+	"healthcare-portal/internal/services"
+)
+
+func main() {
+	if err := godotenv.Load(); err != nil {
+		log.Println("No .env file found")
+	}
+	cfg := config.Load()
+
+	path := flag.String("file", cfg.Clinical.DiagnosisCodesFile, "code table to load")
+	dryRun := flag.Bool("dry-run", false, "parse the file without saving it")
+	flag.Parse()
+
+	file, err := os.Open(*path)
+	if err != nil {
+		log.Fatal("Failed to open code table:", err)
+	}
+	defer file.Close()
+
+	codes, err := services.ParseDiagnosisCodes(file)
+	if err != nil {
+		log.Fatal("Failed to parse code table:", err)
+	}
+	if *dryRun {
+		log.Printf("Parsed %d codes from %s", len(codes), *path)
+		return
+	}
+
+	database.Initialize()
+	codeService := services.NewDiagnosisCodeService(repository.NewDiagnosisCodeRepository(database.GetDB()))
+
+	loaded, err := codeService.ImportCodes(codes)
+	if err != nil {
+		log.Fatal("Failed to load codes:", err)
+	}
+	log.Printf("Loaded %d codes from %s", loaded, *path)
+}
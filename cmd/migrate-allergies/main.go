@@ -0,0 +1,73 @@
+// Command migrate-allergies parses the free-text Patient.Allergies column of
+// every patient into structured allergy entries. The entries are created as
+// unconfirmed candidates for staff to confirm or delete; running the command
+// again skips substances a patient already has on file.
+package main
+
+import (
+	"flag"
+	"log"
+
+	"github.com/joho/godotenv"
+
+	"healthcare-portal/internal/database"
+	"healthcare-portal/internal/repository"
+	"healthcare-portal/internal/services"
+)
+
+func main() {
+	dryRun := flag.Bool("dry-run", false, "print the parsed candidates without saving them")
+	batchSize := flag.Int("batch", 100, "patients loaded per batch")
+	flag.Parse()
+
+	if err := godotenv.Load(); err != nil {
+		log.Println("No .env file found")
+	}
+
+	database.Initialize()
+	db := database.GetDB()
+
+	tenants, err := repository.NewTenantRepository(db).FindAll()
+	if err != nil {
+		log.Fatal("Failed to load tenants:", err)
+	}
+	patientRepo := repository.NewPatientRepository(db)
+	allergyService := services.NewAllergyService(repository.NewAllergyRepository(db), patientRepo)
+
+	var patientsSeen, created int
+	for _, tenant := range tenants {
+		tenantPatients := patientRepo.WithTenant(tenant.ID)
+		tenantAllergies := allergyService.WithTenant(tenant.ID)
+		for offset := 0; ; offset += *batchSize {
+			patients, _, err := tenantPatients.FindAll(*batchSize, offset)
+			if err != nil {
+				log.Fatal("Failed to load patients:", err)
+			}
+			if len(patients) == 0 {
+				break
+			}
+
+			for _, patient := range patients {
+				patientsSeen++
+				if *dryRun {
+					for _, candidate := range services.ParseLegacyAllergies(patient.Allergies) {
+						log.Printf("patient %d: %q -> %s (%s, reaction %q, severity %s)",
+							patient.ID, patient.Allergies, candidate.Substance, candidate.Category, candidate.Reaction, candidate.Severity)
+					}
+					continue
+				}
+
+				// Recorded by 0: the entries were created by this migration,
+				// not by a member of staff.
+				imported, err := tenantAllergies.ImportLegacyAllergies(patient.ID, 0)
+				if err != nil {
+					log.Printf("patient %d: %v", patient.ID, err)
+					continue
+				}
+				created += len(imported)
+			}
+		}
+	}
+
+	log.Printf("Processed %d patients, created %d unconfirmed allergy entries", patientsSeen, created)
+}
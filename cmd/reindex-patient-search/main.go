@@ -0,0 +1,56 @@
+// Command reindex-patient-search rebuilds the patient search index from the
+// patients table. Patients are indexed as they are saved, so it is needed
+// once for patients registered before the index existed, and after changes
+// to how patients are tokenised.
+package main
+
+import (
+	"flag"
+	"log"
+
+	"github.com/joho/godotenv"
+
+	"healthcare-portal/internal/database"
+	"healthcare-portal/internal/repository"
+)
+
+func main() {
+	batchSize := flag.Int("batch", 200, "patients loaded per batch")
+	flag.Parse()
+
+	if err := godotenv.Load(); err != nil {
+		log.Println("No .env file found")
+	}
+
+	database.Initialize()
+	db := database.GetDB()
+	tenants, err := repository.NewTenantRepository(db).FindAll()
+	if err != nil {
+		log.Fatal("Failed to load tenants:", err)
+	}
+
+	var indexed, failed int
+	for _, tenant := range tenants {
+		patientRepo := repository.NewPatientRepository(db).WithTenant(tenant.ID)
+		for offset := 0; ; offset += *batchSize {
+			patients, _, err := patientRepo.FindAll(*batchSize, offset)
+			if err != nil {
+				log.Fatal("Failed to load patients:", err)
+			}
+			if len(patients) == 0 {
+				break
+			}
+
+			for i := range patients {
+				if err := patientRepo.ReindexSearch(&patients[i]); err != nil {
+					log.Printf("patient %d: %v", patients[i].ID, err)
+					failed++
+					continue
+				}
+				indexed++
+			}
+		}
+	}
+
+	log.Printf("Indexed %d patients, %d failed", indexed, failed)
+}
@@ -53,6 +53,12 @@ func main() {
             Name:     "Dr. Robert Brown",
             Role:     models.RoleDoctor,
         },
+        {
+            Email:    "admin@healthcare.com",
+            Password: "admin12345",
+            Name:     "Clinic Administrator",
+            Role:     models.RoleAdmin,
+        },
     }
 
     // Create users
@@ -159,6 +165,46 @@ func main() {
         }
     }
 
+    // Create the default appointment type catalog
+    log.Println("\nCreating appointment types...")
+    appointmentTypes := []models.AppointmentType{
+        {Code: "new-consultation", Name: "New Consultation", DurationMinutes: 30, PriceCents: 15000, ProcedureCode: "99203", IsActive: true},
+        {Code: "follow-up", Name: "Follow-up", DurationMinutes: 15, PriceCents: 8000, ProcedureCode: "99213", IsActive: true},
+        {
+            Code:                    "vaccination",
+            Name:                    "Vaccination",
+            DurationMinutes:         15,
+            PriceCents:              5000,
+            ProcedureCode:           "90471",
+            PreparationInstructions: "Bring your vaccination record.",
+            IsActive:                true,
+        },
+        {
+            Code:                    "procedure",
+            Name:                    "Minor Procedure",
+            DurationMinutes:         60,
+            PriceCents:              40000,
+            PreparationInstructions: "Do not eat or drink for 6 hours before the procedure.",
+            IsActive:                true,
+        },
+    }
+
+    for _, appointmentType := range appointmentTypes {
+        var existingType models.AppointmentType
+        result := db.Where("code = ?", appointmentType.Code).First(&existingType)
+
+        if result.Error == nil {
+            log.Printf("Appointment type %s already exists, skipping...", appointmentType.Code)
+            continue
+        }
+
+        if err := db.Create(&appointmentType).Error; err != nil {
+            log.Printf("Failed to create appointment type %s: %v", appointmentType.Code, err)
+        } else {
+            log.Printf("✓ Created appointment type: %s", appointmentType.Name)
+        }
+    }
+
     log.Println("\n=== Seeding completed successfully! ===")
     log.Println("\nYou can now login with these credentials:")
     log.Println("\nReceptionist Accounts:")
@@ -173,4 +219,7 @@ func main() {
     log.Println("")
     log.Println("  Email: senior.doctor@healthcare.com")
     log.Println("  Password: senior123")
+    log.Println("\nAdmin Account:")
+    log.Println("  Email: admin@healthcare.com")
+    log.Println("  Password: admin12345")
 }
\ No newline at end of file
@@ -1,12 +1,14 @@
 package main
 
 import (
+	"context"
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 	"log"
 	"os"
+	"time"
 
 	_ "healthcare-portal/docs"
 	"healthcare-portal/internal/config"
@@ -15,6 +17,7 @@ import (
 	"healthcare-portal/internal/middleware"
 	"healthcare-portal/internal/repository"
 	"healthcare-portal/internal/services"
+	"healthcare-portal/internal/storage"
 )
 
 // @title Healthcare Portal API
@@ -52,22 +55,113 @@ func main() {
 	db := database.GetDB()
 
 	// Initialize repositories
+	tenantRepo := repository.NewTenantRepository(db)
 	userRepo := repository.NewUserRepository(db)
 	patientRepo := repository.NewPatientRepository(db)
+	patientIdentifierRepo := repository.NewPatientIdentifierRepository(db)
+	relatedPersonRepo := repository.NewRelatedPersonRepository(db)
 	appointmentRepo := repository.NewAppointmentRepository(db)
+	resourceRepo := repository.NewResourceRepository(db)
+	appointmentTypeRepo := repository.NewAppointmentTypeRepository(db)
+	locationRepo := repository.NewLocationRepository(db)
+	scheduleRepo := repository.NewDoctorScheduleRepository(db)
+	encounterRepo := repository.NewEncounterRepository(db)
+	allergyRepo := repository.NewAllergyRepository(db)
+	medicationRepo := repository.NewMedicationRepository(db)
+	vitalsRepo := repository.NewVitalsRepository(db)
+	problemRepo := repository.NewProblemRepository(db)
+	diagnosisCodeRepo := repository.NewDiagnosisCodeRepository(db)
+	labRepo := repository.NewLabRepository(db)
+	immunizationRepo := repository.NewImmunizationRepository(db)
+	documentRepo := repository.NewDocumentRepository(db)
+	referralRepo := repository.NewReferralRepository(db)
+	carePlanRepo := repository.NewCarePlanRepository(db)
+	consentRepo := repository.NewConsentRepository(db)
+	insuranceRepo := repository.NewInsuranceRepository(db)
+	billingRepo := repository.NewBillingRepository(db)
+	claimRepo := repository.NewClaimRepository(db)
 
 	// Initialize services
-	authService := services.NewAuthService(userRepo)
-	patientService := services.NewPatientService(patientRepo)
-	appointmentService := services.NewAppointmentService(appointmentRepo, patientRepo, userRepo)
+	// Users sign in by email before their practice is known; every other
+	// service is scoped to the caller's tenant per request.
+	authService := services.NewAuthService(userRepo.AllTenants())
+	mrnGenerator := services.NewMRNGenerator(patientIdentifierRepo, locationRepo, cfg.MRN)
+	patientService := services.NewPatientService(patientRepo, patientIdentifierRepo, relatedPersonRepo, mrnGenerator)
+	relatedPersonService := services.NewRelatedPersonService(relatedPersonRepo, patientRepo)
+	billingService := services.NewBillingService(billingRepo, patientRepo, cfg.Billing)
+	appointmentService := services.NewAppointmentService(appointmentRepo, patientRepo, userRepo, resourceRepo, appointmentTypeRepo, locationRepo, scheduleRepo, cfg.Scheduling, billingService)
+	resourceService := services.NewResourceService(resourceRepo)
+	appointmentTypeService := services.NewAppointmentTypeService(appointmentTypeRepo, appointmentRepo)
+	locationService := services.NewLocationService(locationRepo, scheduleRepo, userRepo)
+	encounterService := services.NewEncounterService(encounterRepo, appointmentRepo, patientRepo)
+	allergyService := services.NewAllergyService(allergyRepo, patientRepo)
+	interactionRules, err := services.LoadInteractionRules(cfg.Clinical.InteractionRulesFile)
+	if err != nil {
+		log.Fatal("Failed to load interaction rules:", err)
+	}
+	medicationService := services.NewMedicationService(medicationRepo, patientRepo, encounterRepo, allergyRepo, tenantRepo, services.NewInteractionChecker(interactionRules))
+	vitalsService := services.NewVitalsService(vitalsRepo, patientRepo, appointmentRepo)
+	problemService := services.NewProblemService(problemRepo, patientRepo, appointmentRepo, encounterRepo, diagnosisCodeRepo)
+	diagnosisCodeService := services.NewDiagnosisCodeService(diagnosisCodeRepo)
+	labService := services.NewLabService(labRepo, patientRepo, encounterRepo)
+	immunizationSchedule, err := services.LoadImmunizationSchedule(cfg.Clinical.ImmunizationScheduleFile)
+	if err != nil {
+		log.Fatal("Failed to load immunization schedule:", err)
+	}
+	immunizationService := services.NewImmunizationService(immunizationRepo, patientRepo, immunizationSchedule)
+	documentStore, err := storage.NewLocalStore(cfg.Documents.StorageDir)
+	if err != nil {
+		log.Fatal("Failed to open document storage:", err)
+	}
+	maxUploadBytes := int64(cfg.Documents.MaxUploadMB) << 20
+	documentService := services.NewDocumentService(documentRepo, patientRepo, documentStore, maxUploadBytes)
+	referralService := services.NewReferralService(referralRepo, patientRepo, userRepo, documentRepo, appointmentService)
+	carePlanService := services.NewCarePlanService(carePlanRepo, patientRepo, userRepo, problemRepo, appointmentService)
+	consentService := services.NewConsentService(consentRepo, patientRepo, relatedPersonRepo)
+	insuranceService := services.NewInsuranceService(insuranceRepo, patientRepo, appointmentRepo, services.LocalEligibilityChecker{})
+	claimService := services.NewClaimService(claimRepo, appointmentRepo, problemRepo, insuranceRepo, billingRepo, cfg.Claims)
+	branding, err := services.LoadBranding(cfg.Branding)
+	if err != nil {
+		log.Fatal("Failed to load clinic branding:", err)
+	}
+	printoutService := services.NewPrintoutService(billingService, patientRepo, appointmentRepo, problemRepo, vitalsRepo, medicationRepo, allergyRepo, tenantRepo, branding)
+	exportService := services.NewExportService(consentService, patientRepo, problemRepo, immunizationRepo, cfg.Exports.PseudonymKey)
+
+	// Background workers sweep every tenant
+	careTaskWorker := services.NewCareTaskWorker(carePlanService.AllTenants(), time.Duration(cfg.Workers.CareTaskSweepMinutes)*time.Minute)
+	go careTaskWorker.Run(context.Background())
+	reminderWorker := services.NewAppointmentReminderWorker(appointmentRepo.AllTenants(), consentService.AllTenants(), services.LogNotifier{}, time.Duration(cfg.Workers.ReminderSweepMinutes)*time.Minute)
+	go reminderWorker.Run(context.Background())
 
 	// Initialize handlers - Now using services instead of repositories
-	authHandler := handlers.NewAuthHandler(authService)
-	patientHandler := handlers.NewPatientHandler(patientService)
-	appointmentHandler := handlers.NewAppointmentHandler(appointmentService)
+	h := routeHandlers{
+		auth:            handlers.NewAuthHandler(authService),
+		patient:         handlers.NewPatientHandler(patientService),
+		relatedPerson:   handlers.NewRelatedPersonHandler(relatedPersonService),
+		appointment:     handlers.NewAppointmentHandler(appointmentService),
+		resource:        handlers.NewResourceHandler(resourceService),
+		appointmentType: handlers.NewAppointmentTypeHandler(appointmentTypeService),
+		location:        handlers.NewLocationHandler(locationService),
+		encounter:       handlers.NewEncounterHandler(encounterService),
+		allergy:         handlers.NewAllergyHandler(allergyService),
+		medication:      handlers.NewMedicationHandler(medicationService),
+		vitals:          handlers.NewVitalsHandler(vitalsService),
+		problem:         handlers.NewProblemHandler(problemService, diagnosisCodeService),
+		lab:             handlers.NewLabHandler(labService),
+		immunization:    handlers.NewImmunizationHandler(immunizationService),
+		document:        handlers.NewDocumentHandler(documentService, maxUploadBytes),
+		referral:        handlers.NewReferralHandler(referralService),
+		carePlan:        handlers.NewCarePlanHandler(carePlanService),
+		consent:         handlers.NewConsentHandler(consentService),
+		export:          handlers.NewExportHandler(exportService),
+		insurance:       handlers.NewInsuranceHandler(insuranceService),
+		billing:         handlers.NewBillingHandler(billingService),
+		claim:           handlers.NewClaimHandler(claimService),
+		printout:        handlers.NewPrintoutHandler(printoutService),
+	}
 
 	// Setup router
-	router := setupRouter(authHandler, patientHandler, appointmentHandler)
+	router := setupRouter(h)
 
 	// Start server
 	port := os.Getenv("PORT")
@@ -82,7 +176,34 @@ func main() {
 	router.Run(":" + port) // Start the server on the specified port
 }
 
-func setupRouter(authHandler *handlers.AuthHandler, patientHandler *handlers.PatientHandler, appointmentHandler *handlers.AppointmentHandler) *gin.Engine {
+// routeHandlers groups the HTTP handlers wired into the router.
+type routeHandlers struct {
+	auth            *handlers.AuthHandler
+	patient         *handlers.PatientHandler
+	relatedPerson   *handlers.RelatedPersonHandler
+	appointment     *handlers.AppointmentHandler
+	resource        *handlers.ResourceHandler
+	appointmentType *handlers.AppointmentTypeHandler
+	location        *handlers.LocationHandler
+	encounter       *handlers.EncounterHandler
+	allergy         *handlers.AllergyHandler
+	medication      *handlers.MedicationHandler
+	vitals          *handlers.VitalsHandler
+	problem         *handlers.ProblemHandler
+	lab             *handlers.LabHandler
+	immunization    *handlers.ImmunizationHandler
+	document        *handlers.DocumentHandler
+	referral        *handlers.ReferralHandler
+	carePlan        *handlers.CarePlanHandler
+	consent         *handlers.ConsentHandler
+	export          *handlers.ExportHandler
+	insurance       *handlers.InsuranceHandler
+	billing         *handlers.BillingHandler
+	claim           *handlers.ClaimHandler
+	printout        *handlers.PrintoutHandler
+}
+
+func setupRouter(h routeHandlers) *gin.Engine {
 	router := gin.Default()
 
 	// Middleware
@@ -111,43 +232,424 @@ func setupRouter(authHandler *handlers.AuthHandler, patientHandler *handlers.Pat
 		// Auth routes
 		auth := api.Group("/auth")
 		{
-			auth.POST("/login", authHandler.Login)
-			auth.POST("/register", authHandler.Register)
-			auth.GET("/me", middleware.AuthMiddleware(), authHandler.GetCurrentUser)
+			auth.POST("/login", h.auth.Login)
+			// Accounts are created by a practice's admin, in the admin's own
+			// practice.
+			auth.POST("/register", middleware.AuthMiddleware(), middleware.RoleMiddleware("admin"), h.auth.Register)
+			auth.GET("/me", middleware.AuthMiddleware(), h.auth.GetCurrentUser)
 		}
 
 		// Patient routes
 		patients := api.Group("/patients")
 		patients.Use(middleware.AuthMiddleware())
 		{
-			patients.GET("", patientHandler.GetAllPatients)
-			patients.GET("/search", patientHandler.SearchPatients)
-			patients.GET("/:id", patientHandler.GetPatientByID)
+			patients.GET("", h.patient.GetAllPatients)
+			patients.GET("/search", h.patient.SearchPatients)
+			patients.GET("/lookup", h.patient.LookupPatients)
+			patients.GET("/:id", h.patient.GetPatientByID)
 
 			// Receptionist only routes
-			patients.POST("", middleware.RoleMiddleware("receptionist"), patientHandler.CreatePatient)
-			patients.DELETE("/:id", middleware.RoleMiddleware("receptionist"), patientHandler.DeletePatient)
+			patients.POST("", middleware.RoleMiddleware("receptionist"), h.patient.CreatePatient)
+			patients.DELETE("/:id", middleware.RoleMiddleware("receptionist"), h.patient.DeletePatient)
 
 			// Both receptionist and doctor can update
-			patients.PUT("/:id", middleware.RoleMiddleware("receptionist", "doctor"), patientHandler.UpdatePatient)
+			patients.PUT("/:id", middleware.RoleMiddleware("receptionist", "doctor"), h.patient.UpdatePatient)
+
+			// Duplicate detection and merging
+			patients.GET("/:id/duplicates", h.patient.GetDuplicates)
+			patients.GET("/:id/merges", h.patient.GetPatientMerges)
+			patients.POST("/:id/merge", middleware.RoleMiddleware("admin"), h.patient.MergePatient)
+
+			// External identifiers
+			patients.GET("/:id/identifiers", h.patient.GetIdentifiers)
+			patients.POST("/:id/identifiers", middleware.RoleMiddleware("receptionist", "admin"), h.patient.AddIdentifier)
+			patients.DELETE("/:id/identifiers/:identifierId", middleware.RoleMiddleware("receptionist", "admin"), h.patient.RemoveIdentifier)
+
+			// Next of kin and guardians
+			patients.GET("/:id/related-persons", h.relatedPerson.GetPatientRelatedPersons)
+			patients.POST("/:id/related-persons", middleware.RoleMiddleware("receptionist", "nurse", "doctor"), h.relatedPerson.CreateRelatedPerson)
+			patients.PUT("/:id/related-persons/:personId", middleware.RoleMiddleware("receptionist", "nurse", "doctor"), h.relatedPerson.UpdateRelatedPerson)
+			patients.DELETE("/:id/related-persons/:personId", middleware.RoleMiddleware("receptionist", "nurse", "doctor"), h.relatedPerson.DeleteRelatedPerson)
+
+			// Consents
+			patients.GET("/:id/consents", h.consent.GetPatientConsents)
+			patients.GET("/:id/consents/:consentId", h.consent.GetConsentByID)
+			patients.POST("/:id/consents", middleware.RoleMiddleware("receptionist", "nurse", "doctor"), h.consent.GrantConsent)
+			patients.POST("/:id/consents/:consentId/revoke", middleware.RoleMiddleware("receptionist", "nurse", "doctor"), h.consent.RevokeConsent)
+
+			// Insurance coverage
+			patients.GET("/:id/coverages", h.insurance.GetPatientCoverages)
+			patients.GET("/:id/coverages/:coverageId", h.insurance.GetCoverageByID)
+			patients.POST("/:id/coverages", middleware.RoleMiddleware("receptionist", "admin"), h.insurance.AddCoverage)
+			patients.PUT("/:id/coverages/:coverageId", middleware.RoleMiddleware("receptionist", "admin"), h.insurance.UpdateCoverage)
+			patients.DELETE("/:id/coverages/:coverageId", middleware.RoleMiddleware("receptionist", "admin"), h.insurance.DeleteCoverage)
+
+			// Billing - charges, invoices and the account balance
+			patients.GET("/:id/charges", middleware.RoleMiddleware("receptionist", "admin"), h.billing.GetPatientCharges)
+			patients.POST("/:id/charges", middleware.RoleMiddleware("receptionist", "admin"), h.billing.CreateCharge)
+			patients.GET("/:id/invoices", middleware.RoleMiddleware("receptionist", "admin"), h.billing.GetPatientInvoices)
+			patients.POST("/:id/invoices", middleware.RoleMiddleware("receptionist", "admin"), h.billing.CreateInvoice)
+			patients.GET("/:id/balance", middleware.RoleMiddleware("receptionist", "admin"), h.billing.GetPatientBalance)
+			patients.GET("/:id/claims", middleware.RoleMiddleware("receptionist", "admin"), h.claim.GetPatientClaims)
+
+			// Allergy list
+			patients.GET("/:id/allergies", h.allergy.GetPatientAllergies)
+			patients.POST("/:id/allergies", middleware.RoleMiddleware("receptionist", "doctor"), h.allergy.CreateAllergy)
+			patients.POST("/:id/allergies/import", middleware.RoleMiddleware("receptionist", "doctor"), h.allergy.ImportLegacyAllergies)
+
+			// Medication list and prescriptions
+			patients.GET("/:id/medications", h.medication.GetPatientMedications)
+			patients.POST("/:id/medications", middleware.RoleMiddleware("receptionist", "doctor"), h.medication.CreateStatement)
+			patients.GET("/:id/prescriptions", h.medication.GetPatientPrescriptions)
+
+			// Vital signs
+			patients.GET("/:id/vitals", h.vitals.GetPatientVitals)
+			patients.GET("/:id/vitals/series/:measurement", h.vitals.GetVitalsSeries)
+			patients.POST("/:id/vitals", middleware.RoleMiddleware("nurse", "doctor"), h.vitals.RecordVitals)
+
+			// Problem list
+			patients.GET("/:id/problems", h.problem.GetPatientProblems)
+			patients.POST("/:id/problems", middleware.RoleMiddleware("doctor"), h.problem.CreateProblem)
+
+			// Lab orders and results
+			patients.GET("/:id/lab-orders", h.lab.GetPatientOrders)
+
+			// Immunizations and due vaccines
+			patients.GET("/:id/immunizations", h.immunization.GetPatientImmunizations)
+			patients.GET("/:id/immunizations/forecast", h.immunization.GetPatientForecast)
+			patients.POST("/:id/immunizations", middleware.RoleMiddleware("nurse", "doctor"), h.immunization.RecordImmunization)
+
+			// Documents follow the patient's own permissions: anyone who can
+			// view the patient can read them, and those who can update the
+			// patient can attach them
+			patients.GET("/:id/documents", h.document.GetPatientDocuments)
+			patients.POST("/:id/documents", middleware.RoleMiddleware("receptionist", "doctor"), h.document.UploadDocument)
+
+			// Referrals
+			patients.GET("/:id/referrals", h.referral.GetPatientReferrals)
+
+			// Care plans
+			patients.GET("/:id/care-plans", h.carePlan.GetPatientCarePlans)
+			patients.POST("/:id/care-plans", middleware.RoleMiddleware("nurse", "doctor"), h.carePlan.CreateCarePlan)
+		}
+
+		// Allergy routes
+		allergies := api.Group("/allergies")
+		allergies.Use(middleware.AuthMiddleware(), middleware.RoleMiddleware("receptionist", "doctor"))
+		{
+			allergies.PUT("/:id", h.allergy.UpdateAllergy)
+			allergies.POST("/:id/confirm", h.allergy.ConfirmAllergy)
+			allergies.DELETE("/:id", h.allergy.DeleteAllergy)
+		}
+
+		// Medication routes
+		medications := api.Group("/medications")
+		medications.Use(middleware.AuthMiddleware(), middleware.RoleMiddleware("receptionist", "doctor"))
+		{
+			medications.PUT("/:id", h.medication.UpdateStatement)
+			medications.POST("/:id/stop", h.medication.StopStatement)
+			medications.DELETE("/:id", h.medication.DeleteStatement)
+		}
+
+		// Prescription routes
+		prescriptions := api.Group("/prescriptions")
+		prescriptions.Use(middleware.AuthMiddleware())
+		{
+			prescriptions.GET("/:id", h.medication.GetPrescriptionByID)
+			prescriptions.GET("/:id/print", h.medication.PrintPrescription)
+			prescriptions.POST("/:id/refill", middleware.RoleMiddleware("receptionist", "doctor"), h.medication.RefillPrescription)
+
+			// Doctor only routes
+			prescriptions.POST("/check", middleware.RoleMiddleware("doctor"), h.medication.CheckPrescription)
+			prescriptions.POST("", middleware.RoleMiddleware("doctor"), h.medication.CreatePrescription)
+			prescriptions.POST("/:id/cancel", middleware.RoleMiddleware("doctor"), h.medication.CancelPrescription)
 		}
 
 		// Appointment routes
 		appointments := api.Group("/appointments")
 		appointments.Use(middleware.AuthMiddleware())
 		{
-			appointments.GET("", appointmentHandler.GetAllAppointments)
-			appointments.GET("/date", appointmentHandler.GetAppointmentsByDate)
-			appointments.GET("/:id", appointmentHandler.GetAppointmentByID)
-			appointments.GET("/patient/:patientId", appointmentHandler.GetPatientAppointments)
-			appointments.GET("/doctor/:doctorId", appointmentHandler.GetDoctorAppointments)
+			appointments.GET("", h.appointment.GetAllAppointments)
+			appointments.GET("/date", h.appointment.GetAppointmentsByDate)
+			appointments.GET("/availability", h.appointment.GetAvailableSlots)
+			appointments.GET("/:id", h.appointment.GetAppointmentByID)
+			appointments.GET("/patient/:patientId", h.appointment.GetPatientAppointments)
+			appointments.GET("/doctor/:doctorId", h.appointment.GetDoctorAppointments)
 
 			// Receptionist only routes
-			appointments.POST("", middleware.RoleMiddleware("receptionist"), appointmentHandler.CreateAppointment)
-			appointments.DELETE("/:id", middleware.RoleMiddleware("receptionist"), appointmentHandler.DeleteAppointment)
+			appointments.POST("", middleware.RoleMiddleware("receptionist"), h.appointment.CreateAppointment)
+			appointments.DELETE("/:id", middleware.RoleMiddleware("receptionist"), h.appointment.DeleteAppointment)
 
 			// Both can update status
-			appointments.PATCH("/:id/status", middleware.RoleMiddleware("receptionist", "doctor"), appointmentHandler.UpdateAppointmentStatus)
+			appointments.PATCH("/:id/status", middleware.RoleMiddleware("receptionist", "doctor"), h.appointment.UpdateAppointmentStatus)
+
+			// Check-in and vitals before the doctor sees the patient
+			appointments.POST("/:id/check-in", middleware.RoleMiddleware("receptionist", "nurse"), h.appointment.CheckInAppointment)
+			appointments.GET("/:id/vitals", h.vitals.GetAppointmentVitals)
+			appointments.POST("/:id/vitals", middleware.RoleMiddleware("nurse", "doctor"), h.vitals.RecordVisitVitals)
+
+			// Diagnoses for completed visits
+			appointments.GET("/:id/diagnoses", h.problem.GetAppointmentDiagnoses)
+			appointments.PUT("/:id/diagnoses", middleware.RoleMiddleware("doctor"), h.problem.SetAppointmentDiagnoses)
+
+			// Insurance eligibility before the visit
+			appointments.GET("/:id/eligibility", h.insurance.GetAppointmentEligibility)
+			appointments.POST("/:id/eligibility", middleware.RoleMiddleware("receptionist", "admin"), h.insurance.CheckAppointmentEligibility)
+
+			// Insurance claim for a completed visit
+			appointments.POST("/:id/claim", middleware.RoleMiddleware("receptionist", "admin"), h.claim.GenerateClaim)
+
+			// Printable confirmation letter and after-visit summary
+			appointments.GET("/:id/confirmation", h.printout.AppointmentConfirmationPDF)
+			appointments.GET("/:id/visit-summary", h.printout.VisitSummaryPDF)
+		}
+
+		// Resource routes
+		resources := api.Group("/resources")
+		resources.Use(middleware.AuthMiddleware())
+		{
+			resources.GET("", h.resource.GetAllResources)
+			resources.GET("/:id", h.resource.GetResourceByID)
+
+			// Receptionist only routes
+			resources.POST("", middleware.RoleMiddleware("receptionist"), h.resource.CreateResource)
+			resources.PUT("/:id", middleware.RoleMiddleware("receptionist"), h.resource.UpdateResource)
+			resources.DELETE("/:id", middleware.RoleMiddleware("receptionist"), h.resource.DeleteResource)
+		}
+
+		// Appointment type routes
+		appointmentTypes := api.Group("/appointment-types")
+		appointmentTypes.Use(middleware.AuthMiddleware())
+		{
+			appointmentTypes.GET("", h.appointmentType.GetAllAppointmentTypes)
+			appointmentTypes.GET("/:id", h.appointmentType.GetAppointmentTypeByID)
+
+			// Admin only routes
+			appointmentTypes.GET("/report", middleware.RoleMiddleware("admin"), h.appointmentType.GetReport)
+			appointmentTypes.POST("", middleware.RoleMiddleware("admin"), h.appointmentType.CreateAppointmentType)
+			appointmentTypes.PUT("/:id", middleware.RoleMiddleware("admin"), h.appointmentType.UpdateAppointmentType)
+			appointmentTypes.DELETE("/:id", middleware.RoleMiddleware("admin"), h.appointmentType.DeleteAppointmentType)
+		}
+
+		// Location routes
+		locations := api.Group("/locations")
+		locations.Use(middleware.AuthMiddleware())
+		{
+			locations.GET("", h.location.GetAllLocations)
+			locations.GET("/:id", h.location.GetLocationByID)
+
+			// Admin only routes
+			locations.POST("", middleware.RoleMiddleware("admin"), h.location.CreateLocation)
+			locations.PUT("/:id", middleware.RoleMiddleware("admin"), h.location.UpdateLocation)
+			locations.DELETE("/:id", middleware.RoleMiddleware("admin"), h.location.DeleteLocation)
+		}
+
+		// Doctor schedule routes
+		schedules := api.Group("/schedules")
+		schedules.Use(middleware.AuthMiddleware())
+		{
+			schedules.GET("", h.location.GetSchedules)
+
+			// Admin only routes
+			schedules.POST("", middleware.RoleMiddleware("admin"), h.location.CreateSchedule)
+			schedules.DELETE("/:id", middleware.RoleMiddleware("admin"), h.location.DeleteSchedule)
+		}
+
+		// Vital signs routes
+		vitals := api.Group("/vitals")
+		vitals.Use(middleware.AuthMiddleware())
+		{
+			vitals.GET("/:id", h.vitals.GetVitalsByID)
+			vitals.DELETE("/:id", middleware.RoleMiddleware("nurse", "doctor"), h.vitals.DeleteVitals)
+		}
+
+		// Problem list routes
+		problems := api.Group("/problems")
+		problems.Use(middleware.AuthMiddleware(), middleware.RoleMiddleware("doctor"))
+		{
+			problems.PUT("/:id", h.problem.UpdateProblem)
+			problems.POST("/:id/resolve", h.problem.ResolveProblem)
+			problems.DELETE("/:id", h.problem.DeleteProblem)
+		}
+
+		// Lab order routes
+		labOrders := api.Group("/lab-orders")
+		labOrders.Use(middleware.AuthMiddleware())
+		{
+			labOrders.GET("/:id", h.lab.GetOrderByID)
+			labOrders.POST("/:id/results", middleware.RoleMiddleware("nurse", "doctor"), h.lab.EnterResults)
+
+			// Doctor only routes
+			labOrders.GET("/inbox", middleware.RoleMiddleware("doctor"), h.lab.GetReviewInbox)
+			labOrders.POST("", middleware.RoleMiddleware("doctor"), h.lab.CreateOrder)
+			labOrders.POST("/:id/cancel", middleware.RoleMiddleware("doctor"), h.lab.CancelOrder)
+			labOrders.POST("/:id/acknowledge", middleware.RoleMiddleware("doctor"), h.lab.AcknowledgeOrder)
+		}
+
+		// Immunization routes
+		immunizations := api.Group("/immunizations")
+		immunizations.Use(middleware.AuthMiddleware())
+		{
+			immunizations.GET("/:id", h.immunization.GetImmunizationByID)
+			immunizations.PUT("/:id", middleware.RoleMiddleware("nurse", "doctor"), h.immunization.UpdateImmunization)
+			immunizations.DELETE("/:id", middleware.RoleMiddleware("nurse", "doctor"), h.immunization.DeleteImmunization)
+		}
+
+		// Document routes
+		documents := api.Group("/documents")
+		documents.Use(middleware.AuthMiddleware())
+		{
+			documents.GET("/:id", h.document.GetDocumentByID)
+			documents.GET("/:id/content", h.document.DownloadDocument)
+			documents.PUT("/:id", middleware.RoleMiddleware("receptionist", "doctor"), h.document.UpdateDocument)
+			documents.DELETE("/:id", middleware.RoleMiddleware("receptionist", "doctor"), h.document.DeleteDocument)
+		}
+
+		// Referral routes
+		referrals := api.Group("/referrals")
+		referrals.Use(middleware.AuthMiddleware())
+		{
+			referrals.GET("/:id", h.referral.GetReferralByID)
+			referrals.POST("/:id/book", middleware.RoleMiddleware("receptionist", "doctor"), h.referral.BookAppointment)
+
+			// Doctor only routes
+			referrals.GET("", middleware.RoleMiddleware("doctor"), h.referral.GetMyReferrals)
+			referrals.POST("", middleware.RoleMiddleware("doctor"), h.referral.CreateReferral)
+			referrals.POST("/:id/accept", middleware.RoleMiddleware("doctor"), h.referral.AcceptReferral)
+			referrals.POST("/:id/decline", middleware.RoleMiddleware("doctor"), h.referral.DeclineReferral)
+			referrals.POST("/:id/close", middleware.RoleMiddleware("doctor"), h.referral.CloseReferral)
+		}
+
+		// Care plan routes
+		carePlans := api.Group("/care-plans")
+		carePlans.Use(middleware.AuthMiddleware())
+		{
+			carePlans.GET("/:id", h.carePlan.GetCarePlanByID)
+
+			// Nurse and doctor routes
+			carePlans.PUT("/:id", middleware.RoleMiddleware("nurse", "doctor"), h.carePlan.UpdateCarePlan)
+			carePlans.POST("/:id/goals", middleware.RoleMiddleware("nurse", "doctor"), h.carePlan.AddGoal)
+			carePlans.PUT("/:id/goals/:goalId", middleware.RoleMiddleware("nurse", "doctor"), h.carePlan.UpdateGoal)
+			carePlans.POST("/:id/tasks", middleware.RoleMiddleware("nurse", "doctor"), h.carePlan.AddTask)
+		}
+
+		// Care task routes - any staff member can work their assigned tasks
+		careTasks := api.Group("/care-tasks")
+		careTasks.Use(middleware.AuthMiddleware())
+		{
+			careTasks.GET("", h.carePlan.GetTasks)
+			careTasks.GET("/:id", h.carePlan.GetTaskByID)
+			careTasks.GET("/:id/suggested-appointments", h.carePlan.SuggestAppointments)
+			careTasks.POST("/:id/book", h.carePlan.BookTask)
+			careTasks.POST("/:id/complete", h.carePlan.CompleteTask)
+
+			// Nurse and doctor routes
+			careTasks.PUT("/:id", middleware.RoleMiddleware("nurse", "doctor"), h.carePlan.UpdateTask)
+			careTasks.POST("/:id/cancel", middleware.RoleMiddleware("nurse", "doctor"), h.carePlan.CancelTask)
+		}
+
+		// Consent text versions
+		consentTexts := api.Group("/consent-texts")
+		consentTexts.Use(middleware.AuthMiddleware())
+		{
+			consentTexts.GET("", h.consent.GetTexts)
+			consentTexts.GET("/current/:type", h.consent.GetCurrentText)
+
+			// Admin only routes
+			consentTexts.POST("", middleware.RoleMiddleware("admin"), h.consent.PublishText)
+		}
+
+		// Insurance payers and plans
+		payers := api.Group("/insurance-payers")
+		payers.Use(middleware.AuthMiddleware())
+		{
+			payers.GET("", h.insurance.GetPayers)
+			payers.GET("/:id", h.insurance.GetPayerByID)
+			payers.GET("/:id/plans", h.insurance.GetPayerPlans)
+
+			// Admin only routes
+			payers.POST("", middleware.RoleMiddleware("admin"), h.insurance.CreatePayer)
+			payers.PUT("/:id", middleware.RoleMiddleware("admin"), h.insurance.UpdatePayer)
+			payers.POST("/:id/plans", middleware.RoleMiddleware("admin"), h.insurance.CreatePlan)
+		}
+
+		plans := api.Group("/insurance-plans")
+		plans.Use(middleware.AuthMiddleware())
+		{
+			plans.GET("/:id", h.insurance.GetPlanByID)
+
+			// Admin only routes
+			plans.PUT("/:id", middleware.RoleMiddleware("admin"), h.insurance.UpdatePlan)
+		}
+
+		// Billing - front desk and admin only
+		charges := api.Group("/charges")
+		charges.Use(middleware.AuthMiddleware(), middleware.RoleMiddleware("receptionist", "admin"))
+		{
+			charges.POST("/:id/void", h.billing.VoidCharge)
+		}
+
+		invoices := api.Group("/invoices")
+		invoices.Use(middleware.AuthMiddleware(), middleware.RoleMiddleware("receptionist", "admin"))
+		{
+			invoices.GET("/:id", h.billing.GetInvoiceByID)
+			invoices.POST("/:id/void", h.billing.VoidInvoice)
+			invoices.POST("/:id/payments", h.billing.RecordPayment)
+			invoices.GET("/:id/pdf", h.printout.InvoicePDF)
+			invoices.GET("/:id/payments/:paymentId/receipt", h.printout.ReceiptPDF)
+		}
+
+		// Insurance claims (837P) and remittances (835)
+		claims := api.Group("/claims")
+		claims.Use(middleware.AuthMiddleware(), middleware.RoleMiddleware("receptionist", "admin"))
+		{
+			claims.GET("", h.claim.GetClaims)
+			claims.GET("/:id", h.claim.GetClaimByID)
+		}
+
+		claimBatches := api.Group("/claim-batches")
+		claimBatches.Use(middleware.AuthMiddleware(), middleware.RoleMiddleware("receptionist", "admin"))
+		{
+			claimBatches.GET("", h.claim.GetBatches)
+			claimBatches.POST("", h.claim.CreateBatch)
+			claimBatches.GET("/:id", h.claim.GetBatchByID)
+			claimBatches.GET("/:id/file", h.claim.DownloadBatch)
+		}
+
+		remittances := api.Group("/remittances")
+		remittances.Use(middleware.AuthMiddleware(), middleware.RoleMiddleware("receptionist", "admin"))
+		{
+			remittances.GET("", h.claim.GetRemittances)
+			remittances.POST("", h.claim.ImportRemittance)
+			remittances.GET("/:id", h.claim.GetRemittanceByID)
+		}
+
+		// De-identified exports - only patients consenting to research use
+		exports := api.Group("/exports")
+		exports.Use(middleware.AuthMiddleware(), middleware.RoleMiddleware("admin"))
+		{
+			exports.GET("/deidentified", h.export.GetDeidentifiedExport)
+		}
+
+		// ICD-10 code table
+		diagnosisCodes := api.Group("/diagnosis-codes")
+		diagnosisCodes.Use(middleware.AuthMiddleware())
+		{
+			diagnosisCodes.GET("", h.problem.SearchCodes)
+			diagnosisCodes.GET("/:code", h.problem.GetCode)
+		}
+
+		// Encounter routes - clinical notes are for doctors only
+		encounters := api.Group("/encounters")
+		encounters.Use(middleware.AuthMiddleware(), middleware.RoleMiddleware("doctor"))
+		{
+			encounters.POST("", h.encounter.CreateEncounter)
+			encounters.GET("/:id", h.encounter.GetEncounterByID)
+			encounters.GET("/appointment/:appointmentId", h.encounter.GetEncounterByAppointment)
+			encounters.GET("/patient/:patientId", h.encounter.GetPatientTimeline)
+			encounters.PUT("/:id", h.encounter.UpdateEncounter)
+			encounters.POST("/:id/sign", h.encounter.SignEncounter)
+			encounters.POST("/:id/addenda", h.encounter.AddAddendum)
 		}
 	}
 
@@ -6,9 +6,18 @@ import (
 )
 
 type Config struct {
-    Database DatabaseConfig
-    Server   ServerConfig
-    JWT      JWTConfig
+    Database   DatabaseConfig
+    Server     ServerConfig
+    JWT        JWTConfig
+    Scheduling SchedulingConfig
+    Clinical   ClinicalConfig
+    Documents  DocumentsConfig
+    Workers    WorkersConfig
+    MRN        MRNConfig
+    Exports    ExportsConfig
+    Billing    BillingConfig
+    Claims     ClaimsConfig
+    Branding   BrandingConfig
 }
 
 type DatabaseConfig struct {
@@ -24,6 +33,89 @@ type ServerConfig struct {
     Mode string
 }
 
+// SchedulingConfig bounds the clinic day used when searching for free slots.
+type SchedulingConfig struct {
+    DayStart    string
+    DayEnd      string
+    SlotMinutes int
+}
+
+// ClinicalConfig points at the locally maintained clinical rule files.
+type ClinicalConfig struct {
+    InteractionRulesFile     string
+    DiagnosisCodesFile       string
+    ImmunizationScheduleFile string
+}
+
+// DocumentsConfig controls where uploaded patient documents are kept.
+type DocumentsConfig struct {
+    StorageDir  string
+    MaxUploadMB int
+}
+
+// WorkersConfig sets how often background workers run.
+type WorkersConfig struct {
+    CareTaskSweepMinutes int
+    ReminderSweepMinutes int
+}
+
+// MRNConfig shapes generated medical record numbers, e.g. MRN-MAIN-00012348
+// with a per-location sequence and a trailing Luhn check digit.
+type MRNConfig struct {
+    Prefix      string
+    Digits      int
+    CheckDigit  bool
+    PerLocation bool
+}
+
+// ExportsConfig holds the key used to pseudonymise patients in
+// de-identified exports.
+type ExportsConfig struct {
+    PseudonymKey string
+}
+
+// BillingConfig sets the defaults for new invoices. Tax is charged in basis
+// points of the discounted subtotal, so 825 is 8.25%.
+type BillingConfig struct {
+    TaxRateBasisPoints int
+    PaymentTermsDays   int
+}
+
+// ClaimsConfig identifies the practice on electronic claims: the submitter
+// and receiver IDs agreed with the clearinghouse, and the billing provider
+// printed on every claim. Usage is "T" while testing with the clearinghouse
+// and "P" in production.
+type ClaimsConfig struct {
+    SubmitterID           string
+    SubmitterName         string
+    ContactName           string
+    ContactPhone          string
+    ReceiverID            string
+    ReceiverName          string
+    BillingProviderName   string
+    BillingProviderNPI    string
+    BillingProviderTaxID  string
+    BillingProviderStreet string
+    BillingProviderCity   string
+    BillingProviderState  string
+    BillingProviderZip    string
+    Usage                 string
+}
+
+// BrandingConfig is the clinic identity printed on invoices, receipts and
+// other PDFs. ClinicName falls back to the tenant's name, AccentColor is a
+// #RRGGBB color and LogoFile a PNG or JPEG.
+type BrandingConfig struct {
+    ClinicName  string
+    Address     string
+    Phone       string
+    Email       string
+    Website     string
+    AccentColor string
+    LogoFile    string
+    Footer      string
+}
+
 type JWTConfig struct {
     Secret     string
     Expiration int
@@ -46,6 +138,63 @@ func Load() *Config {
             Secret:     getEnv("JWT_SECRET", "your-secret-key"),
             Expiration: getEnvAsInt("JWT_EXPIRATION", 24),
         },
+        Scheduling: SchedulingConfig{
+            DayStart:    getEnv("SCHEDULE_DAY_START", "09:00"),
+            DayEnd:      getEnv("SCHEDULE_DAY_END", "17:00"),
+            SlotMinutes: getEnvAsInt("SCHEDULE_SLOT_MINUTES", 15),
+        },
+        Clinical: ClinicalConfig{
+            InteractionRulesFile:     getEnv("INTERACTION_RULES_FILE", "data/interaction_rules.json"),
+            DiagnosisCodesFile:       getEnv("DIAGNOSIS_CODES_FILE", "data/icd10_codes.txt"),
+            ImmunizationScheduleFile: getEnv("IMMUNIZATION_SCHEDULE_FILE", "data/immunization_schedule.json"),
+        },
+        Documents: DocumentsConfig{
+            StorageDir:  getEnv("DOCUMENT_STORAGE_DIR", "storage/documents"),
+            MaxUploadMB: getEnvAsInt("DOCUMENT_MAX_UPLOAD_MB", 20),
+        },
+        Workers: WorkersConfig{
+            CareTaskSweepMinutes: getEnvAsInt("CARE_TASK_SWEEP_MINUTES", 60),
+            ReminderSweepMinutes: getEnvAsInt("REMINDER_SWEEP_MINUTES", 60),
+        },
+        MRN: MRNConfig{
+            Prefix:      getEnv("MRN_PREFIX", "MRN"),
+            Digits:      getEnvAsInt("MRN_DIGITS", 7),
+            CheckDigit:  getEnvAsBool("MRN_CHECK_DIGIT", true),
+            PerLocation: getEnvAsBool("MRN_PER_LOCATION", true),
+        },
+        Exports: ExportsConfig{
+            PseudonymKey: getEnv("EXPORT_PSEUDONYM_KEY", "change-this-export-key"),
+        },
+        Billing: BillingConfig{
+            TaxRateBasisPoints: getEnvAsInt("BILLING_TAX_RATE_BASIS_POINTS", 0),
+            PaymentTermsDays:   getEnvAsInt("BILLING_PAYMENT_TERMS_DAYS", 30),
+        },
+        Claims: ClaimsConfig{
+            SubmitterID:           getEnv("CLAIMS_SUBMITTER_ID", ""),
+            SubmitterName:         getEnv("CLAIMS_SUBMITTER_NAME", ""),
+            ContactName:           getEnv("CLAIMS_CONTACT_NAME", ""),
+            ContactPhone:          getEnv("CLAIMS_CONTACT_PHONE", ""),
+            ReceiverID:            getEnv("CLAIMS_RECEIVER_ID", ""),
+            ReceiverName:          getEnv("CLAIMS_RECEIVER_NAME", ""),
+            BillingProviderName:   getEnv("CLAIMS_BILLING_PROVIDER_NAME", ""),
+            BillingProviderNPI:    getEnv("CLAIMS_BILLING_PROVIDER_NPI", ""),
+            BillingProviderTaxID:  getEnv("CLAIMS_BILLING_PROVIDER_TAX_ID", ""),
+            BillingProviderStreet: getEnv("CLAIMS_BILLING_PROVIDER_STREET", ""),
+            BillingProviderCity:   getEnv("CLAIMS_BILLING_PROVIDER_CITY", ""),
+            BillingProviderState:  getEnv("CLAIMS_BILLING_PROVIDER_STATE", ""),
+            BillingProviderZip:    getEnv("CLAIMS_BILLING_PROVIDER_ZIP", ""),
+            Usage:                 getEnv("CLAIMS_USAGE", "T"),
+        },
+        Branding: BrandingConfig{
+            ClinicName:  getEnv("CLINIC_NAME", ""),
+            Address:     getEnv("CLINIC_ADDRESS", ""),
+            Phone:       getEnv("CLINIC_PHONE", ""),
+            Email:       getEnv("CLINIC_EMAIL", ""),
+            Website:     getEnv("CLINIC_WEBSITE", ""),
+            AccentColor: getEnv("CLINIC_ACCENT_COLOR", "#1F6FB2"),
+            LogoFile:    getEnv("CLINIC_LOGO_FILE", ""),
+            Footer:      getEnv("CLINIC_DOCUMENT_FOOTER", ""),
+        },
     }
 }
 
@@ -62,4 +211,12 @@ func getEnvAsInt(key string, defaultValue int) int {
         return value
     }
     return defaultValue
+}
+
+func getEnvAsBool(key string, defaultValue bool) bool {
+    valueStr := getEnv(key, "")
+    if value, err := strconv.ParseBool(valueStr); err == nil {
+        return value
+    }
+    return defaultValue
 }
\ No newline at end of file
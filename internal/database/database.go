@@ -87,6 +87,19 @@ func createTables(sqlDB *sql.DB) error {
         name string
         sql  string
     }{
+        {
+            name: "tenants",
+            sql: `
+                CREATE TABLE IF NOT EXISTS tenants (
+                    id SERIAL PRIMARY KEY,
+                    name VARCHAR(255) NOT NULL,
+                    slug VARCHAR(100) UNIQUE NOT NULL,
+                    is_active BOOLEAN DEFAULT true,
+                    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+                    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+                    deleted_at TIMESTAMP
+                )`,
+        },
         {
             name: "users",
             sql: `
@@ -95,7 +108,7 @@ func createTables(sqlDB *sql.DB) error {
                     email VARCHAR(255) UNIQUE NOT NULL,
                     password VARCHAR(255) NOT NULL,
                     name VARCHAR(255) NOT NULL,
-                    role VARCHAR(50) NOT NULL CHECK (role IN ('receptionist', 'doctor')),
+                    role VARCHAR(50) NOT NULL,
                     is_active BOOLEAN DEFAULT true,
                     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
                     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
@@ -109,7 +122,7 @@ func createTables(sqlDB *sql.DB) error {
                     id SERIAL PRIMARY KEY,
                     first_name VARCHAR(255) NOT NULL,
                     last_name VARCHAR(255) NOT NULL,
-                    email VARCHAR(255) UNIQUE,
+                    email VARCHAR(255),
                     phone VARCHAR(50) NOT NULL,
                     date_of_birth TIMESTAMP,
                     gender VARCHAR(20),
@@ -136,14 +149,852 @@ func createTables(sqlDB *sql.DB) error {
                     doctor_id INTEGER NOT NULL REFERENCES users(id),
                     date TIMESTAMP,
                     time VARCHAR(10),
-                    status VARCHAR(20) DEFAULT 'scheduled' CHECK (status IN ('scheduled', 'completed', 'cancelled')),
+                    status VARCHAR(20) DEFAULT 'scheduled' CHECK (status IN ('scheduled', 'checked_in', 'completed', 'cancelled')),
+                    notes TEXT,
+                    created_by INTEGER REFERENCES users(id),
+                    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+                    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+                    deleted_at TIMESTAMP
+                )`,
+        },
+        {
+            name: "locations",
+            sql: `
+                CREATE TABLE IF NOT EXISTS locations (
+                    id SERIAL PRIMARY KEY,
+                    code VARCHAR(50) NOT NULL,
+                    name VARCHAR(255) NOT NULL,
+                    address TEXT,
+                    phone VARCHAR(50),
+                    time_zone VARCHAR(64) NOT NULL DEFAULT 'UTC',
+                    is_active BOOLEAN DEFAULT true,
+                    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+                    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+                    deleted_at TIMESTAMP
+                )`,
+        },
+        {
+            name: "doctor_schedules",
+            sql: `
+                CREATE TABLE IF NOT EXISTS doctor_schedules (
+                    id SERIAL PRIMARY KEY,
+                    doctor_id INTEGER NOT NULL REFERENCES users(id),
+                    location_id INTEGER NOT NULL REFERENCES locations(id),
+                    day_of_week SMALLINT NOT NULL CHECK (day_of_week BETWEEN 0 AND 6),
+                    start_time VARCHAR(5) NOT NULL,
+                    end_time VARCHAR(5) NOT NULL,
+                    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+                    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+                    deleted_at TIMESTAMP
+                )`,
+        },
+        {
+            name: "resources",
+            sql: `
+                CREATE TABLE IF NOT EXISTS resources (
+                    id SERIAL PRIMARY KEY,
+                    name VARCHAR(255) NOT NULL,
+                    kind VARCHAR(20) NOT NULL CHECK (kind IN ('room', 'equipment')),
+                    description TEXT,
+                    is_active BOOLEAN DEFAULT true,
+                    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+                    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+                    deleted_at TIMESTAMP
+                )`,
+        },
+        {
+            name: "resource_capabilities",
+            sql: `
+                CREATE TABLE IF NOT EXISTS resource_capabilities (
+                    id SERIAL PRIMARY KEY,
+                    resource_id INTEGER NOT NULL REFERENCES resources(id) ON DELETE CASCADE,
+                    capability VARCHAR(100) NOT NULL,
+                    UNIQUE (resource_id, capability)
+                )`,
+        },
+        {
+            name: "appointment_types",
+            sql: `
+                CREATE TABLE IF NOT EXISTS appointment_types (
+                    id SERIAL PRIMARY KEY,
+                    code VARCHAR(50) NOT NULL,
+                    name VARCHAR(255) NOT NULL,
+                    is_active BOOLEAN DEFAULT true,
+                    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+                    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+                    deleted_at TIMESTAMP
+                )`,
+        },
+        {
+            name: "appointment_type_requirements",
+            sql: `
+                CREATE TABLE IF NOT EXISTS appointment_type_requirements (
+                    id SERIAL PRIMARY KEY,
+                    appointment_type_id INTEGER NOT NULL REFERENCES appointment_types(id) ON DELETE CASCADE,
+                    capability VARCHAR(100) NOT NULL,
+                    quantity INTEGER NOT NULL DEFAULT 1 CHECK (quantity > 0)
+                )`,
+        },
+        {
+            name: "appointment_type_specialties",
+            sql: `
+                CREATE TABLE IF NOT EXISTS appointment_type_specialties (
+                    id SERIAL PRIMARY KEY,
+                    appointment_type_id INTEGER NOT NULL REFERENCES appointment_types(id) ON DELETE CASCADE,
+                    specialty VARCHAR(100) NOT NULL,
+                    UNIQUE (appointment_type_id, specialty)
+                )`,
+        },
+        {
+            name: "appointment_resources",
+            sql: `
+                CREATE TABLE IF NOT EXISTS appointment_resources (
+                    id SERIAL PRIMARY KEY,
+                    appointment_id INTEGER NOT NULL REFERENCES appointments(id) ON DELETE CASCADE,
+                    resource_id INTEGER NOT NULL REFERENCES resources(id),
+                    UNIQUE (appointment_id, resource_id)
+                )`,
+        },
+        {
+            name: "encounters",
+            sql: `
+                CREATE TABLE IF NOT EXISTS encounters (
+                    id SERIAL PRIMARY KEY,
+                    tenant_id INTEGER NOT NULL DEFAULT 1 REFERENCES tenants(id),
+                    appointment_id INTEGER UNIQUE NOT NULL REFERENCES appointments(id),
+                    patient_id INTEGER NOT NULL REFERENCES patients(id),
+                    doctor_id INTEGER NOT NULL REFERENCES users(id),
+                    visit_date TIMESTAMP NOT NULL,
+                    subjective TEXT,
+                    objective TEXT,
+                    assessment TEXT,
+                    plan TEXT,
+                    status VARCHAR(20) NOT NULL DEFAULT 'draft' CHECK (status IN ('draft', 'signed')),
+                    signed_at TIMESTAMP,
+                    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+                    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+                    deleted_at TIMESTAMP
+                )`,
+        },
+        {
+            name: "encounter_addenda",
+            sql: `
+                CREATE TABLE IF NOT EXISTS encounter_addenda (
+                    id SERIAL PRIMARY KEY,
+                    encounter_id INTEGER NOT NULL REFERENCES encounters(id) ON DELETE CASCADE,
+                    author_id INTEGER NOT NULL REFERENCES users(id),
+                    content TEXT NOT NULL,
+                    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+                )`,
+        },
+        {
+            name: "allergies",
+            sql: `
+                CREATE TABLE IF NOT EXISTS allergies (
+                    id SERIAL PRIMARY KEY,
+                    tenant_id INTEGER NOT NULL DEFAULT 1 REFERENCES tenants(id),
+                    patient_id INTEGER NOT NULL REFERENCES patients(id),
+                    substance VARCHAR(255) NOT NULL,
+                    category VARCHAR(20) NOT NULL CHECK (category IN ('medication', 'food', 'environment', 'biologic')),
+                    reaction VARCHAR(255),
+                    severity VARCHAR(20) NOT NULL DEFAULT 'unknown' CHECK (severity IN ('mild', 'moderate', 'severe', 'unknown')),
+                    status VARCHAR(20) NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'inactive', 'resolved')),
+                    verification VARCHAR(20) NOT NULL DEFAULT 'confirmed' CHECK (verification IN ('unconfirmed', 'confirmed')),
+                    source VARCHAR(20) NOT NULL DEFAULT 'entered',
+                    notes TEXT,
+                    recorded_by INTEGER,
+                    confirmed_by INTEGER REFERENCES users(id),
+                    confirmed_at TIMESTAMP,
+                    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+                    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+                    deleted_at TIMESTAMP
+                )`,
+        },
+        {
+            name: "prescriptions",
+            sql: `
+                CREATE TABLE IF NOT EXISTS prescriptions (
+                    id SERIAL PRIMARY KEY,
+                    tenant_id INTEGER NOT NULL DEFAULT 1 REFERENCES tenants(id),
+                    patient_id INTEGER NOT NULL REFERENCES patients(id),
+                    doctor_id INTEGER NOT NULL REFERENCES users(id),
+                    encounter_id INTEGER REFERENCES encounters(id),
+                    drug VARCHAR(255) NOT NULL,
+                    dose VARCHAR(100) NOT NULL,
+                    route VARCHAR(20) NOT NULL DEFAULT 'oral',
+                    frequency VARCHAR(100) NOT NULL,
+                    quantity VARCHAR(100),
+                    duration_days INTEGER,
+                    refills INTEGER NOT NULL DEFAULT 0 CHECK (refills >= 0),
+                    refills_used INTEGER NOT NULL DEFAULT 0 CHECK (refills_used >= 0),
+                    instructions TEXT,
+                    status VARCHAR(20) NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'completed', 'cancelled')),
+                    issued_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+                    override_reason TEXT,
+                    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+                    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+                    deleted_at TIMESTAMP
+                )`,
+        },
+        {
+            name: "prescription_warnings",
+            sql: `
+                CREATE TABLE IF NOT EXISTS prescription_warnings (
+                    id SERIAL PRIMARY KEY,
+                    prescription_id INTEGER NOT NULL REFERENCES prescriptions(id) ON DELETE CASCADE,
+                    type VARCHAR(30) NOT NULL,
+                    severity VARCHAR(20) NOT NULL CHECK (severity IN ('minor', 'moderate', 'major', 'contraindicated')),
+                    conflict VARCHAR(255),
+                    message TEXT,
+                    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+                )`,
+        },
+        {
+            name: "medication_statements",
+            sql: `
+                CREATE TABLE IF NOT EXISTS medication_statements (
+                    id SERIAL PRIMARY KEY,
+                    tenant_id INTEGER NOT NULL DEFAULT 1 REFERENCES tenants(id),
+                    patient_id INTEGER NOT NULL REFERENCES patients(id),
+                    prescription_id INTEGER REFERENCES prescriptions(id),
+                    drug VARCHAR(255) NOT NULL,
+                    dose VARCHAR(100),
+                    route VARCHAR(20) NOT NULL DEFAULT 'oral',
+                    frequency VARCHAR(100),
+                    start_date TIMESTAMP,
+                    stop_date TIMESTAMP,
+                    status VARCHAR(20) NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'stopped', 'completed')),
+                    notes TEXT,
+                    recorded_by INTEGER,
+                    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+                    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+                    deleted_at TIMESTAMP
+                )`,
+        },
+        {
+            name: "vital_signs",
+            sql: `
+                CREATE TABLE IF NOT EXISTS vital_signs (
+                    id SERIAL PRIMARY KEY,
+                    tenant_id INTEGER NOT NULL DEFAULT 1 REFERENCES tenants(id),
+                    patient_id INTEGER NOT NULL REFERENCES patients(id),
+                    appointment_id INTEGER REFERENCES appointments(id),
+                    recorded_by INTEGER REFERENCES users(id),
+                    recorded_at TIMESTAMP NOT NULL,
+                    systolic_bp INTEGER,
+                    diastolic_bp INTEGER,
+                    heart_rate INTEGER,
+                    respiratory_rate INTEGER,
+                    temperature_c NUMERIC(4,1),
+                    oxygen_saturation INTEGER,
+                    weight_kg NUMERIC(5,1),
+                    height_cm NUMERIC(4,1),
+                    bmi NUMERIC(4,1),
+                    notes TEXT,
+                    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+                    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+                    deleted_at TIMESTAMP
+                )`,
+        },
+        {
+            name: "diagnosis_codes",
+            sql: `
+                CREATE TABLE IF NOT EXISTS diagnosis_codes (
+                    code VARCHAR(10) PRIMARY KEY,
+                    description TEXT NOT NULL
+                )`,
+        },
+        {
+            name: "problems",
+            sql: `
+                CREATE TABLE IF NOT EXISTS problems (
+                    id SERIAL PRIMARY KEY,
+                    tenant_id INTEGER NOT NULL DEFAULT 1 REFERENCES tenants(id),
+                    patient_id INTEGER NOT NULL REFERENCES patients(id),
+                    code VARCHAR(10) NOT NULL REFERENCES diagnosis_codes(code),
+                    description TEXT NOT NULL,
+                    status VARCHAR(20) NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'resolved')),
+                    onset_date TIMESTAMP,
+                    resolved_date TIMESTAMP,
+                    encounter_id INTEGER REFERENCES encounters(id),
+                    notes TEXT,
+                    recorded_by INTEGER,
+                    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+                    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+                    deleted_at TIMESTAMP
+                )`,
+        },
+        {
+            name: "appointment_diagnoses",
+            sql: `
+                CREATE TABLE IF NOT EXISTS appointment_diagnoses (
+                    id SERIAL PRIMARY KEY,
+                    appointment_id INTEGER NOT NULL REFERENCES appointments(id) ON DELETE CASCADE,
+                    code VARCHAR(10) NOT NULL REFERENCES diagnosis_codes(code),
+                    description TEXT NOT NULL,
+                    rank INTEGER NOT NULL,
+                    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+                    UNIQUE (appointment_id, code)
+                )`,
+        },
+        {
+            name: "lab_orders",
+            sql: `
+                CREATE TABLE IF NOT EXISTS lab_orders (
+                    id SERIAL PRIMARY KEY,
+                    tenant_id INTEGER NOT NULL DEFAULT 1 REFERENCES tenants(id),
+                    patient_id INTEGER NOT NULL REFERENCES patients(id),
+                    doctor_id INTEGER NOT NULL REFERENCES users(id),
+                    encounter_id INTEGER REFERENCES encounters(id),
+                    test_code VARCHAR(20),
+                    test_name VARCHAR(255) NOT NULL,
+                    priority VARCHAR(20) NOT NULL DEFAULT 'routine' CHECK (priority IN ('routine', 'urgent', 'stat')),
+                    status VARCHAR(20) NOT NULL DEFAULT 'ordered' CHECK (status IN ('ordered', 'partial', 'resulted', 'cancelled')),
+                    clinical_notes TEXT,
+                    ordered_at TIMESTAMP NOT NULL,
+                    resulted_at TIMESTAMP,
+                    reviewed_by INTEGER REFERENCES users(id),
+                    reviewed_at TIMESTAMP,
+                    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+                    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+                    deleted_at TIMESTAMP
+                )`,
+        },
+        {
+            name: "lab_results",
+            sql: `
+                CREATE TABLE IF NOT EXISTS lab_results (
+                    id SERIAL PRIMARY KEY,
+                    lab_order_id INTEGER NOT NULL REFERENCES lab_orders(id) ON DELETE CASCADE,
+                    analyte VARCHAR(255) NOT NULL,
+                    value VARCHAR(255) NOT NULL,
+                    numeric_value DOUBLE PRECISION,
+                    unit VARCHAR(30),
+                    reference_range VARCHAR(50),
+                    reference_low DOUBLE PRECISION,
+                    reference_high DOUBLE PRECISION,
+                    critical_low DOUBLE PRECISION,
+                    critical_high DOUBLE PRECISION,
+                    flag VARCHAR(20) NOT NULL DEFAULT 'normal' CHECK (flag IN ('normal', 'low', 'high', 'critical_low', 'critical_high', 'abnormal')),
+                    notes TEXT,
+                    entered_by INTEGER,
+                    resulted_at TIMESTAMP NOT NULL,
+                    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+                )`,
+        },
+        {
+            name: "immunizations",
+            sql: `
+                CREATE TABLE IF NOT EXISTS immunizations (
+                    id SERIAL PRIMARY KEY,
+                    tenant_id INTEGER NOT NULL DEFAULT 1 REFERENCES tenants(id),
+                    patient_id INTEGER NOT NULL REFERENCES patients(id),
+                    vaccine_code VARCHAR(20) NOT NULL,
+                    vaccine_name VARCHAR(255) NOT NULL,
+                    dose_number INTEGER NOT NULL CHECK (dose_number > 0),
+                    administered_at TIMESTAMP NOT NULL,
+                    lot_number VARCHAR(50),
+                    manufacturer VARCHAR(255),
+                    expiration_date DATE,
+                    site VARCHAR(20) CHECK (site IN ('', 'left_deltoid', 'right_deltoid', 'left_thigh', 'right_thigh', 'oral', 'intranasal')),
+                    source VARCHAR(20) NOT NULL DEFAULT 'administered' CHECK (source IN ('administered', 'historical')),
+                    administered_by INTEGER REFERENCES users(id),
+                    notes TEXT,
+                    recorded_by INTEGER,
+                    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+                    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+                    deleted_at TIMESTAMP
+                )`,
+        },
+        {
+            name: "documents",
+            sql: `
+                CREATE TABLE IF NOT EXISTS documents (
+                    id SERIAL PRIMARY KEY,
+                    tenant_id INTEGER NOT NULL DEFAULT 1 REFERENCES tenants(id),
+                    patient_id INTEGER NOT NULL REFERENCES patients(id),
+                    category VARCHAR(30) NOT NULL DEFAULT 'other' CHECK (category IN ('referral_letter', 'identification', 'imaging_report', 'lab_report', 'insurance_card', 'other')),
+                    title VARCHAR(255) NOT NULL,
+                    file_name VARCHAR(255) NOT NULL,
+                    content_type VARCHAR(100) NOT NULL,
+                    size_bytes BIGINT NOT NULL,
+                    sha256 VARCHAR(64) NOT NULL,
+                    storage_key VARCHAR(255) NOT NULL UNIQUE,
+                    description TEXT,
+                    uploaded_by INTEGER REFERENCES users(id),
+                    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+                    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+                    deleted_at TIMESTAMP
+                )`,
+        },
+        {
+            name: "referrals",
+            sql: `
+                CREATE TABLE IF NOT EXISTS referrals (
+                    id SERIAL PRIMARY KEY,
+                    tenant_id INTEGER NOT NULL DEFAULT 1 REFERENCES tenants(id),
+                    patient_id INTEGER NOT NULL REFERENCES patients(id),
+                    referring_doctor_id INTEGER NOT NULL REFERENCES users(id),
+                    receiving_doctor_id INTEGER REFERENCES users(id),
+                    specialty VARCHAR(100),
+                    urgency VARCHAR(20) NOT NULL DEFAULT 'routine' CHECK (urgency IN ('routine', 'urgent', 'emergent')),
+                    reason TEXT NOT NULL,
+                    status VARCHAR(20) NOT NULL DEFAULT 'sent' CHECK (status IN ('sent', 'accepted', 'declined', 'scheduled', 'closed')),
+                    response_note TEXT,
+                    responded_at TIMESTAMP,
+                    appointment_id INTEGER REFERENCES appointments(id),
+                    closed_at TIMESTAMP,
+                    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+                    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+                    deleted_at TIMESTAMP,
+                    CHECK (receiving_doctor_id IS NOT NULL OR specialty IS NOT NULL)
+                )`,
+        },
+        {
+            name: "referral_attachments",
+            sql: `
+                CREATE TABLE IF NOT EXISTS referral_attachments (
+                    id SERIAL PRIMARY KEY,
+                    referral_id INTEGER NOT NULL REFERENCES referrals(id) ON DELETE CASCADE,
+                    document_id INTEGER NOT NULL REFERENCES documents(id),
+                    UNIQUE (referral_id, document_id)
+                )`,
+        },
+        {
+            name: "care_plans",
+            sql: `
+                CREATE TABLE IF NOT EXISTS care_plans (
+                    id SERIAL PRIMARY KEY,
+                    tenant_id INTEGER NOT NULL DEFAULT 1 REFERENCES tenants(id),
+                    patient_id INTEGER NOT NULL REFERENCES patients(id),
+                    problem_id INTEGER REFERENCES problems(id),
+                    title VARCHAR(255) NOT NULL,
+                    description TEXT,
+                    status VARCHAR(20) NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'completed', 'cancelled')),
+                    created_by INTEGER REFERENCES users(id),
+                    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+                    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+                    deleted_at TIMESTAMP
+                )`,
+        },
+        {
+            name: "care_plan_goals",
+            sql: `
+                CREATE TABLE IF NOT EXISTS care_plan_goals (
+                    id SERIAL PRIMARY KEY,
+                    care_plan_id INTEGER NOT NULL REFERENCES care_plans(id) ON DELETE CASCADE,
+                    description VARCHAR(255) NOT NULL,
+                    target VARCHAR(100),
+                    target_date DATE,
+                    status VARCHAR(20) NOT NULL DEFAULT 'in_progress' CHECK (status IN ('in_progress', 'achieved', 'not_achieved')),
+                    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+                    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+                )`,
+        },
+        {
+            name: "care_tasks",
+            sql: `
+                CREATE TABLE IF NOT EXISTS care_tasks (
+                    id SERIAL PRIMARY KEY,
+                    tenant_id INTEGER NOT NULL DEFAULT 1 REFERENCES tenants(id),
+                    care_plan_id INTEGER NOT NULL REFERENCES care_plans(id) ON DELETE CASCADE,
+                    patient_id INTEGER NOT NULL REFERENCES patients(id),
+                    title VARCHAR(255) NOT NULL,
+                    description TEXT,
+                    assigned_to INTEGER REFERENCES users(id),
+                    earliest_date DATE NOT NULL,
+                    due_date DATE NOT NULL,
+                    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'scheduled', 'overdue', 'completed', 'cancelled')),
+                    doctor_id INTEGER REFERENCES users(id),
+                    appointment_type_id INTEGER REFERENCES appointment_types(id),
+                    appointment_id INTEGER REFERENCES appointments(id),
+                    completed_at TIMESTAMP,
+                    completed_by INTEGER REFERENCES users(id),
+                    created_by INTEGER REFERENCES users(id),
+                    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+                    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+                    deleted_at TIMESTAMP,
+                    CHECK (earliest_date <= due_date)
+                )`,
+        },
+        {
+            name: "patient_merges",
+            sql: `
+                CREATE TABLE IF NOT EXISTS patient_merges (
+                    id SERIAL PRIMARY KEY,
+                    tenant_id INTEGER NOT NULL DEFAULT 1 REFERENCES tenants(id),
+                    survivor_patient_id INTEGER NOT NULL REFERENCES patients(id),
+                    merged_patient_id INTEGER NOT NULL UNIQUE REFERENCES patients(id),
+                    reason TEXT,
+                    snapshot TEXT,
+                    moved_records TEXT,
+                    merged_by INTEGER NOT NULL REFERENCES users(id),
+                    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+                    CHECK (survivor_patient_id <> merged_patient_id)
+                )`,
+        },
+        {
+            name: "patient_identifiers",
+            sql: `
+                CREATE TABLE IF NOT EXISTS patient_identifiers (
+                    id SERIAL PRIMARY KEY,
+                    tenant_id INTEGER NOT NULL DEFAULT 1 REFERENCES tenants(id),
+                    patient_id INTEGER NOT NULL REFERENCES patients(id),
+                    system VARCHAR(100) NOT NULL,
+                    value VARCHAR(100) NOT NULL,
+                    assigner VARCHAR(255),
+                    created_by INTEGER REFERENCES users(id),
+                    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+                    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+                    UNIQUE (tenant_id, system, value)
+                )`,
+        },
+        {
+            name: "mrn_sequences",
+            sql: `
+                CREATE TABLE IF NOT EXISTS mrn_sequences (
+                    id SERIAL PRIMARY KEY,
+                    tenant_id INTEGER NOT NULL DEFAULT 1 REFERENCES tenants(id),
+                    location_id INTEGER NOT NULL DEFAULT 0,
+                    next_value BIGINT NOT NULL DEFAULT 1,
+                    UNIQUE (tenant_id, location_id)
+                )`,
+        },
+        {
+            name: "related_persons",
+            sql: `
+                CREATE TABLE IF NOT EXISTS related_persons (
+                    id SERIAL PRIMARY KEY,
+                    tenant_id INTEGER NOT NULL DEFAULT 1 REFERENCES tenants(id),
+                    patient_id INTEGER NOT NULL REFERENCES patients(id),
+                    name VARCHAR(255) NOT NULL,
+                    relationship VARCHAR(30) NOT NULL CHECK (relationship IN ('parent', 'legal_guardian', 'spouse', 'partner', 'child', 'sibling', 'grandparent', 'friend', 'caregiver', 'other')),
+                    mobile_phone VARCHAR(50),
+                    home_phone VARCHAR(50),
+                    work_phone VARCHAR(50),
+                    email VARCHAR(255),
+                    address TEXT,
+                    is_guardian BOOLEAN NOT NULL DEFAULT false,
+                    is_emergency_contact BOOLEAN NOT NULL DEFAULT false,
+                    consent_to_share BOOLEAN NOT NULL DEFAULT false,
                     notes TEXT,
                     created_by INTEGER REFERENCES users(id),
+                    updated_by INTEGER REFERENCES users(id),
                     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
                     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
                     deleted_at TIMESTAMP
                 )`,
         },
+        {
+            name: "consent_texts",
+            sql: `
+                CREATE TABLE IF NOT EXISTS consent_texts (
+                    id SERIAL PRIMARY KEY,
+                    tenant_id INTEGER NOT NULL DEFAULT 1 REFERENCES tenants(id),
+                    type VARCHAR(30) NOT NULL CHECK (type IN ('treatment', 'data_sharing', 'sms_reminders', 'email_reminders', 'research')),
+                    version INTEGER NOT NULL,
+                    title VARCHAR(255) NOT NULL,
+                    body TEXT NOT NULL,
+                    effective_from TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+                    requires_reconsent BOOLEAN NOT NULL DEFAULT false,
+                    created_by INTEGER REFERENCES users(id),
+                    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+                    UNIQUE (tenant_id, type, version)
+                )`,
+        },
+        {
+            name: "consents",
+            sql: `
+                CREATE TABLE IF NOT EXISTS consents (
+                    id SERIAL PRIMARY KEY,
+                    tenant_id INTEGER NOT NULL DEFAULT 1 REFERENCES tenants(id),
+                    patient_id INTEGER NOT NULL REFERENCES patients(id),
+                    type VARCHAR(30) NOT NULL CHECK (type IN ('treatment', 'data_sharing', 'sms_reminders', 'email_reminders', 'research')),
+                    consent_text_id INTEGER NOT NULL REFERENCES consent_texts(id),
+                    text_version INTEGER NOT NULL,
+                    status VARCHAR(20) NOT NULL DEFAULT 'granted' CHECK (status IN ('granted', 'revoked')),
+                    granted_at TIMESTAMP NOT NULL,
+                    revoked_at TIMESTAMP,
+                    revocation_reason TEXT,
+                    related_person_id INTEGER REFERENCES related_persons(id),
+                    notes TEXT,
+                    recorded_by INTEGER REFERENCES users(id),
+                    revoked_by INTEGER REFERENCES users(id),
+                    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+                    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+                    CHECK (revoked_at IS NULL OR revoked_at >= granted_at)
+                )`,
+        },
+        {
+            name: "patient_search_terms",
+            sql: `
+                CREATE TABLE IF NOT EXISTS patient_search_terms (
+                    id SERIAL PRIMARY KEY,
+                    tenant_id INTEGER NOT NULL DEFAULT 1 REFERENCES tenants(id),
+                    patient_id INTEGER NOT NULL REFERENCES patients(id),
+                    kind VARCHAR(10) NOT NULL CHECK (kind IN ('word', 'phonetic', 'trigram')),
+                    term VARCHAR(255) NOT NULL
+                )`,
+        },
+        {
+            name: "insurance_payers",
+            sql: `
+                CREATE TABLE IF NOT EXISTS insurance_payers (
+                    id SERIAL PRIMARY KEY,
+                    tenant_id INTEGER NOT NULL DEFAULT 1 REFERENCES tenants(id),
+                    name VARCHAR(255) NOT NULL,
+                    payer_code VARCHAR(20),
+                    phone VARCHAR(50),
+                    address TEXT,
+                    is_active BOOLEAN NOT NULL DEFAULT true,
+                    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+                    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+                )`,
+        },
+        {
+            name: "insurance_plans",
+            sql: `
+                CREATE TABLE IF NOT EXISTS insurance_plans (
+                    id SERIAL PRIMARY KEY,
+                    tenant_id INTEGER NOT NULL DEFAULT 1 REFERENCES tenants(id),
+                    payer_id INTEGER NOT NULL REFERENCES insurance_payers(id),
+                    name VARCHAR(255) NOT NULL,
+                    plan_type VARCHAR(20) NOT NULL CHECK (plan_type IN ('hmo', 'ppo', 'epo', 'pos', 'medicare', 'medicaid', 'other')),
+                    copay_cents BIGINT NOT NULL DEFAULT 0 CHECK (copay_cents >= 0),
+                    is_active BOOLEAN NOT NULL DEFAULT true,
+                    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+                    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+                )`,
+        },
+        {
+            name: "coverages",
+            sql: `
+                CREATE TABLE IF NOT EXISTS coverages (
+                    id SERIAL PRIMARY KEY,
+                    tenant_id INTEGER NOT NULL DEFAULT 1 REFERENCES tenants(id),
+                    patient_id INTEGER NOT NULL REFERENCES patients(id),
+                    plan_id INTEGER NOT NULL REFERENCES insurance_plans(id),
+                    member_id VARCHAR(50) NOT NULL,
+                    group_number VARCHAR(50),
+                    subscriber_relationship VARCHAR(20) NOT NULL DEFAULT 'self' CHECK (subscriber_relationship IN ('self', 'spouse', 'child', 'other')),
+                    subscriber_name VARCHAR(255),
+                    subscriber_date_of_birth DATE,
+                    priority INTEGER NOT NULL DEFAULT 1 CHECK (priority BETWEEN 1 AND 3),
+                    effective_from DATE NOT NULL,
+                    effective_to DATE,
+                    created_by INTEGER REFERENCES users(id),
+                    updated_by INTEGER REFERENCES users(id),
+                    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+                    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+                    deleted_at TIMESTAMP,
+                    CHECK (effective_to IS NULL OR effective_to >= effective_from)
+                )`,
+        },
+        {
+            name: "eligibility_checks",
+            sql: `
+                CREATE TABLE IF NOT EXISTS eligibility_checks (
+                    id SERIAL PRIMARY KEY,
+                    tenant_id INTEGER NOT NULL DEFAULT 1 REFERENCES tenants(id),
+                    patient_id INTEGER NOT NULL REFERENCES patients(id),
+                    coverage_id INTEGER NOT NULL REFERENCES coverages(id),
+                    appointment_id INTEGER REFERENCES appointments(id),
+                    service_date DATE NOT NULL,
+                    status VARCHAR(20) NOT NULL CHECK (status IN ('active', 'inactive', 'error')),
+                    copay_cents BIGINT,
+                    deductible_remaining_cents BIGINT,
+                    message TEXT,
+                    source VARCHAR(50) NOT NULL,
+                    checked_by INTEGER REFERENCES users(id),
+                    checked_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+                )`,
+        },
+        {
+            name: "number_sequences",
+            sql: `
+                CREATE TABLE IF NOT EXISTS number_sequences (
+                    id SERIAL PRIMARY KEY,
+                    tenant_id INTEGER NOT NULL DEFAULT 1 REFERENCES tenants(id),
+                    name VARCHAR(50) NOT NULL,
+                    last_value INTEGER NOT NULL DEFAULT 0,
+                    UNIQUE (tenant_id, name)
+                )`,
+        },
+        {
+            name: "invoices",
+            sql: `
+                CREATE TABLE IF NOT EXISTS invoices (
+                    id SERIAL PRIMARY KEY,
+                    tenant_id INTEGER NOT NULL DEFAULT 1 REFERENCES tenants(id),
+                    patient_id INTEGER NOT NULL REFERENCES patients(id),
+                    sequence INTEGER NOT NULL,
+                    number VARCHAR(20) NOT NULL,
+                    status VARCHAR(20) NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'paid', 'void')),
+                    issued_at TIMESTAMP NOT NULL,
+                    due_date TIMESTAMP NOT NULL,
+                    subtotal_cents BIGINT NOT NULL CHECK (subtotal_cents >= 0),
+                    discount_cents BIGINT NOT NULL DEFAULT 0 CHECK (discount_cents >= 0),
+                    discount_reason VARCHAR(255),
+                    tax_rate_basis_points INTEGER NOT NULL DEFAULT 0 CHECK (tax_rate_basis_points >= 0),
+                    tax_cents BIGINT NOT NULL DEFAULT 0,
+                    total_cents BIGINT NOT NULL,
+                    paid_cents BIGINT NOT NULL DEFAULT 0 CHECK (paid_cents >= 0),
+                    balance_cents BIGINT NOT NULL,
+                    notes TEXT,
+                    void_reason TEXT,
+                    created_by INTEGER REFERENCES users(id),
+                    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+                    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+                    UNIQUE (tenant_id, sequence)
+                )`,
+        },
+        {
+            name: "charges",
+            sql: `
+                CREATE TABLE IF NOT EXISTS charges (
+                    id SERIAL PRIMARY KEY,
+                    tenant_id INTEGER NOT NULL DEFAULT 1 REFERENCES tenants(id),
+                    patient_id INTEGER NOT NULL REFERENCES patients(id),
+                    appointment_id INTEGER REFERENCES appointments(id),
+                    invoice_id INTEGER REFERENCES invoices(id),
+                    code VARCHAR(50),
+                    procedure_code VARCHAR(10),
+                    description VARCHAR(255) NOT NULL,
+                    quantity INTEGER NOT NULL DEFAULT 1 CHECK (quantity > 0),
+                    unit_price_cents BIGINT NOT NULL CHECK (unit_price_cents >= 0),
+                    amount_cents BIGINT NOT NULL CHECK (amount_cents >= 0),
+                    service_date DATE NOT NULL,
+                    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'invoiced', 'voided')),
+                    void_reason TEXT,
+                    created_by INTEGER REFERENCES users(id),
+                    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+                    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+                )`,
+        },
+        {
+            name: "payments",
+            sql: `
+                CREATE TABLE IF NOT EXISTS payments (
+                    id SERIAL PRIMARY KEY,
+                    tenant_id INTEGER NOT NULL DEFAULT 1 REFERENCES tenants(id),
+                    invoice_id INTEGER NOT NULL REFERENCES invoices(id),
+                    patient_id INTEGER NOT NULL REFERENCES patients(id),
+                    kind VARCHAR(20) NOT NULL CHECK (kind IN ('payment', 'refund')),
+                    method VARCHAR(20) NOT NULL CHECK (method IN ('cash', 'card', 'check', 'transfer', 'insurance', 'other')),
+                    amount_cents BIGINT NOT NULL CHECK (amount_cents > 0),
+                    reference VARCHAR(100),
+                    notes TEXT,
+                    received_at TIMESTAMP NOT NULL,
+                    recorded_by INTEGER REFERENCES users(id),
+                    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+                )`,
+        },
+        {
+            name: "claim_batches",
+            sql: `
+                CREATE TABLE IF NOT EXISTS claim_batches (
+                    id SERIAL PRIMARY KEY,
+                    tenant_id INTEGER NOT NULL DEFAULT 1 REFERENCES tenants(id),
+                    file_name VARCHAR(255) NOT NULL,
+                    claim_count INTEGER NOT NULL,
+                    total_charge_cents BIGINT NOT NULL,
+                    content TEXT,
+                    created_by INTEGER REFERENCES users(id),
+                    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+                )`,
+        },
+        {
+            name: "claims",
+            sql: `
+                CREATE TABLE IF NOT EXISTS claims (
+                    id SERIAL PRIMARY KEY,
+                    tenant_id INTEGER NOT NULL DEFAULT 1 REFERENCES tenants(id),
+                    patient_id INTEGER NOT NULL REFERENCES patients(id),
+                    appointment_id INTEGER NOT NULL REFERENCES appointments(id),
+                    coverage_id INTEGER REFERENCES coverages(id),
+                    sequence INTEGER NOT NULL,
+                    control_number VARCHAR(20) NOT NULL,
+                    status VARCHAR(20) NOT NULL CHECK (status IN ('invalid', 'ready', 'submitted', 'paid', 'denied')),
+                    validation_errors TEXT,
+                    service_date DATE NOT NULL,
+                    place_of_service VARCHAR(2) NOT NULL,
+                    total_charge_cents BIGINT NOT NULL,
+                    paid_cents BIGINT NOT NULL DEFAULT 0,
+                    adjustment_cents BIGINT NOT NULL DEFAULT 0,
+                    patient_responsibility_cents BIGINT NOT NULL DEFAULT 0,
+                    payer_claim_number VARCHAR(50),
+                    batch_id INTEGER REFERENCES claim_batches(id),
+                    submitted_at TIMESTAMP,
+                    created_by INTEGER REFERENCES users(id),
+                    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+                    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+                    UNIQUE (tenant_id, sequence)
+                )`,
+        },
+        {
+            name: "claim_diagnoses",
+            sql: `
+                CREATE TABLE IF NOT EXISTS claim_diagnoses (
+                    id SERIAL PRIMARY KEY,
+                    claim_id INTEGER NOT NULL REFERENCES claims(id) ON DELETE CASCADE,
+                    position INTEGER NOT NULL CHECK (position BETWEEN 1 AND 12),
+                    code VARCHAR(10) NOT NULL
+                )`,
+        },
+        {
+            name: "claim_lines",
+            sql: `
+                CREATE TABLE IF NOT EXISTS claim_lines (
+                    id SERIAL PRIMARY KEY,
+                    claim_id INTEGER NOT NULL REFERENCES claims(id) ON DELETE CASCADE,
+                    charge_id INTEGER NOT NULL REFERENCES charges(id),
+                    line_number INTEGER NOT NULL,
+                    procedure_code VARCHAR(50),
+                    description VARCHAR(255),
+                    units INTEGER NOT NULL,
+                    charge_cents BIGINT NOT NULL,
+                    diagnosis_pointers VARCHAR(20),
+                    service_date DATE,
+                    paid_cents BIGINT NOT NULL DEFAULT 0,
+                    adjustment_cents BIGINT NOT NULL DEFAULT 0
+                )`,
+        },
+        {
+            name: "remittances",
+            sql: `
+                CREATE TABLE IF NOT EXISTS remittances (
+                    id SERIAL PRIMARY KEY,
+                    tenant_id INTEGER NOT NULL DEFAULT 1 REFERENCES tenants(id),
+                    trace_number VARCHAR(50) NOT NULL,
+                    payer_name VARCHAR(255),
+                    payment_date DATE,
+                    total_paid_cents BIGINT NOT NULL,
+                    file_name VARCHAR(255),
+                    imported_by INTEGER REFERENCES users(id),
+                    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+                    UNIQUE (tenant_id, trace_number)
+                )`,
+        },
+        {
+            name: "remittance_claims",
+            sql: `
+                CREATE TABLE IF NOT EXISTS remittance_claims (
+                    id SERIAL PRIMARY KEY,
+                    remittance_id INTEGER NOT NULL REFERENCES remittances(id) ON DELETE CASCADE,
+                    control_number VARCHAR(20) NOT NULL,
+                    claim_id INTEGER REFERENCES claims(id),
+                    invoice_id INTEGER REFERENCES invoices(id),
+                    payment_id INTEGER REFERENCES payments(id),
+                    status_code VARCHAR(2),
+                    charge_cents BIGINT NOT NULL DEFAULT 0,
+                    paid_cents BIGINT NOT NULL DEFAULT 0,
+                    adjustment_cents BIGINT NOT NULL DEFAULT 0,
+                    patient_responsibility_cents BIGINT NOT NULL DEFAULT 0,
+                    payer_claim_number VARCHAR(50),
+                    outcome VARCHAR(20) NOT NULL CHECK (outcome IN ('posted', 'denied', 'not_posted', 'unmatched')),
+                    note TEXT
+                )`,
+        },
     }
 
     // Create each table
@@ -156,9 +1007,69 @@ func createTables(sqlDB *sql.DB) error {
         log.Printf("  ✓ %s table ready", table.name)
     }
 
+    // Add columns introduced after the original tables were created
+    alterations := []string{
+        "ALTER TABLE appointments ADD COLUMN IF NOT EXISTS appointment_type_id INTEGER REFERENCES appointment_types(id)",
+        "ALTER TABLE appointments ADD COLUMN IF NOT EXISTS duration_minutes INTEGER NOT NULL DEFAULT 30",
+        "ALTER TABLE appointment_types ADD COLUMN IF NOT EXISTS description TEXT",
+        "ALTER TABLE appointment_types ADD COLUMN IF NOT EXISTS duration_minutes INTEGER NOT NULL DEFAULT 30",
+        "ALTER TABLE appointment_types ADD COLUMN IF NOT EXISTS price_cents BIGINT NOT NULL DEFAULT 0",
+        "ALTER TABLE appointment_types ADD COLUMN IF NOT EXISTS preparation_instructions TEXT",
+        "ALTER TABLE appointment_types ADD COLUMN IF NOT EXISTS procedure_code VARCHAR(10)",
+        "ALTER TABLE charges ADD COLUMN IF NOT EXISTS procedure_code VARCHAR(10)",
+        "ALTER TABLE users ADD COLUMN IF NOT EXISTS specialty VARCHAR(100)",
+        "ALTER TABLE appointments ADD COLUMN IF NOT EXISTS location_id INTEGER REFERENCES locations(id)",
+        "ALTER TABLE resources ADD COLUMN IF NOT EXISTS location_id INTEGER REFERENCES locations(id)",
+        "ALTER TABLE patients ADD COLUMN IF NOT EXISTS duplicate_override_reason TEXT",
+        "ALTER TABLE patients ADD COLUMN IF NOT EXISTS mrn VARCHAR(50)",
+        "ALTER TABLE patients ADD COLUMN IF NOT EXISTS registration_location_id INTEGER REFERENCES locations(id)",
+        "ALTER TABLE appointments ADD COLUMN IF NOT EXISTS reminder_sent_at TIMESTAMP",
+
+        // Multi-tenancy: existing rows belong to the default tenant, and
+        // per-practice codes and emails are unique within a tenant only.
+        "INSERT INTO tenants (id, name, slug) VALUES (1, 'Default Practice', 'default') ON CONFLICT (id) DO NOTHING",
+        "SELECT setval('tenants_id_seq', GREATEST((SELECT MAX(id) FROM tenants), 1))",
+        "ALTER TABLE users ADD COLUMN IF NOT EXISTS tenant_id INTEGER NOT NULL DEFAULT 1 REFERENCES tenants(id)",
+        "ALTER TABLE patients ADD COLUMN IF NOT EXISTS tenant_id INTEGER NOT NULL DEFAULT 1 REFERENCES tenants(id)",
+        "ALTER TABLE appointments ADD COLUMN IF NOT EXISTS tenant_id INTEGER NOT NULL DEFAULT 1 REFERENCES tenants(id)",
+        "ALTER TABLE resources ADD COLUMN IF NOT EXISTS tenant_id INTEGER NOT NULL DEFAULT 1 REFERENCES tenants(id)",
+        "ALTER TABLE appointment_types ADD COLUMN IF NOT EXISTS tenant_id INTEGER NOT NULL DEFAULT 1 REFERENCES tenants(id)",
+        "ALTER TABLE locations ADD COLUMN IF NOT EXISTS tenant_id INTEGER NOT NULL DEFAULT 1 REFERENCES tenants(id)",
+        "ALTER TABLE doctor_schedules ADD COLUMN IF NOT EXISTS tenant_id INTEGER NOT NULL DEFAULT 1 REFERENCES tenants(id)",
+        "ALTER TABLE patients DROP CONSTRAINT IF EXISTS patients_email_key",
+        "ALTER TABLE appointment_types DROP CONSTRAINT IF EXISTS appointment_types_code_key",
+        "ALTER TABLE locations DROP CONSTRAINT IF EXISTS locations_code_key",
+        "CREATE UNIQUE INDEX IF NOT EXISTS idx_patients_tenant_email ON patients(tenant_id, email)",
+        "CREATE UNIQUE INDEX IF NOT EXISTS idx_appointment_types_tenant_code ON appointment_types(tenant_id, code)",
+        "CREATE UNIQUE INDEX IF NOT EXISTS idx_locations_tenant_code ON locations(tenant_id, code)",
+        "ALTER TABLE users DROP CONSTRAINT IF EXISTS users_role_check",
+        "ALTER TABLE users ADD CONSTRAINT users_role_check CHECK (role IN ('receptionist', 'doctor', 'nurse', 'admin'))",
+        "ALTER TABLE appointments DROP CONSTRAINT IF EXISTS appointments_status_check",
+        "ALTER TABLE appointments ADD CONSTRAINT appointments_status_check CHECK (status IN ('scheduled', 'checked_in', 'completed', 'cancelled'))",
+
+        // Invoice and claim numbers come from number_sequences; carry on
+        // from the numbers already issued.
+        "INSERT INTO number_sequences (tenant_id, name, last_value) SELECT tenant_id, 'invoice', MAX(sequence) FROM invoices GROUP BY tenant_id ON CONFLICT (tenant_id, name) DO NOTHING",
+        "INSERT INTO number_sequences (tenant_id, name, last_value) SELECT tenant_id, 'claim', MAX(sequence) FROM claims GROUP BY tenant_id ON CONFLICT (tenant_id, name) DO NOTHING",
+    }
+
+    for _, alteration := range alterations {
+        if _, err := sqlDB.Exec(alteration); err != nil {
+            log.Printf("Error altering tables: %v", err)
+            return err
+        }
+    }
+
     // Create indexes
     indexes := []string{
         "CREATE INDEX IF NOT EXISTS idx_users_email ON users(email)",
+        "CREATE INDEX IF NOT EXISTS idx_users_tenant_id ON users(tenant_id)",
+        "CREATE INDEX IF NOT EXISTS idx_patients_tenant_id ON patients(tenant_id)",
+        "CREATE INDEX IF NOT EXISTS idx_appointments_tenant_id_date ON appointments(tenant_id, date)",
+        "CREATE INDEX IF NOT EXISTS idx_resources_tenant_id ON resources(tenant_id)",
+        "CREATE INDEX IF NOT EXISTS idx_appointment_types_tenant_id ON appointment_types(tenant_id)",
+        "CREATE INDEX IF NOT EXISTS idx_locations_tenant_id ON locations(tenant_id)",
+        "CREATE INDEX IF NOT EXISTS idx_doctor_schedules_tenant_id ON doctor_schedules(tenant_id)",
         "CREATE INDEX IF NOT EXISTS idx_users_deleted_at ON users(deleted_at)",
         "CREATE INDEX IF NOT EXISTS idx_patients_email ON patients(email)",
         "CREATE INDEX IF NOT EXISTS idx_patients_phone ON patients(phone)",
@@ -167,6 +1078,118 @@ func createTables(sqlDB *sql.DB) error {
         "CREATE INDEX IF NOT EXISTS idx_appointments_doctor_id ON appointments(doctor_id)",
         "CREATE INDEX IF NOT EXISTS idx_appointments_date ON appointments(date)",
         "CREATE INDEX IF NOT EXISTS idx_appointments_deleted_at ON appointments(deleted_at)",
+        "CREATE INDEX IF NOT EXISTS idx_appointments_appointment_type_id ON appointments(appointment_type_id)",
+        "CREATE INDEX IF NOT EXISTS idx_appointments_location_id_date ON appointments(location_id, date)",
+        "CREATE INDEX IF NOT EXISTS idx_locations_deleted_at ON locations(deleted_at)",
+        "CREATE INDEX IF NOT EXISTS idx_doctor_schedules_doctor_day ON doctor_schedules(doctor_id, day_of_week)",
+        "CREATE INDEX IF NOT EXISTS idx_doctor_schedules_location_id ON doctor_schedules(location_id)",
+        "CREATE INDEX IF NOT EXISTS idx_doctor_schedules_deleted_at ON doctor_schedules(deleted_at)",
+        "CREATE INDEX IF NOT EXISTS idx_resources_location_id ON resources(location_id)",
+        "CREATE INDEX IF NOT EXISTS idx_resources_deleted_at ON resources(deleted_at)",
+        "CREATE INDEX IF NOT EXISTS idx_resource_capabilities_capability ON resource_capabilities(capability)",
+        "CREATE INDEX IF NOT EXISTS idx_appointment_types_deleted_at ON appointment_types(deleted_at)",
+        "CREATE INDEX IF NOT EXISTS idx_appointment_type_requirements_type_id ON appointment_type_requirements(appointment_type_id)",
+        "CREATE INDEX IF NOT EXISTS idx_appointment_type_specialties_type_id ON appointment_type_specialties(appointment_type_id)",
+        "CREATE INDEX IF NOT EXISTS idx_users_specialty ON users(specialty)",
+        "CREATE INDEX IF NOT EXISTS idx_appointment_resources_appointment_id ON appointment_resources(appointment_id)",
+        "CREATE INDEX IF NOT EXISTS idx_appointment_resources_resource_id ON appointment_resources(resource_id)",
+        "CREATE INDEX IF NOT EXISTS idx_encounters_tenant_id ON encounters(tenant_id)",
+        "CREATE INDEX IF NOT EXISTS idx_encounters_patient_visit ON encounters(patient_id, visit_date)",
+        "CREATE INDEX IF NOT EXISTS idx_encounters_doctor_id ON encounters(doctor_id)",
+        "CREATE INDEX IF NOT EXISTS idx_encounters_deleted_at ON encounters(deleted_at)",
+        "CREATE INDEX IF NOT EXISTS idx_encounter_addenda_encounter_id ON encounter_addenda(encounter_id)",
+        "CREATE INDEX IF NOT EXISTS idx_allergies_tenant_id ON allergies(tenant_id)",
+        "CREATE INDEX IF NOT EXISTS idx_allergies_patient_id ON allergies(patient_id)",
+        "CREATE INDEX IF NOT EXISTS idx_allergies_deleted_at ON allergies(deleted_at)",
+        "CREATE INDEX IF NOT EXISTS idx_prescriptions_tenant_id ON prescriptions(tenant_id)",
+        "CREATE INDEX IF NOT EXISTS idx_prescriptions_patient_id ON prescriptions(patient_id)",
+        "CREATE INDEX IF NOT EXISTS idx_prescriptions_encounter_id ON prescriptions(encounter_id)",
+        "CREATE INDEX IF NOT EXISTS idx_prescriptions_deleted_at ON prescriptions(deleted_at)",
+        "CREATE INDEX IF NOT EXISTS idx_prescription_warnings_prescription_id ON prescription_warnings(prescription_id)",
+        "CREATE INDEX IF NOT EXISTS idx_medication_statements_tenant_id ON medication_statements(tenant_id)",
+        "CREATE INDEX IF NOT EXISTS idx_medication_statements_patient_status ON medication_statements(patient_id, status)",
+        "CREATE INDEX IF NOT EXISTS idx_medication_statements_prescription_id ON medication_statements(prescription_id)",
+        "CREATE INDEX IF NOT EXISTS idx_medication_statements_deleted_at ON medication_statements(deleted_at)",
+        "CREATE INDEX IF NOT EXISTS idx_vital_signs_tenant_id ON vital_signs(tenant_id)",
+        "CREATE INDEX IF NOT EXISTS idx_vital_signs_patient_recorded ON vital_signs(patient_id, recorded_at)",
+        "CREATE INDEX IF NOT EXISTS idx_vital_signs_appointment_id ON vital_signs(appointment_id)",
+        "CREATE INDEX IF NOT EXISTS idx_vital_signs_deleted_at ON vital_signs(deleted_at)",
+        "CREATE INDEX IF NOT EXISTS idx_diagnosis_codes_description ON diagnosis_codes(LOWER(description))",
+        "CREATE INDEX IF NOT EXISTS idx_problems_tenant_id ON problems(tenant_id)",
+        "CREATE INDEX IF NOT EXISTS idx_problems_patient_status ON problems(patient_id, status)",
+        "CREATE INDEX IF NOT EXISTS idx_problems_deleted_at ON problems(deleted_at)",
+        "CREATE INDEX IF NOT EXISTS idx_appointment_diagnoses_appointment_id ON appointment_diagnoses(appointment_id)",
+        "CREATE INDEX IF NOT EXISTS idx_lab_orders_tenant_id ON lab_orders(tenant_id)",
+        "CREATE INDEX IF NOT EXISTS idx_lab_orders_patient_id ON lab_orders(patient_id)",
+        "CREATE INDEX IF NOT EXISTS idx_lab_orders_doctor_review ON lab_orders(doctor_id, reviewed_at)",
+        "CREATE INDEX IF NOT EXISTS idx_lab_orders_deleted_at ON lab_orders(deleted_at)",
+        "CREATE INDEX IF NOT EXISTS idx_lab_results_lab_order_id ON lab_results(lab_order_id)",
+        "CREATE INDEX IF NOT EXISTS idx_immunizations_tenant_id ON immunizations(tenant_id)",
+        "CREATE INDEX IF NOT EXISTS idx_immunizations_patient_vaccine ON immunizations(patient_id, vaccine_code)",
+        "CREATE INDEX IF NOT EXISTS idx_immunizations_deleted_at ON immunizations(deleted_at)",
+        "CREATE INDEX IF NOT EXISTS idx_documents_tenant_id ON documents(tenant_id)",
+        "CREATE INDEX IF NOT EXISTS idx_documents_patient_id ON documents(patient_id, category)",
+        "CREATE INDEX IF NOT EXISTS idx_documents_deleted_at ON documents(deleted_at)",
+        "CREATE INDEX IF NOT EXISTS idx_referrals_tenant_id ON referrals(tenant_id)",
+        "CREATE INDEX IF NOT EXISTS idx_referrals_patient_id ON referrals(patient_id)",
+        "CREATE INDEX IF NOT EXISTS idx_referrals_receiving ON referrals(receiving_doctor_id, status)",
+        "CREATE INDEX IF NOT EXISTS idx_referrals_specialty ON referrals(specialty, status) WHERE receiving_doctor_id IS NULL",
+        "CREATE INDEX IF NOT EXISTS idx_referrals_referring ON referrals(referring_doctor_id, status)",
+        "CREATE INDEX IF NOT EXISTS idx_referrals_deleted_at ON referrals(deleted_at)",
+        "CREATE INDEX IF NOT EXISTS idx_care_plans_tenant_id ON care_plans(tenant_id)",
+        "CREATE INDEX IF NOT EXISTS idx_care_plans_patient_id ON care_plans(patient_id)",
+        "CREATE INDEX IF NOT EXISTS idx_care_plans_deleted_at ON care_plans(deleted_at)",
+        "CREATE INDEX IF NOT EXISTS idx_care_plan_goals_care_plan_id ON care_plan_goals(care_plan_id)",
+        "CREATE INDEX IF NOT EXISTS idx_care_tasks_tenant_id ON care_tasks(tenant_id)",
+        "CREATE INDEX IF NOT EXISTS idx_care_tasks_care_plan_id ON care_tasks(care_plan_id)",
+        "CREATE INDEX IF NOT EXISTS idx_care_tasks_assigned_status ON care_tasks(assigned_to, status, due_date)",
+        "CREATE INDEX IF NOT EXISTS idx_care_tasks_pending_due ON care_tasks(due_date) WHERE status = 'pending'",
+        "CREATE INDEX IF NOT EXISTS idx_care_tasks_deleted_at ON care_tasks(deleted_at)",
+        "CREATE INDEX IF NOT EXISTS idx_patient_merges_tenant_id ON patient_merges(tenant_id)",
+        "CREATE INDEX IF NOT EXISTS idx_patient_merges_survivor ON patient_merges(survivor_patient_id)",
+        "CREATE INDEX IF NOT EXISTS idx_patients_tenant_dob ON patients(tenant_id, date_of_birth)",
+        "CREATE INDEX IF NOT EXISTS idx_patients_tenant_last_name ON patients(tenant_id, LOWER(last_name))",
+        "CREATE UNIQUE INDEX IF NOT EXISTS idx_patients_tenant_mrn ON patients(tenant_id, mrn) WHERE mrn IS NOT NULL AND mrn <> ''",
+        "CREATE INDEX IF NOT EXISTS idx_patient_identifiers_patient_id ON patient_identifiers(patient_id)",
+        "CREATE INDEX IF NOT EXISTS idx_patient_identifiers_value ON patient_identifiers(tenant_id, value)",
+        "CREATE INDEX IF NOT EXISTS idx_related_persons_tenant_id ON related_persons(tenant_id)",
+        "CREATE INDEX IF NOT EXISTS idx_related_persons_patient_id ON related_persons(patient_id)",
+        "CREATE INDEX IF NOT EXISTS idx_related_persons_deleted_at ON related_persons(deleted_at)",
+        "CREATE INDEX IF NOT EXISTS idx_consent_texts_tenant_id ON consent_texts(tenant_id)",
+        "CREATE INDEX IF NOT EXISTS idx_consents_tenant_id ON consents(tenant_id)",
+        "CREATE INDEX IF NOT EXISTS idx_consents_patient_type ON consents(patient_id, type)",
+        "CREATE UNIQUE INDEX IF NOT EXISTS idx_consents_one_granted ON consents(patient_id, type) WHERE status = 'granted'",
+        // text_pattern_ops lets the prefix LIKE on words use the index
+        // whatever the database collation.
+        "CREATE INDEX IF NOT EXISTS idx_patient_search_terms_lookup ON patient_search_terms(tenant_id, kind, term text_pattern_ops)",
+        "CREATE INDEX IF NOT EXISTS idx_patient_search_terms_patient_id ON patient_search_terms(patient_id)",
+        "CREATE INDEX IF NOT EXISTS idx_insurance_payers_tenant_id ON insurance_payers(tenant_id)",
+        "CREATE INDEX IF NOT EXISTS idx_insurance_plans_tenant_id ON insurance_plans(tenant_id)",
+        "CREATE INDEX IF NOT EXISTS idx_insurance_plans_payer_id ON insurance_plans(payer_id)",
+        "CREATE INDEX IF NOT EXISTS idx_coverages_tenant_id ON coverages(tenant_id)",
+        "CREATE INDEX IF NOT EXISTS idx_coverages_patient_id ON coverages(patient_id, priority)",
+        "CREATE INDEX IF NOT EXISTS idx_coverages_deleted_at ON coverages(deleted_at)",
+        "CREATE INDEX IF NOT EXISTS idx_eligibility_checks_tenant_id ON eligibility_checks(tenant_id)",
+        "CREATE INDEX IF NOT EXISTS idx_eligibility_checks_appointment_id ON eligibility_checks(appointment_id)",
+        "CREATE INDEX IF NOT EXISTS idx_eligibility_checks_coverage_id ON eligibility_checks(coverage_id)",
+        "CREATE INDEX IF NOT EXISTS idx_invoices_patient_id ON invoices(patient_id, status)",
+        "CREATE INDEX IF NOT EXISTS idx_charges_tenant_id ON charges(tenant_id)",
+        "CREATE INDEX IF NOT EXISTS idx_charges_patient_id ON charges(patient_id, status)",
+        "CREATE INDEX IF NOT EXISTS idx_charges_appointment_id ON charges(appointment_id)",
+        "CREATE UNIQUE INDEX IF NOT EXISTS idx_charges_one_per_appointment ON charges(appointment_id) WHERE status <> 'voided'",
+        "CREATE INDEX IF NOT EXISTS idx_charges_invoice_id ON charges(invoice_id)",
+        "CREATE INDEX IF NOT EXISTS idx_payments_tenant_id ON payments(tenant_id)",
+        "CREATE INDEX IF NOT EXISTS idx_payments_invoice_id ON payments(invoice_id)",
+        "CREATE INDEX IF NOT EXISTS idx_claims_patient_id ON claims(patient_id)",
+        "CREATE INDEX IF NOT EXISTS idx_claims_appointment_id ON claims(appointment_id)",
+        "CREATE INDEX IF NOT EXISTS idx_claims_status ON claims(tenant_id, status)",
+        "CREATE INDEX IF NOT EXISTS idx_claims_control_number ON claims(control_number)",
+        "CREATE INDEX IF NOT EXISTS idx_claims_batch_id ON claims(batch_id)",
+        "CREATE INDEX IF NOT EXISTS idx_claim_diagnoses_claim_id ON claim_diagnoses(claim_id)",
+        "CREATE INDEX IF NOT EXISTS idx_claim_lines_claim_id ON claim_lines(claim_id)",
+        "CREATE INDEX IF NOT EXISTS idx_claim_batches_tenant_id ON claim_batches(tenant_id)",
+        "CREATE INDEX IF NOT EXISTS idx_remittance_claims_remittance_id ON remittance_claims(remittance_id)",
+        "CREATE INDEX IF NOT EXISTS idx_remittance_claims_claim_id ON remittance_claims(claim_id)",
     }
 
     for _, idx := range indexes {
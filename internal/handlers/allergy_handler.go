@@ -0,0 +1,218 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"healthcare-portal/internal/models"
+	"healthcare-portal/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AllergyHandler struct {
+	allergyService services.AllergyService
+}
+
+func NewAllergyHandler(allergyService services.AllergyService) *AllergyHandler {
+	return &AllergyHandler{allergyService: allergyService}
+}
+
+// allergies returns the service scoped to the caller's tenant.
+func (h *AllergyHandler) allergies(c *gin.Context) services.AllergyService {
+	return h.allergyService.WithTenant(tenantID(c))
+}
+
+type AllergyRequest struct {
+	Substance string `json:"substance" binding:"required"`
+	Category  string `json:"category" binding:"required"`
+	Reaction  string `json:"reaction"`
+	Severity  string `json:"severity"`
+	Status    string `json:"status"`
+	Notes     string `json:"notes"`
+}
+
+// @Summary Get Patient Allergies
+// @Description Get a patient's structured allergy list
+// @Tags allergies
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Patient ID"
+// @Success 200 {array} models.Allergy
+// @Router /api/patients/{id}/allergies [get]
+func (h *AllergyHandler) GetPatientAllergies(c *gin.Context) {
+	patientID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid patient ID"})
+		return
+	}
+
+	allergies, err := h.allergies(c).GetPatientAllergies(uint(patientID))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, allergies)
+}
+
+// @Summary Create Allergy
+// @Description Record an allergy for a patient
+// @Tags allergies
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Patient ID"
+// @Param request body AllergyRequest true "Allergy details"
+// @Success 201 {object} models.Allergy
+// @Router /api/patients/{id}/allergies [post]
+func (h *AllergyHandler) CreateAllergy(c *gin.Context) {
+	patientID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid patient ID"})
+		return
+	}
+
+	var req AllergyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	allergy := &models.Allergy{
+		PatientID:  uint(patientID),
+		Substance:  req.Substance,
+		Category:   models.AllergyCategory(req.Category),
+		Reaction:   req.Reaction,
+		Severity:   models.AllergySeverity(req.Severity),
+		Status:     models.AllergyStatus(req.Status),
+		Notes:      req.Notes,
+		RecordedBy: currentUserID(c),
+	}
+
+	if err := h.allergies(c).CreateAllergy(allergy); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, allergy)
+}
+
+// @Summary Import Legacy Allergies
+// @Description Parse the patient's free-text allergies into unconfirmed entries
+// @Tags allergies
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Patient ID"
+// @Success 200 {array} models.Allergy
+// @Router /api/patients/{id}/allergies/import [post]
+func (h *AllergyHandler) ImportLegacyAllergies(c *gin.Context) {
+	patientID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid patient ID"})
+		return
+	}
+
+	imported, err := h.allergies(c).ImportLegacyAllergies(uint(patientID), currentUserID(c))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, imported)
+}
+
+// @Summary Update Allergy
+// @Description Update an allergy entry
+// @Tags allergies
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Allergy ID"
+// @Param request body AllergyRequest true "Updated allergy details"
+// @Success 200 {object} models.Allergy
+// @Router /api/allergies/{id} [put]
+func (h *AllergyHandler) UpdateAllergy(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid allergy ID"})
+		return
+	}
+
+	var req AllergyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	allergy, err := h.allergies(c).GetAllergyByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Allergy not found"})
+		return
+	}
+
+	allergy.Substance = req.Substance
+	allergy.Category = models.AllergyCategory(req.Category)
+	allergy.Reaction = req.Reaction
+	allergy.Severity = models.AllergySeverity(req.Severity)
+	allergy.Status = models.AllergyStatus(req.Status)
+	allergy.Notes = req.Notes
+
+	if err := h.allergies(c).UpdateAllergy(allergy); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, allergy)
+}
+
+// @Summary Confirm Allergy
+// @Description Confirm an imported allergy candidate
+// @Tags allergies
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Allergy ID"
+// @Success 200 {object} models.Allergy
+// @Router /api/allergies/{id}/confirm [post]
+func (h *AllergyHandler) ConfirmAllergy(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid allergy ID"})
+		return
+	}
+
+	allergy, err := h.allergies(c).ConfirmAllergy(uint(id), currentUserID(c))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, allergy)
+}
+
+// @Summary Delete Allergy
+// @Description Delete an allergy entry, e.g. a rejected import candidate
+// @Tags allergies
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Allergy ID"
+// @Success 200 {object} map[string]string
+// @Router /api/allergies/{id} [delete]
+func (h *AllergyHandler) DeleteAllergy(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid allergy ID"})
+		return
+	}
+
+	if err := h.allergies(c).DeleteAllergy(uint(id)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Allergy deleted successfully"})
+}
@@ -21,12 +21,19 @@ func NewAppointmentHandler(appointmentService services.AppointmentService) *Appo
 	}
 }
 
+// appointments returns the service scoped to the caller's tenant.
+func (h *AppointmentHandler) appointments(c *gin.Context) services.AppointmentService {
+	return h.appointmentService.WithTenant(tenantID(c))
+}
+
 type CreateAppointmentRequest struct {
-	PatientID uint   `json:"patient_id" binding:"required"`
-	DoctorID  uint   `json:"doctor_id" binding:"required"`
-	Date      string `json:"date" binding:"required"`
-	Time      string `json:"time" binding:"required"`
-	Notes     string `json:"notes"`
+	PatientID         uint   `json:"patient_id" binding:"required"`
+	DoctorID          uint   `json:"doctor_id" binding:"required"`
+	AppointmentTypeID *uint  `json:"appointment_type_id"`
+	LocationID        *uint  `json:"location_id"`
+	Date              string `json:"date" binding:"required"`
+	Time              string `json:"time" binding:"required"`
+	Notes             string `json:"notes"`
 }
 
 // @Summary Create Appointment
@@ -58,16 +65,18 @@ func (h *AppointmentHandler) CreateAppointment(c *gin.Context) {
 	// We don't need to check here since the service already does these checks
 
 	appointment := &models.Appointment{
-		PatientID: req.PatientID,
-		DoctorID:  req.DoctorID,
-		Date:      date,
-		Time:      req.Time,
-		Notes:     req.Notes,
-		Status:    models.StatusScheduled,
-		CreatedBy: userID.(uint),
+		PatientID:         req.PatientID,
+		DoctorID:          req.DoctorID,
+		AppointmentTypeID: req.AppointmentTypeID,
+		LocationID:        req.LocationID,
+		Date:              date,
+		Time:              req.Time,
+		Notes:             req.Notes,
+		Status:            models.StatusScheduled,
+		CreatedBy:         userID.(uint),
 	}
 
-	if err := h.appointmentService.CreateAppointment(appointment); err != nil {
+	if err := h.appointments(c).CreateAppointment(appointment); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -76,32 +85,32 @@ func (h *AppointmentHandler) CreateAppointment(c *gin.Context) {
 }
 
 // @Summary Get All Appointments
-// @Description Get all appointments with pagination
+// @Description List appointments a page at a time. Filter by status, doctor_id, patient_id, location_id and appointment_type_id (each taking a comma separated list) and date_from/date_to (YYYY-MM-DD); sort by a comma separated list of date, time, status, doctor_id and created_at, each prefixed with - for descending order. Follow meta.next_cursor (or links.next) for the next page.
 // @Tags appointments
 // @Accept json
 // @Produce json
 // @Security BearerAuth
-// @Param page query int false "Page number"
-// @Param limit query int false "Items per page"
+// @Param status query string false "Statuses, e.g. scheduled,checked_in"
+// @Param doctor_id query string false "Doctor IDs"
+// @Param patient_id query string false "Patient IDs"
+// @Param location_id query string false "Location IDs"
+// @Param appointment_type_id query string false "Appointment type IDs"
+// @Param date_from query string false "On or after (YYYY-MM-DD)"
+// @Param date_to query string false "On or before (YYYY-MM-DD)"
+// @Param sort query string false "Sort fields" default(-date,-time)
+// @Param limit query int false "Items per page (at most 100)" default(20)
+// @Param cursor query string false "Cursor from a previous page"
+// @Param page query int false "Page number, for clients not using cursors"
 // @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
 // @Router /api/appointments [get]
 func (h *AppointmentHandler) GetAllAppointments(c *gin.Context) {
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
-	offset := (page - 1) * limit
-
-	appointments, total, err := h.appointmentService.GetAllAppointments(limit, offset)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	req, ok := listRequest(c)
+	if !ok {
 		return
 	}
-
-	c.JSON(http.StatusOK, gin.H{
-		"appointments": appointments,
-		"total":        total,
-		"page":         page,
-		"limit":        limit,
-	})
+	page, err := h.appointments(c).ListAppointments(req)
+	respondList(c, page, err)
 }
 
 // @Summary Get Appointments by Date
@@ -111,6 +120,7 @@ func (h *AppointmentHandler) GetAllAppointments(c *gin.Context) {
 // @Produce json
 // @Security BearerAuth
 // @Param date query string true "Date (YYYY-MM-DD)"
+// @Param location_id query int false "Location ID"
 // @Success 200 {array} models.Appointment
 // @Router /api/appointments/date [get]
 func (h *AppointmentHandler) GetAppointmentsByDate(c *gin.Context) {
@@ -126,7 +136,13 @@ func (h *AppointmentHandler) GetAppointmentsByDate(c *gin.Context) {
 		return
 	}
 
-	appointments, err := h.appointmentService.GetAppointmentsByDate(date)
+	locationID, err := optionalUintQuery(c, "location_id")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid location ID"})
+		return
+	}
+
+	appointments, err := h.appointments(c).GetAppointmentsByDate(date, locationID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -135,6 +151,50 @@ func (h *AppointmentHandler) GetAppointmentsByDate(c *gin.Context) {
 	c.JSON(http.StatusOK, appointments)
 }
 
+// @Summary Find Available Slots
+// @Description Find free start times on a date for an appointment type, honouring doctor schedules and specialty, patient and resource availability
+// @Tags appointments
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param date query string true "Date (YYYY-MM-DD)"
+// @Param appointment_type_id query int false "Appointment type ID"
+// @Param doctor_id query int false "Doctor ID"
+// @Param patient_id query int false "Patient ID"
+// @Param location_id query int false "Location ID"
+// @Success 200 {array} models.AvailableSlot
+// @Router /api/appointments/availability [get]
+func (h *AppointmentHandler) GetAvailableSlots(c *gin.Context) {
+	date, err := time.Parse("2006-01-02", c.Query("date"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date format"})
+		return
+	}
+
+	query := models.SlotQuery{Date: date}
+	for name, target := range map[string]**uint{
+		"appointment_type_id": &query.AppointmentTypeID,
+		"doctor_id":           &query.DoctorID,
+		"patient_id":          &query.PatientID,
+		"location_id":         &query.LocationID,
+	} {
+		id, err := optionalUintQuery(c, name)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid " + name})
+			return
+		}
+		*target = id
+	}
+
+	slots, err := h.appointments(c).FindAvailableSlots(query)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, slots)
+}
+
 // @Summary Get Appointment by ID
 // @Description Get an appointment by ID
 // @Tags appointments
@@ -151,7 +211,7 @@ func (h *AppointmentHandler) GetAppointmentByID(c *gin.Context) {
 		return
 	}
 
-	appointment, err := h.appointmentService.GetAppointmentByID(uint(id))
+	appointment, err := h.appointments(c).GetAppointmentByID(uint(id))
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Appointment not found"})
 		return
@@ -186,12 +246,14 @@ func (h *AppointmentHandler) UpdateAppointmentStatus(c *gin.Context) {
 	}
 
 	status := models.AppointmentStatus(req.Status)
-	if status != models.StatusScheduled && status != models.StatusCompleted && status != models.StatusCancelled {
+	switch status {
+	case models.StatusScheduled, models.StatusCheckedIn, models.StatusCompleted, models.StatusCancelled:
+	default:
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid status"})
 		return
 	}
 
-	if err := h.appointmentService.UpdateAppointmentStatus(uint(id), status); err != nil {
+	if err := h.appointments(c).UpdateAppointmentStatus(uint(id), status); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -199,6 +261,31 @@ func (h *AppointmentHandler) UpdateAppointmentStatus(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Status updated successfully"})
 }
 
+// @Summary Check In Appointment
+// @Description Mark the patient as arrived for a scheduled appointment
+// @Tags appointments
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Appointment ID"
+// @Success 200 {object} models.Appointment
+// @Router /api/appointments/{id}/check-in [post]
+func (h *AppointmentHandler) CheckInAppointment(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid appointment ID"})
+		return
+	}
+
+	appointment, err := h.appointments(c).CheckInAppointment(uint(id))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, appointment)
+}
+
 // @Summary Delete Appointment
 // @Description Delete an appointment (Receptionist only)
 // @Tags appointments
@@ -215,7 +302,7 @@ func (h *AppointmentHandler) DeleteAppointment(c *gin.Context) {
 		return
 	}
 
-	if err := h.appointmentService.DeleteAppointment(uint(id)); err != nil {
+	if err := h.appointments(c).DeleteAppointment(uint(id)); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -239,7 +326,7 @@ func (h *AppointmentHandler) GetPatientAppointments(c *gin.Context) {
 		return
 	}
 
-	appointments, err := h.appointmentService.GetPatientAppointments(uint(patientID))
+	appointments, err := h.appointments(c).GetPatientAppointments(uint(patientID))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -264,7 +351,7 @@ func (h *AppointmentHandler) GetDoctorAppointments(c *gin.Context) {
 		return
 	}
 
-	appointments, err := h.appointmentService.GetDoctorAppointments(uint(doctorID))
+	appointments, err := h.appointments(c).GetDoctorAppointments(uint(doctorID))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
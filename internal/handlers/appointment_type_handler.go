@@ -0,0 +1,248 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"healthcare-portal/internal/models"
+	"healthcare-portal/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AppointmentTypeHandler struct {
+	appointmentTypeService services.AppointmentTypeService
+}
+
+func NewAppointmentTypeHandler(appointmentTypeService services.AppointmentTypeService) *AppointmentTypeHandler {
+	return &AppointmentTypeHandler{appointmentTypeService: appointmentTypeService}
+}
+
+// appointmentTypes returns the service scoped to the caller's tenant.
+func (h *AppointmentTypeHandler) appointmentTypes(c *gin.Context) services.AppointmentTypeService {
+	return h.appointmentTypeService.WithTenant(tenantID(c))
+}
+
+type AppointmentTypeRequirementRequest struct {
+	Capability string `json:"capability" binding:"required"`
+	Quantity   int    `json:"quantity"`
+}
+
+type AppointmentTypeRequest struct {
+	Code                    string                              `json:"code" binding:"required"`
+	Name                    string                              `json:"name" binding:"required"`
+	Description             string                              `json:"description"`
+	DurationMinutes         int                                 `json:"duration_minutes" binding:"required"`
+	PriceCents              int64                               `json:"price_cents"`
+	ProcedureCode           string                              `json:"procedure_code" example:"99213"`
+	PreparationInstructions string                              `json:"preparation_instructions"`
+	IsActive                *bool                               `json:"is_active"`
+	Specialties             []string                            `json:"specialties"`
+	Requirements            []AppointmentTypeRequirementRequest `json:"requirements"`
+}
+
+func (r AppointmentTypeRequest) requirements() []models.AppointmentTypeRequirement {
+	if r.Requirements == nil {
+		return nil
+	}
+	requirements := make([]models.AppointmentTypeRequirement, 0, len(r.Requirements))
+	for _, requirement := range r.Requirements {
+		requirements = append(requirements, models.AppointmentTypeRequirement{
+			Capability: requirement.Capability,
+			Quantity:   requirement.Quantity,
+		})
+	}
+	return requirements
+}
+
+// @Summary Create Appointment Type
+// @Description Create an appointment type with its resource requirements (Admin only)
+// @Tags appointment-types
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body AppointmentTypeRequest true "Appointment type details"
+// @Success 201 {object} models.AppointmentType
+// @Router /api/appointment-types [post]
+func (h *AppointmentTypeHandler) CreateAppointmentType(c *gin.Context) {
+	var req AppointmentTypeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	appointmentType := &models.AppointmentType{
+		Code:                    req.Code,
+		Name:                    req.Name,
+		Description:             req.Description,
+		DurationMinutes:         req.DurationMinutes,
+		PriceCents:              req.PriceCents,
+		ProcedureCode:           req.ProcedureCode,
+		PreparationInstructions: req.PreparationInstructions,
+		IsActive:                true,
+		Requirements:            req.requirements(),
+	}
+	for _, specialty := range req.Specialties {
+		appointmentType.Specialties = append(appointmentType.Specialties, models.AppointmentTypeSpecialty{Specialty: specialty})
+	}
+
+	if err := h.appointmentTypes(c).CreateAppointmentType(appointmentType); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, appointmentType)
+}
+
+// @Summary Get All Appointment Types
+// @Description Get all appointment types
+// @Tags appointment-types
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} models.AppointmentType
+// @Router /api/appointment-types [get]
+func (h *AppointmentTypeHandler) GetAllAppointmentTypes(c *gin.Context) {
+	appointmentTypes, err := h.appointmentTypes(c).GetAllAppointmentTypes()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, appointmentTypes)
+}
+
+// @Summary Get Appointment Type by ID
+// @Description Get an appointment type by ID
+// @Tags appointment-types
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Appointment type ID"
+// @Success 200 {object} models.AppointmentType
+// @Router /api/appointment-types/{id} [get]
+func (h *AppointmentTypeHandler) GetAppointmentTypeByID(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid appointment type ID"})
+		return
+	}
+
+	appointmentType, err := h.appointmentTypes(c).GetAppointmentTypeByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Appointment type not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, appointmentType)
+}
+
+// @Summary Update Appointment Type
+// @Description Update an appointment type and replace its requirements when given (Admin only)
+// @Tags appointment-types
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Appointment type ID"
+// @Param request body AppointmentTypeRequest true "Updated appointment type details"
+// @Success 200 {object} models.AppointmentType
+// @Router /api/appointment-types/{id} [put]
+func (h *AppointmentTypeHandler) UpdateAppointmentType(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid appointment type ID"})
+		return
+	}
+
+	var req AppointmentTypeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	appointmentType, err := h.appointmentTypes(c).GetAppointmentTypeByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Appointment type not found"})
+		return
+	}
+
+	appointmentType.Code = req.Code
+	appointmentType.Name = req.Name
+	appointmentType.Description = req.Description
+	appointmentType.DurationMinutes = req.DurationMinutes
+	appointmentType.PriceCents = req.PriceCents
+	appointmentType.ProcedureCode = req.ProcedureCode
+	appointmentType.PreparationInstructions = req.PreparationInstructions
+	if req.IsActive != nil {
+		appointmentType.IsActive = *req.IsActive
+	}
+
+	if err := h.appointmentTypes(c).UpdateAppointmentType(appointmentType, req.requirements(), req.Specialties); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	updated, err := h.appointmentTypes(c).GetAppointmentTypeByID(appointmentType.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, updated)
+}
+
+// @Summary Delete Appointment Type
+// @Description Delete an appointment type (Admin only)
+// @Tags appointment-types
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Appointment type ID"
+// @Success 200 {object} map[string]string
+// @Router /api/appointment-types/{id} [delete]
+func (h *AppointmentTypeHandler) DeleteAppointmentType(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid appointment type ID"})
+		return
+	}
+
+	if err := h.appointmentTypes(c).DeleteAppointmentType(uint(id)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Appointment type deleted successfully"})
+}
+
+// @Summary Appointment Type Report
+// @Description Count appointments and completed-visit revenue per appointment type (Admin only)
+// @Tags appointment-types
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param from query string true "Start date (YYYY-MM-DD)"
+// @Param to query string true "End date, exclusive (YYYY-MM-DD)"
+// @Success 200 {array} models.AppointmentTypeReport
+// @Router /api/appointment-types/report [get]
+func (h *AppointmentTypeHandler) GetReport(c *gin.Context) {
+	from, err := time.Parse("2006-01-02", c.Query("from"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid from date"})
+		return
+	}
+	to, err := time.Parse("2006-01-02", c.Query("to"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid to date"})
+		return
+	}
+
+	report, err := h.appointmentTypes(c).GetReport(from, to)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
@@ -14,7 +14,9 @@ type AuthHandler struct {
 }
 
 func NewAuthHandler(authService services.AuthService) *AuthHandler {
-	return &AuthHandler{authService: authService}
+	return &AuthHandler{
+		authService: authService,
+	}
 }
 
 type LoginRequest struct {
@@ -55,17 +57,19 @@ func (h *AuthHandler) Login(c *gin.Context) {
 }
 
 type RegisterRequest struct {
-	Email    string          `json:"email" binding:"required,email"`
-	Password string          `json:"password" binding:"required,min=6"`
-	Name     string          `json:"name" binding:"required"`
-	Role     models.UserRole `json:"role" binding:"required"`
+	Email     string          `json:"email" binding:"required,email"`
+	Password  string          `json:"password" binding:"required,min=6"`
+	Name      string          `json:"name" binding:"required"`
+	Role      models.UserRole `json:"role" binding:"required"`
+	Specialty string          `json:"specialty"`
 }
 
 // @Summary Register
-// @Description Register a new user
+// @Description Create a user account in the calling admin's practice. The practice always comes from the admin's token, never from the request.
 // @Tags auth
 // @Accept json
 // @Produce json
+// @Security BearerAuth
 // @Param request body RegisterRequest true "Registration details"
 // @Success 201 {object} models.User
 // @Router /api/auth/register [post]
@@ -77,10 +81,12 @@ func (h *AuthHandler) Register(c *gin.Context) {
 	}
 
 	user := &models.User{
-		Email:    req.Email,
-		Password: req.Password,
-		Name:     req.Name,
-		Role:     req.Role,
+		TenantID:  tenantID(c),
+		Email:     req.Email,
+		Password:  req.Password,
+		Name:      req.Name,
+		Role:      req.Role,
+		Specialty: req.Specialty,
 	}
 
 	if err := h.authService.Register(user); err != nil {
@@ -106,9 +112,10 @@ func (h *AuthHandler) GetCurrentUser(c *gin.Context) {
 	role, _ := c.Get("role")
 
 	user := models.User{
-		ID:    userID.(uint),
-		Email: email.(string),
-		Role:  models.UserRole(role.(string)),
+		ID:       userID.(uint),
+		TenantID: tenantID(c),
+		Email:    email.(string),
+		Role:     models.UserRole(role.(string)),
 	}
 
 	c.JSON(http.StatusOK, user)
@@ -0,0 +1,370 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"healthcare-portal/internal/models"
+	"healthcare-portal/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type BillingHandler struct {
+	billingService services.BillingService
+}
+
+func NewBillingHandler(billingService services.BillingService) *BillingHandler {
+	return &BillingHandler{billingService: billingService}
+}
+
+// billing returns the service scoped to the caller's tenant.
+func (h *BillingHandler) billing(c *gin.Context) services.BillingService {
+	return h.billingService.WithTenant(tenantID(c))
+}
+
+type ChargeRequest struct {
+	Code           string `json:"code" example:"99070"`
+	Description    string `json:"description" binding:"required"`
+	Quantity       int    `json:"quantity" example:"1"`
+	UnitPriceCents int64  `json:"unit_price_cents" example:"2500"`
+	ServiceDate    string `json:"service_date" example:"2026-03-14"`
+}
+
+type InvoiceRequest struct {
+	// ChargeIDs picks the pending charges to invoice; every pending charge
+	// is invoiced when it is left out.
+	ChargeIDs          []uint `json:"charge_ids"`
+	DiscountCents      int64  `json:"discount_cents"`
+	DiscountReason     string `json:"discount_reason"`
+	TaxRateBasisPoints *int   `json:"tax_rate_basis_points" example:"825"`
+	DueDate            string `json:"due_date" example:"2026-04-13"`
+	Notes              string `json:"notes"`
+}
+
+type PaymentRequest struct {
+	Kind        string `json:"kind" example:"payment"`
+	Method      string `json:"method" binding:"required" example:"card"`
+	AmountCents int64  `json:"amount_cents" binding:"required" example:"5000"`
+	Reference   string `json:"reference"`
+	Notes       string `json:"notes"`
+}
+
+type VoidRequest struct {
+	Reason string `json:"reason" binding:"required"`
+}
+
+// billingError maps service errors to HTTP status codes.
+func billingError(c *gin.Context, err error) {
+	status := http.StatusBadRequest
+	switch {
+	case errors.Is(err, services.ErrChargeNotFound), errors.Is(err, services.ErrInvoiceNotFound),
+		errors.Is(err, services.ErrPatientNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, services.ErrChargeNotPending), errors.Is(err, services.ErrInvoiceVoid),
+		errors.Is(err, services.ErrNothingToInvoice), errors.Is(err, services.ErrInvoiceHasPayments),
+		errors.Is(err, services.ErrPaymentExceedsOwed), errors.Is(err, services.ErrRefundExceedsPaid),
+		errors.Is(err, services.ErrChargesNotAvailable):
+		status = http.StatusConflict
+	}
+	c.JSON(status, gin.H{"error": err.Error()})
+}
+
+// @Summary Get Patient Charges
+// @Description List a patient's charges, oldest service first
+// @Tags billing
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Patient ID"
+// @Param status query string false "Only charges in this status (pending, invoiced, voided)"
+// @Success 200 {array} models.Charge
+// @Router /api/patients/{id}/charges [get]
+func (h *BillingHandler) GetPatientCharges(c *gin.Context) {
+	patientID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid patient ID"})
+		return
+	}
+
+	charges, err := h.billing(c).GetPatientCharges(uint(patientID), models.ChargeStatus(c.Query("status")))
+	if err != nil {
+		billingError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, charges)
+}
+
+// @Summary Create Charge
+// @Description Add a charge to a patient's account, such as supplies. Completed appointments are charged automatically.
+// @Tags billing
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Patient ID"
+// @Param request body ChargeRequest true "Charge details"
+// @Success 201 {object} models.Charge
+// @Router /api/patients/{id}/charges [post]
+func (h *BillingHandler) CreateCharge(c *gin.Context) {
+	patientID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid patient ID"})
+		return
+	}
+
+	var req ChargeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	serviceDate, err := parseOptionalDate(req.ServiceDate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid service_date"})
+		return
+	}
+
+	charge := &models.Charge{
+		PatientID:      uint(patientID),
+		Code:           req.Code,
+		Description:    req.Description,
+		Quantity:       req.Quantity,
+		UnitPriceCents: req.UnitPriceCents,
+		CreatedBy:      currentUserID(c),
+	}
+	if serviceDate != nil {
+		charge.ServiceDate = *serviceDate
+	}
+	if err := h.billing(c).CreateCharge(charge); err != nil {
+		billingError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, charge)
+}
+
+// @Summary Void Charge
+// @Description Cancel a pending charge made in error. Invoiced charges are released by voiding their invoice.
+// @Tags billing
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Charge ID"
+// @Param request body VoidRequest true "Why the charge is voided"
+// @Success 200 {object} models.Charge
+// @Router /api/charges/{id}/void [post]
+func (h *BillingHandler) VoidCharge(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid charge ID"})
+		return
+	}
+
+	var req VoidRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	charge, err := h.billing(c).VoidCharge(uint(id), req.Reason)
+	if err != nil {
+		billingError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, charge)
+}
+
+// @Summary Get Patient Invoices
+// @Description List a patient's invoices, newest first
+// @Tags billing
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Patient ID"
+// @Success 200 {array} models.Invoice
+// @Router /api/patients/{id}/invoices [get]
+func (h *BillingHandler) GetPatientInvoices(c *gin.Context) {
+	patientID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid patient ID"})
+		return
+	}
+
+	invoices, err := h.billing(c).GetPatientInvoices(uint(patientID))
+	if err != nil {
+		billingError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, invoices)
+}
+
+// @Summary Create Invoice
+// @Description Invoice a patient's pending charges with an optional discount. Tax defaults to the configured rate.
+// @Tags billing
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Patient ID"
+// @Param request body InvoiceRequest true "Invoice details"
+// @Success 201 {object} models.Invoice
+// @Router /api/patients/{id}/invoices [post]
+func (h *BillingHandler) CreateInvoice(c *gin.Context) {
+	patientID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid patient ID"})
+		return
+	}
+
+	var req InvoiceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	dueDate, err := parseOptionalDate(req.DueDate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid due_date"})
+		return
+	}
+
+	invoice, err := h.billing(c).CreateInvoice(services.InvoiceDraft{
+		PatientID:          uint(patientID),
+		ChargeIDs:          req.ChargeIDs,
+		DiscountCents:      req.DiscountCents,
+		DiscountReason:     req.DiscountReason,
+		TaxRateBasisPoints: req.TaxRateBasisPoints,
+		DueDate:            dueDate,
+		Notes:              req.Notes,
+		CreatedBy:          currentUserID(c),
+	})
+	if err != nil {
+		billingError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, invoice)
+}
+
+// @Summary Get Invoice
+// @Description Get an invoice with its charges and payments
+// @Tags billing
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Invoice ID"
+// @Success 200 {object} models.Invoice
+// @Router /api/invoices/{id} [get]
+func (h *BillingHandler) GetInvoiceByID(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid invoice ID"})
+		return
+	}
+
+	invoice, err := h.billing(c).GetInvoiceByID(uint(id))
+	if err != nil {
+		billingError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, invoice)
+}
+
+// @Summary Void Invoice
+// @Description Cancel an invoice raised in error and return its charges to pending. Payments must be refunded first.
+// @Tags billing
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Invoice ID"
+// @Param request body VoidRequest true "Why the invoice is voided"
+// @Success 200 {object} models.Invoice
+// @Router /api/invoices/{id}/void [post]
+func (h *BillingHandler) VoidInvoice(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid invoice ID"})
+		return
+	}
+
+	var req VoidRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	invoice, err := h.billing(c).VoidInvoice(uint(id), req.Reason)
+	if err != nil {
+		billingError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, invoice)
+}
+
+// @Summary Record Payment
+// @Description Record a payment against an invoice, or a refund with kind "refund", and return the updated invoice
+// @Tags billing
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Invoice ID"
+// @Param request body PaymentRequest true "Payment details"
+// @Success 201 {object} models.Invoice
+// @Router /api/invoices/{id}/payments [post]
+func (h *BillingHandler) RecordPayment(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid invoice ID"})
+		return
+	}
+
+	var req PaymentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	invoice, err := h.billing(c).RecordPayment(&models.Payment{
+		InvoiceID:   uint(id),
+		Kind:        models.PaymentKind(req.Kind),
+		Method:      models.PaymentMethod(req.Method),
+		AmountCents: req.AmountCents,
+		Reference:   req.Reference,
+		Notes:       req.Notes,
+		RecordedBy:  currentUserID(c),
+	})
+	if err != nil {
+		billingError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, invoice)
+}
+
+// @Summary Get Patient Balance
+// @Description Sum a patient's invoices, payments and charges not yet invoiced
+// @Tags billing
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Patient ID"
+// @Success 200 {object} models.PatientBalance
+// @Router /api/patients/{id}/balance [get]
+func (h *BillingHandler) GetPatientBalance(c *gin.Context) {
+	patientID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid patient ID"})
+		return
+	}
+
+	balance, err := h.billing(c).GetPatientBalance(uint(patientID))
+	if err != nil {
+		billingError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, balance)
+}
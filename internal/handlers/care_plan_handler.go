@@ -0,0 +1,631 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"healthcare-portal/internal/models"
+	"healthcare-portal/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CarePlanHandler struct {
+	carePlanService services.CarePlanService
+}
+
+func NewCarePlanHandler(carePlanService services.CarePlanService) *CarePlanHandler {
+	return &CarePlanHandler{carePlanService: carePlanService}
+}
+
+// carePlans returns the service scoped to the caller's tenant.
+func (h *CarePlanHandler) carePlans(c *gin.Context) services.CarePlanService {
+	return h.carePlanService.WithTenant(tenantID(c))
+}
+
+type CarePlanRequest struct {
+	Title       string            `json:"title" binding:"required" example:"Type 2 diabetes"`
+	Description string            `json:"description"`
+	ProblemID   *uint             `json:"problem_id"`
+	Goals       []CareGoalRequest `json:"goals"`
+	Tasks       []CareTaskRequest `json:"tasks"`
+}
+
+type UpdateCarePlanRequest struct {
+	Title       string `json:"title" binding:"required"`
+	Description string `json:"description"`
+	Status      string `json:"status" example:"active"`
+}
+
+type CareGoalRequest struct {
+	Description string `json:"description" binding:"required" example:"HbA1c below target"`
+	Target      string `json:"target" example:"< 7%"`
+	TargetDate  string `json:"target_date" example:"2025-06-30"`
+	Status      string `json:"status" example:"in_progress"`
+}
+
+// CareTaskRequest describes a follow-up. The due date is given either as a
+// date or relative to today with due_in, e.g. "3m" or "6w".
+type CareTaskRequest struct {
+	Title             string `json:"title" binding:"required" example:"Repeat HbA1c"`
+	Description       string `json:"description"`
+	AssignedTo        *uint  `json:"assigned_to"`
+	DueDate           string `json:"due_date" example:"2025-06-01"`
+	DueIn             string `json:"due_in" example:"3m"`
+	EarliestDate      string `json:"earliest_date" example:"2025-05-15"`
+	DoctorID          *uint  `json:"doctor_id"`
+	AppointmentTypeID *uint  `json:"appointment_type_id"`
+}
+
+type BookCareTaskRequest struct {
+	DoctorID          uint   `json:"doctor_id"`
+	AppointmentTypeID *uint  `json:"appointment_type_id"`
+	LocationID        *uint  `json:"location_id"`
+	Date              string `json:"date" binding:"required" example:"2025-05-20"`
+	Time              string `json:"time" binding:"required" example:"09:30"`
+	Notes             string `json:"notes"`
+}
+
+func (r CareGoalRequest) goal() (models.CarePlanGoal, error) {
+	targetDate, err := parseOptionalDate(r.TargetDate)
+	if err != nil {
+		return models.CarePlanGoal{}, errors.New("invalid target_date format. Use YYYY-MM-DD")
+	}
+	return models.CarePlanGoal{
+		Description: r.Description,
+		Target:      r.Target,
+		TargetDate:  targetDate,
+		Status:      models.CareGoalStatus(r.Status),
+	}, nil
+}
+
+func (r CareTaskRequest) task(c *gin.Context) (models.CareTask, error) {
+	task := models.CareTask{
+		Title:             r.Title,
+		Description:       r.Description,
+		AssignedTo:        r.AssignedTo,
+		DoctorID:          r.DoctorID,
+		AppointmentTypeID: r.AppointmentTypeID,
+		CreatedBy:         currentUserID(c),
+	}
+
+	switch {
+	case r.DueDate != "" && r.DueIn != "":
+		return task, errors.New("give either due_date or due_in, not both")
+	case r.DueIn != "":
+		interval, err := services.ParseAgeInterval(r.DueIn)
+		if err != nil {
+			return task, fmt.Errorf("invalid due_in: %w", err)
+		}
+		now := time.Now().UTC()
+		task.DueDate = interval.From(time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC))
+	default:
+		dueDate, err := parseOptionalDate(r.DueDate)
+		if err != nil {
+			return task, errors.New("invalid due_date format. Use YYYY-MM-DD")
+		}
+		if dueDate != nil {
+			task.DueDate = *dueDate
+		}
+	}
+
+	earliest, err := parseOptionalDate(r.EarliestDate)
+	if err != nil {
+		return task, errors.New("invalid earliest_date format. Use YYYY-MM-DD")
+	}
+	if earliest != nil {
+		task.EarliestDate = *earliest
+	}
+	return task, nil
+}
+
+// carePlanError maps service errors to HTTP status codes.
+func carePlanError(c *gin.Context, err error) {
+	status := http.StatusBadRequest
+	switch {
+	case errors.Is(err, services.ErrCarePlanNotFound), errors.Is(err, services.ErrCareTaskNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, services.ErrCareTaskClosed):
+		status = http.StatusConflict
+	}
+	c.JSON(status, gin.H{"error": err.Error()})
+}
+
+// @Summary Create Care Plan
+// @Description Create a care plan with goals and follow-up tasks for a patient
+// @Tags care-plans
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Patient ID"
+// @Param request body CarePlanRequest true "Care plan"
+// @Success 201 {object} models.CarePlan
+// @Router /api/patients/{id}/care-plans [post]
+func (h *CarePlanHandler) CreateCarePlan(c *gin.Context) {
+	patientID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid patient ID"})
+		return
+	}
+
+	var req CarePlanRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	plan := &models.CarePlan{
+		PatientID:   uint(patientID),
+		ProblemID:   req.ProblemID,
+		Title:       req.Title,
+		Description: req.Description,
+		CreatedBy:   currentUserID(c),
+	}
+	for _, goalReq := range req.Goals {
+		goal, err := goalReq.goal()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		plan.Goals = append(plan.Goals, goal)
+	}
+	for _, taskReq := range req.Tasks {
+		task, err := taskReq.task(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		plan.Tasks = append(plan.Tasks, task)
+	}
+
+	if err := h.carePlans(c).CreateCarePlan(plan); err != nil {
+		carePlanError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, plan)
+}
+
+// @Summary Get Patient Care Plans
+// @Description Get a patient's care plans with their goals and tasks
+// @Tags care-plans
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Patient ID"
+// @Param status query string false "active, completed or cancelled"
+// @Success 200 {array} models.CarePlan
+// @Router /api/patients/{id}/care-plans [get]
+func (h *CarePlanHandler) GetPatientCarePlans(c *gin.Context) {
+	patientID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid patient ID"})
+		return
+	}
+
+	plans, err := h.carePlans(c).GetPatientCarePlans(uint(patientID), models.CarePlanStatus(c.Query("status")))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, plans)
+}
+
+// @Summary Get Care Plan
+// @Description Get a care plan with its goals and tasks
+// @Tags care-plans
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Care plan ID"
+// @Success 200 {object} models.CarePlan
+// @Router /api/care-plans/{id} [get]
+func (h *CarePlanHandler) GetCarePlanByID(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid care plan ID"})
+		return
+	}
+
+	plan, err := h.carePlans(c).GetCarePlanByID(uint(id))
+	if err != nil {
+		carePlanError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, plan)
+}
+
+// @Summary Update Care Plan
+// @Description Update a care plan. Completing or cancelling it cancels its open tasks.
+// @Tags care-plans
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Care plan ID"
+// @Param request body UpdateCarePlanRequest true "Care plan"
+// @Success 200 {object} models.CarePlan
+// @Router /api/care-plans/{id} [put]
+func (h *CarePlanHandler) UpdateCarePlan(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid care plan ID"})
+		return
+	}
+
+	var req UpdateCarePlanRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	plan := &models.CarePlan{ID: uint(id), Title: req.Title, Description: req.Description, Status: models.CarePlanStatus(req.Status)}
+	if err := h.carePlans(c).UpdateCarePlan(plan); err != nil {
+		carePlanError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, plan)
+}
+
+// @Summary Add Care Plan Goal
+// @Description Add a goal to a care plan
+// @Tags care-plans
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Care plan ID"
+// @Param request body CareGoalRequest true "Goal"
+// @Success 201 {object} models.CarePlanGoal
+// @Router /api/care-plans/{id}/goals [post]
+func (h *CarePlanHandler) AddGoal(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid care plan ID"})
+		return
+	}
+
+	var req CareGoalRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	goal, err := req.goal()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.carePlans(c).AddGoal(uint(id), &goal); err != nil {
+		carePlanError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, goal)
+}
+
+// @Summary Update Care Plan Goal
+// @Description Update a goal, e.g. to mark it achieved
+// @Tags care-plans
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Care plan ID"
+// @Param goalId path int true "Goal ID"
+// @Param request body CareGoalRequest true "Goal"
+// @Success 200 {object} models.CarePlanGoal
+// @Router /api/care-plans/{id}/goals/{goalId} [put]
+func (h *CarePlanHandler) UpdateGoal(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid care plan ID"})
+		return
+	}
+	goalID, err := strconv.ParseUint(c.Param("goalId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid goal ID"})
+		return
+	}
+
+	var req CareGoalRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	goal, err := req.goal()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	goal.ID = uint(goalID)
+
+	if err := h.carePlans(c).UpdateGoal(uint(id), &goal); err != nil {
+		carePlanError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, goal)
+}
+
+// @Summary Add Care Task
+// @Description Add a follow-up task to a care plan
+// @Tags care-plans
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Care plan ID"
+// @Param request body CareTaskRequest true "Task"
+// @Success 201 {object} models.CareTask
+// @Router /api/care-plans/{id}/tasks [post]
+func (h *CarePlanHandler) AddTask(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid care plan ID"})
+		return
+	}
+
+	var req CareTaskRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	task, err := req.task(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.carePlans(c).AddTask(uint(id), &task); err != nil {
+		carePlanError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, task)
+}
+
+// @Summary Get Care Tasks
+// @Description Get follow-up tasks, soonest due first. Defaults to the caller's open tasks.
+// @Tags care-tasks
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param assigned_to query string false "me (default), all, or a user ID"
+// @Param status query string false "Comma-separated statuses, defaults to pending,scheduled,overdue"
+// @Param patient_id query int false "Patient ID"
+// @Param due_before query string false "Only tasks due before this date (YYYY-MM-DD)"
+// @Success 200 {array} models.CareTask
+// @Router /api/care-tasks [get]
+func (h *CarePlanHandler) GetTasks(c *gin.Context) {
+	var filter models.CareTaskFilter
+
+	switch assignedTo := c.DefaultQuery("assigned_to", "me"); assignedTo {
+	case "me":
+		userID := currentUserID(c)
+		filter.AssignedTo = &userID
+	case "all":
+	default:
+		id, err := strconv.ParseUint(assignedTo, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "assigned_to must be me, all or a user ID"})
+			return
+		}
+		userID := uint(id)
+		filter.AssignedTo = &userID
+	}
+
+	patientID, err := optionalUintQuery(c, "patient_id")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid patient ID"})
+		return
+	}
+	filter.PatientID = patientID
+
+	filter.DueBefore, err = parseOptionalDate(c.Query("due_before"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date format. Use YYYY-MM-DD"})
+		return
+	}
+
+	statuses := c.DefaultQuery("status", "pending,scheduled,overdue")
+	for _, status := range strings.Split(statuses, ",") {
+		filter.Statuses = append(filter.Statuses, models.CareTaskStatus(strings.TrimSpace(status)))
+	}
+
+	tasks, err := h.carePlans(c).GetTasks(filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, tasks)
+}
+
+// @Summary Get Care Task
+// @Description Get a follow-up task
+// @Tags care-tasks
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Task ID"
+// @Success 200 {object} models.CareTask
+// @Router /api/care-tasks/{id} [get]
+func (h *CarePlanHandler) GetTaskByID(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task ID"})
+		return
+	}
+
+	task, err := h.carePlans(c).GetTaskByID(uint(id))
+	if err != nil {
+		carePlanError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, task)
+}
+
+// @Summary Update Care Task
+// @Description Reassign or reschedule an open follow-up task
+// @Tags care-tasks
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Task ID"
+// @Param request body CareTaskRequest true "Task"
+// @Success 200 {object} models.CareTask
+// @Router /api/care-tasks/{id} [put]
+func (h *CarePlanHandler) UpdateTask(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task ID"})
+		return
+	}
+
+	var req CareTaskRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	task, err := req.task(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	task.ID = uint(id)
+
+	if err := h.carePlans(c).UpdateTask(&task); err != nil {
+		carePlanError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, task)
+}
+
+// @Summary Complete Care Task
+// @Description Mark a follow-up task as done
+// @Tags care-tasks
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Task ID"
+// @Success 200 {object} models.CareTask
+// @Router /api/care-tasks/{id}/complete [post]
+func (h *CarePlanHandler) CompleteTask(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task ID"})
+		return
+	}
+
+	task, err := h.carePlans(c).CompleteTask(uint(id), currentUserID(c))
+	if err != nil {
+		carePlanError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, task)
+}
+
+// @Summary Cancel Care Task
+// @Description Cancel a follow-up task that is no longer needed
+// @Tags care-tasks
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Task ID"
+// @Success 200 {object} models.CareTask
+// @Router /api/care-tasks/{id}/cancel [post]
+func (h *CarePlanHandler) CancelTask(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task ID"})
+		return
+	}
+
+	task, err := h.carePlans(c).CancelTask(uint(id))
+	if err != nil {
+		carePlanError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, task)
+}
+
+// @Summary Suggest Appointments
+// @Description Suggest free appointment slots inside the task's window, with its doctor and appointment type
+// @Tags care-tasks
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Task ID"
+// @Param limit query int false "Maximum number of suggestions (default 5)"
+// @Success 200 {array} models.SuggestedAppointment
+// @Router /api/care-tasks/{id}/suggested-appointments [get]
+func (h *CarePlanHandler) SuggestAppointments(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task ID"})
+		return
+	}
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "5"))
+
+	suggestions, err := h.carePlans(c).SuggestAppointments(uint(id), time.Now(), limit)
+	if err != nil {
+		carePlanError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, suggestions)
+}
+
+// @Summary Book Care Task Appointment
+// @Description Book the follow-up visit for a task, typically one of its suggested slots
+// @Tags care-tasks
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Task ID"
+// @Param request body BookCareTaskRequest true "Appointment slot"
+// @Success 201 {object} models.CareTask
+// @Router /api/care-tasks/{id}/book [post]
+func (h *CarePlanHandler) BookTask(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task ID"})
+		return
+	}
+
+	var req BookCareTaskRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	date, err := time.Parse("2006-01-02", req.Date)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date format. Use YYYY-MM-DD"})
+		return
+	}
+
+	appointment := &models.Appointment{
+		DoctorID:          req.DoctorID,
+		AppointmentTypeID: req.AppointmentTypeID,
+		LocationID:        req.LocationID,
+		Date:              date,
+		Time:              req.Time,
+		Notes:             req.Notes,
+		CreatedBy:         currentUserID(c),
+	}
+	task, err := h.carePlans(c).BookTask(uint(id), appointment)
+	if err != nil {
+		carePlanError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, task)
+}
@@ -0,0 +1,323 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"healthcare-portal/internal/models"
+	"healthcare-portal/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxRemittanceBytes bounds uploaded 835 files; a busy practice's weekly
+// remittance is well under a megabyte.
+const maxRemittanceBytes = 10 << 20
+
+type ClaimHandler struct {
+	claimService services.ClaimService
+}
+
+func NewClaimHandler(claimService services.ClaimService) *ClaimHandler {
+	return &ClaimHandler{claimService: claimService}
+}
+
+// claims returns the service scoped to the caller's tenant.
+func (h *ClaimHandler) claims(c *gin.Context) services.ClaimService {
+	return h.claimService.WithTenant(tenantID(c))
+}
+
+type ClaimBatchRequest struct {
+	// ClaimIDs picks the claims to submit; every ready claim is batched
+	// when it is left out.
+	ClaimIDs []uint `json:"claim_ids"`
+}
+
+// claimError maps service errors to HTTP status codes.
+func claimError(c *gin.Context, err error) {
+	status := http.StatusBadRequest
+	switch {
+	case errors.Is(err, services.ErrClaimNotFound), errors.Is(err, services.ErrClaimBatchNotFound),
+		errors.Is(err, services.ErrRemittanceNotFound), errors.Is(err, services.ErrAppointmentNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, services.ErrAppointmentNotCompleted), errors.Is(err, services.ErrClaimAlreadySubmitted),
+		errors.Is(err, services.ErrNothingToSubmit), errors.Is(err, services.ErrClaimsNotReady),
+		errors.Is(err, services.ErrRemittanceImported):
+		status = http.StatusConflict
+	case errors.Is(err, services.ErrInvalidRemittance):
+		status = http.StatusUnprocessableEntity
+	}
+	c.JSON(status, gin.H{"error": err.Error()})
+}
+
+// @Summary Generate Claim
+// @Description Build and validate the professional claim for a completed appointment from its charges, diagnoses and the patient's primary coverage. Claims failing validation are saved as invalid with the reasons; generate again after fixing the data.
+// @Tags claims
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Appointment ID"
+// @Success 201 {object} models.Claim
+// @Router /api/appointments/{id}/claim [post]
+func (h *ClaimHandler) GenerateClaim(c *gin.Context) {
+	appointmentID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid appointment ID"})
+		return
+	}
+
+	claim, err := h.claims(c).GenerateClaim(uint(appointmentID), currentUserID(c))
+	if err != nil {
+		claimError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, claim)
+}
+
+// @Summary Get Claims
+// @Description List claims, newest first
+// @Tags claims
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param status query string false "Only claims in this status (invalid, ready, submitted, paid, denied)"
+// @Success 200 {array} models.Claim
+// @Router /api/claims [get]
+func (h *ClaimHandler) GetClaims(c *gin.Context) {
+	claims, err := h.claims(c).GetClaims(models.ClaimStatus(c.Query("status")))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, claims)
+}
+
+// @Summary Get Claim
+// @Description Get a claim with its lines, diagnoses, patient and coverage
+// @Tags claims
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Claim ID"
+// @Success 200 {object} models.Claim
+// @Router /api/claims/{id} [get]
+func (h *ClaimHandler) GetClaimByID(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid claim ID"})
+		return
+	}
+
+	claim, err := h.claims(c).GetClaimByID(uint(id))
+	if err != nil {
+		claimError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, claim)
+}
+
+// @Summary Get Patient Claims
+// @Description List a patient's claims, newest first
+// @Tags claims
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Patient ID"
+// @Success 200 {array} models.Claim
+// @Router /api/patients/{id}/claims [get]
+func (h *ClaimHandler) GetPatientClaims(c *gin.Context) {
+	patientID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid patient ID"})
+		return
+	}
+
+	claims, err := h.claims(c).GetPatientClaims(uint(patientID))
+	if err != nil {
+		claimError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, claims)
+}
+
+// @Summary Create Claim Batch
+// @Description Write ready claims into an 837P file for the clearinghouse and mark them submitted. Claims that no longer pass validation are marked invalid and left out.
+// @Tags claims
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body ClaimBatchRequest false "Claims to submit"
+// @Success 201 {object} models.ClaimBatch
+// @Router /api/claim-batches [post]
+func (h *ClaimHandler) CreateBatch(c *gin.Context) {
+	var req ClaimBatchRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	batch, err := h.claims(c).CreateBatch(req.ClaimIDs, currentUserID(c))
+	if err != nil {
+		claimError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, batch)
+}
+
+// @Summary Get Claim Batches
+// @Description List claim batches, newest first
+// @Tags claims
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} models.ClaimBatch
+// @Router /api/claim-batches [get]
+func (h *ClaimHandler) GetBatches(c *gin.Context) {
+	batches, err := h.claims(c).GetBatches()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, batches)
+}
+
+// @Summary Get Claim Batch
+// @Description Get a claim batch with its claims
+// @Tags claims
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Batch ID"
+// @Success 200 {object} models.ClaimBatch
+// @Router /api/claim-batches/{id} [get]
+func (h *ClaimHandler) GetBatchByID(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid batch ID"})
+		return
+	}
+
+	batch, err := h.claims(c).GetBatchByID(uint(id))
+	if err != nil {
+		claimError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, batch)
+}
+
+// @Summary Download Claim Batch
+// @Description Download the batch's 837P file for upload to the clearinghouse
+// @Tags claims
+// @Produce plain
+// @Security BearerAuth
+// @Param id path int true "Batch ID"
+// @Success 200 {file} file
+// @Router /api/claim-batches/{id}/file [get]
+func (h *ClaimHandler) DownloadBatch(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid batch ID"})
+		return
+	}
+
+	batch, err := h.claims(c).GetBatchByID(uint(id))
+	if err != nil {
+		claimError(c, err)
+		return
+	}
+
+	c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": batch.FileName}))
+	c.Data(http.StatusOK, "text/plain; charset=utf-8", []byte(batch.Content))
+}
+
+// @Summary Import Remittance
+// @Description Upload an 835 remittance file. Each claim payment in it is matched to its claim by control number and posted to the claim's invoice as an insurance payment; anything that cannot be posted is listed with a note.
+// @Tags claims
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param file formData file true "835 file"
+// @Success 201 {array} models.Remittance
+// @Router /api/remittances [post]
+func (h *ClaimHandler) ImportRemittance(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxRemittanceBytes+multipartOverhead)
+	header, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A file is required in the 'file' form field"})
+		return
+	}
+	file, err := header.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	defer file.Close()
+	data, err := io.ReadAll(io.LimitReader(file, maxRemittanceBytes))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	remittances, err := h.claims(c).ImportRemittance(header.Filename, data, currentUserID(c))
+	if err != nil {
+		claimError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, remittances)
+}
+
+// @Summary Get Remittances
+// @Description List imported remittances, newest first
+// @Tags claims
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} models.Remittance
+// @Router /api/remittances [get]
+func (h *ClaimHandler) GetRemittances(c *gin.Context) {
+	remittances, err := h.claims(c).GetRemittances()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, remittances)
+}
+
+// @Summary Get Remittance
+// @Description Get a remittance with how each of its claims was reconciled
+// @Tags claims
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Remittance ID"
+// @Success 200 {object} models.Remittance
+// @Router /api/remittances/{id} [get]
+func (h *ClaimHandler) GetRemittanceByID(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid remittance ID"})
+		return
+	}
+
+	remittance, err := h.claims(c).GetRemittanceByID(uint(id))
+	if err != nil {
+		claimError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, remittance)
+}
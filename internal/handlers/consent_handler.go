@@ -0,0 +1,275 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"healthcare-portal/internal/models"
+	"healthcare-portal/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ConsentHandler struct {
+	consentService services.ConsentService
+}
+
+func NewConsentHandler(consentService services.ConsentService) *ConsentHandler {
+	return &ConsentHandler{consentService: consentService}
+}
+
+// consents returns the service scoped to the caller's tenant.
+func (h *ConsentHandler) consents(c *gin.Context) services.ConsentService {
+	return h.consentService.WithTenant(tenantID(c))
+}
+
+type ConsentTextRequest struct {
+	Type              string     `json:"type" binding:"required" example:"sms_reminders"`
+	Title             string     `json:"title" binding:"required"`
+	Body              string     `json:"body" binding:"required"`
+	EffectiveFrom     *time.Time `json:"effective_from"`
+	RequiresReconsent bool       `json:"requires_reconsent"`
+}
+
+type GrantConsentRequest struct {
+	Type string `json:"type" binding:"required" example:"research"`
+	// ConsentTextID pins the text version agreed to; the current version is
+	// used when it is left out.
+	ConsentTextID   uint       `json:"consent_text_id"`
+	RelatedPersonID *uint      `json:"related_person_id"`
+	GrantedAt       *time.Time `json:"granted_at"`
+	Notes           string     `json:"notes"`
+}
+
+type RevokeConsentRequest struct {
+	Reason string `json:"reason"`
+}
+
+// consentError maps service errors to HTTP status codes.
+func consentError(c *gin.Context, err error) {
+	status := http.StatusBadRequest
+	switch {
+	case errors.Is(err, services.ErrPatientNotFound), errors.Is(err, services.ErrConsentNotFound),
+		errors.Is(err, services.ErrConsentTextNotFound), errors.Is(err, services.ErrRelatedPersonNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, services.ErrConsentAlreadyRevoked), errors.Is(err, services.ErrGuardianConsentRequired):
+		status = http.StatusConflict
+	}
+	c.JSON(status, gin.H{"error": err.Error()})
+}
+
+// @Summary Get Consent Texts
+// @Description List every version of the consent texts, newest first, optionally for one type
+// @Tags consents
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param type query string false "Consent type (treatment, data_sharing, sms_reminders, email_reminders, research)"
+// @Success 200 {array} models.ConsentText
+// @Router /api/consent-texts [get]
+func (h *ConsentHandler) GetTexts(c *gin.Context) {
+	texts, err := h.consents(c).GetTexts(models.ConsentType(c.Query("type")))
+	if err != nil {
+		consentError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, texts)
+}
+
+// @Summary Get Current Consent Text
+// @Description Get the version of a consent type's text patients currently agree to
+// @Tags consents
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param type path string true "Consent type"
+// @Success 200 {object} models.ConsentText
+// @Failure 404 {object} map[string]string
+// @Router /api/consent-texts/current/{type} [get]
+func (h *ConsentHandler) GetCurrentText(c *gin.Context) {
+	text, err := h.consents(c).GetCurrentText(models.ConsentType(c.Param("type")))
+	if err != nil {
+		consentError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, text)
+}
+
+// @Summary Publish Consent Text
+// @Description Publish a new version of a consent type's text (Admin only). With requires_reconsent, consents to earlier versions stop counting once it takes effect.
+// @Tags consents
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body ConsentTextRequest true "Consent text"
+// @Success 201 {object} models.ConsentText
+// @Router /api/consent-texts [post]
+func (h *ConsentHandler) PublishText(c *gin.Context) {
+	var req ConsentTextRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	text := models.ConsentText{
+		Type:              models.ConsentType(req.Type),
+		Title:             req.Title,
+		Body:              req.Body,
+		RequiresReconsent: req.RequiresReconsent,
+		CreatedBy:         currentUserID(c),
+	}
+	if req.EffectiveFrom != nil {
+		text.EffectiveFrom = *req.EffectiveFrom
+	}
+	if err := h.consents(c).PublishText(&text); err != nil {
+		consentError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, text)
+}
+
+// @Summary Get Patient Consents
+// @Description List a patient's granted consents, or with history=true every consent recorded, newest first
+// @Tags consents
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Patient ID"
+// @Param history query bool false "Include revoked and superseded consents"
+// @Success 200 {array} models.Consent
+// @Router /api/patients/{id}/consents [get]
+func (h *ConsentHandler) GetPatientConsents(c *gin.Context) {
+	patientID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid patient ID"})
+		return
+	}
+
+	consents, err := h.consents(c).GetPatientConsents(uint(patientID), c.Query("history") == "true")
+	if err != nil {
+		consentError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, consents)
+}
+
+// @Summary Get Patient Consent
+// @Description Get one of a patient's consents with the text agreed to
+// @Tags consents
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Patient ID"
+// @Param consentId path int true "Consent ID"
+// @Success 200 {object} models.Consent
+// @Router /api/patients/{id}/consents/{consentId} [get]
+func (h *ConsentHandler) GetConsentByID(c *gin.Context) {
+	patientID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid patient ID"})
+		return
+	}
+	consentID, err := strconv.ParseUint(c.Param("consentId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid consent ID"})
+		return
+	}
+
+	consent, err := h.consents(c).GetConsentByID(uint(patientID), uint(consentID))
+	if err != nil {
+		consentError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, consent)
+}
+
+// @Summary Grant Consent
+// @Description Record a patient's consent (Receptionist, Nurse or Doctor). It replaces any consent of the same type. Minors consent through a guardian given as related_person_id.
+// @Tags consents
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Patient ID"
+// @Param request body GrantConsentRequest true "Consent"
+// @Success 201 {object} models.Consent
+// @Failure 409 {object} map[string]string
+// @Router /api/patients/{id}/consents [post]
+func (h *ConsentHandler) GrantConsent(c *gin.Context) {
+	patientID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid patient ID"})
+		return
+	}
+
+	var req GrantConsentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	consent := models.Consent{
+		PatientID:       uint(patientID),
+		Type:            models.ConsentType(req.Type),
+		ConsentTextID:   req.ConsentTextID,
+		RelatedPersonID: req.RelatedPersonID,
+		Notes:           req.Notes,
+		RecordedBy:      currentUserID(c),
+	}
+	if req.GrantedAt != nil {
+		consent.GrantedAt = *req.GrantedAt
+	}
+	if err := h.consents(c).GrantConsent(&consent); err != nil {
+		consentError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, consent)
+}
+
+// @Summary Revoke Consent
+// @Description Withdraw a patient's consent from now on (Receptionist, Nurse or Doctor)
+// @Tags consents
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Patient ID"
+// @Param consentId path int true "Consent ID"
+// @Param request body RevokeConsentRequest false "Reason"
+// @Success 200 {object} models.Consent
+// @Failure 409 {object} map[string]string
+// @Router /api/patients/{id}/consents/{consentId}/revoke [post]
+func (h *ConsentHandler) RevokeConsent(c *gin.Context) {
+	patientID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid patient ID"})
+		return
+	}
+	consentID, err := strconv.ParseUint(c.Param("consentId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid consent ID"})
+		return
+	}
+
+	var req RevokeConsentRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	consent, err := h.consents(c).RevokeConsent(uint(patientID), uint(consentID), currentUserID(c), req.Reason)
+	if err != nil {
+		consentError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, consent)
+}
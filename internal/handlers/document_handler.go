@@ -0,0 +1,259 @@
+package handlers
+
+import (
+	"errors"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"healthcare-portal/internal/models"
+	"healthcare-portal/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// multipartOverhead allows for the form fields and boundaries around the
+// uploaded file when limiting the request body.
+const multipartOverhead = 1 << 20
+
+type DocumentHandler struct {
+	documentService services.DocumentService
+	maxUploadBytes  int64
+}
+
+// NewDocumentHandler builds the handler. Request bodies much larger than
+// maxUploadBytes are cut off before the upload reaches the service.
+func NewDocumentHandler(documentService services.DocumentService, maxUploadBytes int64) *DocumentHandler {
+	return &DocumentHandler{documentService: documentService, maxUploadBytes: maxUploadBytes}
+}
+
+// documents returns the service scoped to the caller's tenant.
+func (h *DocumentHandler) documents(c *gin.Context) services.DocumentService {
+	return h.documentService.WithTenant(tenantID(c))
+}
+
+type UpdateDocumentRequest struct {
+	Title       string `json:"title" binding:"required"`
+	Category    string `json:"category" example:"referral_letter"`
+	Description string `json:"description"`
+}
+
+// documentError maps service errors to HTTP status codes.
+func documentError(c *gin.Context, err error) {
+	status := http.StatusBadRequest
+	switch {
+	case errors.Is(err, services.ErrDocumentNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, services.ErrDocumentTooLarge):
+		status = http.StatusRequestEntityTooLarge
+	case errors.Is(err, services.ErrDocumentType):
+		status = http.StatusUnsupportedMediaType
+	}
+	c.JSON(status, gin.H{"error": err.Error()})
+}
+
+// @Summary Upload Patient Document
+// @Description Attach a file such as a referral letter, scanned ID or imaging report to a patient. The content type is detected from the file itself.
+// @Tags documents
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Patient ID"
+// @Param file formData file true "Document file (PDF, image or plain text)"
+// @Param title formData string false "Title, defaults to the file name"
+// @Param category formData string false "referral_letter, identification, imaging_report, lab_report, insurance_card or other"
+// @Param description formData string false "Description"
+// @Success 201 {object} models.Document
+// @Failure 413 {object} map[string]string
+// @Failure 415 {object} map[string]string
+// @Router /api/patients/{id}/documents [post]
+func (h *DocumentHandler) UploadDocument(c *gin.Context) {
+	patientID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid patient ID"})
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.maxUploadBytes+multipartOverhead)
+	header, err := c.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			documentError(c, services.ErrDocumentTooLarge)
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A file is required in the 'file' form field"})
+		return
+	}
+	file, err := header.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	defer file.Close()
+
+	document := &models.Document{
+		PatientID:   uint(patientID),
+		Title:       c.PostForm("title"),
+		Category:    models.DocumentCategory(c.PostForm("category")),
+		Description: c.PostForm("description"),
+		FileName:    header.Filename,
+		UploadedBy:  currentUserID(c),
+	}
+	if err := h.documents(c).UploadDocument(document, file); err != nil {
+		documentError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, document)
+}
+
+// @Summary Get Patient Documents
+// @Description List a patient's documents, newest first
+// @Tags documents
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Patient ID"
+// @Param category query string false "Only this category"
+// @Success 200 {array} models.Document
+// @Router /api/patients/{id}/documents [get]
+func (h *DocumentHandler) GetPatientDocuments(c *gin.Context) {
+	patientID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid patient ID"})
+		return
+	}
+
+	documents, err := h.documents(c).GetPatientDocuments(uint(patientID), models.DocumentCategory(c.Query("category")))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, documents)
+}
+
+// @Summary Get Document
+// @Description Get a document's details
+// @Tags documents
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Document ID"
+// @Success 200 {object} models.Document
+// @Router /api/documents/{id} [get]
+func (h *DocumentHandler) GetDocumentByID(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid document ID"})
+		return
+	}
+
+	document, err := h.documents(c).GetDocumentByID(uint(id))
+	if err != nil {
+		documentError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, document)
+}
+
+// @Summary Download Document
+// @Description Download a document's content. Pass inline=true to display it in the browser instead of saving it.
+// @Tags documents
+// @Produce octet-stream
+// @Security BearerAuth
+// @Param id path int true "Document ID"
+// @Param inline query bool false "Display inline"
+// @Success 200 {file} file
+// @Router /api/documents/{id}/content [get]
+func (h *DocumentHandler) DownloadDocument(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid document ID"})
+		return
+	}
+
+	document, content, err := h.documents(c).OpenDocument(uint(id))
+	if err != nil {
+		if errors.Is(err, services.ErrDocumentNotFound) {
+			documentError(c, err)
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer content.Close()
+
+	disposition := "attachment"
+	if c.Query("inline") == "true" {
+		disposition = "inline"
+	}
+	c.DataFromReader(http.StatusOK, document.SizeBytes, document.ContentType, content, map[string]string{
+		"Content-Disposition":    mime.FormatMediaType(disposition, map[string]string{"filename": document.FileName}),
+		"X-Content-Type-Options": "nosniff",
+		"ETag":                   `"` + document.SHA256 + `"`,
+	})
+}
+
+// @Summary Update Document
+// @Description Change a document's title, category or description
+// @Tags documents
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Document ID"
+// @Param request body UpdateDocumentRequest true "Document details"
+// @Success 200 {object} models.Document
+// @Router /api/documents/{id} [put]
+func (h *DocumentHandler) UpdateDocument(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid document ID"})
+		return
+	}
+
+	var req UpdateDocumentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	document := &models.Document{
+		ID:          uint(id),
+		Title:       req.Title,
+		Category:    models.DocumentCategory(req.Category),
+		Description: req.Description,
+	}
+	if err := h.documents(c).UpdateDocument(document); err != nil {
+		documentError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, document)
+}
+
+// @Summary Delete Document
+// @Description Remove a document from the patient's chart
+// @Tags documents
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Document ID"
+// @Success 200 {object} map[string]string
+// @Router /api/documents/{id} [delete]
+func (h *DocumentHandler) DeleteDocument(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid document ID"})
+		return
+	}
+
+	if err := h.documents(c).DeleteDocument(uint(id)); err != nil {
+		documentError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Document deleted successfully"})
+}
@@ -0,0 +1,252 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"healthcare-portal/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type EncounterHandler struct {
+	encounterService services.EncounterService
+}
+
+func NewEncounterHandler(encounterService services.EncounterService) *EncounterHandler {
+	return &EncounterHandler{encounterService: encounterService}
+}
+
+// encounters returns the service scoped to the caller's tenant.
+func (h *EncounterHandler) encounters(c *gin.Context) services.EncounterService {
+	return h.encounterService.WithTenant(tenantID(c))
+}
+
+type SOAPRequest struct {
+	Subjective string `json:"subjective"`
+	Objective  string `json:"objective"`
+	Assessment string `json:"assessment"`
+	Plan       string `json:"plan"`
+}
+
+func (r SOAPRequest) note() services.SOAPNote {
+	return services.SOAPNote{
+		Subjective: r.Subjective,
+		Objective:  r.Objective,
+		Assessment: r.Assessment,
+		Plan:       r.Plan,
+	}
+}
+
+type CreateEncounterRequest struct {
+	AppointmentID uint `json:"appointment_id" binding:"required"`
+	SOAPRequest
+}
+
+type AddendumRequest struct {
+	Content string `json:"content" binding:"required"`
+}
+
+// encounterError maps service errors to HTTP status codes.
+func encounterError(c *gin.Context, err error) {
+	status := http.StatusBadRequest
+	switch {
+	case errors.Is(err, services.ErrEncounterNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, services.ErrNotTreatingDoctor):
+		status = http.StatusForbidden
+	case errors.Is(err, services.ErrEncounterLocked), errors.Is(err, services.ErrEncounterNotSigned):
+		status = http.StatusConflict
+	}
+	c.JSON(status, gin.H{"error": err.Error()})
+}
+
+// @Summary Create Encounter
+// @Description Open the SOAP note for an appointment (Treating doctor only)
+// @Tags encounters
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body CreateEncounterRequest true "Encounter details"
+// @Success 201 {object} models.Encounter
+// @Router /api/encounters [post]
+func (h *EncounterHandler) CreateEncounter(c *gin.Context) {
+	var req CreateEncounterRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	encounter, err := h.encounters(c).CreateEncounter(req.AppointmentID, currentUserID(c), req.note())
+	if err != nil {
+		encounterError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, encounter)
+}
+
+// @Summary Get Encounter by ID
+// @Description Get an encounter with its addenda
+// @Tags encounters
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Encounter ID"
+// @Success 200 {object} models.Encounter
+// @Router /api/encounters/{id} [get]
+func (h *EncounterHandler) GetEncounterByID(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid encounter ID"})
+		return
+	}
+
+	encounter, err := h.encounters(c).GetEncounterByID(uint(id))
+	if err != nil {
+		encounterError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, encounter)
+}
+
+// @Summary Get Encounter by Appointment
+// @Description Get the encounter documenting an appointment
+// @Tags encounters
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param appointmentId path int true "Appointment ID"
+// @Success 200 {object} models.Encounter
+// @Router /api/encounters/appointment/{appointmentId} [get]
+func (h *EncounterHandler) GetEncounterByAppointment(c *gin.Context) {
+	appointmentID, err := strconv.ParseUint(c.Param("appointmentId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid appointment ID"})
+		return
+	}
+
+	encounter, err := h.encounters(c).GetEncounterByAppointment(uint(appointmentID))
+	if err != nil {
+		encounterError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, encounter)
+}
+
+// @Summary Update Encounter
+// @Description Replace the SOAP sections of a draft encounter (Treating doctor only)
+// @Tags encounters
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Encounter ID"
+// @Param request body SOAPRequest true "SOAP sections"
+// @Success 200 {object} models.Encounter
+// @Router /api/encounters/{id} [put]
+func (h *EncounterHandler) UpdateEncounter(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid encounter ID"})
+		return
+	}
+
+	var req SOAPRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	encounter, err := h.encounters(c).UpdateEncounter(uint(id), currentUserID(c), req.note())
+	if err != nil {
+		encounterError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, encounter)
+}
+
+// @Summary Sign Encounter
+// @Description Sign and lock an encounter (Treating doctor only)
+// @Tags encounters
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Encounter ID"
+// @Success 200 {object} models.Encounter
+// @Router /api/encounters/{id}/sign [post]
+func (h *EncounterHandler) SignEncounter(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid encounter ID"})
+		return
+	}
+
+	encounter, err := h.encounters(c).SignEncounter(uint(id), currentUserID(c))
+	if err != nil {
+		encounterError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, encounter)
+}
+
+// @Summary Add Addendum
+// @Description Append an addendum to a signed encounter
+// @Tags encounters
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Encounter ID"
+// @Param request body AddendumRequest true "Addendum"
+// @Success 201 {object} models.EncounterAddendum
+// @Router /api/encounters/{id}/addenda [post]
+func (h *EncounterHandler) AddAddendum(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid encounter ID"})
+		return
+	}
+
+	var req AddendumRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	addendum, err := h.encounters(c).AddAddendum(uint(id), currentUserID(c), req.Content)
+	if err != nil {
+		encounterError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, addendum)
+}
+
+// @Summary Get Patient Encounter Timeline
+// @Description Get a patient's encounters in chronological order
+// @Tags encounters
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param patientId path int true "Patient ID"
+// @Success 200 {array} models.Encounter
+// @Router /api/encounters/patient/{patientId} [get]
+func (h *EncounterHandler) GetPatientTimeline(c *gin.Context) {
+	patientID, err := strconv.ParseUint(c.Param("patientId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid patient ID"})
+		return
+	}
+
+	encounters, err := h.encounters(c).GetPatientTimeline(uint(patientID))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, encounters)
+}
@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"healthcare-portal/internal/models"
+	"healthcare-portal/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ExportHandler struct {
+	exportService services.ExportService
+}
+
+func NewExportHandler(exportService services.ExportService) *ExportHandler {
+	return &ExportHandler{exportService: exportService}
+}
+
+type DeidentifiedExport struct {
+	GeneratedAt time.Time                    `json:"generated_at"`
+	Consent     models.ConsentType           `json:"consent"`
+	Patients    []models.DeidentifiedPatient `json:"patients"`
+}
+
+// @Summary De-identified Export
+// @Description Export problem lists and immunizations of patients who currently consent to research use, without names, contact details, identifiers or exact dates (Admin only)
+// @Tags exports
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} DeidentifiedExport
+// @Router /api/exports/deidentified [get]
+func (h *ExportHandler) GetDeidentifiedExport(c *gin.Context) {
+	patients, err := h.exportService.WithTenant(tenantID(c)).DeidentifiedPatients()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, DeidentifiedExport{
+		GeneratedAt: time.Now(),
+		Consent:     models.ConsentResearch,
+		Patients:    patients,
+	})
+}
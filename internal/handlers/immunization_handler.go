@@ -0,0 +1,272 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"healthcare-portal/internal/models"
+	"healthcare-portal/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ImmunizationHandler struct {
+	immunizationService services.ImmunizationService
+}
+
+func NewImmunizationHandler(immunizationService services.ImmunizationService) *ImmunizationHandler {
+	return &ImmunizationHandler{immunizationService: immunizationService}
+}
+
+// immunizations returns the service scoped to the caller's tenant.
+func (h *ImmunizationHandler) immunizations(c *gin.Context) services.ImmunizationService {
+	return h.immunizationService.WithTenant(tenantID(c))
+}
+
+type ImmunizationRequest struct {
+	VaccineCode    string     `json:"vaccine_code" binding:"required" example:"MMR"`
+	VaccineName    string     `json:"vaccine_name"`
+	DoseNumber     int        `json:"dose_number"`
+	AdministeredAt *time.Time `json:"administered_at"`
+	LotNumber      string     `json:"lot_number"`
+	Manufacturer   string     `json:"manufacturer"`
+	ExpirationDate string     `json:"expiration_date" example:"2025-12-31"`
+	Site           string     `json:"site" example:"left_deltoid"`
+	Source         string     `json:"source" example:"administered"`
+	AdministeredBy *uint      `json:"administered_by"`
+	Notes          string     `json:"notes"`
+}
+
+// apply copies the request onto immunization. Doses given here default to
+// the caller as the administering user.
+func (r ImmunizationRequest) apply(c *gin.Context, immunization *models.Immunization) error {
+	expiration, err := parseOptionalDate(r.ExpirationDate)
+	if err != nil {
+		return err
+	}
+	immunization.VaccineCode = r.VaccineCode
+	immunization.VaccineName = r.VaccineName
+	immunization.DoseNumber = r.DoseNumber
+	immunization.LotNumber = r.LotNumber
+	immunization.Manufacturer = r.Manufacturer
+	immunization.ExpirationDate = expiration
+	immunization.Site = models.InjectionSite(r.Site)
+	immunization.Source = models.ImmunizationSource(r.Source)
+	immunization.AdministeredBy = r.AdministeredBy
+	immunization.Notes = r.Notes
+	if r.AdministeredAt != nil {
+		immunization.AdministeredAt = *r.AdministeredAt
+	}
+	if immunization.AdministeredBy == nil && immunization.Source != models.ImmunizationHistorical {
+		userID := currentUserID(c)
+		immunization.AdministeredBy = &userID
+	}
+	return nil
+}
+
+// @Summary Get Patient Immunizations
+// @Description Get a patient's immunization history, oldest first
+// @Tags immunizations
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Patient ID"
+// @Success 200 {array} models.Immunization
+// @Router /api/patients/{id}/immunizations [get]
+func (h *ImmunizationHandler) GetPatientImmunizations(c *gin.Context) {
+	patientID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid patient ID"})
+		return
+	}
+
+	immunizations, err := h.immunizations(c).GetPatientImmunizations(uint(patientID))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, immunizations)
+}
+
+// @Summary Record Immunization
+// @Description Record a vaccine dose given here or transcribed from another record
+// @Tags immunizations
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Patient ID"
+// @Param request body ImmunizationRequest true "Dose details"
+// @Success 201 {object} models.Immunization
+// @Router /api/patients/{id}/immunizations [post]
+func (h *ImmunizationHandler) RecordImmunization(c *gin.Context) {
+	patientID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid patient ID"})
+		return
+	}
+
+	var req ImmunizationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	immunization := &models.Immunization{PatientID: uint(patientID), RecordedBy: currentUserID(c)}
+	if err := req.apply(c, immunization); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date format. Use YYYY-MM-DD"})
+		return
+	}
+
+	if err := h.immunizations(c).RecordImmunization(immunization); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, immunization)
+}
+
+// @Summary Get Immunization
+// @Description Get a single immunization record
+// @Tags immunizations
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Immunization ID"
+// @Success 200 {object} models.Immunization
+// @Router /api/immunizations/{id} [get]
+func (h *ImmunizationHandler) GetImmunizationByID(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid immunization ID"})
+		return
+	}
+
+	immunization, err := h.immunizations(c).GetImmunizationByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Immunization not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, immunization)
+}
+
+// @Summary Get Vaccine Forecast
+// @Description Get the next dose of each scheduled vaccine with its due and overdue dates
+// @Tags immunizations
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Patient ID"
+// @Param as_of query string false "Evaluate as of this date (YYYY-MM-DD), defaults to today"
+// @Param status query string false "Comma-separated statuses to include (upcoming, due, overdue, complete)"
+// @Success 200 {array} models.VaccineForecast
+// @Router /api/patients/{id}/immunizations/forecast [get]
+func (h *ImmunizationHandler) GetPatientForecast(c *gin.Context) {
+	patientID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid patient ID"})
+		return
+	}
+
+	asOf := time.Now()
+	if date, err := parseOptionalDate(c.Query("as_of")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date format. Use YYYY-MM-DD"})
+		return
+	} else if date != nil {
+		asOf = *date
+	}
+
+	forecast, err := h.immunizations(c).GetPatientForecast(uint(patientID), asOf)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if status := c.Query("status"); status != "" {
+		wanted := make(map[models.VaccineDueStatus]bool)
+		for _, s := range strings.Split(status, ",") {
+			wanted[models.VaccineDueStatus(strings.TrimSpace(s))] = true
+		}
+		filtered := []models.VaccineForecast{}
+		for _, f := range forecast {
+			if wanted[f.Status] {
+				filtered = append(filtered, f)
+			}
+		}
+		forecast = filtered
+	}
+
+	c.JSON(http.StatusOK, forecast)
+}
+
+// @Summary Update Immunization
+// @Description Correct an immunization record
+// @Tags immunizations
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Immunization ID"
+// @Param request body ImmunizationRequest true "Updated dose details"
+// @Success 200 {object} models.Immunization
+// @Router /api/immunizations/{id} [put]
+func (h *ImmunizationHandler) UpdateImmunization(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid immunization ID"})
+		return
+	}
+
+	var req ImmunizationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	immunization, err := h.immunizations(c).GetImmunizationByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Immunization not found"})
+		return
+	}
+	dose := immunization.DoseNumber
+	if err := req.apply(c, immunization); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date format. Use YYYY-MM-DD"})
+		return
+	}
+	if req.DoseNumber == 0 {
+		immunization.DoseNumber = dose
+	}
+
+	if err := h.immunizations(c).UpdateImmunization(immunization); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, immunization)
+}
+
+// @Summary Delete Immunization
+// @Description Delete an immunization recorded in error
+// @Tags immunizations
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Immunization ID"
+// @Success 200 {object} map[string]string
+// @Router /api/immunizations/{id} [delete]
+func (h *ImmunizationHandler) DeleteImmunization(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid immunization ID"})
+		return
+	}
+
+	if err := h.immunizations(c).DeleteImmunization(uint(id)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Immunization deleted successfully"})
+}
@@ -0,0 +1,575 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"healthcare-portal/internal/models"
+	"healthcare-portal/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type InsuranceHandler struct {
+	insuranceService services.InsuranceService
+}
+
+func NewInsuranceHandler(insuranceService services.InsuranceService) *InsuranceHandler {
+	return &InsuranceHandler{insuranceService: insuranceService}
+}
+
+// insurance returns the service scoped to the caller's tenant.
+func (h *InsuranceHandler) insurance(c *gin.Context) services.InsuranceService {
+	return h.insuranceService.WithTenant(tenantID(c))
+}
+
+type PayerRequest struct {
+	Name      string `json:"name" binding:"required"`
+	PayerCode string `json:"payer_code" example:"60054"`
+	Phone     string `json:"phone"`
+	Address   string `json:"address"`
+	IsActive  *bool  `json:"is_active"`
+}
+
+type PlanRequest struct {
+	Name       string `json:"name" binding:"required"`
+	PlanType   string `json:"plan_type" binding:"required" example:"ppo"`
+	CopayCents int64  `json:"copay_cents"`
+	IsActive   *bool  `json:"is_active"`
+}
+
+type CoverageRequest struct {
+	PlanID                 uint   `json:"plan_id" binding:"required"`
+	MemberID               string `json:"member_id" binding:"required"`
+	GroupNumber            string `json:"group_number"`
+	SubscriberRelationship string `json:"subscriber_relationship" example:"self"`
+	SubscriberName         string `json:"subscriber_name"`
+	SubscriberDateOfBirth  string `json:"subscriber_date_of_birth" example:"1980-04-05"`
+	Priority               int    `json:"priority" example:"1"`
+	EffectiveFrom          string `json:"effective_from" binding:"required" example:"2026-01-01"`
+	EffectiveTo            string `json:"effective_to" example:"2026-12-31"`
+}
+
+// coverage converts the request into a coverage, parsing its dates.
+func (r CoverageRequest) coverage() (*models.Coverage, error) {
+	effectiveFrom, err := time.Parse("2006-01-02", r.EffectiveFrom)
+	if err != nil {
+		return nil, errors.New("invalid effective_from date")
+	}
+	effectiveTo, err := parseOptionalDate(r.EffectiveTo)
+	if err != nil {
+		return nil, errors.New("invalid effective_to date")
+	}
+	subscriberDOB, err := parseOptionalDate(r.SubscriberDateOfBirth)
+	if err != nil {
+		return nil, errors.New("invalid subscriber_date_of_birth")
+	}
+	return &models.Coverage{
+		PlanID:                 r.PlanID,
+		MemberID:               r.MemberID,
+		GroupNumber:            r.GroupNumber,
+		SubscriberRelationship: models.SubscriberRelationship(r.SubscriberRelationship),
+		SubscriberName:         r.SubscriberName,
+		SubscriberDateOfBirth:  subscriberDOB,
+		Priority:               r.Priority,
+		EffectiveFrom:          effectiveFrom,
+		EffectiveTo:            effectiveTo,
+	}, nil
+}
+
+// insuranceError maps service errors to HTTP status codes.
+func insuranceError(c *gin.Context, err error) {
+	status := http.StatusBadRequest
+	switch {
+	case errors.Is(err, services.ErrPayerNotFound), errors.Is(err, services.ErrPlanNotFound),
+		errors.Is(err, services.ErrCoverageNotFound), errors.Is(err, services.ErrPatientNotFound),
+		errors.Is(err, services.ErrAppointmentNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, services.ErrCoveragePriorityUsed), errors.Is(err, services.ErrNoCoverage):
+		status = http.StatusConflict
+	}
+	c.JSON(status, gin.H{"error": err.Error()})
+}
+
+// @Summary Get Insurance Payers
+// @Description List insurance payers by name
+// @Tags insurance
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param active query bool false "Only payers still in use"
+// @Success 200 {array} models.InsurancePayer
+// @Router /api/insurance-payers [get]
+func (h *InsuranceHandler) GetPayers(c *gin.Context) {
+	payers, err := h.insurance(c).GetPayers(c.Query("active") == "true")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, payers)
+}
+
+// @Summary Get Insurance Payer
+// @Description Get an insurance payer with its plans
+// @Tags insurance
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Payer ID"
+// @Success 200 {object} models.InsurancePayer
+// @Router /api/insurance-payers/{id} [get]
+func (h *InsuranceHandler) GetPayerByID(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid payer ID"})
+		return
+	}
+
+	payer, err := h.insurance(c).GetPayerByID(uint(id))
+	if err != nil {
+		insuranceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, payer)
+}
+
+// @Summary Create Insurance Payer
+// @Description Add an insurance payer (Admin only)
+// @Tags insurance
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body PayerRequest true "Payer details"
+// @Success 201 {object} models.InsurancePayer
+// @Router /api/insurance-payers [post]
+func (h *InsuranceHandler) CreatePayer(c *gin.Context) {
+	var req PayerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	payer := &models.InsurancePayer{
+		Name:      req.Name,
+		PayerCode: req.PayerCode,
+		Phone:     req.Phone,
+		Address:   req.Address,
+		IsActive:  true,
+	}
+	if err := h.insurance(c).CreatePayer(payer); err != nil {
+		insuranceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, payer)
+}
+
+// @Summary Update Insurance Payer
+// @Description Update an insurance payer (Admin only). Payers are retired by setting is_active to false rather than deleted.
+// @Tags insurance
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Payer ID"
+// @Param request body PayerRequest true "Payer details"
+// @Success 200 {object} models.InsurancePayer
+// @Router /api/insurance-payers/{id} [put]
+func (h *InsuranceHandler) UpdatePayer(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid payer ID"})
+		return
+	}
+
+	var req PayerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	payer, err := h.insurance(c).GetPayerByID(uint(id))
+	if err != nil {
+		insuranceError(c, err)
+		return
+	}
+
+	payer.Name = req.Name
+	payer.PayerCode = req.PayerCode
+	payer.Phone = req.Phone
+	payer.Address = req.Address
+	if req.IsActive != nil {
+		payer.IsActive = *req.IsActive
+	}
+
+	if err := h.insurance(c).UpdatePayer(payer); err != nil {
+		insuranceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, payer)
+}
+
+// @Summary Get Payer Plans
+// @Description List a payer's plans by name
+// @Tags insurance
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Payer ID"
+// @Success 200 {array} models.InsurancePlan
+// @Router /api/insurance-payers/{id}/plans [get]
+func (h *InsuranceHandler) GetPayerPlans(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid payer ID"})
+		return
+	}
+
+	plans, err := h.insurance(c).GetPayerPlans(uint(id))
+	if err != nil {
+		insuranceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, plans)
+}
+
+// @Summary Create Insurance Plan
+// @Description Add a plan to a payer (Admin only)
+// @Tags insurance
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Payer ID"
+// @Param request body PlanRequest true "Plan details"
+// @Success 201 {object} models.InsurancePlan
+// @Router /api/insurance-payers/{id}/plans [post]
+func (h *InsuranceHandler) CreatePlan(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid payer ID"})
+		return
+	}
+
+	var req PlanRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	plan := &models.InsurancePlan{
+		PayerID:    uint(id),
+		Name:       req.Name,
+		PlanType:   models.PlanType(req.PlanType),
+		CopayCents: req.CopayCents,
+		IsActive:   true,
+	}
+	if err := h.insurance(c).CreatePlan(plan); err != nil {
+		insuranceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, plan)
+}
+
+// @Summary Get Insurance Plan
+// @Description Get an insurance plan with its payer
+// @Tags insurance
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Plan ID"
+// @Success 200 {object} models.InsurancePlan
+// @Router /api/insurance-plans/{id} [get]
+func (h *InsuranceHandler) GetPlanByID(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid plan ID"})
+		return
+	}
+
+	plan, err := h.insurance(c).GetPlanByID(uint(id))
+	if err != nil {
+		insuranceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, plan)
+}
+
+// @Summary Update Insurance Plan
+// @Description Update an insurance plan (Admin only). Plans are retired by setting is_active to false; existing coverages keep them.
+// @Tags insurance
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Plan ID"
+// @Param request body PlanRequest true "Plan details"
+// @Success 200 {object} models.InsurancePlan
+// @Router /api/insurance-plans/{id} [put]
+func (h *InsuranceHandler) UpdatePlan(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid plan ID"})
+		return
+	}
+
+	var req PlanRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	plan, err := h.insurance(c).GetPlanByID(uint(id))
+	if err != nil {
+		insuranceError(c, err)
+		return
+	}
+
+	plan.Name = req.Name
+	plan.PlanType = models.PlanType(req.PlanType)
+	plan.CopayCents = req.CopayCents
+	if req.IsActive != nil {
+		plan.IsActive = *req.IsActive
+	}
+
+	if err := h.insurance(c).UpdatePlan(plan); err != nil {
+		insuranceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, plan)
+}
+
+// @Summary Get Patient Coverages
+// @Description List a patient's insurance coverages, primary first
+// @Tags insurance
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Patient ID"
+// @Param in_force_on query string false "Only coverages in force on this date (YYYY-MM-DD)"
+// @Success 200 {array} models.Coverage
+// @Router /api/patients/{id}/coverages [get]
+func (h *InsuranceHandler) GetPatientCoverages(c *gin.Context) {
+	patientID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid patient ID"})
+		return
+	}
+	inForceOn, err := parseOptionalDate(c.Query("in_force_on"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date format"})
+		return
+	}
+
+	coverages, err := h.insurance(c).GetPatientCoverages(uint(patientID), inForceOn)
+	if err != nil {
+		insuranceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, coverages)
+}
+
+// @Summary Get Coverage
+// @Description Get one of a patient's insurance coverages
+// @Tags insurance
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Patient ID"
+// @Param coverageId path int true "Coverage ID"
+// @Success 200 {object} models.Coverage
+// @Router /api/patients/{id}/coverages/{coverageId} [get]
+func (h *InsuranceHandler) GetCoverageByID(c *gin.Context) {
+	patientID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid patient ID"})
+		return
+	}
+	coverageID, err := strconv.ParseUint(c.Param("coverageId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid coverage ID"})
+		return
+	}
+
+	coverage, err := h.insurance(c).GetCoverageByID(uint(patientID), uint(coverageID))
+	if err != nil {
+		insuranceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, coverage)
+}
+
+// @Summary Add Coverage
+// @Description Record a patient's insurance coverage (Receptionist or Admin). Priority 1 is primary, 2 secondary and 3 tertiary; two coverages of the same priority cannot be in force on the same day.
+// @Tags insurance
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Patient ID"
+// @Param request body CoverageRequest true "Coverage details"
+// @Success 201 {object} models.Coverage
+// @Failure 409 {object} map[string]string
+// @Router /api/patients/{id}/coverages [post]
+func (h *InsuranceHandler) AddCoverage(c *gin.Context) {
+	patientID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid patient ID"})
+		return
+	}
+
+	var req CoverageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	coverage, err := req.coverage()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	coverage.PatientID = uint(patientID)
+	coverage.CreatedBy = currentUserID(c)
+	coverage.UpdatedBy = coverage.CreatedBy
+	if err := h.insurance(c).AddCoverage(coverage); err != nil {
+		insuranceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, coverage)
+}
+
+// @Summary Update Coverage
+// @Description Update a patient's insurance coverage (Receptionist or Admin), for example to set the date it ended
+// @Tags insurance
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Patient ID"
+// @Param coverageId path int true "Coverage ID"
+// @Param request body CoverageRequest true "Coverage details"
+// @Success 200 {object} models.Coverage
+// @Failure 409 {object} map[string]string
+// @Router /api/patients/{id}/coverages/{coverageId} [put]
+func (h *InsuranceHandler) UpdateCoverage(c *gin.Context) {
+	patientID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid patient ID"})
+		return
+	}
+	coverageID, err := strconv.ParseUint(c.Param("coverageId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid coverage ID"})
+		return
+	}
+
+	var req CoverageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	coverage, err := req.coverage()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	coverage.ID = uint(coverageID)
+	coverage.PatientID = uint(patientID)
+	coverage.UpdatedBy = currentUserID(c)
+	if err := h.insurance(c).UpdateCoverage(coverage); err != nil {
+		insuranceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, coverage)
+}
+
+// @Summary Delete Coverage
+// @Description Remove a coverage entered in error (Receptionist or Admin). Coverage that has ended should be given an effective_to date instead.
+// @Tags insurance
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Patient ID"
+// @Param coverageId path int true "Coverage ID"
+// @Success 200 {object} map[string]string
+// @Router /api/patients/{id}/coverages/{coverageId} [delete]
+func (h *InsuranceHandler) DeleteCoverage(c *gin.Context) {
+	patientID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid patient ID"})
+		return
+	}
+	coverageID, err := strconv.ParseUint(c.Param("coverageId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid coverage ID"})
+		return
+	}
+
+	if err := h.insurance(c).DeleteCoverage(uint(patientID), uint(coverageID)); err != nil {
+		insuranceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Coverage deleted successfully"})
+}
+
+// @Summary Get Appointment Eligibility
+// @Description List the eligibility checks made for an appointment, newest first
+// @Tags insurance
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Appointment ID"
+// @Success 200 {array} models.EligibilityCheck
+// @Router /api/appointments/{id}/eligibility [get]
+func (h *InsuranceHandler) GetAppointmentEligibility(c *gin.Context) {
+	appointmentID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid appointment ID"})
+		return
+	}
+
+	checks, err := h.insurance(c).GetAppointmentEligibility(uint(appointmentID))
+	if err != nil {
+		insuranceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, checks)
+}
+
+// @Summary Check Appointment Eligibility
+// @Description Check every coverage in force on the appointment date and record the answers (Receptionist or Admin)
+// @Tags insurance
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Appointment ID"
+// @Success 201 {array} models.EligibilityCheck
+// @Failure 409 {object} map[string]string
+// @Router /api/appointments/{id}/eligibility [post]
+func (h *InsuranceHandler) CheckAppointmentEligibility(c *gin.Context) {
+	appointmentID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid appointment ID"})
+		return
+	}
+
+	checks, err := h.insurance(c).CheckAppointmentEligibility(uint(appointmentID), currentUserID(c))
+	if err != nil {
+		insuranceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, checks)
+}
@@ -0,0 +1,245 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"healthcare-portal/internal/models"
+	"healthcare-portal/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type LabHandler struct {
+	labService services.LabService
+}
+
+func NewLabHandler(labService services.LabService) *LabHandler {
+	return &LabHandler{labService: labService}
+}
+
+// labs returns the service scoped to the caller's tenant.
+func (h *LabHandler) labs(c *gin.Context) services.LabService {
+	return h.labService.WithTenant(tenantID(c))
+}
+
+type LabOrderRequest struct {
+	PatientID     uint   `json:"patient_id" binding:"required"`
+	EncounterID   *uint  `json:"encounter_id"`
+	TestCode      string `json:"test_code" example:"CMP"`
+	TestName      string `json:"test_name" binding:"required" example:"Comprehensive metabolic panel"`
+	Priority      string `json:"priority" example:"routine"`
+	ClinicalNotes string `json:"clinical_notes"`
+}
+
+type LabResultRequest struct {
+	Analyte        string   `json:"analyte" binding:"required" example:"Potassium"`
+	Value          string   `json:"value" binding:"required" example:"5.8"`
+	Unit           string   `json:"unit" example:"mmol/L"`
+	ReferenceRange string   `json:"reference_range" example:"3.5-5.1"`
+	CriticalLow    *float64 `json:"critical_low" example:"2.5"`
+	CriticalHigh   *float64 `json:"critical_high" example:"6.5"`
+	Notes          string   `json:"notes"`
+}
+
+type EnterLabResultsRequest struct {
+	Results []LabResultRequest `json:"results" binding:"required,dive"`
+	Final   bool               `json:"final"`
+}
+
+// @Summary Create Lab Order
+// @Description Order a lab test for a patient (Doctor only)
+// @Tags labs
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body LabOrderRequest true "Order details"
+// @Success 201 {object} models.LabOrder
+// @Router /api/lab-orders [post]
+func (h *LabHandler) CreateOrder(c *gin.Context) {
+	var req LabOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	order := &models.LabOrder{
+		PatientID:     req.PatientID,
+		DoctorID:      currentUserID(c),
+		EncounterID:   req.EncounterID,
+		TestCode:      req.TestCode,
+		TestName:      req.TestName,
+		Priority:      models.LabPriority(req.Priority),
+		ClinicalNotes: req.ClinicalNotes,
+	}
+
+	if err := h.labs(c).CreateOrder(order); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, order)
+}
+
+// @Summary Get Lab Order
+// @Description Get a lab order with its results
+// @Tags labs
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Lab order ID"
+// @Success 200 {object} models.LabOrder
+// @Router /api/lab-orders/{id} [get]
+func (h *LabHandler) GetOrderByID(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid lab order ID"})
+		return
+	}
+
+	order, err := h.labs(c).GetOrderByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Lab order not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, order)
+}
+
+// @Summary Get Patient Lab Orders
+// @Description Get a patient's lab orders and results, newest first
+// @Tags labs
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Patient ID"
+// @Success 200 {array} models.LabOrder
+// @Router /api/patients/{id}/lab-orders [get]
+func (h *LabHandler) GetPatientOrders(c *gin.Context) {
+	patientID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid patient ID"})
+		return
+	}
+
+	orders, err := h.labs(c).GetPatientOrders(uint(patientID))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, orders)
+}
+
+// @Summary Cancel Lab Order
+// @Description Cancel an order that has no results yet (ordering doctor only)
+// @Tags labs
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Lab order ID"
+// @Success 200 {object} models.LabOrder
+// @Router /api/lab-orders/{id}/cancel [post]
+func (h *LabHandler) CancelOrder(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid lab order ID"})
+		return
+	}
+
+	order, err := h.labs(c).CancelOrder(uint(id), currentUserID(c))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, order)
+}
+
+// @Summary Enter Lab Results
+// @Description Enter a batch of results; values are flagged against their reference ranges
+// @Tags labs
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Lab order ID"
+// @Param request body EnterLabResultsRequest true "Results"
+// @Success 200 {object} models.LabOrder
+// @Router /api/lab-orders/{id}/results [post]
+func (h *LabHandler) EnterResults(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid lab order ID"})
+		return
+	}
+
+	var req EnterLabResultsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	results := make([]models.LabResult, len(req.Results))
+	for i, result := range req.Results {
+		results[i] = models.LabResult{
+			Analyte:        result.Analyte,
+			Value:          result.Value,
+			Unit:           result.Unit,
+			ReferenceRange: result.ReferenceRange,
+			CriticalLow:    result.CriticalLow,
+			CriticalHigh:   result.CriticalHigh,
+			Notes:          result.Notes,
+		}
+	}
+
+	order, err := h.labs(c).EnterResults(uint(id), currentUserID(c), results, req.Final)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, order)
+}
+
+// @Summary Get Results Inbox
+// @Description Get the caller's lab orders with results to review, critical and abnormal first (Doctor only)
+// @Tags labs
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} models.LabOrder
+// @Router /api/lab-orders/inbox [get]
+func (h *LabHandler) GetReviewInbox(c *gin.Context) {
+	orders, err := h.labs(c).GetReviewInbox(currentUserID(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, orders)
+}
+
+// @Summary Acknowledge Lab Results
+// @Description Mark the results of an order as reviewed (ordering doctor only)
+// @Tags labs
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Lab order ID"
+// @Success 200 {object} models.LabOrder
+// @Router /api/lab-orders/{id}/acknowledge [post]
+func (h *LabHandler) AcknowledgeOrder(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid lab order ID"})
+		return
+	}
+
+	order, err := h.labs(c).AcknowledgeOrder(uint(id), currentUserID(c))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, order)
+}
@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"healthcare-portal/internal/query"
+
+	"github.com/gin-gonic/gin"
+)
+
+// listRequest reads the filter, sort, limit and cursor parameters of a list
+// endpoint. It writes a 400 and returns false when they are malformed.
+func listRequest(c *gin.Context) (query.Request, bool) {
+	req, err := query.ParseRequest(c.Request.URL.Query())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return req, false
+	}
+	return req, true
+}
+
+// respondList writes a page of a list endpoint, with links to the page and
+// to its neighbours. Requests the list spec rejects are a 400.
+func respondList[T any](c *gin.Context, page *query.Page[T], err error) {
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, query.ErrInvalidQuery) {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	page.Links.Self = c.Request.URL.RequestURI()
+	if page.Meta.NextCursor != "" {
+		page.Links.Next = cursorLink(c, page.Meta.NextCursor)
+	}
+	if page.Meta.PrevCursor != "" {
+		page.Links.Prev = cursorLink(c, page.Meta.PrevCursor)
+	}
+	c.JSON(http.StatusOK, page)
+}
+
+// cursorLink is the request URL moved to cursor.
+func cursorLink(c *gin.Context, cursor string) string {
+	link := *c.Request.URL
+	values := link.Query()
+	values.Del(query.ParamPage)
+	values.Set(query.ParamCursor, cursor)
+	link.RawQuery = values.Encode()
+	return link.RequestURI()
+}
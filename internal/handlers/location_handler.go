@@ -0,0 +1,275 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"healthcare-portal/internal/models"
+	"healthcare-portal/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type LocationHandler struct {
+	locationService services.LocationService
+}
+
+func NewLocationHandler(locationService services.LocationService) *LocationHandler {
+	return &LocationHandler{locationService: locationService}
+}
+
+// locations returns the service scoped to the caller's tenant.
+func (h *LocationHandler) locations(c *gin.Context) services.LocationService {
+	return h.locationService.WithTenant(tenantID(c))
+}
+
+type LocationRequest struct {
+	Code     string `json:"code" binding:"required"`
+	Name     string `json:"name" binding:"required"`
+	Address  string `json:"address"`
+	Phone    string `json:"phone"`
+	TimeZone string `json:"time_zone"`
+	IsActive *bool  `json:"is_active"`
+}
+
+type DoctorScheduleRequest struct {
+	DoctorID   uint   `json:"doctor_id" binding:"required"`
+	LocationID uint   `json:"location_id" binding:"required"`
+	DayOfWeek  *int   `json:"day_of_week" binding:"required"`
+	StartTime  string `json:"start_time" binding:"required"`
+	EndTime    string `json:"end_time" binding:"required"`
+}
+
+// @Summary Create Location
+// @Description Create a clinic location (Admin only)
+// @Tags locations
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body LocationRequest true "Location details"
+// @Success 201 {object} models.Location
+// @Router /api/locations [post]
+func (h *LocationHandler) CreateLocation(c *gin.Context) {
+	var req LocationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	location := &models.Location{
+		Code:     req.Code,
+		Name:     req.Name,
+		Address:  req.Address,
+		Phone:    req.Phone,
+		TimeZone: req.TimeZone,
+		IsActive: true,
+	}
+
+	if err := h.locations(c).CreateLocation(location); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, location)
+}
+
+// @Summary Get All Locations
+// @Description Get all clinic locations
+// @Tags locations
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} models.Location
+// @Router /api/locations [get]
+func (h *LocationHandler) GetAllLocations(c *gin.Context) {
+	locations, err := h.locations(c).GetAllLocations()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, locations)
+}
+
+// @Summary Get Location by ID
+// @Description Get a clinic location by ID
+// @Tags locations
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Location ID"
+// @Success 200 {object} models.Location
+// @Router /api/locations/{id} [get]
+func (h *LocationHandler) GetLocationByID(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid location ID"})
+		return
+	}
+
+	location, err := h.locations(c).GetLocationByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Location not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, location)
+}
+
+// @Summary Update Location
+// @Description Update a clinic location (Admin only)
+// @Tags locations
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Location ID"
+// @Param request body LocationRequest true "Updated location details"
+// @Success 200 {object} models.Location
+// @Router /api/locations/{id} [put]
+func (h *LocationHandler) UpdateLocation(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid location ID"})
+		return
+	}
+
+	var req LocationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	location, err := h.locations(c).GetLocationByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Location not found"})
+		return
+	}
+
+	location.Code = req.Code
+	location.Name = req.Name
+	location.Address = req.Address
+	location.Phone = req.Phone
+	location.TimeZone = req.TimeZone
+	if req.IsActive != nil {
+		location.IsActive = *req.IsActive
+	}
+
+	if err := h.locations(c).UpdateLocation(location); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, location)
+}
+
+// @Summary Delete Location
+// @Description Delete a clinic location (Admin only)
+// @Tags locations
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Location ID"
+// @Success 200 {object} map[string]string
+// @Router /api/locations/{id} [delete]
+func (h *LocationHandler) DeleteLocation(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid location ID"})
+		return
+	}
+
+	if err := h.locations(c).DeleteLocation(uint(id)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Location deleted successfully"})
+}
+
+// @Summary Create Doctor Schedule
+// @Description Assign a doctor to a location for a weekly block of hours (Admin only)
+// @Tags schedules
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body DoctorScheduleRequest true "Schedule block"
+// @Success 201 {object} models.DoctorSchedule
+// @Router /api/schedules [post]
+func (h *LocationHandler) CreateSchedule(c *gin.Context) {
+	var req DoctorScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	schedule := &models.DoctorSchedule{
+		DoctorID:   req.DoctorID,
+		LocationID: req.LocationID,
+		DayOfWeek:  time.Weekday(*req.DayOfWeek),
+		StartTime:  req.StartTime,
+		EndTime:    req.EndTime,
+	}
+
+	if err := h.locations(c).CreateSchedule(schedule); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, schedule)
+}
+
+// @Summary Get Doctor Schedules
+// @Description Get weekly schedule blocks, optionally filtered by doctor and location
+// @Tags schedules
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param doctor_id query int false "Doctor ID"
+// @Param location_id query int false "Location ID"
+// @Success 200 {array} models.DoctorSchedule
+// @Router /api/schedules [get]
+func (h *LocationHandler) GetSchedules(c *gin.Context) {
+	doctorID, err := optionalUintQuery(c, "doctor_id")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid doctor ID"})
+		return
+	}
+	locationID, err := optionalUintQuery(c, "location_id")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid location ID"})
+		return
+	}
+
+	schedules, err := h.locations(c).GetSchedules(doctorID, locationID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, schedules)
+}
+
+// @Summary Delete Doctor Schedule
+// @Description Remove a schedule block (Admin only)
+// @Tags schedules
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Schedule ID"
+// @Success 200 {object} map[string]string
+// @Router /api/schedules/{id} [delete]
+func (h *LocationHandler) DeleteSchedule(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid schedule ID"})
+		return
+	}
+
+	if err := h.locations(c).DeleteSchedule(uint(id)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Schedule deleted successfully"})
+}
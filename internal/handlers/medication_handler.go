@@ -0,0 +1,445 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"healthcare-portal/internal/models"
+	"healthcare-portal/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type MedicationHandler struct {
+	medicationService services.MedicationService
+}
+
+func NewMedicationHandler(medicationService services.MedicationService) *MedicationHandler {
+	return &MedicationHandler{medicationService: medicationService}
+}
+
+// medications returns the service scoped to the caller's tenant.
+func (h *MedicationHandler) medications(c *gin.Context) services.MedicationService {
+	return h.medicationService.WithTenant(tenantID(c))
+}
+
+type MedicationStatementRequest struct {
+	Drug      string `json:"drug" binding:"required"`
+	Dose      string `json:"dose"`
+	Route     string `json:"route"`
+	Frequency string `json:"frequency"`
+	StartDate string `json:"start_date"`
+	StopDate  string `json:"stop_date"`
+	Status    string `json:"status"`
+	Notes     string `json:"notes"`
+}
+
+type StopMedicationRequest struct {
+	StopDate string `json:"stop_date"`
+}
+
+type PrescriptionRequest struct {
+	PatientID    uint   `json:"patient_id" binding:"required"`
+	EncounterID  *uint  `json:"encounter_id"`
+	Drug         string `json:"drug" binding:"required"`
+	Dose         string `json:"dose" binding:"required"`
+	Route        string `json:"route"`
+	Frequency    string `json:"frequency" binding:"required"`
+	Quantity     string `json:"quantity"`
+	DurationDays int    `json:"duration_days"`
+	Refills      int    `json:"refills"`
+	Instructions string `json:"instructions"`
+	// OverrideReason acknowledges the interaction warnings returned by a
+	// previous attempt.
+	OverrideReason string `json:"override_reason"`
+}
+
+func (req PrescriptionRequest) prescription(doctorID uint) *models.Prescription {
+	return &models.Prescription{
+		PatientID:      req.PatientID,
+		DoctorID:       doctorID,
+		EncounterID:    req.EncounterID,
+		Drug:           req.Drug,
+		Dose:           req.Dose,
+		Route:          models.MedicationRoute(req.Route),
+		Frequency:      req.Frequency,
+		Quantity:       req.Quantity,
+		DurationDays:   req.DurationDays,
+		Refills:        req.Refills,
+		Instructions:   req.Instructions,
+		OverrideReason: req.OverrideReason,
+	}
+}
+
+func (req MedicationStatementRequest) apply(statement *models.MedicationStatement) error {
+	startDate, err := parseOptionalDate(req.StartDate)
+	if err != nil {
+		return err
+	}
+	stopDate, err := parseOptionalDate(req.StopDate)
+	if err != nil {
+		return err
+	}
+
+	statement.Drug = req.Drug
+	statement.Dose = req.Dose
+	statement.Route = models.MedicationRoute(req.Route)
+	statement.Frequency = req.Frequency
+	statement.StartDate = startDate
+	statement.StopDate = stopDate
+	statement.Status = models.MedicationStatus(req.Status)
+	statement.Notes = req.Notes
+	return nil
+}
+
+// @Summary Get Patient Medications
+// @Description Get a patient's medication list
+// @Tags medications
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Patient ID"
+// @Param active query bool false "Only active medications"
+// @Success 200 {array} models.MedicationStatement
+// @Router /api/patients/{id}/medications [get]
+func (h *MedicationHandler) GetPatientMedications(c *gin.Context) {
+	patientID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid patient ID"})
+		return
+	}
+	activeOnly := c.Query("active") == "true"
+
+	statements, err := h.medications(c).GetPatientMedications(uint(patientID), activeOnly)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, statements)
+}
+
+// @Summary Add Medication
+// @Description Add a medication to a patient's medication list
+// @Tags medications
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Patient ID"
+// @Param request body MedicationStatementRequest true "Medication details"
+// @Success 201 {object} models.MedicationStatement
+// @Router /api/patients/{id}/medications [post]
+func (h *MedicationHandler) CreateStatement(c *gin.Context) {
+	patientID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid patient ID"})
+		return
+	}
+
+	var req MedicationStatementRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	statement := &models.MedicationStatement{PatientID: uint(patientID), RecordedBy: currentUserID(c)}
+	if err := req.apply(statement); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date format"})
+		return
+	}
+
+	if err := h.medications(c).CreateStatement(statement); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, statement)
+}
+
+// @Summary Update Medication
+// @Description Update a medication list entry
+// @Tags medications
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Medication ID"
+// @Param request body MedicationStatementRequest true "Updated medication details"
+// @Success 200 {object} models.MedicationStatement
+// @Router /api/medications/{id} [put]
+func (h *MedicationHandler) UpdateStatement(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid medication ID"})
+		return
+	}
+
+	var req MedicationStatementRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	statement, err := h.medications(c).GetStatementByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Medication not found"})
+		return
+	}
+	if err := req.apply(statement); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date format"})
+		return
+	}
+
+	if err := h.medications(c).UpdateStatement(statement); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, statement)
+}
+
+// @Summary Stop Medication
+// @Description Mark a medication as stopped (defaults to today)
+// @Tags medications
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Medication ID"
+// @Param request body StopMedicationRequest false "Stop date"
+// @Success 200 {object} models.MedicationStatement
+// @Router /api/medications/{id}/stop [post]
+func (h *MedicationHandler) StopStatement(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid medication ID"})
+		return
+	}
+
+	var req StopMedicationRequest
+	_ = c.ShouldBindJSON(&req)
+	stopDate, err := parseOptionalDate(req.StopDate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date format"})
+		return
+	}
+	stoppedOn := time.Now()
+	if stopDate != nil {
+		stoppedOn = *stopDate
+	}
+
+	statement, err := h.medications(c).StopStatement(uint(id), stoppedOn)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, statement)
+}
+
+// @Summary Delete Medication
+// @Description Delete a medication list entry recorded in error
+// @Tags medications
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Medication ID"
+// @Success 200 {object} map[string]string
+// @Router /api/medications/{id} [delete]
+func (h *MedicationHandler) DeleteStatement(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid medication ID"})
+		return
+	}
+
+	if err := h.medications(c).DeleteStatement(uint(id)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Medication deleted successfully"})
+}
+
+// @Summary Check Prescription
+// @Description Run drug-allergy and drug-drug interaction checks without issuing the prescription (Doctor only)
+// @Tags prescriptions
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body PrescriptionRequest true "Prescription details"
+// @Success 200 {array} models.InteractionWarning
+// @Router /api/prescriptions/check [post]
+func (h *MedicationHandler) CheckPrescription(c *gin.Context) {
+	var req PrescriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	warnings, err := h.medications(c).CheckPrescription(req.prescription(currentUserID(c)))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if warnings == nil {
+		warnings = []models.InteractionWarning{}
+	}
+
+	c.JSON(http.StatusOK, warnings)
+}
+
+// @Summary Create Prescription
+// @Description Issue a prescription (Doctor only). If interaction checks raise warnings the request fails with 409 and the warnings; resend it with an override_reason to issue anyway.
+// @Tags prescriptions
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body PrescriptionRequest true "Prescription details"
+// @Success 201 {object} models.Prescription
+// @Failure 409 {object} map[string]interface{}
+// @Router /api/prescriptions [post]
+func (h *MedicationHandler) CreatePrescription(c *gin.Context) {
+	var req PrescriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	prescription := req.prescription(currentUserID(c))
+	if err := h.medications(c).CreatePrescription(prescription); err != nil {
+		var warningsErr *services.InteractionWarningsError
+		if errors.As(err, &warningsErr) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "warnings": warningsErr.Warnings})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, prescription)
+}
+
+// @Summary Get Prescription by ID
+// @Description Get a prescription by ID
+// @Tags prescriptions
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Prescription ID"
+// @Success 200 {object} models.Prescription
+// @Router /api/prescriptions/{id} [get]
+func (h *MedicationHandler) GetPrescriptionByID(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid prescription ID"})
+		return
+	}
+
+	prescription, err := h.medications(c).GetPrescriptionByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Prescription not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, prescription)
+}
+
+// @Summary Get Patient Prescriptions
+// @Description Get a patient's prescriptions, newest first
+// @Tags prescriptions
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Patient ID"
+// @Success 200 {array} models.Prescription
+// @Router /api/patients/{id}/prescriptions [get]
+func (h *MedicationHandler) GetPatientPrescriptions(c *gin.Context) {
+	patientID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid patient ID"})
+		return
+	}
+
+	prescriptions, err := h.medications(c).GetPatientPrescriptions(uint(patientID))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, prescriptions)
+}
+
+// @Summary Refill Prescription
+// @Description Record that a refill was dispensed
+// @Tags prescriptions
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Prescription ID"
+// @Success 200 {object} models.Prescription
+// @Router /api/prescriptions/{id}/refill [post]
+func (h *MedicationHandler) RefillPrescription(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid prescription ID"})
+		return
+	}
+
+	prescription, err := h.medications(c).RefillPrescription(uint(id))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, prescription)
+}
+
+// @Summary Cancel Prescription
+// @Description Cancel a prescription (Prescribing doctor only)
+// @Tags prescriptions
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Prescription ID"
+// @Success 200 {object} models.Prescription
+// @Router /api/prescriptions/{id}/cancel [post]
+func (h *MedicationHandler) CancelPrescription(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid prescription ID"})
+		return
+	}
+
+	prescription, err := h.medications(c).CancelPrescription(uint(id), currentUserID(c))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, prescription)
+}
+
+// @Summary Print Prescription
+// @Description Get a printable plain-text prescription
+// @Tags prescriptions
+// @Produce plain
+// @Security BearerAuth
+// @Param id path int true "Prescription ID"
+// @Success 200 {string} string
+// @Router /api/prescriptions/{id}/print [get]
+func (h *MedicationHandler) PrintPrescription(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid prescription ID"})
+		return
+	}
+
+	text, err := h.medications(c).PrintPrescription(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.String(http.StatusOK, text)
+}
@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// tenantID returns the tenant resolved from the caller's token by
+// AuthMiddleware.
+func tenantID(c *gin.Context) uint {
+	id, _ := c.Get("tenantID")
+	tenant, _ := id.(uint)
+	return tenant
+}
+
+// currentUserID returns the authenticated user set by AuthMiddleware.
+func currentUserID(c *gin.Context) uint {
+	id, _ := c.Get("userID")
+	user, _ := id.(uint)
+	return user
+}
+
+// optionalUintQuery parses an optional numeric ID from the query string. It
+// returns nil when the parameter is absent.
+func optionalUintQuery(c *gin.Context, name string) (*uint, error) {
+	value := c.Query(name)
+	if value == "" {
+		return nil, nil
+	}
+	id, err := strconv.ParseUint(value, 10, 32)
+	if err != nil {
+		return nil, err
+	}
+	parsed := uint(id)
+	return &parsed, nil
+}
+
+// parseOptionalDate parses a YYYY-MM-DD request field. It returns nil for an
+// empty value.
+func parseOptionalDate(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	date, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return nil, err
+	}
+	return &date, nil
+}
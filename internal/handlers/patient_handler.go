@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 	"time"
@@ -19,6 +20,11 @@ func NewPatientHandler(patientService services.PatientService) *PatientHandler {
 	return &PatientHandler{patientService: patientService}
 }
 
+// patients returns the service scoped to the caller's tenant.
+func (h *PatientHandler) patients(c *gin.Context) services.PatientService {
+	return h.patientService.WithTenant(tenantID(c))
+}
+
 type CreatePatientRequest struct {
 	FirstName         string `json:"first_name" binding:"required"`
 	LastName          string `json:"last_name" binding:"required"`
@@ -33,16 +39,51 @@ type CreatePatientRequest struct {
 	EmergencyContact  string `json:"emergency_contact"`
 	BloodGroup        string `json:"blood_group"`
 	InsuranceNumber   string `json:"insurance_number"`
+	// DuplicateOverrideReason confirms registration after a 409 listed
+	// possible duplicates.
+	DuplicateOverrideReason string `json:"duplicate_override_reason"`
+	// RegistrationLocationID, Identifiers and RelatedPersons are only used
+	// on registration. Patients under 18 must be registered with a guardian.
+	RegistrationLocationID *uint                  `json:"registration_location_id"`
+	Identifiers            []IdentifierRequest    `json:"identifiers"`
+	RelatedPersons         []RelatedPersonRequest `json:"related_persons"`
+}
+
+type IdentifierRequest struct {
+	System   string `json:"system" binding:"required" example:"national_id"`
+	Value    string `json:"value" binding:"required"`
+	Assigner string `json:"assigner"`
+}
+
+type MergePatientRequest struct {
+	DuplicateID uint   `json:"duplicate_id" binding:"required"`
+	Reason      string `json:"reason" binding:"required"`
+}
+
+// patientError maps service errors to HTTP status codes.
+func patientError(c *gin.Context, err error) {
+	var mergedErr *services.PatientMergedError
+	switch {
+	case errors.As(err, &mergedErr):
+		c.JSON(http.StatusGone, gin.H{"error": err.Error(), "merged_into_id": mergedErr.MergedIntoID})
+	case errors.Is(err, services.ErrPatientNotFound), errors.Is(err, services.ErrIdentifierNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrIdentifierInUse), errors.Is(err, services.ErrGuardianRequired):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	}
 }
 
 // @Summary Create Patient
-// @Description Create a new patient (Receptionist only)
+// @Description Create a new patient (Receptionist only). The patient is issued an MRN, from the registration location's sequence when one is given. If the patient looks like one already on file the request fails with 409 and the candidate matches; resend it with a duplicate_override_reason to register anyway.
 // @Tags patients
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param request body CreatePatientRequest true "Patient details"
 // @Success 201 {object} models.Patient
+// @Failure 409 {object} map[string]interface{}
 // @Router /api/patients [post]
 func (h *PatientHandler) CreatePatient(c *gin.Context) {
 	var req CreatePatientRequest
@@ -68,6 +109,19 @@ func (h *PatientHandler) CreatePatient(c *gin.Context) {
 		InsuranceNumber:   req.InsuranceNumber,
 		RegisteredBy:      userID.(uint),
 		LastUpdatedBy:     userID.(uint),
+
+		DuplicateOverrideReason: req.DuplicateOverrideReason,
+		RegistrationLocationID:  req.RegistrationLocationID,
+	}
+	for _, identifier := range req.Identifiers {
+		patient.Identifiers = append(patient.Identifiers, models.PatientIdentifier{
+			System:   identifier.System,
+			Value:    identifier.Value,
+			Assigner: identifier.Assigner,
+		})
+	}
+	for _, person := range req.RelatedPersons {
+		patient.RelatedPersons = append(patient.RelatedPersons, person.person())
 	}
 
 	// Parse date of birth
@@ -78,8 +132,13 @@ func (h *PatientHandler) CreatePatient(c *gin.Context) {
 	}
 	patient.DateOfBirth = dob
 
-	if err := h.patientService.CreatePatient(patient); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := h.patients(c).CreatePatient(patient); err != nil {
+		var duplicatesErr *services.PossibleDuplicatesError
+		if errors.As(err, &duplicatesErr) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "candidates": duplicatesErr.Candidates})
+			return
+		}
+		patientError(c, err)
 		return
 	}
 
@@ -87,32 +146,30 @@ func (h *PatientHandler) CreatePatient(c *gin.Context) {
 }
 
 // @Summary Get All Patients
-// @Description Get all patients with pagination
+// @Description List patients a page at a time. Filter by gender, born_from/born_to and created_from/created_to (YYYY-MM-DD); sort by a comma separated list of last_name, first_name, date_of_birth and created_at, each prefixed with - for descending order. Follow meta.next_cursor (or links.next) for the next page.
 // @Tags patients
 // @Accept json
 // @Produce json
 // @Security BearerAuth
-// @Param page query int false "Page number"
-// @Param limit query int false "Items per page"
+// @Param gender query string false "Gender"
+// @Param born_from query string false "Born on or after (YYYY-MM-DD)"
+// @Param born_to query string false "Born on or before (YYYY-MM-DD)"
+// @Param created_from query string false "Registered on or after (YYYY-MM-DD)"
+// @Param created_to query string false "Registered on or before (YYYY-MM-DD)"
+// @Param sort query string false "Sort fields" default(-created_at)
+// @Param limit query int false "Items per page (at most 100)" default(20)
+// @Param cursor query string false "Cursor from a previous page"
+// @Param page query int false "Page number, for clients not using cursors"
 // @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
 // @Router /api/patients [get]
 func (h *PatientHandler) GetAllPatients(c *gin.Context) {
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
-	offset := (page - 1) * limit
-
-	patients, total, err := h.patientService.GetAllPatients(limit, offset)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	req, ok := listRequest(c)
+	if !ok {
 		return
 	}
-
-	c.JSON(http.StatusOK, gin.H{
-		"patients": patients,
-		"total":    total,
-		"page":     page,
-		"limit":    limit,
-	})
+	page, err := h.patients(c).ListPatients(req)
+	respondList(c, page, err)
 }
 
 // @Summary Get Patient by ID
@@ -123,6 +180,7 @@ func (h *PatientHandler) GetAllPatients(c *gin.Context) {
 // @Security BearerAuth
 // @Param id path int true "Patient ID"
 // @Success 200 {object} models.Patient
+// @Failure 410 {object} map[string]interface{}
 // @Router /api/patients/{id} [get]
 func (h *PatientHandler) GetPatientByID(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
@@ -131,8 +189,13 @@ func (h *PatientHandler) GetPatientByID(c *gin.Context) {
 		return
 	}
 
-	patient, err := h.patientService.GetPatientByID(uint(id))
+	patient, err := h.patients(c).GetPatientByID(uint(id))
 	if err != nil {
+		var mergedErr *services.PatientMergedError
+		if errors.As(err, &mergedErr) {
+			patientError(c, err)
+			return
+		}
 		c.JSON(http.StatusNotFound, gin.H{"error": "Patient not found"})
 		return
 	}
@@ -163,7 +226,7 @@ func (h *PatientHandler) UpdatePatient(c *gin.Context) {
 		return
 	}
 
-	patient, err := h.patientService.GetPatientByID(uint(id))
+	patient, err := h.patients(c).GetPatientByID(uint(id))
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Patient not found"})
 		return
@@ -194,8 +257,8 @@ func (h *PatientHandler) UpdatePatient(c *gin.Context) {
 	}
 	patient.DateOfBirth = dob
 
-	if err := h.patientService.UpdatePatient(patient); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := h.patients(c).UpdatePatient(patient); err != nil {
+		patientError(c, err)
 		return
 	}
 
@@ -218,7 +281,7 @@ func (h *PatientHandler) DeletePatient(c *gin.Context) {
 		return
 	}
 
-	if err := h.patientService.DeletePatient(uint(id)); err != nil {
+	if err := h.patients(c).DeletePatient(uint(id)); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -227,26 +290,244 @@ func (h *PatientHandler) DeletePatient(c *gin.Context) {
 }
 
 // @Summary Search Patients
-// @Description Search patients by name, email, or phone
+// @Description Ranked, typo-tolerant patient search. Every word of q must match a name, email, phone or MRN; names also match misspellings and sound-alikes. Give q, dob or mrn.
 // @Tags patients
 // @Accept json
 // @Produce json
 // @Security BearerAuth
-// @Param q query string true "Search query"
-// @Success 200 {array} models.Patient
+// @Param q query string false "Search text"
+// @Param dob query string false "Date of birth (YYYY-MM-DD)"
+// @Param mrn query string false "Current or previous MRN"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Results per page (max 100)" default(20)
+// @Success 200 {object} models.PatientSearchPage
 // @Router /api/patients/search [get]
 func (h *PatientHandler) SearchPatients(c *gin.Context) {
-	query := c.Query("q")
-	if query == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Search query required"})
+	dob, err := parseOptionalDate(c.Query("dob"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date of birth, expected YYYY-MM-DD"})
 		return
 	}
-
-	patients, err := h.patientService.SearchPatients(query)
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	results, err := h.patients(c).SearchPatients(models.PatientSearchQuery{
+		Text:        c.Query("q"),
+		DateOfBirth: dob,
+		MRN:         c.Query("mrn"),
+		Page:        page,
+		Limit:       limit,
+	})
 	if err != nil {
+		if errors.Is(err, services.ErrSearchCriteria) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
+	c.JSON(http.StatusOK, results)
+}
+
+// @Summary Get Possible Duplicates
+// @Description List registered patients that may be the same person as this one
+// @Tags patients
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Patient ID"
+// @Success 200 {array} models.DuplicateCandidate
+// @Router /api/patients/{id}/duplicates [get]
+func (h *PatientHandler) GetDuplicates(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid patient ID"})
+		return
+	}
+
+	candidates, err := h.patients(c).GetDuplicates(uint(id))
+	if err != nil {
+		patientError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, candidates)
+}
+
+// @Summary Merge Patients
+// @Description Merge a duplicate record into this patient (Admin only). Appointments and clinical records move to this patient and the duplicate is replaced by a tombstone.
+// @Tags patients
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Surviving patient ID"
+// @Param request body MergePatientRequest true "Duplicate to merge"
+// @Success 200 {object} models.PatientMerge
+// @Router /api/patients/{id}/merge [post]
+func (h *PatientHandler) MergePatient(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid patient ID"})
+		return
+	}
+
+	var req MergePatientRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	merge, err := h.patients(c).MergePatients(uint(id), req.DuplicateID, currentUserID(c), req.Reason)
+	if err != nil {
+		patientError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, merge)
+}
+
+// @Summary Get Patient Merges
+// @Description List the tombstones of records merged into this patient
+// @Tags patients
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Patient ID"
+// @Success 200 {array} models.PatientMerge
+// @Router /api/patients/{id}/merges [get]
+func (h *PatientHandler) GetPatientMerges(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid patient ID"})
+		return
+	}
+
+	merges, err := h.patients(c).GetPatientMerges(uint(id))
+	if err != nil {
+		patientError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, merges)
+}
+
+// @Summary Look Up Patients by Identifier
+// @Description Find patients by MRN (including MRNs of merged records) or an external identifier. Without a system the value is matched in every system.
+// @Tags patients
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param value query string true "Identifier value"
+// @Param system query string false "Identifier system, e.g. mrn or national_id"
+// @Success 200 {array} models.Patient
+// @Router /api/patients/lookup [get]
+func (h *PatientHandler) LookupPatients(c *gin.Context) {
+	value := c.Query("value")
+	if value == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Identifier value required"})
+		return
+	}
+
+	patients, err := h.patients(c).LookupPatients(c.Query("system"), value)
+	if err != nil {
+		patientError(c, err)
+		return
+	}
+
 	c.JSON(http.StatusOK, patients)
 }
+
+// @Summary Get Patient Identifiers
+// @Description List a patient's external identifiers
+// @Tags patients
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Patient ID"
+// @Success 200 {array} models.PatientIdentifier
+// @Router /api/patients/{id}/identifiers [get]
+func (h *PatientHandler) GetIdentifiers(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid patient ID"})
+		return
+	}
+
+	identifiers, err := h.patients(c).GetIdentifiers(uint(id))
+	if err != nil {
+		patientError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, identifiers)
+}
+
+// @Summary Add Patient Identifier
+// @Description Attach an external identifier such as a national ID or insurer member ID (Receptionist or Admin). A value can belong to one patient per system.
+// @Tags patients
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Patient ID"
+// @Param request body IdentifierRequest true "Identifier"
+// @Success 201 {object} models.PatientIdentifier
+// @Failure 409 {object} map[string]string
+// @Router /api/patients/{id}/identifiers [post]
+func (h *PatientHandler) AddIdentifier(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid patient ID"})
+		return
+	}
+
+	var req IdentifierRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	identifier := &models.PatientIdentifier{
+		PatientID: uint(id),
+		System:    req.System,
+		Value:     req.Value,
+		Assigner:  req.Assigner,
+		CreatedBy: currentUserID(c),
+	}
+	if err := h.patients(c).AddIdentifier(identifier); err != nil {
+		patientError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, identifier)
+}
+
+// @Summary Remove Patient Identifier
+// @Description Remove an external identifier from a patient (Receptionist or Admin)
+// @Tags patients
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Patient ID"
+// @Param identifierId path int true "Identifier ID"
+// @Success 200 {object} map[string]string
+// @Router /api/patients/{id}/identifiers/{identifierId} [delete]
+func (h *PatientHandler) RemoveIdentifier(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid patient ID"})
+		return
+	}
+	identifierID, err := strconv.ParseUint(c.Param("identifierId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid identifier ID"})
+		return
+	}
+
+	if err := h.patients(c).RemoveIdentifier(uint(id), uint(identifierID)); err != nil {
+		patientError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Identifier removed successfully"})
+}
@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"errors"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"healthcare-portal/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PrintoutHandler struct {
+	printoutService services.PrintoutService
+}
+
+func NewPrintoutHandler(printoutService services.PrintoutService) *PrintoutHandler {
+	return &PrintoutHandler{printoutService: printoutService}
+}
+
+// printouts returns the service scoped to the caller's tenant.
+func (h *PrintoutHandler) printouts(c *gin.Context) services.PrintoutService {
+	return h.printoutService.WithTenant(tenantID(c))
+}
+
+// printoutError maps service errors to HTTP status codes.
+func printoutError(c *gin.Context, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, services.ErrInvoiceNotFound), errors.Is(err, services.ErrPaymentNotFound),
+		errors.Is(err, services.ErrAppointmentNotFound), errors.Is(err, services.ErrPatientNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, services.ErrAppointmentNotBooked), errors.Is(err, services.ErrVisitNotCompleted):
+		status = http.StatusConflict
+	}
+	c.JSON(status, gin.H{"error": err.Error()})
+}
+
+// sendPrintout writes the PDF as a download, or for display in the browser
+// when inline=true.
+func sendPrintout(c *gin.Context, printout *services.Printout) {
+	disposition := "attachment"
+	if c.Query("inline") == "true" {
+		disposition = "inline"
+	}
+	c.Header("Content-Disposition", mime.FormatMediaType(disposition, map[string]string{"filename": printout.FileName}))
+	c.Header("Cache-Control", "no-store")
+	c.Data(http.StatusOK, "application/pdf", printout.Content)
+}
+
+// @Summary Print Invoice
+// @Description Download the invoice as a branded PDF with its charges, payments and balance due
+// @Tags printouts
+// @Produce application/pdf
+// @Security BearerAuth
+// @Param id path int true "Invoice ID"
+// @Param inline query bool false "Display inline"
+// @Success 200 {file} file
+// @Router /api/invoices/{id}/pdf [get]
+func (h *PrintoutHandler) InvoicePDF(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid invoice ID"})
+		return
+	}
+
+	printout, err := h.printouts(c).InvoicePDF(uint(id))
+	if err != nil {
+		printoutError(c, err)
+		return
+	}
+
+	sendPrintout(c, printout)
+}
+
+// @Summary Print Payment Receipt
+// @Description Download a PDF receipt for a payment or refund recorded against the invoice
+// @Tags printouts
+// @Produce application/pdf
+// @Security BearerAuth
+// @Param id path int true "Invoice ID"
+// @Param paymentId path int true "Payment ID"
+// @Param inline query bool false "Display inline"
+// @Success 200 {file} file
+// @Router /api/invoices/{id}/payments/{paymentId}/receipt [get]
+func (h *PrintoutHandler) ReceiptPDF(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid invoice ID"})
+		return
+	}
+	paymentID, err := strconv.ParseUint(c.Param("paymentId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid payment ID"})
+		return
+	}
+
+	printout, err := h.printouts(c).ReceiptPDF(uint(id), uint(paymentID))
+	if err != nil {
+		printoutError(c, err)
+		return
+	}
+
+	sendPrintout(c, printout)
+}
+
+// @Summary Print Appointment Confirmation
+// @Description Download a PDF letter confirming a scheduled appointment, with any preparation instructions for its type
+// @Tags printouts
+// @Produce application/pdf
+// @Security BearerAuth
+// @Param id path int true "Appointment ID"
+// @Param inline query bool false "Display inline"
+// @Success 200 {file} file
+// @Router /api/appointments/{id}/confirmation [get]
+func (h *PrintoutHandler) AppointmentConfirmationPDF(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid appointment ID"})
+		return
+	}
+
+	printout, err := h.printouts(c).AppointmentConfirmationPDF(uint(id))
+	if err != nil {
+		printoutError(c, err)
+		return
+	}
+
+	sendPrintout(c, printout)
+}
+
+// @Summary Print After-Visit Summary
+// @Description Download the PDF summary a patient takes home from a completed visit: diagnoses, vital signs, current medications, allergies and upcoming appointments
+// @Tags printouts
+// @Produce application/pdf
+// @Security BearerAuth
+// @Param id path int true "Appointment ID"
+// @Param inline query bool false "Display inline"
+// @Success 200 {file} file
+// @Router /api/appointments/{id}/visit-summary [get]
+func (h *PrintoutHandler) VisitSummaryPDF(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid appointment ID"})
+		return
+	}
+
+	printout, err := h.printouts(c).VisitSummaryPDF(uint(id))
+	if err != nil {
+		printoutError(c, err)
+		return
+	}
+
+	sendPrintout(c, printout)
+}
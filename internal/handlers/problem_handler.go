@@ -0,0 +1,337 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"healthcare-portal/internal/models"
+	"healthcare-portal/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ProblemHandler struct {
+	problemService services.ProblemService
+	codeService    services.DiagnosisCodeService
+}
+
+func NewProblemHandler(problemService services.ProblemService, codeService services.DiagnosisCodeService) *ProblemHandler {
+	return &ProblemHandler{
+		problemService: problemService,
+		codeService:    codeService,
+	}
+}
+
+// problems returns the service scoped to the caller's tenant.
+func (h *ProblemHandler) problems(c *gin.Context) services.ProblemService {
+	return h.problemService.WithTenant(tenantID(c))
+}
+
+type ProblemRequest struct {
+	Code         string `json:"code" binding:"required"`
+	Description  string `json:"description"`
+	Status       string `json:"status"`
+	OnsetDate    string `json:"onset_date" example:"2024-01-15"`
+	ResolvedDate string `json:"resolved_date" example:"2024-03-01"`
+	EncounterID  *uint  `json:"encounter_id"`
+	Notes        string `json:"notes"`
+}
+
+type AppointmentDiagnosesRequest struct {
+	Diagnoses []struct {
+		Code             string `json:"code" binding:"required"`
+		AddToProblemList bool   `json:"add_to_problem_list"`
+	} `json:"diagnoses" binding:"dive"`
+}
+
+// apply copies the request onto problem.
+func (r ProblemRequest) apply(problem *models.Problem) error {
+	onset, err := parseOptionalDate(r.OnsetDate)
+	if err != nil {
+		return err
+	}
+	resolved, err := parseOptionalDate(r.ResolvedDate)
+	if err != nil {
+		return err
+	}
+	problem.Code = r.Code
+	problem.Description = r.Description
+	problem.Status = models.ProblemStatus(r.Status)
+	problem.OnsetDate = onset
+	problem.ResolvedDate = resolved
+	problem.EncounterID = r.EncounterID
+	problem.Notes = r.Notes
+	return nil
+}
+
+// @Summary Search Diagnosis Codes
+// @Description Search the ICD-10 code table by code prefix or description keywords
+// @Tags problems
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param q query string true "Code prefix (E11, E11.9) or keywords (type 2 diabetes)"
+// @Param limit query int false "Maximum results" default(20)
+// @Success 200 {array} models.DiagnosisCode
+// @Router /api/diagnosis-codes [get]
+func (h *ProblemHandler) SearchCodes(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	codes, err := h.codeService.SearchCodes(c.Query("q"), limit)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, codes)
+}
+
+// @Summary Get Diagnosis Code
+// @Description Look up an ICD-10 code
+// @Tags problems
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param code path string true "ICD-10 code"
+// @Success 200 {object} models.DiagnosisCode
+// @Router /api/diagnosis-codes/{code} [get]
+func (h *ProblemHandler) GetCode(c *gin.Context) {
+	code, err := h.codeService.LookupCode(c.Param("code"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, code)
+}
+
+// @Summary Get Patient Problems
+// @Description Get a patient's problem list
+// @Tags problems
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Patient ID"
+// @Param status query string false "Filter by status (active, resolved)"
+// @Success 200 {array} models.Problem
+// @Router /api/patients/{id}/problems [get]
+func (h *ProblemHandler) GetPatientProblems(c *gin.Context) {
+	patientID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid patient ID"})
+		return
+	}
+
+	problems, err := h.problems(c).GetPatientProblems(uint(patientID), models.ProblemStatus(c.Query("status")))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, problems)
+}
+
+// @Summary Create Problem
+// @Description Add a coded diagnosis to a patient's problem list (Doctor only)
+// @Tags problems
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Patient ID"
+// @Param request body ProblemRequest true "Problem details"
+// @Success 201 {object} models.Problem
+// @Router /api/patients/{id}/problems [post]
+func (h *ProblemHandler) CreateProblem(c *gin.Context) {
+	patientID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid patient ID"})
+		return
+	}
+
+	var req ProblemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	problem := &models.Problem{PatientID: uint(patientID), RecordedBy: currentUserID(c)}
+	if err := req.apply(problem); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date format. Use YYYY-MM-DD"})
+		return
+	}
+
+	if err := h.problems(c).CreateProblem(problem); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, problem)
+}
+
+// @Summary Update Problem
+// @Description Update a problem list entry (Doctor only)
+// @Tags problems
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Problem ID"
+// @Param request body ProblemRequest true "Updated problem details"
+// @Success 200 {object} models.Problem
+// @Router /api/problems/{id} [put]
+func (h *ProblemHandler) UpdateProblem(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid problem ID"})
+		return
+	}
+
+	var req ProblemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	problem, err := h.problems(c).GetProblemByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Problem not found"})
+		return
+	}
+	if err := req.apply(problem); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date format. Use YYYY-MM-DD"})
+		return
+	}
+
+	if err := h.problems(c).UpdateProblem(problem); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, problem)
+}
+
+// @Summary Resolve Problem
+// @Description Mark a problem resolved (Doctor only)
+// @Tags problems
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Problem ID"
+// @Param request body object{resolved_date=string} false "Resolution date (YYYY-MM-DD), defaults to today"
+// @Success 200 {object} models.Problem
+// @Router /api/problems/{id}/resolve [post]
+func (h *ProblemHandler) ResolveProblem(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid problem ID"})
+		return
+	}
+
+	var req struct {
+		ResolvedDate string `json:"resolved_date"`
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	resolved, err := parseOptionalDate(req.ResolvedDate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date format. Use YYYY-MM-DD"})
+		return
+	}
+
+	problem, err := h.problems(c).ResolveProblem(uint(id), resolved)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, problem)
+}
+
+// @Summary Delete Problem
+// @Description Delete a problem list entry recorded in error (Doctor only)
+// @Tags problems
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Problem ID"
+// @Success 200 {object} map[string]string
+// @Router /api/problems/{id} [delete]
+func (h *ProblemHandler) DeleteProblem(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid problem ID"})
+		return
+	}
+
+	if err := h.problems(c).DeleteProblem(uint(id)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Problem deleted successfully"})
+}
+
+// @Summary Get Appointment Diagnoses
+// @Description Get the diagnoses attached to an appointment, primary first
+// @Tags problems
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Appointment ID"
+// @Success 200 {array} models.AppointmentDiagnosis
+// @Router /api/appointments/{id}/diagnoses [get]
+func (h *ProblemHandler) GetAppointmentDiagnoses(c *gin.Context) {
+	appointmentID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid appointment ID"})
+		return
+	}
+
+	diagnoses, err := h.problems(c).GetAppointmentDiagnoses(uint(appointmentID))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, diagnoses)
+}
+
+// @Summary Set Appointment Diagnoses
+// @Description Replace the diagnoses of a completed appointment; the first is primary (Doctor only)
+// @Tags problems
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Appointment ID"
+// @Param request body AppointmentDiagnosesRequest true "Diagnoses in order"
+// @Success 200 {array} models.AppointmentDiagnosis
+// @Router /api/appointments/{id}/diagnoses [put]
+func (h *ProblemHandler) SetAppointmentDiagnoses(c *gin.Context) {
+	appointmentID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid appointment ID"})
+		return
+	}
+
+	var req AppointmentDiagnosesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	entries := make([]services.DiagnosisEntry, len(req.Diagnoses))
+	for i, diagnosis := range req.Diagnoses {
+		entries[i] = services.DiagnosisEntry{Code: diagnosis.Code, AddToProblemList: diagnosis.AddToProblemList}
+	}
+
+	diagnoses, err := h.problems(c).SetAppointmentDiagnoses(uint(appointmentID), currentUserID(c), entries)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, diagnoses)
+}
@@ -0,0 +1,287 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"healthcare-portal/internal/models"
+	"healthcare-portal/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ReferralHandler struct {
+	referralService services.ReferralService
+}
+
+func NewReferralHandler(referralService services.ReferralService) *ReferralHandler {
+	return &ReferralHandler{referralService: referralService}
+}
+
+// referrals returns the service scoped to the caller's tenant.
+func (h *ReferralHandler) referrals(c *gin.Context) services.ReferralService {
+	return h.referralService.WithTenant(tenantID(c))
+}
+
+type CreateReferralRequest struct {
+	PatientID         uint   `json:"patient_id" binding:"required"`
+	ReceivingDoctorID *uint  `json:"receiving_doctor_id"`
+	Specialty         string `json:"specialty" example:"cardiology"`
+	Urgency           string `json:"urgency" example:"routine"`
+	Reason            string `json:"reason" binding:"required"`
+	DocumentIDs       []uint `json:"document_ids"`
+}
+
+type ReferralResponseRequest struct {
+	Note string `json:"note"`
+}
+
+type BookReferralRequest struct {
+	AppointmentTypeID *uint  `json:"appointment_type_id"`
+	LocationID        *uint  `json:"location_id"`
+	Date              string `json:"date" binding:"required" example:"2025-03-14"`
+	Time              string `json:"time" binding:"required" example:"10:30"`
+	Notes             string `json:"notes"`
+}
+
+// referralError maps service errors to HTTP status codes.
+func referralError(c *gin.Context, err error) {
+	status := http.StatusBadRequest
+	switch {
+	case errors.Is(err, services.ErrReferralNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, services.ErrNotReferralReceiver), errors.Is(err, services.ErrNotReferralParty):
+		status = http.StatusForbidden
+	case errors.Is(err, services.ErrReferralState):
+		status = http.StatusConflict
+	}
+	c.JSON(status, gin.H{"error": err.Error()})
+}
+
+// @Summary Create Referral
+// @Description Refer a patient to a colleague or to any doctor of a specialty (Doctor only)
+// @Tags referrals
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body CreateReferralRequest true "Referral"
+// @Success 201 {object} models.Referral
+// @Router /api/referrals [post]
+func (h *ReferralHandler) CreateReferral(c *gin.Context) {
+	var req CreateReferralRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	referral := &models.Referral{
+		PatientID:         req.PatientID,
+		ReferringDoctorID: currentUserID(c),
+		ReceivingDoctorID: req.ReceivingDoctorID,
+		Specialty:         req.Specialty,
+		Urgency:           models.ReferralUrgency(req.Urgency),
+		Reason:            req.Reason,
+	}
+	if err := h.referrals(c).CreateReferral(referral, req.DocumentIDs); err != nil {
+		referralError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, referral)
+}
+
+// @Summary Get My Referrals
+// @Description Get the caller's incoming referral inbox, urgent first, or the referrals they have sent (Doctor only)
+// @Tags referrals
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param direction query string false "incoming (default) or outgoing"
+// @Param status query string false "Only this status"
+// @Success 200 {array} models.Referral
+// @Router /api/referrals [get]
+func (h *ReferralHandler) GetMyReferrals(c *gin.Context) {
+	status := models.ReferralStatus(c.Query("status"))
+
+	var referrals []models.Referral
+	var err error
+	switch c.DefaultQuery("direction", "incoming") {
+	case "incoming":
+		referrals, err = h.referrals(c).GetIncomingReferrals(currentUserID(c), status)
+	case "outgoing":
+		referrals, err = h.referrals(c).GetOutgoingReferrals(currentUserID(c), status)
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "direction must be incoming or outgoing"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, referrals)
+}
+
+// @Summary Get Patient Referrals
+// @Description Get a patient's referrals, newest first
+// @Tags referrals
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Patient ID"
+// @Success 200 {array} models.Referral
+// @Router /api/patients/{id}/referrals [get]
+func (h *ReferralHandler) GetPatientReferrals(c *gin.Context) {
+	patientID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid patient ID"})
+		return
+	}
+
+	referrals, err := h.referrals(c).GetPatientReferrals(uint(patientID))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, referrals)
+}
+
+// @Summary Get Referral
+// @Description Get a referral with its attachments and booked appointment
+// @Tags referrals
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Referral ID"
+// @Success 200 {object} models.Referral
+// @Router /api/referrals/{id} [get]
+func (h *ReferralHandler) GetReferralByID(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid referral ID"})
+		return
+	}
+
+	referral, err := h.referrals(c).GetReferralByID(uint(id))
+	if err != nil {
+		referralError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, referral)
+}
+
+// @Summary Accept Referral
+// @Description Accept a referral addressed to you or to your specialty (Doctor only)
+// @Tags referrals
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Referral ID"
+// @Param request body ReferralResponseRequest false "Optional note to the referring doctor"
+// @Success 200 {object} models.Referral
+// @Router /api/referrals/{id}/accept [post]
+func (h *ReferralHandler) AcceptReferral(c *gin.Context) {
+	h.respond(c, h.referrals(c).AcceptReferral)
+}
+
+// @Summary Decline Referral
+// @Description Decline a referral with a reason (Doctor only)
+// @Tags referrals
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Referral ID"
+// @Param request body ReferralResponseRequest true "Reason for declining"
+// @Success 200 {object} models.Referral
+// @Router /api/referrals/{id}/decline [post]
+func (h *ReferralHandler) DeclineReferral(c *gin.Context) {
+	h.respond(c, h.referrals(c).DeclineReferral)
+}
+
+// @Summary Close Referral
+// @Description Close a referral once the patient has been seen, or withdraw it (Referring or receiving doctor only)
+// @Tags referrals
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Referral ID"
+// @Param request body ReferralResponseRequest false "Optional closing note"
+// @Success 200 {object} models.Referral
+// @Router /api/referrals/{id}/close [post]
+func (h *ReferralHandler) CloseReferral(c *gin.Context) {
+	h.respond(c, h.referrals(c).CloseReferral)
+}
+
+// respond runs a state change that takes the calling doctor and a note.
+func (h *ReferralHandler) respond(c *gin.Context, action func(id, doctorID uint, note string) (*models.Referral, error)) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid referral ID"})
+		return
+	}
+
+	var req ReferralResponseRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	referral, err := action(uint(id), currentUserID(c), req.Note)
+	if err != nil {
+		referralError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, referral)
+}
+
+// @Summary Book Referral Appointment
+// @Description Book the appointment for an accepted referral with the receiving doctor
+// @Tags referrals
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Referral ID"
+// @Param request body BookReferralRequest true "Appointment slot"
+// @Success 201 {object} models.Referral
+// @Router /api/referrals/{id}/book [post]
+func (h *ReferralHandler) BookAppointment(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid referral ID"})
+		return
+	}
+
+	var req BookReferralRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	date, err := time.Parse("2006-01-02", req.Date)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date format. Use YYYY-MM-DD"})
+		return
+	}
+
+	appointment := &models.Appointment{
+		AppointmentTypeID: req.AppointmentTypeID,
+		LocationID:        req.LocationID,
+		Date:              date,
+		Time:              req.Time,
+		Notes:             req.Notes,
+		CreatedBy:         currentUserID(c),
+	}
+	referral, err := h.referrals(c).BookAppointment(uint(id), appointment)
+	if err != nil {
+		referralError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, referral)
+}
@@ -0,0 +1,200 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"healthcare-portal/internal/models"
+	"healthcare-portal/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RelatedPersonHandler struct {
+	relatedPersonService services.RelatedPersonService
+}
+
+func NewRelatedPersonHandler(relatedPersonService services.RelatedPersonService) *RelatedPersonHandler {
+	return &RelatedPersonHandler{relatedPersonService: relatedPersonService}
+}
+
+// relatedPersons returns the service scoped to the caller's tenant.
+func (h *RelatedPersonHandler) relatedPersons(c *gin.Context) services.RelatedPersonService {
+	return h.relatedPersonService.WithTenant(tenantID(c))
+}
+
+type RelatedPersonRequest struct {
+	Name               string `json:"name" binding:"required"`
+	Relationship       string `json:"relationship" binding:"required" example:"parent"`
+	MobilePhone        string `json:"mobile_phone"`
+	HomePhone          string `json:"home_phone"`
+	WorkPhone          string `json:"work_phone"`
+	Email              string `json:"email"`
+	Address            string `json:"address"`
+	IsGuardian         bool   `json:"is_guardian"`
+	IsEmergencyContact bool   `json:"is_emergency_contact"`
+	ConsentToShare     bool   `json:"consent_to_share"`
+	Notes              string `json:"notes"`
+}
+
+func (r RelatedPersonRequest) person() models.RelatedPerson {
+	return models.RelatedPerson{
+		Name:               r.Name,
+		Relationship:       models.Relationship(r.Relationship),
+		MobilePhone:        r.MobilePhone,
+		HomePhone:          r.HomePhone,
+		WorkPhone:          r.WorkPhone,
+		Email:              r.Email,
+		Address:            r.Address,
+		IsGuardian:         r.IsGuardian,
+		IsEmergencyContact: r.IsEmergencyContact,
+		ConsentToShare:     r.ConsentToShare,
+		Notes:              r.Notes,
+	}
+}
+
+// relatedPersonError maps service errors to HTTP status codes.
+func relatedPersonError(c *gin.Context, err error) {
+	status := http.StatusBadRequest
+	switch {
+	case errors.Is(err, services.ErrPatientNotFound), errors.Is(err, services.ErrRelatedPersonNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, services.ErrGuardianRequired):
+		status = http.StatusConflict
+	}
+	c.JSON(status, gin.H{"error": err.Error()})
+}
+
+// @Summary Get Related Persons
+// @Description List a patient's next of kin, guardians and other contacts, guardians first
+// @Tags related-persons
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Patient ID"
+// @Success 200 {array} models.RelatedPerson
+// @Router /api/patients/{id}/related-persons [get]
+func (h *RelatedPersonHandler) GetPatientRelatedPersons(c *gin.Context) {
+	patientID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid patient ID"})
+		return
+	}
+
+	persons, err := h.relatedPersons(c).GetPatientRelatedPersons(uint(patientID))
+	if err != nil {
+		relatedPersonError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, persons)
+}
+
+// @Summary Add Related Person
+// @Description Add a next of kin, guardian or other contact to a patient (Receptionist, Nurse or Doctor)
+// @Tags related-persons
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Patient ID"
+// @Param request body RelatedPersonRequest true "Related person"
+// @Success 201 {object} models.RelatedPerson
+// @Router /api/patients/{id}/related-persons [post]
+func (h *RelatedPersonHandler) CreateRelatedPerson(c *gin.Context) {
+	patientID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid patient ID"})
+		return
+	}
+
+	var req RelatedPersonRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	person := req.person()
+	person.PatientID = uint(patientID)
+	person.CreatedBy = currentUserID(c)
+	person.UpdatedBy = person.CreatedBy
+	if err := h.relatedPersons(c).CreateRelatedPerson(&person); err != nil {
+		relatedPersonError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, person)
+}
+
+// @Summary Update Related Person
+// @Description Update a patient's contact (Receptionist, Nurse or Doctor). A minor's last guardian cannot stop being a guardian.
+// @Tags related-persons
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Patient ID"
+// @Param personId path int true "Related person ID"
+// @Param request body RelatedPersonRequest true "Related person"
+// @Success 200 {object} models.RelatedPerson
+// @Failure 409 {object} map[string]string
+// @Router /api/patients/{id}/related-persons/{personId} [put]
+func (h *RelatedPersonHandler) UpdateRelatedPerson(c *gin.Context) {
+	patientID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid patient ID"})
+		return
+	}
+	personID, err := strconv.ParseUint(c.Param("personId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid related person ID"})
+		return
+	}
+
+	var req RelatedPersonRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	person := req.person()
+	person.ID = uint(personID)
+	person.PatientID = uint(patientID)
+	person.UpdatedBy = currentUserID(c)
+	if err := h.relatedPersons(c).UpdateRelatedPerson(&person); err != nil {
+		relatedPersonError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, person)
+}
+
+// @Summary Delete Related Person
+// @Description Remove a patient's contact (Receptionist, Nurse or Doctor). A minor's last guardian cannot be removed.
+// @Tags related-persons
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Patient ID"
+// @Param personId path int true "Related person ID"
+// @Success 200 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /api/patients/{id}/related-persons/{personId} [delete]
+func (h *RelatedPersonHandler) DeleteRelatedPerson(c *gin.Context) {
+	patientID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid patient ID"})
+		return
+	}
+	personID, err := strconv.ParseUint(c.Param("personId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid related person ID"})
+		return
+	}
+
+	if err := h.relatedPersons(c).DeleteRelatedPerson(uint(patientID), uint(personID)); err != nil {
+		relatedPersonError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Related person deleted successfully"})
+}
@@ -0,0 +1,183 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"healthcare-portal/internal/models"
+	"healthcare-portal/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ResourceHandler struct {
+	resourceService services.ResourceService
+}
+
+func NewResourceHandler(resourceService services.ResourceService) *ResourceHandler {
+	return &ResourceHandler{resourceService: resourceService}
+}
+
+// resources returns the service scoped to the caller's tenant.
+func (h *ResourceHandler) resources(c *gin.Context) services.ResourceService {
+	return h.resourceService.WithTenant(tenantID(c))
+}
+
+type ResourceRequest struct {
+	Name         string   `json:"name" binding:"required"`
+	Kind         string   `json:"kind" binding:"required"`
+	LocationID   *uint    `json:"location_id"`
+	Description  string   `json:"description"`
+	IsActive     *bool    `json:"is_active"`
+	Capabilities []string `json:"capabilities"`
+}
+
+// @Summary Create Resource
+// @Description Create a bookable room or piece of equipment (Receptionist only)
+// @Tags resources
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body ResourceRequest true "Resource details"
+// @Success 201 {object} models.Resource
+// @Router /api/resources [post]
+func (h *ResourceHandler) CreateResource(c *gin.Context) {
+	var req ResourceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resource := &models.Resource{
+		Name:        req.Name,
+		Kind:        models.ResourceKind(req.Kind),
+		LocationID:  req.LocationID,
+		Description: req.Description,
+		IsActive:    true,
+	}
+
+	if err := h.resources(c).CreateResource(resource, req.Capabilities); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, resource)
+}
+
+// @Summary Get All Resources
+// @Description Get all rooms and equipment
+// @Tags resources
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} models.Resource
+// @Router /api/resources [get]
+func (h *ResourceHandler) GetAllResources(c *gin.Context) {
+	resources, err := h.resources(c).GetAllResources()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, resources)
+}
+
+// @Summary Get Resource by ID
+// @Description Get a room or piece of equipment by ID
+// @Tags resources
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Resource ID"
+// @Success 200 {object} models.Resource
+// @Router /api/resources/{id} [get]
+func (h *ResourceHandler) GetResourceByID(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid resource ID"})
+		return
+	}
+
+	resource, err := h.resources(c).GetResourceByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Resource not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, resource)
+}
+
+// @Summary Update Resource
+// @Description Update a resource and replace its capabilities when given (Receptionist only)
+// @Tags resources
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Resource ID"
+// @Param request body ResourceRequest true "Updated resource details"
+// @Success 200 {object} models.Resource
+// @Router /api/resources/{id} [put]
+func (h *ResourceHandler) UpdateResource(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid resource ID"})
+		return
+	}
+
+	var req ResourceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resource, err := h.resources(c).GetResourceByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Resource not found"})
+		return
+	}
+
+	resource.Name = req.Name
+	resource.Kind = models.ResourceKind(req.Kind)
+	resource.LocationID = req.LocationID
+	resource.Description = req.Description
+	if req.IsActive != nil {
+		resource.IsActive = *req.IsActive
+	}
+
+	if err := h.resources(c).UpdateResource(resource, req.Capabilities); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	updated, err := h.resources(c).GetResourceByID(resource.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, updated)
+}
+
+// @Summary Delete Resource
+// @Description Delete a resource (Receptionist only)
+// @Tags resources
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Resource ID"
+// @Success 200 {object} map[string]string
+// @Router /api/resources/{id} [delete]
+func (h *ResourceHandler) DeleteResource(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid resource ID"})
+		return
+	}
+
+	if err := h.resources(c).DeleteResource(uint(id)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Resource deleted successfully"})
+}
@@ -0,0 +1,279 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"healthcare-portal/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type VitalsHandler struct {
+	vitalsService services.VitalsService
+}
+
+func NewVitalsHandler(vitalsService services.VitalsService) *VitalsHandler {
+	return &VitalsHandler{vitalsService: vitalsService}
+}
+
+// vitals returns the service scoped to the caller's tenant.
+func (h *VitalsHandler) vitals(c *gin.Context) services.VitalsService {
+	return h.vitalsService.WithTenant(tenantID(c))
+}
+
+type VitalsRequest struct {
+	RecordedAt       *time.Time `json:"recorded_at"`
+	SystolicBP       *int       `json:"systolic_bp"`
+	DiastolicBP      *int       `json:"diastolic_bp"`
+	HeartRate        *int       `json:"heart_rate"`
+	RespiratoryRate  *int       `json:"respiratory_rate"`
+	Temperature      *float64   `json:"temperature"`
+	TemperatureUnit  string     `json:"temperature_unit" example:"C"`
+	OxygenSaturation *int       `json:"oxygen_saturation"`
+	Weight           *float64   `json:"weight"`
+	WeightUnit       string     `json:"weight_unit" example:"kg"`
+	Height           *float64   `json:"height"`
+	HeightUnit       string     `json:"height_unit" example:"cm"`
+	Notes            string     `json:"notes"`
+}
+
+func (r VitalsRequest) reading() services.VitalsReading {
+	return services.VitalsReading{
+		RecordedAt:       r.RecordedAt,
+		SystolicBP:       r.SystolicBP,
+		DiastolicBP:      r.DiastolicBP,
+		HeartRate:        r.HeartRate,
+		RespiratoryRate:  r.RespiratoryRate,
+		Temperature:      r.Temperature,
+		TemperatureUnit:  r.TemperatureUnit,
+		OxygenSaturation: r.OxygenSaturation,
+		Weight:           r.Weight,
+		WeightUnit:       r.WeightUnit,
+		Height:           r.Height,
+		HeightUnit:       r.HeightUnit,
+		Notes:            r.Notes,
+	}
+}
+
+// dateRange parses the optional from and to query parameters. to covers the
+// whole day.
+func dateRange(c *gin.Context) (from, to *time.Time, err error) {
+	if from, err = parseOptionalDate(c.Query("from")); err != nil {
+		return nil, nil, err
+	}
+	if to, err = parseOptionalDate(c.Query("to")); err != nil {
+		return nil, nil, err
+	}
+	if to != nil {
+		end := to.AddDate(0, 0, 1).Add(-time.Nanosecond)
+		to = &end
+	}
+	return from, to, nil
+}
+
+// @Summary Get Patient Vitals
+// @Description Get a patient's vital signs, oldest first, optionally limited to a date range
+// @Tags vitals
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Patient ID"
+// @Param from query string false "From date (YYYY-MM-DD)"
+// @Param to query string false "To date (YYYY-MM-DD)"
+// @Success 200 {array} models.VitalSigns
+// @Router /api/patients/{id}/vitals [get]
+func (h *VitalsHandler) GetPatientVitals(c *gin.Context) {
+	patientID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid patient ID"})
+		return
+	}
+
+	from, to, err := dateRange(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date format. Use YYYY-MM-DD"})
+		return
+	}
+
+	vitals, err := h.vitals(c).GetPatientVitals(uint(patientID), from, to)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, vitals)
+}
+
+// @Summary Get Vitals Series
+// @Description Get one measurement over time for charting
+// @Tags vitals
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Patient ID"
+// @Param measurement path string true "Measurement (systolic_bp, diastolic_bp, heart_rate, respiratory_rate, temperature, oxygen_saturation, weight, height, bmi)"
+// @Param from query string false "From date (YYYY-MM-DD)"
+// @Param to query string false "To date (YYYY-MM-DD)"
+// @Success 200 {array} models.VitalPoint
+// @Router /api/patients/{id}/vitals/series/{measurement} [get]
+func (h *VitalsHandler) GetVitalsSeries(c *gin.Context) {
+	patientID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid patient ID"})
+		return
+	}
+
+	from, to, err := dateRange(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date format. Use YYYY-MM-DD"})
+		return
+	}
+
+	points, err := h.vitals(c).GetVitalsSeries(uint(patientID), c.Param("measurement"), from, to)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, points)
+}
+
+// @Summary Record Patient Vitals
+// @Description Record vital signs taken outside an appointment
+// @Tags vitals
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Patient ID"
+// @Param request body VitalsRequest true "Measurements"
+// @Success 201 {object} models.VitalSigns
+// @Router /api/patients/{id}/vitals [post]
+func (h *VitalsHandler) RecordVitals(c *gin.Context) {
+	patientID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid patient ID"})
+		return
+	}
+
+	var req VitalsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	vitals, err := h.vitals(c).RecordVitals(uint(patientID), currentUserID(c), req.reading())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, vitals)
+}
+
+// @Summary Get Appointment Vitals
+// @Description Get the vital signs taken for an appointment
+// @Tags vitals
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Appointment ID"
+// @Success 200 {array} models.VitalSigns
+// @Router /api/appointments/{id}/vitals [get]
+func (h *VitalsHandler) GetAppointmentVitals(c *gin.Context) {
+	appointmentID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid appointment ID"})
+		return
+	}
+
+	vitals, err := h.vitals(c).GetAppointmentVitals(uint(appointmentID))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, vitals)
+}
+
+// @Summary Record Appointment Vitals
+// @Description Record vital signs for an appointment, checking the patient in if needed
+// @Tags vitals
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Appointment ID"
+// @Param request body VitalsRequest true "Measurements"
+// @Success 201 {object} models.VitalSigns
+// @Router /api/appointments/{id}/vitals [post]
+func (h *VitalsHandler) RecordVisitVitals(c *gin.Context) {
+	appointmentID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid appointment ID"})
+		return
+	}
+
+	var req VitalsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	vitals, err := h.vitals(c).RecordVisitVitals(uint(appointmentID), currentUserID(c), req.reading())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, vitals)
+}
+
+// @Summary Get Vitals
+// @Description Get a set of vital signs by ID
+// @Tags vitals
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Vitals ID"
+// @Success 200 {object} models.VitalSigns
+// @Router /api/vitals/{id} [get]
+func (h *VitalsHandler) GetVitalsByID(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid vitals ID"})
+		return
+	}
+
+	vitals, err := h.vitals(c).GetVitalsByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Vitals not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, vitals)
+}
+
+// @Summary Delete Vitals
+// @Description Delete a set of vital signs entered in error
+// @Tags vitals
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Vitals ID"
+// @Success 200 {object} map[string]string
+// @Router /api/vitals/{id} [delete]
+func (h *VitalsHandler) DeleteVitals(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid vitals ID"})
+		return
+	}
+
+	if err := h.vitals(c).DeleteVitals(uint(id)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Vitals deleted successfully"})
+}
@@ -5,6 +5,7 @@ import (
     "strings"
 
     "github.com/gin-gonic/gin"
+    "healthcare-portal/internal/models"
     "healthcare-portal/internal/utils"
 )
 
@@ -25,9 +26,17 @@ func AuthMiddleware() gin.HandlerFunc {
             return
         }
 
+        // Tokens issued before multi-tenancy carry no tenant; those users
+        // were all migrated into the default tenant.
+        tenantID := claims.TenantID
+        if tenantID == 0 {
+            tenantID = models.DefaultTenantID
+        }
+
         c.Set("userID", claims.UserID)
         c.Set("email", claims.Email)
         c.Set("role", claims.Role)
+        c.Set("tenantID", tenantID)
         c.Next()
     }
 }
@@ -0,0 +1,76 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type AllergyCategory string
+
+const (
+	AllergyMedication  AllergyCategory = "medication"
+	AllergyFood        AllergyCategory = "food"
+	AllergyEnvironment AllergyCategory = "environment"
+	AllergyBiologic    AllergyCategory = "biologic"
+)
+
+type AllergySeverity string
+
+const (
+	SeverityMild     AllergySeverity = "mild"
+	SeverityModerate AllergySeverity = "moderate"
+	SeveritySevere   AllergySeverity = "severe"
+	SeverityUnknown  AllergySeverity = "unknown"
+)
+
+type AllergyStatus string
+
+const (
+	AllergyActive   AllergyStatus = "active"
+	AllergyInactive AllergyStatus = "inactive"
+	AllergyResolved AllergyStatus = "resolved"
+)
+
+type AllergyVerification string
+
+const (
+	AllergyUnconfirmed AllergyVerification = "unconfirmed"
+	AllergyConfirmed   AllergyVerification = "confirmed"
+)
+
+// AllergySource records where an entry came from. Legacy entries were parsed
+// from Patient.Allergies and stay unconfirmed until staff review them.
+type AllergySource string
+
+const (
+	AllergySourceEntered AllergySource = "entered"
+	AllergySourceLegacy  AllergySource = "legacy"
+)
+
+// Allergy is a structured entry on a patient's allergy list.
+type Allergy struct {
+	ID           uint                `json:"id" gorm:"primaryKey"`
+	TenantID     uint                `json:"tenant_id" gorm:"not null;default:1;index"`
+	PatientID    uint                `json:"patient_id" gorm:"not null;index"`
+	Substance    string              `json:"substance" gorm:"not null"`
+	Category     AllergyCategory     `json:"category" gorm:"type:varchar(20);not null"`
+	Reaction     string              `json:"reaction"`
+	Severity     AllergySeverity     `json:"severity" gorm:"type:varchar(20);not null;default:'unknown'"`
+	Status       AllergyStatus       `json:"status" gorm:"type:varchar(20);not null;default:'active'"`
+	Verification AllergyVerification `json:"verification" gorm:"type:varchar(20);not null;default:'confirmed'"`
+	Source       AllergySource       `json:"source" gorm:"type:varchar(20);not null;default:'entered'"`
+	Notes        string              `json:"notes" gorm:"type:text"`
+	RecordedBy   uint                `json:"recorded_by"`
+	ConfirmedBy  *uint               `json:"confirmed_by"`
+	ConfirmedAt  *time.Time          `json:"confirmed_at"`
+	CreatedAt    time.Time           `json:"created_at"`
+	UpdatedAt    time.Time           `json:"updated_at"`
+	DeletedAt    gorm.DeletedAt      `json:"-" gorm:"index"`
+}
+
+// IsActive reports whether the allergy should be considered clinically.
+// Unconfirmed candidates count: a possible allergy is still a warning.
+func (a *Allergy) IsActive() bool {
+	return a.Status == AllergyActive
+}
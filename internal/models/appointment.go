@@ -9,20 +9,92 @@ type AppointmentStatus string
 
 const (
     StatusScheduled AppointmentStatus = "scheduled"
+    StatusCheckedIn AppointmentStatus = "checked_in"
     StatusCompleted AppointmentStatus = "completed"
     StatusCancelled AppointmentStatus = "cancelled"
 )
 
 type Appointment struct {
-    ID            uint              `json:"id" gorm:"primaryKey"`
-    PatientID     uint              `json:"patient_id" gorm:"not null"`
-    DoctorID      uint              `json:"doctor_id" gorm:"not null"`
-    Date          time.Time         `json:"date"`
-    Time          string            `json:"time" gorm:"type:varchar(10)"`
-    Status        AppointmentStatus `json:"status" gorm:"type:varchar(20);default:'scheduled'"`
-    Notes         string            `json:"notes" gorm:"type:text"`
-    CreatedBy     uint              `json:"created_by"`
-    CreatedAt     time.Time         `json:"created_at"`
-    UpdatedAt     time.Time         `json:"updated_at"`
-    DeletedAt     gorm.DeletedAt    `json:"-" gorm:"index"`
-}
\ No newline at end of file
+    ID                uint                  `json:"id" gorm:"primaryKey"`
+    TenantID          uint                  `json:"tenant_id" gorm:"not null;default:1;index"`
+    PatientID         uint                  `json:"patient_id" gorm:"not null"`
+    DoctorID          uint                  `json:"doctor_id" gorm:"not null"`
+    AppointmentTypeID *uint                 `json:"appointment_type_id"`
+    LocationID        *uint                 `json:"location_id"`
+    Date              time.Time             `json:"date"`
+    Time              string                `json:"time" gorm:"type:varchar(10)"`
+    DurationMinutes   int                   `json:"duration_minutes" gorm:"default:30"`
+    Status            AppointmentStatus     `json:"status" gorm:"type:varchar(20);default:'scheduled'"`
+    Notes             string                `json:"notes" gorm:"type:text"`
+    // ReminderSentAt is when the patient was reminded of the appointment;
+    // see services.AppointmentReminderWorker.
+    ReminderSentAt    *time.Time            `json:"reminder_sent_at,omitempty"`
+    CreatedBy         uint                  `json:"created_by"`
+    CreatedAt         time.Time             `json:"created_at"`
+    UpdatedAt         time.Time             `json:"updated_at"`
+    DeletedAt         gorm.DeletedAt        `json:"-" gorm:"index"`
+
+    // StartsAt is the absolute start time, resolved in the location's time
+    // zone when the location is loaded.
+    StartsAt *time.Time `json:"starts_at,omitempty" gorm:"-"`
+
+    Patient         *Patient              `json:"patient,omitempty" gorm:"foreignKey:PatientID"`
+    Doctor          *User                 `json:"doctor,omitempty" gorm:"foreignKey:DoctorID"`
+    CreatedByUser   *User                 `json:"created_by_user,omitempty" gorm:"foreignKey:CreatedBy"`
+    AppointmentType *AppointmentType      `json:"appointment_type,omitempty" gorm:"foreignKey:AppointmentTypeID"`
+    Location        *Location             `json:"location,omitempty" gorm:"foreignKey:LocationID"`
+    Resources       []AppointmentResource `json:"resources,omitempty" gorm:"foreignKey:AppointmentID"`
+}
+
+// DefaultAppointmentMinutes is the length assumed for appointments booked
+// without an appointment type.
+const DefaultAppointmentMinutes = 30
+
+// Window returns the start and end of the appointment in minutes after
+// midnight. ok is false when Time is not in HH:MM form.
+func (a *Appointment) Window() (start, end int, ok bool) {
+    slot, err := time.Parse("15:04", a.Time)
+    if err != nil {
+        return 0, 0, false
+    }
+
+    duration := a.DurationMinutes
+    if duration <= 0 {
+        duration = DefaultAppointmentMinutes
+    }
+
+    start = slot.Hour()*60 + slot.Minute()
+    return start, start + duration, true
+}
+
+// AfterFind resolves StartsAt once the location has been preloaded.
+func (a *Appointment) AfterFind(tx *gorm.DB) error {
+    if a.Location == nil {
+        return nil
+    }
+    start, _, ok := a.Window()
+    if !ok {
+        return nil
+    }
+    startsAt := time.Date(a.Date.Year(), a.Date.Month(), a.Date.Day(), start/60, start%60, 0, 0, a.Location.Zone())
+    a.StartsAt = &startsAt
+    return nil
+}
+
+// AvailableSlot is a start time at which a doctor can take an appointment.
+type AvailableSlot struct {
+    DoctorID        uint   `json:"doctor_id"`
+    DoctorName      string `json:"doctor_name"`
+    LocationID      *uint  `json:"location_id,omitempty"`
+    Time            string `json:"time"`
+    DurationMinutes int    `json:"duration_minutes"`
+}
+
+// SlotQuery narrows a search for available slots. Only Date is required.
+type SlotQuery struct {
+    Date              time.Time
+    AppointmentTypeID *uint
+    DoctorID          *uint
+    PatientID         *uint
+    LocationID        *uint
+}
@@ -0,0 +1,72 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// AppointmentType is an entry in the admin-managed catalog of visit kinds
+// (new consultation, follow-up, vaccination, procedure). It supplies the
+// default duration and price of an appointment, restricts which doctor
+// specialties may take it and lists the resources it needs. Code is the
+// catalog's own slug; ProcedureCode is the CPT or HCPCS code the visit is
+// billed to insurers under.
+type AppointmentType struct {
+	ID                      uint                         `json:"id" gorm:"primaryKey"`
+	TenantID                uint                         `json:"tenant_id" gorm:"not null;default:1;index;uniqueIndex:idx_appointment_types_tenant_code,priority:1"`
+	Code                    string                       `json:"code" gorm:"type:varchar(50);uniqueIndex:idx_appointment_types_tenant_code,priority:2;not null"`
+	Name                    string                       `json:"name" gorm:"not null"`
+	Description             string                       `json:"description" gorm:"type:text"`
+	DurationMinutes         int                          `json:"duration_minutes" gorm:"default:30"`
+	PriceCents              int64                        `json:"price_cents"`
+	ProcedureCode           string                       `json:"procedure_code,omitempty" gorm:"type:varchar(10)"`
+	PreparationInstructions string                       `json:"preparation_instructions" gorm:"type:text"`
+	IsActive                bool                         `json:"is_active" gorm:"default:true"`
+	Specialties             []AppointmentTypeSpecialty   `json:"specialties" gorm:"foreignKey:AppointmentTypeID"`
+	Requirements            []AppointmentTypeRequirement `json:"requirements" gorm:"foreignKey:AppointmentTypeID"`
+	CreatedAt               time.Time                    `json:"created_at"`
+	UpdatedAt               time.Time                    `json:"updated_at"`
+	DeletedAt               gorm.DeletedAt               `json:"-" gorm:"index"`
+}
+
+// AppointmentTypeRequirement asks for Quantity resources offering Capability.
+type AppointmentTypeRequirement struct {
+	ID                uint   `json:"id" gorm:"primaryKey"`
+	AppointmentTypeID uint   `json:"appointment_type_id" gorm:"not null;index"`
+	Capability        string `json:"capability" gorm:"type:varchar(100);not null"`
+	Quantity          int    `json:"quantity" gorm:"default:1"`
+}
+
+// AppointmentTypeSpecialty allows doctors with Specialty to take the type.
+type AppointmentTypeSpecialty struct {
+	ID                uint   `json:"id" gorm:"primaryKey"`
+	AppointmentTypeID uint   `json:"appointment_type_id" gorm:"not null;index"`
+	Specialty         string `json:"specialty" gorm:"type:varchar(100);not null"`
+}
+
+// AllowsSpecialty reports whether a doctor with the given specialty may take
+// appointments of this type. A type without specialties is open to everyone.
+func (t *AppointmentType) AllowsSpecialty(specialty string) bool {
+	if len(t.Specialties) == 0 {
+		return true
+	}
+	for _, s := range t.Specialties {
+		if s.Specialty == specialty {
+			return true
+		}
+	}
+	return false
+}
+
+// AppointmentTypeReport summarises appointments of one type over a period.
+type AppointmentTypeReport struct {
+	AppointmentTypeID *uint  `json:"appointment_type_id"`
+	Code              string `json:"code"`
+	Name              string `json:"name"`
+	Total             int64  `json:"total"`
+	Scheduled         int64  `json:"scheduled"`
+	Completed         int64  `json:"completed"`
+	Cancelled         int64  `json:"cancelled"`
+	RevenueCents      int64  `json:"revenue_cents"`
+}
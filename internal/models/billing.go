@@ -0,0 +1,130 @@
+package models
+
+import (
+	"time"
+)
+
+type ChargeStatus string
+
+const (
+	ChargePending  ChargeStatus = "pending"
+	ChargeInvoiced ChargeStatus = "invoiced"
+	ChargeVoided   ChargeStatus = "voided"
+)
+
+// Charge is one billable item: a completed appointment, charged at its
+// appointment type's price and procedure code, or an item added by staff. Charges are pending
+// until an invoice picks them up. An appointment has at most one charge
+// that is not voided. Amounts are in cents.
+type Charge struct {
+	ID             uint         `json:"id" gorm:"primaryKey"`
+	TenantID       uint         `json:"tenant_id" gorm:"not null;default:1;index"`
+	PatientID      uint         `json:"patient_id" gorm:"not null;index"`
+	AppointmentID  *uint        `json:"appointment_id,omitempty" gorm:"index;uniqueIndex:idx_charges_one_per_appointment,where:status <> 'voided'"`
+	InvoiceID      *uint        `json:"invoice_id,omitempty" gorm:"index"`
+	Code           string       `json:"code" gorm:"type:varchar(50)"`
+	ProcedureCode  string       `json:"procedure_code,omitempty" gorm:"type:varchar(10)"`
+	Description    string       `json:"description" gorm:"not null"`
+	Quantity       int          `json:"quantity" gorm:"not null;default:1"`
+	UnitPriceCents int64        `json:"unit_price_cents" gorm:"not null"`
+	AmountCents    int64        `json:"amount_cents" gorm:"not null"`
+	ServiceDate    time.Time    `json:"service_date"`
+	Status         ChargeStatus `json:"status" gorm:"type:varchar(20);not null;default:'pending'"`
+	VoidReason     string       `json:"void_reason,omitempty" gorm:"type:text"`
+	CreatedBy      uint         `json:"created_by"`
+	CreatedAt      time.Time    `json:"created_at"`
+	UpdatedAt      time.Time    `json:"updated_at"`
+}
+
+type InvoiceStatus string
+
+const (
+	InvoiceOpen InvoiceStatus = "open"
+	InvoicePaid InvoiceStatus = "paid"
+	InvoiceVoid InvoiceStatus = "void"
+)
+
+// Invoice bills a patient for a group of charges. The discount comes off
+// the subtotal before tax, which is charged at TaxRateBasisPoints (825 is
+// 8.25%). PaidCents is net of refunds, and BalanceCents is what is still
+// owed. Number is issued per tenant, e.g. INV-000042.
+type Invoice struct {
+	ID                 uint          `json:"id" gorm:"primaryKey"`
+	TenantID           uint          `json:"tenant_id" gorm:"not null;default:1;index;uniqueIndex:idx_invoices_tenant_sequence,priority:1"`
+	PatientID          uint          `json:"patient_id" gorm:"not null;index"`
+	Sequence           int           `json:"-" gorm:"not null;uniqueIndex:idx_invoices_tenant_sequence,priority:2"`
+	Number             string        `json:"number" gorm:"type:varchar(20);not null"`
+	Status             InvoiceStatus `json:"status" gorm:"type:varchar(20);not null;default:'open'"`
+	IssuedAt           time.Time     `json:"issued_at"`
+	DueDate            time.Time     `json:"due_date"`
+	SubtotalCents      int64         `json:"subtotal_cents" gorm:"not null"`
+	DiscountCents      int64         `json:"discount_cents" gorm:"not null;default:0"`
+	DiscountReason     string        `json:"discount_reason,omitempty"`
+	TaxRateBasisPoints int           `json:"tax_rate_basis_points" gorm:"not null;default:0"`
+	TaxCents           int64         `json:"tax_cents" gorm:"not null;default:0"`
+	TotalCents         int64         `json:"total_cents" gorm:"not null"`
+	PaidCents          int64         `json:"paid_cents" gorm:"not null;default:0"`
+	BalanceCents       int64         `json:"balance_cents" gorm:"not null"`
+	Notes              string        `json:"notes,omitempty" gorm:"type:text"`
+	VoidReason         string        `json:"void_reason,omitempty" gorm:"type:text"`
+	CreatedBy          uint          `json:"created_by"`
+	CreatedAt          time.Time     `json:"created_at"`
+	UpdatedAt          time.Time     `json:"updated_at"`
+	Charges            []Charge      `json:"charges,omitempty" gorm:"foreignKey:InvoiceID"`
+	Payments           []Payment     `json:"payments,omitempty" gorm:"foreignKey:InvoiceID"`
+}
+
+type PaymentKind string
+
+const (
+	PaymentReceived PaymentKind = "payment"
+	PaymentRefund   PaymentKind = "refund"
+)
+
+type PaymentMethod string
+
+const (
+	PaymentCash      PaymentMethod = "cash"
+	PaymentCard      PaymentMethod = "card"
+	PaymentCheck     PaymentMethod = "check"
+	PaymentTransfer  PaymentMethod = "transfer"
+	PaymentInsurance PaymentMethod = "insurance"
+	PaymentOther     PaymentMethod = "other"
+)
+
+// IsValid reports whether m is one of the known payment methods.
+func (m PaymentMethod) IsValid() bool {
+	switch m {
+	case PaymentCash, PaymentCard, PaymentCheck, PaymentTransfer, PaymentInsurance, PaymentOther:
+		return true
+	}
+	return false
+}
+
+// Payment is money received against an invoice, or a refund of it. Amount
+// is always positive; Kind says which way it went.
+type Payment struct {
+	ID          uint          `json:"id" gorm:"primaryKey"`
+	TenantID    uint          `json:"tenant_id" gorm:"not null;default:1;index"`
+	InvoiceID   uint          `json:"invoice_id" gorm:"not null;index"`
+	PatientID   uint          `json:"patient_id" gorm:"not null;index"`
+	Kind        PaymentKind   `json:"kind" gorm:"type:varchar(20);not null"`
+	Method      PaymentMethod `json:"method" gorm:"type:varchar(20);not null"`
+	AmountCents int64         `json:"amount_cents" gorm:"not null"`
+	Reference   string        `json:"reference,omitempty"`
+	Notes       string        `json:"notes,omitempty" gorm:"type:text"`
+	ReceivedAt  time.Time     `json:"received_at"`
+	RecordedBy  uint          `json:"recorded_by"`
+	CreatedAt   time.Time     `json:"created_at"`
+}
+
+// PatientBalance sums a patient's account. Void invoices and charges are
+// left out. UnbilledCents is pending charges not yet on an invoice.
+type PatientBalance struct {
+	PatientID     uint  `json:"patient_id"`
+	InvoicedCents int64 `json:"invoiced_cents"`
+	PaidCents     int64 `json:"paid_cents"`
+	BalanceCents  int64 `json:"balance_cents"`
+	UnbilledCents int64 `json:"unbilled_cents"`
+	OpenInvoices  int64 `json:"open_invoices"`
+}
@@ -0,0 +1,113 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type CarePlanStatus string
+
+const (
+	CarePlanActive    CarePlanStatus = "active"
+	CarePlanCompleted CarePlanStatus = "completed"
+	CarePlanCancelled CarePlanStatus = "cancelled"
+)
+
+// CarePlan groups the goals and follow-up tasks agreed for a patient, such
+// as a diabetes plan linked to the problem list entry it addresses.
+type CarePlan struct {
+	ID          uint           `json:"id" gorm:"primaryKey"`
+	TenantID    uint           `json:"tenant_id" gorm:"not null;default:1;index"`
+	PatientID   uint           `json:"patient_id" gorm:"not null;index"`
+	ProblemID   *uint          `json:"problem_id"`
+	Title       string         `json:"title" gorm:"not null"`
+	Description string         `json:"description" gorm:"type:text"`
+	Status      CarePlanStatus `json:"status" gorm:"type:varchar(20);not null;default:'active'"`
+	CreatedBy   uint           `json:"created_by"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `json:"-" gorm:"index"`
+
+	Goals []CarePlanGoal `json:"goals,omitempty" gorm:"foreignKey:CarePlanID"`
+	Tasks []CareTask     `json:"tasks,omitempty" gorm:"foreignKey:CarePlanID"`
+}
+
+type CareGoalStatus string
+
+const (
+	CareGoalInProgress  CareGoalStatus = "in_progress"
+	CareGoalAchieved    CareGoalStatus = "achieved"
+	CareGoalNotAchieved CareGoalStatus = "not_achieved"
+)
+
+// CarePlanGoal is a measurable target such as "HbA1c below 7%".
+type CarePlanGoal struct {
+	ID          uint           `json:"id" gorm:"primaryKey"`
+	CarePlanID  uint           `json:"care_plan_id" gorm:"not null;index"`
+	Description string         `json:"description" gorm:"not null"`
+	Target      string         `json:"target" gorm:"type:varchar(100)"`
+	TargetDate  *time.Time     `json:"target_date"`
+	Status      CareGoalStatus `json:"status" gorm:"type:varchar(20);not null;default:'in_progress'"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+}
+
+type CareTaskStatus string
+
+const (
+	CareTaskPending   CareTaskStatus = "pending"
+	CareTaskScheduled CareTaskStatus = "scheduled"
+	CareTaskOverdue   CareTaskStatus = "overdue"
+	CareTaskCompleted CareTaskStatus = "completed"
+	CareTaskCancelled CareTaskStatus = "cancelled"
+)
+
+// IsOpen reports whether the task still needs doing.
+func (s CareTaskStatus) IsOpen() bool {
+	return s == CareTaskPending || s == CareTaskScheduled || s == CareTaskOverdue
+}
+
+// CareTask is a follow-up that falls due on DueDate, such as "repeat HbA1c
+// in 3 months". EarliestDate opens the window in which it is worth doing;
+// pending tasks past DueDate are marked overdue by the care task worker.
+// DoctorID and AppointmentTypeID describe the visit to suggest for it.
+type CareTask struct {
+	ID                uint           `json:"id" gorm:"primaryKey"`
+	TenantID          uint           `json:"tenant_id" gorm:"not null;default:1;index"`
+	CarePlanID        uint           `json:"care_plan_id" gorm:"not null;index"`
+	PatientID         uint           `json:"patient_id" gorm:"not null;index"`
+	Title             string         `json:"title" gorm:"not null"`
+	Description       string         `json:"description" gorm:"type:text"`
+	AssignedTo        *uint          `json:"assigned_to" gorm:"index"`
+	EarliestDate      time.Time      `json:"earliest_date" gorm:"not null"`
+	DueDate           time.Time      `json:"due_date" gorm:"not null;index"`
+	Status            CareTaskStatus `json:"status" gorm:"type:varchar(20);not null;default:'pending'"`
+	DoctorID          *uint          `json:"doctor_id"`
+	AppointmentTypeID *uint          `json:"appointment_type_id"`
+	AppointmentID     *uint          `json:"appointment_id"`
+	CompletedAt       *time.Time     `json:"completed_at"`
+	CompletedBy       *uint          `json:"completed_by"`
+	CreatedBy         uint           `json:"created_by"`
+	CreatedAt         time.Time      `json:"created_at"`
+	UpdatedAt         time.Time      `json:"updated_at"`
+	DeletedAt         gorm.DeletedAt `json:"-" gorm:"index"`
+
+	Patient        *Patient     `json:"patient,omitempty" gorm:"foreignKey:PatientID"`
+	AssignedToUser *User        `json:"assigned_to_user,omitempty" gorm:"foreignKey:AssignedTo"`
+	Appointment    *Appointment `json:"appointment,omitempty" gorm:"foreignKey:AppointmentID"`
+}
+
+// CareTaskFilter narrows a task list. Zero values match everything.
+type CareTaskFilter struct {
+	AssignedTo *uint
+	PatientID  *uint
+	Statuses   []CareTaskStatus
+	DueBefore  *time.Time
+}
+
+// SuggestedAppointment is a free slot inside a care task's window.
+type SuggestedAppointment struct {
+	Date string `json:"date"`
+	AvailableSlot
+}
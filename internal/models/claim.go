@@ -0,0 +1,133 @@
+package models
+
+import (
+	"time"
+)
+
+type ClaimStatus string
+
+const (
+	ClaimInvalid   ClaimStatus = "invalid"
+	ClaimReady     ClaimStatus = "ready"
+	ClaimSubmitted ClaimStatus = "submitted"
+	ClaimPaid      ClaimStatus = "paid"
+	ClaimDenied    ClaimStatus = "denied"
+)
+
+// Claim is a professional (837P) claim for one completed appointment, billed
+// to the patient's primary coverage. Lines and diagnoses are copied from the
+// appointment's charges and diagnoses when the claim is generated. A claim
+// that fails validation is kept as invalid with the reasons, and can be
+// regenerated once the data is fixed. ControlNumber is the patient control
+// number (CLM01) payers echo back on remittances, e.g. CLM-000042.
+type Claim struct {
+	ID                         uint             `json:"id" gorm:"primaryKey"`
+	TenantID                   uint             `json:"tenant_id" gorm:"not null;default:1;index;uniqueIndex:idx_claims_tenant_sequence,priority:1"`
+	PatientID                  uint             `json:"patient_id" gorm:"not null;index"`
+	AppointmentID              uint             `json:"appointment_id" gorm:"not null;index"`
+	CoverageID                 *uint            `json:"coverage_id,omitempty"`
+	Sequence                   int              `json:"-" gorm:"not null;uniqueIndex:idx_claims_tenant_sequence,priority:2"`
+	ControlNumber              string           `json:"control_number" gorm:"type:varchar(20);not null;index"`
+	Status                     ClaimStatus      `json:"status" gorm:"type:varchar(20);not null"`
+	ValidationErrors           []string         `json:"validation_errors,omitempty" gorm:"type:text;serializer:json"`
+	ServiceDate                time.Time        `json:"service_date"`
+	PlaceOfService             string           `json:"place_of_service" gorm:"type:varchar(2);not null"`
+	TotalChargeCents           int64            `json:"total_charge_cents" gorm:"not null"`
+	PaidCents                  int64            `json:"paid_cents" gorm:"not null;default:0"`
+	AdjustmentCents            int64            `json:"adjustment_cents" gorm:"not null;default:0"`
+	PatientResponsibilityCents int64            `json:"patient_responsibility_cents" gorm:"not null;default:0"`
+	PayerClaimNumber           string           `json:"payer_claim_number,omitempty" gorm:"type:varchar(50)"`
+	BatchID                    *uint            `json:"batch_id,omitempty" gorm:"index"`
+	SubmittedAt                *time.Time       `json:"submitted_at,omitempty"`
+	CreatedBy                  uint             `json:"created_by"`
+	CreatedAt                  time.Time        `json:"created_at"`
+	UpdatedAt                  time.Time        `json:"updated_at"`
+	Lines                      []ClaimLine      `json:"lines,omitempty" gorm:"foreignKey:ClaimID"`
+	Diagnoses                  []ClaimDiagnosis `json:"diagnoses,omitempty" gorm:"foreignKey:ClaimID"`
+	Coverage                   *Coverage        `json:"coverage,omitempty" gorm:"foreignKey:CoverageID"`
+	Patient                    *Patient         `json:"patient,omitempty" gorm:"foreignKey:PatientID"`
+}
+
+// ClaimDiagnosis is an ICD-10 code on a claim. Position 1 is the principal
+// diagnosis; lines point at diagnoses by position.
+type ClaimDiagnosis struct {
+	ID       uint   `json:"id" gorm:"primaryKey"`
+	ClaimID  uint   `json:"claim_id" gorm:"not null;index"`
+	Position int    `json:"position" gorm:"not null"`
+	Code     string `json:"code" gorm:"type:varchar(10);not null"`
+}
+
+// ClaimLine is one billed service, copied from a charge. DiagnosisPointers
+// lists the diagnosis positions it treats, e.g. "1,2".
+type ClaimLine struct {
+	ID                uint      `json:"id" gorm:"primaryKey"`
+	ClaimID           uint      `json:"claim_id" gorm:"not null;index"`
+	ChargeID          uint      `json:"charge_id" gorm:"not null;index"`
+	LineNumber        int       `json:"line_number" gorm:"not null"`
+	ProcedureCode     string    `json:"procedure_code" gorm:"type:varchar(50)"`
+	Description       string    `json:"description"`
+	Units             int       `json:"units" gorm:"not null"`
+	ChargeCents       int64     `json:"charge_cents" gorm:"not null"`
+	DiagnosisPointers string    `json:"diagnosis_pointers" gorm:"type:varchar(20)"`
+	ServiceDate       time.Time `json:"service_date"`
+	PaidCents         int64     `json:"paid_cents" gorm:"not null;default:0"`
+	AdjustmentCents   int64     `json:"adjustment_cents" gorm:"not null;default:0"`
+}
+
+// ClaimBatch is an 837P file of claims for the clearinghouse. Its ID is the
+// interchange control number, so it is unique across tenants.
+type ClaimBatch struct {
+	ID               uint      `json:"id" gorm:"primaryKey"`
+	TenantID         uint      `json:"tenant_id" gorm:"not null;default:1;index"`
+	FileName         string    `json:"file_name" gorm:"not null"`
+	ClaimCount       int       `json:"claim_count" gorm:"not null"`
+	TotalChargeCents int64     `json:"total_charge_cents" gorm:"not null"`
+	Content          string    `json:"-" gorm:"type:text"`
+	CreatedBy        uint      `json:"created_by"`
+	CreatedAt        time.Time `json:"created_at"`
+	Claims           []Claim   `json:"claims,omitempty" gorm:"foreignKey:BatchID"`
+}
+
+type RemittanceOutcome string
+
+const (
+	RemittancePosted    RemittanceOutcome = "posted"
+	RemittanceDenied    RemittanceOutcome = "denied"
+	RemittanceNotPosted RemittanceOutcome = "not_posted"
+	RemittanceUnmatched RemittanceOutcome = "unmatched"
+)
+
+// Remittance is one payment advice (an 835 transaction) from a payer: a
+// check or EFT identified by TraceNumber, covering one or more claims.
+type Remittance struct {
+	ID             uint              `json:"id" gorm:"primaryKey"`
+	TenantID       uint              `json:"tenant_id" gorm:"not null;default:1;index;uniqueIndex:idx_remittances_tenant_trace,priority:1"`
+	TraceNumber    string            `json:"trace_number" gorm:"type:varchar(50);not null;uniqueIndex:idx_remittances_tenant_trace,priority:2"`
+	PayerName      string            `json:"payer_name"`
+	PaymentDate    *time.Time        `json:"payment_date,omitempty"`
+	TotalPaidCents int64             `json:"total_paid_cents" gorm:"not null"`
+	FileName       string            `json:"file_name"`
+	ImportedBy     uint              `json:"imported_by"`
+	CreatedAt      time.Time         `json:"created_at"`
+	Claims         []RemittanceClaim `json:"claims,omitempty" gorm:"foreignKey:RemittanceID"`
+}
+
+// RemittanceClaim is the payer's decision on one claim (a CLP loop) and what
+// was done with it. Outcome says whether the payment was posted to the
+// claim's invoice; Note explains anything left for staff to follow up.
+type RemittanceClaim struct {
+	ID                         uint              `json:"id" gorm:"primaryKey"`
+	RemittanceID               uint              `json:"remittance_id" gorm:"not null;index"`
+	ControlNumber              string            `json:"control_number" gorm:"type:varchar(20);not null"`
+	ClaimID                    *uint             `json:"claim_id,omitempty" gorm:"index"`
+	InvoiceID                  *uint             `json:"invoice_id,omitempty"`
+	PaymentID                  *uint             `json:"payment_id,omitempty"`
+	StatusCode                 string            `json:"status_code" gorm:"type:varchar(2)"`
+	ChargeCents                int64             `json:"charge_cents"`
+	PaidCents                  int64             `json:"paid_cents"`
+	AdjustmentCents            int64             `json:"adjustment_cents"`
+	PatientResponsibilityCents int64             `json:"patient_responsibility_cents"`
+	PayerClaimNumber           string            `json:"payer_claim_number,omitempty" gorm:"type:varchar(50)"`
+	Outcome                    RemittanceOutcome `json:"outcome" gorm:"type:varchar(20);not null"`
+	Note                       string            `json:"note,omitempty" gorm:"type:text"`
+}
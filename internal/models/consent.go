@@ -0,0 +1,77 @@
+package models
+
+import (
+	"time"
+)
+
+type ConsentType string
+
+const (
+	ConsentTreatment      ConsentType = "treatment"
+	ConsentDataSharing    ConsentType = "data_sharing"
+	ConsentSMSReminders   ConsentType = "sms_reminders"
+	ConsentEmailReminders ConsentType = "email_reminders"
+	ConsentResearch       ConsentType = "research"
+)
+
+// IsValid reports whether t is one of the known consent types.
+func (t ConsentType) IsValid() bool {
+	switch t {
+	case ConsentTreatment, ConsentDataSharing, ConsentSMSReminders, ConsentEmailReminders, ConsentResearch:
+		return true
+	}
+	return false
+}
+
+// ConsentText is one version of the wording a patient agrees to for a
+// consent type. Versions are numbered per tenant and type. When
+// RequiresReconsent is set, consents given to earlier versions stop
+// counting once this version takes effect.
+type ConsentText struct {
+	ID                uint        `json:"id" gorm:"primaryKey"`
+	TenantID          uint        `json:"tenant_id" gorm:"not null;default:1;index;uniqueIndex:idx_consent_texts_version,priority:1"`
+	Type              ConsentType `json:"type" gorm:"type:varchar(30);not null;uniqueIndex:idx_consent_texts_version,priority:2"`
+	Version           int         `json:"version" gorm:"not null;uniqueIndex:idx_consent_texts_version,priority:3"`
+	Title             string      `json:"title" gorm:"not null"`
+	Body              string      `json:"body" gorm:"type:text;not null"`
+	EffectiveFrom     time.Time   `json:"effective_from"`
+	RequiresReconsent bool        `json:"requires_reconsent" gorm:"not null;default:false"`
+	CreatedBy         uint        `json:"created_by"`
+	CreatedAt         time.Time   `json:"created_at"`
+}
+
+type ConsentStatus string
+
+const (
+	ConsentGranted ConsentStatus = "granted"
+	ConsentRevoked ConsentStatus = "revoked"
+)
+
+// Consent records a patient agreeing to one version of a consent text.
+// Rows are never deleted: revoking, or granting again, closes the active row
+// so the history shows what applied at any point in time. RelatedPersonID
+// is the guardian who consented on behalf of a minor.
+type Consent struct {
+	ID               uint          `json:"id" gorm:"primaryKey"`
+	TenantID         uint          `json:"tenant_id" gorm:"not null;default:1;index"`
+	PatientID        uint          `json:"patient_id" gorm:"not null;index;uniqueIndex:idx_consents_one_granted,priority:1,where:status = 'granted'"`
+	Type             ConsentType   `json:"type" gorm:"type:varchar(30);not null;uniqueIndex:idx_consents_one_granted,priority:2"`
+	ConsentTextID    uint          `json:"consent_text_id" gorm:"not null"`
+	TextVersion      int           `json:"text_version" gorm:"not null"`
+	Status           ConsentStatus `json:"status" gorm:"type:varchar(20);not null;default:'granted'"`
+	GrantedAt        time.Time     `json:"granted_at"`
+	RevokedAt        *time.Time    `json:"revoked_at"`
+	RevocationReason string        `json:"revocation_reason,omitempty" gorm:"type:text"`
+	RelatedPersonID  *uint         `json:"related_person_id,omitempty"`
+	Notes            string        `json:"notes,omitempty" gorm:"type:text"`
+	RecordedBy       uint          `json:"recorded_by"`
+	RevokedBy        *uint         `json:"revoked_by,omitempty"`
+	CreatedAt        time.Time     `json:"created_at"`
+	UpdatedAt        time.Time     `json:"updated_at"`
+
+	// NeedsReconsent is set on granted consents whose text version has been
+	// superseded by one that requires patients to agree again.
+	NeedsReconsent bool `json:"needs_reconsent" gorm:"-"`
+
+	ConsentText *ConsentText `json:"consent_text,omitempty" gorm:"foreignKey:ConsentTextID"`
+}
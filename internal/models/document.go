@@ -0,0 +1,39 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type DocumentCategory string
+
+const (
+	DocumentReferralLetter DocumentCategory = "referral_letter"
+	DocumentIdentification DocumentCategory = "identification"
+	DocumentImagingReport  DocumentCategory = "imaging_report"
+	DocumentLabReport      DocumentCategory = "lab_report"
+	DocumentInsuranceCard  DocumentCategory = "insurance_card"
+	DocumentOther          DocumentCategory = "other"
+)
+
+// Document is a file attached to a patient. The content lives in the blob
+// store under StorageKey; ContentType is sniffed from the upload rather than
+// trusted from the client, and SHA256 is checked again on download.
+type Document struct {
+	ID          uint             `json:"id" gorm:"primaryKey"`
+	TenantID    uint             `json:"tenant_id" gorm:"not null;default:1;index"`
+	PatientID   uint             `json:"patient_id" gorm:"not null;index"`
+	Category    DocumentCategory `json:"category" gorm:"type:varchar(30);not null;default:'other'"`
+	Title       string           `json:"title" gorm:"not null"`
+	FileName    string           `json:"file_name" gorm:"not null"`
+	ContentType string           `json:"content_type" gorm:"type:varchar(100);not null"`
+	SizeBytes   int64            `json:"size_bytes" gorm:"not null"`
+	SHA256      string           `json:"sha256" gorm:"column:sha256;type:varchar(64);not null"`
+	StorageKey  string           `json:"-" gorm:"not null;uniqueIndex"`
+	Description string           `json:"description" gorm:"type:text"`
+	UploadedBy  uint             `json:"uploaded_by"`
+	CreatedAt   time.Time        `json:"created_at"`
+	UpdatedAt   time.Time        `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt   `json:"-" gorm:"index"`
+}
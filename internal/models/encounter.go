@@ -0,0 +1,58 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type EncounterStatus string
+
+const (
+	EncounterDraft  EncounterStatus = "draft"
+	EncounterSigned EncounterStatus = "signed"
+)
+
+// Encounter documents a visit in SOAP form. There is at most one encounter
+// per appointment. Once the treating doctor signs it the SOAP sections are
+// locked and corrections are made through addenda.
+type Encounter struct {
+	ID            uint                `json:"id" gorm:"primaryKey"`
+	TenantID      uint                `json:"tenant_id" gorm:"not null;default:1;index"`
+	AppointmentID uint                `json:"appointment_id" gorm:"not null;uniqueIndex"`
+	PatientID     uint                `json:"patient_id" gorm:"not null;index"`
+	DoctorID      uint                `json:"doctor_id" gorm:"not null;index"`
+	VisitDate     time.Time           `json:"visit_date" gorm:"not null"`
+	Subjective    string              `json:"subjective" gorm:"type:text"`
+	Objective     string              `json:"objective" gorm:"type:text"`
+	Assessment    string              `json:"assessment" gorm:"type:text"`
+	Plan          string              `json:"plan" gorm:"type:text"`
+	Status        EncounterStatus     `json:"status" gorm:"type:varchar(20);not null;default:'draft'"`
+	SignedAt      *time.Time          `json:"signed_at"`
+	Doctor        *User               `json:"doctor,omitempty" gorm:"foreignKey:DoctorID"`
+	Appointment   *Appointment        `json:"appointment,omitempty" gorm:"foreignKey:AppointmentID"`
+	Addenda       []EncounterAddendum `json:"addenda,omitempty" gorm:"foreignKey:EncounterID"`
+	CreatedAt     time.Time           `json:"created_at"`
+	UpdatedAt     time.Time           `json:"updated_at"`
+	DeletedAt     gorm.DeletedAt      `json:"-" gorm:"index"`
+}
+
+// IsSigned reports whether the SOAP sections are locked.
+func (e *Encounter) IsSigned() bool {
+	return e.Status == EncounterSigned
+}
+
+// EncounterAddendum is an append-only note added to a signed encounter.
+type EncounterAddendum struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	EncounterID uint      `json:"encounter_id" gorm:"not null;index"`
+	AuthorID    uint      `json:"author_id" gorm:"not null"`
+	Content     string    `json:"content" gorm:"type:text;not null"`
+	Author      *User     `json:"author,omitempty" gorm:"foreignKey:AuthorID"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// TableName keeps the plural of addendum.
+func (EncounterAddendum) TableName() string {
+	return "encounter_addenda"
+}
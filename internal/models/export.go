@@ -0,0 +1,28 @@
+package models
+
+// DeidentifiedPatient is a patient's record stripped of direct identifiers
+// for research export. Names, contact details, identifiers and exact dates
+// are dropped; dates are reduced to years and birth years of patients aged
+// 90 or over are withheld, following the HIPAA safe harbor method. The
+// pseudonym is stable across exports so records can be linked over time.
+type DeidentifiedPatient struct {
+	Pseudonym     string                     `json:"pseudonym"`
+	BirthYear     *int                       `json:"birth_year"`
+	AgeOver89     bool                       `json:"age_over_89"`
+	Gender        string                     `json:"gender"`
+	Problems      []DeidentifiedProblem      `json:"problems"`
+	Immunizations []DeidentifiedImmunization `json:"immunizations"`
+}
+
+type DeidentifiedProblem struct {
+	Code        string        `json:"code"`
+	Description string        `json:"description"`
+	Status      ProblemStatus `json:"status"`
+	OnsetYear   *int          `json:"onset_year"`
+}
+
+type DeidentifiedImmunization struct {
+	VaccineCode      string `json:"vaccine_code"`
+	DoseNumber       int    `json:"dose_number"`
+	AdministeredYear int    `json:"administered_year"`
+}
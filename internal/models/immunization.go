@@ -0,0 +1,74 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type InjectionSite string
+
+const (
+	SiteLeftDeltoid  InjectionSite = "left_deltoid"
+	SiteRightDeltoid InjectionSite = "right_deltoid"
+	SiteLeftThigh    InjectionSite = "left_thigh"
+	SiteRightThigh   InjectionSite = "right_thigh"
+	SiteOral         InjectionSite = "oral"
+	SiteIntranasal   InjectionSite = "intranasal"
+)
+
+// ImmunizationSource separates doses given here from doses transcribed from
+// paper cards or other providers, which have no lot or administering user.
+type ImmunizationSource string
+
+const (
+	ImmunizationAdministered ImmunizationSource = "administered"
+	ImmunizationHistorical   ImmunizationSource = "historical"
+)
+
+// Immunization is one vaccine dose given to a patient. VaccineCode matches a
+// vaccine in the schedule file so the dose counts towards the forecast.
+type Immunization struct {
+	ID             uint               `json:"id" gorm:"primaryKey"`
+	TenantID       uint               `json:"tenant_id" gorm:"not null;default:1;index"`
+	PatientID      uint               `json:"patient_id" gorm:"not null;index"`
+	VaccineCode    string             `json:"vaccine_code" gorm:"type:varchar(20);not null"`
+	VaccineName    string             `json:"vaccine_name" gorm:"not null"`
+	DoseNumber     int                `json:"dose_number" gorm:"not null"`
+	AdministeredAt time.Time          `json:"administered_at" gorm:"not null"`
+	LotNumber      string             `json:"lot_number" gorm:"type:varchar(50)"`
+	Manufacturer   string             `json:"manufacturer"`
+	ExpirationDate *time.Time         `json:"expiration_date"`
+	Site           InjectionSite      `json:"site" gorm:"type:varchar(20)"`
+	Source         ImmunizationSource `json:"source" gorm:"type:varchar(20);not null;default:'administered'"`
+	AdministeredBy *uint              `json:"administered_by"`
+	Notes          string             `json:"notes" gorm:"type:text"`
+	RecordedBy     uint               `json:"recorded_by"`
+	CreatedAt      time.Time          `json:"created_at"`
+	UpdatedAt      time.Time          `json:"updated_at"`
+	DeletedAt      gorm.DeletedAt     `json:"-" gorm:"index"`
+
+	AdministeredByUser *User `json:"administered_by_user,omitempty" gorm:"foreignKey:AdministeredBy"`
+}
+
+type VaccineDueStatus string
+
+const (
+	VaccineUpcoming VaccineDueStatus = "upcoming"
+	VaccineDue      VaccineDueStatus = "due"
+	VaccineOverdue  VaccineDueStatus = "overdue"
+	VaccineComplete VaccineDueStatus = "complete"
+)
+
+// VaccineForecast is the next dose of a vaccine for a patient. Dates are
+// nil once the series is complete.
+type VaccineForecast struct {
+	VaccineCode  string           `json:"vaccine_code"`
+	VaccineName  string           `json:"vaccine_name"`
+	DoseNumber   int              `json:"dose_number,omitempty"`
+	DosesGiven   int              `json:"doses_given"`
+	Status       VaccineDueStatus `json:"status"`
+	EarliestDate *time.Time       `json:"earliest_date,omitempty"`
+	DueDate      *time.Time       `json:"due_date,omitempty"`
+	OverdueDate  *time.Time       `json:"overdue_date,omitempty"`
+}
@@ -0,0 +1,146 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// InsurancePayer is an insurance company or government program that pays
+// claims. PayerCode is the payer's electronic (EDI) payer ID.
+type InsurancePayer struct {
+	ID        uint            `json:"id" gorm:"primaryKey"`
+	TenantID  uint            `json:"tenant_id" gorm:"not null;default:1;index"`
+	Name      string          `json:"name" gorm:"not null"`
+	PayerCode string          `json:"payer_code" gorm:"type:varchar(20)"`
+	Phone     string          `json:"phone" gorm:"type:varchar(50)"`
+	Address   string          `json:"address" gorm:"type:text"`
+	IsActive  bool            `json:"is_active" gorm:"not null;default:true"`
+	CreatedAt time.Time       `json:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at"`
+	Plans     []InsurancePlan `json:"plans,omitempty" gorm:"foreignKey:PayerID"`
+}
+
+type PlanType string
+
+const (
+	PlanHMO      PlanType = "hmo"
+	PlanPPO      PlanType = "ppo"
+	PlanEPO      PlanType = "epo"
+	PlanPOS      PlanType = "pos"
+	PlanMedicare PlanType = "medicare"
+	PlanMedicaid PlanType = "medicaid"
+	PlanOther    PlanType = "other"
+)
+
+// IsValid reports whether t is one of the known plan types.
+func (t PlanType) IsValid() bool {
+	switch t {
+	case PlanHMO, PlanPPO, PlanEPO, PlanPOS, PlanMedicare, PlanMedicaid, PlanOther:
+		return true
+	}
+	return false
+}
+
+// InsurancePlan is a product a payer sells. CopayCents is the plan's usual
+// copay for an office visit, reported by eligibility checks that cannot ask
+// the payer.
+type InsurancePlan struct {
+	ID         uint            `json:"id" gorm:"primaryKey"`
+	TenantID   uint            `json:"tenant_id" gorm:"not null;default:1;index"`
+	PayerID    uint            `json:"payer_id" gorm:"not null;index"`
+	Name       string          `json:"name" gorm:"not null"`
+	PlanType   PlanType        `json:"plan_type" gorm:"type:varchar(20);not null"`
+	CopayCents int64           `json:"copay_cents" gorm:"not null;default:0"`
+	IsActive   bool            `json:"is_active" gorm:"not null;default:true"`
+	CreatedAt  time.Time       `json:"created_at"`
+	UpdatedAt  time.Time       `json:"updated_at"`
+	Payer      *InsurancePayer `json:"payer,omitempty" gorm:"foreignKey:PayerID"`
+}
+
+type SubscriberRelationship string
+
+const (
+	SubscriberSelf   SubscriberRelationship = "self"
+	SubscriberSpouse SubscriberRelationship = "spouse"
+	SubscriberChild  SubscriberRelationship = "child"
+	SubscriberOther  SubscriberRelationship = "other"
+)
+
+// IsValid reports whether r is one of the known subscriber relationships.
+func (r SubscriberRelationship) IsValid() bool {
+	switch r {
+	case SubscriberSelf, SubscriberSpouse, SubscriberChild, SubscriberOther:
+		return true
+	}
+	return false
+}
+
+// Coverage is a patient's membership of an insurance plan. The subscriber
+// is the policy holder; when that is not the patient, their name and date
+// of birth are recorded too. Priority orders the coverages in force at the
+// same time: 1 is primary, 2 secondary and 3 tertiary. EffectiveTo is the
+// last day covered, or nil while the coverage is open-ended.
+type Coverage struct {
+	ID                     uint                   `json:"id" gorm:"primaryKey"`
+	TenantID               uint                   `json:"tenant_id" gorm:"not null;default:1;index"`
+	PatientID              uint                   `json:"patient_id" gorm:"not null;index"`
+	PlanID                 uint                   `json:"plan_id" gorm:"not null"`
+	MemberID               string                 `json:"member_id" gorm:"type:varchar(50);not null"`
+	GroupNumber            string                 `json:"group_number" gorm:"type:varchar(50)"`
+	SubscriberRelationship SubscriberRelationship `json:"subscriber_relationship" gorm:"type:varchar(20);not null"`
+	SubscriberName         string                 `json:"subscriber_name"`
+	SubscriberDateOfBirth  *time.Time             `json:"subscriber_date_of_birth,omitempty"`
+	Priority               int                    `json:"priority" gorm:"not null;default:1"`
+	EffectiveFrom          time.Time              `json:"effective_from"`
+	EffectiveTo            *time.Time             `json:"effective_to,omitempty"`
+	CreatedBy              uint                   `json:"created_by"`
+	UpdatedBy              uint                   `json:"updated_by"`
+	CreatedAt              time.Time              `json:"created_at"`
+	UpdatedAt              time.Time              `json:"updated_at"`
+	DeletedAt              gorm.DeletedAt         `json:"-" gorm:"index"`
+	Plan                   *InsurancePlan         `json:"plan,omitempty" gorm:"foreignKey:PlanID"`
+}
+
+// CoversOn reports whether the coverage is in force on day.
+func (c *Coverage) CoversOn(day time.Time) bool {
+	day = time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.UTC)
+	from := time.Date(c.EffectiveFrom.Year(), c.EffectiveFrom.Month(), c.EffectiveFrom.Day(), 0, 0, 0, 0, time.UTC)
+	if day.Before(from) {
+		return false
+	}
+	if c.EffectiveTo != nil {
+		to := time.Date(c.EffectiveTo.Year(), c.EffectiveTo.Month(), c.EffectiveTo.Day(), 0, 0, 0, 0, time.UTC)
+		return !day.After(to)
+	}
+	return true
+}
+
+type EligibilityStatus string
+
+const (
+	EligibilityActive   EligibilityStatus = "active"
+	EligibilityInactive EligibilityStatus = "inactive"
+	EligibilityError    EligibilityStatus = "error"
+)
+
+// EligibilityCheck records what a payer said about a coverage for a date
+// of service, usually before an appointment. Source names the checker that
+// answered. Checks are kept as they were returned, so a later change to
+// the coverage does not rewrite them.
+type EligibilityCheck struct {
+	ID                       uint              `json:"id" gorm:"primaryKey"`
+	TenantID                 uint              `json:"tenant_id" gorm:"not null;default:1;index"`
+	PatientID                uint              `json:"patient_id" gorm:"not null;index"`
+	CoverageID               uint              `json:"coverage_id" gorm:"not null;index"`
+	AppointmentID            *uint             `json:"appointment_id,omitempty" gorm:"index"`
+	ServiceDate              time.Time         `json:"service_date"`
+	Status                   EligibilityStatus `json:"status" gorm:"type:varchar(20);not null"`
+	CopayCents               *int64            `json:"copay_cents,omitempty"`
+	DeductibleRemainingCents *int64            `json:"deductible_remaining_cents,omitempty"`
+	Message                  string            `json:"message" gorm:"type:text"`
+	Source                   string            `json:"source" gorm:"type:varchar(50);not null"`
+	CheckedBy                uint              `json:"checked_by"`
+	CheckedAt                time.Time         `json:"checked_at"`
+	Coverage                 *Coverage         `json:"coverage,omitempty" gorm:"foreignKey:CoverageID"`
+}
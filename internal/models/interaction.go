@@ -0,0 +1,60 @@
+package models
+
+import "time"
+
+type InteractionSeverity string
+
+const (
+	InteractionMinor           InteractionSeverity = "minor"
+	InteractionModerate        InteractionSeverity = "moderate"
+	InteractionMajor           InteractionSeverity = "major"
+	InteractionContraindicated InteractionSeverity = "contraindicated"
+)
+
+// Rank orders severities from minor (1) to contraindicated (4). Unknown
+// values rank 0.
+func (s InteractionSeverity) Rank() int {
+	switch s {
+	case InteractionMinor:
+		return 1
+	case InteractionModerate:
+		return 2
+	case InteractionMajor:
+		return 3
+	case InteractionContraindicated:
+		return 4
+	}
+	return 0
+}
+
+type InteractionType string
+
+const (
+	InteractionDrugAllergy      InteractionType = "drug-allergy"
+	InteractionCrossSensitivity InteractionType = "cross-sensitivity"
+	InteractionDrugDrug         InteractionType = "drug-drug"
+	InteractionDuplicateTherapy InteractionType = "duplicate-therapy"
+)
+
+// InteractionWarning is a problem found when checking a drug against the
+// patient's allergies and current medications. Conflict names the allergy
+// substance or the other drug.
+type InteractionWarning struct {
+	Type     InteractionType     `json:"type"`
+	Severity InteractionSeverity `json:"severity"`
+	Drug     string              `json:"drug"`
+	Conflict string              `json:"conflict"`
+	Message  string              `json:"message"`
+}
+
+// PrescriptionWarning is a warning the prescriber acknowledged when issuing a
+// prescription.
+type PrescriptionWarning struct {
+	ID             uint                `json:"id" gorm:"primaryKey"`
+	PrescriptionID uint                `json:"prescription_id" gorm:"not null;index"`
+	Type           InteractionType     `json:"type" gorm:"type:varchar(30);not null"`
+	Severity       InteractionSeverity `json:"severity" gorm:"type:varchar(20);not null"`
+	Conflict       string              `json:"conflict"`
+	Message        string              `json:"message" gorm:"type:text"`
+	CreatedAt      time.Time           `json:"created_at"`
+}
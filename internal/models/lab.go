@@ -0,0 +1,110 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type LabOrderStatus string
+
+const (
+	LabOrdered   LabOrderStatus = "ordered"
+	LabPartial   LabOrderStatus = "partial"
+	LabResulted  LabOrderStatus = "resulted"
+	LabCancelled LabOrderStatus = "cancelled"
+)
+
+type LabPriority string
+
+const (
+	LabRoutine LabPriority = "routine"
+	LabUrgent  LabPriority = "urgent"
+	LabStat    LabPriority = "stat"
+)
+
+// LabOrder is a test ordered by a doctor. Results arrive as one or more
+// batches; the order is resulted once the final batch is in. New results
+// clear the acknowledgement so the ordering doctor reviews them again.
+type LabOrder struct {
+	ID            uint           `json:"id" gorm:"primaryKey"`
+	TenantID      uint           `json:"tenant_id" gorm:"not null;default:1;index"`
+	PatientID     uint           `json:"patient_id" gorm:"not null;index"`
+	DoctorID      uint           `json:"doctor_id" gorm:"not null;index"`
+	EncounterID   *uint          `json:"encounter_id"`
+	TestCode      string         `json:"test_code" gorm:"type:varchar(20)"`
+	TestName      string         `json:"test_name" gorm:"not null"`
+	Priority      LabPriority    `json:"priority" gorm:"type:varchar(20);not null;default:'routine'"`
+	Status        LabOrderStatus `json:"status" gorm:"type:varchar(20);not null;default:'ordered'"`
+	ClinicalNotes string         `json:"clinical_notes" gorm:"type:text"`
+	OrderedAt     time.Time      `json:"ordered_at" gorm:"not null"`
+	ResultedAt    *time.Time     `json:"resulted_at"`
+	ReviewedBy    *uint          `json:"reviewed_by"`
+	ReviewedAt    *time.Time     `json:"reviewed_at"`
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+	DeletedAt     gorm.DeletedAt `json:"-" gorm:"index"`
+
+	// Abnormal is set when any loaded result is flagged.
+	Abnormal bool `json:"abnormal" gorm:"-"`
+
+	Patient *Patient    `json:"patient,omitempty" gorm:"foreignKey:PatientID"`
+	Doctor  *User       `json:"doctor,omitempty" gorm:"foreignKey:DoctorID"`
+	Results []LabResult `json:"results,omitempty" gorm:"foreignKey:LabOrderID"`
+}
+
+// AfterFind sets Abnormal from the preloaded results.
+func (o *LabOrder) AfterFind(tx *gorm.DB) error {
+	o.Abnormal = false
+	for _, result := range o.Results {
+		if result.Flag.IsAbnormal() {
+			o.Abnormal = true
+			break
+		}
+	}
+	return nil
+}
+
+type LabFlag string
+
+const (
+	LabFlagNormal       LabFlag = "normal"
+	LabFlagLow          LabFlag = "low"
+	LabFlagHigh         LabFlag = "high"
+	LabFlagCriticalLow  LabFlag = "critical_low"
+	LabFlagCriticalHigh LabFlag = "critical_high"
+	// LabFlagAbnormal marks a qualitative result that differs from the
+	// expected value, e.g. "Positive" where "Negative" is normal, or a
+	// numeric result that cannot be placed within its range.
+	LabFlagAbnormal LabFlag = "abnormal"
+)
+
+func (f LabFlag) IsAbnormal() bool {
+	return f != "" && f != LabFlagNormal
+}
+
+func (f LabFlag) IsCritical() bool {
+	return f == LabFlagCriticalLow || f == LabFlagCriticalHigh
+}
+
+// LabResult is one analyte reported for an order. ReferenceRange is kept as
+// reported ("3.5-5.0", "<200", "Negative"); the numeric bounds are parsed
+// from it for flagging.
+type LabResult struct {
+	ID             uint      `json:"id" gorm:"primaryKey"`
+	LabOrderID     uint      `json:"lab_order_id" gorm:"not null;index"`
+	Analyte        string    `json:"analyte" gorm:"not null"`
+	Value          string    `json:"value" gorm:"not null"`
+	NumericValue   *float64  `json:"numeric_value"`
+	Unit           string    `json:"unit" gorm:"type:varchar(30)"`
+	ReferenceRange string    `json:"reference_range" gorm:"type:varchar(50)"`
+	ReferenceLow   *float64  `json:"reference_low"`
+	ReferenceHigh  *float64  `json:"reference_high"`
+	CriticalLow    *float64  `json:"critical_low"`
+	CriticalHigh   *float64  `json:"critical_high"`
+	Flag           LabFlag   `json:"flag" gorm:"type:varchar(20);not null;default:'normal'"`
+	Notes          string    `json:"notes" gorm:"type:text"`
+	EnteredBy      uint      `json:"entered_by"`
+	ResultedAt     time.Time `json:"resulted_at" gorm:"not null"`
+	CreatedAt      time.Time `json:"created_at"`
+}
@@ -0,0 +1,59 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Location is a clinic site. Appointment dates and times booked at a location
+// are wall-clock values in the location's time zone.
+type Location struct {
+	ID        uint           `json:"id" gorm:"primaryKey"`
+	TenantID  uint           `json:"tenant_id" gorm:"not null;default:1;index;uniqueIndex:idx_locations_tenant_code,priority:1"`
+	Code      string         `json:"code" gorm:"type:varchar(50);uniqueIndex:idx_locations_tenant_code,priority:2;not null"`
+	Name      string         `json:"name" gorm:"not null"`
+	Address   string         `json:"address"`
+	Phone     string         `json:"phone" gorm:"type:varchar(50)"`
+	TimeZone  string         `json:"time_zone" gorm:"type:varchar(64);not null;default:'UTC'"`
+	IsActive  bool           `json:"is_active" gorm:"default:true"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// Zone returns the location's time zone, falling back to UTC when the stored
+// name cannot be loaded.
+func (l *Location) Zone() *time.Location {
+	zone, err := time.LoadLocation(l.TimeZone)
+	if err != nil {
+		return time.UTC
+	}
+	return zone
+}
+
+// DoctorSchedule assigns a doctor to a location for a block of hours on a
+// day of the week (0 = Sunday). Times are HH:MM in the location's time zone.
+type DoctorSchedule struct {
+	ID         uint           `json:"id" gorm:"primaryKey"`
+	TenantID   uint           `json:"tenant_id" gorm:"not null;default:1;index"`
+	DoctorID   uint           `json:"doctor_id" gorm:"not null;index"`
+	LocationID uint           `json:"location_id" gorm:"not null;index"`
+	DayOfWeek  time.Weekday   `json:"day_of_week" gorm:"not null"`
+	StartTime  string         `json:"start_time" gorm:"type:varchar(5);not null"`
+	EndTime    string         `json:"end_time" gorm:"type:varchar(5);not null"`
+	Location   *Location      `json:"location,omitempty" gorm:"foreignKey:LocationID"`
+	CreatedAt  time.Time      `json:"created_at"`
+	UpdatedAt  time.Time      `json:"updated_at"`
+	DeletedAt  gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// Window returns the schedule block in minutes after midnight.
+func (s *DoctorSchedule) Window() (start, end int, ok bool) {
+	from, errFrom := time.Parse("15:04", s.StartTime)
+	to, errTo := time.Parse("15:04", s.EndTime)
+	if errFrom != nil || errTo != nil {
+		return 0, 0, false
+	}
+	return from.Hour()*60 + from.Minute(), to.Hour()*60 + to.Minute(), true
+}
@@ -0,0 +1,101 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type MedicationRoute string
+
+const (
+	RouteOral          MedicationRoute = "oral"
+	RouteSublingual    MedicationRoute = "sublingual"
+	RouteTopical       MedicationRoute = "topical"
+	RouteInhaled       MedicationRoute = "inhaled"
+	RouteIntravenous   MedicationRoute = "intravenous"
+	RouteIntramuscular MedicationRoute = "intramuscular"
+	RouteSubcutaneous  MedicationRoute = "subcutaneous"
+	RouteRectal        MedicationRoute = "rectal"
+	RouteOther         MedicationRoute = "other"
+)
+
+type MedicationStatus string
+
+const (
+	MedicationActive    MedicationStatus = "active"
+	MedicationStopped   MedicationStatus = "stopped"
+	MedicationCompleted MedicationStatus = "completed"
+)
+
+// MedicationStatement is an entry on a patient's medication list: something
+// the patient is taking or has taken, whether prescribed here or elsewhere.
+type MedicationStatement struct {
+	ID             uint             `json:"id" gorm:"primaryKey"`
+	TenantID       uint             `json:"tenant_id" gorm:"not null;default:1;index"`
+	PatientID      uint             `json:"patient_id" gorm:"not null;index"`
+	PrescriptionID *uint            `json:"prescription_id" gorm:"index"`
+	Drug           string           `json:"drug" gorm:"not null"`
+	Dose           string           `json:"dose" gorm:"type:varchar(100)"`
+	Route          MedicationRoute  `json:"route" gorm:"type:varchar(20);not null;default:'oral'"`
+	Frequency      string           `json:"frequency" gorm:"type:varchar(100)"`
+	StartDate      *time.Time       `json:"start_date"`
+	StopDate       *time.Time       `json:"stop_date"`
+	Status         MedicationStatus `json:"status" gorm:"type:varchar(20);not null;default:'active'"`
+	Notes          string           `json:"notes" gorm:"type:text"`
+	RecordedBy     uint             `json:"recorded_by"`
+	CreatedAt      time.Time        `json:"created_at"`
+	UpdatedAt      time.Time        `json:"updated_at"`
+	DeletedAt      gorm.DeletedAt   `json:"-" gorm:"index"`
+}
+
+// IsActive reports whether the patient is currently taking the medication.
+func (m *MedicationStatement) IsActive() bool {
+	return m.Status == MedicationActive
+}
+
+type PrescriptionStatus string
+
+const (
+	PrescriptionActive    PrescriptionStatus = "active"
+	PrescriptionCompleted PrescriptionStatus = "completed"
+	PrescriptionCancelled PrescriptionStatus = "cancelled"
+)
+
+// Prescription is a doctor-issued order for a medication, optionally written
+// during an encounter. Issuing one adds the drug to the patient's medication
+// list. Interaction warnings raised at issue time are kept with the
+// prescriber's override reason.
+type Prescription struct {
+	ID             uint                  `json:"id" gorm:"primaryKey"`
+	TenantID       uint                  `json:"tenant_id" gorm:"not null;default:1;index"`
+	PatientID      uint                  `json:"patient_id" gorm:"not null;index"`
+	DoctorID       uint                  `json:"doctor_id" gorm:"not null;index"`
+	EncounterID    *uint                 `json:"encounter_id" gorm:"index"`
+	Drug           string                `json:"drug" gorm:"not null"`
+	Dose           string                `json:"dose" gorm:"type:varchar(100);not null"`
+	Route          MedicationRoute       `json:"route" gorm:"type:varchar(20);not null;default:'oral'"`
+	Frequency      string                `json:"frequency" gorm:"type:varchar(100);not null"`
+	Quantity       string                `json:"quantity" gorm:"type:varchar(100)"`
+	DurationDays   int                   `json:"duration_days"`
+	Refills        int                   `json:"refills" gorm:"not null;default:0"`
+	RefillsUsed    int                   `json:"refills_used" gorm:"not null;default:0"`
+	Instructions   string                `json:"instructions" gorm:"type:text"`
+	Status         PrescriptionStatus    `json:"status" gorm:"type:varchar(20);not null;default:'active'"`
+	IssuedAt       time.Time             `json:"issued_at"`
+	OverrideReason string                `json:"override_reason" gorm:"type:text"`
+	Warnings       []PrescriptionWarning `json:"warnings,omitempty" gorm:"foreignKey:PrescriptionID"`
+	Patient        *Patient              `json:"patient,omitempty" gorm:"foreignKey:PatientID"`
+	Doctor         *User                 `json:"doctor,omitempty" gorm:"foreignKey:DoctorID"`
+	CreatedAt      time.Time             `json:"created_at"`
+	UpdatedAt      time.Time             `json:"updated_at"`
+	DeletedAt      gorm.DeletedAt        `json:"-" gorm:"index"`
+}
+
+// RefillsRemaining is the number of refills that may still be dispensed.
+func (p *Prescription) RefillsRemaining() int {
+	if p.RefillsUsed >= p.Refills {
+		return 0
+	}
+	return p.Refills - p.RefillsUsed
+}
@@ -7,9 +7,13 @@ import (
 
 type Patient struct {
     ID                uint           `json:"id" gorm:"primaryKey"`
+    TenantID          uint           `json:"tenant_id" gorm:"not null;default:1;index;uniqueIndex:idx_patients_tenant_email,priority:1"`
+    // MRN is the generated medical record number printed on wristbands and
+    // documents; see services.MRNGenerator.
+    MRN               string         `json:"mrn" gorm:"type:varchar(50);index"`
     FirstName         string         `json:"first_name" gorm:"not null"`
     LastName          string         `json:"last_name" gorm:"not null"`
-    Email             string         `json:"email" gorm:"uniqueIndex"`
+    Email             string         `json:"email" gorm:"uniqueIndex:idx_patients_tenant_email,priority:2"`
     Phone             string         `json:"phone" gorm:"not null"`
     DateOfBirth       time.Time      `json:"date_of_birth"`
     Gender            string         `json:"gender" gorm:"type:varchar(20)"`
@@ -17,16 +21,54 @@ type Patient struct {
     MedicalHistory    string         `json:"medical_history" gorm:"type:text"`
     CurrentMedication string         `json:"current_medication" gorm:"type:text"`
     Allergies         string         `json:"allergies" gorm:"type:text"`
+    // EmergencyContact is the legacy free-text contact; RelatedPersons holds
+    // structured next of kin and guardians.
     EmergencyContact  string         `json:"emergency_contact"`
     BloodGroup        string         `json:"blood_group" gorm:"type:varchar(10)"`
+    // InsuranceNumber is free text kept from before coverages were recorded
+    // against insurance plans; see Coverage.
     InsuranceNumber   string         `json:"insurance_number" gorm:"type:varchar(50)"`
+    // DuplicateOverrideReason records why the patient was registered despite
+    // possible duplicate matches.
+    DuplicateOverrideReason string   `json:"duplicate_override_reason,omitempty" gorm:"type:text"`
+    // RegistrationLocationID is the site that registered the patient and
+    // issued the MRN.
+    RegistrationLocationID *uint     `json:"registration_location_id,omitempty"`
     RegisteredBy      uint           `json:"registered_by"`
     LastUpdatedBy     uint           `json:"last_updated_by"`
     CreatedAt         time.Time      `json:"created_at"`
     UpdatedAt         time.Time      `json:"updated_at"`
     DeletedAt         gorm.DeletedAt `json:"-" gorm:"index"`
+
+    Identifiers       []PatientIdentifier `json:"identifiers,omitempty" gorm:"foreignKey:PatientID"`
+    RelatedPersons    []RelatedPerson     `json:"related_persons,omitempty" gorm:"foreignKey:PatientID"`
     
     // Remove the relationship fields for now to avoid issues
     // RegisteredByUser  *User `json:"registered_by_user,omitempty" gorm:"foreignKey:RegisteredBy"`
     // LastUpdatedByUser *User `json:"last_updated_by_user,omitempty" gorm:"foreignKey:LastUpdatedBy"`
+}
+
+// AgeOfMajority is the age from which a patient no longer needs a guardian.
+const AgeOfMajority = 18
+
+// AgeOn returns the patient's age in whole years on date, or -1 when the date
+// of birth is unknown.
+func (p *Patient) AgeOn(date time.Time) int {
+    if p.DateOfBirth.IsZero() {
+        return -1
+    }
+    year, month, day := date.Date()
+    birthYear, birthMonth, birthDay := p.DateOfBirth.Date()
+    age := year - birthYear
+    if month < birthMonth || month == birthMonth && day < birthDay {
+        age--
+    }
+    return age
+}
+
+// IsMinorOn reports whether the patient is under AgeOfMajority on date.
+// Patients without a date of birth are treated as adults.
+func (p *Patient) IsMinorOn(date time.Time) bool {
+    age := p.AgeOn(date)
+    return age >= 0 && age < AgeOfMajority
 }
\ No newline at end of file
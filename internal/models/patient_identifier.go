@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+// Well-known identifier systems. Any other lower-case system name may be
+// used, e.g. "insurer_member_id:acme" to keep one insurer's member IDs apart
+// from another's.
+const (
+	IdentifierMRN             = "mrn"
+	IdentifierPreviousMRN     = "previous_mrn"
+	IdentifierNationalID      = "national_id"
+	IdentifierInsurerMemberID = "insurer_member_id"
+	IdentifierPassport        = "passport"
+	IdentifierDriversLicense  = "drivers_license"
+)
+
+// PatientIdentifier is an external identifier for a patient. A value is
+// unique within its system and tenant. Values are stored normalized.
+type PatientIdentifier struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	TenantID  uint      `json:"tenant_id" gorm:"not null;default:1;index;uniqueIndex:idx_patient_identifiers_system_value,priority:1"`
+	PatientID uint      `json:"patient_id" gorm:"not null;index"`
+	System    string    `json:"system" gorm:"type:varchar(100);not null;uniqueIndex:idx_patient_identifiers_system_value,priority:2"`
+	Value     string    `json:"value" gorm:"type:varchar(100);not null;uniqueIndex:idx_patient_identifiers_system_value,priority:3"`
+	Assigner  string    `json:"assigner,omitempty" gorm:"type:varchar(255)"`
+	CreatedBy uint      `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// MRNSequence is the next MRN number for a location. LocationID 0 holds the
+// tenant-wide sequence used when MRNs are not issued per location.
+type MRNSequence struct {
+	ID         uint   `json:"id" gorm:"primaryKey"`
+	TenantID   uint   `json:"tenant_id" gorm:"not null;default:1;uniqueIndex:idx_mrn_sequences_tenant_location,priority:1"`
+	LocationID uint   `json:"location_id" gorm:"not null;default:0;uniqueIndex:idx_mrn_sequences_tenant_location,priority:2"`
+	NextValue  uint64 `json:"next_value" gorm:"not null;default:1"`
+}
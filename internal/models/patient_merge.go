@@ -0,0 +1,36 @@
+package models
+
+import "time"
+
+// MatchConfidence grades how likely a duplicate candidate is the same person.
+type MatchConfidence string
+
+const (
+	MatchPossible MatchConfidence = "possible"
+	MatchProbable MatchConfidence = "probable"
+)
+
+// DuplicateCandidate is an existing patient that may be the same person as
+// the one being registered. Reasons lists the fields that agreed.
+type DuplicateCandidate struct {
+	Patient    Patient         `json:"patient"`
+	Score      float64         `json:"score"`
+	Confidence MatchConfidence `json:"confidence"`
+	Reasons    []string        `json:"reasons"`
+}
+
+// PatientMerge is the tombstone left when a duplicate record is merged into
+// the surviving one. The duplicate row itself is soft-deleted; Snapshot keeps
+// its demographics as they were at merge time and MovedRecords counts the
+// rows re-pointed per table.
+type PatientMerge struct {
+	ID                uint             `json:"id" gorm:"primaryKey"`
+	TenantID          uint             `json:"tenant_id" gorm:"not null;default:1;index"`
+	SurvivorPatientID uint             `json:"survivor_patient_id" gorm:"not null;index"`
+	MergedPatientID   uint             `json:"merged_patient_id" gorm:"not null;uniqueIndex"`
+	Reason            string           `json:"reason" gorm:"type:text"`
+	Snapshot          Patient          `json:"snapshot" gorm:"type:text;serializer:json"`
+	MovedRecords      map[string]int64 `json:"moved_records" gorm:"type:text;serializer:json"`
+	MergedBy          uint             `json:"merged_by" gorm:"not null"`
+	CreatedAt         time.Time        `json:"created_at"`
+}
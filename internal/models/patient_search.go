@@ -0,0 +1,45 @@
+package models
+
+import (
+	"time"
+)
+
+// PatientSearchTerm is one entry of the patient search index: a word, a
+// phonetic code or a trigram the patient is found by. The index is
+// rebuilt from the patient whenever they are saved.
+type PatientSearchTerm struct {
+	ID        uint   `json:"id" gorm:"primaryKey"`
+	TenantID  uint   `json:"tenant_id" gorm:"not null;default:1;index:idx_patient_search_terms_lookup,priority:1"`
+	PatientID uint   `json:"patient_id" gorm:"not null;index"`
+	Kind      string `json:"kind" gorm:"type:varchar(10);not null;index:idx_patient_search_terms_lookup,priority:2"`
+	Term      string `json:"term" gorm:"type:varchar(255);not null;index:idx_patient_search_terms_lookup,priority:3"`
+}
+
+// PatientSearchQuery is a ranked patient search. Text is free text matched
+// against names, email, phone and MRN, tolerating typos in names; either it
+// or one of the filters is required. MRN also finds patients by a previous
+// MRN they had before a merge.
+type PatientSearchQuery struct {
+	Text        string
+	DateOfBirth *time.Time
+	MRN         string
+	Page        int
+	Limit       int
+}
+
+// PatientSearchHit is a patient found by a search with how well they
+// matched, from 0 to 1, and the fields that matched.
+type PatientSearchHit struct {
+	Patient       Patient  `json:"patient"`
+	Score         float64  `json:"score"`
+	MatchedFields []string `json:"matched_fields,omitempty"`
+}
+
+// PatientSearchPage is one page of search hits, best first. For text
+// searches Total stops at the number of candidates a search ranks.
+type PatientSearchPage struct {
+	Hits  []PatientSearchHit `json:"hits"`
+	Total int64              `json:"total"`
+	Page  int                `json:"page"`
+	Limit int                `json:"limit"`
+}
@@ -0,0 +1,54 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// DiagnosisCode is an entry in the locally loaded ICD-10 code table. Codes
+// are stored upper case with the dot, e.g. "E11.9".
+type DiagnosisCode struct {
+	Code        string `json:"code" gorm:"primaryKey;type:varchar(10)"`
+	Description string `json:"description" gorm:"not null"`
+}
+
+type ProblemStatus string
+
+const (
+	ProblemActive   ProblemStatus = "active"
+	ProblemResolved ProblemStatus = "resolved"
+)
+
+// Problem is a coded diagnosis on a patient's problem list.
+type Problem struct {
+	ID           uint           `json:"id" gorm:"primaryKey"`
+	TenantID     uint           `json:"tenant_id" gorm:"not null;default:1;index"`
+	PatientID    uint           `json:"patient_id" gorm:"not null;index"`
+	Code         string         `json:"code" gorm:"type:varchar(10);not null"`
+	Description  string         `json:"description" gorm:"not null"`
+	Status       ProblemStatus  `json:"status" gorm:"type:varchar(20);not null;default:'active'"`
+	OnsetDate    *time.Time     `json:"onset_date"`
+	ResolvedDate *time.Time     `json:"resolved_date"`
+	EncounterID  *uint          `json:"encounter_id"`
+	Notes        string         `json:"notes" gorm:"type:text"`
+	RecordedBy   uint           `json:"recorded_by"`
+	CreatedAt    time.Time      `json:"created_at"`
+	UpdatedAt    time.Time      `json:"updated_at"`
+	DeletedAt    gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// AppointmentDiagnosis is a code billed or documented for a completed
+// appointment. Rank 1 is the primary diagnosis.
+type AppointmentDiagnosis struct {
+	ID            uint      `json:"id" gorm:"primaryKey"`
+	AppointmentID uint      `json:"appointment_id" gorm:"not null;index"`
+	Code          string    `json:"code" gorm:"type:varchar(10);not null"`
+	Description   string    `json:"description" gorm:"not null"`
+	Rank          int       `json:"rank" gorm:"not null"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+func (AppointmentDiagnosis) TableName() string {
+	return "appointment_diagnoses"
+}
@@ -0,0 +1,62 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type ReferralStatus string
+
+const (
+	ReferralSent      ReferralStatus = "sent"
+	ReferralAccepted  ReferralStatus = "accepted"
+	ReferralDeclined  ReferralStatus = "declined"
+	ReferralScheduled ReferralStatus = "scheduled"
+	ReferralClosed    ReferralStatus = "closed"
+)
+
+type ReferralUrgency string
+
+const (
+	ReferralRoutine  ReferralUrgency = "routine"
+	ReferralUrgent   ReferralUrgency = "urgent"
+	ReferralEmergent ReferralUrgency = "emergent"
+)
+
+// Referral asks a colleague to see a patient. It is addressed either to a
+// receiving doctor or, when ReceivingDoctorID is nil, to any doctor with the
+// given specialty; whoever accepts it becomes the receiving doctor.
+type Referral struct {
+	ID                uint            `json:"id" gorm:"primaryKey"`
+	TenantID          uint            `json:"tenant_id" gorm:"not null;default:1;index"`
+	PatientID         uint            `json:"patient_id" gorm:"not null;index"`
+	ReferringDoctorID uint            `json:"referring_doctor_id" gorm:"not null;index"`
+	ReceivingDoctorID *uint           `json:"receiving_doctor_id" gorm:"index"`
+	Specialty         string          `json:"specialty" gorm:"type:varchar(100)"`
+	Urgency           ReferralUrgency `json:"urgency" gorm:"type:varchar(20);not null;default:'routine'"`
+	Reason            string          `json:"reason" gorm:"type:text;not null"`
+	Status            ReferralStatus  `json:"status" gorm:"type:varchar(20);not null;default:'sent'"`
+	ResponseNote      string          `json:"response_note" gorm:"type:text"`
+	RespondedAt       *time.Time      `json:"responded_at"`
+	AppointmentID     *uint           `json:"appointment_id"`
+	ClosedAt          *time.Time      `json:"closed_at"`
+	CreatedAt         time.Time       `json:"created_at"`
+	UpdatedAt         time.Time       `json:"updated_at"`
+	DeletedAt         gorm.DeletedAt  `json:"-" gorm:"index"`
+
+	Patient         *Patient             `json:"patient,omitempty" gorm:"foreignKey:PatientID"`
+	ReferringDoctor *User                `json:"referring_doctor,omitempty" gorm:"foreignKey:ReferringDoctorID"`
+	ReceivingDoctor *User                `json:"receiving_doctor,omitempty" gorm:"foreignKey:ReceivingDoctorID"`
+	Appointment     *Appointment         `json:"appointment,omitempty" gorm:"foreignKey:AppointmentID"`
+	Attachments     []ReferralAttachment `json:"attachments,omitempty" gorm:"foreignKey:ReferralID"`
+}
+
+// ReferralAttachment links a patient document, such as a referral letter or
+// imaging report, to the referral.
+type ReferralAttachment struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	ReferralID uint      `json:"referral_id" gorm:"not null;uniqueIndex:idx_referral_document"`
+	DocumentID uint      `json:"document_id" gorm:"not null;uniqueIndex:idx_referral_document"`
+	Document   *Document `json:"document,omitempty" gorm:"foreignKey:DocumentID"`
+}
@@ -0,0 +1,64 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type Relationship string
+
+const (
+	RelationshipParent        Relationship = "parent"
+	RelationshipLegalGuardian Relationship = "legal_guardian"
+	RelationshipSpouse        Relationship = "spouse"
+	RelationshipPartner       Relationship = "partner"
+	RelationshipChild         Relationship = "child"
+	RelationshipSibling       Relationship = "sibling"
+	RelationshipGrandparent   Relationship = "grandparent"
+	RelationshipFriend        Relationship = "friend"
+	RelationshipCaregiver     Relationship = "caregiver"
+	RelationshipOther         Relationship = "other"
+)
+
+// IsValid reports whether r is one of the known relationships.
+func (r Relationship) IsValid() bool {
+	switch r {
+	case RelationshipParent, RelationshipLegalGuardian, RelationshipSpouse, RelationshipPartner,
+		RelationshipChild, RelationshipSibling, RelationshipGrandparent, RelationshipFriend,
+		RelationshipCaregiver, RelationshipOther:
+		return true
+	}
+	return false
+}
+
+// RelatedPerson is a next of kin, guardian or other contact of a patient.
+// Minors must have at least one guardian. ConsentToShare records that the
+// patient (or their guardian) agreed to clinical information being shared
+// with this person.
+type RelatedPerson struct {
+	ID                 uint           `json:"id" gorm:"primaryKey"`
+	TenantID           uint           `json:"tenant_id" gorm:"not null;default:1;index"`
+	PatientID          uint           `json:"patient_id" gorm:"not null;index"`
+	Name               string         `json:"name" gorm:"not null"`
+	Relationship       Relationship   `json:"relationship" gorm:"type:varchar(30);not null"`
+	MobilePhone        string         `json:"mobile_phone" gorm:"type:varchar(50)"`
+	HomePhone          string         `json:"home_phone" gorm:"type:varchar(50)"`
+	WorkPhone          string         `json:"work_phone" gorm:"type:varchar(50)"`
+	Email              string         `json:"email"`
+	Address            string         `json:"address" gorm:"type:text"`
+	IsGuardian         bool           `json:"is_guardian" gorm:"not null;default:false"`
+	IsEmergencyContact bool           `json:"is_emergency_contact" gorm:"not null;default:false"`
+	ConsentToShare     bool           `json:"consent_to_share" gorm:"not null;default:false"`
+	Notes              string         `json:"notes" gorm:"type:text"`
+	CreatedBy          uint           `json:"created_by"`
+	UpdatedBy          uint           `json:"updated_by"`
+	CreatedAt          time.Time      `json:"created_at"`
+	UpdatedAt          time.Time      `json:"updated_at"`
+	DeletedAt          gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// TableName keeps the table named after the model rather than "people".
+func (RelatedPerson) TableName() string {
+	return "related_persons"
+}
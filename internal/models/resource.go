@@ -0,0 +1,55 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type ResourceKind string
+
+const (
+	ResourceKindRoom      ResourceKind = "room"
+	ResourceKindEquipment ResourceKind = "equipment"
+)
+
+// Resource is a bookable room or piece of equipment. Capabilities describe
+// what the resource can be used for (e.g. "exam", "ultrasound") and are what
+// appointment types ask for.
+type Resource struct {
+	ID           uint                 `json:"id" gorm:"primaryKey"`
+	TenantID     uint                 `json:"tenant_id" gorm:"not null;default:1;index"`
+	Name         string               `json:"name" gorm:"not null"`
+	Kind         ResourceKind         `json:"kind" gorm:"type:varchar(20);not null"`
+	LocationID   *uint                `json:"location_id" gorm:"index"`
+	Description  string               `json:"description" gorm:"type:text"`
+	IsActive     bool                 `json:"is_active" gorm:"default:true"`
+	Capabilities []ResourceCapability `json:"capabilities" gorm:"foreignKey:ResourceID"`
+	CreatedAt    time.Time            `json:"created_at"`
+	UpdatedAt    time.Time            `json:"updated_at"`
+	DeletedAt    gorm.DeletedAt       `json:"-" gorm:"index"`
+}
+
+type ResourceCapability struct {
+	ID         uint   `json:"id" gorm:"primaryKey"`
+	ResourceID uint   `json:"resource_id" gorm:"not null;index"`
+	Capability string `json:"capability" gorm:"type:varchar(100);not null;index"`
+}
+
+// AppointmentResource records that a resource is held by an appointment.
+type AppointmentResource struct {
+	ID            uint      `json:"id" gorm:"primaryKey"`
+	AppointmentID uint      `json:"appointment_id" gorm:"not null;index"`
+	ResourceID    uint      `json:"resource_id" gorm:"not null;index"`
+	Resource      *Resource `json:"resource,omitempty" gorm:"foreignKey:ResourceID"`
+}
+
+// HasCapability reports whether the resource offers the given capability.
+func (r *Resource) HasCapability(capability string) bool {
+	for _, c := range r.Capabilities {
+		if c.Capability == capability {
+			return true
+		}
+	}
+	return false
+}
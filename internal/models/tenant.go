@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// DefaultTenantID is the practice that owns every row created before
+// multi-tenancy was introduced.
+const DefaultTenantID uint = 1
+
+// Tenant is an independent practice hosted on the deployment. Users, patients,
+// appointments and the practice's own catalogs all carry a TenantID.
+type Tenant struct {
+	ID        uint           `json:"id" gorm:"primaryKey"`
+	Name      string         `json:"name" gorm:"not null"`
+	Slug      string         `json:"slug" gorm:"type:varchar(100);uniqueIndex;not null"`
+	IsActive  bool           `json:"is_active" gorm:"default:true"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// NumberSequence is the last number a tenant issued in one of its numbering
+// series, such as "invoice" or "claim".
+type NumberSequence struct {
+	ID        uint   `json:"id" gorm:"primaryKey"`
+	TenantID  uint   `json:"tenant_id" gorm:"not null;default:1;uniqueIndex:idx_number_sequences_tenant_name,priority:1"`
+	Name      string `json:"name" gorm:"type:varchar(50);not null;uniqueIndex:idx_number_sequences_tenant_name,priority:2"`
+	LastValue int    `json:"last_value" gorm:"not null;default:0"`
+}
@@ -10,14 +10,18 @@ type UserRole string
 const (
     RoleReceptionist UserRole = "receptionist"
     RoleDoctor       UserRole = "doctor"
+    RoleNurse        UserRole = "nurse"
+    RoleAdmin        UserRole = "admin"
 )
 
 type User struct {
     ID        uint           `json:"id" gorm:"primaryKey"`
+    TenantID  uint           `json:"tenant_id" gorm:"not null;default:1;index"`
     Email     string         `json:"email" gorm:"uniqueIndex;not null"`
     Password  string         `json:"-" gorm:"not null"`
     Name      string         `json:"name" gorm:"not null"`
     Role      UserRole       `json:"role" gorm:"type:varchar(50);not null"`
+    Specialty string         `json:"specialty,omitempty" gorm:"type:varchar(100)"`
     IsActive  bool           `json:"is_active" gorm:"default:true"`
     CreatedAt time.Time      `json:"created_at"`
     UpdatedAt time.Time      `json:"updated_at"`
@@ -0,0 +1,193 @@
+package models
+
+import (
+	"math"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Vital measurement names, used for flags and time series.
+const (
+	VitalSystolicBP       = "systolic_bp"
+	VitalDiastolicBP      = "diastolic_bp"
+	VitalHeartRate        = "heart_rate"
+	VitalRespiratoryRate  = "respiratory_rate"
+	VitalTemperature      = "temperature"
+	VitalOxygenSaturation = "oxygen_saturation"
+	VitalWeight           = "weight"
+	VitalHeight           = "height"
+	VitalBMI              = "bmi"
+)
+
+type VitalFlagLevel string
+
+const (
+	FlagLow          VitalFlagLevel = "low"
+	FlagHigh         VitalFlagLevel = "high"
+	FlagCriticalLow  VitalFlagLevel = "critical_low"
+	FlagCriticalHigh VitalFlagLevel = "critical_high"
+)
+
+// VitalRange is the adult reference range for a measurement. Values outside
+// [Low, High] are flagged, and outside [CriticalLow, CriticalHigh] are
+// critical.
+type VitalRange struct {
+	Unit         string
+	Low          float64
+	High         float64
+	CriticalLow  float64
+	CriticalHigh float64
+}
+
+// VitalRanges holds the reference range and stored unit of each measurement.
+// Weight and height are stored without a range.
+var VitalRanges = map[string]VitalRange{
+	VitalSystolicBP:       {Unit: "mmHg", Low: 90, High: 139, CriticalLow: 70, CriticalHigh: 180},
+	VitalDiastolicBP:      {Unit: "mmHg", Low: 60, High: 89, CriticalLow: 40, CriticalHigh: 120},
+	VitalHeartRate:        {Unit: "bpm", Low: 60, High: 100, CriticalLow: 40, CriticalHigh: 130},
+	VitalRespiratoryRate:  {Unit: "breaths/min", Low: 12, High: 20, CriticalLow: 8, CriticalHigh: 30},
+	VitalTemperature:      {Unit: "°C", Low: 36.0, High: 37.9, CriticalLow: 35.0, CriticalHigh: 40.0},
+	VitalOxygenSaturation: {Unit: "%", Low: 95, High: 100, CriticalLow: 90, CriticalHigh: 100},
+	VitalBMI:              {Unit: "kg/m²", Low: 18.5, High: 24.9, CriticalLow: 0, CriticalHigh: math.Inf(1)},
+	VitalWeight:           {Unit: "kg", Low: 0, High: math.Inf(1), CriticalLow: 0, CriticalHigh: math.Inf(1)},
+	VitalHeight:           {Unit: "cm", Low: 0, High: math.Inf(1), CriticalLow: 0, CriticalHigh: math.Inf(1)},
+}
+
+// VitalFlag marks a measurement outside its reference range.
+type VitalFlag struct {
+	Measurement string         `json:"measurement"`
+	Value       float64        `json:"value"`
+	Unit        string         `json:"unit"`
+	Level       VitalFlagLevel `json:"level"`
+}
+
+// VitalSigns is one set of measurements taken at the same time. Values are
+// stored in metric units; measurements that were not taken are nil.
+type VitalSigns struct {
+	ID               uint           `json:"id" gorm:"primaryKey"`
+	TenantID         uint           `json:"tenant_id" gorm:"not null;default:1;index"`
+	PatientID        uint           `json:"patient_id" gorm:"not null;index"`
+	AppointmentID    *uint          `json:"appointment_id" gorm:"index"`
+	RecordedBy       uint           `json:"recorded_by"`
+	RecordedAt       time.Time      `json:"recorded_at" gorm:"not null"`
+	SystolicBP       *int           `json:"systolic_bp"`
+	DiastolicBP      *int           `json:"diastolic_bp"`
+	HeartRate        *int           `json:"heart_rate"`
+	RespiratoryRate  *int           `json:"respiratory_rate"`
+	TemperatureC     *float64       `json:"temperature_c"`
+	OxygenSaturation *int           `json:"oxygen_saturation"`
+	WeightKg         *float64       `json:"weight_kg"`
+	HeightCm         *float64       `json:"height_cm"`
+	BMI              *float64       `json:"bmi"`
+	Notes            string         `json:"notes" gorm:"type:text"`
+	CreatedAt        time.Time      `json:"created_at"`
+	UpdatedAt        time.Time      `json:"updated_at"`
+	DeletedAt        gorm.DeletedAt `json:"-" gorm:"index"`
+
+	// Flags lists the measurements outside their reference range.
+	Flags []VitalFlag `json:"flags" gorm:"-"`
+}
+
+func (VitalSigns) TableName() string {
+	return "vital_signs"
+}
+
+// Value returns the named measurement, or ok false when it was not taken.
+func (v *VitalSigns) Value(measurement string) (value float64, ok bool) {
+	intValue := func(p *int) (float64, bool) {
+		if p == nil {
+			return 0, false
+		}
+		return float64(*p), true
+	}
+	floatValue := func(p *float64) (float64, bool) {
+		if p == nil {
+			return 0, false
+		}
+		return *p, true
+	}
+
+	switch measurement {
+	case VitalSystolicBP:
+		return intValue(v.SystolicBP)
+	case VitalDiastolicBP:
+		return intValue(v.DiastolicBP)
+	case VitalHeartRate:
+		return intValue(v.HeartRate)
+	case VitalRespiratoryRate:
+		return intValue(v.RespiratoryRate)
+	case VitalTemperature:
+		return floatValue(v.TemperatureC)
+	case VitalOxygenSaturation:
+		return intValue(v.OxygenSaturation)
+	case VitalWeight:
+		return floatValue(v.WeightKg)
+	case VitalHeight:
+		return floatValue(v.HeightCm)
+	case VitalBMI:
+		return floatValue(v.BMI)
+	}
+	return 0, false
+}
+
+// ComputeBMI sets BMI from weight and height, rounded to one decimal. BMI is
+// cleared when either measurement is missing.
+func (v *VitalSigns) ComputeBMI() {
+	v.BMI = nil
+	if v.WeightKg == nil || v.HeightCm == nil || *v.HeightCm <= 0 {
+		return
+	}
+	meters := *v.HeightCm / 100
+	bmi := math.Round(*v.WeightKg/(meters*meters)*10) / 10
+	v.BMI = &bmi
+}
+
+// Evaluate sets Flags from the reference ranges.
+func (v *VitalSigns) Evaluate() {
+	v.Flags = nil
+	for _, measurement := range []string{
+		VitalSystolicBP, VitalDiastolicBP, VitalHeartRate, VitalRespiratoryRate,
+		VitalTemperature, VitalOxygenSaturation, VitalBMI,
+	} {
+		value, ok := v.Value(measurement)
+		if !ok {
+			continue
+		}
+		limits := VitalRanges[measurement]
+		if level, flagged := limits.Classify(value); flagged {
+			v.Flags = append(v.Flags, VitalFlag{Measurement: measurement, Value: value, Unit: limits.Unit, Level: level})
+		}
+	}
+}
+
+// Classify returns the flag for value, or flagged false when it is within
+// the reference range.
+func (r VitalRange) Classify(value float64) (level VitalFlagLevel, flagged bool) {
+	switch {
+	case value < r.CriticalLow:
+		return FlagCriticalLow, true
+	case value > r.CriticalHigh:
+		return FlagCriticalHigh, true
+	case value < r.Low:
+		return FlagLow, true
+	case value > r.High:
+		return FlagHigh, true
+	}
+	return "", false
+}
+
+// AfterFind flags the loaded measurements.
+func (v *VitalSigns) AfterFind(tx *gorm.DB) error {
+	v.Evaluate()
+	return nil
+}
+
+// VitalPoint is one value in a measurement's time series.
+type VitalPoint struct {
+	VitalSignsID uint           `json:"vital_signs_id"`
+	RecordedAt   time.Time      `json:"recorded_at"`
+	Value        float64        `json:"value"`
+	Unit         string         `json:"unit"`
+	Flag         VitalFlagLevel `json:"flag,omitempty"`
+}
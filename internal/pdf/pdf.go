@@ -0,0 +1,310 @@
+// Package pdf writes simple PDF documents: text in the standard Helvetica
+// faces, lines, filled rectangles and raster images on fixed-size pages.
+// It has no dependencies outside the standard library, which is all the
+// portal's printouts need; layout is left to the caller.
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Page sizes in points, 72 to the inch.
+const (
+	LetterWidth  = 612.0
+	LetterHeight = 792.0
+)
+
+var ErrInvalidColor = errors.New("colors must be written as #RRGGBB")
+
+// Color is an RGB color.
+type Color struct {
+	R, G, B uint8
+}
+
+var (
+	Black = Color{0, 0, 0}
+	White = Color{255, 255, 255}
+)
+
+// ParseColor reads a color written as #RRGGBB.
+func ParseColor(hex string) (Color, error) {
+	hex = strings.TrimPrefix(strings.TrimSpace(hex), "#")
+	if len(hex) != 6 {
+		return Color{}, ErrInvalidColor
+	}
+	value, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return Color{}, ErrInvalidColor
+	}
+	return Color{R: uint8(value >> 16), G: uint8(value >> 8), B: uint8(value)}, nil
+}
+
+func (c Color) operands() string {
+	return fmt.Sprintf("%s %s %s", number(float64(c.R)/255), number(float64(c.G)/255), number(float64(c.B)/255))
+}
+
+// Document is a PDF being built. Coordinates given to its pages are in
+// points from the top-left corner of the page, with y growing downwards.
+type Document struct {
+	width  float64
+	height float64
+	title  string
+	pages  []*Page
+	images []*Image
+}
+
+// New starts an empty document whose pages are width by height points.
+func New(width, height float64) *Document {
+	return &Document{width: width, height: height}
+}
+
+// SetTitle sets the title viewers show for the document.
+func (d *Document) SetTitle(title string) {
+	d.title = title
+}
+
+// Size returns the page width and height in points.
+func (d *Document) Size() (width, height float64) {
+	return d.width, d.height
+}
+
+// AddPage appends a blank page.
+func (d *Document) AddPage() *Page {
+	page := &Page{doc: d}
+	d.pages = append(d.pages, page)
+	return page
+}
+
+// Pages returns the document's pages in order.
+func (d *Document) Pages() []*Page {
+	return d.pages
+}
+
+// Image is a raster image added to a document, which any of its pages can
+// draw.
+type Image struct {
+	name   string
+	width  int
+	height int
+	pixels []byte
+}
+
+// Size returns the image's dimensions in pixels.
+func (img *Image) Size() (width, height int) {
+	return img.width, img.height
+}
+
+// AddImage adds img to the document. Transparent pixels are flattened onto
+// white, since the images are only drawn on white paper.
+func (d *Document) AddImage(img image.Image) *Image {
+	bounds := img.Bounds()
+	pixels := make([]byte, 0, bounds.Dx()*bounds.Dy()*3)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			// RGBA returns alpha-premultiplied 16-bit channels.
+			r, g, b, a := img.At(x, y).RGBA()
+			white := 0xFFFF - a
+			pixels = append(pixels, byte((r+white)>>8), byte((g+white)>>8), byte((b+white)>>8))
+		}
+	}
+
+	added := &Image{
+		name:   fmt.Sprintf("Im%d", len(d.images)+1),
+		width:  bounds.Dx(),
+		height: bounds.Dy(),
+		pixels: pixels,
+	}
+	d.images = append(d.images, added)
+	return added
+}
+
+// Page is one page of a document. Its drawing methods append to the page's
+// content in the order they are called, so later drawing covers earlier.
+type Page struct {
+	doc     *Document
+	content bytes.Buffer
+}
+
+// Text draws text with its baseline at y, starting at x.
+func (p *Page) Text(x, y float64, font Font, size float64, color Color, text string) {
+	fmt.Fprintf(&p.content, "BT /F%d %s Tf %s rg %s %s Td %s Tj ET\n",
+		int(font)+1, number(size), color.operands(), number(x), number(p.doc.height-y), literal(encode(text)))
+}
+
+// Line draws a straight line width points thick.
+func (p *Page) Line(x1, y1, x2, y2, width float64, color Color) {
+	fmt.Fprintf(&p.content, "%s w %s RG %s %s m %s %s l S\n",
+		number(width), color.operands(), number(x1), number(p.doc.height-y1), number(x2), number(p.doc.height-y2))
+}
+
+// Rect fills a width by height rectangle whose top-left corner is at x, y.
+func (p *Page) Rect(x, y, width, height float64, fill Color) {
+	fmt.Fprintf(&p.content, "%s rg %s %s %s %s re f\n",
+		fill.operands(), number(x), number(p.doc.height-y-height), number(width), number(height))
+}
+
+// Image draws img scaled to width by height points with its top-left corner
+// at x, y.
+func (p *Page) Image(img *Image, x, y, width, height float64) {
+	fmt.Fprintf(&p.content, "q %s 0 0 %s %s %s cm /%s Do Q\n",
+		number(width), number(height), number(x), number(p.doc.height-y-height), img.name)
+}
+
+// Bytes renders the document.
+func (d *Document) Bytes() ([]byte, error) {
+	var buf bytes.Buffer
+	if _, err := d.WriteTo(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// WriteTo renders the document to w.
+func (d *Document) WriteTo(w io.Writer) (int64, error) {
+	out := &objectWriter{}
+	out.WriteString("%PDF-1.4\n%\xE2\xE3\xCF\xD3\n")
+
+	// Objects 1 to 5 are fixed; images and then pages follow.
+	const (
+		catalogObject   = 1
+		pagesObject     = 2
+		resourcesObject = 3
+		infoObject      = 4
+		firstFontObject = 5
+	)
+	firstImageObject := firstFontObject + len(baseFonts)
+	firstPageObject := firstImageObject + len(d.images)
+
+	out.object(catalogObject, fmt.Sprintf("<< /Type /Catalog /Pages %d 0 R >>", pagesObject))
+
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", firstPageObject+2*i)
+	}
+	out.object(pagesObject, fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d /MediaBox [0 0 %s %s] >>",
+		strings.Join(kids, " "), len(d.pages), number(d.width), number(d.height)))
+
+	var resources strings.Builder
+	resources.WriteString("<< /ProcSet [/PDF /Text /ImageC] /Font <<")
+	for i := range baseFonts {
+		fmt.Fprintf(&resources, " /F%d %d 0 R", i+1, firstFontObject+i)
+	}
+	resources.WriteString(" >>")
+	if len(d.images) > 0 {
+		resources.WriteString(" /XObject <<")
+		for i, img := range d.images {
+			fmt.Fprintf(&resources, " /%s %d 0 R", img.name, firstImageObject+i)
+		}
+		resources.WriteString(" >>")
+	}
+	resources.WriteString(" >>")
+	out.object(resourcesObject, resources.String())
+
+	out.object(infoObject, fmt.Sprintf("<< /Title %s /Producer (healthcare-portal) >>", literal(encode(d.title))))
+
+	for i, name := range baseFonts {
+		out.object(firstFontObject+i, fmt.Sprintf("<< /Type /Font /Subtype /Type1 /BaseFont /%s /Encoding /WinAnsiEncoding >>", name))
+	}
+
+	for i, img := range d.images {
+		if err := out.stream(firstImageObject+i, fmt.Sprintf("/Type /XObject /Subtype /Image /Width %d /Height %d /ColorSpace /DeviceRGB /BitsPerComponent 8",
+			img.width, img.height), img.pixels); err != nil {
+			return 0, err
+		}
+	}
+
+	for i, page := range d.pages {
+		pageObject := firstPageObject + 2*i
+		out.object(pageObject, fmt.Sprintf("<< /Type /Page /Parent %d 0 R /Resources %d 0 R /Contents %d 0 R >>",
+			pagesObject, resourcesObject, pageObject+1))
+		if err := out.stream(pageObject+1, "", page.content.Bytes()); err != nil {
+			return 0, err
+		}
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(out, "xref\n0 %d\n0000000000 65535 f \n", len(out.offsets)+1)
+	for _, offset := range out.offsets {
+		fmt.Fprintf(out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(out, "trailer\n<< /Size %d /Root %d 0 R /Info %d 0 R >>\nstartxref\n%d\n%%%%EOF\n",
+		len(out.offsets)+1, catalogObject, infoObject, xref)
+
+	n, err := w.Write(out.Bytes())
+	return int64(n), err
+}
+
+// objectWriter buffers the file and records where each object starts for
+// the cross-reference table. Objects must be written in number order.
+type objectWriter struct {
+	bytes.Buffer
+	offsets []int
+}
+
+func (w *objectWriter) object(number int, body string) {
+	w.begin(number)
+	fmt.Fprintf(w, "%s\nendobj\n", body)
+}
+
+// stream writes data Flate-compressed, with entries added to the stream
+// dictionary.
+func (w *objectWriter) stream(number int, entries string, data []byte) error {
+	var compressed bytes.Buffer
+	zw := zlib.NewWriter(&compressed)
+	if _, err := zw.Write(data); err != nil {
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+
+	w.begin(number)
+	if entries != "" {
+		entries += " "
+	}
+	fmt.Fprintf(w, "<< %s/Filter /FlateDecode /Length %d >>\nstream\n", entries, compressed.Len())
+	w.Write(compressed.Bytes())
+	w.WriteString("\nendstream\nendobj\n")
+	return nil
+}
+
+func (w *objectWriter) begin(number int) {
+	if number != len(w.offsets)+1 {
+		panic(fmt.Sprintf("pdf: object %d written out of order", number))
+	}
+	w.offsets = append(w.offsets, w.Len())
+	fmt.Fprintf(w, "%d 0 obj\n", number)
+}
+
+// number formats a coordinate or size with at most two decimals.
+func number(value float64) string {
+	return strconv.FormatFloat(math.Round(value*100)/100, 'f', -1, 64)
+}
+
+// literal writes encoded text as a PDF string, escaping delimiters and
+// anything outside printable ASCII.
+func literal(text []byte) string {
+	var b strings.Builder
+	b.WriteByte('(')
+	for _, c := range text {
+		switch {
+		case c == '(' || c == ')' || c == '\\':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case c < 0x20 || c > 0x7E:
+			fmt.Fprintf(&b, "\\%03o", c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	b.WriteByte(')')
+	return b.String()
+}
@@ -0,0 +1,449 @@
+// Package printout lays out the portal's printable documents — invoices,
+// receipts, appointment letters and visit summaries — as branded PDFs. A
+// document is a title and a list of blocks; Render flows the blocks down
+// the page, breaking onto new pages as needed, under the clinic's header
+// and above a numbered footer.
+package printout
+
+import (
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"os"
+	"strings"
+
+	"healthcare-portal/internal/pdf"
+)
+
+// Branding is how the clinic presents itself on every page.
+type Branding struct {
+	ClinicName string
+	Address    string
+	Phone      string
+	Email      string
+	Website    string
+	// Accent colors the document title, headings and table headers.
+	Accent pdf.Color
+	// Logo is drawn at the top left of each page when set.
+	Logo image.Image
+	// Footer is a line of small print, e.g. a tax ID or a thank-you note.
+	Footer string
+}
+
+// LoadLogo reads a PNG or JPEG logo.
+func LoadLogo(path string) (image.Image, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	logo, _, err := image.Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return logo, nil
+}
+
+// Document is one printout. Reference lines are printed under the title,
+// e.g. the invoice number and issue date.
+type Document struct {
+	Title     string
+	Reference []string
+	Blocks    []Block
+}
+
+// Block is a piece of document content.
+type Block interface {
+	draw(l *layout)
+}
+
+// Heading starts a section.
+type Heading struct {
+	Text string
+}
+
+// Paragraph is wrapped text. Muted paragraphs are set smaller and in grey,
+// for notes and small print.
+type Paragraph struct {
+	Text  string
+	Muted bool
+}
+
+// Field is a labelled value.
+type Field struct {
+	Label string
+	Value string
+}
+
+// Fields lays out labelled values in a grid, two to a row unless Columns
+// says otherwise.
+type Fields struct {
+	Items   []Field
+	Columns int
+}
+
+type Align int
+
+const (
+	AlignLeft Align = iota
+	AlignRight
+)
+
+// Column describes a table column. Width is relative to the other columns;
+// zero counts as one.
+type Column struct {
+	Title string
+	Width float64
+	Align Align
+}
+
+// Table prints rows under a header that is repeated on every page the table
+// runs onto. Totals are printed right-aligned underneath, the last in bold.
+// Empty is shown in place of the rows when there are none.
+type Table struct {
+	Columns []Column
+	Rows    [][]string
+	Totals  []Field
+	Empty   string
+}
+
+// Spacer leaves a gap of Height points.
+type Spacer struct {
+	Height float64
+}
+
+// Page geometry and type sizes, in points.
+const (
+	margin       = 48.0
+	footerRule   = pdf.LetterHeight - 48
+	bodyBottom   = footerRule - 14
+	bodySize     = 10.0
+	bodyLeading  = 14.0
+	mutedSize    = 8.5
+	mutedLeading = 12.0
+	tableSize    = 9.0
+	tableLeading = 12.0
+	cellPadding  = 6.0
+	logoHeight   = 44.0
+	logoMaxWidth = 120.0
+)
+
+var (
+	textColor  = pdf.Color{R: 34, G: 34, B: 34}
+	mutedColor = pdf.Color{R: 110, G: 110, B: 110}
+	ruleColor  = pdf.Color{R: 210, G: 210, B: 210}
+	stripe     = pdf.Color{R: 245, G: 246, B: 248}
+)
+
+// Render lays the document out on US Letter pages.
+func Render(branding Branding, doc Document) ([]byte, error) {
+	out := pdf.New(pdf.LetterWidth, pdf.LetterHeight)
+	title := doc.Title
+	if branding.ClinicName != "" {
+		title += " - " + branding.ClinicName
+	}
+	out.SetTitle(title)
+
+	l := &layout{doc: out, branding: branding, document: doc}
+	if branding.Logo != nil {
+		l.logo = out.AddImage(branding.Logo)
+	}
+	l.newPage()
+	for _, block := range doc.Blocks {
+		block.draw(l)
+	}
+	l.drawFooters()
+
+	return out.Bytes()
+}
+
+// layout tracks where the next block goes.
+type layout struct {
+	doc      *pdf.Document
+	branding Branding
+	document Document
+	logo     *pdf.Image
+	page     *pdf.Page
+	bodyTop  float64
+	y        float64
+}
+
+func (l *layout) width() float64 {
+	return pdf.LetterWidth - 2*margin
+}
+
+// ensure starts a new page unless height more points fit on this one. It
+// reports whether it did.
+func (l *layout) ensure(height float64) bool {
+	if l.y+height <= bodyBottom || l.y <= l.bodyTop {
+		return false
+	}
+	l.newPage()
+	return true
+}
+
+// newPage adds a page with the clinic header and moves to the top of its
+// body.
+func (l *layout) newPage() {
+	l.page = l.doc.AddPage()
+	l.page.Rect(0, 0, pdf.LetterWidth, 6, l.branding.Accent)
+
+	left := margin
+	bottom := 0.0
+	if l.logo != nil {
+		pixelsWide, pixelsHigh := l.logo.Size()
+		width := logoHeight * float64(pixelsWide) / float64(pixelsHigh)
+		height := logoHeight
+		if width > logoMaxWidth {
+			width, height = logoMaxWidth, logoMaxWidth*float64(pixelsHigh)/float64(pixelsWide)
+		}
+		l.page.Image(l.logo, left, 26, width, height)
+		left += width + 12
+		bottom = 26 + height
+	}
+
+	y := 44.0
+	l.page.Text(left, y, pdf.HelveticaBold, 16, textColor, l.branding.ClinicName)
+	var contact []string
+	for _, part := range []string{l.branding.Phone, l.branding.Email, l.branding.Website} {
+		if part != "" {
+			contact = append(contact, part)
+		}
+	}
+	for _, line := range []string{l.branding.Address, strings.Join(contact, "  |  ")} {
+		if line != "" {
+			y += 12
+			l.page.Text(left, y, pdf.Helvetica, mutedSize, mutedColor, line)
+		}
+	}
+	bottom = max(bottom, y)
+
+	right := pdf.LetterWidth - margin
+	y = 46
+	l.rightText(right, y, pdf.HelveticaBold, 18, l.branding.Accent, l.document.Title)
+	for _, line := range l.document.Reference {
+		y += 12
+		l.rightText(right, y, pdf.Helvetica, 9, mutedColor, line)
+	}
+	bottom = max(bottom, y) + 12
+
+	l.page.Line(margin, bottom, right, bottom, 0.75, ruleColor)
+	l.bodyTop = bottom + 20
+	l.y = l.bodyTop
+}
+
+// drawFooters numbers the pages once their count is known.
+func (l *layout) drawFooters() {
+	pages := l.doc.Pages()
+	right := pdf.LetterWidth - margin
+	for i, page := range pages {
+		page.Line(margin, footerRule, right, footerRule, 0.5, ruleColor)
+		if l.branding.Footer != "" {
+			page.Text(margin, footerRule+14, pdf.Helvetica, 7.5, mutedColor, l.branding.Footer)
+		}
+		label := fmt.Sprintf("Page %d of %d", i+1, len(pages))
+		page.Text(right-pdf.TextWidth(pdf.Helvetica, 7.5, label), footerRule+14, pdf.Helvetica, 7.5, mutedColor, label)
+	}
+}
+
+func (l *layout) rightText(right, y float64, font pdf.Font, size float64, color pdf.Color, text string) {
+	l.page.Text(right-pdf.TextWidth(font, size, text), y, font, size, color, text)
+}
+
+func (b Heading) draw(l *layout) {
+	if l.y > l.bodyTop {
+		l.y += 8
+	}
+	// Keep the heading with at least a couple of lines of what follows.
+	l.ensure(22 + 2*bodyLeading)
+	l.page.Text(margin, l.y+12, pdf.HelveticaBold, 12, l.branding.Accent, b.Text)
+	l.page.Line(margin, l.y+17, margin+l.width(), l.y+17, 0.5, ruleColor)
+	l.y += 26
+}
+
+func (b Paragraph) draw(l *layout) {
+	size, leading, color := bodySize, bodyLeading, textColor
+	if b.Muted {
+		size, leading, color = mutedSize, mutedLeading, mutedColor
+	}
+	for _, line := range wrap(pdf.Helvetica, size, l.width(), b.Text) {
+		l.ensure(leading)
+		l.page.Text(margin, l.y+size, pdf.Helvetica, size, color, line)
+		l.y += leading
+	}
+	l.y += 6
+}
+
+func (b Fields) draw(l *layout) {
+	columns := b.Columns
+	if columns <= 0 {
+		columns = 2
+	}
+	columnWidth := l.width() / float64(columns)
+
+	for start := 0; start < len(b.Items); start += columns {
+		row := b.Items[start:min(start+columns, len(b.Items))]
+		values := make([][]string, len(row))
+		lines := 1
+		for i, field := range row {
+			value := field.Value
+			if value == "" {
+				value = "-"
+			}
+			values[i] = wrap(pdf.Helvetica, bodySize, columnWidth-12, value)
+			lines = max(lines, len(values[i]))
+		}
+		height := 12 + float64(lines)*bodyLeading
+
+		l.ensure(height)
+		for i, field := range row {
+			x := margin + float64(i)*columnWidth
+			l.page.Text(x, l.y+8, pdf.HelveticaBold, 7.5, mutedColor, strings.ToUpper(field.Label))
+			for j, line := range values[i] {
+				l.page.Text(x, l.y+22+float64(j)*bodyLeading, pdf.Helvetica, bodySize, textColor, line)
+			}
+		}
+		l.y += height + 6
+	}
+}
+
+func (b Table) draw(l *layout) {
+	total := 0.0
+	for _, column := range b.Columns {
+		total += columnWeight(column)
+	}
+	widths := make([]float64, len(b.Columns))
+	for i, column := range b.Columns {
+		widths[i] = l.width() * columnWeight(column) / total
+	}
+
+	header := func() {
+		l.page.Rect(margin, l.y, l.width(), 20, l.branding.Accent)
+		x := margin
+		for i, column := range b.Columns {
+			cellText(l, column.Align, x, widths[i], l.y+13.5, pdf.HelveticaBold, pdf.White, column.Title)
+			x += widths[i]
+		}
+		l.y += 20
+	}
+	l.ensure(20 + tableLeading + 8)
+	header()
+
+	if len(b.Rows) == 0 && b.Empty != "" {
+		l.page.Text(margin+cellPadding, l.y+tableSize+4, pdf.Helvetica, tableSize, mutedColor, b.Empty)
+		l.y += tableLeading + 8
+	}
+	for r, row := range b.Rows {
+		cells := make([][]string, len(b.Columns))
+		lines := 1
+		for i := range b.Columns {
+			if i < len(row) {
+				cells[i] = wrap(pdf.Helvetica, tableSize, widths[i]-2*cellPadding, row[i])
+			}
+			lines = max(lines, len(cells[i]))
+		}
+		height := float64(lines)*tableLeading + 8
+
+		if l.ensure(height) {
+			header()
+		}
+		if r%2 == 1 {
+			l.page.Rect(margin, l.y, l.width(), height, stripe)
+		}
+		x := margin
+		for i, column := range b.Columns {
+			for j, line := range cells[i] {
+				cellText(l, column.Align, x, widths[i], l.y+tableSize+4+float64(j)*tableLeading, pdf.Helvetica, textColor, line)
+			}
+			x += widths[i]
+		}
+		l.y += height
+	}
+	l.page.Line(margin, l.y, margin+l.width(), l.y, 0.5, ruleColor)
+	l.y += 8
+
+	right := margin + l.width() - cellPadding
+	for i, field := range b.Totals {
+		font := pdf.Helvetica
+		if i == len(b.Totals)-1 {
+			font = pdf.HelveticaBold
+		}
+		l.ensure(16)
+		l.rightText(right-110, l.y+bodySize, font, bodySize, textColor, field.Label)
+		l.rightText(right, l.y+bodySize, font, bodySize, textColor, field.Value)
+		l.y += 16
+	}
+	l.y += 8
+}
+
+func (b Spacer) draw(l *layout) {
+	l.y += b.Height
+}
+
+func cellText(l *layout, align Align, x, width, y float64, font pdf.Font, color pdf.Color, text string) {
+	if align == AlignRight {
+		l.rightText(x+width-cellPadding, y, font, tableSize, color, text)
+		return
+	}
+	l.page.Text(x+cellPadding, y, font, tableSize, color, text)
+}
+
+func columnWeight(column Column) float64 {
+	if column.Width <= 0 {
+		return 1
+	}
+	return column.Width
+}
+
+// wrap breaks text into lines no wider than width, keeping its own line
+// breaks. Words too long for a line are split.
+func wrap(font pdf.Font, size, width float64, text string) []string {
+	var lines []string
+	for _, paragraph := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		line := ""
+		for _, word := range strings.Fields(paragraph) {
+			candidate := word
+			if line != "" {
+				candidate = line + " " + word
+			}
+			if pdf.TextWidth(font, size, candidate) <= width {
+				line = candidate
+				continue
+			}
+			if line != "" {
+				lines = append(lines, line)
+			}
+			for pdf.TextWidth(font, size, word) > width {
+				cut := fitPrefix(font, size, width, word)
+				lines = append(lines, word[:cut])
+				word = word[cut:]
+			}
+			line = word
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// fitPrefix returns the byte length of the longest prefix of word that fits
+// in width, and at least one character.
+func fitPrefix(font pdf.Font, size, width float64, word string) int {
+	cut := 0
+	for i := range word {
+		if i > 0 && pdf.TextWidth(font, size, word[:i]) > width {
+			break
+		}
+		cut = i
+	}
+	if cut == 0 {
+		for i := range word {
+			if i > 0 {
+				return i
+			}
+		}
+		return len(word)
+	}
+	return cut
+}
@@ -0,0 +1,96 @@
+package query
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+	"time"
+)
+
+// cursor marks a position in a list: the sort values of the row a page
+// ended (or, paging backwards, started) at. It records the sort and
+// filters it was issued for, so it cannot be replayed against a different
+// list.
+type cursor struct {
+	Sort     string   `json:"s"`
+	Filters  string   `json:"f"`
+	Values   []string `json:"v"`
+	Backward bool     `json:"b,omitempty"`
+}
+
+// encodeCursor makes an opaque cursor at the row with the given sort values.
+func (p *plan) encodeCursor(values []interface{}, backward bool) string {
+	c := cursor{Sort: p.sort, Filters: p.fingerprint(), Values: make([]string, len(values)), Backward: backward}
+	for i, value := range values {
+		c.Values[i] = formatValue(value)
+	}
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeCursor reads a cursor issued for p's sort and filters.
+func decodeCursor(encoded string, p *plan) (*cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, invalid("malformed cursor")
+	}
+	var c cursor
+	if err := json.Unmarshal(data, &c); err != nil || len(c.Values) != len(p.order) {
+		return nil, invalid("malformed cursor")
+	}
+	if c.Sort != p.sort || c.Filters != p.fingerprint() {
+		return nil, invalid("cursor does not match the sort and filters of this request")
+	}
+	return &c, nil
+}
+
+// values parses the cursor's sort values into the types of the columns.
+func (c *cursor) values(p *plan) ([]interface{}, error) {
+	values := make([]interface{}, len(c.Values))
+	for i, raw := range c.Values {
+		switch p.order[i].Type {
+		case Integer:
+			number, err := strconv.ParseInt(raw, 10, 64)
+			if err != nil {
+				return nil, invalid("malformed cursor")
+			}
+			values[i] = number
+		case Time:
+			at, err := time.Parse(time.RFC3339Nano, raw)
+			if err != nil {
+				return nil, invalid("malformed cursor")
+			}
+			values[i] = at
+		default:
+			values[i] = raw
+		}
+	}
+	return values, nil
+}
+
+func (p *plan) fingerprint() string {
+	sum := sha256.Sum256([]byte(p.filterKey))
+	return hex.EncodeToString(sum[:8])
+}
+
+func formatValue(value interface{}) string {
+	switch v := value.(type) {
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case *time.Time:
+		if v == nil {
+			return ""
+		}
+		return v.Format(time.RFC3339Nano)
+	case string:
+		return v
+	default:
+		data, _ := json.Marshal(v)
+		if s, err := strconv.Unquote(string(data)); err == nil {
+			return s
+		}
+		return string(data)
+	}
+}
@@ -0,0 +1,135 @@
+package query
+
+import (
+	"fmt"
+	"reflect"
+
+	"gorm.io/gorm"
+)
+
+// Page is one page of a list, in the envelope every list endpoint returns.
+type Page[T any] struct {
+	Data  []T   `json:"data"`
+	Meta  Meta  `json:"meta"`
+	Links Links `json:"links"`
+}
+
+// Meta describes a page. Total counts every row matching the filters.
+// NextCursor and PrevCursor are set when there are rows after or before
+// the page.
+type Meta struct {
+	Total      int64  `json:"total"`
+	Limit      int    `json:"limit"`
+	Sort       string `json:"sort"`
+	Page       int    `json:"page,omitempty"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	PrevCursor string `json:"prev_cursor,omitempty"`
+}
+
+// Links are the URLs of this page and its neighbours. They are filled in
+// by the handler, which knows the request URL.
+type Links struct {
+	Self string `json:"self"`
+	Next string `json:"next,omitempty"`
+	Prev string `json:"prev,omitempty"`
+}
+
+// Run lists the rows of T selected by db that match req, checked against
+// spec. db carries whatever scoping the repository applies, such as the
+// tenant; preloads are loaded for the page's rows only.
+func Run[T any](db *gorm.DB, spec Spec, req Request, preloads ...string) (*Page[T], error) {
+	p, err := spec.build(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var model T
+	stmt := &gorm.Statement{DB: db, Context: db.Statement.Context}
+	if err := stmt.Parse(&model); err != nil {
+		return nil, err
+	}
+
+	filtered := db.Model(&model)
+	for _, c := range p.conditions {
+		filtered = filtered.Where(c.sql, c.args...)
+	}
+	filtered = filtered.Session(&gorm.Session{})
+
+	page := &Page[T]{Data: []T{}, Meta: Meta{Limit: p.limit, Sort: p.sort}}
+	if err := filtered.Count(&page.Meta.Total).Error; err != nil {
+		return nil, err
+	}
+
+	backward := p.cursor != nil && p.cursor.Backward
+	rowsQuery := filtered
+	if p.cursor != nil {
+		values, err := p.cursor.values(p)
+		if err != nil {
+			return nil, err
+		}
+		keyset := p.keysetCondition(values, backward)
+		rowsQuery = rowsQuery.Where(keyset.sql, keyset.args...)
+	}
+	for _, preload := range preloads {
+		rowsQuery = rowsQuery.Preload(preload)
+	}
+	rowsQuery = rowsQuery.Order(p.orderClause(backward)).Limit(p.limit + 1)
+	if p.page > 1 {
+		page.Meta.Page = p.page
+		rowsQuery = rowsQuery.Offset((p.page - 1) * p.limit)
+	}
+
+	var rows []T
+	if err := rowsQuery.Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	more := len(rows) > p.limit
+	if more {
+		rows = rows[:p.limit]
+	}
+	if backward {
+		for i, j := 0, len(rows)-1; i < j; i, j = i+1, j-1 {
+			rows[i], rows[j] = rows[j], rows[i]
+		}
+	}
+	if len(rows) == 0 {
+		return page, nil
+	}
+	page.Data = rows
+
+	// Paging backwards, the cursor's own row follows this page; paging
+	// forwards, it precedes it.
+	hasNext, hasPrev := more, p.cursor != nil || p.page > 1
+	if backward {
+		hasNext, hasPrev = true, more
+	}
+	if hasNext {
+		values, err := p.rowValues(stmt, &rows[len(rows)-1])
+		if err != nil {
+			return nil, err
+		}
+		page.Meta.NextCursor = p.encodeCursor(values, false)
+	}
+	if hasPrev {
+		values, err := p.rowValues(stmt, &rows[0])
+		if err != nil {
+			return nil, err
+		}
+		page.Meta.PrevCursor = p.encodeCursor(values, true)
+	}
+	return page, nil
+}
+
+// rowValues reads the sort columns of row.
+func (p *plan) rowValues(stmt *gorm.Statement, row interface{}) ([]interface{}, error) {
+	value := reflect.ValueOf(row).Elem()
+	values := make([]interface{}, len(p.order))
+	for i, field := range p.order {
+		schemaField := stmt.Schema.LookUpField(field.Column)
+		if schemaField == nil {
+			return nil, fmt.Errorf("sort column %q is not a field of %s", field.Column, stmt.Schema.Name)
+		}
+		values[i], _ = schemaField.ValueOf(stmt.Context, value)
+	}
+	return values, nil
+}
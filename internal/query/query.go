@@ -0,0 +1,325 @@
+// Package query is the shared list layer behind collection endpoints:
+// whitelisted filters, multi-field sorting and keyset pagination with
+// opaque cursors. Each repository describes what its list accepts in a
+// Spec; handlers turn the query string into a Request and render the
+// resulting Page with the same meta and links everywhere.
+package query
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidQuery wraps every problem with a list request, such as an
+// unknown filter or sort field or a malformed cursor.
+var ErrInvalidQuery = errors.New("invalid list query")
+
+func invalid(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s", ErrInvalidQuery, fmt.Sprintf(format, args...))
+}
+
+// Reserved query parameters; every other parameter is a filter.
+const (
+	ParamSort   = "sort"
+	ParamLimit  = "limit"
+	ParamCursor = "cursor"
+	ParamPage   = "page"
+)
+
+// Request is a list request as given in the query string, before it is
+// checked against a Spec.
+type Request struct {
+	// Filters holds every non-reserved parameter.
+	Filters url.Values
+	// Sort is a comma separated list of sort fields, each optionally
+	// prefixed with "-" for descending order, e.g. "-date,time".
+	Sort   string
+	Limit  int
+	Cursor string
+	// Page selects a page by offset for clients that predate cursors. It
+	// is ignored when a cursor is given.
+	Page int
+}
+
+// ParseRequest splits query string values into a Request.
+func ParseRequest(values url.Values) (Request, error) {
+	req := Request{Filters: url.Values{}}
+	for key, value := range values {
+		switch key {
+		case ParamSort:
+			req.Sort = strings.TrimSpace(values.Get(key))
+		case ParamCursor:
+			req.Cursor = strings.TrimSpace(values.Get(key))
+		case ParamLimit, ParamPage:
+			number, err := strconv.Atoi(values.Get(key))
+			if err != nil || number < 1 {
+				return req, invalid("%s must be a positive number", key)
+			}
+			if key == ParamLimit {
+				req.Limit = number
+			} else {
+				req.Page = number
+			}
+		default:
+			req.Filters[key] = value
+		}
+	}
+	return req, nil
+}
+
+type ColumnType int
+
+const (
+	String ColumnType = iota
+	Integer
+	Time
+)
+
+// Filter is a filter a list accepts. A plain filter takes one value or a
+// comma separated list of values, matched exactly. A Range filter on a
+// Time column is given as <name>_from and <name>_to dates (YYYY-MM-DD),
+// both inclusive.
+type Filter struct {
+	Column string
+	Type   ColumnType
+	// Allowed lists the accepted values of a String filter; any value is
+	// accepted when it is empty.
+	Allowed []string
+	// Fold compares String values case-insensitively.
+	Fold  bool
+	Range bool
+}
+
+// Sort is a field a list can be sorted by. Sort columns must be NOT NULL,
+// since keyset pagination compares their values.
+type Sort struct {
+	Column string
+	Type   ColumnType
+}
+
+// Spec describes the filters, sort fields and page sizes a list accepts.
+// Rows are always finally ordered by id, so every sort is total and
+// cursors are stable.
+type Spec struct {
+	Filters      map[string]Filter
+	Sorts        map[string]Sort
+	DefaultSort  string
+	DefaultLimit int
+	MaxLimit     int
+}
+
+// condition is a checked filter as SQL.
+type condition struct {
+	sql  string
+	args []interface{}
+}
+
+// orderField is a checked sort field.
+type orderField struct {
+	name string
+	Sort
+	desc bool
+}
+
+// plan is a Request checked against a Spec.
+type plan struct {
+	conditions []condition
+	filterKey  string
+	order      []orderField
+	sort       string
+	limit      int
+	page       int
+	cursor     *cursor
+}
+
+// build checks req against the spec.
+func (s Spec) build(req Request) (*plan, error) {
+	p := &plan{limit: req.Limit, page: req.Page}
+	if p.limit == 0 {
+		p.limit = s.DefaultLimit
+	}
+	if s.MaxLimit > 0 && p.limit > s.MaxLimit {
+		p.limit = s.MaxLimit
+	}
+
+	if err := s.buildFilters(p, req.Filters); err != nil {
+		return nil, err
+	}
+	if err := s.buildOrder(p, req.Sort); err != nil {
+		return nil, err
+	}
+	if req.Cursor != "" {
+		c, err := decodeCursor(req.Cursor, p)
+		if err != nil {
+			return nil, err
+		}
+		p.cursor = c
+		p.page = 0
+	}
+	return p, nil
+}
+
+func (s Spec) buildFilters(p *plan, values url.Values) error {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	applied := url.Values{}
+	for _, key := range keys {
+		value := strings.TrimSpace(values.Get(key))
+		if value == "" {
+			continue
+		}
+
+		name, bound := key, ""
+		filter, ok := s.Filters[key]
+		if !ok {
+			for _, suffix := range []string{"_from", "_to"} {
+				if strings.HasSuffix(key, suffix) {
+					name, bound = strings.TrimSuffix(key, suffix), suffix
+					filter, ok = s.Filters[name]
+					break
+				}
+			}
+		}
+		if !ok || filter.Range != (bound != "") {
+			return invalid("unknown filter %q", key)
+		}
+
+		c, err := filterCondition(filter, name, bound, value)
+		if err != nil {
+			return err
+		}
+		p.conditions = append(p.conditions, c)
+		applied.Set(key, value)
+	}
+	p.filterKey = applied.Encode()
+	return nil
+}
+
+func filterCondition(filter Filter, name, bound, value string) (condition, error) {
+	if filter.Range {
+		day, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			return condition{}, invalid("%s%s must be a date (YYYY-MM-DD)", name, bound)
+		}
+		if bound == "_from" {
+			return condition{sql: filter.Column + " >= ?", args: []interface{}{day}}, nil
+		}
+		return condition{sql: filter.Column + " < ?", args: []interface{}{day.AddDate(0, 0, 1)}}, nil
+	}
+
+	parts := strings.Split(value, ",")
+	args := make([]interface{}, 0, len(parts))
+	for _, part := range parts {
+		part = strings.TrimSpace(part)
+		switch filter.Type {
+		case Integer:
+			number, err := strconv.ParseInt(part, 10, 64)
+			if err != nil {
+				return condition{}, invalid("%s must be a number", name)
+			}
+			args = append(args, number)
+		default:
+			if filter.Fold {
+				part = strings.ToLower(part)
+			}
+			if len(filter.Allowed) > 0 && !contains(filter.Allowed, part) {
+				return condition{}, invalid("%s must be one of %s", name, strings.Join(filter.Allowed, ", "))
+			}
+			args = append(args, part)
+		}
+	}
+
+	column := filter.Column
+	if filter.Type == String && filter.Fold {
+		column = "LOWER(" + column + ")"
+	}
+	return condition{sql: column + " IN ?", args: []interface{}{args}}, nil
+}
+
+func (s Spec) buildOrder(p *plan, sortParam string) error {
+	if sortParam == "" {
+		sortParam = s.DefaultSort
+	}
+
+	seen := make(map[string]bool)
+	names := []string{}
+	for _, field := range strings.Split(sortParam, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		desc := strings.HasPrefix(field, "-")
+		name := strings.TrimPrefix(field, "-")
+		sortField, ok := s.Sorts[name]
+		if !ok {
+			return invalid("unknown sort field %q", name)
+		}
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		p.order = append(p.order, orderField{name: name, Sort: sortField, desc: desc})
+		names = append(names, field)
+	}
+	p.sort = strings.Join(names, ",")
+
+	if !seen["id"] {
+		p.order = append(p.order, orderField{name: "id", Sort: Sort{Column: "id", Type: Integer}})
+	}
+	return nil
+}
+
+// orderClause renders the sort, reversed when paging backwards.
+func (p *plan) orderClause(reverse bool) string {
+	parts := make([]string, len(p.order))
+	for i, field := range p.order {
+		desc := field.desc != reverse
+		direction := "ASC"
+		if desc {
+			direction = "DESC"
+		}
+		parts[i] = field.Column + " " + direction
+	}
+	return strings.Join(parts, ", ")
+}
+
+// keysetCondition selects the rows after the cursor's row in sort order, or
+// before it when reverse is set: rows whose sort values compare past the
+// cursor's on the first field that differs.
+func (p *plan) keysetCondition(values []interface{}, reverse bool) condition {
+	alternatives := []string{}
+	args := []interface{}{}
+	for i, field := range p.order {
+		parts := []string{}
+		for j := 0; j < i; j++ {
+			parts = append(parts, p.order[j].Column+" = ?")
+			args = append(args, values[j])
+		}
+		op := ">"
+		if field.desc != reverse {
+			op = "<"
+		}
+		parts = append(parts, field.Column+" "+op+" ?")
+		args = append(args, values[i])
+		alternatives = append(alternatives, "("+strings.Join(parts, " AND ")+")")
+	}
+	return condition{sql: "(" + strings.Join(alternatives, " OR ") + ")", args: args}
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
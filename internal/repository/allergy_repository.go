@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"healthcare-portal/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type AllergyRepository interface {
+	WithTenant(tenantID uint) AllergyRepository
+	Create(allergy *models.Allergy) error
+	FindByID(id uint) (*models.Allergy, error)
+	FindByPatientID(patientID uint) ([]models.Allergy, error)
+	Update(allergy *models.Allergy) error
+	Delete(id uint) error
+}
+
+type allergyRepository struct {
+	db *gorm.DB
+}
+
+func NewAllergyRepository(db *gorm.DB) AllergyRepository {
+	return &allergyRepository{db: requireTenant(db)}
+}
+
+// WithTenant returns a repository limited to tenantID's rows.
+func (r *allergyRepository) WithTenant(tenantID uint) AllergyRepository {
+	return &allergyRepository{db: scopeToTenant(r.db, tenantID)}
+}
+
+func (r *allergyRepository) Create(allergy *models.Allergy) error {
+	return r.db.Create(allergy).Error
+}
+
+func (r *allergyRepository) FindByID(id uint) (*models.Allergy, error) {
+	var allergy models.Allergy
+	err := r.db.First(&allergy, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &allergy, nil
+}
+
+func (r *allergyRepository) FindByPatientID(patientID uint) ([]models.Allergy, error) {
+	var allergies []models.Allergy
+	err := r.db.Where("patient_id = ?", patientID).
+		Order("substance ASC").
+		Find(&allergies).Error
+	return allergies, err
+}
+
+func (r *allergyRepository) Update(allergy *models.Allergy) error {
+	return r.db.Save(allergy).Error
+}
+
+func (r *allergyRepository) Delete(id uint) error {
+	return r.db.Delete(&models.Allergy{}, id).Error
+}
@@ -3,19 +3,28 @@ package repository
 import (
     "time"
     "healthcare-portal/internal/models"
+    "healthcare-portal/internal/query"
     "gorm.io/gorm"
 )
 
 type AppointmentRepository interface {
+    WithTenant(tenantID uint) AppointmentRepository
+    AllTenants() AppointmentRepository
     Create(appointment *models.Appointment) error
-    FindAll(limit, offset int) ([]models.Appointment, int64, error)
+    CreateWithResources(appointment *models.Appointment, resourceIDs []uint) error
+    List(req query.Request) (*query.Page[models.Appointment], error)
     FindByID(id uint) (*models.Appointment, error)
     FindByDate(date time.Time) ([]models.Appointment, error)
+    FindByDateAtLocation(date time.Time, locationID uint) ([]models.Appointment, error)
     FindByPatientID(patientID uint) ([]models.Appointment, error)
     FindByDoctorID(doctorID uint) ([]models.Appointment, error)
     Update(appointment *models.Appointment) error
     Delete(id uint) error
     UpdateStatus(id uint, status models.AppointmentStatus) error
+    FindDueForReminder(date time.Time) ([]models.Appointment, error)
+    MarkReminderSent(id uint, at time.Time) error
+    ReportByType(from, to time.Time) ([]models.AppointmentTypeReport, error)
+    Transaction(fn func(appointments AppointmentRepository, billing BillingRepository) error) error
 }
 
 type appointmentRepository struct {
@@ -23,33 +32,78 @@ type appointmentRepository struct {
 }
 
 func NewAppointmentRepository(db *gorm.DB) AppointmentRepository {
-    return &appointmentRepository{db: db}
+    return &appointmentRepository{db: requireTenant(db)}
+}
+
+// WithTenant returns a repository limited to tenantID's rows.
+func (r *appointmentRepository) WithTenant(tenantID uint) AppointmentRepository {
+    return &appointmentRepository{db: scopeToTenant(r.db, tenantID)}
+}
+
+// AllTenants returns a repository that reaches every tenant's rows. It is
+// only for AppointmentReminderWorker, which sweeps every practice.
+func (r *appointmentRepository) AllTenants() AppointmentRepository {
+    return &appointmentRepository{db: allTenants(r.db)}
 }
 
 func (r *appointmentRepository) Create(appointment *models.Appointment) error {
     return r.db.Create(appointment).Error
 }
 
-func (r *appointmentRepository) FindAll(limit, offset int) ([]models.Appointment, int64, error) {
-    var appointments []models.Appointment
-    var total int64
+// CreateWithResources stores the appointment and the resources it holds in a
+// single transaction so a booking never ends up half-allocated.
+func (r *appointmentRepository) CreateWithResources(appointment *models.Appointment, resourceIDs []uint) error {
+    return r.db.Transaction(func(tx *gorm.DB) error {
+        if err := tx.Create(appointment).Error; err != nil {
+            return err
+        }
+        for _, resourceID := range resourceIDs {
+            link := models.AppointmentResource{AppointmentID: appointment.ID, ResourceID: resourceID}
+            if err := tx.Create(&link).Error; err != nil {
+                return err
+            }
+            appointment.Resources = append(appointment.Resources, link)
+        }
+        return nil
+    })
+}
 
-    err := r.db.Model(&models.Appointment{}).Count(&total).Error
-    if err != nil {
-        return nil, 0, err
-    }
+// AppointmentListSpec is what the appointment list can be filtered and
+// sorted by. Filters taking IDs or statuses accept a comma separated list.
+var AppointmentListSpec = query.Spec{
+    Filters: map[string]query.Filter{
+        "status": {Column: "status", Type: query.String, Allowed: []string{
+            string(models.StatusScheduled), string(models.StatusCheckedIn),
+            string(models.StatusCompleted), string(models.StatusCancelled),
+        }},
+        "doctor_id":           {Column: "doctor_id", Type: query.Integer},
+        "patient_id":          {Column: "patient_id", Type: query.Integer},
+        "location_id":         {Column: "location_id", Type: query.Integer},
+        "appointment_type_id": {Column: "appointment_type_id", Type: query.Integer},
+        "date":                {Column: "date", Type: query.Time, Range: true},
+    },
+    Sorts: map[string]query.Sort{
+        "date":       {Column: "date", Type: query.Time},
+        "time":       {Column: "time", Type: query.String},
+        "status":     {Column: "status", Type: query.String},
+        "doctor_id":  {Column: "doctor_id", Type: query.Integer},
+        "created_at": {Column: "created_at", Type: query.Time},
+    },
+    DefaultSort:  "-date,-time",
+    DefaultLimit: 20,
+    MaxLimit:     100,
+}
 
-    err = r.db.Preload("Patient").Preload("Doctor").Preload("CreatedByUser").
-        Limit(limit).Offset(offset).
-        Order("date DESC, time DESC").
-        Find(&appointments).Error
-    
-    return appointments, total, err
+// List returns one page of appointments as described by req.
+func (r *appointmentRepository) List(req query.Request) (*query.Page[models.Appointment], error) {
+    return query.Run[models.Appointment](r.db, AppointmentListSpec, req,
+        "Patient", "Doctor", "CreatedByUser", "AppointmentType", "Location", "Resources.Resource")
 }
 
 func (r *appointmentRepository) FindByID(id uint) (*models.Appointment, error) {
     var appointment models.Appointment
     err := r.db.Preload("Patient").Preload("Doctor").Preload("CreatedByUser").
+        Preload("AppointmentType").Preload("Location").Preload("Resources.Resource").
         First(&appointment, id).Error
     if err != nil {
         return nil, err
@@ -63,15 +117,30 @@ func (r *appointmentRepository) FindByDate(date time.Time) ([]models.Appointment
     endOfDay := startOfDay.Add(24 * time.Hour)
     
     err := r.db.Preload("Patient").Preload("Doctor").Preload("CreatedByUser").
+        Preload("AppointmentType").Preload("Location").Preload("Resources.Resource").
         Where("date >= ? AND date < ?", startOfDay, endOfDay).
         Order("time ASC").
         Find(&appointments).Error
     return appointments, err
 }
 
+func (r *appointmentRepository) FindByDateAtLocation(date time.Time, locationID uint) ([]models.Appointment, error) {
+    var appointments []models.Appointment
+    startOfDay := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
+    endOfDay := startOfDay.Add(24 * time.Hour)
+
+    err := r.db.Preload("Patient").Preload("Doctor").Preload("CreatedByUser").
+        Preload("AppointmentType").Preload("Location").Preload("Resources.Resource").
+        Where("date >= ? AND date < ? AND location_id = ?", startOfDay, endOfDay, locationID).
+        Order("time ASC").
+        Find(&appointments).Error
+    return appointments, err
+}
+
 func (r *appointmentRepository) FindByPatientID(patientID uint) ([]models.Appointment, error) {
     var appointments []models.Appointment
     err := r.db.Preload("Patient").Preload("Doctor").Preload("CreatedByUser").
+        Preload("AppointmentType").Preload("Location").Preload("Resources.Resource").
         Where("patient_id = ?", patientID).
         Order("date DESC, time DESC").
         Find(&appointments).Error
@@ -81,6 +150,7 @@ func (r *appointmentRepository) FindByPatientID(patientID uint) ([]models.Appoin
 func (r *appointmentRepository) FindByDoctorID(doctorID uint) ([]models.Appointment, error) {
     var appointments []models.Appointment
     err := r.db.Preload("Patient").Preload("Doctor").Preload("CreatedByUser").
+        Preload("AppointmentType").Preload("Location").Preload("Resources.Resource").
         Where("doctor_id = ?", doctorID).
         Order("date DESC, time DESC").
         Find(&appointments).Error
@@ -95,6 +165,60 @@ func (r *appointmentRepository) Delete(id uint) error {
     return r.db.Delete(&models.Appointment{}, id).Error
 }
 
+// Transaction runs fn with appointment and billing repositories bound to one
+// database transaction, committed only when fn returns nil.
+func (r *appointmentRepository) Transaction(fn func(appointments AppointmentRepository, billing BillingRepository) error) error {
+    return r.db.Transaction(func(tx *gorm.DB) error {
+        return fn(&appointmentRepository{db: tx}, &billingRepository{db: tx})
+    })
+}
+
 func (r *appointmentRepository) UpdateStatus(id uint, status models.AppointmentStatus) error {
     return r.db.Model(&models.Appointment{}).Where("id = ?", id).Update("status", status).Error
+}
+
+// FindDueForReminder returns the scheduled appointments on date whose
+// patient has not been reminded yet.
+func (r *appointmentRepository) FindDueForReminder(date time.Time) ([]models.Appointment, error) {
+    var appointments []models.Appointment
+    startOfDay := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
+    endOfDay := startOfDay.Add(24 * time.Hour)
+
+    err := r.db.Preload("Patient").Preload("Location").
+        Where("date >= ? AND date < ? AND status = ? AND reminder_sent_at IS NULL", startOfDay, endOfDay, models.StatusScheduled).
+        Order("time ASC").
+        Find(&appointments).Error
+    return appointments, err
+}
+
+func (r *appointmentRepository) MarkReminderSent(id uint, at time.Time) error {
+    return r.db.Model(&models.Appointment{}).Where("id = ?", id).Update("reminder_sent_at", at).Error
+}
+
+// ReportByType counts appointments in [from, to) per appointment type and
+// status. Revenue is what completed visits were actually charged, leaving
+// out voided charges, so later price changes do not rewrite past revenue.
+func (r *appointmentRepository) ReportByType(from, to time.Time) ([]models.AppointmentTypeReport, error) {
+    charged := r.db.Model(&models.Charge{}).
+        Select("appointment_id, SUM(amount_cents) AS amount_cents").
+        Where("appointment_id IS NOT NULL AND status <> ?", models.ChargeVoided).
+        Group("appointment_id")
+
+    var rows []models.AppointmentTypeReport
+    err := r.db.Model(&models.Appointment{}).
+        Select(`appointments.appointment_type_id,
+            COALESCE(appointment_types.code, '') AS code,
+            COALESCE(appointment_types.name, '') AS name,
+            COUNT(*) AS total,
+            SUM(CASE WHEN appointments.status IN ('scheduled', 'checked_in') THEN 1 ELSE 0 END) AS scheduled,
+            SUM(CASE WHEN appointments.status = 'completed' THEN 1 ELSE 0 END) AS completed,
+            SUM(CASE WHEN appointments.status = 'cancelled' THEN 1 ELSE 0 END) AS cancelled,
+            SUM(CASE WHEN appointments.status = 'completed' THEN COALESCE(appointment_charges.amount_cents, 0) ELSE 0 END) AS revenue_cents`).
+        Joins("LEFT JOIN appointment_types ON appointment_types.id = appointments.appointment_type_id").
+        Joins("LEFT JOIN (?) AS appointment_charges ON appointment_charges.appointment_id = appointments.id", charged).
+        Where("appointments.date >= ? AND appointments.date < ?", from, to).
+        Group("appointments.appointment_type_id, appointment_types.code, appointment_types.name").
+        Order("total DESC").
+        Scan(&rows).Error
+    return rows, err
 }
\ No newline at end of file
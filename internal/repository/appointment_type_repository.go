@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"healthcare-portal/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type AppointmentTypeRepository interface {
+	WithTenant(tenantID uint) AppointmentTypeRepository
+	Create(appointmentType *models.AppointmentType) error
+	FindAll() ([]models.AppointmentType, error)
+	FindByID(id uint) (*models.AppointmentType, error)
+	Update(appointmentType *models.AppointmentType) error
+	ReplaceRequirements(appointmentTypeID uint, requirements []models.AppointmentTypeRequirement) error
+	ReplaceSpecialties(appointmentTypeID uint, specialties []string) error
+	Delete(id uint) error
+}
+
+type appointmentTypeRepository struct {
+	db *gorm.DB
+}
+
+func NewAppointmentTypeRepository(db *gorm.DB) AppointmentTypeRepository {
+	return &appointmentTypeRepository{db: requireTenant(db)}
+}
+
+// WithTenant returns a repository limited to tenantID's rows.
+func (r *appointmentTypeRepository) WithTenant(tenantID uint) AppointmentTypeRepository {
+	return &appointmentTypeRepository{db: scopeToTenant(r.db, tenantID)}
+}
+
+func (r *appointmentTypeRepository) Create(appointmentType *models.AppointmentType) error {
+	return r.db.Create(appointmentType).Error
+}
+
+func (r *appointmentTypeRepository) FindAll() ([]models.AppointmentType, error) {
+	var appointmentTypes []models.AppointmentType
+	err := r.db.Preload("Requirements").Preload("Specialties").Order("name ASC").Find(&appointmentTypes).Error
+	return appointmentTypes, err
+}
+
+func (r *appointmentTypeRepository) FindByID(id uint) (*models.AppointmentType, error) {
+	var appointmentType models.AppointmentType
+	err := r.db.Preload("Requirements").Preload("Specialties").First(&appointmentType, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &appointmentType, nil
+}
+
+func (r *appointmentTypeRepository) Update(appointmentType *models.AppointmentType) error {
+	return r.db.Omit("Requirements", "Specialties").Save(appointmentType).Error
+}
+
+func (r *appointmentTypeRepository) ReplaceRequirements(appointmentTypeID uint, requirements []models.AppointmentTypeRequirement) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("appointment_type_id = ?", appointmentTypeID).Delete(&models.AppointmentTypeRequirement{}).Error; err != nil {
+			return err
+		}
+		for i := range requirements {
+			requirements[i].ID = 0
+			requirements[i].AppointmentTypeID = appointmentTypeID
+			if err := tx.Create(&requirements[i]).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *appointmentTypeRepository) ReplaceSpecialties(appointmentTypeID uint, specialties []string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("appointment_type_id = ?", appointmentTypeID).Delete(&models.AppointmentTypeSpecialty{}).Error; err != nil {
+			return err
+		}
+		for _, specialty := range specialties {
+			if err := tx.Create(&models.AppointmentTypeSpecialty{AppointmentTypeID: appointmentTypeID, Specialty: specialty}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *appointmentTypeRepository) Delete(id uint) error {
+	return r.db.Delete(&models.AppointmentType{}, id).Error
+}
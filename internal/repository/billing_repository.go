@@ -0,0 +1,240 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+
+	"healthcare-portal/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrChargesUnavailable is returned when a charge being invoiced is no
+	// longer pending, for example because another invoice took it first.
+	ErrChargesUnavailable = errors.New("some charges are no longer pending")
+	ErrInvoiceVoid        = errors.New("invoice is void")
+	ErrInvoiceHasPayments = errors.New("refund the payments on an invoice before voiding it")
+	ErrPaymentExceedsOwed = errors.New("payment is more than the invoice balance")
+	ErrRefundExceedsPaid  = errors.New("refund is more than was paid on the invoice")
+)
+
+type BillingRepository interface {
+	WithTenant(tenantID uint) BillingRepository
+	CreateCharge(charge *models.Charge) error
+	CreateAppointmentCharge(charge *models.Charge) (bool, error)
+	FindChargeByID(id uint) (*models.Charge, error)
+	FindChargesByPatientID(patientID uint, status models.ChargeStatus) ([]models.Charge, error)
+	FindChargesByAppointmentID(appointmentID uint) ([]models.Charge, error)
+	UpdateCharge(charge *models.Charge) error
+	CreateInvoice(invoice *models.Invoice, chargeIDs []uint) error
+	FindInvoiceByID(id uint) (*models.Invoice, error)
+	FindInvoicesByPatientID(patientID uint) ([]models.Invoice, error)
+	VoidInvoice(id uint, reason string) error
+	CreatePayment(payment *models.Payment) error
+	PatientBalance(patientID uint) (*models.PatientBalance, error)
+}
+
+type billingRepository struct {
+	db *gorm.DB
+}
+
+func NewBillingRepository(db *gorm.DB) BillingRepository {
+	return &billingRepository{db: requireTenant(db)}
+}
+
+// WithTenant returns a repository limited to tenantID's rows.
+func (r *billingRepository) WithTenant(tenantID uint) BillingRepository {
+	return &billingRepository{db: scopeToTenant(r.db, tenantID)}
+}
+
+func (r *billingRepository) CreateCharge(charge *models.Charge) error {
+	return r.db.Create(charge).Error
+}
+
+// CreateAppointmentCharge saves the charge for an appointment unless the
+// appointment already has one that is not voided, and reports whether it
+// was saved.
+func (r *billingRepository) CreateAppointmentCharge(charge *models.Charge) (bool, error) {
+	result := r.db.Clauses(clause.OnConflict{
+		Columns:     []clause.Column{{Name: "appointment_id"}},
+		TargetWhere: clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "status <> 'voided'"}}},
+		DoNothing:   true,
+	}).Create(charge)
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *billingRepository) FindChargeByID(id uint) (*models.Charge, error) {
+	var charge models.Charge
+	err := r.db.First(&charge, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &charge, nil
+}
+
+// FindChargesByPatientID returns the patient's charges, oldest service
+// first. An empty status returns charges in every status.
+func (r *billingRepository) FindChargesByPatientID(patientID uint, status models.ChargeStatus) ([]models.Charge, error) {
+	var charges []models.Charge
+	query := r.db.Where("patient_id = ?", patientID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	err := query.Order("service_date ASC, id ASC").Find(&charges).Error
+	return charges, err
+}
+
+func (r *billingRepository) FindChargesByAppointmentID(appointmentID uint) ([]models.Charge, error) {
+	var charges []models.Charge
+	err := r.db.Where("appointment_id = ?", appointmentID).Order("id ASC").Find(&charges).Error
+	return charges, err
+}
+
+func (r *billingRepository) UpdateCharge(charge *models.Charge) error {
+	return r.db.Save(charge).Error
+}
+
+// CreateInvoice numbers and saves the invoice and moves the charges onto
+// it, failing with ErrChargesUnavailable unless every one is still pending.
+func (r *billingRepository) CreateInvoice(invoice *models.Invoice, chargeIDs []uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		sequence, err := nextNumber(tx, "invoice")
+		if err != nil {
+			return err
+		}
+		invoice.Sequence = sequence
+		invoice.Number = fmt.Sprintf("INV-%06d", invoice.Sequence)
+		if err := tx.Omit("Charges", "Payments").Create(invoice).Error; err != nil {
+			return err
+		}
+
+		result := tx.Model(&models.Charge{}).
+			Where("id IN ? AND status = ? AND patient_id = ?", chargeIDs, models.ChargePending, invoice.PatientID).
+			Updates(map[string]interface{}{"invoice_id": invoice.ID, "status": models.ChargeInvoiced})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected != int64(len(chargeIDs)) {
+			return ErrChargesUnavailable
+		}
+		return nil
+	})
+}
+
+// FindInvoiceByID returns the invoice with its charges and payments.
+func (r *billingRepository) FindInvoiceByID(id uint) (*models.Invoice, error) {
+	var invoice models.Invoice
+	err := r.db.Preload("Charges", func(db *gorm.DB) *gorm.DB { return db.Order("service_date ASC, id ASC") }).
+		Preload("Payments", func(db *gorm.DB) *gorm.DB { return db.Order("received_at ASC, id ASC") }).
+		First(&invoice, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &invoice, nil
+}
+
+// FindInvoicesByPatientID returns the patient's invoices, newest first.
+func (r *billingRepository) FindInvoicesByPatientID(patientID uint) ([]models.Invoice, error) {
+	var invoices []models.Invoice
+	err := r.db.Where("patient_id = ?", patientID).Order("issued_at DESC, id DESC").Find(&invoices).Error
+	return invoices, err
+}
+
+// VoidInvoice voids the invoice and returns its charges to pending so they
+// can be invoiced again. The invoice is only voided while nothing is paid on
+// it, checked in the same statement that voids it so a payment recorded
+// meanwhile cannot be lost.
+func (r *billingRepository) VoidInvoice(id uint, reason string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Invoice{}).
+			Where("id = ? AND status <> ? AND paid_cents = 0", id, models.InvoiceVoid).
+			Updates(map[string]interface{}{"status": models.InvoiceVoid, "void_reason": reason, "balance_cents": 0})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			var invoice models.Invoice
+			if err := tx.First(&invoice, id).Error; err != nil {
+				return err
+			}
+			if invoice.Status == models.InvoiceVoid {
+				return ErrInvoiceVoid
+			}
+			return ErrInvoiceHasPayments
+		}
+		return tx.Model(&models.Charge{}).Where("invoice_id = ?", id).
+			Updates(map[string]interface{}{"invoice_id": nil, "status": models.ChargePending}).Error
+	})
+}
+
+// CreatePayment saves the payment and moves the invoice's paid amount,
+// balance and status by it. The totals are adjusted in the database with
+// the limits in the WHERE clause, rather than read, changed and written
+// back, so concurrent payments can never take more than the balance or
+// refund more than was paid.
+func (r *billingRepository) CreatePayment(payment *models.Payment) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		delta, limit := payment.AmountCents, "balance_cents >= ?"
+		if payment.Kind == models.PaymentRefund {
+			delta, limit = -payment.AmountCents, "paid_cents >= ?"
+		}
+		result := tx.Model(&models.Invoice{}).
+			Where("id = ? AND status <> ?", payment.InvoiceID, models.InvoiceVoid).
+			Where(limit, payment.AmountCents).
+			Updates(map[string]interface{}{
+				"paid_cents":    gorm.Expr("paid_cents + ?", delta),
+				"balance_cents": gorm.Expr("balance_cents - ?", delta),
+				"status":        gorm.Expr("CASE WHEN balance_cents - ? = 0 THEN ? ELSE ? END", delta, models.InvoicePaid, models.InvoiceOpen),
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			var invoice models.Invoice
+			if err := tx.First(&invoice, payment.InvoiceID).Error; err != nil {
+				return err
+			}
+			switch {
+			case invoice.Status == models.InvoiceVoid:
+				return ErrInvoiceVoid
+			case payment.Kind == models.PaymentRefund:
+				return ErrRefundExceedsPaid
+			default:
+				return ErrPaymentExceedsOwed
+			}
+		}
+		return tx.Create(payment).Error
+	})
+}
+
+func (r *billingRepository) PatientBalance(patientID uint) (*models.PatientBalance, error) {
+	balance := &models.PatientBalance{PatientID: patientID}
+	var invoiced struct {
+		Invoiced int64
+		Paid     int64
+		Balance  int64
+	}
+	err := r.db.Model(&models.Invoice{}).
+		Select("COALESCE(SUM(total_cents), 0) AS invoiced, COALESCE(SUM(paid_cents), 0) AS paid, COALESCE(SUM(balance_cents), 0) AS balance").
+		Where("patient_id = ? AND status <> ?", patientID, models.InvoiceVoid).
+		Scan(&invoiced).Error
+	if err != nil {
+		return nil, err
+	}
+	balance.InvoicedCents, balance.PaidCents, balance.BalanceCents = invoiced.Invoiced, invoiced.Paid, invoiced.Balance
+
+	err = r.db.Model(&models.Invoice{}).Where("patient_id = ? AND status = ?", patientID, models.InvoiceOpen).
+		Count(&balance.OpenInvoices).Error
+	if err != nil {
+		return nil, err
+	}
+	err = r.db.Model(&models.Charge{}).Select("COALESCE(SUM(amount_cents), 0)").
+		Where("patient_id = ? AND status = ?", patientID, models.ChargePending).
+		Scan(&balance.UnbilledCents).Error
+	if err != nil {
+		return nil, err
+	}
+	return balance, nil
+}
@@ -0,0 +1,161 @@
+package repository
+
+import (
+	"time"
+
+	"healthcare-portal/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type CarePlanRepository interface {
+	WithTenant(tenantID uint) CarePlanRepository
+	AllTenants() CarePlanRepository
+	Create(plan *models.CarePlan) error
+	FindByID(id uint) (*models.CarePlan, error)
+	FindByPatientID(patientID uint, status models.CarePlanStatus) ([]models.CarePlan, error)
+	Update(plan *models.CarePlan) error
+	CreateGoal(goal *models.CarePlanGoal) error
+	UpdateGoal(goal *models.CarePlanGoal) error
+	CreateTask(task *models.CareTask) error
+	FindTaskByID(id uint) (*models.CareTask, error)
+	FindTasks(filter models.CareTaskFilter) ([]models.CareTask, error)
+	UpdateTask(task *models.CareTask) error
+	MarkOverdue(asOf time.Time) ([]models.CareTask, error)
+}
+
+type carePlanRepository struct {
+	db *gorm.DB
+}
+
+func NewCarePlanRepository(db *gorm.DB) CarePlanRepository {
+	return &carePlanRepository{db: requireTenant(db)}
+}
+
+// WithTenant returns a repository limited to tenantID's rows.
+func (r *carePlanRepository) WithTenant(tenantID uint) CarePlanRepository {
+	return &carePlanRepository{db: scopeToTenant(r.db, tenantID)}
+}
+
+// AllTenants returns a repository that reaches every tenant's rows. It is
+// only for CareTaskWorker, which sweeps every practice.
+func (r *carePlanRepository) AllTenants() CarePlanRepository {
+	return &carePlanRepository{db: allTenants(r.db)}
+}
+
+// Create stores the plan with its goals and tasks in one transaction. Tasks
+// carry their own tenant, so they are stamped with the plan's.
+func (r *carePlanRepository) Create(plan *models.CarePlan) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		tasks := plan.Tasks
+		if err := tx.Omit("Tasks").Create(plan).Error; err != nil {
+			return err
+		}
+		for i := range tasks {
+			tasks[i].CarePlanID = plan.ID
+			tasks[i].TenantID = plan.TenantID
+			if err := tx.Omit("Patient", "AssignedToUser", "Appointment").Create(&tasks[i]).Error; err != nil {
+				return err
+			}
+		}
+		plan.Tasks = tasks
+		return nil
+	})
+}
+
+func (r *carePlanRepository) FindByID(id uint) (*models.CarePlan, error) {
+	var plan models.CarePlan
+	err := r.db.Preload("Goals", func(db *gorm.DB) *gorm.DB { return db.Order("id ASC") }).
+		Preload("Tasks", func(db *gorm.DB) *gorm.DB { return db.Order("due_date ASC, id ASC") }).
+		Preload("Tasks.AssignedToUser").
+		First(&plan, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &plan, nil
+}
+
+// FindByPatientID returns the patient's plans, newest first. An empty status
+// returns every status.
+func (r *carePlanRepository) FindByPatientID(patientID uint, status models.CarePlanStatus) ([]models.CarePlan, error) {
+	var plans []models.CarePlan
+	query := r.db.Preload("Goals", func(db *gorm.DB) *gorm.DB { return db.Order("id ASC") }).
+		Preload("Tasks", func(db *gorm.DB) *gorm.DB { return db.Order("due_date ASC, id ASC") }).
+		Where("patient_id = ?", patientID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	err := query.Order("created_at DESC").Find(&plans).Error
+	return plans, err
+}
+
+func (r *carePlanRepository) Update(plan *models.CarePlan) error {
+	return r.db.Omit("Goals", "Tasks").Save(plan).Error
+}
+
+func (r *carePlanRepository) CreateGoal(goal *models.CarePlanGoal) error {
+	return r.db.Create(goal).Error
+}
+
+func (r *carePlanRepository) UpdateGoal(goal *models.CarePlanGoal) error {
+	return r.db.Save(goal).Error
+}
+
+func (r *carePlanRepository) CreateTask(task *models.CareTask) error {
+	return r.db.Omit("Patient", "AssignedToUser", "Appointment").Create(task).Error
+}
+
+func (r *carePlanRepository) FindTaskByID(id uint) (*models.CareTask, error) {
+	var task models.CareTask
+	err := r.db.Preload("Patient").Preload("AssignedToUser").Preload("Appointment").First(&task, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+// FindTasks returns the tasks matching filter, soonest due first.
+func (r *carePlanRepository) FindTasks(filter models.CareTaskFilter) ([]models.CareTask, error) {
+	var tasks []models.CareTask
+	query := r.db.Preload("Patient").Preload("AssignedToUser")
+	if filter.AssignedTo != nil {
+		query = query.Where("assigned_to = ?", *filter.AssignedTo)
+	}
+	if filter.PatientID != nil {
+		query = query.Where("patient_id = ?", *filter.PatientID)
+	}
+	if len(filter.Statuses) > 0 {
+		query = query.Where("status IN ?", filter.Statuses)
+	}
+	if filter.DueBefore != nil {
+		query = query.Where("due_date < ?", *filter.DueBefore)
+	}
+	err := query.Order("due_date ASC, id ASC").Find(&tasks).Error
+	return tasks, err
+}
+
+func (r *carePlanRepository) UpdateTask(task *models.CareTask) error {
+	return r.db.Omit("Patient", "AssignedToUser", "Appointment").Save(task).Error
+}
+
+// MarkOverdue moves pending tasks due before asOf to overdue and returns
+// them.
+func (r *carePlanRepository) MarkOverdue(asOf time.Time) ([]models.CareTask, error) {
+	var tasks []models.CareTask
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("status = ? AND due_date < ?", models.CareTaskPending, asOf).
+			Order("due_date ASC").Find(&tasks).Error; err != nil {
+			return err
+		}
+		if len(tasks) == 0 {
+			return nil
+		}
+		ids := make([]uint, len(tasks))
+		for i := range tasks {
+			ids[i] = tasks[i].ID
+			tasks[i].Status = models.CareTaskOverdue
+		}
+		return tx.Model(&models.CareTask{}).Where("id IN ?", ids).Update("status", models.CareTaskOverdue).Error
+	})
+	return tasks, err
+}
@@ -0,0 +1,232 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+
+	"healthcare-portal/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrClaimsUnavailable is returned when a claim being batched is no longer
+// ready, for example because another batch took it first.
+var ErrClaimsUnavailable = errors.New("some claims are no longer ready to submit")
+
+type ClaimRepository interface {
+	WithTenant(tenantID uint) ClaimRepository
+	SaveClaim(claim *models.Claim) error
+	UpdateClaim(claim *models.Claim) error
+	FindClaimByID(id uint) (*models.Claim, error)
+	FindClaimByAppointmentID(appointmentID uint) (*models.Claim, error)
+	FindClaimByControlNumber(controlNumber string) (*models.Claim, error)
+	FindClaims(status models.ClaimStatus) ([]models.Claim, error)
+	FindClaimsByPatientID(patientID uint) ([]models.Claim, error)
+	CreateBatch(batch *models.ClaimBatch, claimIDs []uint, render func(batch *models.ClaimBatch) error) error
+	FindBatchByID(id uint) (*models.ClaimBatch, error)
+	FindBatches() ([]models.ClaimBatch, error)
+	CreateRemittance(remittance *models.Remittance) error
+	CreateRemittanceClaim(remittanceClaim *models.RemittanceClaim) error
+	FindRemittanceByID(id uint) (*models.Remittance, error)
+	FindRemittanceByTraceNumber(traceNumber string) (*models.Remittance, error)
+	FindRemittances() ([]models.Remittance, error)
+	Transaction(fn func(claims ClaimRepository, billing BillingRepository) error) error
+}
+
+type claimRepository struct {
+	db *gorm.DB
+}
+
+func NewClaimRepository(db *gorm.DB) ClaimRepository {
+	return &claimRepository{db: requireTenant(db)}
+}
+
+// WithTenant returns a repository limited to tenantID's rows.
+func (r *claimRepository) WithTenant(tenantID uint) ClaimRepository {
+	return &claimRepository{db: scopeToTenant(r.db, tenantID)}
+}
+
+// SaveClaim creates a numbered claim, or replaces an existing claim together
+// with its lines and diagnoses.
+func (r *claimRepository) SaveClaim(claim *models.Claim) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if claim.ID == 0 {
+			sequence, err := nextNumber(tx, "claim")
+			if err != nil {
+				return err
+			}
+			claim.Sequence = sequence
+			claim.ControlNumber = fmt.Sprintf("CLM-%06d", claim.Sequence)
+		} else {
+			if err := tx.Where("claim_id = ?", claim.ID).Delete(&models.ClaimLine{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Where("claim_id = ?", claim.ID).Delete(&models.ClaimDiagnosis{}).Error; err != nil {
+				return err
+			}
+			for i := range claim.Lines {
+				claim.Lines[i].ID = 0
+			}
+			for i := range claim.Diagnoses {
+				claim.Diagnoses[i].ID = 0
+			}
+		}
+		return tx.Omit("Coverage", "Patient").Save(claim).Error
+	})
+}
+
+// UpdateClaim saves the claim and the amounts on its lines.
+func (r *claimRepository) UpdateClaim(claim *models.Claim) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Save(claim).Error; err != nil {
+			return err
+		}
+		for i := range claim.Lines {
+			if err := tx.Save(&claim.Lines[i]).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// FindClaimByID returns the claim with its lines, diagnoses, patient and
+// coverage.
+func (r *claimRepository) FindClaimByID(id uint) (*models.Claim, error) {
+	var claim models.Claim
+	err := r.withDetails(r.db).First(&claim, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &claim, nil
+}
+
+// FindClaimByAppointmentID returns the latest claim for the appointment.
+func (r *claimRepository) FindClaimByAppointmentID(appointmentID uint) (*models.Claim, error) {
+	var claim models.Claim
+	err := r.withDetails(r.db).Where("appointment_id = ?", appointmentID).Order("id DESC").First(&claim).Error
+	if err != nil {
+		return nil, err
+	}
+	return &claim, nil
+}
+
+func (r *claimRepository) FindClaimByControlNumber(controlNumber string) (*models.Claim, error) {
+	var claim models.Claim
+	err := r.withDetails(r.db).Where("control_number = ?", controlNumber).First(&claim).Error
+	if err != nil {
+		return nil, err
+	}
+	return &claim, nil
+}
+
+// FindClaims returns claims newest first. An empty status returns claims in
+// every status.
+func (r *claimRepository) FindClaims(status models.ClaimStatus) ([]models.Claim, error) {
+	var claims []models.Claim
+	query := r.db
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	err := query.Order("id DESC").Find(&claims).Error
+	return claims, err
+}
+
+func (r *claimRepository) FindClaimsByPatientID(patientID uint) ([]models.Claim, error) {
+	var claims []models.Claim
+	err := r.db.Where("patient_id = ?", patientID).Order("id DESC").Find(&claims).Error
+	return claims, err
+}
+
+// CreateBatch saves the batch, lets render fill in its file now that the
+// batch has an ID, and marks the claims submitted. It fails with
+// ErrClaimsUnavailable unless every claim is still ready.
+func (r *claimRepository) CreateBatch(batch *models.ClaimBatch, claimIDs []uint, render func(batch *models.ClaimBatch) error) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Claims").Create(batch).Error; err != nil {
+			return err
+		}
+		if err := render(batch); err != nil {
+			return err
+		}
+		if err := tx.Omit("Claims").Save(batch).Error; err != nil {
+			return err
+		}
+
+		result := tx.Model(&models.Claim{}).
+			Where("id IN ? AND status = ?", claimIDs, models.ClaimReady).
+			Updates(map[string]interface{}{"batch_id": batch.ID, "status": models.ClaimSubmitted, "submitted_at": batch.CreatedAt})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected != int64(len(claimIDs)) {
+			return ErrClaimsUnavailable
+		}
+		return nil
+	})
+}
+
+// FindBatchByID returns the batch with its claims.
+func (r *claimRepository) FindBatchByID(id uint) (*models.ClaimBatch, error) {
+	var batch models.ClaimBatch
+	err := r.db.Preload("Claims", func(db *gorm.DB) *gorm.DB { return db.Order("id ASC") }).First(&batch, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &batch, nil
+}
+
+func (r *claimRepository) FindBatches() ([]models.ClaimBatch, error) {
+	var batches []models.ClaimBatch
+	err := r.db.Order("id DESC").Find(&batches).Error
+	return batches, err
+}
+
+func (r *claimRepository) CreateRemittance(remittance *models.Remittance) error {
+	return r.db.Omit("Claims").Create(remittance).Error
+}
+
+func (r *claimRepository) CreateRemittanceClaim(remittanceClaim *models.RemittanceClaim) error {
+	return r.db.Create(remittanceClaim).Error
+}
+
+// FindRemittanceByID returns the remittance with its claims.
+func (r *claimRepository) FindRemittanceByID(id uint) (*models.Remittance, error) {
+	var remittance models.Remittance
+	err := r.db.Preload("Claims", func(db *gorm.DB) *gorm.DB { return db.Order("id ASC") }).First(&remittance, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &remittance, nil
+}
+
+func (r *claimRepository) FindRemittanceByTraceNumber(traceNumber string) (*models.Remittance, error) {
+	var remittance models.Remittance
+	err := r.db.Where("trace_number = ?", traceNumber).First(&remittance).Error
+	if err != nil {
+		return nil, err
+	}
+	return &remittance, nil
+}
+
+func (r *claimRepository) FindRemittances() ([]models.Remittance, error) {
+	var remittances []models.Remittance
+	err := r.db.Order("id DESC").Find(&remittances).Error
+	return remittances, err
+}
+
+// Transaction runs fn with claim and billing repositories bound to one
+// database transaction, committed only when fn returns nil.
+func (r *claimRepository) Transaction(fn func(claims ClaimRepository, billing BillingRepository) error) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return fn(&claimRepository{db: tx}, &billingRepository{db: tx})
+	})
+}
+
+func (r *claimRepository) withDetails(db *gorm.DB) *gorm.DB {
+	return db.Preload("Lines", func(db *gorm.DB) *gorm.DB { return db.Order("line_number ASC") }).
+		Preload("Diagnoses", func(db *gorm.DB) *gorm.DB { return db.Order("position ASC") }).
+		Preload("Coverage.Plan.Payer").
+		Preload("Patient")
+}
@@ -0,0 +1,154 @@
+package repository
+
+import (
+	"time"
+
+	"healthcare-portal/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type ConsentRepository interface {
+	WithTenant(tenantID uint) ConsentRepository
+	AllTenants() ConsentRepository
+	CreateText(text *models.ConsentText) error
+	FindTextByID(id uint) (*models.ConsentText, error)
+	FindTexts(consentType models.ConsentType) ([]models.ConsentText, error)
+	FindCurrentText(consentType models.ConsentType, at time.Time) (*models.ConsentText, error)
+	Grant(consent *models.Consent) error
+	FindByID(id uint) (*models.Consent, error)
+	FindByPatientID(patientID uint, activeOnly bool) ([]models.Consent, error)
+	FindActive(patientID uint, consentType models.ConsentType) (*models.Consent, error)
+	FindConsentedPatientIDs(consentType models.ConsentType, minVersion int) ([]uint, error)
+	Update(consent *models.Consent) error
+}
+
+type consentRepository struct {
+	db *gorm.DB
+}
+
+func NewConsentRepository(db *gorm.DB) ConsentRepository {
+	return &consentRepository{db: requireTenant(db)}
+}
+
+// WithTenant returns a repository limited to tenantID's rows.
+func (r *consentRepository) WithTenant(tenantID uint) ConsentRepository {
+	return &consentRepository{db: scopeToTenant(r.db, tenantID)}
+}
+
+// AllTenants returns a repository that reaches every tenant's rows. It is
+// only for AppointmentReminderWorker, which sweeps every practice.
+func (r *consentRepository) AllTenants() ConsentRepository {
+	return &consentRepository{db: allTenants(r.db)}
+}
+
+// CreateText stores text as the next version of its consent type.
+func (r *consentRepository) CreateText(text *models.ConsentText) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var latest int
+		err := tx.Model(&models.ConsentText{}).Where("type = ?", text.Type).
+			Select("COALESCE(MAX(version), 0)").Scan(&latest).Error
+		if err != nil {
+			return err
+		}
+		text.Version = latest + 1
+		return tx.Create(text).Error
+	})
+}
+
+func (r *consentRepository) FindTextByID(id uint) (*models.ConsentText, error) {
+	var text models.ConsentText
+	err := r.db.First(&text, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &text, nil
+}
+
+// FindTexts returns every version of the consent type's text, newest first.
+// An empty type returns the texts of all types.
+func (r *consentRepository) FindTexts(consentType models.ConsentType) ([]models.ConsentText, error) {
+	var texts []models.ConsentText
+	query := r.db.Order("type ASC, version DESC")
+	if consentType != "" {
+		query = query.Where("type = ?", consentType)
+	}
+	err := query.Find(&texts).Error
+	return texts, err
+}
+
+// FindCurrentText returns the newest version of the consent type's text in
+// effect at the given time.
+func (r *consentRepository) FindCurrentText(consentType models.ConsentType, at time.Time) (*models.ConsentText, error) {
+	var text models.ConsentText
+	err := r.db.Where("type = ? AND effective_from <= ?", consentType, at).
+		Order("version DESC").First(&text).Error
+	if err != nil {
+		return nil, err
+	}
+	return &text, nil
+}
+
+// Grant records consent and, in the same transaction, closes the patient's
+// previous active consent of that type.
+func (r *consentRepository) Grant(consent *models.Consent) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&models.Consent{}).
+			Where("patient_id = ? AND type = ? AND status = ?", consent.PatientID, consent.Type, models.ConsentGranted).
+			Updates(map[string]interface{}{
+				"status":            models.ConsentRevoked,
+				"revoked_at":        consent.GrantedAt,
+				"revocation_reason": "superseded",
+				"revoked_by":        consent.RecordedBy,
+			}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Create(consent).Error
+	})
+}
+
+func (r *consentRepository) FindByID(id uint) (*models.Consent, error) {
+	var consent models.Consent
+	err := r.db.Preload("ConsentText").First(&consent, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &consent, nil
+}
+
+// FindByPatientID returns the patient's consents, newest first.
+func (r *consentRepository) FindByPatientID(patientID uint, activeOnly bool) ([]models.Consent, error) {
+	var consents []models.Consent
+	query := r.db.Where("patient_id = ?", patientID)
+	if activeOnly {
+		query = query.Where("status = ?", models.ConsentGranted)
+	}
+	err := query.Order("granted_at DESC, id DESC").Find(&consents).Error
+	return consents, err
+}
+
+// FindActive returns the patient's granted consent of the given type.
+func (r *consentRepository) FindActive(patientID uint, consentType models.ConsentType) (*models.Consent, error) {
+	var consent models.Consent
+	err := r.db.Where("patient_id = ? AND type = ? AND status = ?", patientID, consentType, models.ConsentGranted).
+		Order("granted_at DESC, id DESC").First(&consent).Error
+	if err != nil {
+		return nil, err
+	}
+	return &consent, nil
+}
+
+// FindConsentedPatientIDs returns the patients with a granted consent of the
+// given type to text version minVersion or later.
+func (r *consentRepository) FindConsentedPatientIDs(consentType models.ConsentType, minVersion int) ([]uint, error) {
+	var patientIDs []uint
+	err := r.db.Model(&models.Consent{}).
+		Where("type = ? AND status = ? AND text_version >= ?", consentType, models.ConsentGranted, minVersion).
+		Distinct().Order("patient_id ASC").Pluck("patient_id", &patientIDs).Error
+	return patientIDs, err
+}
+
+func (r *consentRepository) Update(consent *models.Consent) error {
+	return r.db.Omit("ConsentText").Save(consent).Error
+}
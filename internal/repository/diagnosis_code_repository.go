@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"strings"
+
+	"healthcare-portal/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// DiagnosisCodeRepository reads and loads the ICD-10 code table. The table is
+// reference data shared by every tenant.
+type DiagnosisCodeRepository interface {
+	FindByCode(code string) (*models.DiagnosisCode, error)
+	SearchByPrefix(prefix string, limit int) ([]models.DiagnosisCode, error)
+	SearchByKeywords(keywords []string, limit int) ([]models.DiagnosisCode, error)
+	Upsert(codes []models.DiagnosisCode) error
+	Count() (int64, error)
+}
+
+type diagnosisCodeRepository struct {
+	db *gorm.DB
+}
+
+func NewDiagnosisCodeRepository(db *gorm.DB) DiagnosisCodeRepository {
+	return &diagnosisCodeRepository{db: db}
+}
+
+func (r *diagnosisCodeRepository) FindByCode(code string) (*models.DiagnosisCode, error) {
+	var diagnosisCode models.DiagnosisCode
+	err := r.db.Where("code = ?", code).First(&diagnosisCode).Error
+	if err != nil {
+		return nil, err
+	}
+	return &diagnosisCode, nil
+}
+
+func (r *diagnosisCodeRepository) SearchByPrefix(prefix string, limit int) ([]models.DiagnosisCode, error) {
+	var codes []models.DiagnosisCode
+	err := r.db.Where("code LIKE ?", prefix+"%").
+		Order("code ASC").
+		Limit(limit).
+		Find(&codes).Error
+	return codes, err
+}
+
+// SearchByKeywords returns codes whose description contains every keyword.
+func (r *diagnosisCodeRepository) SearchByKeywords(keywords []string, limit int) ([]models.DiagnosisCode, error) {
+	var codes []models.DiagnosisCode
+	query := r.db
+	for _, keyword := range keywords {
+		// LOWER ... LIKE instead of ILIKE so the query also runs on SQLite
+		query = query.Where("LOWER(description) LIKE ?", "%"+strings.ToLower(keyword)+"%")
+	}
+	err := query.Order("code ASC").Limit(limit).Find(&codes).Error
+	return codes, err
+}
+
+// Upsert inserts the codes, replacing the description of codes already
+// loaded.
+func (r *diagnosisCodeRepository) Upsert(codes []models.DiagnosisCode) error {
+	if len(codes) == 0 {
+		return nil
+	}
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "code"}},
+		DoUpdates: clause.AssignmentColumns([]string{"description"}),
+	}).CreateInBatches(codes, 500).Error
+}
+
+func (r *diagnosisCodeRepository) Count() (int64, error) {
+	var count int64
+	err := r.db.Model(&models.DiagnosisCode{}).Count(&count).Error
+	return count, err
+}
@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"time"
+
+	"healthcare-portal/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type DoctorScheduleRepository interface {
+	WithTenant(tenantID uint) DoctorScheduleRepository
+	Create(schedule *models.DoctorSchedule) error
+	FindAll(doctorID, locationID *uint) ([]models.DoctorSchedule, error)
+	FindByID(id uint) (*models.DoctorSchedule, error)
+	FindByDoctorAndDay(doctorID uint, day time.Weekday) ([]models.DoctorSchedule, error)
+	CountByDoctor(doctorID uint) (int64, error)
+	Delete(id uint) error
+}
+
+type doctorScheduleRepository struct {
+	db *gorm.DB
+}
+
+func NewDoctorScheduleRepository(db *gorm.DB) DoctorScheduleRepository {
+	return &doctorScheduleRepository{db: requireTenant(db)}
+}
+
+// WithTenant returns a repository limited to tenantID's rows.
+func (r *doctorScheduleRepository) WithTenant(tenantID uint) DoctorScheduleRepository {
+	return &doctorScheduleRepository{db: scopeToTenant(r.db, tenantID)}
+}
+
+func (r *doctorScheduleRepository) Create(schedule *models.DoctorSchedule) error {
+	return r.db.Create(schedule).Error
+}
+
+func (r *doctorScheduleRepository) FindAll(doctorID, locationID *uint) ([]models.DoctorSchedule, error) {
+	var schedules []models.DoctorSchedule
+	query := r.db.Preload("Location")
+	if doctorID != nil {
+		query = query.Where("doctor_id = ?", *doctorID)
+	}
+	if locationID != nil {
+		query = query.Where("location_id = ?", *locationID)
+	}
+	err := query.Order("doctor_id ASC, day_of_week ASC, start_time ASC").Find(&schedules).Error
+	return schedules, err
+}
+
+func (r *doctorScheduleRepository) FindByID(id uint) (*models.DoctorSchedule, error) {
+	var schedule models.DoctorSchedule
+	err := r.db.Preload("Location").First(&schedule, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &schedule, nil
+}
+
+func (r *doctorScheduleRepository) FindByDoctorAndDay(doctorID uint, day time.Weekday) ([]models.DoctorSchedule, error) {
+	var schedules []models.DoctorSchedule
+	err := r.db.Preload("Location").
+		Where("doctor_id = ? AND day_of_week = ?", doctorID, day).
+		Order("start_time ASC").
+		Find(&schedules).Error
+	return schedules, err
+}
+
+func (r *doctorScheduleRepository) CountByDoctor(doctorID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.DoctorSchedule{}).Where("doctor_id = ?", doctorID).Count(&count).Error
+	return count, err
+}
+
+func (r *doctorScheduleRepository) Delete(id uint) error {
+	return r.db.Delete(&models.DoctorSchedule{}, id).Error
+}
@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"healthcare-portal/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type DocumentRepository interface {
+	WithTenant(tenantID uint) DocumentRepository
+	Create(document *models.Document) error
+	FindByID(id uint) (*models.Document, error)
+	FindByPatientID(patientID uint, category models.DocumentCategory) ([]models.Document, error)
+	Update(document *models.Document) error
+	Delete(id uint) error
+}
+
+type documentRepository struct {
+	db *gorm.DB
+}
+
+func NewDocumentRepository(db *gorm.DB) DocumentRepository {
+	return &documentRepository{db: requireTenant(db)}
+}
+
+// WithTenant returns a repository limited to tenantID's rows.
+func (r *documentRepository) WithTenant(tenantID uint) DocumentRepository {
+	return &documentRepository{db: scopeToTenant(r.db, tenantID)}
+}
+
+func (r *documentRepository) Create(document *models.Document) error {
+	return r.db.Create(document).Error
+}
+
+func (r *documentRepository) FindByID(id uint) (*models.Document, error) {
+	var document models.Document
+	if err := r.db.First(&document, id).Error; err != nil {
+		return nil, err
+	}
+	return &document, nil
+}
+
+// FindByPatientID returns the patient's documents, newest first. An empty
+// category returns every category.
+func (r *documentRepository) FindByPatientID(patientID uint, category models.DocumentCategory) ([]models.Document, error) {
+	var documents []models.Document
+	query := r.db.Where("patient_id = ?", patientID)
+	if category != "" {
+		query = query.Where("category = ?", category)
+	}
+	err := query.Order("created_at DESC, id DESC").Find(&documents).Error
+	return documents, err
+}
+
+func (r *documentRepository) Update(document *models.Document) error {
+	return r.db.Save(document).Error
+}
+
+func (r *documentRepository) Delete(id uint) error {
+	return r.db.Delete(&models.Document{}, id).Error
+}
@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"healthcare-portal/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type EncounterRepository interface {
+	WithTenant(tenantID uint) EncounterRepository
+	Create(encounter *models.Encounter) error
+	FindByID(id uint) (*models.Encounter, error)
+	FindByAppointmentID(appointmentID uint) (*models.Encounter, error)
+	FindByPatientID(patientID uint) ([]models.Encounter, error)
+	Update(encounter *models.Encounter) error
+	AddAddendum(addendum *models.EncounterAddendum) error
+}
+
+type encounterRepository struct {
+	db *gorm.DB
+}
+
+func NewEncounterRepository(db *gorm.DB) EncounterRepository {
+	return &encounterRepository{db: requireTenant(db)}
+}
+
+// WithTenant returns a repository limited to tenantID's rows.
+func (r *encounterRepository) WithTenant(tenantID uint) EncounterRepository {
+	return &encounterRepository{db: scopeToTenant(r.db, tenantID)}
+}
+
+func (r *encounterRepository) withDetails() *gorm.DB {
+	return r.db.Preload("Doctor").
+		Preload("Addenda", func(db *gorm.DB) *gorm.DB { return db.Order("created_at ASC, id ASC") }).
+		Preload("Addenda.Author")
+}
+
+func (r *encounterRepository) Create(encounter *models.Encounter) error {
+	return r.db.Create(encounter).Error
+}
+
+func (r *encounterRepository) FindByID(id uint) (*models.Encounter, error) {
+	var encounter models.Encounter
+	err := r.withDetails().Preload("Appointment").First(&encounter, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &encounter, nil
+}
+
+func (r *encounterRepository) FindByAppointmentID(appointmentID uint) (*models.Encounter, error) {
+	var encounter models.Encounter
+	err := r.withDetails().Preload("Appointment").
+		Where("appointment_id = ?", appointmentID).
+		First(&encounter).Error
+	if err != nil {
+		return nil, err
+	}
+	return &encounter, nil
+}
+
+// FindByPatientID returns the patient's encounters oldest first.
+func (r *encounterRepository) FindByPatientID(patientID uint) ([]models.Encounter, error) {
+	var encounters []models.Encounter
+	err := r.withDetails().
+		Where("patient_id = ?", patientID).
+		Order("visit_date ASC, id ASC").
+		Find(&encounters).Error
+	return encounters, err
+}
+
+func (r *encounterRepository) Update(encounter *models.Encounter) error {
+	return r.db.Omit("Doctor", "Appointment", "Addenda").Save(encounter).Error
+}
+
+func (r *encounterRepository) AddAddendum(addendum *models.EncounterAddendum) error {
+	return r.db.Create(addendum).Error
+}
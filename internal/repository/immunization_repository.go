@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"healthcare-portal/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type ImmunizationRepository interface {
+	WithTenant(tenantID uint) ImmunizationRepository
+	Create(immunization *models.Immunization) error
+	FindByID(id uint) (*models.Immunization, error)
+	FindByPatientID(patientID uint) ([]models.Immunization, error)
+	Update(immunization *models.Immunization) error
+	Delete(id uint) error
+}
+
+type immunizationRepository struct {
+	db *gorm.DB
+}
+
+func NewImmunizationRepository(db *gorm.DB) ImmunizationRepository {
+	return &immunizationRepository{db: requireTenant(db)}
+}
+
+// WithTenant returns a repository limited to tenantID's rows.
+func (r *immunizationRepository) WithTenant(tenantID uint) ImmunizationRepository {
+	return &immunizationRepository{db: scopeToTenant(r.db, tenantID)}
+}
+
+func (r *immunizationRepository) Create(immunization *models.Immunization) error {
+	return r.db.Create(immunization).Error
+}
+
+func (r *immunizationRepository) FindByID(id uint) (*models.Immunization, error) {
+	var immunization models.Immunization
+	err := r.db.Preload("AdministeredByUser").First(&immunization, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &immunization, nil
+}
+
+// FindByPatientID returns the patient's doses in the order they were given.
+func (r *immunizationRepository) FindByPatientID(patientID uint) ([]models.Immunization, error) {
+	var immunizations []models.Immunization
+	err := r.db.Preload("AdministeredByUser").
+		Where("patient_id = ?", patientID).
+		Order("administered_at ASC").
+		Find(&immunizations).Error
+	return immunizations, err
+}
+
+func (r *immunizationRepository) Update(immunization *models.Immunization) error {
+	return r.db.Omit("AdministeredByUser").Save(immunization).Error
+}
+
+func (r *immunizationRepository) Delete(id uint) error {
+	return r.db.Delete(&models.Immunization{}, id).Error
+}
@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"healthcare-portal/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type InsuranceRepository interface {
+	WithTenant(tenantID uint) InsuranceRepository
+	CreatePayer(payer *models.InsurancePayer) error
+	FindPayerByID(id uint) (*models.InsurancePayer, error)
+	FindPayers(activeOnly bool) ([]models.InsurancePayer, error)
+	UpdatePayer(payer *models.InsurancePayer) error
+	CreatePlan(plan *models.InsurancePlan) error
+	FindPlanByID(id uint) (*models.InsurancePlan, error)
+	FindPlansByPayerID(payerID uint) ([]models.InsurancePlan, error)
+	UpdatePlan(plan *models.InsurancePlan) error
+	CreateCoverage(coverage *models.Coverage) error
+	FindCoverageByID(id uint) (*models.Coverage, error)
+	FindCoveragesByPatientID(patientID uint) ([]models.Coverage, error)
+	UpdateCoverage(coverage *models.Coverage) error
+	DeleteCoverage(id uint) error
+	CreateEligibilityCheck(check *models.EligibilityCheck) error
+	FindEligibilityChecksByAppointmentID(appointmentID uint) ([]models.EligibilityCheck, error)
+}
+
+type insuranceRepository struct {
+	db *gorm.DB
+}
+
+func NewInsuranceRepository(db *gorm.DB) InsuranceRepository {
+	return &insuranceRepository{db: requireTenant(db)}
+}
+
+// WithTenant returns a repository limited to tenantID's rows.
+func (r *insuranceRepository) WithTenant(tenantID uint) InsuranceRepository {
+	return &insuranceRepository{db: scopeToTenant(r.db, tenantID)}
+}
+
+func (r *insuranceRepository) CreatePayer(payer *models.InsurancePayer) error {
+	return r.db.Omit("Plans").Create(payer).Error
+}
+
+// FindPayerByID returns the payer with its plans.
+func (r *insuranceRepository) FindPayerByID(id uint) (*models.InsurancePayer, error) {
+	var payer models.InsurancePayer
+	err := r.db.Preload("Plans", func(db *gorm.DB) *gorm.DB { return db.Order("name ASC") }).First(&payer, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &payer, nil
+}
+
+func (r *insuranceRepository) FindPayers(activeOnly bool) ([]models.InsurancePayer, error) {
+	var payers []models.InsurancePayer
+	query := r.db
+	if activeOnly {
+		query = query.Where("is_active = ?", true)
+	}
+	err := query.Order("name ASC").Find(&payers).Error
+	return payers, err
+}
+
+func (r *insuranceRepository) UpdatePayer(payer *models.InsurancePayer) error {
+	return r.db.Omit("Plans").Save(payer).Error
+}
+
+func (r *insuranceRepository) CreatePlan(plan *models.InsurancePlan) error {
+	return r.db.Omit("Payer").Create(plan).Error
+}
+
+// FindPlanByID returns the plan with its payer.
+func (r *insuranceRepository) FindPlanByID(id uint) (*models.InsurancePlan, error) {
+	var plan models.InsurancePlan
+	err := r.db.Preload("Payer").First(&plan, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &plan, nil
+}
+
+func (r *insuranceRepository) FindPlansByPayerID(payerID uint) ([]models.InsurancePlan, error) {
+	var plans []models.InsurancePlan
+	err := r.db.Where("payer_id = ?", payerID).Order("name ASC").Find(&plans).Error
+	return plans, err
+}
+
+func (r *insuranceRepository) UpdatePlan(plan *models.InsurancePlan) error {
+	return r.db.Omit("Payer").Save(plan).Error
+}
+
+func (r *insuranceRepository) CreateCoverage(coverage *models.Coverage) error {
+	return r.db.Omit("Plan").Create(coverage).Error
+}
+
+// FindCoverageByID returns the coverage with its plan and payer.
+func (r *insuranceRepository) FindCoverageByID(id uint) (*models.Coverage, error) {
+	var coverage models.Coverage
+	err := r.db.Preload("Plan.Payer").First(&coverage, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &coverage, nil
+}
+
+// FindCoveragesByPatientID returns the patient's coverages in priority
+// order, the most recent first within a priority.
+func (r *insuranceRepository) FindCoveragesByPatientID(patientID uint) ([]models.Coverage, error) {
+	var coverages []models.Coverage
+	err := r.db.Preload("Plan.Payer").Where("patient_id = ?", patientID).
+		Order("priority ASC, effective_from DESC, id ASC").
+		Find(&coverages).Error
+	return coverages, err
+}
+
+func (r *insuranceRepository) UpdateCoverage(coverage *models.Coverage) error {
+	return r.db.Omit("Plan").Save(coverage).Error
+}
+
+func (r *insuranceRepository) DeleteCoverage(id uint) error {
+	return r.db.Delete(&models.Coverage{}, id).Error
+}
+
+func (r *insuranceRepository) CreateEligibilityCheck(check *models.EligibilityCheck) error {
+	return r.db.Omit("Coverage").Create(check).Error
+}
+
+// FindEligibilityChecksByAppointmentID returns the checks made for the
+// appointment, newest first.
+func (r *insuranceRepository) FindEligibilityChecksByAppointmentID(appointmentID uint) ([]models.EligibilityCheck, error) {
+	var checks []models.EligibilityCheck
+	err := r.db.Preload("Coverage.Plan.Payer").Where("appointment_id = ?", appointmentID).
+		Order("checked_at DESC, id DESC").
+		Find(&checks).Error
+	return checks, err
+}
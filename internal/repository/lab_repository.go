@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"healthcare-portal/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type LabRepository interface {
+	WithTenant(tenantID uint) LabRepository
+	CreateOrder(order *models.LabOrder) error
+	FindOrderByID(id uint) (*models.LabOrder, error)
+	FindOrdersByPatientID(patientID uint) ([]models.LabOrder, error)
+	FindUnreviewedByDoctorID(doctorID uint) ([]models.LabOrder, error)
+	UpdateOrder(order *models.LabOrder) error
+	AddResults(order *models.LabOrder, results []models.LabResult) error
+}
+
+type labRepository struct {
+	db *gorm.DB
+}
+
+func NewLabRepository(db *gorm.DB) LabRepository {
+	return &labRepository{db: requireTenant(db)}
+}
+
+// WithTenant returns a repository limited to tenantID's rows.
+func (r *labRepository) WithTenant(tenantID uint) LabRepository {
+	return &labRepository{db: scopeToTenant(r.db, tenantID)}
+}
+
+func (r *labRepository) CreateOrder(order *models.LabOrder) error {
+	return r.db.Create(order).Error
+}
+
+func (r *labRepository) FindOrderByID(id uint) (*models.LabOrder, error) {
+	var order models.LabOrder
+	err := r.db.Preload("Patient").Preload("Doctor").
+		Preload("Results", func(db *gorm.DB) *gorm.DB { return db.Order("id ASC") }).
+		First(&order, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+func (r *labRepository) FindOrdersByPatientID(patientID uint) ([]models.LabOrder, error) {
+	var orders []models.LabOrder
+	err := r.db.Preload("Doctor").
+		Preload("Results", func(db *gorm.DB) *gorm.DB { return db.Order("id ASC") }).
+		Where("patient_id = ?", patientID).
+		Order("ordered_at DESC").
+		Find(&orders).Error
+	return orders, err
+}
+
+// FindUnreviewedByDoctorID returns the doctor's orders that have results not
+// yet acknowledged, oldest result first.
+func (r *labRepository) FindUnreviewedByDoctorID(doctorID uint) ([]models.LabOrder, error) {
+	var orders []models.LabOrder
+	err := r.db.Preload("Patient").
+		Preload("Results", func(db *gorm.DB) *gorm.DB { return db.Order("id ASC") }).
+		Where("doctor_id = ? AND status IN ? AND reviewed_at IS NULL", doctorID,
+			[]models.LabOrderStatus{models.LabPartial, models.LabResulted}).
+		Order("resulted_at ASC").
+		Find(&orders).Error
+	return orders, err
+}
+
+func (r *labRepository) UpdateOrder(order *models.LabOrder) error {
+	return r.db.Omit("Patient", "Doctor", "Results").Save(order).Error
+}
+
+// AddResults stores a batch of results and the order's new status in one
+// transaction.
+func (r *labRepository) AddResults(order *models.LabOrder, results []models.LabResult) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for i := range results {
+			results[i].LabOrderID = order.ID
+		}
+		if err := tx.Create(&results).Error; err != nil {
+			return err
+		}
+		return tx.Omit("Patient", "Doctor", "Results").Save(order).Error
+	})
+}
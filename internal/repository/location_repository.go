@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"healthcare-portal/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type LocationRepository interface {
+	WithTenant(tenantID uint) LocationRepository
+	Create(location *models.Location) error
+	FindAll() ([]models.Location, error)
+	FindByID(id uint) (*models.Location, error)
+	Update(location *models.Location) error
+	Delete(id uint) error
+}
+
+type locationRepository struct {
+	db *gorm.DB
+}
+
+func NewLocationRepository(db *gorm.DB) LocationRepository {
+	return &locationRepository{db: requireTenant(db)}
+}
+
+// WithTenant returns a repository limited to tenantID's rows.
+func (r *locationRepository) WithTenant(tenantID uint) LocationRepository {
+	return &locationRepository{db: scopeToTenant(r.db, tenantID)}
+}
+
+func (r *locationRepository) Create(location *models.Location) error {
+	return r.db.Create(location).Error
+}
+
+func (r *locationRepository) FindAll() ([]models.Location, error) {
+	var locations []models.Location
+	err := r.db.Order("name ASC").Find(&locations).Error
+	return locations, err
+}
+
+func (r *locationRepository) FindByID(id uint) (*models.Location, error) {
+	var location models.Location
+	err := r.db.First(&location, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &location, nil
+}
+
+func (r *locationRepository) Update(location *models.Location) error {
+	return r.db.Save(location).Error
+}
+
+func (r *locationRepository) Delete(id uint) error {
+	return r.db.Delete(&models.Location{}, id).Error
+}
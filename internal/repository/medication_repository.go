@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"healthcare-portal/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type MedicationRepository interface {
+	WithTenant(tenantID uint) MedicationRepository
+	CreateStatement(statement *models.MedicationStatement) error
+	FindStatementByID(id uint) (*models.MedicationStatement, error)
+	FindStatementsByPatientID(patientID uint, activeOnly bool) ([]models.MedicationStatement, error)
+	UpdateStatement(statement *models.MedicationStatement) error
+	DeleteStatement(id uint) error
+	CreatePrescription(prescription *models.Prescription, statement *models.MedicationStatement) error
+	FindPrescriptionByID(id uint) (*models.Prescription, error)
+	FindPrescriptionsByPatientID(patientID uint) ([]models.Prescription, error)
+	UpdatePrescription(prescription *models.Prescription) error
+	StopStatementsForPrescription(prescriptionID uint) error
+}
+
+type medicationRepository struct {
+	db *gorm.DB
+}
+
+func NewMedicationRepository(db *gorm.DB) MedicationRepository {
+	return &medicationRepository{db: requireTenant(db)}
+}
+
+// WithTenant returns a repository limited to tenantID's rows.
+func (r *medicationRepository) WithTenant(tenantID uint) MedicationRepository {
+	return &medicationRepository{db: scopeToTenant(r.db, tenantID)}
+}
+
+func (r *medicationRepository) CreateStatement(statement *models.MedicationStatement) error {
+	return r.db.Create(statement).Error
+}
+
+func (r *medicationRepository) FindStatementByID(id uint) (*models.MedicationStatement, error) {
+	var statement models.MedicationStatement
+	err := r.db.First(&statement, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &statement, nil
+}
+
+func (r *medicationRepository) FindStatementsByPatientID(patientID uint, activeOnly bool) ([]models.MedicationStatement, error) {
+	var statements []models.MedicationStatement
+	query := r.db.Where("patient_id = ?", patientID)
+	if activeOnly {
+		query = query.Where("status = ?", models.MedicationActive)
+	}
+	err := query.Order("status ASC, drug ASC").Find(&statements).Error
+	return statements, err
+}
+
+func (r *medicationRepository) UpdateStatement(statement *models.MedicationStatement) error {
+	return r.db.Save(statement).Error
+}
+
+func (r *medicationRepository) DeleteStatement(id uint) error {
+	return r.db.Delete(&models.MedicationStatement{}, id).Error
+}
+
+// CreatePrescription saves the prescription, its acknowledged warnings and
+// the medication list entry it adds in one transaction.
+func (r *medicationRepository) CreatePrescription(prescription *models.Prescription, statement *models.MedicationStatement) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Patient", "Doctor").Create(prescription).Error; err != nil {
+			return err
+		}
+		statement.PrescriptionID = &prescription.ID
+		return tx.Create(statement).Error
+	})
+}
+
+func (r *medicationRepository) FindPrescriptionByID(id uint) (*models.Prescription, error) {
+	var prescription models.Prescription
+	err := r.db.Preload("Patient").Preload("Doctor").Preload("Warnings").First(&prescription, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &prescription, nil
+}
+
+func (r *medicationRepository) FindPrescriptionsByPatientID(patientID uint) ([]models.Prescription, error) {
+	var prescriptions []models.Prescription
+	err := r.db.Preload("Doctor").
+		Where("patient_id = ?", patientID).
+		Order("issued_at DESC").
+		Find(&prescriptions).Error
+	return prescriptions, err
+}
+
+func (r *medicationRepository) UpdatePrescription(prescription *models.Prescription) error {
+	return r.db.Omit("Patient", "Doctor", "Warnings").Save(prescription).Error
+}
+
+func (r *medicationRepository) StopStatementsForPrescription(prescriptionID uint) error {
+	return r.db.Model(&models.MedicationStatement{}).
+		Where("prescription_id = ? AND status = ?", prescriptionID, models.MedicationActive).
+		Update("status", models.MedicationStopped).Error
+}
@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"healthcare-portal/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// nextNumber issues the next number of the tenant's name series. The
+// counter row is created or incremented in a single statement, so
+// concurrent callers queue on the row instead of reading the same value.
+func nextNumber(db *gorm.DB, name string) (int, error) {
+	sequence := models.NumberSequence{Name: name, LastValue: 1}
+	err := db.Clauses(
+		clause.OnConflict{
+			Columns:   []clause.Column{{Name: "tenant_id"}, {Name: "name"}},
+			DoUpdates: clause.Set{{Column: clause.Column{Name: "last_value"}, Value: gorm.Expr("number_sequences.last_value + 1")}},
+		},
+		clause.Returning{Columns: []clause.Column{{Name: "last_value"}}},
+	).Create(&sequence).Error
+	return sequence.LastValue, err
+}
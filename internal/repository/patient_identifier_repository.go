@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"healthcare-portal/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type PatientIdentifierRepository interface {
+	WithTenant(tenantID uint) PatientIdentifierRepository
+	Create(identifier *models.PatientIdentifier) error
+	FindByID(id uint) (*models.PatientIdentifier, error)
+	FindByPatientID(patientID uint) ([]models.PatientIdentifier, error)
+	FindBySystemValue(system, value string) (*models.PatientIdentifier, error)
+	FindByValue(value string) ([]models.PatientIdentifier, error)
+	Delete(id uint) error
+	NextMRNSequence(locationID uint) (uint64, error)
+}
+
+type patientIdentifierRepository struct {
+	db *gorm.DB
+}
+
+func NewPatientIdentifierRepository(db *gorm.DB) PatientIdentifierRepository {
+	return &patientIdentifierRepository{db: requireTenant(db)}
+}
+
+// WithTenant returns a repository limited to tenantID's rows.
+func (r *patientIdentifierRepository) WithTenant(tenantID uint) PatientIdentifierRepository {
+	return &patientIdentifierRepository{db: scopeToTenant(r.db, tenantID)}
+}
+
+func (r *patientIdentifierRepository) Create(identifier *models.PatientIdentifier) error {
+	return r.db.Create(identifier).Error
+}
+
+func (r *patientIdentifierRepository) FindByID(id uint) (*models.PatientIdentifier, error) {
+	var identifier models.PatientIdentifier
+	err := r.db.First(&identifier, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &identifier, nil
+}
+
+func (r *patientIdentifierRepository) FindByPatientID(patientID uint) ([]models.PatientIdentifier, error) {
+	var identifiers []models.PatientIdentifier
+	err := r.db.Where("patient_id = ?", patientID).Order("system ASC, id ASC").Find(&identifiers).Error
+	return identifiers, err
+}
+
+func (r *patientIdentifierRepository) FindBySystemValue(system, value string) (*models.PatientIdentifier, error) {
+	var identifier models.PatientIdentifier
+	err := r.db.Where("system = ? AND value = ?", system, value).First(&identifier).Error
+	if err != nil {
+		return nil, err
+	}
+	return &identifier, nil
+}
+
+// FindByValue returns the identifiers with value in any system.
+func (r *patientIdentifierRepository) FindByValue(value string) ([]models.PatientIdentifier, error) {
+	var identifiers []models.PatientIdentifier
+	err := r.db.Where("value = ?", value).Order("id ASC").Find(&identifiers).Error
+	return identifiers, err
+}
+
+func (r *patientIdentifierRepository) Delete(id uint) error {
+	return r.db.Delete(&models.PatientIdentifier{}, id).Error
+}
+
+// NextMRNSequence reserves the next number of the location's MRN sequence.
+// The sequence row is created on first use or incremented in a single
+// statement, so concurrent registrations queue on the row instead of
+// reading the same value or both creating it.
+func (r *patientIdentifierRepository) NextMRNSequence(locationID uint) (uint64, error) {
+	sequence := models.MRNSequence{LocationID: locationID, NextValue: 2}
+	err := r.db.Clauses(
+		clause.OnConflict{
+			Columns:   []clause.Column{{Name: "tenant_id"}, {Name: "location_id"}},
+			DoUpdates: clause.Set{{Column: clause.Column{Name: "next_value"}, Value: gorm.Expr("mrn_sequences.next_value + 1")}},
+		},
+		clause.Returning{Columns: []clause.Column{{Name: "next_value"}}},
+	).Create(&sequence).Error
+	if err != nil {
+		return 0, err
+	}
+	return sequence.NextValue - 1, nil
+}
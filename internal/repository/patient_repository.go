@@ -1,17 +1,55 @@
 package repository
 
 import (
+    "strings"
+
     "healthcare-portal/internal/models"
+    "healthcare-portal/internal/query"
     "gorm.io/gorm"
 )
 
 type PatientRepository interface {
+    WithTenant(tenantID uint) PatientRepository
     Create(patient *models.Patient) error
     FindAll(limit, offset int) ([]models.Patient, int64, error)
+    List(req query.Request) (*query.Page[models.Patient], error)
     FindByID(id uint) (*models.Patient, error)
+    FindByMRN(mrn string) (*models.Patient, error)
     Update(patient *models.Patient) error
     Delete(id uint) error
-    Search(query string) ([]models.Patient, error)
+    Search(query models.PatientSearchQuery) (*models.PatientSearchPage, error)
+    ReindexSearch(patient *models.Patient) error
+    FindDuplicateCandidates(patient *models.Patient) ([]models.Patient, error)
+    Merge(survivor, duplicate *models.Patient, merge *models.PatientMerge) error
+    FindMergeByMergedID(patientID uint) (*models.PatientMerge, error)
+    FindMergesBySurvivorID(patientID uint) ([]models.PatientMerge, error)
+}
+
+// patientReferenceTables are the tables whose patient_id moves to the
+// surviving record when two patients are merged.
+var patientReferenceTables = []string{
+    "appointments",
+    "encounters",
+    "allergies",
+    "prescriptions",
+    "medication_statements",
+    "vital_signs",
+    "problems",
+    "lab_orders",
+    "immunizations",
+    "documents",
+    "referrals",
+    "care_plans",
+    "care_tasks",
+    "patient_identifiers",
+    "related_persons",
+    "consents",
+    "coverages",
+    "eligibility_checks",
+    "charges",
+    "invoices",
+    "payments",
+    "claims",
 }
 
 type patientRepository struct {
@@ -19,11 +57,40 @@ type patientRepository struct {
 }
 
 func NewPatientRepository(db *gorm.DB) PatientRepository {
-    return &patientRepository{db: db}
+    return &patientRepository{db: requireTenant(db)}
 }
 
+// WithTenant returns a repository limited to tenantID's rows.
+func (r *patientRepository) WithTenant(tenantID uint) PatientRepository {
+    return &patientRepository{db: scopeToTenant(r.db, tenantID)}
+}
+
+// Create stores the patient with their identifiers and related persons.
+// Those carry their own tenant, so they are stamped with the patient's. The
+// patient is added to the search index in the same transaction.
 func (r *patientRepository) Create(patient *models.Patient) error {
-    return r.db.Create(patient).Error
+    return r.db.Transaction(func(tx *gorm.DB) error {
+        identifiers, relatedPersons := patient.Identifiers, patient.RelatedPersons
+        if err := tx.Omit("Identifiers", "RelatedPersons").Create(patient).Error; err != nil {
+            return err
+        }
+        for i := range identifiers {
+            identifiers[i].PatientID = patient.ID
+            identifiers[i].TenantID = patient.TenantID
+            if err := tx.Create(&identifiers[i]).Error; err != nil {
+                return err
+            }
+        }
+        for i := range relatedPersons {
+            relatedPersons[i].PatientID = patient.ID
+            relatedPersons[i].TenantID = patient.TenantID
+            if err := tx.Create(&relatedPersons[i]).Error; err != nil {
+                return err
+            }
+        }
+        patient.Identifiers, patient.RelatedPersons = identifiers, relatedPersons
+        return indexPatient(tx, patient)
+    })
 }
 
 func (r *patientRepository) FindAll(limit, offset int) ([]models.Patient, int64, error) {
@@ -43,9 +110,43 @@ func (r *patientRepository) FindAll(limit, offset int) ([]models.Patient, int64,
     return patients, total, err
 }
 
+// PatientListSpec is what the patient list can be filtered and sorted by.
+var PatientListSpec = query.Spec{
+    Filters: map[string]query.Filter{
+        "gender":  {Column: "gender", Type: query.String, Fold: true},
+        "born":    {Column: "date_of_birth", Type: query.Time, Range: true},
+        "created": {Column: "created_at", Type: query.Time, Range: true},
+    },
+    Sorts: map[string]query.Sort{
+        "last_name":     {Column: "last_name", Type: query.String},
+        "first_name":    {Column: "first_name", Type: query.String},
+        "date_of_birth": {Column: "date_of_birth", Type: query.Time},
+        "created_at":    {Column: "created_at", Type: query.Time},
+    },
+    DefaultSort:  "-created_at",
+    DefaultLimit: 20,
+    MaxLimit:     100,
+}
+
+// List returns one page of patients as described by req.
+func (r *patientRepository) List(req query.Request) (*query.Page[models.Patient], error) {
+    return query.Run[models.Patient](r.db, PatientListSpec, req)
+}
+
 func (r *patientRepository) FindByID(id uint) (*models.Patient, error) {
     var patient models.Patient
-    err := r.db.First(&patient, id).Error
+    err := r.db.Preload("Identifiers", func(db *gorm.DB) *gorm.DB { return db.Order("system ASC, id ASC") }).
+        Preload("RelatedPersons", func(db *gorm.DB) *gorm.DB { return db.Order("is_guardian DESC, is_emergency_contact DESC, id ASC") }).
+        First(&patient, id).Error
+    if err != nil {
+        return nil, err
+    }
+    return &patient, nil
+}
+
+func (r *patientRepository) FindByMRN(mrn string) (*models.Patient, error) {
+    var patient models.Patient
+    err := r.db.Where("mrn = ?", mrn).First(&patient).Error
     if err != nil {
         return nil, err
     }
@@ -53,18 +154,169 @@ func (r *patientRepository) FindByID(id uint) (*models.Patient, error) {
 }
 
 func (r *patientRepository) Update(patient *models.Patient) error {
-    return r.db.Save(patient).Error
+    return r.db.Transaction(func(tx *gorm.DB) error {
+        if err := tx.Omit("Identifiers", "RelatedPersons").Save(patient).Error; err != nil {
+            return err
+        }
+        return indexPatient(tx, patient)
+    })
 }
 
 func (r *patientRepository) Delete(id uint) error {
-    return r.db.Delete(&models.Patient{}, id).Error
+    return r.db.Transaction(func(tx *gorm.DB) error {
+        result := tx.Delete(&models.Patient{}, id)
+        if result.Error != nil || result.RowsAffected == 0 {
+            return result.Error
+        }
+        return unindexPatient(tx, id)
+    })
 }
 
-func (r *patientRepository) Search(query string) ([]models.Patient, error) {
+// FindDuplicateCandidates returns the patients worth scoring against
+// patient: those sharing the date of birth, email, the last four phone
+// digits or the first two letters of the last name. Blocking on these keeps
+// the comparison away from a full table scan in the matcher.
+func (r *patientRepository) FindDuplicateCandidates(patient *models.Patient) ([]models.Patient, error) {
+    conditions := []string{}
+    args := []interface{}{}
+    if !patient.DateOfBirth.IsZero() {
+        conditions = append(conditions, "date_of_birth = ?")
+        args = append(args, patient.DateOfBirth)
+    }
+    if email := strings.ToLower(strings.TrimSpace(patient.Email)); email != "" {
+        conditions = append(conditions, "LOWER(email) = ?")
+        args = append(args, email)
+    }
+    if digits := phoneDigits(patient.Phone); len(digits) >= 4 {
+        conditions = append(conditions, "phone LIKE ?")
+        args = append(args, "%"+digits[len(digits)-4:])
+    }
+    if lastName := strings.ToLower(strings.TrimSpace(patient.LastName)); len([]rune(lastName)) >= 2 {
+        conditions = append(conditions, "LOWER(last_name) LIKE ?")
+        args = append(args, string([]rune(lastName)[:2])+"%")
+    }
+    if len(conditions) == 0 {
+        return nil, nil
+    }
+
     var patients []models.Patient
-    searchQuery := "%" + query + "%"
-    err := r.db.Where("first_name ILIKE ? OR last_name ILIKE ? OR email ILIKE ? OR phone ILIKE ?",
-        searchQuery, searchQuery, searchQuery, searchQuery).
-        Find(&patients).Error
+    query := r.db.Where("("+strings.Join(conditions, " OR ")+")", args...)
+    if patient.ID != 0 {
+        query = query.Where("id <> ?", patient.ID)
+    }
+    err := query.Order("id ASC").Find(&patients).Error
     return patients, err
-}
\ No newline at end of file
+}
+
+// Merge re-points every record of duplicate at survivor, saves survivor,
+// soft-deletes duplicate and stores the tombstone, all in one transaction.
+// merge.MovedRecords is filled with the rows moved per table.
+func (r *patientRepository) Merge(survivor, duplicate *models.Patient, merge *models.PatientMerge) error {
+    return r.db.Transaction(func(tx *gorm.DB) error {
+        if err := supersedeMergedConsents(tx, survivor.ID, duplicate.ID, merge.MergedBy); err != nil {
+            return err
+        }
+        merge.MovedRecords = make(map[string]int64)
+        for _, table := range patientReferenceTables {
+            result := tx.Table(table).Where("patient_id = ?", duplicate.ID).Update("patient_id", survivor.ID)
+            if result.Error != nil {
+                return result.Error
+            }
+            if result.RowsAffected > 0 {
+                merge.MovedRecords[table] = result.RowsAffected
+            }
+        }
+
+        // The email is unique per tenant, so release it before the
+        // survivor takes it over. The tombstone snapshot keeps the original.
+        if duplicate.Email != "" && survivor.Email == duplicate.Email {
+            if err := tx.Model(&models.Patient{}).Where("id = ?", duplicate.ID).Update("email", nil).Error; err != nil {
+                return err
+            }
+        }
+        if err := tx.Omit("Identifiers", "RelatedPersons").Save(survivor).Error; err != nil {
+            return err
+        }
+        if err := indexPatient(tx, survivor); err != nil {
+            return err
+        }
+        // Wristbands and letters printed with the old MRN still find the
+        // surviving record.
+        if duplicate.MRN != "" {
+            previous := &models.PatientIdentifier{
+                TenantID:  survivor.TenantID,
+                PatientID: survivor.ID,
+                System:    models.IdentifierPreviousMRN,
+                Value:     duplicate.MRN,
+                CreatedBy: merge.MergedBy,
+            }
+            if err := tx.Create(previous).Error; err != nil {
+                return err
+            }
+        }
+        if err := tx.Delete(&models.Patient{}, duplicate.ID).Error; err != nil {
+            return err
+        }
+        if err := unindexPatient(tx, duplicate.ID); err != nil {
+            return err
+        }
+        return tx.Create(merge).Error
+    })
+}
+
+// supersedeMergedConsents revokes the older of each pair of granted consents
+// of the same type held by both patients, so that only one grant per type is
+// active once the duplicate's consents move to the survivor.
+func supersedeMergedConsents(tx *gorm.DB, survivorID, duplicateID, mergedBy uint) error {
+    var granted []models.Consent
+    err := tx.Where("patient_id IN ? AND status = ?", []uint{survivorID, duplicateID}, models.ConsentGranted).
+        Order("granted_at ASC, id ASC").Find(&granted).Error
+    if err != nil {
+        return err
+    }
+
+    newest := make(map[models.ConsentType]models.Consent)
+    for _, consent := range granted {
+        older, clash := newest[consent.Type]
+        newest[consent.Type] = consent
+        if !clash {
+            continue
+        }
+        err := tx.Model(&models.Consent{}).Where("id = ?", older.ID).
+            Updates(map[string]interface{}{
+                "status":            models.ConsentRevoked,
+                "revoked_at":        consent.GrantedAt,
+                "revocation_reason": "superseded by patient merge",
+                "revoked_by":        mergedBy,
+            }).Error
+        if err != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+func (r *patientRepository) FindMergeByMergedID(patientID uint) (*models.PatientMerge, error) {
+    var merge models.PatientMerge
+    err := r.db.Where("merged_patient_id = ?", patientID).First(&merge).Error
+    if err != nil {
+        return nil, err
+    }
+    return &merge, nil
+}
+
+func (r *patientRepository) FindMergesBySurvivorID(patientID uint) ([]models.PatientMerge, error) {
+    var merges []models.PatientMerge
+    err := r.db.Where("survivor_patient_id = ?", patientID).Order("created_at DESC").Find(&merges).Error
+    return merges, err
+}
+
+func phoneDigits(phone string) string {
+    var digits strings.Builder
+    for _, r := range phone {
+        if r >= '0' && r <= '9' {
+            digits.WriteRune(r)
+        }
+    }
+    return digits.String()
+}
@@ -0,0 +1,224 @@
+package repository
+
+import (
+	"sort"
+	"strings"
+
+	"healthcare-portal/internal/models"
+	"healthcare-portal/internal/search"
+
+	"gorm.io/gorm"
+)
+
+const (
+	defaultSearchLimit = 20
+	// searchCandidateLimit bounds the patients loaded and scored for one
+	// text search, and so the total it reports.
+	searchCandidateLimit = 500
+)
+
+// Search finds patients matching query.Text, best match first. Each word of
+// the text must match a name, the email, phone or MRN; names also match by
+// sound and by shared trigrams, so "Jonson" finds "Johnson". Candidates are
+// looked up in the patient_search_terms index, where the database keeps the
+// searchCandidateLimit with the most matching entries, and are then scored
+// in Go, which keeps the search portable across databases. Without text,
+// the filters alone select patients, ordered by name.
+func (r *patientRepository) Search(query models.PatientSearchQuery) (*models.PatientSearchPage, error) {
+	if query.Page < 1 {
+		query.Page = 1
+	}
+	if query.Limit < 1 {
+		query.Limit = defaultSearchLimit
+	}
+	page := &models.PatientSearchPage{Hits: []models.PatientSearchHit{}, Page: query.Page, Limit: query.Limit}
+	offset := (query.Page - 1) * query.Limit
+
+	terms := search.ParseQuery(query.Text)
+	if len(terms) == 0 {
+		if err := r.searchFilters(r.db.Model(&models.Patient{}), query).Count(&page.Total).Error; err != nil {
+			return nil, err
+		}
+		var patients []models.Patient
+		err := r.searchFilters(r.db, query).Order("last_name ASC, first_name ASC, id ASC").
+			Offset(offset).Limit(query.Limit).Find(&patients).Error
+		if err != nil {
+			return nil, err
+		}
+		for _, patient := range patients {
+			page.Hits = append(page.Hits, models.PatientSearchHit{Patient: patient, Score: 1})
+		}
+		return page, nil
+	}
+
+	candidateIDs, err := r.searchCandidates(terms, query)
+	if err != nil {
+		return nil, err
+	}
+
+	hits := []models.PatientSearchHit{}
+	if len(candidateIDs) > 0 {
+		var patients []models.Patient
+		if err := r.db.Where("id IN ?", candidateIDs).Find(&patients).Error; err != nil {
+			return nil, err
+		}
+		for _, patient := range patients {
+			if score, matched, ok := search.Score(terms, patientSearchFields(&patient)); ok {
+				hits = append(hits, models.PatientSearchHit{Patient: patient, Score: score, MatchedFields: matched})
+			}
+		}
+	}
+
+	sort.Slice(hits, func(i, j int) bool {
+		a, b := hits[i], hits[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		if a.Patient.LastName != b.Patient.LastName {
+			return a.Patient.LastName < b.Patient.LastName
+		}
+		if a.Patient.FirstName != b.Patient.FirstName {
+			return a.Patient.FirstName < b.Patient.FirstName
+		}
+		return a.Patient.ID < b.Patient.ID
+	})
+
+	page.Total = int64(len(hits))
+	if offset < len(hits) {
+		end := offset + query.Limit
+		if end > len(hits) {
+			end = len(hits)
+		}
+		page.Hits = hits[offset:end]
+	}
+	return page, nil
+}
+
+// searchFilters narrows db to patients born on the query's date of birth
+// and holding its MRN, current or from before a merge.
+func (r *patientRepository) searchFilters(db *gorm.DB, query models.PatientSearchQuery) *gorm.DB {
+	if query.DateOfBirth != nil {
+		day := *query.DateOfBirth
+		db = db.Where("date_of_birth >= ? AND date_of_birth < ?", day, day.AddDate(0, 0, 1))
+	}
+	if mrn := strings.ToUpper(strings.TrimSpace(query.MRN)); mrn != "" {
+		previous := r.db.Model(&models.PatientIdentifier{}).Select("patient_id").
+			Where("system = ? AND value = ?", models.IdentifierPreviousMRN, mrn)
+		db = db.Where("(mrn = ? OR id IN (?))", mrn, previous)
+	}
+	return db
+}
+
+// searchCandidates returns the IDs of up to searchCandidateLimit patients
+// passing the query's filters with an index entry for every term: a word
+// starting with it or, for words that may be misspelt, the same Soundex code
+// or enough shared trigrams. Patients with the most matching entries come
+// first.
+func (r *patientRepository) searchCandidates(terms []search.Term, query models.PatientSearchQuery) ([]uint, error) {
+	candidates := r.db.Model(&models.PatientSearchTerm{}).
+		Where("patient_id IN (?)", r.searchFilters(r.db.Model(&models.Patient{}).Select("id"), query))
+	anyTerm := []string{}
+	anyArgs := []interface{}{}
+	for _, term := range terms {
+		conditions := []string{"(kind = ? AND term LIKE ? ESCAPE '\\')"}
+		args := []interface{}{search.IndexWord, likePrefix(term.Text)}
+		minTrigrams := 1
+		if term.Fuzzy() {
+			conditions = append(conditions, "(kind = ? AND term = ?)", "(kind = ? AND term IN ?)")
+			args = append(args, search.IndexPhonetic, search.Soundex(term.Text), search.IndexTrigram, search.Trigrams(term.Text))
+			if shared := search.MinSharedTrigrams(term); shared > minTrigrams {
+				minTrigrams = shared
+			}
+		}
+		condition := "(" + strings.Join(conditions, " OR ") + ")"
+		anyTerm = append(anyTerm, condition)
+		anyArgs = append(anyArgs, args...)
+
+		matching := r.db.Model(&models.PatientSearchTerm{}).Select("patient_id").
+			Where(condition, args...).
+			Group("patient_id").
+			Having("SUM(CASE WHEN kind <> ? THEN 1 ELSE 0 END) > 0 OR SUM(CASE WHEN kind = ? THEN 1 ELSE 0 END) >= ?",
+				search.IndexTrigram, search.IndexTrigram, minTrigrams)
+		candidates = candidates.Where("patient_id IN (?)", matching)
+	}
+
+	var ids []uint
+	err := candidates.Where("("+strings.Join(anyTerm, " OR ")+")", anyArgs...).
+		Group("patient_id").
+		Order("COUNT(*) DESC, patient_id ASC").
+		Limit(searchCandidateLimit).
+		Pluck("patient_id", &ids).Error
+	return ids, err
+}
+
+// ReindexSearch rebuilds the patient's search index entries.
+func (r *patientRepository) ReindexSearch(patient *models.Patient) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return indexPatient(tx, patient)
+	})
+}
+
+// indexPatient replaces the patient's search index entries with ones for
+// their current details.
+func indexPatient(tx *gorm.DB, patient *models.Patient) error {
+	if err := unindexPatient(tx, patient.ID); err != nil {
+		return err
+	}
+	indexTerms := search.IndexTerms(patientSearchFields(patient))
+	if len(indexTerms) == 0 {
+		return nil
+	}
+	rows := make([]models.PatientSearchTerm, 0, len(indexTerms))
+	for _, term := range indexTerms {
+		rows = append(rows, models.PatientSearchTerm{TenantID: patient.TenantID, PatientID: patient.ID, Kind: term.Kind, Term: term.Term})
+	}
+	return tx.CreateInBatches(rows, 200).Error
+}
+
+func unindexPatient(tx *gorm.DB, patientID uint) error {
+	return tx.Where("patient_id = ?", patientID).Delete(&models.PatientSearchTerm{}).Error
+}
+
+// patientSearchFields lists what a patient is searched by. Phones are
+// indexed in full and by their last seven and four digits, and MRN numbers
+// also without their leading zeros, since that is how staff type them.
+func patientSearchFields(patient *models.Patient) []search.Field {
+	emailTokens := []string{}
+	if email := search.Fold(strings.TrimSpace(patient.Email)); email != "" {
+		local := email
+		if at := strings.Index(email, "@"); at >= 0 {
+			local = email[:at]
+		}
+		emailTokens = append(append(emailTokens, email), search.Words(local)...)
+	}
+
+	phoneTokens := []string{}
+	if phone := search.Digits(patient.Phone); phone != "" {
+		phoneTokens = append(phoneTokens, phone)
+		for _, length := range []int{7, 4} {
+			if len(phone) > length {
+				phoneTokens = append(phoneTokens, phone[len(phone)-length:])
+			}
+		}
+	}
+
+	mrnTokens := search.Words(patient.MRN)
+	for _, token := range mrnTokens {
+		if trimmed := strings.TrimLeft(token, "0"); search.IsNumber(token) && trimmed != "" && trimmed != token {
+			mrnTokens = append(mrnTokens, trimmed)
+		}
+	}
+
+	return []search.Field{
+		{Name: "last_name", Tokens: search.Words(patient.LastName), Fuzzy: true, Weight: 1},
+		{Name: "first_name", Tokens: search.Words(patient.FirstName), Fuzzy: true, Weight: 0.9},
+		{Name: "mrn", Tokens: mrnTokens, Weight: 1},
+		{Name: "email", Tokens: emailTokens, Weight: 0.8},
+		{Name: "phone", Tokens: phoneTokens, Weight: 0.8},
+	}
+}
+
+// likePrefix builds a LIKE pattern matching values that start with s.
+func likePrefix(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s) + "%"
+}
@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"healthcare-portal/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type ProblemRepository interface {
+	WithTenant(tenantID uint) ProblemRepository
+	Create(problem *models.Problem) error
+	FindByID(id uint) (*models.Problem, error)
+	FindByPatientID(patientID uint, status models.ProblemStatus) ([]models.Problem, error)
+	Update(problem *models.Problem) error
+	Delete(id uint) error
+	FindAppointmentDiagnoses(appointmentID uint) ([]models.AppointmentDiagnosis, error)
+	ReplaceAppointmentDiagnoses(appointmentID uint, diagnoses []models.AppointmentDiagnosis) error
+}
+
+type problemRepository struct {
+	db *gorm.DB
+}
+
+func NewProblemRepository(db *gorm.DB) ProblemRepository {
+	return &problemRepository{db: requireTenant(db)}
+}
+
+// WithTenant returns a repository limited to tenantID's rows.
+func (r *problemRepository) WithTenant(tenantID uint) ProblemRepository {
+	return &problemRepository{db: scopeToTenant(r.db, tenantID)}
+}
+
+func (r *problemRepository) Create(problem *models.Problem) error {
+	return r.db.Create(problem).Error
+}
+
+func (r *problemRepository) FindByID(id uint) (*models.Problem, error) {
+	var problem models.Problem
+	err := r.db.First(&problem, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &problem, nil
+}
+
+// FindByPatientID returns the patient's problems, active first. An empty
+// status returns every problem.
+func (r *problemRepository) FindByPatientID(patientID uint, status models.ProblemStatus) ([]models.Problem, error) {
+	var problems []models.Problem
+	query := r.db.Where("patient_id = ?", patientID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	err := query.Order("status ASC, onset_date DESC, code ASC").Find(&problems).Error
+	return problems, err
+}
+
+func (r *problemRepository) Update(problem *models.Problem) error {
+	return r.db.Save(problem).Error
+}
+
+func (r *problemRepository) Delete(id uint) error {
+	return r.db.Delete(&models.Problem{}, id).Error
+}
+
+func (r *problemRepository) FindAppointmentDiagnoses(appointmentID uint) ([]models.AppointmentDiagnosis, error) {
+	var diagnoses []models.AppointmentDiagnosis
+	err := r.db.Where("appointment_id = ?", appointmentID).
+		Order("rank ASC").
+		Find(&diagnoses).Error
+	return diagnoses, err
+}
+
+// ReplaceAppointmentDiagnoses swaps the appointment's diagnoses for the given
+// list in one transaction.
+func (r *problemRepository) ReplaceAppointmentDiagnoses(appointmentID uint, diagnoses []models.AppointmentDiagnosis) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("appointment_id = ?", appointmentID).Delete(&models.AppointmentDiagnosis{}).Error; err != nil {
+			return err
+		}
+		if len(diagnoses) == 0 {
+			return nil
+		}
+		return tx.Create(&diagnoses).Error
+	})
+}
@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"healthcare-portal/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type ReferralRepository interface {
+	WithTenant(tenantID uint) ReferralRepository
+	Create(referral *models.Referral) error
+	FindByID(id uint) (*models.Referral, error)
+	FindByPatientID(patientID uint) ([]models.Referral, error)
+	FindIncoming(doctorID uint, specialty string, status models.ReferralStatus) ([]models.Referral, error)
+	FindOutgoing(doctorID uint, status models.ReferralStatus) ([]models.Referral, error)
+	Update(referral *models.Referral) error
+}
+
+type referralRepository struct {
+	db *gorm.DB
+}
+
+func NewReferralRepository(db *gorm.DB) ReferralRepository {
+	return &referralRepository{db: requireTenant(db)}
+}
+
+// WithTenant returns a repository limited to tenantID's rows.
+func (r *referralRepository) WithTenant(tenantID uint) ReferralRepository {
+	return &referralRepository{db: scopeToTenant(r.db, tenantID)}
+}
+
+// Create stores the referral together with its attachments.
+func (r *referralRepository) Create(referral *models.Referral) error {
+	return r.db.Omit("Patient", "ReferringDoctor", "ReceivingDoctor", "Appointment", "Attachments.Document").Create(referral).Error
+}
+
+func (r *referralRepository) FindByID(id uint) (*models.Referral, error) {
+	var referral models.Referral
+	err := r.db.Preload("Patient").Preload("ReferringDoctor").Preload("ReceivingDoctor").
+		Preload("Appointment").Preload("Attachments.Document").
+		First(&referral, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &referral, nil
+}
+
+func (r *referralRepository) FindByPatientID(patientID uint) ([]models.Referral, error) {
+	var referrals []models.Referral
+	err := r.db.Preload("ReferringDoctor").Preload("ReceivingDoctor").
+		Where("patient_id = ?", patientID).
+		Order("created_at DESC").
+		Find(&referrals).Error
+	return referrals, err
+}
+
+// FindIncoming returns referrals addressed to the doctor, plus open
+// referrals addressed to the doctor's specialty that nobody has taken yet.
+// Urgent referrals come first. An empty status returns every status.
+func (r *referralRepository) FindIncoming(doctorID uint, specialty string, status models.ReferralStatus) ([]models.Referral, error) {
+	var referrals []models.Referral
+	query := r.db.Preload("Patient").Preload("ReferringDoctor")
+	if specialty != "" {
+		query = query.Where("receiving_doctor_id = ? OR (receiving_doctor_id IS NULL AND specialty = ?)", doctorID, specialty)
+	} else {
+		query = query.Where("receiving_doctor_id = ?", doctorID)
+	}
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	err := query.Order(urgencyOrder).Order("created_at ASC").Find(&referrals).Error
+	return referrals, err
+}
+
+// FindOutgoing returns the referrals the doctor has sent, newest first.
+func (r *referralRepository) FindOutgoing(doctorID uint, status models.ReferralStatus) ([]models.Referral, error) {
+	var referrals []models.Referral
+	query := r.db.Preload("Patient").Preload("ReceivingDoctor").Where("referring_doctor_id = ?", doctorID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	err := query.Order("created_at DESC").Find(&referrals).Error
+	return referrals, err
+}
+
+func (r *referralRepository) Update(referral *models.Referral) error {
+	return r.db.Omit("Patient", "ReferringDoctor", "ReceivingDoctor", "Appointment", "Attachments").Save(referral).Error
+}
+
+const urgencyOrder = "CASE urgency WHEN 'emergent' THEN 0 WHEN 'urgent' THEN 1 ELSE 2 END"
@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"healthcare-portal/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type RelatedPersonRepository interface {
+	WithTenant(tenantID uint) RelatedPersonRepository
+	Create(person *models.RelatedPerson) error
+	FindByID(id uint) (*models.RelatedPerson, error)
+	FindByPatientID(patientID uint) ([]models.RelatedPerson, error)
+	CountGuardians(patientID uint) (int64, error)
+	Update(person *models.RelatedPerson) error
+	Delete(id uint) error
+}
+
+type relatedPersonRepository struct {
+	db *gorm.DB
+}
+
+func NewRelatedPersonRepository(db *gorm.DB) RelatedPersonRepository {
+	return &relatedPersonRepository{db: requireTenant(db)}
+}
+
+// WithTenant returns a repository limited to tenantID's rows.
+func (r *relatedPersonRepository) WithTenant(tenantID uint) RelatedPersonRepository {
+	return &relatedPersonRepository{db: scopeToTenant(r.db, tenantID)}
+}
+
+func (r *relatedPersonRepository) Create(person *models.RelatedPerson) error {
+	return r.db.Create(person).Error
+}
+
+func (r *relatedPersonRepository) FindByID(id uint) (*models.RelatedPerson, error) {
+	var person models.RelatedPerson
+	err := r.db.First(&person, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &person, nil
+}
+
+// FindByPatientID returns the patient's contacts, guardians first.
+func (r *relatedPersonRepository) FindByPatientID(patientID uint) ([]models.RelatedPerson, error) {
+	var persons []models.RelatedPerson
+	err := r.db.Where("patient_id = ?", patientID).
+		Order("is_guardian DESC, is_emergency_contact DESC, id ASC").
+		Find(&persons).Error
+	return persons, err
+}
+
+func (r *relatedPersonRepository) CountGuardians(patientID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.RelatedPerson{}).Where("patient_id = ? AND is_guardian = ?", patientID, true).Count(&count).Error
+	return count, err
+}
+
+func (r *relatedPersonRepository) Update(person *models.RelatedPerson) error {
+	return r.db.Save(person).Error
+}
+
+func (r *relatedPersonRepository) Delete(id uint) error {
+	return r.db.Delete(&models.RelatedPerson{}, id).Error
+}
@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"healthcare-portal/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type ResourceRepository interface {
+	WithTenant(tenantID uint) ResourceRepository
+	Create(resource *models.Resource) error
+	FindAll() ([]models.Resource, error)
+	FindByID(id uint) (*models.Resource, error)
+	FindByCapability(capability string, locationID *uint) ([]models.Resource, error)
+	Update(resource *models.Resource) error
+	ReplaceCapabilities(resourceID uint, capabilities []string) error
+	Delete(id uint) error
+}
+
+type resourceRepository struct {
+	db *gorm.DB
+}
+
+func NewResourceRepository(db *gorm.DB) ResourceRepository {
+	return &resourceRepository{db: requireTenant(db)}
+}
+
+// WithTenant returns a repository limited to tenantID's rows.
+func (r *resourceRepository) WithTenant(tenantID uint) ResourceRepository {
+	return &resourceRepository{db: scopeToTenant(r.db, tenantID)}
+}
+
+func (r *resourceRepository) Create(resource *models.Resource) error {
+	return r.db.Create(resource).Error
+}
+
+func (r *resourceRepository) FindAll() ([]models.Resource, error) {
+	var resources []models.Resource
+	err := r.db.Preload("Capabilities").Order("name ASC").Find(&resources).Error
+	return resources, err
+}
+
+func (r *resourceRepository) FindByID(id uint) (*models.Resource, error) {
+	var resource models.Resource
+	err := r.db.Preload("Capabilities").First(&resource, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &resource, nil
+}
+
+// FindByCapability returns the active resources offering a capability,
+// ordered by ID so allocation is deterministic. When locationID is set only
+// resources at that location, or not tied to any location, are returned.
+func (r *resourceRepository) FindByCapability(capability string, locationID *uint) ([]models.Resource, error) {
+	var resources []models.Resource
+	query := r.db.Preload("Capabilities").
+		Where("is_active = ?", true).
+		Where("id IN (?)", r.db.Model(&models.ResourceCapability{}).Select("resource_id").Where("capability = ?", capability))
+	if locationID != nil {
+		query = query.Where("location_id = ? OR location_id IS NULL", *locationID)
+	}
+	err := query.Order("id ASC").Find(&resources).Error
+	return resources, err
+}
+
+func (r *resourceRepository) Update(resource *models.Resource) error {
+	return r.db.Omit("Capabilities").Save(resource).Error
+}
+
+func (r *resourceRepository) ReplaceCapabilities(resourceID uint, capabilities []string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("resource_id = ?", resourceID).Delete(&models.ResourceCapability{}).Error; err != nil {
+			return err
+		}
+		for _, capability := range capabilities {
+			if err := tx.Create(&models.ResourceCapability{ResourceID: resourceID, Capability: capability}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *resourceRepository) Delete(id uint) error {
+	return r.db.Delete(&models.Resource{}, id).Error
+}
@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"healthcare-portal/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type TenantRepository interface {
+	Create(tenant *models.Tenant) error
+	FindByID(id uint) (*models.Tenant, error)
+	FindBySlug(slug string) (*models.Tenant, error)
+	FindAll() ([]models.Tenant, error)
+}
+
+type tenantRepository struct {
+	db *gorm.DB
+}
+
+func NewTenantRepository(db *gorm.DB) TenantRepository {
+	return &tenantRepository{db: db}
+}
+
+func (r *tenantRepository) Create(tenant *models.Tenant) error {
+	return r.db.Create(tenant).Error
+}
+
+func (r *tenantRepository) FindByID(id uint) (*models.Tenant, error) {
+	var tenant models.Tenant
+	err := r.db.First(&tenant, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &tenant, nil
+}
+
+func (r *tenantRepository) FindBySlug(slug string) (*models.Tenant, error) {
+	var tenant models.Tenant
+	err := r.db.Where("slug = ?", slug).First(&tenant).Error
+	if err != nil {
+		return nil, err
+	}
+	return &tenant, nil
+}
+
+// FindAll returns every tenant, oldest first.
+func (r *tenantRepository) FindAll() ([]models.Tenant, error) {
+	var tenants []models.Tenant
+	err := r.db.Order("id ASC").Find(&tenants).Error
+	return tenants, err
+}
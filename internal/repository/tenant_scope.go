@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"errors"
+	"reflect"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrTenantRequired is returned when a repository reads or writes
+// tenant-owned rows before a tenant has been chosen with WithTenant.
+var ErrTenantRequired = errors.New("no tenant chosen for tenant-owned data")
+
+// tenantSetting marks a handle whose tenant has been chosen, either one
+// tenant or all of them.
+const tenantSetting = "repository:tenant"
+
+// TenantScope restricts a statement to rows owned by tenantID. It only
+// applies to models with a TenantID field, so the same scoped handle can be
+// used for child tables such as resource_capabilities. Structs written
+// through the scope without a tenant are stamped with tenantID.
+func TenantScope(tenantID uint) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if !tenantOwned(db) {
+			return db
+		}
+
+		stampTenant(db.Statement.Dest, tenantID)
+		return db.Where(clause.Eq{
+			Column: clause.Column{Table: clause.CurrentTable, Name: "tenant_id"},
+			Value:  tenantID,
+		})
+	}
+}
+
+// scopeToTenant returns a reusable handle whose every statement is limited
+// to tenantID.
+func scopeToTenant(db *gorm.DB, tenantID uint) *gorm.DB {
+	return db.Set(tenantSetting, tenantID).Scopes(TenantScope(tenantID)).Session(&gorm.Session{})
+}
+
+// requireTenant returns a reusable handle whose statements on tenant-owned
+// models fail with ErrTenantRequired until a tenant is chosen, so a
+// repository that was never scoped cannot reach every practice's rows.
+func requireTenant(db *gorm.DB) *gorm.DB {
+	return db.Scopes(failWithoutTenant).Session(&gorm.Session{})
+}
+
+// allTenants returns a reusable handle that reaches every tenant's rows.
+// It is only for background jobs that work across practices.
+func allTenants(db *gorm.DB) *gorm.DB {
+	return db.Set(tenantSetting, "all").Session(&gorm.Session{})
+}
+
+func failWithoutTenant(db *gorm.DB) *gorm.DB {
+	if _, ok := db.Get(tenantSetting); ok || !tenantOwned(db) {
+		return db
+	}
+	db.AddError(ErrTenantRequired)
+	return db
+}
+
+// tenantOwned reports whether the statement's model has a TenantID field.
+func tenantOwned(db *gorm.DB) bool {
+	model := db.Statement.Model
+	if model == nil {
+		model = db.Statement.Dest
+	}
+	if model == nil {
+		return false
+	}
+	if err := db.Statement.Parse(model); err != nil {
+		return false
+	}
+	return db.Statement.Schema.LookUpField("TenantID") != nil
+}
+
+func stampTenant(dest interface{}, tenantID uint) {
+	value := reflect.ValueOf(dest)
+	for value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return
+		}
+		value = value.Elem()
+	}
+
+	switch value.Kind() {
+	case reflect.Struct:
+		setTenantField(value, tenantID)
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			item := value.Index(i)
+			for item.Kind() == reflect.Ptr && !item.IsNil() {
+				item = item.Elem()
+			}
+			if item.Kind() == reflect.Struct {
+				setTenantField(item, tenantID)
+			}
+		}
+	}
+}
+
+func setTenantField(value reflect.Value, tenantID uint) {
+	field := value.FieldByName("TenantID")
+	if field.IsValid() && field.CanSet() && field.Kind() == reflect.Uint && field.Uint() == 0 {
+		field.SetUint(uint64(tenantID))
+	}
+}
@@ -6,6 +6,8 @@ import (
 )
 
 type UserRepository interface {
+    WithTenant(tenantID uint) UserRepository
+    AllTenants() UserRepository
     Create(user *models.User) error
     FindByEmail(email string) (*models.User, error)
     FindByID(id uint) (*models.User, error)
@@ -19,7 +21,18 @@ type userRepository struct {
 }
 
 func NewUserRepository(db *gorm.DB) UserRepository {
-    return &userRepository{db: db}
+    return &userRepository{db: requireTenant(db)}
+}
+
+// WithTenant returns a repository limited to tenantID's rows.
+func (r *userRepository) WithTenant(tenantID uint) UserRepository {
+    return &userRepository{db: scopeToTenant(r.db, tenantID)}
+}
+
+// AllTenants returns a repository that reaches every tenant's users, for
+// signing in before the practice is known.
+func (r *userRepository) AllTenants() UserRepository {
+    return &userRepository{db: allTenants(r.db)}
 }
 
 func (r *userRepository) Create(user *models.User) error {
@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"time"
+
+	"healthcare-portal/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type VitalsRepository interface {
+	WithTenant(tenantID uint) VitalsRepository
+	Create(vitals *models.VitalSigns) error
+	FindByID(id uint) (*models.VitalSigns, error)
+	FindByPatientID(patientID uint, from, to *time.Time) ([]models.VitalSigns, error)
+	FindByAppointmentID(appointmentID uint) ([]models.VitalSigns, error)
+	FindLatestHeight(patientID uint) (*float64, error)
+	Delete(id uint) error
+}
+
+type vitalsRepository struct {
+	db *gorm.DB
+}
+
+func NewVitalsRepository(db *gorm.DB) VitalsRepository {
+	return &vitalsRepository{db: requireTenant(db)}
+}
+
+// WithTenant returns a repository limited to tenantID's rows.
+func (r *vitalsRepository) WithTenant(tenantID uint) VitalsRepository {
+	return &vitalsRepository{db: scopeToTenant(r.db, tenantID)}
+}
+
+func (r *vitalsRepository) Create(vitals *models.VitalSigns) error {
+	return r.db.Create(vitals).Error
+}
+
+func (r *vitalsRepository) FindByID(id uint) (*models.VitalSigns, error) {
+	var vitals models.VitalSigns
+	err := r.db.First(&vitals, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &vitals, nil
+}
+
+// FindByPatientID returns the patient's vitals oldest first. from and to are
+// inclusive bounds on RecordedAt and may be nil.
+func (r *vitalsRepository) FindByPatientID(patientID uint, from, to *time.Time) ([]models.VitalSigns, error) {
+	var vitals []models.VitalSigns
+	query := r.db.Where("patient_id = ?", patientID)
+	if from != nil {
+		query = query.Where("recorded_at >= ?", *from)
+	}
+	if to != nil {
+		query = query.Where("recorded_at <= ?", *to)
+	}
+	err := query.Order("recorded_at ASC").Find(&vitals).Error
+	return vitals, err
+}
+
+func (r *vitalsRepository) FindByAppointmentID(appointmentID uint) ([]models.VitalSigns, error) {
+	var vitals []models.VitalSigns
+	err := r.db.Where("appointment_id = ?", appointmentID).
+		Order("recorded_at ASC").
+		Find(&vitals).Error
+	return vitals, err
+}
+
+// FindLatestHeight returns the patient's most recently recorded height, or
+// nil when none has been taken.
+func (r *vitalsRepository) FindLatestHeight(patientID uint) (*float64, error) {
+	var vitals []models.VitalSigns
+	err := r.db.Where("patient_id = ? AND height_cm IS NOT NULL", patientID).
+		Order("recorded_at DESC").
+		Limit(1).
+		Find(&vitals).Error
+	if err != nil || len(vitals) == 0 {
+		return nil, err
+	}
+	return vitals[0].HeightCm, nil
+}
+
+func (r *vitalsRepository) Delete(id uint) error {
+	return r.db.Delete(&models.VitalSigns{}, id).Error
+}
@@ -0,0 +1,181 @@
+package search
+
+import (
+	"math"
+	"strings"
+)
+
+type TermKind string
+
+const (
+	TermWord   TermKind = "word"
+	TermNumber TermKind = "number"
+	TermEmail  TermKind = "email"
+)
+
+// Term is one normalised word of a search query.
+type Term struct {
+	Text string
+	Kind TermKind
+}
+
+// Fuzzy reports whether the term may match misspelt words. Short words and
+// numbers only match exactly or as a prefix.
+func (t Term) Fuzzy() bool {
+	return t.Kind == TermWord && len([]rune(t.Text)) >= 3 && isAlpha(t.Text)
+}
+
+// ParseQuery splits a free-text query into terms. Anything containing "@"
+// is kept whole as an email address; everything else is split into words.
+func ParseQuery(query string) []Term {
+	terms := []Term{}
+	seen := make(map[string]bool)
+	add := func(term Term) {
+		if term.Text != "" && !seen[term.Text] {
+			seen[term.Text] = true
+			terms = append(terms, term)
+		}
+	}
+
+	for _, part := range strings.Fields(query) {
+		if strings.Contains(part, "@") {
+			add(Term{Text: Fold(strings.Trim(part, "<>,;\"'")), Kind: TermEmail})
+			continue
+		}
+		for _, word := range Words(part) {
+			if IsNumber(word) {
+				add(Term{Text: word, Kind: TermNumber})
+			} else {
+				add(Term{Text: word, Kind: TermWord})
+			}
+		}
+	}
+	return terms
+}
+
+// Field is one searchable value of a record. Tokens are its folded words;
+// Fuzzy fields such as names also match misspellings. Weight scales the
+// score of matches in the field.
+type Field struct {
+	Name   string
+	Tokens []string
+	Fuzzy  bool
+	Weight float64
+}
+
+// Index kinds of the terms stored for a record.
+const (
+	IndexWord     = "word"
+	IndexPhonetic = "phonetic"
+	IndexTrigram  = "trigram"
+)
+
+// IndexTerm is a value a record is found by.
+type IndexTerm struct {
+	Kind string
+	Term string
+}
+
+// IndexTerms returns the distinct terms to store for a record with fields:
+// every token for exact and prefix lookups, and for fuzzy fields the
+// Soundex codes and trigrams of the alphabetic tokens.
+func IndexTerms(fields []Field) []IndexTerm {
+	seen := make(map[IndexTerm]bool)
+	terms := []IndexTerm{}
+	add := func(kind, term string) {
+		key := IndexTerm{Kind: kind, Term: term}
+		if term != "" && !seen[key] {
+			seen[key] = true
+			terms = append(terms, key)
+		}
+	}
+
+	for _, field := range fields {
+		for _, token := range field.Tokens {
+			add(IndexWord, token)
+			if !field.Fuzzy || !isAlpha(token) {
+				continue
+			}
+			add(IndexPhonetic, Soundex(token))
+			for _, trigram := range Trigrams(token) {
+				add(IndexTrigram, trigram)
+			}
+		}
+	}
+	return terms
+}
+
+const (
+	// MinSimilarity is the trigram similarity from which a misspelt word
+	// still counts as a match.
+	MinSimilarity = 0.35
+
+	exactScore    = 1.0
+	prefixScore   = 0.85
+	phoneticScore = 0.7
+)
+
+// MinSharedTrigrams is the number of a term's trigrams a word must share
+// with it to possibly reach MinSimilarity. Index lookups use it to discard
+// hopeless candidates before scoring.
+func MinSharedTrigrams(term Term) int {
+	return int(math.Ceil(MinSimilarity * float64(len(Trigrams(term.Text)))))
+}
+
+// Score rates how well a record with fields matches every term. Each term
+// scores its best match over all fields, weighted by the field; the result
+// is the mean over the terms, between 0 and 1. ok is false when any term
+// matches nothing, since all terms of a query must match. matched lists the
+// fields that matched, in field order.
+func Score(terms []Term, fields []Field) (score float64, matched []string, ok bool) {
+	if len(terms) == 0 {
+		return 0, nil, false
+	}
+
+	hits := make(map[string]bool)
+	total := 0.0
+	for _, term := range terms {
+		best, bestField := 0.0, ""
+		for _, field := range fields {
+			for _, token := range field.Tokens {
+				if s := matchScore(term, token, field.Fuzzy) * field.Weight; s > best {
+					best, bestField = s, field.Name
+				}
+			}
+		}
+		if best == 0 {
+			return 0, nil, false
+		}
+		hits[bestField] = true
+		total += best
+	}
+
+	for _, field := range fields {
+		if hits[field.Name] {
+			matched = append(matched, field.Name)
+			delete(hits, field.Name)
+		}
+	}
+	return total / float64(len(terms)), matched, true
+}
+
+// matchScore rates a single term against a single token.
+func matchScore(term Term, token string, fuzzy bool) float64 {
+	switch {
+	case token == term.Text:
+		return exactScore
+	case strings.HasPrefix(token, term.Text):
+		return prefixScore
+	case !fuzzy || !term.Fuzzy() || !isAlpha(token):
+		return 0
+	}
+
+	best := Similarity(term.Text, token)
+	if best < MinSimilarity {
+		best = 0
+	}
+	if Soundex(term.Text) == Soundex(token) && phoneticScore > best {
+		best = phoneticScore
+	}
+	return best
+}
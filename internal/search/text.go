@@ -0,0 +1,157 @@
+// Package search holds the text handling behind typo-tolerant record
+// search: normalising and tokenising text, phonetic keys, trigrams and the
+// scoring of query terms against a record's fields. It has no database
+// code; repositories store the index terms it produces in ordinary tables,
+// so the same search runs on Postgres and on SQLite in tests.
+package search
+
+import (
+	"strings"
+	"unicode"
+)
+
+// foldings maps accented Latin letters to their plain form so "José"
+// matches "Jose".
+var foldings = map[rune]string{
+	'à': "a", 'á': "a", 'â': "a", 'ã': "a", 'ä': "a", 'å': "a", 'æ': "ae",
+	'ç': "c", 'è': "e", 'é': "e", 'ê': "e", 'ë': "e",
+	'ì': "i", 'í': "i", 'î': "i", 'ï': "i", 'ñ': "n",
+	'ò': "o", 'ó': "o", 'ô': "o", 'õ': "o", 'ö': "o", 'ø': "o", 'œ': "oe",
+	'ù': "u", 'ú': "u", 'û': "u", 'ü': "u", 'ý': "y", 'ÿ': "y", 'ß': "ss",
+}
+
+// Fold lower-cases s and strips accents from Latin letters.
+func Fold(s string) string {
+	var folded strings.Builder
+	for _, r := range strings.ToLower(s) {
+		if plain, ok := foldings[r]; ok {
+			folded.WriteString(plain)
+			continue
+		}
+		folded.WriteRune(r)
+	}
+	return folded.String()
+}
+
+// Words splits s into folded words of letters and digits. Apostrophes are
+// dropped rather than split on, so "O'Brien" is the single word "obrien";
+// hyphens and other punctuation separate words.
+func Words(s string) []string {
+	s = strings.NewReplacer("'", "", "’", "").Replace(Fold(s))
+	return strings.FieldsFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// Digits returns the digits of s.
+func Digits(s string) string {
+	var digits strings.Builder
+	for _, r := range s {
+		if r >= '0' && r <= '9' {
+			digits.WriteRune(r)
+		}
+	}
+	return digits.String()
+}
+
+// IsNumber reports whether word is made of digits only.
+func IsNumber(word string) bool {
+	return word != "" && strings.Trim(word, "0123456789") == ""
+}
+
+// isAlpha reports whether word is made of letters only.
+func isAlpha(word string) bool {
+	for _, r := range word {
+		if !unicode.IsLetter(r) {
+			return false
+		}
+	}
+	return word != ""
+}
+
+// soundexCodes are the digit classes of American Soundex. Vowels and H, W,
+// Y have no code.
+var soundexCodes = map[rune]byte{
+	'b': '1', 'f': '1', 'p': '1', 'v': '1',
+	'c': '2', 'g': '2', 'j': '2', 'k': '2', 'q': '2', 's': '2', 'x': '2', 'z': '2',
+	'd': '3', 't': '3',
+	'l': '4',
+	'm': '5', 'n': '5',
+	'r': '6',
+}
+
+// Soundex returns the four character American Soundex code of an
+// alphabetic word, e.g. J525 for both "Johnson" and "Jonson", or "" for
+// words that are not plain ASCII letters.
+func Soundex(word string) string {
+	word = Fold(word)
+	if word == "" {
+		return ""
+	}
+	for _, r := range word {
+		if r < 'a' || r > 'z' {
+			return ""
+		}
+	}
+
+	code := []byte{byte(unicode.ToUpper(rune(word[0])))}
+	last := soundexCodes[rune(word[0])]
+	for _, r := range word[1:] {
+		digit, coded := soundexCodes[r]
+		switch {
+		case coded && digit != last:
+			code = append(code, digit)
+			if len(code) == 4 {
+				return string(code)
+			}
+			last = digit
+		case r != 'h' && r != 'w':
+			// Vowels separate letters of the same class; H and W do not.
+			last = digit
+		}
+	}
+	for len(code) < 4 {
+		code = append(code, '0')
+	}
+	return string(code)
+}
+
+// Trigrams returns the distinct three letter sequences of word, padded the
+// way Postgres' pg_trgm does: two spaces in front and one behind, so short
+// words and word starts carry weight.
+func Trigrams(word string) []string {
+	runes := []rune("  " + Fold(word) + " ")
+	if len(runes) < 4 {
+		return nil
+	}
+	seen := make(map[string]bool)
+	trigrams := []string{}
+	for i := 0; i+3 <= len(runes); i++ {
+		trigram := string(runes[i : i+3])
+		if !seen[trigram] {
+			seen[trigram] = true
+			trigrams = append(trigrams, trigram)
+		}
+	}
+	return trigrams
+}
+
+// Similarity is the share of trigrams two words have in common, from 0 for
+// nothing in common to 1 for the same word.
+func Similarity(a, b string) float64 {
+	left, right := Trigrams(a), Trigrams(b)
+	if len(left) == 0 || len(right) == 0 {
+		return 0
+	}
+	inLeft := make(map[string]bool, len(left))
+	for _, trigram := range left {
+		inLeft[trigram] = true
+	}
+	shared := 0
+	for _, trigram := range right {
+		if inLeft[trigram] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(left)+len(right)-shared)
+}
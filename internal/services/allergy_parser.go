@@ -0,0 +1,175 @@
+package services
+
+import (
+	"strings"
+
+	"healthcare-portal/internal/models"
+)
+
+// noAllergyPhrases are free-text values that mean the patient has no known
+// allergies.
+var noAllergyPhrases = map[string]bool{
+	"none": true, "nil": true, "no": true, "n/a": true, "na": true,
+	"nka": true, "nkda": true, "no known allergies": true, "no known drug allergies": true,
+}
+
+// allergyCategoryKeywords maps word prefixes of common substances to a
+// category; the first match wins. Anything unrecognised is treated as a
+// medication so it is not missed by prescribing checks; staff confirm or
+// correct the category anyway.
+var allergyCategoryKeywords = []struct {
+	keyword  string
+	category models.AllergyCategory
+}{
+	{"peanut", models.AllergyFood}, {"nut", models.AllergyFood}, {"shellfish", models.AllergyFood},
+	{"shrimp", models.AllergyFood}, {"fish", models.AllergyFood}, {"egg", models.AllergyFood},
+	{"milk", models.AllergyFood}, {"dairy", models.AllergyFood}, {"lactose", models.AllergyFood},
+	{"wheat", models.AllergyFood}, {"gluten", models.AllergyFood}, {"soy", models.AllergyFood},
+	{"sesame", models.AllergyFood}, {"strawberr", models.AllergyFood}, {"beef", models.AllergyFood},
+	{"dust", models.AllergyEnvironment}, {"pollen", models.AllergyEnvironment}, {"grass", models.AllergyEnvironment},
+	{"mold", models.AllergyEnvironment}, {"mould", models.AllergyEnvironment}, {"dander", models.AllergyEnvironment},
+	{"cat", models.AllergyEnvironment}, {"dog", models.AllergyEnvironment}, {"latex", models.AllergyEnvironment},
+	{"bee", models.AllergyEnvironment}, {"wasp", models.AllergyEnvironment}, {"sting", models.AllergyEnvironment},
+	{"vaccine", models.AllergyBiologic}, {"serum", models.AllergyBiologic}, {"blood", models.AllergyBiologic},
+}
+
+var allergySeverityKeywords = []struct {
+	keyword  string
+	severity models.AllergySeverity
+}{
+	{"anaphyla", models.SeveritySevere},
+	{"severe", models.SeveritySevere},
+	{"moderate", models.SeverityModerate},
+	{"mild", models.SeverityMild},
+}
+
+// ParseLegacyAllergies turns the free-text Patient.Allergies column into
+// candidate entries, e.g. "Penicillin (rash), Peanuts - severe anaphylaxis".
+// Entries are separated by commas, semicolons, slashes, new lines or "and";
+// text in parentheses or after a dash or colon becomes the reaction.
+func ParseLegacyAllergies(text string) []models.Allergy {
+	var allergies []models.Allergy
+	seen := make(map[string]bool)
+
+	for _, part := range splitLegacyList(text) {
+		entry := strings.Trim(strings.TrimSpace(part), ".")
+		if entry == "" || noAllergyPhrases[strings.ToLower(entry)] {
+			continue
+		}
+
+		substance, reaction := splitReaction(entry)
+		severity := models.SeverityUnknown
+		for _, candidate := range allergySeverityKeywords {
+			if strings.Contains(strings.ToLower(entry), candidate.keyword) {
+				severity = candidate.severity
+				break
+			}
+		}
+		substance = stripSeverityWords(substance)
+		if substance == "" || seen[strings.ToLower(substance)] {
+			continue
+		}
+		seen[strings.ToLower(substance)] = true
+
+		allergies = append(allergies, models.Allergy{
+			Substance:    substance,
+			Category:     categorizeSubstance(substance),
+			Reaction:     reaction,
+			Severity:     severity,
+			Status:       models.AllergyActive,
+			Verification: models.AllergyUnconfirmed,
+			Source:       models.AllergySourceLegacy,
+			Notes:        "Imported from: " + entry,
+		})
+	}
+	return allergies
+}
+
+// splitLegacyList splits on list separators outside parentheses.
+func splitLegacyList(text string) []string {
+	var parts []string
+	var current strings.Builder
+	depth := 0
+
+	flush := func() {
+		parts = append(parts, current.String())
+		current.Reset()
+	}
+
+	for _, r := range text {
+		switch {
+		case r == '(':
+			depth++
+		case r == ')' && depth > 0:
+			depth--
+		case depth == 0 && (r == ',' || r == ';' || r == '/' || r == '\n'):
+			flush()
+			continue
+		}
+		current.WriteRune(r)
+	}
+	flush()
+
+	var split []string
+	for _, part := range parts {
+		split = append(split, splitOnAnd(part)...)
+	}
+	return split
+}
+
+func splitOnAnd(part string) []string {
+	if strings.Contains(part, "(") {
+		return []string{part}
+	}
+	var split []string
+	for _, piece := range strings.Split(part, " and ") {
+		split = append(split, strings.Split(piece, " AND ")...)
+	}
+	return split
+}
+
+func splitReaction(entry string) (substance, reaction string) {
+	if open := strings.Index(entry, "("); open >= 0 {
+		closing := strings.LastIndex(entry, ")")
+		if closing < open {
+			closing = len(entry)
+		}
+		reaction = strings.TrimSpace(entry[open+1 : closing])
+		rest := entry[:open]
+		if closing < len(entry) {
+			rest += entry[closing+1:]
+		}
+		return strings.TrimSpace(rest), reaction
+	}
+	for _, separator := range []string{" - ", ": ", ":", " – "} {
+		if index := strings.Index(entry, separator); index > 0 {
+			return strings.TrimSpace(entry[:index]), strings.TrimSpace(entry[index+len(separator):])
+		}
+	}
+	return entry, ""
+}
+
+func stripSeverityWords(substance string) string {
+	words := strings.Fields(substance)
+	kept := words[:0]
+	for _, word := range words {
+		switch strings.ToLower(word) {
+		case "severe", "moderate", "mild", "allergy", "allergic", "to":
+			continue
+		}
+		kept = append(kept, word)
+	}
+	return strings.Join(kept, " ")
+}
+
+func categorizeSubstance(substance string) models.AllergyCategory {
+	words := strings.Fields(strings.ToLower(substance))
+	for _, candidate := range allergyCategoryKeywords {
+		for _, word := range words {
+			if strings.HasPrefix(word, candidate.keyword) {
+				return candidate.category
+			}
+		}
+	}
+	return models.AllergyMedication
+}
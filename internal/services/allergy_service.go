@@ -0,0 +1,177 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"healthcare-portal/internal/models"
+	"healthcare-portal/internal/repository"
+)
+
+type AllergyService interface {
+	WithTenant(tenantID uint) AllergyService
+	CreateAllergy(allergy *models.Allergy) error
+	GetAllergyByID(id uint) (*models.Allergy, error)
+	GetPatientAllergies(patientID uint) ([]models.Allergy, error)
+	UpdateAllergy(allergy *models.Allergy) error
+	ConfirmAllergy(id, confirmedBy uint) (*models.Allergy, error)
+	DeleteAllergy(id uint) error
+	ImportLegacyAllergies(patientID, recordedBy uint) ([]models.Allergy, error)
+}
+
+type allergyService struct {
+	allergyRepo repository.AllergyRepository
+	patientRepo repository.PatientRepository
+}
+
+func NewAllergyService(allergyRepo repository.AllergyRepository, patientRepo repository.PatientRepository) AllergyService {
+	return &allergyService{
+		allergyRepo: allergyRepo,
+		patientRepo: patientRepo,
+	}
+}
+
+// WithTenant returns a service limited to tenantID's patients.
+func (s *allergyService) WithTenant(tenantID uint) AllergyService {
+	return &allergyService{
+		allergyRepo: s.allergyRepo.WithTenant(tenantID),
+		patientRepo: s.patientRepo.WithTenant(tenantID),
+	}
+}
+
+// CreateAllergy records an allergy entered by staff. Entries made directly
+// are confirmed by the person recording them.
+func (s *allergyService) CreateAllergy(allergy *models.Allergy) error {
+	if _, err := s.patientRepo.FindByID(allergy.PatientID); err != nil {
+		return errors.New("patient not found")
+	}
+	if err := normalizeAllergy(allergy); err != nil {
+		return err
+	}
+
+	existing, err := s.allergyRepo.FindByPatientID(allergy.PatientID)
+	if err != nil {
+		return err
+	}
+	for _, other := range existing {
+		if strings.EqualFold(other.Substance, allergy.Substance) {
+			return errors.New("allergy to this substance is already recorded")
+		}
+	}
+
+	now := time.Now()
+	allergy.Source = models.AllergySourceEntered
+	allergy.Verification = models.AllergyConfirmed
+	allergy.ConfirmedBy = &allergy.RecordedBy
+	allergy.ConfirmedAt = &now
+	return s.allergyRepo.Create(allergy)
+}
+
+func (s *allergyService) GetAllergyByID(id uint) (*models.Allergy, error) {
+	return s.allergyRepo.FindByID(id)
+}
+
+func (s *allergyService) GetPatientAllergies(patientID uint) ([]models.Allergy, error) {
+	if _, err := s.patientRepo.FindByID(patientID); err != nil {
+		return nil, errors.New("patient not found")
+	}
+	return s.allergyRepo.FindByPatientID(patientID)
+}
+
+func (s *allergyService) UpdateAllergy(allergy *models.Allergy) error {
+	if err := normalizeAllergy(allergy); err != nil {
+		return err
+	}
+	return s.allergyRepo.Update(allergy)
+}
+
+// ConfirmAllergy marks a candidate entry as reviewed by staff. Candidates
+// that turn out to be wrong are deleted instead.
+func (s *allergyService) ConfirmAllergy(id, confirmedBy uint) (*models.Allergy, error) {
+	allergy, err := s.allergyRepo.FindByID(id)
+	if err != nil {
+		return nil, errors.New("allergy not found")
+	}
+	if allergy.Verification == models.AllergyConfirmed {
+		return nil, errors.New("allergy is already confirmed")
+	}
+
+	now := time.Now()
+	allergy.Verification = models.AllergyConfirmed
+	allergy.ConfirmedBy = &confirmedBy
+	allergy.ConfirmedAt = &now
+	if err := s.allergyRepo.Update(allergy); err != nil {
+		return nil, err
+	}
+	return allergy, nil
+}
+
+func (s *allergyService) DeleteAllergy(id uint) error {
+	return s.allergyRepo.Delete(id)
+}
+
+// ImportLegacyAllergies parses the patient's free-text allergies into
+// unconfirmed entries. Substances already on the list are skipped, so the
+// import can be run more than once.
+func (s *allergyService) ImportLegacyAllergies(patientID, recordedBy uint) ([]models.Allergy, error) {
+	patient, err := s.patientRepo.FindByID(patientID)
+	if err != nil {
+		return nil, errors.New("patient not found")
+	}
+
+	existing, err := s.allergyRepo.FindByPatientID(patientID)
+	if err != nil {
+		return nil, err
+	}
+	known := make(map[string]bool, len(existing))
+	for _, allergy := range existing {
+		known[strings.ToLower(allergy.Substance)] = true
+	}
+
+	var imported []models.Allergy
+	for _, candidate := range ParseLegacyAllergies(patient.Allergies) {
+		if known[strings.ToLower(candidate.Substance)] {
+			continue
+		}
+		candidate.PatientID = patient.ID
+		candidate.RecordedBy = recordedBy
+		if err := s.allergyRepo.Create(&candidate); err != nil {
+			return imported, err
+		}
+		imported = append(imported, candidate)
+	}
+	return imported, nil
+}
+
+func normalizeAllergy(allergy *models.Allergy) error {
+	allergy.Substance = strings.TrimSpace(allergy.Substance)
+	if allergy.Substance == "" {
+		return errors.New("substance is required")
+	}
+
+	switch allergy.Category {
+	case models.AllergyMedication, models.AllergyFood, models.AllergyEnvironment, models.AllergyBiologic:
+	default:
+		return errors.New("category must be medication, food, environment or biologic")
+	}
+
+	if allergy.Severity == "" {
+		allergy.Severity = models.SeverityUnknown
+	}
+	switch allergy.Severity {
+	case models.SeverityMild, models.SeverityModerate, models.SeveritySevere, models.SeverityUnknown:
+	default:
+		return errors.New("severity must be mild, moderate, severe or unknown")
+	}
+
+	if allergy.Status == "" {
+		allergy.Status = models.AllergyActive
+	}
+	switch allergy.Status {
+	case models.AllergyActive, models.AllergyInactive, models.AllergyResolved:
+	default:
+		return errors.New("status must be active, inactive or resolved")
+	}
+	return nil
+}
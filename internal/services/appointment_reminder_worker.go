@@ -0,0 +1,136 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"healthcare-portal/internal/models"
+	"healthcare-portal/internal/repository"
+)
+
+// AppointmentReminderWorker periodically reminds patients of the next
+// day's appointments, across all tenants. A reminder only goes out over a
+// channel the patient has a current consent for: sms_reminders for their
+// phone and email_reminders for their email address. Patients who have not
+// consented are left alone and checked again on the next sweep, so a
+// consent granted later in the day still gets them reminded.
+type AppointmentReminderWorker struct {
+	appointmentRepo repository.AppointmentRepository
+	consents        ConsentChecker
+	notifier        Notifier
+	interval        time.Duration
+}
+
+// NewAppointmentReminderWorker builds the worker. A non-positive interval
+// falls back to hourly sweeps.
+func NewAppointmentReminderWorker(appointmentRepo repository.AppointmentRepository, consents ConsentChecker, notifier Notifier, interval time.Duration) *AppointmentReminderWorker {
+	if interval <= 0 {
+		interval = time.Hour
+	}
+	return &AppointmentReminderWorker{appointmentRepo: appointmentRepo, consents: consents, notifier: notifier, interval: interval}
+}
+
+// ReminderSweep summarises one sweep.
+type ReminderSweep struct {
+	Sent    int
+	Skipped int
+}
+
+// Sweep reminds patients of scheduled appointments on the day after now
+// that have not had a reminder yet.
+func (w *AppointmentReminderWorker) Sweep(now time.Time) (ReminderSweep, error) {
+	var result ReminderSweep
+	tomorrow := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, time.UTC)
+	appointments, err := w.appointmentRepo.FindDueForReminder(tomorrow)
+	if err != nil {
+		return result, err
+	}
+
+	for i := range appointments {
+		appointment := &appointments[i]
+		sent, err := w.remind(appointment)
+		if err != nil {
+			log.Printf("Appointment reminder for appointment %d failed: %v", appointment.ID, err)
+		}
+		if !sent {
+			result.Skipped++
+			continue
+		}
+		if err := w.appointmentRepo.MarkReminderSent(appointment.ID, now); err != nil {
+			return result, err
+		}
+		result.Sent++
+	}
+	return result, nil
+}
+
+// remind sends the appointment's reminder over every channel the patient
+// consents to and reports whether any of them went out.
+func (w *AppointmentReminderWorker) remind(appointment *models.Appointment) (bool, error) {
+	patient := appointment.Patient
+	if patient == nil {
+		return false, nil
+	}
+
+	channels := []struct {
+		channel     NotificationChannel
+		consentType models.ConsentType
+		to          string
+	}{
+		{ChannelSMS, models.ConsentSMSReminders, patient.Phone},
+		{ChannelEmail, models.ConsentEmailReminders, patient.Email},
+	}
+
+	sent := false
+	message := reminderMessage(appointment)
+	for _, c := range channels {
+		if c.to == "" {
+			continue
+		}
+		allowed, err := w.consents.HasConsent(patient.ID, c.consentType)
+		if err != nil {
+			return sent, err
+		}
+		if !allowed {
+			continue
+		}
+		if err := w.notifier.Send(c.channel, c.to, message); err != nil {
+			return sent, err
+		}
+		sent = true
+	}
+	return sent, nil
+}
+
+// reminderMessage words the reminder without clinical details, since SMS
+// and email are not private channels.
+func reminderMessage(appointment *models.Appointment) string {
+	message := fmt.Sprintf("Reminder: you have an appointment on %s at %s", appointment.Date.Format("Mon 2 Jan"), appointment.Time)
+	if appointment.Location != nil && appointment.Location.Name != "" {
+		message += " at " + appointment.Location.Name
+	}
+	return message + "."
+}
+
+// Run sweeps once immediately and then every interval until ctx is done.
+func (w *AppointmentReminderWorker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		result, err := w.Sweep(time.Now())
+		if err != nil {
+			log.Printf("Appointment reminder sweep failed: %v", err)
+		} else if result.Sent > 0 || result.Skipped > 0 {
+			log.Printf("Appointment reminder sweep: %d sent, %d skipped", result.Sent, result.Skipped)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -2,60 +2,156 @@ package services
 
 import (
     "errors"
+    "fmt"
+    "sort"
     "time"
     
+    "healthcare-portal/internal/config"
     "healthcare-portal/internal/models"
+    "healthcare-portal/internal/query"
     "healthcare-portal/internal/repository"
 )
 
 type AppointmentService interface {
+    WithTenant(tenantID uint) AppointmentService
     CreateAppointment(appointment *models.Appointment) error
     GetAppointmentByID(id uint) (*models.Appointment, error)
-    GetAllAppointments(limit, offset int) ([]models.Appointment, int64, error)
-    GetAppointmentsByDate(date time.Time) ([]models.Appointment, error)
+    ListAppointments(req query.Request) (*query.Page[models.Appointment], error)
+    GetAppointmentsByDate(date time.Time, locationID *uint) ([]models.Appointment, error)
     GetPatientAppointments(patientID uint) ([]models.Appointment, error)
     GetDoctorAppointments(doctorID uint) ([]models.Appointment, error)
     UpdateAppointmentStatus(id uint, status models.AppointmentStatus) error
+    CheckInAppointment(id uint) (*models.Appointment, error)
     DeleteAppointment(id uint) error
     CheckDoctorAvailability(doctorID uint, date time.Time, timeSlot string) (bool, error)
+    CheckPatientAvailability(patientID uint, date time.Time, timeSlot string) (bool, error)
+    FindAvailableSlots(query models.SlotQuery) ([]models.AvailableSlot, error)
 }
 
 type appointmentService struct {
-    appointmentRepo repository.AppointmentRepository
-    patientRepo     repository.PatientRepository
-    userRepo        repository.UserRepository
+    appointmentRepo     repository.AppointmentRepository
+    patientRepo         repository.PatientRepository
+    userRepo            repository.UserRepository
+    resourceRepo        repository.ResourceRepository
+    appointmentTypeRepo repository.AppointmentTypeRepository
+    locationRepo        repository.LocationRepository
+    scheduleRepo        repository.DoctorScheduleRepository
+    scheduling          config.SchedulingConfig
+    charger             AppointmentCharger
 }
 
-func NewAppointmentService(appointmentRepo repository.AppointmentRepository, patientRepo repository.PatientRepository, userRepo repository.UserRepository) AppointmentService {
+func NewAppointmentService(appointmentRepo repository.AppointmentRepository, patientRepo repository.PatientRepository, userRepo repository.UserRepository, resourceRepo repository.ResourceRepository, appointmentTypeRepo repository.AppointmentTypeRepository, locationRepo repository.LocationRepository, scheduleRepo repository.DoctorScheduleRepository, scheduling config.SchedulingConfig, charger AppointmentCharger) AppointmentService {
     return &appointmentService{
-        appointmentRepo: appointmentRepo,
-        patientRepo:     patientRepo,
-        userRepo:        userRepo,
+        appointmentRepo:     appointmentRepo,
+        patientRepo:         patientRepo,
+        userRepo:            userRepo,
+        resourceRepo:        resourceRepo,
+        appointmentTypeRepo: appointmentTypeRepo,
+        locationRepo:        locationRepo,
+        scheduleRepo:        scheduleRepo,
+        scheduling:          scheduling,
+        charger:             charger,
     }
 }
 
+// WithTenant returns a service whose lookups and bookings are limited to
+// tenantID.
+func (s *appointmentService) WithTenant(tenantID uint) AppointmentService {
+    return &appointmentService{
+        appointmentRepo:     s.appointmentRepo.WithTenant(tenantID),
+        patientRepo:         s.patientRepo.WithTenant(tenantID),
+        userRepo:            s.userRepo.WithTenant(tenantID),
+        resourceRepo:        s.resourceRepo.WithTenant(tenantID),
+        appointmentTypeRepo: s.appointmentTypeRepo.WithTenant(tenantID),
+        locationRepo:        s.locationRepo.WithTenant(tenantID),
+        scheduleRepo:        s.scheduleRepo.WithTenant(tenantID),
+        scheduling:          s.scheduling,
+        charger:             s.charger,
+    }
+}
+
+// CreateAppointment books the appointment only if the doctor is scheduled at
+// the location and the doctor, the patient and every resource required by the
+// appointment type are free for the whole duration of the visit.
 func (s *appointmentService) CreateAppointment(appointment *models.Appointment) error {
-    // Check if doctor is available
-    available, err := s.CheckDoctorAvailability(appointment.DoctorID, appointment.Date, appointment.Time)
+    appointmentType, err := s.resolveAppointmentType(appointment.AppointmentTypeID)
     if err != nil {
         return err
     }
-    if !available {
-        return errors.New("doctor is not available at this time")
+
+    if appointment.DurationMinutes <= 0 {
+        appointment.DurationMinutes = models.DefaultAppointmentMinutes
+        if appointmentType != nil {
+            appointment.DurationMinutes = appointmentType.DurationMinutes
+        }
+    }
+
+    start, end, ok := appointment.Window()
+    if !ok {
+        return errors.New("invalid time format, expected HH:MM")
     }
-    
-    return s.appointmentRepo.Create(appointment)
+
+    // Both lookups go through the tenant-scoped repositories, so a patient
+    // or doctor from another practice is reported as not found.
+    if _, err := s.patientRepo.FindByID(appointment.PatientID); err != nil {
+        return errors.New("patient not found")
+    }
+    doctor, err := s.userRepo.FindByID(appointment.DoctorID)
+    if err != nil || doctor.Role != models.RoleDoctor {
+        return errors.New("doctor not found")
+    }
+
+    if err := s.bindLocation(appointment, start, end); err != nil {
+        return err
+    }
+
+    if appointmentType != nil {
+        if !appointmentType.AllowsSpecialty(doctor.Specialty) {
+            return errors.New("doctor's specialty is not allowed for this appointment type")
+        }
+    }
+
+    sameDay, err := s.appointmentRepo.FindByDate(appointment.Date)
+    if err != nil {
+        return err
+    }
+
+    booked := overlapping(sameDay, start, end)
+    for _, existing := range booked {
+        if existing.DoctorID == appointment.DoctorID {
+            return errors.New("doctor is not available at this time")
+        }
+        if existing.PatientID == appointment.PatientID {
+            return errors.New("patient already has an appointment at this time")
+        }
+    }
+
+    candidates, err := s.resourceCandidates(appointmentType, appointment.LocationID)
+    if err != nil {
+        return err
+    }
+    resourceIDs, err := pickResources(appointmentType, candidates, booked)
+    if err != nil {
+        return err
+    }
+
+    return s.appointmentRepo.CreateWithResources(appointment, resourceIDs)
 }
 
 func (s *appointmentService) GetAppointmentByID(id uint) (*models.Appointment, error) {
     return s.appointmentRepo.FindByID(id)
 }
 
-func (s *appointmentService) GetAllAppointments(limit, offset int) ([]models.Appointment, int64, error) {
-    return s.appointmentRepo.FindAll(limit, offset)
+// ListAppointments returns one page of appointments, filtered and sorted as
+// req asks.
+func (s *appointmentService) ListAppointments(req query.Request) (*query.Page[models.Appointment], error) {
+    return s.appointmentRepo.List(req)
 }
 
-func (s *appointmentService) GetAppointmentsByDate(date time.Time) ([]models.Appointment, error) {
+func (s *appointmentService) GetAppointmentsByDate(date time.Time, locationID *uint) ([]models.Appointment, error) {
+    if locationID != nil {
+        return s.appointmentRepo.FindByDateAtLocation(date, *locationID)
+    }
     return s.appointmentRepo.FindByDate(date)
 }
 
@@ -67,8 +163,59 @@ func (s *appointmentService) GetDoctorAppointments(doctorID uint) ([]models.Appo
     return s.appointmentRepo.FindByDoctorID(doctorID)
 }
 
+// UpdateAppointmentStatus sets the appointment's status. Completing an
+// appointment charges the patient for it in the same transaction, so an
+// appointment is never left completed without its charge. A completed
+// appointment has been billed and cannot move to another status.
 func (s *appointmentService) UpdateAppointmentStatus(id uint, status models.AppointmentStatus) error {
-    return s.appointmentRepo.UpdateStatus(id, status)
+    return s.appointmentRepo.Transaction(func(appointmentRepo repository.AppointmentRepository, billingRepo repository.BillingRepository) error {
+        appointment, err := appointmentRepo.FindByID(id)
+        if err != nil {
+            return errors.New("appointment not found")
+        }
+        if appointment.Status == models.StatusCompleted && status != models.StatusCompleted {
+            return fmt.Errorf("cannot change a completed appointment to %s", status)
+        }
+        if err := appointmentRepo.UpdateStatus(id, status); err != nil {
+            return err
+        }
+        if status != models.StatusCompleted {
+            return nil
+        }
+        appointment.Status = status
+        _, err = s.charger.WithRepository(billingRepo).ChargeAppointment(appointment)
+        return err
+    })
+}
+
+// CheckInAppointment marks the patient as arrived for a scheduled
+// appointment so nurses can take vitals before the doctor sees them.
+func (s *appointmentService) CheckInAppointment(id uint) (*models.Appointment, error) {
+    appointment, err := s.appointmentRepo.FindByID(id)
+    if err != nil {
+        return nil, errors.New("appointment not found")
+    }
+    if err := checkIn(s.appointmentRepo, appointment); err != nil {
+        return nil, err
+    }
+    return appointment, nil
+}
+
+// checkIn moves a scheduled appointment to checked_in. Appointments that are
+// already checked in are left alone.
+func checkIn(appointmentRepo repository.AppointmentRepository, appointment *models.Appointment) error {
+    switch appointment.Status {
+    case models.StatusCheckedIn:
+        return nil
+    case models.StatusScheduled:
+    default:
+        return fmt.Errorf("cannot check in a %s appointment", appointment.Status)
+    }
+    if err := appointmentRepo.UpdateStatus(appointment.ID, models.StatusCheckedIn); err != nil {
+        return err
+    }
+    appointment.Status = models.StatusCheckedIn
+    return nil
 }
 
 func (s *appointmentService) DeleteAppointment(id uint) error {
@@ -76,16 +223,383 @@ func (s *appointmentService) DeleteAppointment(id uint) error {
 }
 
 func (s *appointmentService) CheckDoctorAvailability(doctorID uint, date time.Time, timeSlot string) (bool, error) {
+    return s.isFree(date, timeSlot, func(appointment models.Appointment) bool {
+        return appointment.DoctorID == doctorID
+    })
+}
+
+func (s *appointmentService) CheckPatientAvailability(patientID uint, date time.Time, timeSlot string) (bool, error) {
+    return s.isFree(date, timeSlot, func(appointment models.Appointment) bool {
+        return appointment.PatientID == patientID
+    })
+}
+
+// FindAvailableSlots lists the start times on a date at which an appointment
+// of the given type could be booked. Doctors are limited to the requested one
+// and to the specialties allowed by the type, and are only offered inside
+// their schedule blocks (or the clinic day when they have no schedule). Slots
+// where the patient is already booked, the required resources are taken or
+// that have already passed in the location's time zone are left out.
+func (s *appointmentService) FindAvailableSlots(query models.SlotQuery) ([]models.AvailableSlot, error) {
+    appointmentType, err := s.resolveAppointmentType(query.AppointmentTypeID)
+    if err != nil {
+        return nil, err
+    }
+
+    duration := models.DefaultAppointmentMinutes
+    if appointmentType != nil {
+        duration = appointmentType.DurationMinutes
+    }
+
+    doctors, err := s.eligibleDoctors(appointmentType, query.DoctorID)
+    if err != nil {
+        return nil, err
+    }
+
+    dayStart, dayEnd, err := s.clinicDay()
+    if err != nil {
+        return nil, err
+    }
+    step := s.scheduling.SlotMinutes
+    if step <= 0 {
+        step = 15
+    }
+
+    if query.LocationID != nil {
+        if _, err := s.activeLocation(*query.LocationID); err != nil {
+            return nil, err
+        }
+    }
+
+    sameDay, err := s.appointmentRepo.FindByDate(query.Date)
+    if err != nil {
+        return nil, err
+    }
+
+    candidatesByLocation := make(map[uint]map[string][]models.Resource)
+    slots := []models.AvailableSlot{}
+    for _, doctor := range doctors {
+        windows, err := s.workingWindows(doctor.ID, query.Date, query.LocationID, dayStart, dayEnd)
+        if err != nil {
+            return nil, err
+        }
+
+        for _, window := range windows {
+            var key uint
+            if window.locationID != nil {
+                key = *window.locationID
+            }
+            candidates, cached := candidatesByLocation[key]
+            if !cached {
+                if candidates, err = s.resourceCandidates(appointmentType, window.locationID); err != nil {
+                    return nil, err
+                }
+                candidatesByLocation[key] = candidates
+            }
+
+            earliest, err := s.earliestStart(query.Date, window.locationID)
+            if err != nil {
+                return nil, err
+            }
+
+            for start := window.start; start+duration <= window.end; start += step {
+                if start < earliest {
+                    continue
+                }
+                booked := overlapping(sameDay, start, start+duration)
+                if hasAppointment(booked, func(a models.Appointment) bool { return a.DoctorID == doctor.ID }) {
+                    continue
+                }
+                if query.PatientID != nil && hasAppointment(booked, func(a models.Appointment) bool { return a.PatientID == *query.PatientID }) {
+                    continue
+                }
+                if _, err := pickResources(appointmentType, candidates, booked); err != nil {
+                    continue
+                }
+
+                slots = append(slots, models.AvailableSlot{
+                    DoctorID:        doctor.ID,
+                    DoctorName:      doctor.Name,
+                    LocationID:      window.locationID,
+                    Time:            fmt.Sprintf("%02d:%02d", start/60, start%60),
+                    DurationMinutes: duration,
+                })
+            }
+        }
+    }
+
+    sort.SliceStable(slots, func(i, j int) bool {
+        if slots[i].Time != slots[j].Time {
+            return slots[i].Time < slots[j].Time
+        }
+        return slots[i].DoctorID < slots[j].DoctorID
+    })
+    return slots, nil
+}
+
+// workWindow is a block of minutes after midnight during which a doctor sees
+// patients, optionally at a specific location.
+type workWindow struct {
+    start, end int
+    locationID *uint
+}
+
+// workingWindows returns the doctor's blocks on date. Doctors without any
+// schedule are assumed to work the whole clinic day.
+func (s *appointmentService) workingWindows(doctorID uint, date time.Time, locationID *uint, dayStart, dayEnd int) ([]workWindow, error) {
+    count, err := s.scheduleRepo.CountByDoctor(doctorID)
+    if err != nil {
+        return nil, err
+    }
+    if count == 0 {
+        return []workWindow{{start: dayStart, end: dayEnd, locationID: locationID}}, nil
+    }
+
+    schedules, err := s.scheduleRepo.FindByDoctorAndDay(doctorID, date.Weekday())
+    if err != nil {
+        return nil, err
+    }
+
+    var windows []workWindow
+    for _, schedule := range schedules {
+        if locationID != nil && schedule.LocationID != *locationID {
+            continue
+        }
+        start, end, ok := schedule.Window()
+        if !ok {
+            continue
+        }
+        scheduledAt := schedule.LocationID
+        windows = append(windows, workWindow{start: start, end: end, locationID: &scheduledAt})
+    }
+    return windows, nil
+}
+
+// earliestStart returns the first minute of date that has not yet passed in
+// the location's time zone. Dates other than today there start at midnight.
+func (s *appointmentService) earliestStart(date time.Time, locationID *uint) (int, error) {
+    if locationID == nil {
+        return 0, nil
+    }
+    location, err := s.locationRepo.FindByID(*locationID)
+    if err != nil {
+        return 0, errors.New("location not found")
+    }
+
+    now := time.Now().In(location.Zone())
+    if now.Year() != date.Year() || now.Month() != date.Month() || now.Day() != date.Day() {
+        return 0, nil
+    }
+    return now.Hour()*60 + now.Minute() + 1, nil
+}
+
+// bindLocation checks that the doctor is scheduled to work at the
+// appointment's location for the whole visit. When no location was given and
+// the doctor's schedule covers the visit, the scheduled location is used.
+func (s *appointmentService) bindLocation(appointment *models.Appointment, start, end int) error {
+    if appointment.LocationID != nil {
+        if _, err := s.activeLocation(*appointment.LocationID); err != nil {
+            return err
+        }
+    }
+
+    count, err := s.scheduleRepo.CountByDoctor(appointment.DoctorID)
+    if err != nil {
+        return err
+    }
+    if count == 0 {
+        return nil
+    }
+
+    schedules, err := s.scheduleRepo.FindByDoctorAndDay(appointment.DoctorID, appointment.Date.Weekday())
+    if err != nil {
+        return err
+    }
+    for _, schedule := range schedules {
+        blockStart, blockEnd, ok := schedule.Window()
+        if !ok || start < blockStart || end > blockEnd {
+            continue
+        }
+        if appointment.LocationID == nil {
+            scheduledAt := schedule.LocationID
+            appointment.LocationID = &scheduledAt
+            return nil
+        }
+        if schedule.LocationID == *appointment.LocationID {
+            return nil
+        }
+    }
+
+    if appointment.LocationID != nil {
+        return errors.New("doctor is not scheduled at this location at this time")
+    }
+    return errors.New("doctor is not scheduled at this time")
+}
+
+func (s *appointmentService) activeLocation(id uint) (*models.Location, error) {
+    location, err := s.locationRepo.FindByID(id)
+    if err != nil {
+        return nil, errors.New("location not found")
+    }
+    if !location.IsActive {
+        return nil, errors.New("location is not active")
+    }
+    return location, nil
+}
+
+func (s *appointmentService) isFree(date time.Time, timeSlot string, matches func(models.Appointment) bool) (bool, error) {
+    candidate := models.Appointment{Time: timeSlot}
+    start, end, ok := candidate.Window()
+    if !ok {
+        return false, errors.New("invalid time format, expected HH:MM")
+    }
+
     appointments, err := s.appointmentRepo.FindByDate(date)
     if err != nil {
         return false, err
     }
-    
+
+    return !hasAppointment(overlapping(appointments, start, end), matches), nil
+}
+
+func (s *appointmentService) resolveAppointmentType(id *uint) (*models.AppointmentType, error) {
+    if id == nil {
+        return nil, nil
+    }
+
+    appointmentType, err := s.appointmentTypeRepo.FindByID(*id)
+    if err != nil {
+        return nil, errors.New("appointment type not found")
+    }
+    if !appointmentType.IsActive {
+        return nil, errors.New("appointment type is not active")
+    }
+    return appointmentType, nil
+}
+
+func (s *appointmentService) eligibleDoctors(appointmentType *models.AppointmentType, doctorID *uint) ([]models.User, error) {
+    var doctors []models.User
+    if doctorID != nil {
+        doctor, err := s.userRepo.FindByID(*doctorID)
+        if err != nil || doctor.Role != models.RoleDoctor {
+            return nil, errors.New("doctor not found")
+        }
+        doctors = []models.User{*doctor}
+    } else {
+        var err error
+        if doctors, err = s.userRepo.FindByRole(models.RoleDoctor); err != nil {
+            return nil, err
+        }
+    }
+
+    eligible := make([]models.User, 0, len(doctors))
+    for _, doctor := range doctors {
+        if !doctor.IsActive {
+            continue
+        }
+        if appointmentType != nil && !appointmentType.AllowsSpecialty(doctor.Specialty) {
+            continue
+        }
+        eligible = append(eligible, doctor)
+    }
+    return eligible, nil
+}
+
+func (s *appointmentService) clinicDay() (start, end int, err error) {
+    open := models.Appointment{Time: s.scheduling.DayStart}
+    closing := models.Appointment{Time: s.scheduling.DayEnd}
+    start, _, okStart := open.Window()
+    end, _, okEnd := closing.Window()
+    if !okStart || !okEnd || end <= start {
+        return 0, 0, errors.New("invalid clinic day configuration")
+    }
+    return start, end, nil
+}
+
+// resourceCandidates loads, per required capability, the active resources
+// at the location that could satisfy the appointment type.
+func (s *appointmentService) resourceCandidates(appointmentType *models.AppointmentType, locationID *uint) (map[string][]models.Resource, error) {
+    candidates := make(map[string][]models.Resource)
+    if appointmentType == nil {
+        return candidates, nil
+    }
+
+    for _, requirement := range appointmentType.Requirements {
+        if _, loaded := candidates[requirement.Capability]; loaded {
+            continue
+        }
+        resources, err := s.resourceRepo.FindByCapability(requirement.Capability, locationID)
+        if err != nil {
+            return nil, err
+        }
+        candidates[requirement.Capability] = resources
+    }
+    return candidates, nil
+}
+
+// pickResources chooses free resources for every requirement of the
+// appointment type. Resources held by booked appointments are skipped.
+func pickResources(appointmentType *models.AppointmentType, candidates map[string][]models.Resource, booked []models.Appointment) ([]uint, error) {
+    if appointmentType == nil {
+        return nil, nil
+    }
+
+    inUse := make(map[uint]bool)
+    for _, existing := range booked {
+        for _, held := range existing.Resources {
+            inUse[held.ResourceID] = true
+        }
+    }
+
+    var allocated []uint
+    for _, requirement := range appointmentType.Requirements {
+        needed := requirement.Quantity
+        if needed <= 0 {
+            needed = 1
+        }
+        for _, candidate := range candidates[requirement.Capability] {
+            if needed == 0 {
+                break
+            }
+            if inUse[candidate.ID] {
+                continue
+            }
+            inUse[candidate.ID] = true
+            allocated = append(allocated, candidate.ID)
+            needed--
+        }
+        if needed > 0 {
+            return nil, fmt.Errorf("no %s resource is available at this time", requirement.Capability)
+        }
+    }
+
+    return allocated, nil
+}
+
+// overlapping returns the non-cancelled appointments whose window intersects
+// [start, end). Appointments with an unparseable time are skipped.
+func overlapping(appointments []models.Appointment, start, end int) []models.Appointment {
+    var active []models.Appointment
     for _, appointment := range appointments {
-        if appointment.DoctorID == doctorID && appointment.Time == timeSlot && appointment.Status != models.StatusCancelled {
-            return false, nil
+        if appointment.Status == models.StatusCancelled {
+            continue
+        }
+        otherStart, otherEnd, ok := appointment.Window()
+        if !ok {
+            continue
+        }
+        if otherStart < end && start < otherEnd {
+            active = append(active, appointment)
         }
     }
-    
-    return true, nil
-}
\ No newline at end of file
+    return active
+}
+
+func hasAppointment(appointments []models.Appointment, matches func(models.Appointment) bool) bool {
+    for _, appointment := range appointments {
+        if matches(appointment) {
+            return true
+        }
+    }
+    return false
+}
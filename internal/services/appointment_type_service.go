@@ -0,0 +1,164 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"healthcare-portal/internal/models"
+	"healthcare-portal/internal/repository"
+)
+
+type AppointmentTypeService interface {
+	WithTenant(tenantID uint) AppointmentTypeService
+	CreateAppointmentType(appointmentType *models.AppointmentType) error
+	GetAppointmentTypeByID(id uint) (*models.AppointmentType, error)
+	GetAllAppointmentTypes() ([]models.AppointmentType, error)
+	UpdateAppointmentType(appointmentType *models.AppointmentType, requirements []models.AppointmentTypeRequirement, specialties []string) error
+	DeleteAppointmentType(id uint) error
+	GetReport(from, to time.Time) ([]models.AppointmentTypeReport, error)
+}
+
+type appointmentTypeService struct {
+	appointmentTypeRepo repository.AppointmentTypeRepository
+	appointmentRepo     repository.AppointmentRepository
+}
+
+func NewAppointmentTypeService(appointmentTypeRepo repository.AppointmentTypeRepository, appointmentRepo repository.AppointmentRepository) AppointmentTypeService {
+	return &appointmentTypeService{
+		appointmentTypeRepo: appointmentTypeRepo,
+		appointmentRepo:     appointmentRepo,
+	}
+}
+
+// WithTenant returns a service limited to tenantID's catalog and appointments.
+func (s *appointmentTypeService) WithTenant(tenantID uint) AppointmentTypeService {
+	return &appointmentTypeService{
+		appointmentTypeRepo: s.appointmentTypeRepo.WithTenant(tenantID),
+		appointmentRepo:     s.appointmentRepo.WithTenant(tenantID),
+	}
+}
+
+func (s *appointmentTypeService) CreateAppointmentType(appointmentType *models.AppointmentType) error {
+	if err := validateAppointmentType(appointmentType); err != nil {
+		return err
+	}
+
+	requirements, err := normalizeRequirements(appointmentType.Requirements)
+	if err != nil {
+		return err
+	}
+	appointmentType.Requirements = requirements
+
+	var specialties []string
+	for _, specialty := range appointmentType.Specialties {
+		specialties = append(specialties, specialty.Specialty)
+	}
+	appointmentType.Specialties = nil
+	for _, specialty := range normalizeSpecialties(specialties) {
+		appointmentType.Specialties = append(appointmentType.Specialties, models.AppointmentTypeSpecialty{Specialty: specialty})
+	}
+
+	return s.appointmentTypeRepo.Create(appointmentType)
+}
+
+func (s *appointmentTypeService) GetAppointmentTypeByID(id uint) (*models.AppointmentType, error) {
+	return s.appointmentTypeRepo.FindByID(id)
+}
+
+func (s *appointmentTypeService) GetAllAppointmentTypes() ([]models.AppointmentType, error) {
+	return s.appointmentTypeRepo.FindAll()
+}
+
+// UpdateAppointmentType saves the type and replaces its resource
+// requirements and allowed specialties when they are non-nil.
+func (s *appointmentTypeService) UpdateAppointmentType(appointmentType *models.AppointmentType, requirements []models.AppointmentTypeRequirement, specialties []string) error {
+	if err := validateAppointmentType(appointmentType); err != nil {
+		return err
+	}
+
+	var normalized []models.AppointmentTypeRequirement
+	if requirements != nil {
+		var err error
+		if normalized, err = normalizeRequirements(requirements); err != nil {
+			return err
+		}
+	}
+
+	if err := s.appointmentTypeRepo.Update(appointmentType); err != nil {
+		return err
+	}
+	if requirements != nil {
+		if err := s.appointmentTypeRepo.ReplaceRequirements(appointmentType.ID, normalized); err != nil {
+			return err
+		}
+	}
+	if specialties != nil {
+		return s.appointmentTypeRepo.ReplaceSpecialties(appointmentType.ID, normalizeSpecialties(specialties))
+	}
+	return nil
+}
+
+func (s *appointmentTypeService) DeleteAppointmentType(id uint) error {
+	return s.appointmentTypeRepo.Delete(id)
+}
+
+func (s *appointmentTypeService) GetReport(from, to time.Time) ([]models.AppointmentTypeReport, error) {
+	if !to.After(from) {
+		return nil, errors.New("report end must be after its start")
+	}
+	return s.appointmentRepo.ReportByType(from, to)
+}
+
+func validateAppointmentType(appointmentType *models.AppointmentType) error {
+	appointmentType.Code = strings.ToLower(strings.TrimSpace(appointmentType.Code))
+	if appointmentType.Code == "" {
+		return errors.New("appointment type code is required")
+	}
+	if appointmentType.DurationMinutes <= 0 {
+		return errors.New("duration must be a positive number of minutes")
+	}
+	if appointmentType.PriceCents < 0 {
+		return errors.New("price cannot be negative")
+	}
+	appointmentType.ProcedureCode = strings.ToUpper(strings.TrimSpace(appointmentType.ProcedureCode))
+	if appointmentType.ProcedureCode != "" && !procedureCodePattern.MatchString(appointmentType.ProcedureCode) {
+		return errors.New("procedure code must be a CPT or HCPCS code")
+	}
+	return nil
+}
+
+func normalizeRequirements(requirements []models.AppointmentTypeRequirement) ([]models.AppointmentTypeRequirement, error) {
+	normalized := make([]models.AppointmentTypeRequirement, 0, len(requirements))
+	for _, requirement := range requirements {
+		requirement.Capability = strings.ToLower(strings.TrimSpace(requirement.Capability))
+		if requirement.Capability == "" {
+			return nil, errors.New("requirement capability is required")
+		}
+		if requirement.Quantity <= 0 {
+			requirement.Quantity = 1
+		}
+		normalized = append(normalized, requirement)
+	}
+	return normalized, nil
+}
+
+// normalizeSpecialties lower-cases, trims and de-duplicates specialty names.
+// Doctors' specialties are normalized the same way on registration.
+func normalizeSpecialties(specialties []string) []string {
+	seen := make(map[string]bool)
+	normalized := make([]string, 0, len(specialties))
+	for _, specialty := range specialties {
+		specialty = normalizeSpecialty(specialty)
+		if specialty == "" || seen[specialty] {
+			continue
+		}
+		seen[specialty] = true
+		normalized = append(normalized, specialty)
+	}
+	return normalized
+}
+
+func normalizeSpecialty(specialty string) string {
+	return strings.ToLower(strings.TrimSpace(specialty))
+}
@@ -56,7 +56,7 @@ func (s *authService) Login(email, password string) (string, *models.User, error
 	}
 
 	// Generate JWT token
-	token, err := utils.GenerateJWT(user.ID, user.Email, string(user.Role))
+	token, err := utils.GenerateJWT(user.ID, user.Email, string(user.Role), user.TenantID)
 	if err != nil {
 		return "", nil, errors.New("failed to generate token")
 	}
@@ -75,10 +75,11 @@ func (s *authService) Register(user *models.User) error {
 		return errors.New("email already registered")
 	}
 
-	// Validate role
-	if user.Role != models.RoleReceptionist && user.Role != models.RoleDoctor {
+	// Validate role; admin accounts are provisioned by the seeder only
+	if user.Role != models.RoleReceptionist && user.Role != models.RoleDoctor && user.Role != models.RoleNurse {
 		return errors.New("invalid role")
 	}
+	user.Specialty = normalizeSpecialty(user.Specialty)
 
 	// Hash password
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(user.Password), bcrypt.DefaultCost)
@@ -124,7 +125,7 @@ func (s *authService) RefreshToken(userID uint) (string, error) {
 		return "", errors.New("user account is deactivated")
 	}
 
-	token, err := utils.GenerateJWT(user.ID, user.Email, string(user.Role))
+	token, err := utils.GenerateJWT(user.ID, user.Email, string(user.Role), user.TenantID)
 	if err != nil {
 		return "", errors.New("failed to generate token")
 	}
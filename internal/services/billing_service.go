@@ -0,0 +1,359 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"healthcare-portal/internal/config"
+	"healthcare-portal/internal/models"
+	"healthcare-portal/internal/repository"
+)
+
+var (
+	ErrChargeNotFound      = errors.New("charge not found")
+	ErrChargeNotPending    = errors.New("only pending charges can be changed")
+	ErrInvoiceNotFound     = errors.New("invoice not found")
+	ErrInvoiceVoid         = repository.ErrInvoiceVoid
+	ErrNothingToInvoice    = errors.New("the patient has no pending charges to invoice")
+	ErrInvoiceHasPayments  = repository.ErrInvoiceHasPayments
+	ErrPaymentExceedsOwed  = repository.ErrPaymentExceedsOwed
+	ErrRefundExceedsPaid   = repository.ErrRefundExceedsPaid
+	ErrChargesNotAvailable = repository.ErrChargesUnavailable
+)
+
+// AppointmentCharger bills an appointment when it is completed.
+// WithRepository returns a charger that writes through billingRepo, so the
+// charge can be made in the caller's transaction.
+type AppointmentCharger interface {
+	WithRepository(billingRepo repository.BillingRepository) AppointmentCharger
+	ChargeAppointment(appointment *models.Appointment) ([]models.Charge, error)
+}
+
+// InvoiceDraft describes an invoice to raise. Without ChargeIDs every
+// pending charge of the patient is invoiced. TaxRateBasisPoints overrides
+// the configured rate, and DueDate the configured payment terms.
+type InvoiceDraft struct {
+	PatientID          uint
+	ChargeIDs          []uint
+	DiscountCents      int64
+	DiscountReason     string
+	TaxRateBasisPoints *int
+	DueDate            *time.Time
+	Notes              string
+	CreatedBy          uint
+}
+
+type BillingService interface {
+	WithTenant(tenantID uint) BillingService
+	AppointmentCharger
+	CreateCharge(charge *models.Charge) error
+	GetPatientCharges(patientID uint, status models.ChargeStatus) ([]models.Charge, error)
+	VoidCharge(id uint, reason string) (*models.Charge, error)
+	CreateInvoice(draft InvoiceDraft) (*models.Invoice, error)
+	GetInvoiceByID(id uint) (*models.Invoice, error)
+	GetPatientInvoices(patientID uint) ([]models.Invoice, error)
+	VoidInvoice(id uint, reason string) (*models.Invoice, error)
+	RecordPayment(payment *models.Payment) (*models.Invoice, error)
+	GetPatientBalance(patientID uint) (*models.PatientBalance, error)
+}
+
+type billingService struct {
+	billingRepo repository.BillingRepository
+	patientRepo repository.PatientRepository
+	billing     config.BillingConfig
+}
+
+func NewBillingService(billingRepo repository.BillingRepository, patientRepo repository.PatientRepository, billing config.BillingConfig) BillingService {
+	return &billingService{billingRepo: billingRepo, patientRepo: patientRepo, billing: billing}
+}
+
+// WithTenant returns a service that only sees tenantID's charges, invoices
+// and payments.
+func (s *billingService) WithTenant(tenantID uint) BillingService {
+	return &billingService{
+		billingRepo: s.billingRepo.WithTenant(tenantID),
+		patientRepo: s.patientRepo.WithTenant(tenantID),
+		billing:     s.billing,
+	}
+}
+
+// WithRepository returns a service that reads and writes billing rows
+// through billingRepo.
+func (s *billingService) WithRepository(billingRepo repository.BillingRepository) AppointmentCharger {
+	return &billingService{billingRepo: billingRepo, patientRepo: s.patientRepo, billing: s.billing}
+}
+
+// ChargeAppointment charges a completed appointment at its appointment
+// type's price. Appointments without a type or with a free type are not
+// charged, and an appointment is only ever charged once, so completing it
+// again does nothing. Charges are made in the appointment's tenant.
+func (s *billingService) ChargeAppointment(appointment *models.Appointment) ([]models.Charge, error) {
+	if appointment.Status != models.StatusCompleted {
+		return nil, fmt.Errorf("cannot charge a %s appointment", appointment.Status)
+	}
+	appointmentType := appointment.AppointmentType
+	if appointmentType == nil || appointmentType.PriceCents <= 0 {
+		return nil, nil
+	}
+
+	charge := models.Charge{
+		PatientID:      appointment.PatientID,
+		AppointmentID:  &appointment.ID,
+		Code:           appointmentType.Code,
+		ProcedureCode:  appointmentType.ProcedureCode,
+		Description:    appointmentType.Name,
+		Quantity:       1,
+		UnitPriceCents: appointmentType.PriceCents,
+		AmountCents:    appointmentType.PriceCents,
+		ServiceDate:    appointment.Date,
+		Status:         models.ChargePending,
+	}
+	created, err := s.billingRepo.WithTenant(appointment.TenantID).CreateAppointmentCharge(&charge)
+	if err != nil || !created {
+		return nil, err
+	}
+	return []models.Charge{charge}, nil
+}
+
+// CreateCharge adds an item billed by staff, such as supplies.
+func (s *billingService) CreateCharge(charge *models.Charge) error {
+	if _, err := s.patientRepo.FindByID(charge.PatientID); err != nil {
+		return ErrPatientNotFound
+	}
+	charge.Code = strings.TrimSpace(charge.Code)
+	charge.Description = strings.TrimSpace(charge.Description)
+	if charge.Description == "" {
+		return errors.New("charge description is required")
+	}
+	if charge.Quantity == 0 {
+		charge.Quantity = 1
+	}
+	if charge.Quantity < 0 || charge.UnitPriceCents < 0 {
+		return errors.New("charge quantity and price cannot be negative")
+	}
+	if charge.ServiceDate.IsZero() {
+		charge.ServiceDate = time.Now()
+	}
+	charge.AmountCents = int64(charge.Quantity) * charge.UnitPriceCents
+	charge.Status = models.ChargePending
+	charge.AppointmentID = nil
+	charge.InvoiceID = nil
+	return s.billingRepo.CreateCharge(charge)
+}
+
+func (s *billingService) GetPatientCharges(patientID uint, status models.ChargeStatus) ([]models.Charge, error) {
+	if _, err := s.patientRepo.FindByID(patientID); err != nil {
+		return nil, ErrPatientNotFound
+	}
+	return s.billingRepo.FindChargesByPatientID(patientID, status)
+}
+
+// VoidCharge cancels a charge made in error. Charges already on an invoice
+// are released by voiding the invoice first.
+func (s *billingService) VoidCharge(id uint, reason string) (*models.Charge, error) {
+	charge, err := s.billingRepo.FindChargeByID(id)
+	if err != nil {
+		return nil, ErrChargeNotFound
+	}
+	if charge.Status != models.ChargePending {
+		return nil, ErrChargeNotPending
+	}
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, errors.New("a reason is required to void a charge")
+	}
+	charge.Status = models.ChargeVoided
+	charge.VoidReason = reason
+	if err := s.billingRepo.UpdateCharge(charge); err != nil {
+		return nil, err
+	}
+	return charge, nil
+}
+
+// CreateInvoice groups pending charges of one patient into an invoice.
+func (s *billingService) CreateInvoice(draft InvoiceDraft) (*models.Invoice, error) {
+	if _, err := s.patientRepo.FindByID(draft.PatientID); err != nil {
+		return nil, ErrPatientNotFound
+	}
+	pending, err := s.billingRepo.FindChargesByPatientID(draft.PatientID, models.ChargePending)
+	if err != nil {
+		return nil, err
+	}
+
+	charges := pending
+	if len(draft.ChargeIDs) > 0 {
+		byID := make(map[uint]models.Charge, len(pending))
+		for _, charge := range pending {
+			byID[charge.ID] = charge
+		}
+		charges = []models.Charge{}
+		seen := make(map[uint]bool)
+		for _, id := range draft.ChargeIDs {
+			charge, ok := byID[id]
+			if !ok {
+				return nil, ErrChargesNotAvailable
+			}
+			if !seen[id] {
+				seen[id] = true
+				charges = append(charges, charge)
+			}
+		}
+	}
+	if len(charges) == 0 {
+		return nil, ErrNothingToInvoice
+	}
+
+	taxRate := s.billing.TaxRateBasisPoints
+	if draft.TaxRateBasisPoints != nil {
+		taxRate = *draft.TaxRateBasisPoints
+	}
+	if taxRate < 0 {
+		return nil, errors.New("tax rate cannot be negative")
+	}
+
+	invoice := &models.Invoice{
+		PatientID:          draft.PatientID,
+		Status:             models.InvoiceOpen,
+		IssuedAt:           time.Now(),
+		DiscountCents:      draft.DiscountCents,
+		DiscountReason:     strings.TrimSpace(draft.DiscountReason),
+		TaxRateBasisPoints: taxRate,
+		Notes:              strings.TrimSpace(draft.Notes),
+		CreatedBy:          draft.CreatedBy,
+	}
+	chargeIDs := make([]uint, len(charges))
+	for i, charge := range charges {
+		chargeIDs[i] = charge.ID
+		invoice.SubtotalCents += charge.AmountCents
+	}
+	if invoice.DiscountCents < 0 || invoice.DiscountCents > invoice.SubtotalCents {
+		return nil, errors.New("discount must be between zero and the invoice subtotal")
+	}
+	if invoice.DiscountCents > 0 && invoice.DiscountReason == "" {
+		return nil, errors.New("a reason is required for a discount")
+	}
+	invoice.TaxCents = taxOn(invoice.SubtotalCents-invoice.DiscountCents, taxRate)
+	invoice.TotalCents = invoice.SubtotalCents - invoice.DiscountCents + invoice.TaxCents
+	invoice.BalanceCents = invoice.TotalCents
+	invoice.DueDate = invoice.IssuedAt.AddDate(0, 0, s.billing.PaymentTermsDays)
+	if draft.DueDate != nil {
+		invoice.DueDate = *draft.DueDate
+	}
+	settle(invoice)
+
+	if err := s.billingRepo.CreateInvoice(invoice, chargeIDs); err != nil {
+		return nil, err
+	}
+	return s.GetInvoiceByID(invoice.ID)
+}
+
+func (s *billingService) GetInvoiceByID(id uint) (*models.Invoice, error) {
+	invoice, err := s.billingRepo.FindInvoiceByID(id)
+	if err != nil {
+		return nil, ErrInvoiceNotFound
+	}
+	return invoice, nil
+}
+
+func (s *billingService) GetPatientInvoices(patientID uint) ([]models.Invoice, error) {
+	if _, err := s.patientRepo.FindByID(patientID); err != nil {
+		return nil, ErrPatientNotFound
+	}
+	return s.billingRepo.FindInvoicesByPatientID(patientID)
+}
+
+// VoidInvoice cancels an invoice raised in error and returns its charges to
+// pending. Money taken on it must be refunded first.
+func (s *billingService) VoidInvoice(id uint, reason string) (*models.Invoice, error) {
+	invoice, err := s.GetInvoiceByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if invoice.Status == models.InvoiceVoid {
+		return nil, ErrInvoiceVoid
+	}
+	if invoice.PaidCents != 0 {
+		return nil, ErrInvoiceHasPayments
+	}
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, errors.New("a reason is required to void an invoice")
+	}
+	if err := s.billingRepo.VoidInvoice(id, reason); err != nil {
+		return nil, err
+	}
+	return s.GetInvoiceByID(id)
+}
+
+// RecordPayment records money received against an invoice, or refunded
+// from it, and returns the invoice with its new balance. Payments cannot
+// exceed the balance and refunds cannot exceed what was paid. The invoice
+// read here only gives early errors; the repository enforces the limits
+// against the current totals when it saves the payment.
+func (s *billingService) RecordPayment(payment *models.Payment) (*models.Invoice, error) {
+	invoice, err := s.GetInvoiceByID(payment.InvoiceID)
+	if err != nil {
+		return nil, err
+	}
+	if invoice.Status == models.InvoiceVoid {
+		return nil, ErrInvoiceVoid
+	}
+
+	payment.Method = models.PaymentMethod(strings.ToLower(strings.TrimSpace(string(payment.Method))))
+	payment.Reference = strings.TrimSpace(payment.Reference)
+	payment.Notes = strings.TrimSpace(payment.Notes)
+	if payment.Kind == "" {
+		payment.Kind = models.PaymentReceived
+	}
+	if !payment.Method.IsValid() {
+		return nil, errors.New("invalid payment method")
+	}
+	if payment.AmountCents <= 0 {
+		return nil, errors.New("amount must be positive")
+	}
+	switch payment.Kind {
+	case models.PaymentReceived:
+		if payment.AmountCents > invoice.BalanceCents {
+			return nil, ErrPaymentExceedsOwed
+		}
+	case models.PaymentRefund:
+		if payment.AmountCents > invoice.PaidCents {
+			return nil, ErrRefundExceedsPaid
+		}
+	default:
+		return nil, errors.New("invalid payment kind")
+	}
+	if payment.ReceivedAt.IsZero() {
+		payment.ReceivedAt = time.Now()
+	}
+	payment.PatientID = invoice.PatientID
+
+	if err := s.billingRepo.CreatePayment(payment); err != nil {
+		return nil, err
+	}
+	return s.GetInvoiceByID(invoice.ID)
+}
+
+func (s *billingService) GetPatientBalance(patientID uint) (*models.PatientBalance, error) {
+	if _, err := s.patientRepo.FindByID(patientID); err != nil {
+		return nil, ErrPatientNotFound
+	}
+	return s.billingRepo.PatientBalance(patientID)
+}
+
+// taxOn returns the tax on amount at rate basis points, rounded half up to
+// the cent.
+func taxOn(amount int64, rate int) int64 {
+	return (amount*int64(rate) + 5000) / 10000
+}
+
+// settle marks an invoice paid when nothing is owed and open otherwise.
+func settle(invoice *models.Invoice) {
+	if invoice.BalanceCents == 0 {
+		invoice.Status = models.InvoicePaid
+	} else {
+		invoice.Status = models.InvoiceOpen
+	}
+}
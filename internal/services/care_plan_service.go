@@ -0,0 +1,483 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"healthcare-portal/internal/models"
+	"healthcare-portal/internal/repository"
+)
+
+var (
+	ErrCarePlanNotFound = errors.New("care plan not found")
+	ErrCareTaskNotFound = errors.New("care task not found")
+	ErrCareTaskClosed   = errors.New("care task is already completed or cancelled")
+)
+
+const (
+	// defaultTaskWindow is how long before its due date a task without an
+	// earliest date becomes worth doing.
+	defaultTaskWindow = 14 * 24 * time.Hour
+	// overdueSearchDays is how far ahead slots are suggested for a task that
+	// is already past its due date.
+	overdueSearchDays = 14
+	// maxSearchDays caps the number of days searched for suggestions.
+	maxSearchDays = 60
+)
+
+type CarePlanService interface {
+	WithTenant(tenantID uint) CarePlanService
+	AllTenants() CarePlanService
+	CreateCarePlan(plan *models.CarePlan) error
+	GetCarePlanByID(id uint) (*models.CarePlan, error)
+	GetPatientCarePlans(patientID uint, status models.CarePlanStatus) ([]models.CarePlan, error)
+	UpdateCarePlan(plan *models.CarePlan) error
+	AddGoal(planID uint, goal *models.CarePlanGoal) error
+	UpdateGoal(planID uint, goal *models.CarePlanGoal) error
+	AddTask(planID uint, task *models.CareTask) error
+	GetTaskByID(id uint) (*models.CareTask, error)
+	GetTasks(filter models.CareTaskFilter) ([]models.CareTask, error)
+	UpdateTask(task *models.CareTask) error
+	CompleteTask(id, userID uint) (*models.CareTask, error)
+	CancelTask(id uint) (*models.CareTask, error)
+	SuggestAppointments(id uint, from time.Time, limit int) ([]models.SuggestedAppointment, error)
+	BookTask(id uint, appointment *models.Appointment) (*models.CareTask, error)
+	MarkOverdueTasks(asOf time.Time) ([]models.CareTask, error)
+}
+
+type carePlanService struct {
+	carePlanRepo       repository.CarePlanRepository
+	patientRepo        repository.PatientRepository
+	userRepo           repository.UserRepository
+	problemRepo        repository.ProblemRepository
+	appointmentService AppointmentService
+}
+
+func NewCarePlanService(carePlanRepo repository.CarePlanRepository, patientRepo repository.PatientRepository, userRepo repository.UserRepository, problemRepo repository.ProblemRepository, appointmentService AppointmentService) CarePlanService {
+	return &carePlanService{
+		carePlanRepo:       carePlanRepo,
+		patientRepo:        patientRepo,
+		userRepo:           userRepo,
+		problemRepo:        problemRepo,
+		appointmentService: appointmentService,
+	}
+}
+
+// WithTenant returns a service limited to tenantID's patients and staff.
+func (s *carePlanService) WithTenant(tenantID uint) CarePlanService {
+	return &carePlanService{
+		carePlanRepo:       s.carePlanRepo.WithTenant(tenantID),
+		patientRepo:        s.patientRepo.WithTenant(tenantID),
+		userRepo:           s.userRepo.WithTenant(tenantID),
+		problemRepo:        s.problemRepo.WithTenant(tenantID),
+		appointmentService: s.appointmentService.WithTenant(tenantID),
+	}
+}
+
+// AllTenants returns a service whose task sweeps cover every tenant. It is
+// only for CareTaskWorker.
+func (s *carePlanService) AllTenants() CarePlanService {
+	return &carePlanService{
+		carePlanRepo:       s.carePlanRepo.AllTenants(),
+		patientRepo:        s.patientRepo,
+		userRepo:           s.userRepo,
+		problemRepo:        s.problemRepo,
+		appointmentService: s.appointmentService,
+	}
+}
+
+// CreateCarePlan stores a plan with its initial goals and tasks.
+func (s *carePlanService) CreateCarePlan(plan *models.CarePlan) error {
+	if _, err := s.patientRepo.FindByID(plan.PatientID); err != nil {
+		return errors.New("patient not found")
+	}
+	plan.Title = strings.TrimSpace(plan.Title)
+	plan.Description = strings.TrimSpace(plan.Description)
+	if plan.Title == "" {
+		return errors.New("title is required")
+	}
+	if plan.ProblemID != nil {
+		problem, err := s.problemRepo.FindByID(*plan.ProblemID)
+		if err != nil || problem.PatientID != plan.PatientID {
+			return errors.New("problem not found for this patient")
+		}
+	}
+	plan.Status = models.CarePlanActive
+
+	for i := range plan.Goals {
+		if err := normalizeGoal(&plan.Goals[i]); err != nil {
+			return err
+		}
+	}
+	for i := range plan.Tasks {
+		task := &plan.Tasks[i]
+		task.PatientID = plan.PatientID
+		task.CreatedBy = plan.CreatedBy
+		if err := s.normalizeTask(task); err != nil {
+			return err
+		}
+	}
+
+	if err := s.carePlanRepo.Create(plan); err != nil {
+		return err
+	}
+	return s.reloadPlan(plan)
+}
+
+func (s *carePlanService) GetCarePlanByID(id uint) (*models.CarePlan, error) {
+	plan, err := s.carePlanRepo.FindByID(id)
+	if err != nil {
+		return nil, ErrCarePlanNotFound
+	}
+	return plan, nil
+}
+
+func (s *carePlanService) GetPatientCarePlans(patientID uint, status models.CarePlanStatus) ([]models.CarePlan, error) {
+	if _, err := s.patientRepo.FindByID(patientID); err != nil {
+		return nil, errors.New("patient not found")
+	}
+	return s.carePlanRepo.FindByPatientID(patientID, status)
+}
+
+// UpdateCarePlan changes the title, description and status. Completing or
+// cancelling a plan cancels its open tasks so they leave the work lists.
+func (s *carePlanService) UpdateCarePlan(plan *models.CarePlan) error {
+	existing, err := s.GetCarePlanByID(plan.ID)
+	if err != nil {
+		return err
+	}
+	existing.Title = strings.TrimSpace(plan.Title)
+	existing.Description = strings.TrimSpace(plan.Description)
+	if existing.Title == "" {
+		return errors.New("title is required")
+	}
+	if plan.Status != "" {
+		switch plan.Status {
+		case models.CarePlanActive, models.CarePlanCompleted, models.CarePlanCancelled:
+			existing.Status = plan.Status
+		default:
+			return errors.New("status must be active, completed or cancelled")
+		}
+	}
+
+	if err := s.carePlanRepo.Update(existing); err != nil {
+		return err
+	}
+	if existing.Status != models.CarePlanActive {
+		for i := range existing.Tasks {
+			task := &existing.Tasks[i]
+			if !task.Status.IsOpen() {
+				continue
+			}
+			task.Status = models.CareTaskCancelled
+			if err := s.carePlanRepo.UpdateTask(task); err != nil {
+				return err
+			}
+		}
+	}
+	*plan = *existing
+	return s.reloadPlan(plan)
+}
+
+func (s *carePlanService) AddGoal(planID uint, goal *models.CarePlanGoal) error {
+	if _, err := s.GetCarePlanByID(planID); err != nil {
+		return err
+	}
+	goal.CarePlanID = planID
+	if err := normalizeGoal(goal); err != nil {
+		return err
+	}
+	return s.carePlanRepo.CreateGoal(goal)
+}
+
+func (s *carePlanService) UpdateGoal(planID uint, goal *models.CarePlanGoal) error {
+	plan, err := s.GetCarePlanByID(planID)
+	if err != nil {
+		return err
+	}
+	for _, existing := range plan.Goals {
+		if existing.ID != goal.ID {
+			continue
+		}
+		goal.CarePlanID = planID
+		goal.CreatedAt = existing.CreatedAt
+		if err := normalizeGoal(goal); err != nil {
+			return err
+		}
+		return s.carePlanRepo.UpdateGoal(goal)
+	}
+	return errors.New("goal not found")
+}
+
+func (s *carePlanService) AddTask(planID uint, task *models.CareTask) error {
+	plan, err := s.GetCarePlanByID(planID)
+	if err != nil {
+		return err
+	}
+	if plan.Status != models.CarePlanActive {
+		return fmt.Errorf("cannot add tasks to a %s care plan", plan.Status)
+	}
+	task.CarePlanID = planID
+	task.PatientID = plan.PatientID
+	if err := s.normalizeTask(task); err != nil {
+		return err
+	}
+	if err := s.carePlanRepo.CreateTask(task); err != nil {
+		return err
+	}
+	return s.reloadTask(task)
+}
+
+func (s *carePlanService) GetTaskByID(id uint) (*models.CareTask, error) {
+	task, err := s.carePlanRepo.FindTaskByID(id)
+	if err != nil {
+		return nil, ErrCareTaskNotFound
+	}
+	return task, nil
+}
+
+func (s *carePlanService) GetTasks(filter models.CareTaskFilter) ([]models.CareTask, error) {
+	return s.carePlanRepo.FindTasks(filter)
+}
+
+// UpdateTask reschedules or reassigns an open task. An overdue task given a
+// due date that has not passed yet goes back to pending.
+func (s *carePlanService) UpdateTask(task *models.CareTask) error {
+	existing, err := s.GetTaskByID(task.ID)
+	if err != nil {
+		return err
+	}
+	if !existing.Status.IsOpen() {
+		return ErrCareTaskClosed
+	}
+
+	existing.Title = task.Title
+	existing.Description = task.Description
+	existing.AssignedTo = task.AssignedTo
+	existing.EarliestDate = task.EarliestDate
+	existing.DueDate = task.DueDate
+	existing.DoctorID = task.DoctorID
+	existing.AppointmentTypeID = task.AppointmentTypeID
+	if err := s.normalizeTask(existing); err != nil {
+		return err
+	}
+	if existing.Status == models.CareTaskOverdue && !existing.DueDate.Before(today()) {
+		existing.Status = models.CareTaskPending
+	}
+	if err := s.carePlanRepo.UpdateTask(existing); err != nil {
+		return err
+	}
+	*task = *existing
+	return s.reloadTask(task)
+}
+
+func (s *carePlanService) CompleteTask(id, userID uint) (*models.CareTask, error) {
+	task, err := s.GetTaskByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if !task.Status.IsOpen() {
+		return nil, ErrCareTaskClosed
+	}
+	now := time.Now()
+	task.Status = models.CareTaskCompleted
+	task.CompletedAt = &now
+	task.CompletedBy = &userID
+	if err := s.carePlanRepo.UpdateTask(task); err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+func (s *carePlanService) CancelTask(id uint) (*models.CareTask, error) {
+	task, err := s.GetTaskByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if !task.Status.IsOpen() {
+		return nil, ErrCareTaskClosed
+	}
+	task.Status = models.CareTaskCancelled
+	if err := s.carePlanRepo.UpdateTask(task); err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+// SuggestAppointments returns up to limit free slots for the task's visit
+// between its earliest and due dates, starting no sooner than from. Tasks
+// already past due get slots from the next two weeks instead.
+func (s *carePlanService) SuggestAppointments(id uint, from time.Time, limit int) ([]models.SuggestedAppointment, error) {
+	task, err := s.GetTaskByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if !task.Status.IsOpen() {
+		return nil, ErrCareTaskClosed
+	}
+	if limit <= 0 {
+		limit = 5
+	}
+
+	start := dateOnly(laterOf(task.EarliestDate, from))
+	end := dateOnly(task.DueDate)
+	if end.Before(start) {
+		end = start.AddDate(0, 0, overdueSearchDays)
+	}
+	if last := start.AddDate(0, 0, maxSearchDays); end.After(last) {
+		end = last
+	}
+
+	suggestions := []models.SuggestedAppointment{}
+	for day := start; !day.After(end) && len(suggestions) < limit; day = day.AddDate(0, 0, 1) {
+		slots, err := s.appointmentService.FindAvailableSlots(models.SlotQuery{
+			Date:              day,
+			AppointmentTypeID: task.AppointmentTypeID,
+			DoctorID:          task.DoctorID,
+			PatientID:         &task.PatientID,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, slot := range slots {
+			if len(suggestions) == limit {
+				break
+			}
+			suggestions = append(suggestions, models.SuggestedAppointment{Date: day.Format("2006-01-02"), AvailableSlot: slot})
+		}
+	}
+	return suggestions, nil
+}
+
+// BookTask books the follow-up visit through AppointmentService and links
+// it to the task. The doctor and appointment type default to the task's.
+func (s *carePlanService) BookTask(id uint, appointment *models.Appointment) (*models.CareTask, error) {
+	task, err := s.GetTaskByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if !task.Status.IsOpen() {
+		return nil, ErrCareTaskClosed
+	}
+	if task.Status == models.CareTaskScheduled {
+		return nil, errors.New("care task already has an appointment")
+	}
+
+	appointment.PatientID = task.PatientID
+	if appointment.DoctorID == 0 && task.DoctorID != nil {
+		appointment.DoctorID = *task.DoctorID
+	}
+	if appointment.DoctorID == 0 {
+		return nil, errors.New("doctor is required")
+	}
+	if appointment.AppointmentTypeID == nil {
+		appointment.AppointmentTypeID = task.AppointmentTypeID
+	}
+	appointment.Status = models.StatusScheduled
+	if strings.TrimSpace(appointment.Notes) == "" {
+		appointment.Notes = "Follow-up: " + task.Title
+	}
+	if err := s.appointmentService.CreateAppointment(appointment); err != nil {
+		return nil, err
+	}
+
+	task.Status = models.CareTaskScheduled
+	task.AppointmentID = &appointment.ID
+	if err := s.carePlanRepo.UpdateTask(task); err != nil {
+		return nil, err
+	}
+	return task, s.reloadTask(task)
+}
+
+// MarkOverdueTasks moves pending tasks due before asOf to overdue and
+// returns them. It is run by the care task worker.
+func (s *carePlanService) MarkOverdueTasks(asOf time.Time) ([]models.CareTask, error) {
+	return s.carePlanRepo.MarkOverdue(dateOnly(asOf))
+}
+
+func (s *carePlanService) normalizeTask(task *models.CareTask) error {
+	task.Title = strings.TrimSpace(task.Title)
+	task.Description = strings.TrimSpace(task.Description)
+	if task.Title == "" {
+		return errors.New("task title is required")
+	}
+	if task.DueDate.IsZero() {
+		return errors.New("task due date is required")
+	}
+	task.DueDate = dateOnly(task.DueDate)
+	if task.EarliestDate.IsZero() {
+		// Open the window up to two weeks ahead of the due date, but not in
+		// the past unless the task itself is already past due.
+		task.EarliestDate = laterOf(dateOnly(task.DueDate.Add(-defaultTaskWindow)), today())
+		if task.EarliestDate.After(task.DueDate) {
+			task.EarliestDate = task.DueDate
+		}
+	}
+	task.EarliestDate = dateOnly(task.EarliestDate)
+	if task.EarliestDate.After(task.DueDate) {
+		return errors.New("earliest date cannot be after the due date")
+	}
+	if task.Status == "" {
+		task.Status = models.CareTaskPending
+	}
+
+	if task.AssignedTo != nil {
+		if _, err := s.userRepo.FindByID(*task.AssignedTo); err != nil {
+			return errors.New("assigned user not found")
+		}
+	}
+	if task.DoctorID != nil {
+		doctor, err := s.userRepo.FindByID(*task.DoctorID)
+		if err != nil || doctor.Role != models.RoleDoctor {
+			return errors.New("doctor not found")
+		}
+	}
+	return nil
+}
+
+func (s *carePlanService) reloadPlan(plan *models.CarePlan) error {
+	loaded, err := s.carePlanRepo.FindByID(plan.ID)
+	if err != nil {
+		return err
+	}
+	*plan = *loaded
+	return nil
+}
+
+func (s *carePlanService) reloadTask(task *models.CareTask) error {
+	loaded, err := s.carePlanRepo.FindTaskByID(task.ID)
+	if err != nil {
+		return err
+	}
+	*task = *loaded
+	return nil
+}
+
+func normalizeGoal(goal *models.CarePlanGoal) error {
+	goal.Description = strings.TrimSpace(goal.Description)
+	goal.Target = strings.TrimSpace(goal.Target)
+	if goal.Description == "" {
+		return errors.New("goal description is required")
+	}
+	if goal.Status == "" {
+		goal.Status = models.CareGoalInProgress
+	}
+	switch goal.Status {
+	case models.CareGoalInProgress, models.CareGoalAchieved, models.CareGoalNotAchieved:
+	default:
+		return errors.New("goal status must be in_progress, achieved or not_achieved")
+	}
+	return nil
+}
+
+// dateOnly drops the time of day, keeping the calendar date in UTC as dates
+// parsed from YYYY-MM-DD are.
+func dateOnly(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func today() time.Time {
+	return dateOnly(time.Now())
+}
@@ -0,0 +1,74 @@
+package services
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"healthcare-portal/internal/models"
+)
+
+// CareTaskWorker periodically marks follow-up tasks that have passed their
+// due date as overdue, across all tenants, so they surface in staff work
+// lists and can be filtered with status=overdue.
+type CareTaskWorker struct {
+	carePlanService CarePlanService
+	interval        time.Duration
+}
+
+// NewCareTaskWorker builds the worker. A non-positive interval falls back to
+// hourly sweeps.
+func NewCareTaskWorker(carePlanService CarePlanService, interval time.Duration) *CareTaskWorker {
+	if interval <= 0 {
+		interval = time.Hour
+	}
+	return &CareTaskWorker{carePlanService: carePlanService, interval: interval}
+}
+
+// Sweep marks tasks due before today as overdue and returns them.
+func (w *CareTaskWorker) Sweep() ([]models.CareTask, error) {
+	return w.carePlanService.MarkOverdueTasks(time.Now())
+}
+
+// Run sweeps once immediately and then every interval until ctx is done.
+func (w *CareTaskWorker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		w.sweepAndLog()
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *CareTaskWorker) sweepAndLog() {
+	tasks, err := w.Sweep()
+	if err != nil {
+		log.Printf("Care task sweep failed: %v", err)
+		return
+	}
+	if len(tasks) == 0 {
+		return
+	}
+
+	unassigned := 0
+	byAssignee := make(map[uint]int)
+	for _, task := range tasks {
+		if task.AssignedTo == nil {
+			unassigned++
+			continue
+		}
+		byAssignee[*task.AssignedTo]++
+	}
+	log.Printf("Care task sweep: %d task(s) now overdue", len(tasks))
+	for userID, count := range byAssignee {
+		log.Printf("Care task sweep: user %d has %d newly overdue task(s)", userID, count)
+	}
+	if unassigned > 0 {
+		log.Printf("Care task sweep: %d newly overdue task(s) are unassigned", unassigned)
+	}
+}
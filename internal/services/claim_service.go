@@ -0,0 +1,620 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"healthcare-portal/internal/config"
+	"healthcare-portal/internal/models"
+	"healthcare-portal/internal/repository"
+)
+
+var (
+	ErrClaimNotFound           = errors.New("claim not found")
+	ErrClaimBatchNotFound      = errors.New("claim batch not found")
+	ErrRemittanceNotFound      = errors.New("remittance not found")
+	ErrAppointmentNotCompleted = errors.New("only completed appointments can be claimed")
+	ErrClaimAlreadySubmitted   = errors.New("the appointment's claim has already been submitted")
+	ErrNothingToSubmit         = errors.New("there are no claims ready to submit")
+	ErrClaimsNotReady          = repository.ErrClaimsUnavailable
+	ErrRemittanceImported      = errors.New("this remittance has already been imported")
+	ErrInvalidRemittance       = errors.New("not a valid 835 remittance file")
+)
+
+const (
+	// placeOfServiceOffice is the CMS place of service code for an office
+	// visit.
+	placeOfServiceOffice = "11"
+	// maxClaimLines and maxClaimDiagnoses are the 837P limits per claim.
+	maxClaimLines     = 50
+	maxClaimDiagnoses = 12
+	// maxDiagnosisPointers is how many diagnoses one service line can point
+	// at.
+	maxDiagnosisPointers = 4
+)
+
+var (
+	procedureCodePattern = regexp.MustCompile(`^[A-Z0-9]{5}$`)
+	diagnosisCodePattern = regexp.MustCompile(`^[A-Z][0-9][0-9A-Z]{1,5}$`)
+	// usAddressPattern matches the "street, city, ST 12345" addresses
+	// patients are registered with.
+	usAddressPattern = regexp.MustCompile(`^(.+),\s*([^,]+),\s*([A-Za-z]{2})\s+(\d{5})(?:-?(\d{4}))?$`)
+)
+
+type ClaimService interface {
+	WithTenant(tenantID uint) ClaimService
+	GenerateClaim(appointmentID, createdBy uint) (*models.Claim, error)
+	GetClaimByID(id uint) (*models.Claim, error)
+	GetClaims(status models.ClaimStatus) ([]models.Claim, error)
+	GetPatientClaims(patientID uint) ([]models.Claim, error)
+	CreateBatch(claimIDs []uint, createdBy uint) (*models.ClaimBatch, error)
+	GetBatchByID(id uint) (*models.ClaimBatch, error)
+	GetBatches() ([]models.ClaimBatch, error)
+	ImportRemittance(fileName string, data []byte, importedBy uint) ([]models.Remittance, error)
+	GetRemittanceByID(id uint) (*models.Remittance, error)
+	GetRemittances() ([]models.Remittance, error)
+}
+
+type claimService struct {
+	claimRepo       repository.ClaimRepository
+	appointmentRepo repository.AppointmentRepository
+	problemRepo     repository.ProblemRepository
+	insuranceRepo   repository.InsuranceRepository
+	billingRepo     repository.BillingRepository
+	claims          config.ClaimsConfig
+}
+
+func NewClaimService(claimRepo repository.ClaimRepository, appointmentRepo repository.AppointmentRepository, problemRepo repository.ProblemRepository, insuranceRepo repository.InsuranceRepository, billingRepo repository.BillingRepository, claims config.ClaimsConfig) ClaimService {
+	return &claimService{
+		claimRepo:       claimRepo,
+		appointmentRepo: appointmentRepo,
+		problemRepo:     problemRepo,
+		insuranceRepo:   insuranceRepo,
+		billingRepo:     billingRepo,
+		claims:          claims,
+	}
+}
+
+// WithTenant returns a service that only sees tenantID's claims, batches
+// and remittances.
+func (s *claimService) WithTenant(tenantID uint) ClaimService {
+	return &claimService{
+		claimRepo:       s.claimRepo.WithTenant(tenantID),
+		appointmentRepo: s.appointmentRepo.WithTenant(tenantID),
+		problemRepo:     s.problemRepo.WithTenant(tenantID),
+		insuranceRepo:   s.insuranceRepo.WithTenant(tenantID),
+		billingRepo:     s.billingRepo.WithTenant(tenantID),
+		claims:          s.claims,
+	}
+}
+
+// GenerateClaim builds the claim for a completed appointment from its
+// charges, its diagnoses and the patient's primary coverage, and validates
+// it. Generating again rebuilds a claim that has not been submitted yet, so
+// staff can fix the data and retry; a denied claim is replaced by a new one.
+func (s *claimService) GenerateClaim(appointmentID, createdBy uint) (*models.Claim, error) {
+	appointment, err := s.appointmentRepo.FindByID(appointmentID)
+	if err != nil {
+		return nil, ErrAppointmentNotFound
+	}
+	if appointment.Status != models.StatusCompleted {
+		return nil, ErrAppointmentNotCompleted
+	}
+
+	claim, err := s.claimRepo.FindClaimByAppointmentID(appointmentID)
+	switch {
+	case err != nil || claim.Status == models.ClaimDenied:
+		claim = &models.Claim{CreatedBy: createdBy}
+	case claim.Status != models.ClaimInvalid && claim.Status != models.ClaimReady:
+		return nil, ErrClaimAlreadySubmitted
+	}
+	claim.PatientID = appointment.PatientID
+	claim.AppointmentID = appointment.ID
+	claim.ServiceDate = appointment.Date
+	claim.PlaceOfService = placeOfServiceOffice
+	claim.Patient = appointment.Patient
+
+	claim.Coverage, claim.CoverageID = nil, nil
+	coverages, err := s.insuranceRepo.FindCoveragesByPatientID(appointment.PatientID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range coverages {
+		if coverages[i].Priority == 1 && coverages[i].CoversOn(appointment.Date) {
+			claim.Coverage = &coverages[i]
+			claim.CoverageID = &coverages[i].ID
+			break
+		}
+	}
+
+	diagnoses, err := s.problemRepo.FindAppointmentDiagnoses(appointment.ID)
+	if err != nil {
+		return nil, err
+	}
+	claim.Diagnoses = make([]models.ClaimDiagnosis, len(diagnoses))
+	for i, diagnosis := range diagnoses {
+		claim.Diagnoses[i] = models.ClaimDiagnosis{Position: i + 1, Code: strings.ToUpper(strings.TrimSpace(diagnosis.Code))}
+	}
+	pointers := make([]string, 0, maxDiagnosisPointers)
+	for i := 1; i <= len(diagnoses) && i <= maxDiagnosisPointers; i++ {
+		pointers = append(pointers, fmt.Sprint(i))
+	}
+
+	charges, err := s.billingRepo.FindChargesByAppointmentID(appointment.ID)
+	if err != nil {
+		return nil, err
+	}
+	claim.Lines = []models.ClaimLine{}
+	claim.TotalChargeCents = 0
+	for _, charge := range charges {
+		if charge.Status == models.ChargeVoided {
+			continue
+		}
+		claim.Lines = append(claim.Lines, models.ClaimLine{
+			ChargeID:          charge.ID,
+			LineNumber:        len(claim.Lines) + 1,
+			ProcedureCode:     charge.ProcedureCode,
+			Description:       charge.Description,
+			Units:             charge.Quantity,
+			ChargeCents:       charge.AmountCents,
+			DiagnosisPointers: strings.Join(pointers, ","),
+			ServiceDate:       charge.ServiceDate,
+		})
+		claim.TotalChargeCents += charge.AmountCents
+	}
+
+	claim.ValidationErrors = s.validate(claim)
+	claim.Status = models.ClaimReady
+	if len(claim.ValidationErrors) > 0 {
+		claim.Status = models.ClaimInvalid
+	}
+	if err := s.claimRepo.SaveClaim(claim); err != nil {
+		return nil, err
+	}
+	return s.GetClaimByID(claim.ID)
+}
+
+// validate lists everything a clearinghouse would reject the claim for.
+func (s *claimService) validate(claim *models.Claim) []string {
+	problems := s.validateSubmitter()
+
+	patient := claim.Patient
+	if patient == nil {
+		return append(problems, "the appointment's patient was not found")
+	}
+	if strings.TrimSpace(patient.FirstName) == "" || strings.TrimSpace(patient.LastName) == "" {
+		problems = append(problems, "the patient's first and last name are required")
+	}
+	if patient.DateOfBirth.IsZero() {
+		problems = append(problems, "the patient's date of birth is required")
+	}
+	if _, ok := splitAddress(patient.Address); !ok {
+		problems = append(problems, `the patient's address must be in the form "street, city, ST 12345"`)
+	}
+
+	if coverage := claim.Coverage; coverage == nil {
+		problems = append(problems, "the patient has no primary coverage in force on the service date")
+	} else {
+		if strings.TrimSpace(coverage.MemberID) == "" {
+			problems = append(problems, "the coverage has no member ID")
+		}
+		if coverage.Plan == nil || coverage.Plan.Payer == nil {
+			problems = append(problems, "the coverage's plan or payer was not found")
+		} else if strings.TrimSpace(coverage.Plan.Payer.PayerCode) == "" {
+			problems = append(problems, fmt.Sprintf("payer %s has no payer ID for electronic claims", coverage.Plan.Payer.Name))
+		}
+		if coverage.SubscriberRelationship != models.SubscriberSelf {
+			if _, _, ok := splitName(coverage.SubscriberName); !ok {
+				problems = append(problems, "the subscriber's first and last name are required")
+			}
+			if coverage.SubscriberDateOfBirth == nil {
+				problems = append(problems, "the subscriber's date of birth is required")
+			}
+		}
+	}
+
+	switch {
+	case len(claim.Diagnoses) == 0:
+		problems = append(problems, "the appointment has no diagnoses")
+	case len(claim.Diagnoses) > maxClaimDiagnoses:
+		problems = append(problems, fmt.Sprintf("a claim can carry at most %d diagnoses", maxClaimDiagnoses))
+	}
+	for _, diagnosis := range claim.Diagnoses {
+		if !diagnosisCodePattern.MatchString(strings.ReplaceAll(diagnosis.Code, ".", "")) {
+			problems = append(problems, fmt.Sprintf("diagnosis %s is not an ICD-10 code", diagnosis.Code))
+		}
+	}
+
+	switch {
+	case len(claim.Lines) == 0:
+		problems = append(problems, "the appointment has no charges")
+	case len(claim.Lines) > maxClaimLines:
+		problems = append(problems, fmt.Sprintf("a claim can carry at most %d service lines", maxClaimLines))
+	}
+	for _, line := range claim.Lines {
+		if !procedureCodePattern.MatchString(line.ProcedureCode) {
+			problems = append(problems, fmt.Sprintf("line %d: %q is not a CPT or HCPCS code", line.LineNumber, line.ProcedureCode))
+		}
+		if line.ChargeCents <= 0 {
+			problems = append(problems, fmt.Sprintf("line %d has no charge amount", line.LineNumber))
+		}
+	}
+	return problems
+}
+
+// validateSubmitter checks the practice details configured for claims.
+func (s *claimService) validateSubmitter() []string {
+	var problems []string
+	required := []struct{ value, name string }{
+		{s.claims.SubmitterID, "submitter ID"},
+		{s.claims.SubmitterName, "submitter name"},
+		{s.claims.ReceiverID, "receiver ID"},
+		{s.claims.ReceiverName, "receiver name"},
+		{s.claims.BillingProviderName, "billing provider name"},
+		{s.claims.BillingProviderStreet, "billing provider street"},
+		{s.claims.BillingProviderCity, "billing provider city"},
+	}
+	for _, field := range required {
+		if strings.TrimSpace(field.value) == "" {
+			problems = append(problems, fmt.Sprintf("the %s is not configured", field.name))
+		}
+	}
+	if !validNPI(s.claims.BillingProviderNPI) {
+		problems = append(problems, "the billing provider NPI is missing or invalid")
+	}
+	if len(digitsOnly(s.claims.BillingProviderTaxID)) != 9 {
+		problems = append(problems, "the billing provider tax ID must have 9 digits")
+	}
+	if len(strings.TrimSpace(s.claims.BillingProviderState)) != 2 {
+		problems = append(problems, "the billing provider state must be a 2-letter code")
+	}
+	if zip := digitsOnly(s.claims.BillingProviderZip); len(zip) != 5 && len(zip) != 9 {
+		problems = append(problems, "the billing provider ZIP code must have 5 or 9 digits")
+	}
+	return problems
+}
+
+func (s *claimService) GetClaimByID(id uint) (*models.Claim, error) {
+	claim, err := s.claimRepo.FindClaimByID(id)
+	if err != nil {
+		return nil, ErrClaimNotFound
+	}
+	return claim, nil
+}
+
+func (s *claimService) GetClaims(status models.ClaimStatus) ([]models.Claim, error) {
+	return s.claimRepo.FindClaims(status)
+}
+
+func (s *claimService) GetPatientClaims(patientID uint) ([]models.Claim, error) {
+	return s.claimRepo.FindClaimsByPatientID(patientID)
+}
+
+// CreateBatch writes ready claims into an 837P file. Without claimIDs every
+// ready claim is batched. Claims are validated again first, since patient,
+// coverage or practice details may have changed since they were generated;
+// any that now fail are marked invalid and left out.
+func (s *claimService) CreateBatch(claimIDs []uint, createdBy uint) (*models.ClaimBatch, error) {
+	var candidates []models.Claim
+	if len(claimIDs) == 0 {
+		ready, err := s.claimRepo.FindClaims(models.ClaimReady)
+		if err != nil {
+			return nil, err
+		}
+		for _, claim := range ready {
+			claimIDs = append(claimIDs, claim.ID)
+		}
+	}
+	sort.Slice(claimIDs, func(i, j int) bool { return claimIDs[i] < claimIDs[j] })
+	seen := make(map[uint]bool)
+	for _, id := range claimIDs {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		claim, err := s.GetClaimByID(id)
+		if err != nil {
+			return nil, err
+		}
+		if claim.Status != models.ClaimReady {
+			return nil, ErrClaimsNotReady
+		}
+		if problems := s.validate(claim); len(problems) > 0 {
+			claim.Status = models.ClaimInvalid
+			claim.ValidationErrors = problems
+			if err := s.claimRepo.UpdateClaim(claim); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		candidates = append(candidates, *claim)
+	}
+	if len(candidates) == 0 {
+		return nil, ErrNothingToSubmit
+	}
+
+	batch := &models.ClaimBatch{ClaimCount: len(candidates), CreatedBy: createdBy}
+	ids := make([]uint, len(candidates))
+	for i, claim := range candidates {
+		ids[i] = claim.ID
+		batch.TotalChargeCents += claim.TotalChargeCents
+	}
+	err := s.claimRepo.CreateBatch(batch, ids, func(batch *models.ClaimBatch) error {
+		batch.FileName = fmt.Sprintf("837P-%09d.x12", batch.ID)
+		batch.Content = s.render837(batch, candidates)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetBatchByID(batch.ID)
+}
+
+// GetBatchByID returns the batch with its claims. The 837P file itself is
+// in Content.
+func (s *claimService) GetBatchByID(id uint) (*models.ClaimBatch, error) {
+	batch, err := s.claimRepo.FindBatchByID(id)
+	if err != nil {
+		return nil, ErrClaimBatchNotFound
+	}
+	return batch, nil
+}
+
+func (s *claimService) GetBatches() ([]models.ClaimBatch, error) {
+	return s.claimRepo.FindBatches()
+}
+
+// ImportRemittance reads an 835 file and reconciles each claim payment in
+// it: the claim is marked paid or denied, and money paid is posted as an
+// insurance payment to the invoice holding the claim's charges. Anything
+// that cannot be posted automatically is recorded with a note for staff.
+//
+// Each payment advice is identified by its check or EFT trace number and is
+// imported in one transaction with its claim updates and payments, so it is
+// posted completely or not at all. Advices already imported are skipped,
+// which lets a file that failed part way be imported again; it is only an
+// error when every advice in the file has been imported before.
+func (s *claimService) ImportRemittance(fileName string, data []byte, importedBy uint) ([]models.Remittance, error) {
+	advices, err := parse835(data)
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool, len(advices))
+	for _, advice := range advices {
+		if seen[advice.traceNumber] {
+			return nil, fmt.Errorf("%w: trace number %s appears more than once", ErrInvalidRemittance, advice.traceNumber)
+		}
+		seen[advice.traceNumber] = true
+	}
+
+	remittances := make([]models.Remittance, 0, len(advices))
+	for _, advice := range advices {
+		if _, err := s.claimRepo.FindRemittanceByTraceNumber(advice.traceNumber); err == nil {
+			continue
+		}
+		remittance := models.Remittance{
+			TraceNumber:    advice.traceNumber,
+			PayerName:      advice.payerName,
+			PaymentDate:    advice.paymentDate,
+			TotalPaidCents: advice.totalPaid,
+			FileName:       fileName,
+			ImportedBy:     importedBy,
+		}
+		err := s.claimRepo.Transaction(func(claimRepo repository.ClaimRepository, billingRepo repository.BillingRepository) error {
+			tx := &claimService{claimRepo: claimRepo, billingRepo: billingRepo}
+			if err := claimRepo.CreateRemittance(&remittance); err != nil {
+				return err
+			}
+			for _, payment := range advice.claims {
+				remittanceClaim, err := tx.reconcile(&remittance, payment)
+				if err != nil {
+					return err
+				}
+				remittanceClaim.RemittanceID = remittance.ID
+				if err := claimRepo.CreateRemittanceClaim(remittanceClaim); err != nil {
+					return err
+				}
+				remittance.Claims = append(remittance.Claims, *remittanceClaim)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("trace number %s: %w", advice.traceNumber, err)
+		}
+		remittances = append(remittances, remittance)
+	}
+	if len(remittances) == 0 {
+		return nil, ErrRemittanceImported
+	}
+	return remittances, nil
+}
+
+// reconcile applies the payer's decision on one claim.
+func (s *claimService) reconcile(remittance *models.Remittance, payment claimPayment) (*models.RemittanceClaim, error) {
+	result := &models.RemittanceClaim{
+		ControlNumber:              payment.controlNumber,
+		StatusCode:                 payment.statusCode,
+		ChargeCents:                payment.charge,
+		PaidCents:                  payment.paid,
+		AdjustmentCents:            payment.adjustments,
+		PatientResponsibilityCents: payment.patientResponsibility,
+		PayerClaimNumber:           payment.payerClaimNumber,
+		Outcome:                    models.RemittanceNotPosted,
+	}
+
+	claim, err := s.claimRepo.FindClaimByControlNumber(payment.controlNumber)
+	if err != nil {
+		result.Outcome = models.RemittanceUnmatched
+		result.Note = "no claim has this control number"
+		return result, nil
+	}
+	result.ClaimID = &claim.ID
+	if claim.Status != models.ClaimSubmitted {
+		result.Note = fmt.Sprintf("the claim is %s, not awaiting payment", claim.Status)
+		return result, nil
+	}
+
+	switch payment.statusCode {
+	case "1", "2", "3", "19", "20", "21":
+		claim.Status = models.ClaimPaid
+	case "4":
+		claim.Status = models.ClaimDenied
+	default:
+		result.Note = fmt.Sprintf("claim status %s is reconciled by hand", payment.statusCode)
+		return result, nil
+	}
+	claim.PaidCents = payment.paid
+	claim.AdjustmentCents = payment.adjustments
+	claim.PatientResponsibilityCents = payment.patientResponsibility
+	claim.PayerClaimNumber = payment.payerClaimNumber
+	applyServicePayments(claim, payment.lines)
+	if err := s.claimRepo.UpdateClaim(claim); err != nil {
+		return nil, err
+	}
+
+	if claim.Status == models.ClaimDenied {
+		result.Outcome = models.RemittanceDenied
+		return result, nil
+	}
+	if payment.paid <= 0 {
+		result.Note = "the payer paid nothing on this claim"
+		return result, nil
+	}
+
+	invoiceID := s.claimInvoiceID(claim)
+	if invoiceID == nil {
+		result.Note = "the claim's charges are not on an invoice yet; post the payment once they are invoiced"
+		return result, nil
+	}
+	result.InvoiceID = invoiceID
+	invoice, err := s.billingRepo.FindInvoiceByID(*invoiceID)
+	if err != nil {
+		return nil, err
+	}
+	if invoice.Status == models.InvoiceVoid || invoice.BalanceCents <= 0 {
+		result.Note = fmt.Sprintf("invoice %s has nothing owing", invoice.Number)
+		return result, nil
+	}
+	amount := payment.paid
+	if amount > invoice.BalanceCents {
+		amount = invoice.BalanceCents
+		result.Note = fmt.Sprintf("%s of the payment is more than the invoice balance and was not posted", formatAmount(payment.paid-amount))
+	}
+
+	posted := &models.Payment{
+		InvoiceID:   invoice.ID,
+		PatientID:   invoice.PatientID,
+		Kind:        models.PaymentReceived,
+		Method:      models.PaymentInsurance,
+		AmountCents: amount,
+		Reference:   remittance.TraceNumber,
+		Notes:       strings.TrimSpace(fmt.Sprintf("%s claim %s", remittance.PayerName, claim.ControlNumber)),
+		RecordedBy:  remittance.ImportedBy,
+	}
+	posted.ReceivedAt = time.Now()
+	if remittance.PaymentDate != nil {
+		posted.ReceivedAt = *remittance.PaymentDate
+	}
+	if err := s.billingRepo.CreatePayment(posted); err != nil {
+		return nil, err
+	}
+	result.PaymentID = &posted.ID
+	result.Outcome = models.RemittancePosted
+	return result, nil
+}
+
+// claimInvoiceID returns the invoice the claim's charges were billed on.
+func (s *claimService) claimInvoiceID(claim *models.Claim) *uint {
+	for _, line := range claim.Lines {
+		charge, err := s.billingRepo.FindChargeByID(line.ChargeID)
+		if err == nil && charge.InvoiceID != nil {
+			return charge.InvoiceID
+		}
+	}
+	return nil
+}
+
+func (s *claimService) GetRemittanceByID(id uint) (*models.Remittance, error) {
+	remittance, err := s.claimRepo.FindRemittanceByID(id)
+	if err != nil {
+		return nil, ErrRemittanceNotFound
+	}
+	return remittance, nil
+}
+
+func (s *claimService) GetRemittances() ([]models.Remittance, error) {
+	return s.claimRepo.FindRemittances()
+}
+
+// applyServicePayments copies paid and adjusted amounts onto the claim
+// lines, matching each service to the first unpaid line with its procedure
+// code.
+func applyServicePayments(claim *models.Claim, paidLines []servicePayment) {
+	used := make(map[int]bool)
+	for _, service := range paidLines {
+		for i := range claim.Lines {
+			if !used[i] && claim.Lines[i].ProcedureCode == service.procedureCode {
+				used[i] = true
+				claim.Lines[i].PaidCents = service.paid
+				claim.Lines[i].AdjustmentCents = service.adjustments
+				break
+			}
+		}
+	}
+}
+
+// postalAddress is an address split into the parts claims need.
+type postalAddress struct {
+	street, city, state, zip string
+}
+
+// splitAddress parses a "street, city, ST 12345" address.
+func splitAddress(address string) (postalAddress, bool) {
+	match := usAddressPattern.FindStringSubmatch(strings.TrimSpace(address))
+	if match == nil {
+		return postalAddress{}, false
+	}
+	return postalAddress{
+		street: strings.TrimSpace(match[1]),
+		city:   strings.TrimSpace(match[2]),
+		state:  strings.ToUpper(match[3]),
+		zip:    match[4] + match[5],
+	}, true
+}
+
+// splitName splits "First Last" into first and last name; the last word is
+// the last name.
+func splitName(name string) (first, last string, ok bool) {
+	fields := strings.Fields(name)
+	if len(fields) < 2 {
+		return "", "", false
+	}
+	return strings.Join(fields[:len(fields)-1], " "), fields[len(fields)-1], true
+}
+
+// validNPI checks a National Provider Identifier: ten digits whose last is a
+// Luhn check digit computed with the 80840 health industry prefix.
+func validNPI(npi string) bool {
+	if len(npi) != 10 || digitsOnly(npi) != npi {
+		return false
+	}
+	return luhnCheckDigit("80840"+npi[:9]) == int(npi[9]-'0')
+}
+
+func digitsOnly(value string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, value)
+}
+
+// claimDate formats a date as CCYYMMDD.
+func claimDate(t time.Time) string {
+	return t.Format("20060102")
+}
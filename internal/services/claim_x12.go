@@ -0,0 +1,348 @@
+package services
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"healthcare-portal/internal/models"
+	"healthcare-portal/internal/x12"
+)
+
+// claimImplementation is the 837P implementation guide the batches follow.
+const claimImplementation = "005010X222A1"
+
+// render837 writes the claims as one 837P interchange. The batch ID is used
+// as the interchange and group control number.
+func (s *claimService) render837(batch *models.ClaimBatch, claims []models.Claim) string {
+	cfg := s.claims
+	at := batch.CreatedAt
+	usage := strings.ToUpper(strings.TrimSpace(cfg.Usage))
+	if usage != "P" {
+		usage = "T"
+	}
+
+	w := x12.NewWriter(x12.DefaultDelimiters)
+	w.ISA(cfg.SubmitterID, cfg.ReceiverID, at, int(batch.ID), usage)
+	w.Segment("GS", "HC", cfg.SubmitterID, cfg.ReceiverID, at.Format("20060102"), at.Format("1504"), batch.ID, "X", claimImplementation)
+	start := w.Segments()
+	w.Segment("ST", "837", "0001", claimImplementation)
+	w.Segment("BHT", "0019", "00", fmt.Sprintf("%09d", batch.ID), at.Format("20060102"), at.Format("1504"), "CH")
+
+	// Submitter and receiver (loops 1000A and 1000B)
+	w.Segment("NM1", "41", "2", cfg.SubmitterName, "", "", "", "", "46", cfg.SubmitterID)
+	w.Segment("PER", "IC", cfg.ContactName, "TE", digitsOnly(cfg.ContactPhone))
+	w.Segment("NM1", "40", "2", cfg.ReceiverName, "", "", "", "", "46", cfg.ReceiverID)
+
+	// Billing provider (loop 2000A)
+	w.Segment("HL", 1, "", "20", 1)
+	w.Segment("NM1", "85", "2", cfg.BillingProviderName, "", "", "", "", "XX", cfg.BillingProviderNPI)
+	w.Segment("N3", cfg.BillingProviderStreet)
+	w.Segment("N4", cfg.BillingProviderCity, strings.ToUpper(cfg.BillingProviderState), digitsOnly(cfg.BillingProviderZip))
+	w.Segment("REF", "EI", digitsOnly(cfg.BillingProviderTaxID))
+
+	hl := 1
+	for _, claim := range claims {
+		hl = writeClaim(w, hl, claim)
+	}
+
+	w.Segment("SE", w.Segments()-start+1, "0001")
+	w.Segment("GE", 1, batch.ID)
+	w.Segment("IEA", 1, fmt.Sprintf("%09d", batch.ID))
+	return w.String()
+}
+
+// writeClaim writes the subscriber, patient and claim loops of one claim
+// and returns the last hierarchical level used.
+func writeClaim(w *x12.Writer, hl int, claim models.Claim) int {
+	patient := claim.Patient
+	coverage := claim.Coverage
+	payer := coverage.Plan.Payer
+	address, _ := splitAddress(patient.Address)
+	self := coverage.SubscriberRelationship == models.SubscriberSelf
+
+	// Subscriber (loop 2000B)
+	hl++
+	subscriberHL := hl
+	children := 1
+	relationship := ""
+	if self {
+		children = 0
+		relationship = "18"
+	}
+	w.Segment("HL", hl, 1, "22", children)
+	w.Segment("SBR", payerResponsibility(coverage.Priority), relationship, coverage.GroupNumber, "", "", "", "", "", claimFilingIndicator(coverage.Plan.PlanType))
+	if self {
+		w.Segment("NM1", "IL", "1", patient.LastName, patient.FirstName, "", "", "", "MI", coverage.MemberID)
+		writeAddress(w, address)
+		w.Segment("DMG", "D8", claimDate(patient.DateOfBirth), genderCode(patient.Gender))
+	} else {
+		first, last, _ := splitName(coverage.SubscriberName)
+		w.Segment("NM1", "IL", "1", last, first, "", "", "", "MI", coverage.MemberID)
+		w.Segment("DMG", "D8", claimDate(*coverage.SubscriberDateOfBirth))
+	}
+	w.Segment("NM1", "PR", "2", payer.Name, "", "", "", "", "PI", payer.PayerCode)
+
+	// Patient (loop 2000C), only when the patient is not the subscriber
+	if !self {
+		hl++
+		w.Segment("HL", hl, subscriberHL, "23", 0)
+		w.Segment("PAT", patientRelationship(coverage.SubscriberRelationship))
+		w.Segment("NM1", "QC", "1", patient.LastName, patient.FirstName)
+		writeAddress(w, address)
+		w.Segment("DMG", "D8", claimDate(patient.DateOfBirth), genderCode(patient.Gender))
+	}
+
+	// Claim (loop 2300)
+	w.Segment("CLM", claim.ControlNumber, formatAmount(claim.TotalChargeCents), "", "",
+		x12.Composite{claim.PlaceOfService, "B", "1"}, "Y", "A", "Y", "Y")
+	diagnoses := make([]interface{}, len(claim.Diagnoses))
+	for i, diagnosis := range claim.Diagnoses {
+		qualifier := "ABF"
+		if i == 0 {
+			qualifier = "ABK"
+		}
+		diagnoses[i] = x12.Composite{qualifier, strings.ReplaceAll(diagnosis.Code, ".", "")}
+	}
+	w.Segment("HI", diagnoses...)
+
+	// Service lines (loop 2400)
+	for _, line := range claim.Lines {
+		w.Segment("LX", line.LineNumber)
+		w.Segment("SV1", x12.Composite{"HC", line.ProcedureCode}, formatAmount(line.ChargeCents), "UN", line.Units, "", "",
+			x12.Composite(strings.Split(line.DiagnosisPointers, ",")))
+		serviceDate := line.ServiceDate
+		if serviceDate.IsZero() {
+			serviceDate = claim.ServiceDate
+		}
+		w.Segment("DTP", "472", "D8", claimDate(serviceDate))
+	}
+	return hl
+}
+
+func writeAddress(w *x12.Writer, address postalAddress) {
+	w.Segment("N3", address.street)
+	w.Segment("N4", address.city, address.state, address.zip)
+}
+
+// payerResponsibility is SBR01: primary, secondary or tertiary.
+func payerResponsibility(priority int) string {
+	switch priority {
+	case 2:
+		return "S"
+	case 3:
+		return "T"
+	}
+	return "P"
+}
+
+// claimFilingIndicator is SBR09, the kind of insurance billed.
+func claimFilingIndicator(planType models.PlanType) string {
+	switch planType {
+	case models.PlanHMO:
+		return "HM"
+	case models.PlanPPO:
+		return "12"
+	case models.PlanPOS:
+		return "13"
+	case models.PlanEPO:
+		return "14"
+	case models.PlanMedicare:
+		return "MB"
+	case models.PlanMedicaid:
+		return "MC"
+	}
+	return "CI"
+}
+
+// patientRelationship is PAT01, the patient's relationship to the
+// subscriber.
+func patientRelationship(relationship models.SubscriberRelationship) string {
+	switch relationship {
+	case models.SubscriberSpouse:
+		return "01"
+	case models.SubscriberChild:
+		return "19"
+	}
+	return "G8"
+}
+
+func genderCode(gender string) string {
+	switch strings.ToLower(strings.TrimSpace(gender)) {
+	case "m", "male":
+		return "M"
+	case "f", "female":
+		return "F"
+	}
+	return "U"
+}
+
+// formatAmount writes cents as an X12 decimal amount without trailing
+// zeros, e.g. 12050 as "120.5".
+func formatAmount(cents int64) string {
+	sign := ""
+	if cents < 0 {
+		sign, cents = "-", -cents
+	}
+	if cents%100 == 0 {
+		return fmt.Sprintf("%s%d", sign, cents/100)
+	}
+	return strings.TrimRight(fmt.Sprintf("%s%d.%02d", sign, cents/100, cents%100), "0")
+}
+
+// parseAmount reads an X12 decimal amount into cents. An empty amount is
+// zero.
+func parseAmount(value string) (int64, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, nil
+	}
+	negative := strings.HasPrefix(value, "-")
+	value = strings.TrimPrefix(value, "-")
+	whole, fraction, _ := strings.Cut(value, ".")
+	if len(fraction) > 2 {
+		return 0, fmt.Errorf("amount %q has more than two decimals", value)
+	}
+	fraction += strings.Repeat("0", 2-len(fraction))
+	if whole == "" {
+		whole = "0"
+	}
+	units, err := strconv.ParseInt(whole, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount %q", value)
+	}
+	cents, err := strconv.ParseInt(fraction, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount %q", value)
+	}
+	total := units*100 + cents
+	if negative {
+		total = -total
+	}
+	return total, nil
+}
+
+// remittanceAdvice is one 835 transaction: a single check or EFT.
+type remittanceAdvice struct {
+	traceNumber string
+	payerName   string
+	paymentDate *time.Time
+	totalPaid   int64
+	claims      []claimPayment
+}
+
+// claimPayment is a CLP loop, the payer's decision on one claim.
+type claimPayment struct {
+	controlNumber         string
+	statusCode            string
+	payerClaimNumber      string
+	charge                int64
+	paid                  int64
+	patientResponsibility int64
+	adjustments           int64
+	lines                 []servicePayment
+}
+
+// servicePayment is an SVC loop, the payment for one service line.
+type servicePayment struct {
+	procedureCode string
+	charge        int64
+	paid          int64
+	adjustments   int64
+}
+
+// parse835 reads the remittance advices in an 835 interchange. Adjustments
+// are the CAS amounts other than patient responsibility, which CLP05
+// already reports.
+func parse835(data []byte) ([]remittanceAdvice, error) {
+	segments, d, err := x12.Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRemittance, err)
+	}
+
+	var advices []remittanceAdvice
+	var advice *remittanceAdvice
+	var claim *claimPayment
+	var service *servicePayment
+	amount := func(segment x12.Segment, i int) int64 {
+		if err != nil {
+			return 0
+		}
+		var value int64
+		value, err = parseAmount(segment.Element(i))
+		return value
+	}
+
+	for _, segment := range segments {
+		if advice == nil && segment.ID() != "ST" {
+			continue
+		}
+		switch segment.ID() {
+		case "ST":
+			if segment.Element(1) != "835" {
+				return nil, fmt.Errorf("%w: transaction set %s", ErrInvalidRemittance, segment.Element(1))
+			}
+			advices = append(advices, remittanceAdvice{})
+			advice, claim, service = &advices[len(advices)-1], nil, nil
+		case "BPR":
+			advice.totalPaid = amount(segment, 2)
+			if date, parseErr := time.Parse("20060102", segment.Element(16)); parseErr == nil {
+				advice.paymentDate = &date
+			}
+		case "TRN":
+			advice.traceNumber = strings.TrimSpace(segment.Element(2))
+		case "N1":
+			if segment.Element(1) == "PR" {
+				advice.payerName = strings.TrimSpace(segment.Element(2))
+			}
+		case "CLP":
+			advice.claims = append(advice.claims, claimPayment{
+				controlNumber:         strings.TrimSpace(segment.Element(1)),
+				statusCode:            segment.Element(2),
+				charge:                amount(segment, 3),
+				paid:                  amount(segment, 4),
+				patientResponsibility: amount(segment, 5),
+				payerClaimNumber:      strings.TrimSpace(segment.Element(7)),
+			})
+			claim, service = &advice.claims[len(advice.claims)-1], nil
+		case "SVC":
+			if claim == nil {
+				return nil, fmt.Errorf("%w: SVC outside a claim", ErrInvalidRemittance)
+			}
+			claim.lines = append(claim.lines, servicePayment{
+				procedureCode: segment.Component(1, 2, d),
+				charge:        amount(segment, 2),
+				paid:          amount(segment, 3),
+			})
+			service = &claim.lines[len(claim.lines)-1]
+		case "CAS":
+			if claim == nil || segment.Element(1) == "PR" {
+				continue
+			}
+			var adjusted int64
+			for i := 3; i < len(segment); i += 3 {
+				adjusted += amount(segment, i)
+			}
+			claim.adjustments += adjusted
+			if service != nil {
+				service.adjustments += adjusted
+			}
+		case "SE":
+			if advice.traceNumber == "" {
+				return nil, fmt.Errorf("%w: payment has no TRN trace number", ErrInvalidRemittance)
+			}
+			advice, claim, service = nil, nil, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s segment: %v", ErrInvalidRemittance, segment.ID(), err)
+		}
+	}
+	if advice != nil {
+		return nil, fmt.Errorf("%w: transaction is missing its SE trailer", ErrInvalidRemittance)
+	}
+	if len(advices) == 0 {
+		return nil, fmt.Errorf("%w: no 835 transactions found", ErrInvalidRemittance)
+	}
+	return advices, nil
+}
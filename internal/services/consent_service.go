@@ -0,0 +1,281 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"healthcare-portal/internal/models"
+	"healthcare-portal/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrConsentNotFound         = errors.New("consent not found")
+	ErrConsentTextNotFound     = errors.New("consent text not found")
+	ErrConsentAlreadyRevoked   = errors.New("consent has already been revoked")
+	ErrGuardianConsentRequired = errors.New("consent for a patient under 18 must be given by one of their guardians")
+)
+
+// ConsentChecker is the hook features that contact patients or share their
+// data call first. A patient without a current consent of the type has not
+// agreed, whatever they agreed to in the past.
+type ConsentChecker interface {
+	HasConsent(patientID uint, consentType models.ConsentType) (bool, error)
+}
+
+type ConsentService interface {
+	WithTenant(tenantID uint) ConsentService
+	AllTenants() ConsentService
+	ConsentChecker
+	PublishText(text *models.ConsentText) error
+	GetTexts(consentType models.ConsentType) ([]models.ConsentText, error)
+	GetCurrentText(consentType models.ConsentType) (*models.ConsentText, error)
+	GrantConsent(consent *models.Consent) error
+	RevokeConsent(patientID, id, revokedBy uint, reason string) (*models.Consent, error)
+	GetConsentByID(patientID, id uint) (*models.Consent, error)
+	GetPatientConsents(patientID uint, includeHistory bool) ([]models.Consent, error)
+	ConsentedPatientIDs(consentType models.ConsentType) ([]uint, error)
+}
+
+type consentService struct {
+	consentRepo       repository.ConsentRepository
+	patientRepo       repository.PatientRepository
+	relatedPersonRepo repository.RelatedPersonRepository
+}
+
+func NewConsentService(consentRepo repository.ConsentRepository, patientRepo repository.PatientRepository, relatedPersonRepo repository.RelatedPersonRepository) ConsentService {
+	return &consentService{consentRepo: consentRepo, patientRepo: patientRepo, relatedPersonRepo: relatedPersonRepo}
+}
+
+// WithTenant returns a service that only sees tenantID's consents.
+func (s *consentService) WithTenant(tenantID uint) ConsentService {
+	return &consentService{
+		consentRepo:       s.consentRepo.WithTenant(tenantID),
+		patientRepo:       s.patientRepo.WithTenant(tenantID),
+		relatedPersonRepo: s.relatedPersonRepo.WithTenant(tenantID),
+	}
+}
+
+// AllTenants returns a service that checks consents in every tenant. It is
+// only for AppointmentReminderWorker.
+func (s *consentService) AllTenants() ConsentService {
+	return &consentService{
+		consentRepo:       s.consentRepo.AllTenants(),
+		patientRepo:       s.patientRepo,
+		relatedPersonRepo: s.relatedPersonRepo,
+	}
+}
+
+// PublishText stores a new version of a consent type's wording. It takes
+// effect immediately unless EffectiveFrom is set.
+func (s *consentService) PublishText(text *models.ConsentText) error {
+	text.Type = models.ConsentType(strings.ToLower(strings.TrimSpace(string(text.Type))))
+	text.Title = strings.TrimSpace(text.Title)
+	text.Body = strings.TrimSpace(text.Body)
+	if !text.Type.IsValid() {
+		return errors.New("invalid consent type")
+	}
+	if text.Title == "" || text.Body == "" {
+		return errors.New("consent text needs a title and a body")
+	}
+	if text.EffectiveFrom.IsZero() {
+		text.EffectiveFrom = time.Now()
+	}
+	return s.consentRepo.CreateText(text)
+}
+
+func (s *consentService) GetTexts(consentType models.ConsentType) ([]models.ConsentText, error) {
+	if consentType != "" && !consentType.IsValid() {
+		return nil, errors.New("invalid consent type")
+	}
+	return s.consentRepo.FindTexts(consentType)
+}
+
+func (s *consentService) GetCurrentText(consentType models.ConsentType) (*models.ConsentText, error) {
+	text, err := s.consentRepo.FindCurrentText(consentType, time.Now())
+	if err != nil {
+		return nil, ErrConsentTextNotFound
+	}
+	return text, nil
+}
+
+// GrantConsent records the patient agreeing to a consent text, by default
+// the current version of the type. It replaces any consent of the same type
+// the patient already has. Minors consent through one of their guardians.
+func (s *consentService) GrantConsent(consent *models.Consent) error {
+	consent.Type = models.ConsentType(strings.ToLower(strings.TrimSpace(string(consent.Type))))
+	if !consent.Type.IsValid() {
+		return errors.New("invalid consent type")
+	}
+
+	now := time.Now()
+	if consent.GrantedAt.IsZero() {
+		consent.GrantedAt = now
+	}
+	if consent.GrantedAt.After(now) {
+		return errors.New("consent cannot be granted in the future")
+	}
+
+	patient, err := s.patientRepo.FindByID(consent.PatientID)
+	if err != nil {
+		return ErrPatientNotFound
+	}
+
+	var text *models.ConsentText
+	if consent.ConsentTextID != 0 {
+		text, err = s.consentRepo.FindTextByID(consent.ConsentTextID)
+		if err != nil || text.Type != consent.Type {
+			return ErrConsentTextNotFound
+		}
+	} else if text, err = s.consentRepo.FindCurrentText(consent.Type, consent.GrantedAt); err != nil {
+		return ErrConsentTextNotFound
+	}
+
+	if consent.RelatedPersonID != nil {
+		person, err := s.relatedPersonRepo.FindByID(*consent.RelatedPersonID)
+		if err != nil || person.PatientID != patient.ID {
+			return ErrRelatedPersonNotFound
+		}
+		if patient.IsMinorOn(consent.GrantedAt) && !person.IsGuardian {
+			return ErrGuardianConsentRequired
+		}
+	} else if patient.IsMinorOn(consent.GrantedAt) {
+		return ErrGuardianConsentRequired
+	}
+
+	consent.ID = 0
+	consent.ConsentTextID = text.ID
+	consent.TextVersion = text.Version
+	consent.Status = models.ConsentGranted
+	consent.RevokedAt = nil
+	consent.RevokedBy = nil
+	consent.RevocationReason = ""
+	consent.Notes = strings.TrimSpace(consent.Notes)
+	if err := s.consentRepo.Grant(consent); err != nil {
+		return err
+	}
+	consent.ConsentText = text
+	return nil
+}
+
+// RevokeConsent withdraws a granted consent from now on.
+func (s *consentService) RevokeConsent(patientID, id, revokedBy uint, reason string) (*models.Consent, error) {
+	consent, err := s.GetConsentByID(patientID, id)
+	if err != nil {
+		return nil, err
+	}
+	if consent.Status == models.ConsentRevoked {
+		return nil, ErrConsentAlreadyRevoked
+	}
+
+	now := time.Now()
+	consent.Status = models.ConsentRevoked
+	consent.RevokedAt = &now
+	consent.RevokedBy = &revokedBy
+	consent.RevocationReason = strings.TrimSpace(reason)
+	consent.NeedsReconsent = false
+	if err := s.consentRepo.Update(consent); err != nil {
+		return nil, err
+	}
+	return consent, nil
+}
+
+func (s *consentService) GetConsentByID(patientID, id uint) (*models.Consent, error) {
+	consent, err := s.consentRepo.FindByID(id)
+	if err != nil || consent.PatientID != patientID {
+		return nil, ErrConsentNotFound
+	}
+	if err := s.flagReconsent([]*models.Consent{consent}); err != nil {
+		return nil, err
+	}
+	return consent, nil
+}
+
+// GetPatientConsents returns the patient's granted consents, or with
+// includeHistory every consent ever recorded, newest first.
+func (s *consentService) GetPatientConsents(patientID uint, includeHistory bool) ([]models.Consent, error) {
+	if _, err := s.patientRepo.FindByID(patientID); err != nil {
+		return nil, ErrPatientNotFound
+	}
+	consents, err := s.consentRepo.FindByPatientID(patientID, !includeHistory)
+	if err != nil {
+		return nil, err
+	}
+
+	pointers := make([]*models.Consent, len(consents))
+	for i := range consents {
+		pointers[i] = &consents[i]
+	}
+	if err := s.flagReconsent(pointers); err != nil {
+		return nil, err
+	}
+	return consents, nil
+}
+
+// HasConsent reports whether the patient currently agrees to consentType:
+// they have a granted consent and no later text version requiring
+// reconsent has taken effect since. It works unscoped as well, so
+// background workers can check patients of every tenant.
+func (s *consentService) HasConsent(patientID uint, consentType models.ConsentType) (bool, error) {
+	consent, err := s.consentRepo.FindActive(patientID, consentType)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	minVersion, err := s.minimumVersion(s.consentRepo.WithTenant(consent.TenantID), consentType)
+	if err != nil {
+		return false, err
+	}
+	return consent.TextVersion >= minVersion, nil
+}
+
+// ConsentedPatientIDs returns the patients who currently agree to
+// consentType.
+func (s *consentService) ConsentedPatientIDs(consentType models.ConsentType) ([]uint, error) {
+	minVersion, err := s.minimumVersion(s.consentRepo, consentType)
+	if err != nil {
+		return nil, err
+	}
+	return s.consentRepo.FindConsentedPatientIDs(consentType, minVersion)
+}
+
+// minimumVersion returns the oldest text version of consentType that still
+// counts: the newest version in effect that requires reconsent, or 0.
+func (s *consentService) minimumVersion(repo repository.ConsentRepository, consentType models.ConsentType) (int, error) {
+	texts, err := repo.FindTexts(consentType)
+	if err != nil {
+		return 0, err
+	}
+	now := time.Now()
+	for _, text := range texts {
+		if text.RequiresReconsent && !text.EffectiveFrom.After(now) {
+			return text.Version, nil
+		}
+	}
+	return 0, nil
+}
+
+// flagReconsent marks granted consents given to text versions that no
+// longer count.
+func (s *consentService) flagReconsent(consents []*models.Consent) error {
+	minVersions := make(map[models.ConsentType]int)
+	for _, consent := range consents {
+		if consent.Status != models.ConsentGranted {
+			continue
+		}
+		minVersion, ok := minVersions[consent.Type]
+		if !ok {
+			var err error
+			if minVersion, err = s.minimumVersion(s.consentRepo, consent.Type); err != nil {
+				return err
+			}
+			minVersions[consent.Type] = minVersion
+		}
+		consent.NeedsReconsent = consent.TextVersion < minVersion
+	}
+	return nil
+}
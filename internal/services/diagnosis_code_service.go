@@ -0,0 +1,126 @@
+package services
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"healthcare-portal/internal/models"
+	"healthcare-portal/internal/repository"
+)
+
+const (
+	defaultCodeSearchLimit = 20
+	maxCodeSearchLimit     = 100
+)
+
+var icd10Pattern = regexp.MustCompile(`^[A-Z][0-9][0-9A-Z](\.[0-9A-Z]{1,4})?$`)
+
+type DiagnosisCodeService interface {
+	SearchCodes(query string, limit int) ([]models.DiagnosisCode, error)
+	LookupCode(code string) (*models.DiagnosisCode, error)
+	ImportCodes(codes []models.DiagnosisCode) (int, error)
+}
+
+type diagnosisCodeService struct {
+	codeRepo repository.DiagnosisCodeRepository
+}
+
+func NewDiagnosisCodeService(codeRepo repository.DiagnosisCodeRepository) DiagnosisCodeService {
+	return &diagnosisCodeService{codeRepo: codeRepo}
+}
+
+// SearchCodes matches a code prefix ("E11", "E119", "e11.9") or, for any
+// other query, descriptions containing every word of the query.
+func (s *diagnosisCodeService) SearchCodes(query string, limit int) ([]models.DiagnosisCode, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, errors.New("search query is required")
+	}
+	if limit <= 0 {
+		limit = defaultCodeSearchLimit
+	}
+	if limit > maxCodeSearchLimit {
+		limit = maxCodeSearchLimit
+	}
+
+	if looksLikeCode(query) {
+		return s.codeRepo.SearchByPrefix(NormalizeDiagnosisCode(query), limit)
+	}
+	return s.codeRepo.SearchByKeywords(strings.Fields(query), limit)
+}
+
+func (s *diagnosisCodeService) LookupCode(code string) (*models.DiagnosisCode, error) {
+	diagnosisCode, err := s.codeRepo.FindByCode(NormalizeDiagnosisCode(code))
+	if err != nil {
+		return nil, fmt.Errorf("unknown ICD-10 code %q", code)
+	}
+	return diagnosisCode, nil
+}
+
+// ImportCodes loads or refreshes codes in the table and returns how many
+// were written.
+func (s *diagnosisCodeService) ImportCodes(codes []models.DiagnosisCode) (int, error) {
+	if err := s.codeRepo.Upsert(codes); err != nil {
+		return 0, err
+	}
+	return len(codes), nil
+}
+
+// NormalizeDiagnosisCode upper-cases a code and puts the dot after the
+// category, so "e119" and "E11.9" are the same code.
+func NormalizeDiagnosisCode(code string) string {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	code = strings.ReplaceAll(code, ".", "")
+	if len(code) > 3 {
+		code = code[:3] + "." + code[3:]
+	}
+	return code
+}
+
+// looksLikeCode reports whether a search query is a code prefix rather than
+// a description keyword: a letter followed by a digit.
+func looksLikeCode(query string) bool {
+	return len(query) >= 2 && !strings.Contains(query, " ") &&
+		(query[0] >= 'A' && query[0] <= 'Z' || query[0] >= 'a' && query[0] <= 'z') &&
+		query[1] >= '0' && query[1] <= '9'
+}
+
+// ParseDiagnosisCodes reads a code table in either the CMS order-file layout
+// ("E119    Type 2 diabetes mellitus without complications") or as CSV
+// ("E11.9,Type 2 diabetes mellitus without complications"). Blank lines,
+// "#" comments and a header on the first line are skipped.
+func ParseDiagnosisCodes(r io.Reader) ([]models.DiagnosisCode, error) {
+	var codes []models.DiagnosisCode
+	scanner := bufio.NewScanner(r)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		var code, description string
+		if i := strings.IndexAny(line, ", \t"); i > 0 {
+			code, description = line[:i], line[i+1:]
+		} else {
+			code = line
+		}
+		code = NormalizeDiagnosisCode(code)
+		description = strings.Trim(strings.TrimSpace(description), `"`)
+
+		if !icd10Pattern.MatchString(code) {
+			if lineNumber == 1 {
+				continue
+			}
+			return nil, fmt.Errorf("line %d: invalid ICD-10 code %q", lineNumber, code)
+		}
+		if description == "" {
+			return nil, fmt.Errorf("line %d: code %s has no description", lineNumber, code)
+		}
+		codes = append(codes, models.DiagnosisCode{Code: code, Description: description})
+	}
+	return codes, scanner.Err()
+}
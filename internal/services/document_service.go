@@ -0,0 +1,272 @@
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"healthcare-portal/internal/models"
+	"healthcare-portal/internal/repository"
+	"healthcare-portal/internal/storage"
+)
+
+var (
+	ErrDocumentNotFound  = errors.New("document not found")
+	ErrDocumentTooLarge  = errors.New("document exceeds the upload size limit")
+	ErrDocumentType      = errors.New("document type is not allowed; upload a PDF, image or plain text file")
+	ErrDocumentEmpty     = errors.New("document is empty")
+	ErrDocumentChecksum  = errors.New("document content does not match its checksum")
+	errDocumentSizeLimit = errors.New("size limit reached")
+)
+
+// allowedDocumentTypes are the sniffed content types accepted for upload.
+var allowedDocumentTypes = map[string]bool{
+	"application/pdf":           true,
+	"image/jpeg":                true,
+	"image/png":                 true,
+	"image/gif":                 true,
+	"image/webp":                true,
+	"image/bmp":                 true,
+	"image/tiff":                true,
+	"text/plain; charset=utf-8": true,
+}
+
+type DocumentService interface {
+	WithTenant(tenantID uint) DocumentService
+	UploadDocument(document *models.Document, content io.Reader) error
+	GetDocumentByID(id uint) (*models.Document, error)
+	GetPatientDocuments(patientID uint, category models.DocumentCategory) ([]models.Document, error)
+	OpenDocument(id uint) (*models.Document, io.ReadCloser, error)
+	UpdateDocument(document *models.Document) error
+	DeleteDocument(id uint) error
+}
+
+type documentService struct {
+	documentRepo repository.DocumentRepository
+	patientRepo  repository.PatientRepository
+	store        storage.BlobStore
+	maxBytes     int64
+}
+
+// NewDocumentService builds the service. Uploads larger than maxBytes are
+// rejected.
+func NewDocumentService(documentRepo repository.DocumentRepository, patientRepo repository.PatientRepository, store storage.BlobStore, maxBytes int64) DocumentService {
+	return &documentService{
+		documentRepo: documentRepo,
+		patientRepo:  patientRepo,
+		store:        store,
+		maxBytes:     maxBytes,
+	}
+}
+
+// WithTenant returns a service limited to tenantID's patients.
+func (s *documentService) WithTenant(tenantID uint) DocumentService {
+	return &documentService{
+		documentRepo: s.documentRepo.WithTenant(tenantID),
+		patientRepo:  s.patientRepo.WithTenant(tenantID),
+		store:        s.store,
+		maxBytes:     s.maxBytes,
+	}
+}
+
+// UploadDocument streams content into the blob store and records it. The
+// content type is sniffed from the first bytes, and the size and SHA-256
+// are computed while the content is written.
+func (s *documentService) UploadDocument(document *models.Document, content io.Reader) error {
+	if _, err := s.patientRepo.FindByID(document.PatientID); err != nil {
+		return errors.New("patient not found")
+	}
+	if err := normalizeDocument(document); err != nil {
+		return err
+	}
+
+	buffered := bufio.NewReaderSize(content, 512)
+	head, err := buffered.Peek(512)
+	if err != nil && err != io.EOF {
+		return err
+	}
+	if len(head) == 0 {
+		return ErrDocumentEmpty
+	}
+	contentType := sniffContentType(head)
+	if !allowedDocumentTypes[contentType] {
+		return ErrDocumentType
+	}
+
+	key, err := newDocumentKey(document.PatientID)
+	if err != nil {
+		return err
+	}
+	upload := &hashingReader{
+		reader: buffered,
+		hash:   sha256.New(),
+		limit:  s.maxBytes,
+	}
+	if err := s.store.Put(key, upload, contentType); err != nil {
+		if errors.Is(err, errDocumentSizeLimit) {
+			return ErrDocumentTooLarge
+		}
+		return fmt.Errorf("store document: %w", err)
+	}
+
+	document.ContentType = contentType
+	document.SizeBytes = upload.size
+	document.SHA256 = hex.EncodeToString(upload.hash.Sum(nil))
+	document.StorageKey = key
+	if err := s.documentRepo.Create(document); err != nil {
+		s.store.Delete(key)
+		return err
+	}
+	return nil
+}
+
+func (s *documentService) GetDocumentByID(id uint) (*models.Document, error) {
+	document, err := s.documentRepo.FindByID(id)
+	if err != nil {
+		return nil, ErrDocumentNotFound
+	}
+	return document, nil
+}
+
+func (s *documentService) GetPatientDocuments(patientID uint, category models.DocumentCategory) ([]models.Document, error) {
+	if _, err := s.patientRepo.FindByID(patientID); err != nil {
+		return nil, errors.New("patient not found")
+	}
+	return s.documentRepo.FindByPatientID(patientID, category)
+}
+
+// OpenDocument returns the document and a reader over its content. The
+// reader fails with ErrDocumentChecksum at the end of the content if the
+// blob no longer matches the checksum taken at upload.
+func (s *documentService) OpenDocument(id uint) (*models.Document, io.ReadCloser, error) {
+	document, err := s.GetDocumentByID(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	blob, err := s.store.Get(document.StorageKey)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, nil, fmt.Errorf("content for document %d is missing from storage", id)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return document, &verifyingReader{
+		ReadCloser: blob,
+		hash:       sha256.New(),
+		expected:   document.SHA256,
+	}, nil
+}
+
+// UpdateDocument changes the title, category and description. The content
+// cannot be replaced; upload a new document instead.
+func (s *documentService) UpdateDocument(document *models.Document) error {
+	existing, err := s.documentRepo.FindByID(document.ID)
+	if err != nil {
+		return ErrDocumentNotFound
+	}
+	if err := normalizeDocument(document); err != nil {
+		return err
+	}
+	existing.Title = document.Title
+	existing.Category = document.Category
+	existing.Description = document.Description
+	if err := s.documentRepo.Update(existing); err != nil {
+		return err
+	}
+	*document = *existing
+	return nil
+}
+
+// DeleteDocument removes the document from the patient's chart. The blob is
+// kept, like the soft-deleted row, so the record can be recovered.
+func (s *documentService) DeleteDocument(id uint) error {
+	if _, err := s.documentRepo.FindByID(id); err != nil {
+		return ErrDocumentNotFound
+	}
+	return s.documentRepo.Delete(id)
+}
+
+func normalizeDocument(document *models.Document) error {
+	document.Title = strings.TrimSpace(document.Title)
+	document.Description = strings.TrimSpace(document.Description)
+	document.FileName = filepath.Base(strings.ReplaceAll(strings.TrimSpace(document.FileName), "\\", "/"))
+	if document.FileName == "." || document.FileName == "/" {
+		document.FileName = ""
+	}
+	if document.Title == "" {
+		document.Title = strings.TrimSuffix(document.FileName, filepath.Ext(document.FileName))
+	}
+	if document.Title == "" {
+		return errors.New("title is required")
+	}
+	if document.Category == "" {
+		document.Category = models.DocumentOther
+	}
+	switch document.Category {
+	case models.DocumentReferralLetter, models.DocumentIdentification, models.DocumentImagingReport,
+		models.DocumentLabReport, models.DocumentInsuranceCard, models.DocumentOther:
+	default:
+		return errors.New("category must be referral_letter, identification, imaging_report, lab_report, insurance_card or other")
+	}
+	return nil
+}
+
+// sniffContentType extends http.DetectContentType with TIFF, which scanners
+// commonly produce.
+func sniffContentType(head []byte) string {
+	if bytes.HasPrefix(head, []byte("II*\x00")) || bytes.HasPrefix(head, []byte("MM\x00*")) {
+		return "image/tiff"
+	}
+	return http.DetectContentType(head)
+}
+
+func newDocumentKey(patientID uint) (string, error) {
+	random := make([]byte, 16)
+	if _, err := rand.Read(random); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("patients/%d/%s", patientID, hex.EncodeToString(random)), nil
+}
+
+// hashingReader hashes and counts what is read through it and fails once
+// more than limit bytes have been read.
+type hashingReader struct {
+	reader io.Reader
+	hash   hash.Hash
+	limit  int64
+	size   int64
+}
+
+func (r *hashingReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.size += int64(n)
+	if r.limit > 0 && r.size > r.limit {
+		return 0, errDocumentSizeLimit
+	}
+	r.hash.Write(p[:n])
+	return n, err
+}
+
+type verifyingReader struct {
+	io.ReadCloser
+	hash     hash.Hash
+	expected string
+}
+
+func (r *verifyingReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.hash.Write(p[:n])
+	if err == io.EOF && hex.EncodeToString(r.hash.Sum(nil)) != r.expected {
+		return n, ErrDocumentChecksum
+	}
+	return n, err
+}
@@ -0,0 +1,65 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"healthcare-portal/internal/models"
+)
+
+// EligibilityRequest asks whether a coverage pays for care on ServiceDate.
+// Coverage has its plan and payer loaded.
+type EligibilityRequest struct {
+	Patient     models.Patient
+	Coverage    models.Coverage
+	ServiceDate time.Time
+}
+
+// EligibilityResult is a payer's answer. Amounts are nil when the payer did
+// not say.
+type EligibilityResult struct {
+	Status                   models.EligibilityStatus
+	CopayCents               *int64
+	DeductibleRemainingCents *int64
+	Message                  string
+}
+
+// EligibilityChecker asks a payer, or a clearinghouse acting for it, about
+// a patient's coverage. Name is recorded as the source of every check.
+type EligibilityChecker interface {
+	Name() string
+	Check(request EligibilityRequest) (*EligibilityResult, error)
+}
+
+// LocalEligibilityChecker answers from the coverage on file instead of
+// asking the payer: a coverage is active when its dates include the
+// service date and its plan and payer are still in use. It stands in until
+// a clearinghouse is configured.
+type LocalEligibilityChecker struct{}
+
+func (LocalEligibilityChecker) Name() string {
+	return "local"
+}
+
+func (LocalEligibilityChecker) Check(request EligibilityRequest) (*EligibilityResult, error) {
+	coverage := request.Coverage
+	plan := coverage.Plan
+	switch {
+	case plan == nil || plan.Payer == nil:
+		return nil, fmt.Errorf("coverage %d has no plan or payer loaded", coverage.ID)
+	case !coverage.CoversOn(request.ServiceDate):
+		return &EligibilityResult{
+			Status:  models.EligibilityInactive,
+			Message: fmt.Sprintf("Coverage is not in force on %s", request.ServiceDate.Format("2006-01-02")),
+		}, nil
+	case !plan.IsActive || !plan.Payer.IsActive:
+		return &EligibilityResult{Status: models.EligibilityInactive, Message: "Plan is no longer offered"}, nil
+	}
+
+	copay := plan.CopayCents
+	return &EligibilityResult{
+		Status:     models.EligibilityActive,
+		CopayCents: &copay,
+		Message:    "Coverage on file is in force; not verified with the payer",
+	}, nil
+}
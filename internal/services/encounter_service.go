@@ -0,0 +1,208 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"healthcare-portal/internal/models"
+	"healthcare-portal/internal/repository"
+)
+
+var (
+	ErrEncounterNotFound  = errors.New("encounter not found")
+	ErrEncounterLocked    = errors.New("encounter is signed and locked; add an addendum instead")
+	ErrNotTreatingDoctor  = errors.New("only the treating doctor can change this encounter")
+	ErrEncounterNotSigned = errors.New("addenda can only be added to signed encounters")
+)
+
+// SOAPNote holds the editable sections of an encounter.
+type SOAPNote struct {
+	Subjective string
+	Objective  string
+	Assessment string
+	Plan       string
+}
+
+type EncounterService interface {
+	WithTenant(tenantID uint) EncounterService
+	CreateEncounter(appointmentID, doctorID uint, note SOAPNote) (*models.Encounter, error)
+	GetEncounterByID(id uint) (*models.Encounter, error)
+	GetEncounterByAppointment(appointmentID uint) (*models.Encounter, error)
+	UpdateEncounter(id, doctorID uint, note SOAPNote) (*models.Encounter, error)
+	SignEncounter(id, doctorID uint) (*models.Encounter, error)
+	AddAddendum(id, authorID uint, content string) (*models.EncounterAddendum, error)
+	GetPatientTimeline(patientID uint) ([]models.Encounter, error)
+}
+
+type encounterService struct {
+	encounterRepo   repository.EncounterRepository
+	appointmentRepo repository.AppointmentRepository
+	patientRepo     repository.PatientRepository
+}
+
+func NewEncounterService(encounterRepo repository.EncounterRepository, appointmentRepo repository.AppointmentRepository, patientRepo repository.PatientRepository) EncounterService {
+	return &encounterService{
+		encounterRepo:   encounterRepo,
+		appointmentRepo: appointmentRepo,
+		patientRepo:     patientRepo,
+	}
+}
+
+// WithTenant returns a service limited to tenantID's encounters.
+func (s *encounterService) WithTenant(tenantID uint) EncounterService {
+	return &encounterService{
+		encounterRepo:   s.encounterRepo.WithTenant(tenantID),
+		appointmentRepo: s.appointmentRepo.WithTenant(tenantID),
+		patientRepo:     s.patientRepo.WithTenant(tenantID),
+	}
+}
+
+// CreateEncounter opens the draft note for an appointment. Only the doctor
+// the appointment is booked with can document it.
+func (s *encounterService) CreateEncounter(appointmentID, doctorID uint, note SOAPNote) (*models.Encounter, error) {
+	appointment, err := s.appointmentRepo.FindByID(appointmentID)
+	if err != nil {
+		return nil, errors.New("appointment not found")
+	}
+	if appointment.DoctorID != doctorID {
+		return nil, ErrNotTreatingDoctor
+	}
+	if appointment.Status == models.StatusCancelled {
+		return nil, errors.New("cannot document a cancelled appointment")
+	}
+	if _, err := s.encounterRepo.FindByAppointmentID(appointmentID); err == nil {
+		return nil, errors.New("appointment already has an encounter")
+	}
+
+	encounter := &models.Encounter{
+		AppointmentID: appointment.ID,
+		PatientID:     appointment.PatientID,
+		DoctorID:      appointment.DoctorID,
+		VisitDate:     visitTime(appointment),
+		Status:        models.EncounterDraft,
+	}
+	applySOAP(encounter, note)
+
+	if err := s.encounterRepo.Create(encounter); err != nil {
+		return nil, err
+	}
+	return s.encounterRepo.FindByID(encounter.ID)
+}
+
+func (s *encounterService) GetEncounterByID(id uint) (*models.Encounter, error) {
+	encounter, err := s.encounterRepo.FindByID(id)
+	if err != nil {
+		return nil, ErrEncounterNotFound
+	}
+	return encounter, nil
+}
+
+func (s *encounterService) GetEncounterByAppointment(appointmentID uint) (*models.Encounter, error) {
+	encounter, err := s.encounterRepo.FindByAppointmentID(appointmentID)
+	if err != nil {
+		return nil, ErrEncounterNotFound
+	}
+	return encounter, nil
+}
+
+// UpdateEncounter replaces the SOAP sections of a draft encounter.
+func (s *encounterService) UpdateEncounter(id, doctorID uint, note SOAPNote) (*models.Encounter, error) {
+	encounter, err := s.editable(id, doctorID)
+	if err != nil {
+		return nil, err
+	}
+
+	applySOAP(encounter, note)
+	if err := s.encounterRepo.Update(encounter); err != nil {
+		return nil, err
+	}
+	return encounter, nil
+}
+
+// SignEncounter locks the encounter. An encounter needs an assessment before
+// it can be signed.
+func (s *encounterService) SignEncounter(id, doctorID uint) (*models.Encounter, error) {
+	encounter, err := s.editable(id, doctorID)
+	if err != nil {
+		return nil, err
+	}
+	if encounter.Assessment == "" {
+		return nil, errors.New("assessment is required before signing")
+	}
+
+	now := time.Now()
+	encounter.Status = models.EncounterSigned
+	encounter.SignedAt = &now
+	if err := s.encounterRepo.Update(encounter); err != nil {
+		return nil, err
+	}
+	return encounter, nil
+}
+
+// AddAddendum appends a note to a signed encounter. Any doctor of the
+// practice may add one; the author is recorded with it.
+func (s *encounterService) AddAddendum(id, authorID uint, content string) (*models.EncounterAddendum, error) {
+	encounter, err := s.encounterRepo.FindByID(id)
+	if err != nil {
+		return nil, ErrEncounterNotFound
+	}
+	if !encounter.IsSigned() {
+		return nil, ErrEncounterNotSigned
+	}
+	content = strings.TrimSpace(content)
+	if content == "" {
+		return nil, errors.New("addendum content is required")
+	}
+
+	addendum := &models.EncounterAddendum{
+		EncounterID: encounter.ID,
+		AuthorID:    authorID,
+		Content:     content,
+	}
+	if err := s.encounterRepo.AddAddendum(addendum); err != nil {
+		return nil, err
+	}
+	return addendum, nil
+}
+
+// GetPatientTimeline lists the patient's encounters in visit order.
+func (s *encounterService) GetPatientTimeline(patientID uint) ([]models.Encounter, error) {
+	if _, err := s.patientRepo.FindByID(patientID); err != nil {
+		return nil, errors.New("patient not found")
+	}
+	return s.encounterRepo.FindByPatientID(patientID)
+}
+
+func (s *encounterService) editable(id, doctorID uint) (*models.Encounter, error) {
+	encounter, err := s.encounterRepo.FindByID(id)
+	if err != nil {
+		return nil, ErrEncounterNotFound
+	}
+	if encounter.DoctorID != doctorID {
+		return nil, ErrNotTreatingDoctor
+	}
+	if encounter.IsSigned() {
+		return nil, ErrEncounterLocked
+	}
+	return encounter, nil
+}
+
+func applySOAP(encounter *models.Encounter, note SOAPNote) {
+	encounter.Subjective = strings.TrimSpace(note.Subjective)
+	encounter.Objective = strings.TrimSpace(note.Objective)
+	encounter.Assessment = strings.TrimSpace(note.Assessment)
+	encounter.Plan = strings.TrimSpace(note.Plan)
+}
+
+// visitTime is the appointment's start, used to order the timeline.
+func visitTime(appointment *models.Appointment) time.Time {
+	if appointment.StartsAt != nil {
+		return *appointment.StartsAt
+	}
+	start, _, ok := appointment.Window()
+	if !ok {
+		return appointment.Date
+	}
+	return appointment.Date.Add(time.Duration(start) * time.Minute)
+}
@@ -0,0 +1,126 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"healthcare-portal/internal/models"
+	"healthcare-portal/internal/repository"
+)
+
+type ExportService interface {
+	WithTenant(tenantID uint) ExportService
+	DeidentifiedPatients() ([]models.DeidentifiedPatient, error)
+}
+
+type exportService struct {
+	consentService   ConsentService
+	patientRepo      repository.PatientRepository
+	problemRepo      repository.ProblemRepository
+	immunizationRepo repository.ImmunizationRepository
+	pseudonymKey     []byte
+}
+
+// NewExportService builds the export service. pseudonymKey keys the
+// pseudonyms given to exported patients; changing it unlinks new exports
+// from earlier ones.
+func NewExportService(consentService ConsentService, patientRepo repository.PatientRepository, problemRepo repository.ProblemRepository, immunizationRepo repository.ImmunizationRepository, pseudonymKey string) ExportService {
+	return &exportService{
+		consentService:   consentService,
+		patientRepo:      patientRepo,
+		problemRepo:      problemRepo,
+		immunizationRepo: immunizationRepo,
+		pseudonymKey:     []byte(pseudonymKey),
+	}
+}
+
+// WithTenant returns a service that only exports tenantID's patients.
+func (s *exportService) WithTenant(tenantID uint) ExportService {
+	return &exportService{
+		consentService:   s.consentService.WithTenant(tenantID),
+		patientRepo:      s.patientRepo.WithTenant(tenantID),
+		problemRepo:      s.problemRepo.WithTenant(tenantID),
+		immunizationRepo: s.immunizationRepo.WithTenant(tenantID),
+		pseudonymKey:     s.pseudonymKey,
+	}
+}
+
+// DeidentifiedPatients exports the problem lists and immunizations of the
+// patients who currently consent to research use. Everyone else is left
+// out entirely.
+func (s *exportService) DeidentifiedPatients() ([]models.DeidentifiedPatient, error) {
+	patientIDs, err := s.consentService.ConsentedPatientIDs(models.ConsentResearch)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	records := []models.DeidentifiedPatient{}
+	for _, patientID := range patientIDs {
+		patient, err := s.patientRepo.FindByID(patientID)
+		if err != nil {
+			// Deleted or merged away since consenting.
+			continue
+		}
+		problems, err := s.problemRepo.FindByPatientID(patientID, "")
+		if err != nil {
+			return nil, err
+		}
+		immunizations, err := s.immunizationRepo.FindByPatientID(patientID)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, s.deidentify(patient, problems, immunizations, now))
+	}
+	return records, nil
+}
+
+func (s *exportService) deidentify(patient *models.Patient, problems []models.Problem, immunizations []models.Immunization, now time.Time) models.DeidentifiedPatient {
+	record := models.DeidentifiedPatient{
+		Pseudonym:     s.pseudonym(patient),
+		Gender:        patient.Gender,
+		Problems:      make([]models.DeidentifiedProblem, 0, len(problems)),
+		Immunizations: make([]models.DeidentifiedImmunization, 0, len(immunizations)),
+	}
+	if age := patient.AgeOn(now); age >= 90 {
+		record.AgeOver89 = true
+	} else if age >= 0 {
+		record.BirthYear = yearOf(&patient.DateOfBirth)
+	}
+
+	for _, problem := range problems {
+		record.Problems = append(record.Problems, models.DeidentifiedProblem{
+			Code:        problem.Code,
+			Description: problem.Description,
+			Status:      problem.Status,
+			OnsetYear:   yearOf(problem.OnsetDate),
+		})
+	}
+	for _, immunization := range immunizations {
+		record.Immunizations = append(record.Immunizations, models.DeidentifiedImmunization{
+			VaccineCode:      immunization.VaccineCode,
+			DoseNumber:       immunization.DoseNumber,
+			AdministeredYear: immunization.AdministeredAt.Year(),
+		})
+	}
+	return record
+}
+
+// pseudonym derives a stable identifier for the patient that cannot be
+// traced back to them without the key.
+func (s *exportService) pseudonym(patient *models.Patient) string {
+	mac := hmac.New(sha256.New, s.pseudonymKey)
+	fmt.Fprintf(mac, "%d:%d", patient.TenantID, patient.ID)
+	return hex.EncodeToString(mac.Sum(nil)[:16])
+}
+
+func yearOf(t *time.Time) *int {
+	if t == nil || t.IsZero() {
+		return nil
+	}
+	year := t.Year()
+	return &year
+}
@@ -0,0 +1,241 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"healthcare-portal/internal/models"
+)
+
+// AgeInterval is a calendar interval written as years, months, weeks and
+// days, e.g. "2m", "6w", "4y6m" or "0d". Months and years are calendar
+// months and years, so "2m" from 31 Dec is 28 or 29 Feb plus the overflow.
+type AgeInterval struct {
+	Years, Months, Days int
+	set                 bool
+}
+
+var intervalPattern = regexp.MustCompile(`^(?:(\d+)y)?(?:(\d+)m)?(?:(\d+)w)?(?:(\d+)d)?$`)
+
+func ParseAgeInterval(text string) (AgeInterval, error) {
+	text = strings.ToLower(strings.ReplaceAll(text, " ", ""))
+	match := intervalPattern.FindStringSubmatch(text)
+	if text == "" || match == nil {
+		return AgeInterval{}, fmt.Errorf("invalid interval %q", text)
+	}
+	number := func(s string) int {
+		n, _ := strconv.Atoi(s)
+		return n
+	}
+	return AgeInterval{
+		Years:  number(match[1]),
+		Months: number(match[2]),
+		Days:   number(match[3])*7 + number(match[4]),
+		set:    true,
+	}, nil
+}
+
+func (a *AgeInterval) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err != nil {
+		return err
+	}
+	parsed, err := ParseAgeInterval(text)
+	if err != nil {
+		return err
+	}
+	*a = parsed
+	return nil
+}
+
+// IsSet reports whether the interval was given in the schedule file.
+func (a AgeInterval) IsSet() bool {
+	return a.set
+}
+
+// From returns t moved forward by the interval.
+func (a AgeInterval) From(t time.Time) time.Time {
+	return t.AddDate(a.Years, a.Months, a.Days)
+}
+
+// ImmunizationSchedule is the locally maintained schedule definition file.
+type ImmunizationSchedule struct {
+	Version  string            `json:"version"`
+	Vaccines []VaccineSchedule `json:"vaccines"`
+}
+
+// VaccineSchedule describes a primary series and, optionally, a booster
+// that repeats after it. A vaccine with only a booster (e.g. influenza)
+// starts at Booster.StartAge.
+type VaccineSchedule struct {
+	Code    string           `json:"code"`
+	Name    string           `json:"name"`
+	Doses   []DoseSchedule   `json:"doses"`
+	Booster *BoosterSchedule `json:"booster"`
+	// MaxAge stops recommending an unfinished series after this age.
+	MaxAge AgeInterval `json:"max_age"`
+}
+
+// DoseSchedule is one dose of a primary series. Ages are measured from the
+// date of birth; MinInterval is the minimum gap after the previous dose and
+// pushes the dose back when the previous one was given late.
+type DoseSchedule struct {
+	MinAge      AgeInterval `json:"min_age"`
+	DueAge      AgeInterval `json:"due_age"`
+	OverdueAge  AgeInterval `json:"overdue_age"`
+	MinInterval AgeInterval `json:"min_interval"`
+}
+
+// BoosterSchedule repeats a dose Every interval after the last one. It is
+// overdue Grace after it falls due.
+type BoosterSchedule struct {
+	StartAge AgeInterval `json:"start_age"`
+	Every    AgeInterval `json:"every"`
+	Grace    AgeInterval `json:"grace"`
+}
+
+// LoadImmunizationSchedule reads and validates a schedule file.
+func LoadImmunizationSchedule(path string) (*ImmunizationSchedule, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var schedule ImmunizationSchedule
+	if err := json.Unmarshal(data, &schedule); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+
+	// Intervals are compared by applying them to a fixed date.
+	reference := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	seen := make(map[string]bool, len(schedule.Vaccines))
+	for _, vaccine := range schedule.Vaccines {
+		code := strings.ToLower(vaccine.Code)
+		if code == "" || vaccine.Name == "" {
+			return nil, fmt.Errorf("vaccine %q: code and name are required", vaccine.Code)
+		}
+		if seen[code] {
+			return nil, fmt.Errorf("vaccine %s is defined twice", vaccine.Code)
+		}
+		seen[code] = true
+
+		if len(vaccine.Doses) == 0 && vaccine.Booster == nil {
+			return nil, fmt.Errorf("vaccine %s: needs doses or a booster", vaccine.Code)
+		}
+		for i, dose := range vaccine.Doses {
+			if !dose.DueAge.IsSet() || !dose.OverdueAge.IsSet() {
+				return nil, fmt.Errorf("vaccine %s dose %d: due_age and overdue_age are required", vaccine.Code, i+1)
+			}
+			if dose.OverdueAge.From(reference).Before(dose.DueAge.From(reference)) {
+				return nil, fmt.Errorf("vaccine %s dose %d: overdue_age is before due_age", vaccine.Code, i+1)
+			}
+		}
+		if vaccine.Booster != nil && !vaccine.Booster.Every.From(reference).After(reference) {
+			return nil, fmt.Errorf("vaccine %s: booster needs a non-zero every interval", vaccine.Code)
+		}
+	}
+	return &schedule, nil
+}
+
+// Vaccine returns the schedule entry for code, matched case-insensitively.
+func (s *ImmunizationSchedule) Vaccine(code string) (*VaccineSchedule, bool) {
+	for i := range s.Vaccines {
+		if strings.EqualFold(s.Vaccines[i].Code, code) {
+			return &s.Vaccines[i], true
+		}
+	}
+	return nil, false
+}
+
+// Forecast returns the next dose of every scheduled vaccine for a patient
+// born on dateOfBirth with the given doses on record, as of asOf. Vaccines
+// whose series was not finished before MaxAge are left out.
+func (s *ImmunizationSchedule) Forecast(dateOfBirth time.Time, immunizations []models.Immunization, asOf time.Time) []models.VaccineForecast {
+	given := make(map[string][]time.Time)
+	for _, immunization := range immunizations {
+		code := strings.ToLower(immunization.VaccineCode)
+		given[code] = append(given[code], immunization.AdministeredAt)
+	}
+
+	var forecasts []models.VaccineForecast
+	for _, vaccine := range s.Vaccines {
+		doses := given[strings.ToLower(vaccine.Code)]
+		sort.Slice(doses, func(i, j int) bool { return doses[i].Before(doses[j]) })
+
+		forecast, ok := vaccine.next(dateOfBirth, doses, asOf)
+		if ok {
+			forecasts = append(forecasts, forecast)
+		}
+	}
+	return forecasts
+}
+
+func (v VaccineSchedule) next(dateOfBirth time.Time, doses []time.Time, asOf time.Time) (models.VaccineForecast, bool) {
+	forecast := models.VaccineForecast{
+		VaccineCode: v.Code,
+		VaccineName: v.Name,
+		DosesGiven:  len(doses),
+		DoseNumber:  len(doses) + 1,
+	}
+	var last time.Time
+	if len(doses) > 0 {
+		last = doses[len(doses)-1]
+	}
+
+	var earliest, due, overdue time.Time
+	switch {
+	case len(doses) < len(v.Doses):
+		if v.MaxAge.IsSet() && asOf.After(v.MaxAge.From(dateOfBirth)) {
+			return forecast, false
+		}
+		dose := v.Doses[len(doses)]
+		earliest = dose.MinAge.From(dateOfBirth)
+		due = dose.DueAge.From(dateOfBirth)
+		overdue = dose.OverdueAge.From(dateOfBirth)
+		window := overdue.Sub(due)
+		if len(doses) > 0 {
+			earliest = laterOf(earliest, dose.MinInterval.From(last))
+			due = laterOf(due, dose.MinInterval.From(last))
+		}
+		overdue = laterOf(overdue, due.Add(window))
+
+	case v.Booster != nil:
+		due = v.Booster.StartAge.From(dateOfBirth)
+		if len(doses) > 0 {
+			due = laterOf(due, v.Booster.Every.From(last))
+		}
+		earliest = due
+		overdue = v.Booster.Grace.From(due)
+
+	default:
+		forecast.DoseNumber = 0
+		forecast.Status = models.VaccineComplete
+		return forecast, true
+	}
+
+	forecast.EarliestDate = &earliest
+	forecast.DueDate = &due
+	forecast.OverdueDate = &overdue
+	switch {
+	case asOf.Before(due):
+		forecast.Status = models.VaccineUpcoming
+	case asOf.Before(overdue):
+		forecast.Status = models.VaccineDue
+	default:
+		forecast.Status = models.VaccineOverdue
+	}
+	return forecast, true
+}
+
+func laterOf(a, b time.Time) time.Time {
+	if b.After(a) {
+		return b
+	}
+	return a
+}
@@ -0,0 +1,183 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"healthcare-portal/internal/models"
+	"healthcare-portal/internal/repository"
+)
+
+type ImmunizationService interface {
+	WithTenant(tenantID uint) ImmunizationService
+	RecordImmunization(immunization *models.Immunization) error
+	GetImmunizationByID(id uint) (*models.Immunization, error)
+	GetPatientImmunizations(patientID uint) ([]models.Immunization, error)
+	UpdateImmunization(immunization *models.Immunization) error
+	DeleteImmunization(id uint) error
+	GetPatientForecast(patientID uint, asOf time.Time) ([]models.VaccineForecast, error)
+}
+
+type immunizationService struct {
+	immunizationRepo repository.ImmunizationRepository
+	patientRepo      repository.PatientRepository
+	schedule         *ImmunizationSchedule
+}
+
+// NewImmunizationService builds the service. A nil schedule disables
+// forecasting but still allows doses to be recorded.
+func NewImmunizationService(immunizationRepo repository.ImmunizationRepository, patientRepo repository.PatientRepository, schedule *ImmunizationSchedule) ImmunizationService {
+	return &immunizationService{
+		immunizationRepo: immunizationRepo,
+		patientRepo:      patientRepo,
+		schedule:         schedule,
+	}
+}
+
+// WithTenant returns a service limited to tenantID's patients.
+func (s *immunizationService) WithTenant(tenantID uint) ImmunizationService {
+	return &immunizationService{
+		immunizationRepo: s.immunizationRepo.WithTenant(tenantID),
+		patientRepo:      s.patientRepo.WithTenant(tenantID),
+		schedule:         s.schedule,
+	}
+}
+
+// RecordImmunization stores a dose. Scheduled vaccines take their name from
+// the schedule, and the dose number defaults to the next in the series.
+func (s *immunizationService) RecordImmunization(immunization *models.Immunization) error {
+	patient, err := s.patientRepo.FindByID(immunization.PatientID)
+	if err != nil {
+		return errors.New("patient not found")
+	}
+	if err := s.normalizeImmunization(immunization, patient); err != nil {
+		return err
+	}
+
+	if immunization.DoseNumber == 0 {
+		given, err := s.immunizationRepo.FindByPatientID(patient.ID)
+		if err != nil {
+			return err
+		}
+		immunization.DoseNumber = 1
+		for _, other := range given {
+			if strings.EqualFold(other.VaccineCode, immunization.VaccineCode) && !other.AdministeredAt.After(immunization.AdministeredAt) {
+				immunization.DoseNumber++
+			}
+		}
+	}
+	return s.immunizationRepo.Create(immunization)
+}
+
+func (s *immunizationService) GetImmunizationByID(id uint) (*models.Immunization, error) {
+	return s.immunizationRepo.FindByID(id)
+}
+
+func (s *immunizationService) GetPatientImmunizations(patientID uint) ([]models.Immunization, error) {
+	if _, err := s.patientRepo.FindByID(patientID); err != nil {
+		return nil, errors.New("patient not found")
+	}
+	return s.immunizationRepo.FindByPatientID(patientID)
+}
+
+func (s *immunizationService) UpdateImmunization(immunization *models.Immunization) error {
+	patient, err := s.patientRepo.FindByID(immunization.PatientID)
+	if err != nil {
+		return errors.New("patient not found")
+	}
+	if immunization.DoseNumber <= 0 {
+		return errors.New("dose number must be positive")
+	}
+	if err := s.normalizeImmunization(immunization, patient); err != nil {
+		return err
+	}
+	return s.immunizationRepo.Update(immunization)
+}
+
+func (s *immunizationService) DeleteImmunization(id uint) error {
+	return s.immunizationRepo.Delete(id)
+}
+
+// GetPatientForecast computes the patient's due and overdue vaccines from
+// their date of birth and recorded doses.
+func (s *immunizationService) GetPatientForecast(patientID uint, asOf time.Time) ([]models.VaccineForecast, error) {
+	if s.schedule == nil {
+		return nil, errors.New("no immunization schedule is configured")
+	}
+	patient, err := s.patientRepo.FindByID(patientID)
+	if err != nil {
+		return nil, errors.New("patient not found")
+	}
+	if patient.DateOfBirth.IsZero() {
+		return nil, errors.New("patient has no date of birth on file")
+	}
+
+	immunizations, err := s.immunizationRepo.FindByPatientID(patientID)
+	if err != nil {
+		return nil, err
+	}
+	return s.schedule.Forecast(patient.DateOfBirth, immunizations, asOf), nil
+}
+
+func (s *immunizationService) normalizeImmunization(immunization *models.Immunization, patient *models.Patient) error {
+	immunization.VaccineCode = strings.TrimSpace(immunization.VaccineCode)
+	immunization.VaccineName = strings.TrimSpace(immunization.VaccineName)
+	immunization.LotNumber = strings.TrimSpace(immunization.LotNumber)
+	immunization.Manufacturer = strings.TrimSpace(immunization.Manufacturer)
+	immunization.Notes = strings.TrimSpace(immunization.Notes)
+	if immunization.VaccineCode == "" {
+		return errors.New("vaccine code is required")
+	}
+	if s.schedule != nil {
+		if vaccine, ok := s.schedule.Vaccine(immunization.VaccineCode); ok {
+			immunization.VaccineCode = vaccine.Code
+			if immunization.VaccineName == "" {
+				immunization.VaccineName = vaccine.Name
+			}
+		}
+	}
+	if immunization.VaccineName == "" {
+		return errors.New("vaccine name is required for vaccines outside the schedule")
+	}
+	if immunization.DoseNumber < 0 {
+		return errors.New("dose number must be positive")
+	}
+
+	if immunization.AdministeredAt.IsZero() {
+		immunization.AdministeredAt = time.Now()
+	}
+	if immunization.AdministeredAt.After(time.Now()) {
+		return errors.New("administered date cannot be in the future")
+	}
+	if !patient.DateOfBirth.IsZero() && immunization.AdministeredAt.Before(patient.DateOfBirth) {
+		return errors.New("administered date cannot be before the date of birth")
+	}
+
+	switch immunization.Site {
+	case "", models.SiteLeftDeltoid, models.SiteRightDeltoid, models.SiteLeftThigh, models.SiteRightThigh, models.SiteOral, models.SiteIntranasal:
+	default:
+		return errors.New("site must be left_deltoid, right_deltoid, left_thigh, right_thigh, oral or intranasal")
+	}
+
+	if immunization.Source == "" {
+		immunization.Source = models.ImmunizationAdministered
+	}
+	switch immunization.Source {
+	case models.ImmunizationAdministered:
+		// Doses given here need the details a recall would be traced by.
+		if immunization.LotNumber == "" {
+			return errors.New("lot number is required")
+		}
+		if immunization.AdministeredBy == nil {
+			return errors.New("administered by is required")
+		}
+		if immunization.ExpirationDate != nil && immunization.ExpirationDate.Before(immunization.AdministeredAt.Truncate(24*time.Hour)) {
+			return errors.New("vaccine lot was expired when administered")
+		}
+	case models.ImmunizationHistorical:
+	default:
+		return errors.New("source must be administered or historical")
+	}
+	return nil
+}
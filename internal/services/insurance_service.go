@@ -0,0 +1,383 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"healthcare-portal/internal/models"
+	"healthcare-portal/internal/repository"
+)
+
+var (
+	ErrPayerNotFound        = errors.New("insurance payer not found")
+	ErrPlanNotFound         = errors.New("insurance plan not found")
+	ErrCoverageNotFound     = errors.New("coverage not found")
+	ErrCoveragePriorityUsed = errors.New("the patient already has a coverage with this priority for these dates")
+	ErrNoCoverage           = errors.New("the patient has no coverage in force on the appointment date")
+	ErrAppointmentNotFound  = errors.New("appointment not found")
+)
+
+// maxCoveragePriority is the lowest order of benefits tracked: tertiary.
+const maxCoveragePriority = 3
+
+type InsuranceService interface {
+	WithTenant(tenantID uint) InsuranceService
+	CreatePayer(payer *models.InsurancePayer) error
+	GetPayers(activeOnly bool) ([]models.InsurancePayer, error)
+	GetPayerByID(id uint) (*models.InsurancePayer, error)
+	UpdatePayer(payer *models.InsurancePayer) error
+	CreatePlan(plan *models.InsurancePlan) error
+	GetPlanByID(id uint) (*models.InsurancePlan, error)
+	GetPayerPlans(payerID uint) ([]models.InsurancePlan, error)
+	UpdatePlan(plan *models.InsurancePlan) error
+	AddCoverage(coverage *models.Coverage) error
+	GetPatientCoverages(patientID uint, inForceOn *time.Time) ([]models.Coverage, error)
+	GetCoverageByID(patientID, id uint) (*models.Coverage, error)
+	UpdateCoverage(coverage *models.Coverage) error
+	DeleteCoverage(patientID, id uint) error
+	CheckAppointmentEligibility(appointmentID, checkedBy uint) ([]models.EligibilityCheck, error)
+	GetAppointmentEligibility(appointmentID uint) ([]models.EligibilityCheck, error)
+}
+
+type insuranceService struct {
+	insuranceRepo   repository.InsuranceRepository
+	patientRepo     repository.PatientRepository
+	appointmentRepo repository.AppointmentRepository
+	checker         EligibilityChecker
+}
+
+func NewInsuranceService(insuranceRepo repository.InsuranceRepository, patientRepo repository.PatientRepository, appointmentRepo repository.AppointmentRepository, checker EligibilityChecker) InsuranceService {
+	return &insuranceService{insuranceRepo: insuranceRepo, patientRepo: patientRepo, appointmentRepo: appointmentRepo, checker: checker}
+}
+
+// WithTenant returns a service that only sees tenantID's payers, plans and
+// coverages.
+func (s *insuranceService) WithTenant(tenantID uint) InsuranceService {
+	return &insuranceService{
+		insuranceRepo:   s.insuranceRepo.WithTenant(tenantID),
+		patientRepo:     s.patientRepo.WithTenant(tenantID),
+		appointmentRepo: s.appointmentRepo.WithTenant(tenantID),
+		checker:         s.checker,
+	}
+}
+
+func (s *insuranceService) CreatePayer(payer *models.InsurancePayer) error {
+	if err := normalizePayer(payer); err != nil {
+		return err
+	}
+	return s.insuranceRepo.CreatePayer(payer)
+}
+
+func (s *insuranceService) GetPayers(activeOnly bool) ([]models.InsurancePayer, error) {
+	return s.insuranceRepo.FindPayers(activeOnly)
+}
+
+func (s *insuranceService) GetPayerByID(id uint) (*models.InsurancePayer, error) {
+	payer, err := s.insuranceRepo.FindPayerByID(id)
+	if err != nil {
+		return nil, ErrPayerNotFound
+	}
+	return payer, nil
+}
+
+func (s *insuranceService) UpdatePayer(payer *models.InsurancePayer) error {
+	existing, err := s.GetPayerByID(payer.ID)
+	if err != nil {
+		return err
+	}
+	if err := normalizePayer(payer); err != nil {
+		return err
+	}
+	payer.TenantID = existing.TenantID
+	payer.CreatedAt = existing.CreatedAt
+	return s.insuranceRepo.UpdatePayer(payer)
+}
+
+func (s *insuranceService) CreatePlan(plan *models.InsurancePlan) error {
+	if _, err := s.GetPayerByID(plan.PayerID); err != nil {
+		return err
+	}
+	if err := normalizePlan(plan); err != nil {
+		return err
+	}
+	return s.insuranceRepo.CreatePlan(plan)
+}
+
+func (s *insuranceService) GetPlanByID(id uint) (*models.InsurancePlan, error) {
+	plan, err := s.insuranceRepo.FindPlanByID(id)
+	if err != nil {
+		return nil, ErrPlanNotFound
+	}
+	return plan, nil
+}
+
+func (s *insuranceService) GetPayerPlans(payerID uint) ([]models.InsurancePlan, error) {
+	if _, err := s.GetPayerByID(payerID); err != nil {
+		return nil, err
+	}
+	return s.insuranceRepo.FindPlansByPayerID(payerID)
+}
+
+// UpdatePlan saves changes to a plan. A plan stays with the payer it was
+// created under.
+func (s *insuranceService) UpdatePlan(plan *models.InsurancePlan) error {
+	existing, err := s.GetPlanByID(plan.ID)
+	if err != nil {
+		return err
+	}
+	if err := normalizePlan(plan); err != nil {
+		return err
+	}
+	plan.TenantID = existing.TenantID
+	plan.PayerID = existing.PayerID
+	plan.CreatedAt = existing.CreatedAt
+	return s.insuranceRepo.UpdatePlan(plan)
+}
+
+// AddCoverage enrols the patient in a plan. Coverages of the same priority
+// cannot overlap: end the old primary coverage before adding a new one.
+func (s *insuranceService) AddCoverage(coverage *models.Coverage) error {
+	if _, err := s.patientRepo.FindByID(coverage.PatientID); err != nil {
+		return ErrPatientNotFound
+	}
+	plan, err := s.GetPlanByID(coverage.PlanID)
+	if err != nil {
+		return err
+	}
+	if !plan.IsActive {
+		return errors.New("insurance plan is no longer offered")
+	}
+	if err := normalizeCoverage(coverage); err != nil {
+		return err
+	}
+	if err := s.ensurePriorityFree(coverage); err != nil {
+		return err
+	}
+	if err := s.insuranceRepo.CreateCoverage(coverage); err != nil {
+		return err
+	}
+	coverage.Plan = plan
+	return nil
+}
+
+// GetPatientCoverages returns the patient's coverages, primary first. With
+// inForceOn set, only the coverages in force that day are returned.
+func (s *insuranceService) GetPatientCoverages(patientID uint, inForceOn *time.Time) ([]models.Coverage, error) {
+	if _, err := s.patientRepo.FindByID(patientID); err != nil {
+		return nil, ErrPatientNotFound
+	}
+	coverages, err := s.insuranceRepo.FindCoveragesByPatientID(patientID)
+	if err != nil || inForceOn == nil {
+		return coverages, err
+	}
+	inForce := []models.Coverage{}
+	for _, coverage := range coverages {
+		if coverage.CoversOn(*inForceOn) {
+			inForce = append(inForce, coverage)
+		}
+	}
+	return inForce, nil
+}
+
+func (s *insuranceService) GetCoverageByID(patientID, id uint) (*models.Coverage, error) {
+	coverage, err := s.insuranceRepo.FindCoverageByID(id)
+	if err != nil || coverage.PatientID != patientID {
+		return nil, ErrCoverageNotFound
+	}
+	return coverage, nil
+}
+
+// UpdateCoverage saves changes to a coverage, such as a new member ID or
+// the date it ended. The plan can be changed to another active plan.
+func (s *insuranceService) UpdateCoverage(coverage *models.Coverage) error {
+	existing, err := s.GetCoverageByID(coverage.PatientID, coverage.ID)
+	if err != nil {
+		return err
+	}
+	plan := existing.Plan
+	if coverage.PlanID != existing.PlanID {
+		if plan, err = s.GetPlanByID(coverage.PlanID); err != nil {
+			return err
+		}
+		if !plan.IsActive {
+			return errors.New("insurance plan is no longer offered")
+		}
+	}
+	if err := normalizeCoverage(coverage); err != nil {
+		return err
+	}
+	if err := s.ensurePriorityFree(coverage); err != nil {
+		return err
+	}
+	coverage.TenantID = existing.TenantID
+	coverage.CreatedBy = existing.CreatedBy
+	coverage.CreatedAt = existing.CreatedAt
+	if err := s.insuranceRepo.UpdateCoverage(coverage); err != nil {
+		return err
+	}
+	coverage.Plan = plan
+	return nil
+}
+
+// DeleteCoverage removes a coverage entered in error. Coverage that ended
+// should be given an end date instead, so past eligibility checks and
+// claims still point at it.
+func (s *insuranceService) DeleteCoverage(patientID, id uint) error {
+	if _, err := s.GetCoverageByID(patientID, id); err != nil {
+		return err
+	}
+	return s.insuranceRepo.DeleteCoverage(id)
+}
+
+// ensurePriorityFree fails when another of the patient's coverages has the
+// same priority on any day this one is in force.
+func (s *insuranceService) ensurePriorityFree(coverage *models.Coverage) error {
+	coverages, err := s.insuranceRepo.FindCoveragesByPatientID(coverage.PatientID)
+	if err != nil {
+		return err
+	}
+	for _, other := range coverages {
+		if other.ID == coverage.ID || other.Priority != coverage.Priority {
+			continue
+		}
+		if coveragesOverlap(coverage, &other) {
+			return ErrCoveragePriorityUsed
+		}
+	}
+	return nil
+}
+
+// CheckAppointmentEligibility checks every coverage in force on the
+// appointment's date, primary first, and records the answers against the
+// appointment. A checker that fails is recorded as an error rather than
+// failing the request, so staff can see which payer did not answer.
+func (s *insuranceService) CheckAppointmentEligibility(appointmentID, checkedBy uint) ([]models.EligibilityCheck, error) {
+	appointment, err := s.appointmentRepo.FindByID(appointmentID)
+	if err != nil {
+		return nil, ErrAppointmentNotFound
+	}
+	patient, err := s.patientRepo.FindByID(appointment.PatientID)
+	if err != nil {
+		return nil, ErrPatientNotFound
+	}
+	coverages, err := s.GetPatientCoverages(patient.ID, &appointment.Date)
+	if err != nil {
+		return nil, err
+	}
+	if len(coverages) == 0 {
+		return nil, ErrNoCoverage
+	}
+
+	checks := make([]models.EligibilityCheck, 0, len(coverages))
+	for i := range coverages {
+		coverage := coverages[i]
+		check := models.EligibilityCheck{
+			PatientID:     patient.ID,
+			CoverageID:    coverage.ID,
+			AppointmentID: &appointment.ID,
+			ServiceDate:   appointment.Date,
+			Source:        s.checker.Name(),
+			CheckedBy:     checkedBy,
+			CheckedAt:     time.Now(),
+		}
+		result, err := s.checker.Check(EligibilityRequest{Patient: *patient, Coverage: coverage, ServiceDate: appointment.Date})
+		if err != nil {
+			check.Status = models.EligibilityError
+			check.Message = err.Error()
+		} else {
+			check.Status = result.Status
+			check.CopayCents = result.CopayCents
+			check.DeductibleRemainingCents = result.DeductibleRemainingCents
+			check.Message = result.Message
+		}
+		if err := s.insuranceRepo.CreateEligibilityCheck(&check); err != nil {
+			return nil, err
+		}
+		check.Coverage = &coverage
+		checks = append(checks, check)
+	}
+	return checks, nil
+}
+
+func (s *insuranceService) GetAppointmentEligibility(appointmentID uint) ([]models.EligibilityCheck, error) {
+	if _, err := s.appointmentRepo.FindByID(appointmentID); err != nil {
+		return nil, ErrAppointmentNotFound
+	}
+	return s.insuranceRepo.FindEligibilityChecksByAppointmentID(appointmentID)
+}
+
+func normalizePayer(payer *models.InsurancePayer) error {
+	payer.Name = strings.TrimSpace(payer.Name)
+	payer.PayerCode = strings.ToUpper(strings.TrimSpace(payer.PayerCode))
+	payer.Phone = strings.TrimSpace(payer.Phone)
+	payer.Address = strings.TrimSpace(payer.Address)
+	if payer.Name == "" {
+		return errors.New("payer name is required")
+	}
+	return nil
+}
+
+func normalizePlan(plan *models.InsurancePlan) error {
+	plan.Name = strings.TrimSpace(plan.Name)
+	plan.PlanType = models.PlanType(strings.ToLower(strings.TrimSpace(string(plan.PlanType))))
+	if plan.Name == "" {
+		return errors.New("plan name is required")
+	}
+	if !plan.PlanType.IsValid() {
+		return errors.New("invalid plan type")
+	}
+	if plan.CopayCents < 0 {
+		return errors.New("copay cannot be negative")
+	}
+	return nil
+}
+
+// normalizeCoverage trims the coverage's fields and checks them. Only a
+// subscriber other than the patient needs their own name and date of birth.
+func normalizeCoverage(coverage *models.Coverage) error {
+	coverage.MemberID = strings.TrimSpace(coverage.MemberID)
+	coverage.GroupNumber = strings.TrimSpace(coverage.GroupNumber)
+	coverage.SubscriberName = strings.TrimSpace(coverage.SubscriberName)
+	coverage.SubscriberRelationship = models.SubscriberRelationship(strings.ToLower(strings.TrimSpace(string(coverage.SubscriberRelationship))))
+	if coverage.SubscriberRelationship == "" {
+		coverage.SubscriberRelationship = models.SubscriberSelf
+	}
+	if coverage.Priority == 0 {
+		coverage.Priority = 1
+	}
+
+	if coverage.MemberID == "" {
+		return errors.New("member ID is required")
+	}
+	if !coverage.SubscriberRelationship.IsValid() {
+		return errors.New("invalid subscriber relationship")
+	}
+	if coverage.SubscriberRelationship == models.SubscriberSelf {
+		coverage.SubscriberName = ""
+		coverage.SubscriberDateOfBirth = nil
+	} else if coverage.SubscriberName == "" || coverage.SubscriberDateOfBirth == nil {
+		return errors.New("subscriber name and date of birth are required when the patient is not the subscriber")
+	}
+	if coverage.Priority < 1 || coverage.Priority > maxCoveragePriority {
+		return errors.New("priority must be 1 (primary), 2 (secondary) or 3 (tertiary)")
+	}
+	if coverage.EffectiveFrom.IsZero() {
+		return errors.New("effective from date is required")
+	}
+	if coverage.EffectiveTo != nil && coverage.EffectiveTo.Before(coverage.EffectiveFrom) {
+		return errors.New("coverage cannot end before it starts")
+	}
+	return nil
+}
+
+// coveragesOverlap reports whether a and b are in force on a common day.
+func coveragesOverlap(a, b *models.Coverage) bool {
+	if a.EffectiveTo != nil && a.EffectiveTo.Before(b.EffectiveFrom) {
+		return false
+	}
+	if b.EffectiveTo != nil && b.EffectiveTo.Before(a.EffectiveFrom) {
+		return false
+	}
+	return true
+}
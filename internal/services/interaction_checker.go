@@ -0,0 +1,213 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"healthcare-portal/internal/models"
+)
+
+// InteractionRules is the locally loaded rules file. Terms in the rules may be
+// a drug class key from DrugClasses or a single drug name.
+type InteractionRules struct {
+	Version            string                 `json:"version"`
+	DrugClasses        map[string][]string    `json:"drug_classes"`
+	CrossSensitivities []CrossSensitivityRule `json:"cross_sensitivities"`
+	Interactions       []InteractionRule      `json:"interactions"`
+}
+
+// CrossSensitivityRule warns when a patient allergic to Allergen is given
+// Drug.
+type CrossSensitivityRule struct {
+	Allergen string                     `json:"allergen"`
+	Drug     string                     `json:"drug"`
+	Severity models.InteractionSeverity `json:"severity"`
+	Message  string                     `json:"message"`
+}
+
+// InteractionRule warns when A and B are taken together, in either order.
+type InteractionRule struct {
+	A        string                     `json:"a"`
+	B        string                     `json:"b"`
+	Severity models.InteractionSeverity `json:"severity"`
+	Message  string                     `json:"message"`
+}
+
+// LoadInteractionRules reads and validates a rules file.
+func LoadInteractionRules(path string) (*InteractionRules, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var rules InteractionRules
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	for _, rule := range rules.CrossSensitivities {
+		if rule.Severity.Rank() == 0 {
+			return nil, fmt.Errorf("cross-sensitivity %s/%s: invalid severity %q", rule.Allergen, rule.Drug, rule.Severity)
+		}
+	}
+	for _, rule := range rules.Interactions {
+		if rule.Severity.Rank() == 0 {
+			return nil, fmt.Errorf("interaction %s/%s: invalid severity %q", rule.A, rule.B, rule.Severity)
+		}
+	}
+	return &rules, nil
+}
+
+// InteractionChecker checks a drug against a patient's allergies and active
+// medications.
+type InteractionChecker struct {
+	rules   *InteractionRules
+	classes map[string][]string
+}
+
+func NewInteractionChecker(rules *InteractionRules) *InteractionChecker {
+	classes := make(map[string][]string, len(rules.DrugClasses))
+	for class, members := range rules.DrugClasses {
+		for _, member := range members {
+			classes[normalizeTerm(class)] = append(classes[normalizeTerm(class)], normalizeTerm(member))
+		}
+	}
+	return &InteractionChecker{rules: rules, classes: classes}
+}
+
+// Check returns the warnings for prescribing drug, most severe first. Only
+// active allergies and medications are considered; unconfirmed allergies
+// still count.
+func (c *InteractionChecker) Check(drug string, allergies []models.Allergy, medications []models.MedicationStatement) []models.InteractionWarning {
+	var warnings []models.InteractionWarning
+
+	for _, allergy := range allergies {
+		if !allergy.IsActive() {
+			continue
+		}
+		if c.sameDrugOrClass(drug, allergy.Substance) {
+			warnings = append(warnings, models.InteractionWarning{
+				Type:     models.InteractionDrugAllergy,
+				Severity: allergySeverityToInteraction(allergy.Severity),
+				Drug:     drug,
+				Conflict: allergy.Substance,
+				Message:  allergyMessage(allergy),
+			})
+			continue
+		}
+		for _, rule := range c.rules.CrossSensitivities {
+			if c.matches(allergy.Substance, rule.Allergen) && c.matches(drug, rule.Drug) {
+				warnings = append(warnings, models.InteractionWarning{
+					Type:     models.InteractionCrossSensitivity,
+					Severity: rule.Severity,
+					Drug:     drug,
+					Conflict: allergy.Substance,
+					Message:  rule.Message,
+				})
+				break
+			}
+		}
+	}
+
+	for _, medication := range medications {
+		if !medication.IsActive() {
+			continue
+		}
+		if normalizeTerm(medication.Drug) == normalizeTerm(drug) {
+			warnings = append(warnings, models.InteractionWarning{
+				Type:     models.InteractionDuplicateTherapy,
+				Severity: models.InteractionModerate,
+				Drug:     drug,
+				Conflict: medication.Drug,
+				Message:  "Patient is already taking " + medication.Drug + ".",
+			})
+			continue
+		}
+		for _, rule := range c.rules.Interactions {
+			if (c.matches(drug, rule.A) && c.matches(medication.Drug, rule.B)) ||
+				(c.matches(drug, rule.B) && c.matches(medication.Drug, rule.A)) {
+				warnings = append(warnings, models.InteractionWarning{
+					Type:     models.InteractionDrugDrug,
+					Severity: rule.Severity,
+					Drug:     drug,
+					Conflict: medication.Drug,
+					Message:  rule.Message,
+				})
+			}
+		}
+	}
+
+	sort.SliceStable(warnings, func(i, j int) bool {
+		return warnings[i].Severity.Rank() > warnings[j].Severity.Rank()
+	})
+	return warnings
+}
+
+// matches reports whether name is the drug term or a member of the class it
+// names.
+func (c *InteractionChecker) matches(name, term string) bool {
+	term = normalizeTerm(term)
+	if members, ok := c.classes[term]; ok {
+		for _, member := range members {
+			if containsTerm(name, member) {
+				return true
+			}
+		}
+		return false
+	}
+	return containsTerm(name, term)
+}
+
+// sameDrugOrClass reports whether the drug is the allergen itself or shares
+// a class with it.
+func (c *InteractionChecker) sameDrugOrClass(drug, allergen string) bool {
+	if containsTerm(drug, normalizeTerm(allergen)) || containsTerm(allergen, normalizeTerm(drug)) {
+		return true
+	}
+	for class := range c.classes {
+		if c.matches(drug, class) && c.matches(allergen, class) {
+			return true
+		}
+	}
+	return false
+}
+
+// containsTerm matches whole words, so "amoxicillin 500 mg" contains
+// "amoxicillin" but "penicillamine" does not contain "penicillin".
+func containsTerm(name, term string) bool {
+	if term == "" {
+		return false
+	}
+	return strings.Contains(" "+normalizeTerm(name)+" ", " "+term+" ")
+}
+
+func normalizeTerm(value string) string {
+	value = strings.ToLower(value)
+	value = strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '-' {
+			return r
+		}
+		return ' '
+	}, value)
+	return strings.Join(strings.Fields(value), " ")
+}
+
+func allergySeverityToInteraction(severity models.AllergySeverity) models.InteractionSeverity {
+	if severity == models.SeveritySevere {
+		return models.InteractionContraindicated
+	}
+	return models.InteractionMajor
+}
+
+func allergyMessage(allergy models.Allergy) string {
+	message := "Patient has a recorded allergy to " + allergy.Substance
+	if allergy.Reaction != "" {
+		message += " (" + allergy.Reaction + ")"
+	}
+	if allergy.Verification == models.AllergyUnconfirmed {
+		message += "; the allergy is not yet confirmed"
+	}
+	return message + "."
+}
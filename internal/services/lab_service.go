@@ -0,0 +1,384 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"healthcare-portal/internal/models"
+	"healthcare-portal/internal/repository"
+)
+
+type LabService interface {
+	WithTenant(tenantID uint) LabService
+	CreateOrder(order *models.LabOrder) error
+	GetOrderByID(id uint) (*models.LabOrder, error)
+	GetPatientOrders(patientID uint) ([]models.LabOrder, error)
+	CancelOrder(id, doctorID uint) (*models.LabOrder, error)
+	EnterResults(orderID, enteredBy uint, results []models.LabResult, final bool) (*models.LabOrder, error)
+	GetReviewInbox(doctorID uint) ([]models.LabOrder, error)
+	AcknowledgeOrder(id, doctorID uint) (*models.LabOrder, error)
+}
+
+type labService struct {
+	labRepo       repository.LabRepository
+	patientRepo   repository.PatientRepository
+	encounterRepo repository.EncounterRepository
+}
+
+func NewLabService(labRepo repository.LabRepository, patientRepo repository.PatientRepository, encounterRepo repository.EncounterRepository) LabService {
+	return &labService{
+		labRepo:       labRepo,
+		patientRepo:   patientRepo,
+		encounterRepo: encounterRepo,
+	}
+}
+
+// WithTenant returns a service limited to tenantID's patients.
+func (s *labService) WithTenant(tenantID uint) LabService {
+	return &labService{
+		labRepo:       s.labRepo.WithTenant(tenantID),
+		patientRepo:   s.patientRepo.WithTenant(tenantID),
+		encounterRepo: s.encounterRepo.WithTenant(tenantID),
+	}
+}
+
+// CreateOrder places a lab order. An encounter, if given, must be the
+// ordering doctor's visit with the patient.
+func (s *labService) CreateOrder(order *models.LabOrder) error {
+	if _, err := s.patientRepo.FindByID(order.PatientID); err != nil {
+		return errors.New("patient not found")
+	}
+	if order.EncounterID != nil {
+		encounter, err := s.encounterRepo.FindByID(*order.EncounterID)
+		if err != nil {
+			return errors.New("encounter not found")
+		}
+		if encounter.PatientID != order.PatientID || encounter.DoctorID != order.DoctorID {
+			return errors.New("encounter does not belong to this patient and doctor")
+		}
+	}
+
+	order.TestName = strings.TrimSpace(order.TestName)
+	if order.TestName == "" {
+		return errors.New("test name is required")
+	}
+	order.TestCode = strings.ToUpper(strings.TrimSpace(order.TestCode))
+	order.ClinicalNotes = strings.TrimSpace(order.ClinicalNotes)
+	if order.Priority == "" {
+		order.Priority = models.LabRoutine
+	}
+	switch order.Priority {
+	case models.LabRoutine, models.LabUrgent, models.LabStat:
+	default:
+		return errors.New("priority must be routine, urgent or stat")
+	}
+
+	order.Status = models.LabOrdered
+	order.OrderedAt = time.Now()
+	order.ResultedAt = nil
+	order.ReviewedBy = nil
+	order.ReviewedAt = nil
+	return s.labRepo.CreateOrder(order)
+}
+
+func (s *labService) GetOrderByID(id uint) (*models.LabOrder, error) {
+	return s.labRepo.FindOrderByID(id)
+}
+
+func (s *labService) GetPatientOrders(patientID uint) ([]models.LabOrder, error) {
+	if _, err := s.patientRepo.FindByID(patientID); err != nil {
+		return nil, errors.New("patient not found")
+	}
+	return s.labRepo.FindOrdersByPatientID(patientID)
+}
+
+// CancelOrder withdraws an order that has no results yet.
+func (s *labService) CancelOrder(id, doctorID uint) (*models.LabOrder, error) {
+	order, err := s.labRepo.FindOrderByID(id)
+	if err != nil {
+		return nil, errors.New("lab order not found")
+	}
+	if order.DoctorID != doctorID {
+		return nil, errors.New("only the ordering doctor can cancel this order")
+	}
+	if order.Status != models.LabOrdered {
+		return nil, errors.New("only orders without results can be cancelled")
+	}
+
+	order.Status = models.LabCancelled
+	if err := s.labRepo.UpdateOrder(order); err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+// EnterResults records a batch of results and flags each against its
+// reference range. final marks the order resulted; otherwise it stays
+// partial and more results may follow. Either way the order goes back to
+// the ordering doctor's inbox.
+func (s *labService) EnterResults(orderID, enteredBy uint, results []models.LabResult, final bool) (*models.LabOrder, error) {
+	order, err := s.labRepo.FindOrderByID(orderID)
+	if err != nil {
+		return nil, errors.New("lab order not found")
+	}
+	switch order.Status {
+	case models.LabCancelled:
+		return nil, errors.New("cannot enter results for a cancelled order")
+	case models.LabResulted:
+		return nil, errors.New("order already has final results")
+	}
+	if len(results) == 0 {
+		return nil, errors.New("at least one result is required")
+	}
+
+	now := time.Now()
+	for i := range results {
+		if err := flagResult(&results[i]); err != nil {
+			return nil, fmt.Errorf("result %d: %w", i+1, err)
+		}
+		results[i].EnteredBy = enteredBy
+		results[i].ResultedAt = now
+	}
+
+	order.Status = models.LabPartial
+	if final {
+		order.Status = models.LabResulted
+	}
+	order.ResultedAt = &now
+	order.ReviewedBy = nil
+	order.ReviewedAt = nil
+	if err := s.labRepo.AddResults(order, results); err != nil {
+		return nil, err
+	}
+	return s.labRepo.FindOrderByID(order.ID)
+}
+
+// GetReviewInbox lists the doctor's orders with unacknowledged results:
+// critical first, then abnormal, then the rest, oldest first within each.
+func (s *labService) GetReviewInbox(doctorID uint) ([]models.LabOrder, error) {
+	orders, err := s.labRepo.FindUnreviewedByDoctorID(doctorID)
+	if err != nil {
+		return nil, err
+	}
+
+	urgency := func(order models.LabOrder) int {
+		rank := 2
+		for _, result := range order.Results {
+			if result.Flag.IsCritical() {
+				return 0
+			}
+			if result.Flag.IsAbnormal() {
+				rank = 1
+			}
+		}
+		return rank
+	}
+	sort.SliceStable(orders, func(i, j int) bool {
+		return urgency(orders[i]) < urgency(orders[j])
+	})
+	return orders, nil
+}
+
+// AcknowledgeOrder records that the ordering doctor has reviewed the
+// results received so far.
+func (s *labService) AcknowledgeOrder(id, doctorID uint) (*models.LabOrder, error) {
+	order, err := s.labRepo.FindOrderByID(id)
+	if err != nil {
+		return nil, errors.New("lab order not found")
+	}
+	if order.DoctorID != doctorID {
+		return nil, errors.New("only the ordering doctor can acknowledge these results")
+	}
+	if order.Status != models.LabPartial && order.Status != models.LabResulted {
+		return nil, errors.New("order has no results to acknowledge")
+	}
+	if order.ReviewedAt != nil {
+		return nil, errors.New("results are already acknowledged")
+	}
+
+	now := time.Now()
+	order.ReviewedBy = &doctorID
+	order.ReviewedAt = &now
+	if err := s.labRepo.UpdateOrder(order); err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+// flagResult validates a result, parses its value and reference range and
+// sets Flag. A value that cannot be read against a numeric range, or that
+// is reported beyond the assay's limits and may lie either side of a bound,
+// is flagged abnormal so a doctor reviews it.
+func flagResult(result *models.LabResult) error {
+	result.Analyte = strings.TrimSpace(result.Analyte)
+	result.Value = strings.TrimSpace(result.Value)
+	result.Unit = strings.TrimSpace(result.Unit)
+	result.ReferenceRange = strings.TrimSpace(result.ReferenceRange)
+	if result.Analyte == "" {
+		return errors.New("analyte is required")
+	}
+	if result.Value == "" {
+		return errors.New("value is required")
+	}
+
+	result.NumericValue = nil
+	value, parsed := parseLabValue(result.Value)
+	if parsed {
+		result.NumericValue = &value.number
+	}
+
+	low, high, numeric := ParseReferenceRange(result.ReferenceRange)
+	result.ReferenceLow, result.ReferenceHigh = low, high
+	if result.CriticalLow != nil && result.CriticalHigh != nil && *result.CriticalLow >= *result.CriticalHigh {
+		return errors.New("critical low must be below critical high")
+	}
+
+	result.Flag = models.LabFlagNormal
+	if !parsed {
+		// Qualitative result: anything other than the expected text is
+		// abnormal, and so is text where a number was expected.
+		if numeric || result.ReferenceRange != "" && !strings.EqualFold(result.Value, result.ReferenceRange) {
+			result.Flag = models.LabFlagAbnormal
+		}
+		return nil
+	}
+
+	switch {
+	case result.CriticalLow != nil && value.atMost(*result.CriticalLow):
+		result.Flag = models.LabFlagCriticalLow
+	case result.CriticalHigh != nil && value.atLeast(*result.CriticalHigh):
+		result.Flag = models.LabFlagCriticalHigh
+	case low != nil && value.below(*low):
+		result.Flag = models.LabFlagLow
+	case high != nil && value.above(*high):
+		result.Flag = models.LabFlagHigh
+	case (low != nil && !value.atLeast(*low)) || (high != nil && !value.atMost(*high)):
+		result.Flag = models.LabFlagAbnormal
+	}
+	return nil
+}
+
+// thousandsPattern matches numbers written with thousands separators, e.g.
+// 1,200 or 12,500.5.
+var thousandsPattern = regexp.MustCompile(`^-?\d{1,3}(,\d{3})+(\.\d+)?$`)
+
+// labValue is a numeric result. Values reported beyond an assay's limits,
+// such as ">1000" or "<0.01", carry the comparison: the true value is only
+// known to lie on that side of number.
+type labValue struct {
+	number     float64
+	comparison string
+}
+
+// parseLabValue reads a numeric result, allowing a leading <, <=, > or >=
+// and thousands separators.
+func parseLabValue(text string) (labValue, bool) {
+	var value labValue
+	text = strings.TrimSpace(text)
+	for _, prefix := range []struct{ text, comparison string }{
+		{"<=", "<="}, {"≤", "<="}, {"<", "<"}, {">=", ">="}, {"≥", ">="}, {">", ">"},
+	} {
+		if strings.HasPrefix(text, prefix.text) {
+			value.comparison = prefix.comparison
+			text = strings.TrimSpace(strings.TrimPrefix(text, prefix.text))
+			break
+		}
+	}
+	if thousandsPattern.MatchString(text) {
+		text = strings.ReplaceAll(text, ",", "")
+	}
+	number, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		return value, false
+	}
+	value.number = number
+	return value, true
+}
+
+// above reports whether the value is certainly greater than limit.
+func (v labValue) above(limit float64) bool {
+	switch v.comparison {
+	case "":
+		return v.number > limit
+	case ">":
+		return v.number >= limit
+	case ">=":
+		return v.number > limit
+	}
+	return false
+}
+
+// atLeast reports whether the value is certainly limit or more.
+func (v labValue) atLeast(limit float64) bool {
+	switch v.comparison {
+	case "", ">", ">=":
+		return v.number >= limit
+	}
+	return false
+}
+
+// below reports whether the value is certainly less than limit.
+func (v labValue) below(limit float64) bool {
+	switch v.comparison {
+	case "":
+		return v.number < limit
+	case "<":
+		return v.number <= limit
+	case "<=":
+		return v.number < limit
+	}
+	return false
+}
+
+// atMost reports whether the value is certainly limit or less.
+func (v labValue) atMost(limit float64) bool {
+	switch v.comparison {
+	case "", "<", "<=":
+		return v.number <= limit
+	}
+	return false
+}
+
+// ParseReferenceRange reads the numeric bounds of a reference range in the
+// forms "3.5-5.0", "3.5 - 5.0", "<200", "<=200", ">60" or ">=60". numeric is
+// false for qualitative ranges such as "Negative", which have no bounds.
+func ParseReferenceRange(text string) (low, high *float64, numeric bool) {
+	text = strings.TrimSpace(text)
+	parse := func(s string) *float64 {
+		value, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+		if err != nil {
+			return nil
+		}
+		return &value
+	}
+
+	for _, prefix := range []string{"<=", "≤", "<"} {
+		if strings.HasPrefix(text, prefix) {
+			high = parse(strings.TrimPrefix(text, prefix))
+			return nil, high, high != nil
+		}
+	}
+	for _, prefix := range []string{">=", "≥", ">"} {
+		if strings.HasPrefix(text, prefix) {
+			low = parse(strings.TrimPrefix(text, prefix))
+			return low, nil, low != nil
+		}
+	}
+
+	// Split on the dash between the bounds, not a leading minus sign.
+	if len(text) < 2 {
+		return nil, nil, false
+	}
+	if i := strings.Index(text[1:], "-"); i >= 0 {
+		low, high = parse(text[:i+1]), parse(text[i+2:])
+		if low != nil && high != nil && *low <= *high {
+			return low, high, true
+		}
+	}
+	return nil, nil, false
+}
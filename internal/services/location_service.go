@@ -0,0 +1,133 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"healthcare-portal/internal/models"
+	"healthcare-portal/internal/repository"
+)
+
+type LocationService interface {
+	WithTenant(tenantID uint) LocationService
+	CreateLocation(location *models.Location) error
+	GetLocationByID(id uint) (*models.Location, error)
+	GetAllLocations() ([]models.Location, error)
+	UpdateLocation(location *models.Location) error
+	DeleteLocation(id uint) error
+	CreateSchedule(schedule *models.DoctorSchedule) error
+	GetSchedules(doctorID, locationID *uint) ([]models.DoctorSchedule, error)
+	DeleteSchedule(id uint) error
+}
+
+type locationService struct {
+	locationRepo repository.LocationRepository
+	scheduleRepo repository.DoctorScheduleRepository
+	userRepo     repository.UserRepository
+}
+
+func NewLocationService(locationRepo repository.LocationRepository, scheduleRepo repository.DoctorScheduleRepository, userRepo repository.UserRepository) LocationService {
+	return &locationService{
+		locationRepo: locationRepo,
+		scheduleRepo: scheduleRepo,
+		userRepo:     userRepo,
+	}
+}
+
+// WithTenant returns a service limited to tenantID's locations and doctors.
+func (s *locationService) WithTenant(tenantID uint) LocationService {
+	return &locationService{
+		locationRepo: s.locationRepo.WithTenant(tenantID),
+		scheduleRepo: s.scheduleRepo.WithTenant(tenantID),
+		userRepo:     s.userRepo.WithTenant(tenantID),
+	}
+}
+
+func (s *locationService) CreateLocation(location *models.Location) error {
+	if err := validateLocation(location); err != nil {
+		return err
+	}
+	return s.locationRepo.Create(location)
+}
+
+func (s *locationService) GetLocationByID(id uint) (*models.Location, error) {
+	return s.locationRepo.FindByID(id)
+}
+
+func (s *locationService) GetAllLocations() ([]models.Location, error) {
+	return s.locationRepo.FindAll()
+}
+
+func (s *locationService) UpdateLocation(location *models.Location) error {
+	if err := validateLocation(location); err != nil {
+		return err
+	}
+	return s.locationRepo.Update(location)
+}
+
+func (s *locationService) DeleteLocation(id uint) error {
+	return s.locationRepo.Delete(id)
+}
+
+// CreateSchedule assigns a doctor to a location for a weekly block of hours.
+// Blocks for the same doctor and day may not overlap, even across locations.
+func (s *locationService) CreateSchedule(schedule *models.DoctorSchedule) error {
+	if schedule.DayOfWeek < time.Sunday || schedule.DayOfWeek > time.Saturday {
+		return errors.New("day_of_week must be between 0 (Sunday) and 6 (Saturday)")
+	}
+	start, end, ok := schedule.Window()
+	if !ok {
+		return errors.New("invalid time format, expected HH:MM")
+	}
+	if end <= start {
+		return errors.New("schedule end must be after its start")
+	}
+
+	doctor, err := s.userRepo.FindByID(schedule.DoctorID)
+	if err != nil || doctor.Role != models.RoleDoctor {
+		return errors.New("doctor not found")
+	}
+	location, err := s.locationRepo.FindByID(schedule.LocationID)
+	if err != nil {
+		return errors.New("location not found")
+	}
+	if !location.IsActive {
+		return errors.New("location is not active")
+	}
+
+	existing, err := s.scheduleRepo.FindByDoctorAndDay(schedule.DoctorID, schedule.DayOfWeek)
+	if err != nil {
+		return err
+	}
+	for _, other := range existing {
+		otherStart, otherEnd, ok := other.Window()
+		if ok && otherStart < end && start < otherEnd {
+			return errors.New("schedule overlaps an existing block for this doctor")
+		}
+	}
+
+	return s.scheduleRepo.Create(schedule)
+}
+
+func (s *locationService) GetSchedules(doctorID, locationID *uint) ([]models.DoctorSchedule, error) {
+	return s.scheduleRepo.FindAll(doctorID, locationID)
+}
+
+func (s *locationService) DeleteSchedule(id uint) error {
+	return s.scheduleRepo.Delete(id)
+}
+
+func validateLocation(location *models.Location) error {
+	location.Code = strings.ToLower(strings.TrimSpace(location.Code))
+	if location.Code == "" {
+		return errors.New("location code is required")
+	}
+	if location.TimeZone == "" {
+		location.TimeZone = "UTC"
+	}
+	if _, err := time.LoadLocation(location.TimeZone); err != nil {
+		return errors.New("unknown time zone")
+	}
+	return nil
+}
@@ -0,0 +1,360 @@
+package services
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"text/template"
+	"time"
+
+	"healthcare-portal/internal/models"
+	"healthcare-portal/internal/repository"
+)
+
+type MedicationService interface {
+	WithTenant(tenantID uint) MedicationService
+	CreateStatement(statement *models.MedicationStatement) error
+	GetStatementByID(id uint) (*models.MedicationStatement, error)
+	GetPatientMedications(patientID uint, activeOnly bool) ([]models.MedicationStatement, error)
+	UpdateStatement(statement *models.MedicationStatement) error
+	StopStatement(id uint, stoppedOn time.Time) (*models.MedicationStatement, error)
+	DeleteStatement(id uint) error
+	CheckPrescription(prescription *models.Prescription) ([]models.InteractionWarning, error)
+	CreatePrescription(prescription *models.Prescription) error
+	GetPrescriptionByID(id uint) (*models.Prescription, error)
+	GetPatientPrescriptions(patientID uint) ([]models.Prescription, error)
+	RefillPrescription(id uint) (*models.Prescription, error)
+	CancelPrescription(id, doctorID uint) (*models.Prescription, error)
+	PrintPrescription(id uint) (string, error)
+}
+
+// InteractionWarningsError is returned when a prescription raises warnings
+// and no override reason was given.
+type InteractionWarningsError struct {
+	Warnings []models.InteractionWarning
+}
+
+func (e *InteractionWarningsError) Error() string {
+	return "prescription raised interaction warnings; acknowledge them with an override reason"
+}
+
+type medicationService struct {
+	medicationRepo repository.MedicationRepository
+	patientRepo    repository.PatientRepository
+	encounterRepo  repository.EncounterRepository
+	allergyRepo    repository.AllergyRepository
+	tenantRepo     repository.TenantRepository
+	checker        *InteractionChecker
+}
+
+func NewMedicationService(medicationRepo repository.MedicationRepository, patientRepo repository.PatientRepository, encounterRepo repository.EncounterRepository, allergyRepo repository.AllergyRepository, tenantRepo repository.TenantRepository, checker *InteractionChecker) MedicationService {
+	return &medicationService{
+		medicationRepo: medicationRepo,
+		patientRepo:    patientRepo,
+		encounterRepo:  encounterRepo,
+		allergyRepo:    allergyRepo,
+		tenantRepo:     tenantRepo,
+		checker:        checker,
+	}
+}
+
+// WithTenant returns a service limited to tenantID's patients.
+func (s *medicationService) WithTenant(tenantID uint) MedicationService {
+	return &medicationService{
+		medicationRepo: s.medicationRepo.WithTenant(tenantID),
+		patientRepo:    s.patientRepo.WithTenant(tenantID),
+		encounterRepo:  s.encounterRepo.WithTenant(tenantID),
+		allergyRepo:    s.allergyRepo.WithTenant(tenantID),
+		tenantRepo:     s.tenantRepo,
+		checker:        s.checker,
+	}
+}
+
+func (s *medicationService) CreateStatement(statement *models.MedicationStatement) error {
+	if _, err := s.patientRepo.FindByID(statement.PatientID); err != nil {
+		return errors.New("patient not found")
+	}
+	if err := normalizeStatement(statement); err != nil {
+		return err
+	}
+	return s.medicationRepo.CreateStatement(statement)
+}
+
+func (s *medicationService) GetStatementByID(id uint) (*models.MedicationStatement, error) {
+	return s.medicationRepo.FindStatementByID(id)
+}
+
+func (s *medicationService) GetPatientMedications(patientID uint, activeOnly bool) ([]models.MedicationStatement, error) {
+	if _, err := s.patientRepo.FindByID(patientID); err != nil {
+		return nil, errors.New("patient not found")
+	}
+	return s.medicationRepo.FindStatementsByPatientID(patientID, activeOnly)
+}
+
+func (s *medicationService) UpdateStatement(statement *models.MedicationStatement) error {
+	if err := normalizeStatement(statement); err != nil {
+		return err
+	}
+	return s.medicationRepo.UpdateStatement(statement)
+}
+
+// StopStatement takes the medication off the active list as of stoppedOn.
+func (s *medicationService) StopStatement(id uint, stoppedOn time.Time) (*models.MedicationStatement, error) {
+	statement, err := s.medicationRepo.FindStatementByID(id)
+	if err != nil {
+		return nil, errors.New("medication not found")
+	}
+	if !statement.IsActive() {
+		return nil, errors.New("medication is not active")
+	}
+	if statement.StartDate != nil && stoppedOn.Before(*statement.StartDate) {
+		return nil, errors.New("stop date cannot be before start date")
+	}
+
+	statement.Status = models.MedicationStopped
+	statement.StopDate = &stoppedOn
+	if err := s.medicationRepo.UpdateStatement(statement); err != nil {
+		return nil, err
+	}
+	return statement, nil
+}
+
+func (s *medicationService) DeleteStatement(id uint) error {
+	return s.medicationRepo.DeleteStatement(id)
+}
+
+// CheckPrescription runs the interaction checks for a prescription without
+// issuing it.
+func (s *medicationService) CheckPrescription(prescription *models.Prescription) ([]models.InteractionWarning, error) {
+	if _, err := s.patientRepo.FindByID(prescription.PatientID); err != nil {
+		return nil, errors.New("patient not found")
+	}
+	return s.interactionWarnings(prescription)
+}
+
+// CreatePrescription issues a prescription and adds the drug to the
+// patient's active medication list. When the prescription is written during
+// an encounter, the encounter must be the prescribing doctor's visit with the
+// same patient. If the interaction checks raise warnings the prescription is
+// only issued with an override reason, and the warnings are stored with it.
+func (s *medicationService) CreatePrescription(prescription *models.Prescription) error {
+	if _, err := s.patientRepo.FindByID(prescription.PatientID); err != nil {
+		return errors.New("patient not found")
+	}
+	if prescription.EncounterID != nil {
+		encounter, err := s.encounterRepo.FindByID(*prescription.EncounterID)
+		if err != nil {
+			return errors.New("encounter not found")
+		}
+		if encounter.PatientID != prescription.PatientID || encounter.DoctorID != prescription.DoctorID {
+			return errors.New("encounter does not belong to this patient and doctor")
+		}
+	}
+	if err := normalizePrescription(prescription); err != nil {
+		return err
+	}
+
+	warnings, err := s.interactionWarnings(prescription)
+	if err != nil {
+		return err
+	}
+	prescription.OverrideReason = strings.TrimSpace(prescription.OverrideReason)
+	if len(warnings) > 0 && prescription.OverrideReason == "" {
+		return &InteractionWarningsError{Warnings: warnings}
+	}
+	prescription.Warnings = nil
+	for _, warning := range warnings {
+		prescription.Warnings = append(prescription.Warnings, models.PrescriptionWarning{
+			Type:     warning.Type,
+			Severity: warning.Severity,
+			Conflict: warning.Conflict,
+			Message:  warning.Message,
+		})
+	}
+
+	prescription.Status = models.PrescriptionActive
+	prescription.RefillsUsed = 0
+	prescription.IssuedAt = time.Now()
+
+	start := prescription.IssuedAt
+	statement := &models.MedicationStatement{
+		PatientID:  prescription.PatientID,
+		Drug:       prescription.Drug,
+		Dose:       prescription.Dose,
+		Route:      prescription.Route,
+		Frequency:  prescription.Frequency,
+		StartDate:  &start,
+		Status:     models.MedicationActive,
+		RecordedBy: prescription.DoctorID,
+	}
+	return s.medicationRepo.CreatePrescription(prescription, statement)
+}
+
+func (s *medicationService) interactionWarnings(prescription *models.Prescription) ([]models.InteractionWarning, error) {
+	if s.checker == nil {
+		return nil, nil
+	}
+	allergies, err := s.allergyRepo.FindByPatientID(prescription.PatientID)
+	if err != nil {
+		return nil, err
+	}
+	medications, err := s.medicationRepo.FindStatementsByPatientID(prescription.PatientID, true)
+	if err != nil {
+		return nil, err
+	}
+	return s.checker.Check(prescription.Drug, allergies, medications), nil
+}
+
+func (s *medicationService) GetPrescriptionByID(id uint) (*models.Prescription, error) {
+	return s.medicationRepo.FindPrescriptionByID(id)
+}
+
+func (s *medicationService) GetPatientPrescriptions(patientID uint) ([]models.Prescription, error) {
+	if _, err := s.patientRepo.FindByID(patientID); err != nil {
+		return nil, errors.New("patient not found")
+	}
+	return s.medicationRepo.FindPrescriptionsByPatientID(patientID)
+}
+
+// RefillPrescription records that one refill was dispensed. The prescription
+// is completed when the last refill is used.
+func (s *medicationService) RefillPrescription(id uint) (*models.Prescription, error) {
+	prescription, err := s.medicationRepo.FindPrescriptionByID(id)
+	if err != nil {
+		return nil, errors.New("prescription not found")
+	}
+	if prescription.Status != models.PrescriptionActive {
+		return nil, errors.New("prescription is not active")
+	}
+	if prescription.RefillsRemaining() == 0 {
+		return nil, errors.New("no refills remaining")
+	}
+
+	prescription.RefillsUsed++
+	if prescription.RefillsRemaining() == 0 {
+		prescription.Status = models.PrescriptionCompleted
+	}
+	if err := s.medicationRepo.UpdatePrescription(prescription); err != nil {
+		return nil, err
+	}
+	return prescription, nil
+}
+
+// CancelPrescription voids an active prescription and stops the medication
+// list entry it created. Only the prescribing doctor can cancel.
+func (s *medicationService) CancelPrescription(id, doctorID uint) (*models.Prescription, error) {
+	prescription, err := s.medicationRepo.FindPrescriptionByID(id)
+	if err != nil {
+		return nil, errors.New("prescription not found")
+	}
+	if prescription.DoctorID != doctorID {
+		return nil, errors.New("only the prescribing doctor can cancel this prescription")
+	}
+	if prescription.Status != models.PrescriptionActive {
+		return nil, errors.New("prescription is not active")
+	}
+
+	prescription.Status = models.PrescriptionCancelled
+	if err := s.medicationRepo.UpdatePrescription(prescription); err != nil {
+		return nil, err
+	}
+	if err := s.medicationRepo.StopStatementsForPrescription(prescription.ID); err != nil {
+		return nil, err
+	}
+	return prescription, nil
+}
+
+// PrintPrescription renders the prescription as plain text for printing.
+func (s *medicationService) PrintPrescription(id uint) (string, error) {
+	prescription, err := s.medicationRepo.FindPrescriptionByID(id)
+	if err != nil {
+		return "", errors.New("prescription not found")
+	}
+
+	clinic := "Healthcare Portal"
+	if tenant, err := s.tenantRepo.FindByID(prescription.TenantID); err == nil {
+		clinic = tenant.Name
+	}
+	return RenderPrescription(clinic, prescription)
+}
+
+var prescriptionTemplate = template.Must(template.New("prescription").Parse(`{{.Clinic}}
+PRESCRIPTION #{{.Rx.ID}}
+Issued: {{.Rx.IssuedAt.Format "2006-01-02"}}{{if ne .Rx.Status "active"}}  [{{.Rx.Status}}]{{end}}
+
+Patient: {{with .Rx.Patient}}{{.FirstName}} {{.LastName}}{{if not .DateOfBirth.IsZero}}  (DOB {{.DateOfBirth.Format "2006-01-02"}}){{end}}{{end}}
+
+Rx: {{.Rx.Drug}} {{.Rx.Dose}}
+Sig: {{.Rx.Route}}, {{.Rx.Frequency}}{{if .Rx.DurationDays}} for {{.Rx.DurationDays}} days{{end}}
+{{- if .Rx.Quantity}}
+Dispense: {{.Rx.Quantity}}{{end}}
+Refills: {{.Rx.Refills}}{{if .Rx.RefillsUsed}} ({{.Rx.RefillsUsed}} used){{end}}
+{{- if .Rx.Instructions}}
+Instructions: {{.Rx.Instructions}}{{end}}
+
+Prescriber: {{with .Rx.Doctor}}{{.Name}}{{end}}
+Signature: ______________________________
+`))
+
+// RenderPrescription formats a prescription loaded with its patient and
+// doctor.
+func RenderPrescription(clinic string, prescription *models.Prescription) (string, error) {
+	var out bytes.Buffer
+	err := prescriptionTemplate.Execute(&out, struct {
+		Clinic string
+		Rx     *models.Prescription
+	}{clinic, prescription})
+	return out.String(), err
+}
+
+func normalizeStatement(statement *models.MedicationStatement) error {
+	statement.Drug = strings.TrimSpace(statement.Drug)
+	if statement.Drug == "" {
+		return errors.New("drug is required")
+	}
+	if err := normalizeRoute(&statement.Route); err != nil {
+		return err
+	}
+	if statement.Status == "" {
+		statement.Status = models.MedicationActive
+	}
+	switch statement.Status {
+	case models.MedicationActive, models.MedicationStopped, models.MedicationCompleted:
+	default:
+		return errors.New("status must be active, stopped or completed")
+	}
+	if statement.StartDate != nil && statement.StopDate != nil && statement.StopDate.Before(*statement.StartDate) {
+		return errors.New("stop date cannot be before start date")
+	}
+	return nil
+}
+
+func normalizePrescription(prescription *models.Prescription) error {
+	prescription.Drug = strings.TrimSpace(prescription.Drug)
+	prescription.Dose = strings.TrimSpace(prescription.Dose)
+	prescription.Frequency = strings.TrimSpace(prescription.Frequency)
+	if prescription.Drug == "" || prescription.Dose == "" || prescription.Frequency == "" {
+		return errors.New("drug, dose and frequency are required")
+	}
+	if prescription.Refills < 0 || prescription.Refills > 12 {
+		return errors.New("refills must be between 0 and 12")
+	}
+	if prescription.DurationDays < 0 {
+		return errors.New("duration_days cannot be negative")
+	}
+	return normalizeRoute(&prescription.Route)
+}
+
+func normalizeRoute(route *models.MedicationRoute) error {
+	*route = models.MedicationRoute(strings.ToLower(strings.TrimSpace(string(*route))))
+	if *route == "" {
+		*route = models.RouteOral
+	}
+	switch *route {
+	case models.RouteOral, models.RouteSublingual, models.RouteTopical, models.RouteInhaled,
+		models.RouteIntravenous, models.RouteIntramuscular, models.RouteSubcutaneous,
+		models.RouteRectal, models.RouteOther:
+		return nil
+	}
+	return errors.New("invalid route")
+}
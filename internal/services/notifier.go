@@ -0,0 +1,27 @@
+package services
+
+import (
+	"log"
+)
+
+type NotificationChannel string
+
+const (
+	ChannelSMS   NotificationChannel = "sms"
+	ChannelEmail NotificationChannel = "email"
+)
+
+// Notifier delivers a message to a patient over one channel. to is a phone
+// number for SMS and an address for email.
+type Notifier interface {
+	Send(channel NotificationChannel, to, message string) error
+}
+
+// LogNotifier writes messages to the server log instead of delivering them.
+// It stands in until an SMS or email provider is configured.
+type LogNotifier struct{}
+
+func (LogNotifier) Send(channel NotificationChannel, to, message string) error {
+	log.Printf("Notification (%s) to %s: %s", channel, to, message)
+	return nil
+}
@@ -0,0 +1,277 @@
+package services
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"healthcare-portal/internal/models"
+)
+
+// Match weights in the Fellegi-Sunter style: each field adds its agreement
+// weight when it matches, a smaller amount on a near match and subtracts its
+// disagreement weight when both records have the field and it differs.
+// Fields missing on either side contribute nothing.
+type fieldWeight struct {
+	agree, partial, disagree float64
+}
+
+var (
+	lastNameWeight  = fieldWeight{agree: 4, partial: 2, disagree: 3}
+	firstNameWeight = fieldWeight{agree: 3, partial: 1.5, disagree: 2}
+	birthDateWeight = fieldWeight{agree: 5, partial: 2, disagree: 3}
+	phoneWeight     = fieldWeight{agree: 4, partial: 1, disagree: 1}
+	emailWeight     = fieldWeight{agree: 5, partial: 0, disagree: 0.5}
+)
+
+const (
+	// possibleMatchScore is the lowest score reported as a candidate: the
+	// same full name with nothing on file to tell the records apart.
+	possibleMatchScore = 7
+	// probableMatchScore needs the name plus the date of birth or phone.
+	probableMatchScore = 11
+
+	nameMatchSimilarity   = 0.94
+	namePartialSimilarity = 0.85
+)
+
+// PatientMatcher scores how likely two patient records describe the same
+// person from name similarity, date of birth, phone and email.
+type PatientMatcher struct{}
+
+// Candidates scores each existing patient against the new one and returns
+// those at or above the possible-match score, best first.
+func (m PatientMatcher) Candidates(patient *models.Patient, existing []models.Patient) []models.DuplicateCandidate {
+	candidates := []models.DuplicateCandidate{}
+	for _, other := range existing {
+		if patient.ID != 0 && other.ID == patient.ID {
+			continue
+		}
+		score, reasons := m.Score(patient, &other)
+		if score < possibleMatchScore {
+			continue
+		}
+		confidence := models.MatchPossible
+		if score >= probableMatchScore {
+			confidence = models.MatchProbable
+		}
+		candidates = append(candidates, models.DuplicateCandidate{
+			Patient:    other,
+			Score:      score,
+			Confidence: confidence,
+			Reasons:    reasons,
+		})
+	}
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].Score > candidates[j].Score })
+	return candidates
+}
+
+// Score returns the match weight of a against b and the fields that agreed.
+func (m PatientMatcher) Score(a, b *models.Patient) (float64, []string) {
+	score := 0.0
+	reasons := []string{}
+	add := func(weight fieldWeight, level matchLevel, reason string) {
+		switch level {
+		case fieldAgrees:
+			score += weight.agree
+			reasons = append(reasons, reason)
+		case fieldNearlyAgrees:
+			score += weight.partial
+			reasons = append(reasons, "similar "+reason)
+		case fieldDisagrees:
+			score -= weight.disagree
+		}
+	}
+
+	firstA, lastA := normalizeName(a.FirstName), normalizeName(a.LastName)
+	firstB, lastB := normalizeName(b.FirstName), normalizeName(b.LastName)
+	// Registrations sometimes swap first and last name; score whichever
+	// pairing fits better.
+	if nameSimilarity(firstA, lastB)+nameSimilarity(lastA, firstB) > nameSimilarity(firstA, firstB)+nameSimilarity(lastA, lastB) {
+		firstB, lastB = lastB, firstB
+	}
+	add(lastNameWeight, compareNames(lastA, lastB), "last name")
+	add(firstNameWeight, compareFirstNames(firstA, firstB), "first name")
+	add(birthDateWeight, compareBirthDates(a, b), "date of birth")
+	add(phoneWeight, comparePhones(NormalizePhone(a.Phone), NormalizePhone(b.Phone)), "phone")
+	add(emailWeight, compareExact(normalizeEmail(a.Email), normalizeEmail(b.Email)), "email")
+	return score, reasons
+}
+
+type matchLevel int
+
+const (
+	fieldMissing matchLevel = iota
+	fieldAgrees
+	fieldNearlyAgrees
+	fieldDisagrees
+)
+
+func compareNames(a, b string) matchLevel {
+	if a == "" || b == "" {
+		return fieldMissing
+	}
+	similarity := nameSimilarity(a, b)
+	switch {
+	case similarity >= nameMatchSimilarity:
+		return fieldAgrees
+	case similarity >= namePartialSimilarity:
+		return fieldNearlyAgrees
+	}
+	return fieldDisagrees
+}
+
+// compareFirstNames also treats an initial as a near match, so "J" and
+// "John" count for something.
+func compareFirstNames(a, b string) matchLevel {
+	level := compareNames(a, b)
+	if level == fieldDisagrees && (len(a) == 1 || len(b) == 1) && a[0] == b[0] {
+		return fieldNearlyAgrees
+	}
+	return level
+}
+
+// compareBirthDates accepts a swapped day and month, or a single wrong
+// component, as a near match since both are common keying errors.
+func compareBirthDates(a, b *models.Patient) matchLevel {
+	if a.DateOfBirth.IsZero() || b.DateOfBirth.IsZero() {
+		return fieldMissing
+	}
+	yearA, monthA, dayA := a.DateOfBirth.Date()
+	yearB, monthB, dayB := b.DateOfBirth.Date()
+	same := 0
+	if yearA == yearB {
+		same++
+	}
+	if monthA == monthB {
+		same++
+	}
+	if dayA == dayB {
+		same++
+	}
+	switch {
+	case same == 3:
+		return fieldAgrees
+	case same == 2, yearA == yearB && int(monthA) == dayB && dayA == int(monthB):
+		return fieldNearlyAgrees
+	}
+	return fieldDisagrees
+}
+
+// comparePhones treats the same local number with a different area code as a
+// near match.
+func comparePhones(a, b string) matchLevel {
+	if a == "" || b == "" {
+		return fieldMissing
+	}
+	if a == b {
+		return fieldAgrees
+	}
+	if len(a) >= 7 && len(b) >= 7 && a[len(a)-7:] == b[len(b)-7:] {
+		return fieldNearlyAgrees
+	}
+	return fieldDisagrees
+}
+
+func compareExact(a, b string) matchLevel {
+	if a == "" || b == "" {
+		return fieldMissing
+	}
+	if a == b {
+		return fieldAgrees
+	}
+	return fieldDisagrees
+}
+
+// NormalizePhone keeps the digits of a phone number and drops a leading
+// North American country code, so "+1 (555) 123-4567" and "555.123.4567"
+// compare equal.
+func NormalizePhone(phone string) string {
+	var digits strings.Builder
+	for _, r := range phone {
+		if r >= '0' && r <= '9' {
+			digits.WriteRune(r)
+		}
+	}
+	normalized := digits.String()
+	if len(normalized) == 11 && normalized[0] == '1' {
+		normalized = normalized[1:]
+	}
+	return normalized
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// normalizeName lower-cases a name and drops everything but letters, so
+// punctuation, spacing and hyphenation do not affect similarity.
+func normalizeName(name string) string {
+	var letters strings.Builder
+	for _, r := range strings.ToLower(name) {
+		if unicode.IsLetter(r) {
+			letters.WriteRune(r)
+		}
+	}
+	return letters.String()
+}
+
+// nameSimilarity is the Jaro-Winkler similarity of two normalized names, from
+// 0 (nothing in common) to 1 (identical).
+func nameSimilarity(a, b string) float64 {
+	if a == b {
+		if a == "" {
+			return 0
+		}
+		return 1
+	}
+	ra, rb := []rune(a), []rune(b)
+	if len(ra) == 0 || len(rb) == 0 {
+		return 0
+	}
+
+	window := max(len(ra), len(rb))/2 - 1
+	if window < 0 {
+		window = 0
+	}
+	matchedA := make([]bool, len(ra))
+	matchedB := make([]bool, len(rb))
+	matches := 0
+	for i := range ra {
+		start, end := max(0, i-window), min(len(rb), i+window+1)
+		for j := start; j < end; j++ {
+			if matchedB[j] || ra[i] != rb[j] {
+				continue
+			}
+			matchedA[i], matchedB[j] = true, true
+			matches++
+			break
+		}
+	}
+	if matches == 0 {
+		return 0
+	}
+
+	transpositions, j := 0, 0
+	for i := range ra {
+		if !matchedA[i] {
+			continue
+		}
+		for !matchedB[j] {
+			j++
+		}
+		if ra[i] != rb[j] {
+			transpositions++
+		}
+		j++
+	}
+
+	m := float64(matches)
+	jaro := (m/float64(len(ra)) + m/float64(len(rb)) + (m-float64(transpositions)/2)/m) / 3
+
+	prefix := 0
+	for prefix < min(4, len(ra), len(rb)) && ra[prefix] == rb[prefix] {
+		prefix++
+	}
+	return jaro + float64(prefix)*0.1*(1-jaro)
+}
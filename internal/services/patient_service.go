@@ -1,40 +1,168 @@
 package services
 
 import (
+    "errors"
+    "regexp"
+    "strings"
+    "time"
+
     "healthcare-portal/internal/models"
+    "healthcare-portal/internal/query"
     "healthcare-portal/internal/repository"
 )
 
+var (
+    ErrPatientNotFound    = errors.New("patient not found")
+    ErrMergeSamePatient   = errors.New("a patient cannot be merged into itself")
+    ErrIdentifierNotFound = errors.New("identifier not found")
+    ErrIdentifierInUse    = errors.New("identifier is already assigned to a patient")
+    ErrInvalidMRN         = errors.New("MRN check digit is invalid")
+    ErrSearchCriteria     = errors.New("search needs text of at least two characters, a date of birth or an MRN")
+)
+
+// maxSearchLimit caps the number of patient search results per page.
+const maxSearchLimit = 100
+
+var identifierSystemPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_.:-]*$`)
+
 type PatientService interface {
+    WithTenant(tenantID uint) PatientService
     CreatePatient(patient *models.Patient) error
     GetPatientByID(id uint) (*models.Patient, error)
-    GetAllPatients(limit, offset int) ([]models.Patient, int64, error)
+    ListPatients(req query.Request) (*query.Page[models.Patient], error)
     UpdatePatient(patient *models.Patient) error
     DeletePatient(id uint) error
-    SearchPatients(query string) ([]models.Patient, error)
+    SearchPatients(query models.PatientSearchQuery) (*models.PatientSearchPage, error)
+    FindDuplicates(patient *models.Patient) ([]models.DuplicateCandidate, error)
+    GetDuplicates(id uint) ([]models.DuplicateCandidate, error)
+    MergePatients(survivorID, duplicateID, mergedBy uint, reason string) (*models.PatientMerge, error)
+    GetPatientMerges(id uint) ([]models.PatientMerge, error)
+    LookupPatients(system, value string) ([]models.Patient, error)
+    GetIdentifiers(patientID uint) ([]models.PatientIdentifier, error)
+    AddIdentifier(identifier *models.PatientIdentifier) error
+    RemoveIdentifier(patientID, identifierID uint) error
+}
+
+// PossibleDuplicatesError is returned when a new patient matches existing
+// records and no override reason was given.
+type PossibleDuplicatesError struct {
+    Candidates []models.DuplicateCandidate
+}
+
+func (e *PossibleDuplicatesError) Error() string {
+    return "patient may already be registered; review the candidates or give a duplicate override reason"
+}
+
+// PatientMergedError is returned when looking up a patient that was merged
+// into another record.
+type PatientMergedError struct {
+    MergedIntoID uint
+}
+
+func (e *PatientMergedError) Error() string {
+    return "patient was merged into another record"
 }
 
 type patientService struct {
-    patientRepo repository.PatientRepository
+    patientRepo       repository.PatientRepository
+    identifierRepo    repository.PatientIdentifierRepository
+    relatedPersonRepo repository.RelatedPersonRepository
+    mrnGenerator      *MRNGenerator
+    matcher           PatientMatcher
+}
+
+func NewPatientService(patientRepo repository.PatientRepository, identifierRepo repository.PatientIdentifierRepository, relatedPersonRepo repository.RelatedPersonRepository, mrnGenerator *MRNGenerator) PatientService {
+    return &patientService{
+        patientRepo:       patientRepo,
+        identifierRepo:    identifierRepo,
+        relatedPersonRepo: relatedPersonRepo,
+        mrnGenerator:      mrnGenerator,
+    }
 }
 
-func NewPatientService(patientRepo repository.PatientRepository) PatientService {
-    return &patientService{patientRepo: patientRepo}
+// WithTenant returns a service that only sees tenantID's patients.
+func (s *patientService) WithTenant(tenantID uint) PatientService {
+    return &patientService{
+        patientRepo:       s.patientRepo.WithTenant(tenantID),
+        identifierRepo:    s.identifierRepo.WithTenant(tenantID),
+        relatedPersonRepo: s.relatedPersonRepo.WithTenant(tenantID),
+        mrnGenerator:      s.mrnGenerator.WithTenant(tenantID),
+    }
 }
 
+// CreatePatient registers a patient unless they look like someone already on
+// file. Possible duplicates are returned in a PossibleDuplicatesError; set
+// DuplicateOverrideReason to register the patient anyway. The patient is
+// issued a new MRN, and any external identifiers and related persons are
+// stored with them; minors must be registered with a guardian.
 func (s *patientService) CreatePatient(patient *models.Patient) error {
+    for i := range patient.Identifiers {
+        if err := s.normalizeIdentifier(&patient.Identifiers[i]); err != nil {
+            return err
+        }
+        patient.Identifiers[i].CreatedBy = patient.RegisteredBy
+    }
+    for i := range patient.RelatedPersons {
+        if err := normalizeRelatedPerson(&patient.RelatedPersons[i]); err != nil {
+            return err
+        }
+        patient.RelatedPersons[i].CreatedBy = patient.RegisteredBy
+        patient.RelatedPersons[i].UpdatedBy = patient.RegisteredBy
+    }
+    if patient.IsMinorOn(time.Now()) && !hasGuardian(patient.RelatedPersons) {
+        return ErrGuardianRequired
+    }
+
+    patient.DuplicateOverrideReason = strings.TrimSpace(patient.DuplicateOverrideReason)
+    if patient.DuplicateOverrideReason == "" {
+        candidates, err := s.FindDuplicates(patient)
+        if err != nil {
+            return err
+        }
+        if len(candidates) > 0 {
+            return &PossibleDuplicatesError{Candidates: candidates}
+        }
+    }
+
+    mrn, err := s.mrnGenerator.Next(patient.RegistrationLocationID)
+    if err != nil {
+        return err
+    }
+    patient.MRN = mrn
     return s.patientRepo.Create(patient)
 }
 
+// GetPatientByID returns the patient. For a record that was merged away the
+// error is a PatientMergedError naming the surviving record.
 func (s *patientService) GetPatientByID(id uint) (*models.Patient, error) {
-    return s.patientRepo.FindByID(id)
+    patient, err := s.patientRepo.FindByID(id)
+    if err == nil {
+        return patient, nil
+    }
+    if survivorID, ok := s.survivorOf(id); ok {
+        return nil, &PatientMergedError{MergedIntoID: survivorID}
+    }
+    return nil, err
 }
 
-func (s *patientService) GetAllPatients(limit, offset int) ([]models.Patient, int64, error) {
-    return s.patientRepo.FindAll(limit, offset)
+// ListPatients returns one page of patients, filtered and sorted as req
+// asks.
+func (s *patientService) ListPatients(req query.Request) (*query.Page[models.Patient], error) {
+    return s.patientRepo.List(req)
 }
 
+// UpdatePatient saves the patient's details. A date of birth that makes the
+// patient a minor needs a guardian on file first.
 func (s *patientService) UpdatePatient(patient *models.Patient) error {
+    if patient.IsMinorOn(time.Now()) {
+        guardians, err := s.relatedPersonRepo.CountGuardians(patient.ID)
+        if err != nil {
+            return err
+        }
+        if guardians == 0 {
+            return ErrGuardianRequired
+        }
+    }
     return s.patientRepo.Update(patient)
 }
 
@@ -42,6 +170,233 @@ func (s *patientService) DeletePatient(id uint) error {
     return s.patientRepo.Delete(id)
 }
 
-func (s *patientService) SearchPatients(query string) ([]models.Patient, error) {
+// SearchPatients runs a ranked patient search. Text on its own must be at
+// least two characters long, so a single letter does not list everyone.
+func (s *patientService) SearchPatients(query models.PatientSearchQuery) (*models.PatientSearchPage, error) {
+    query.Text = strings.TrimSpace(query.Text)
+    query.MRN = strings.TrimSpace(query.MRN)
+    if len([]rune(query.Text)) < 2 && query.DateOfBirth == nil && query.MRN == "" {
+        return nil, ErrSearchCriteria
+    }
+    if query.Limit > maxSearchLimit {
+        query.Limit = maxSearchLimit
+    }
     return s.patientRepo.Search(query)
-}
\ No newline at end of file
+}
+
+// FindDuplicates scores patient against the likely matches on file.
+func (s *patientService) FindDuplicates(patient *models.Patient) ([]models.DuplicateCandidate, error) {
+    existing, err := s.patientRepo.FindDuplicateCandidates(patient)
+    if err != nil {
+        return nil, err
+    }
+    return s.matcher.Candidates(patient, existing), nil
+}
+
+// GetDuplicates returns the possible duplicates of a registered patient.
+func (s *patientService) GetDuplicates(id uint) ([]models.DuplicateCandidate, error) {
+    patient, err := s.patientRepo.FindByID(id)
+    if err != nil {
+        return nil, ErrPatientNotFound
+    }
+    return s.FindDuplicates(patient)
+}
+
+// MergePatients folds the duplicate record into the survivor. Appointments
+// and clinical records move to the survivor, demographics the survivor lacks
+// are copied over, and the duplicate is soft-deleted behind a PatientMerge
+// tombstone that snapshots it.
+func (s *patientService) MergePatients(survivorID, duplicateID, mergedBy uint, reason string) (*models.PatientMerge, error) {
+    if survivorID == duplicateID {
+        return nil, ErrMergeSamePatient
+    }
+    survivor, err := s.patientRepo.FindByID(survivorID)
+    if err != nil {
+        return nil, ErrPatientNotFound
+    }
+    duplicate, err := s.patientRepo.FindByID(duplicateID)
+    if err != nil {
+        return nil, ErrPatientNotFound
+    }
+
+    merge := &models.PatientMerge{
+        SurvivorPatientID: survivor.ID,
+        MergedPatientID:   duplicate.ID,
+        Reason:            strings.TrimSpace(reason),
+        Snapshot:          *duplicate,
+        MergedBy:          mergedBy,
+    }
+    mergeDemographics(survivor, duplicate)
+    survivor.LastUpdatedBy = mergedBy
+    if err := s.patientRepo.Merge(survivor, duplicate, merge); err != nil {
+        return nil, err
+    }
+    return merge, nil
+}
+
+// GetPatientMerges lists the records merged into the patient.
+func (s *patientService) GetPatientMerges(id uint) ([]models.PatientMerge, error) {
+    if _, err := s.patientRepo.FindByID(id); err != nil {
+        return nil, ErrPatientNotFound
+    }
+    return s.patientRepo.FindMergesBySurvivorID(id)
+}
+
+// LookupPatients finds patients by an identifier. System "mrn" matches
+// current and merged-away MRNs; any other system matches its external
+// identifiers; an empty system matches the value in every system.
+func (s *patientService) LookupPatients(system, value string) ([]models.Patient, error) {
+    system = strings.ToLower(strings.TrimSpace(system))
+    mrn := strings.ToUpper(strings.TrimSpace(value))
+    if mrn == "" {
+        return nil, errors.New("identifier value is required")
+    }
+
+    patients := []models.Patient{}
+    seen := make(map[uint]bool)
+    add := func(patientID uint) {
+        if seen[patientID] {
+            return
+        }
+        patient, err := s.patientRepo.FindByID(patientID)
+        if err != nil {
+            return
+        }
+        seen[patientID] = true
+        patients = append(patients, *patient)
+    }
+
+    if system == "" || system == models.IdentifierMRN {
+        if system == models.IdentifierMRN && !s.mrnGenerator.Valid(mrn) {
+            return nil, ErrInvalidMRN
+        }
+        if patient, err := s.patientRepo.FindByMRN(mrn); err == nil {
+            add(patient.ID)
+        }
+        if previous, err := s.identifierRepo.FindBySystemValue(models.IdentifierPreviousMRN, mrn); err == nil {
+            add(previous.PatientID)
+        }
+        if system == models.IdentifierMRN {
+            return patients, nil
+        }
+    }
+
+    normalized := normalizeIdentifierValue(value)
+    if system != "" {
+        if identifier, err := s.identifierRepo.FindBySystemValue(system, normalized); err == nil {
+            add(identifier.PatientID)
+        }
+        return patients, nil
+    }
+    identifiers, err := s.identifierRepo.FindByValue(normalized)
+    if err != nil {
+        return nil, err
+    }
+    for _, identifier := range identifiers {
+        add(identifier.PatientID)
+    }
+    return patients, nil
+}
+
+func (s *patientService) GetIdentifiers(patientID uint) ([]models.PatientIdentifier, error) {
+    if _, err := s.patientRepo.FindByID(patientID); err != nil {
+        return nil, ErrPatientNotFound
+    }
+    return s.identifierRepo.FindByPatientID(patientID)
+}
+
+// AddIdentifier attaches an external identifier to a patient. The value must
+// not already be used in the same system.
+func (s *patientService) AddIdentifier(identifier *models.PatientIdentifier) error {
+    if _, err := s.patientRepo.FindByID(identifier.PatientID); err != nil {
+        return ErrPatientNotFound
+    }
+    if err := s.normalizeIdentifier(identifier); err != nil {
+        return err
+    }
+    return s.identifierRepo.Create(identifier)
+}
+
+func (s *patientService) RemoveIdentifier(patientID, identifierID uint) error {
+    identifier, err := s.identifierRepo.FindByID(identifierID)
+    if err != nil || identifier.PatientID != patientID {
+        return ErrIdentifierNotFound
+    }
+    return s.identifierRepo.Delete(identifierID)
+}
+
+// normalizeIdentifier validates the system, normalizes the value and checks
+// that no patient already holds it.
+func (s *patientService) normalizeIdentifier(identifier *models.PatientIdentifier) error {
+    identifier.System = strings.ToLower(strings.TrimSpace(identifier.System))
+    identifier.Value = normalizeIdentifierValue(identifier.Value)
+    identifier.Assigner = strings.TrimSpace(identifier.Assigner)
+    switch {
+    case identifier.System == models.IdentifierMRN, identifier.System == models.IdentifierPreviousMRN:
+        return errors.New("MRNs are issued by the system and cannot be added as identifiers")
+    case !identifierSystemPattern.MatchString(identifier.System):
+        return errors.New("identifier system must be lower-case letters, digits, '_', '.', ':' or '-'")
+    case identifier.Value == "":
+        return errors.New("identifier value is required")
+    }
+    if existing, err := s.identifierRepo.FindBySystemValue(identifier.System, identifier.Value); err == nil && existing.ID != identifier.ID {
+        return ErrIdentifierInUse
+    }
+    return nil
+}
+
+// normalizeIdentifierValue upper-cases a value and drops spaces and hyphens,
+// so "ab 123-456" and "AB123456" are the same identifier.
+func normalizeIdentifierValue(value string) string {
+    return strings.NewReplacer(" ", "", "-", "").Replace(strings.ToUpper(strings.TrimSpace(value)))
+}
+
+// survivorOf follows merge tombstones from id to the record that finally
+// survived.
+func (s *patientService) survivorOf(id uint) (uint, bool) {
+    survivorID, found := id, false
+    // Bound the walk in case of a corrupt cycle.
+    for i := 0; i < 10; i++ {
+        merge, err := s.patientRepo.FindMergeByMergedID(survivorID)
+        if err != nil {
+            break
+        }
+        survivorID, found = merge.SurvivorPatientID, true
+    }
+    return survivorID, found
+}
+
+// mergeDemographics fills the survivor's empty fields from the duplicate and
+// appends free-text history the survivor does not already contain.
+func mergeDemographics(survivor, duplicate *models.Patient) {
+    fillEmpty := func(dst *string, src string) {
+        if strings.TrimSpace(*dst) == "" {
+            *dst = src
+        }
+    }
+    fillEmpty(&survivor.Email, duplicate.Email)
+    fillEmpty(&survivor.Phone, duplicate.Phone)
+    fillEmpty(&survivor.Gender, duplicate.Gender)
+    fillEmpty(&survivor.Address, duplicate.Address)
+    fillEmpty(&survivor.EmergencyContact, duplicate.EmergencyContact)
+    fillEmpty(&survivor.BloodGroup, duplicate.BloodGroup)
+    fillEmpty(&survivor.InsuranceNumber, duplicate.InsuranceNumber)
+    if survivor.DateOfBirth.IsZero() {
+        survivor.DateOfBirth = duplicate.DateOfBirth
+    }
+
+    appendText := func(dst *string, src string) {
+        src = strings.TrimSpace(src)
+        if src == "" || strings.Contains(*dst, src) {
+            return
+        }
+        if strings.TrimSpace(*dst) == "" {
+            *dst = src
+            return
+        }
+        *dst += "\n" + src
+    }
+    appendText(&survivor.MedicalHistory, duplicate.MedicalHistory)
+    appendText(&survivor.CurrentMedication, duplicate.CurrentMedication)
+    appendText(&survivor.Allergies, duplicate.Allergies)
+}
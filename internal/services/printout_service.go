@@ -0,0 +1,571 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"healthcare-portal/internal/config"
+	"healthcare-portal/internal/models"
+	"healthcare-portal/internal/pdf"
+	"healthcare-portal/internal/printout"
+	"healthcare-portal/internal/repository"
+)
+
+var (
+	ErrPaymentNotFound      = errors.New("payment not found")
+	ErrAppointmentNotBooked = errors.New("confirmations can only be printed for scheduled appointments")
+	ErrVisitNotCompleted    = errors.New("visit summaries are only available for completed appointments")
+)
+
+// Printout is a rendered PDF and the name to download it as.
+type Printout struct {
+	FileName string
+	Content  []byte
+}
+
+// LoadBranding reads the clinic's branding, including its logo file.
+func LoadBranding(cfg config.BrandingConfig) (printout.Branding, error) {
+	branding := printout.Branding{
+		ClinicName: cfg.ClinicName,
+		Address:    cfg.Address,
+		Phone:      cfg.Phone,
+		Email:      cfg.Email,
+		Website:    cfg.Website,
+		Footer:     cfg.Footer,
+	}
+	accent, err := pdf.ParseColor(cfg.AccentColor)
+	if err != nil {
+		return branding, fmt.Errorf("accent color %q: %w", cfg.AccentColor, err)
+	}
+	branding.Accent = accent
+	if cfg.LogoFile != "" {
+		if branding.Logo, err = printout.LoadLogo(cfg.LogoFile); err != nil {
+			return branding, err
+		}
+	}
+	return branding, nil
+}
+
+// vitalLines are the single-value measurements printed on visit summaries,
+// after blood pressure.
+var vitalLines = []struct {
+	label       string
+	measurement string
+}{
+	{"Heart rate", models.VitalHeartRate},
+	{"Respiratory rate", models.VitalRespiratoryRate},
+	{"Temperature", models.VitalTemperature},
+	{"Oxygen saturation", models.VitalOxygenSaturation},
+	{"Weight", models.VitalWeight},
+	{"Height", models.VitalHeight},
+	{"BMI", models.VitalBMI},
+}
+
+type PrintoutService interface {
+	WithTenant(tenantID uint) PrintoutService
+	InvoicePDF(invoiceID uint) (*Printout, error)
+	ReceiptPDF(invoiceID, paymentID uint) (*Printout, error)
+	AppointmentConfirmationPDF(appointmentID uint) (*Printout, error)
+	VisitSummaryPDF(appointmentID uint) (*Printout, error)
+}
+
+type printoutService struct {
+	billingService  BillingService
+	patientRepo     repository.PatientRepository
+	appointmentRepo repository.AppointmentRepository
+	problemRepo     repository.ProblemRepository
+	vitalsRepo      repository.VitalsRepository
+	medicationRepo  repository.MedicationRepository
+	allergyRepo     repository.AllergyRepository
+	tenantRepo      repository.TenantRepository
+	tenantID        uint
+	branding        printout.Branding
+}
+
+func NewPrintoutService(billingService BillingService, patientRepo repository.PatientRepository, appointmentRepo repository.AppointmentRepository, problemRepo repository.ProblemRepository, vitalsRepo repository.VitalsRepository, medicationRepo repository.MedicationRepository, allergyRepo repository.AllergyRepository, tenantRepo repository.TenantRepository, branding printout.Branding) PrintoutService {
+	return &printoutService{
+		billingService:  billingService,
+		patientRepo:     patientRepo,
+		appointmentRepo: appointmentRepo,
+		problemRepo:     problemRepo,
+		vitalsRepo:      vitalsRepo,
+		medicationRepo:  medicationRepo,
+		allergyRepo:     allergyRepo,
+		tenantRepo:      tenantRepo,
+		branding:        branding,
+	}
+}
+
+// WithTenant returns a service that only prints tenantID's records, under
+// the tenant's name unless a clinic name is configured.
+func (s *printoutService) WithTenant(tenantID uint) PrintoutService {
+	return &printoutService{
+		billingService:  s.billingService.WithTenant(tenantID),
+		patientRepo:     s.patientRepo.WithTenant(tenantID),
+		appointmentRepo: s.appointmentRepo.WithTenant(tenantID),
+		problemRepo:     s.problemRepo.WithTenant(tenantID),
+		vitalsRepo:      s.vitalsRepo.WithTenant(tenantID),
+		medicationRepo:  s.medicationRepo.WithTenant(tenantID),
+		allergyRepo:     s.allergyRepo.WithTenant(tenantID),
+		tenantRepo:      s.tenantRepo,
+		tenantID:        tenantID,
+		branding:        s.branding,
+	}
+}
+
+// InvoicePDF prints the invoice with its charges, payments and the balance
+// still owed.
+func (s *printoutService) InvoicePDF(invoiceID uint) (*Printout, error) {
+	invoice, err := s.billingService.GetInvoiceByID(invoiceID)
+	if err != nil {
+		return nil, err
+	}
+	patient, err := s.patientRepo.FindByID(invoice.PatientID)
+	if err != nil {
+		return nil, ErrPatientNotFound
+	}
+
+	title := "Invoice"
+	if invoice.Status == models.InvoiceVoid {
+		title = "Invoice (Void)"
+	}
+	doc := printout.Document{
+		Title: title,
+		Reference: []string{
+			invoice.Number,
+			"Issued " + printDate(invoice.IssuedAt),
+			"Due " + printDate(invoice.DueDate),
+		},
+	}
+	doc.Blocks = append(doc.Blocks, printout.Fields{Items: []printout.Field{
+		{Label: "Bill to", Value: patientName(patient)},
+		{Label: "Medical record number", Value: patient.MRN},
+		{Label: "Address", Value: patient.Address},
+		{Label: "Amount due", Value: formatDollars(invoice.BalanceCents)},
+	}})
+	if invoice.Status == models.InvoiceVoid {
+		doc.Blocks = append(doc.Blocks, printout.Paragraph{Text: "This invoice has been voided and nothing is owed on it. " + invoice.VoidReason})
+	}
+
+	charges := printout.Table{
+		Columns: []printout.Column{
+			{Title: "Date", Width: 1.3},
+			{Title: "Description", Width: 4},
+			{Title: "Code", Width: 1},
+			{Title: "Qty", Width: 0.6, Align: printout.AlignRight},
+			{Title: "Unit price", Width: 1.3, Align: printout.AlignRight},
+			{Title: "Amount", Width: 1.3, Align: printout.AlignRight},
+		},
+	}
+	for _, charge := range invoice.Charges {
+		charges.Rows = append(charges.Rows, []string{
+			printDate(charge.ServiceDate),
+			charge.Description,
+			charge.Code,
+			strconv.Itoa(charge.Quantity),
+			formatDollars(charge.UnitPriceCents),
+			formatDollars(charge.AmountCents),
+		})
+	}
+	charges.Totals = append(charges.Totals, printout.Field{Label: "Subtotal", Value: formatDollars(invoice.SubtotalCents)})
+	if invoice.DiscountCents != 0 {
+		label := "Discount"
+		if invoice.DiscountReason != "" {
+			label += " (" + invoice.DiscountReason + ")"
+		}
+		charges.Totals = append(charges.Totals, printout.Field{Label: label, Value: formatDollars(-invoice.DiscountCents)})
+	}
+	if invoice.TaxCents != 0 {
+		rate := strconv.FormatFloat(float64(invoice.TaxRateBasisPoints)/100, 'f', -1, 64)
+		charges.Totals = append(charges.Totals, printout.Field{Label: "Tax (" + rate + "%)", Value: formatDollars(invoice.TaxCents)})
+	}
+	charges.Totals = append(charges.Totals,
+		printout.Field{Label: "Total", Value: formatDollars(invoice.TotalCents)},
+		printout.Field{Label: "Paid", Value: formatDollars(-invoice.PaidCents)},
+		printout.Field{Label: "Balance due", Value: formatDollars(invoice.BalanceCents)},
+	)
+	doc.Blocks = append(doc.Blocks, printout.Heading{Text: "Charges"}, charges)
+
+	if len(invoice.Payments) > 0 {
+		payments := printout.Table{
+			Columns: []printout.Column{
+				{Title: "Date", Width: 1.3},
+				{Title: "Payment", Width: 3},
+				{Title: "Reference", Width: 2.5},
+				{Title: "Amount", Width: 1.3, Align: printout.AlignRight},
+			},
+		}
+		for _, payment := range invoice.Payments {
+			payments.Rows = append(payments.Rows, []string{
+				printDate(payment.ReceivedAt),
+				paymentDescription(payment),
+				payment.Reference,
+				formatDollars(signedAmount(payment)),
+			})
+		}
+		doc.Blocks = append(doc.Blocks, printout.Heading{Text: "Payments"}, payments)
+	}
+
+	if invoice.Notes != "" {
+		doc.Blocks = append(doc.Blocks, printout.Heading{Text: "Notes"}, printout.Paragraph{Text: invoice.Notes})
+	}
+	if invoice.Status == models.InvoiceOpen && invoice.BalanceCents > 0 {
+		doc.Blocks = append(doc.Blocks, printout.Paragraph{
+			Text:  fmt.Sprintf("Please pay %s by %s and quote %s with your payment.", formatDollars(invoice.BalanceCents), printDate(invoice.DueDate), invoice.Number),
+			Muted: true,
+		})
+	}
+
+	return s.render(invoice.Number+".pdf", doc)
+}
+
+// ReceiptPDF prints a receipt for one payment or refund on the invoice,
+// showing the balance left once it was recorded.
+func (s *printoutService) ReceiptPDF(invoiceID, paymentID uint) (*Printout, error) {
+	invoice, err := s.billingService.GetInvoiceByID(invoiceID)
+	if err != nil {
+		return nil, err
+	}
+	var payment *models.Payment
+	var paidThrough int64
+	for i := range invoice.Payments {
+		paidThrough += signedAmount(invoice.Payments[i])
+		if invoice.Payments[i].ID == paymentID {
+			payment = &invoice.Payments[i]
+			break
+		}
+	}
+	if payment == nil {
+		return nil, ErrPaymentNotFound
+	}
+	patient, err := s.patientRepo.FindByID(invoice.PatientID)
+	if err != nil {
+		return nil, ErrPatientNotFound
+	}
+
+	title, party, thanks := "Payment Receipt", "Received from", "Thank you for your payment."
+	if payment.Kind == models.PaymentRefund {
+		title, party, thanks = "Refund Receipt", "Refunded to", "This refund has been credited against the invoice above."
+	}
+	doc := printout.Document{
+		Title: title,
+		Reference: []string{
+			fmt.Sprintf("Receipt %s-%d", invoice.Number, payment.ID),
+			printDate(payment.ReceivedAt),
+		},
+		Blocks: []printout.Block{
+			printout.Fields{Items: []printout.Field{
+				{Label: party, Value: patientName(patient)},
+				{Label: "Medical record number", Value: patient.MRN},
+				{Label: "Amount", Value: formatDollars(payment.AmountCents)},
+				{Label: "Method", Value: humanize(string(payment.Method))},
+				{Label: "Reference", Value: payment.Reference},
+				{Label: "Date", Value: printDate(payment.ReceivedAt)},
+			}},
+			printout.Heading{Text: "Invoice " + invoice.Number},
+			printout.Fields{Items: []printout.Field{
+				{Label: "Issued", Value: printDate(invoice.IssuedAt)},
+				{Label: "Invoice total", Value: formatDollars(invoice.TotalCents)},
+				{Label: "Paid to date", Value: formatDollars(paidThrough)},
+				{Label: "Balance remaining", Value: formatDollars(invoice.TotalCents - paidThrough)},
+			}},
+			printout.Paragraph{Text: thanks},
+		},
+	}
+
+	return s.render(fmt.Sprintf("receipt-%s-%d.pdf", invoice.Number, payment.ID), doc)
+}
+
+// AppointmentConfirmationPDF prints a letter confirming a booked
+// appointment, with any preparation its type calls for.
+func (s *printoutService) AppointmentConfirmationPDF(appointmentID uint) (*Printout, error) {
+	appointment, err := s.appointmentRepo.FindByID(appointmentID)
+	if err != nil {
+		return nil, ErrAppointmentNotFound
+	}
+	if appointment.Status != models.StatusScheduled && appointment.Status != models.StatusCheckedIn {
+		return nil, ErrAppointmentNotBooked
+	}
+
+	greeting := "This letter confirms your appointment with us."
+	if appointment.Patient != nil {
+		greeting = "Dear " + appointment.Patient.FirstName + ", this letter confirms your appointment with us."
+	}
+	doc := printout.Document{
+		Title:     "Appointment Confirmation",
+		Reference: []string{fmt.Sprintf("Appointment #%d", appointment.ID)},
+		Blocks: []printout.Block{
+			printout.Paragraph{Text: greeting},
+			printout.Fields{Items: s.visitFields(appointment)},
+		},
+	}
+	if appointment.AppointmentType != nil && appointment.AppointmentType.PreparationInstructions != "" {
+		doc.Blocks = append(doc.Blocks,
+			printout.Heading{Text: "Before your visit"},
+			printout.Paragraph{Text: appointment.AppointmentType.PreparationInstructions},
+		)
+	}
+	closing := "Please arrive 10 minutes early and bring photo ID and your insurance card."
+	if phone := s.contactPhone(appointment); phone != "" {
+		closing += " To reschedule or cancel, call us at " + phone + "."
+	}
+	doc.Blocks = append(doc.Blocks, printout.Paragraph{Text: closing, Muted: true})
+
+	return s.render(fmt.Sprintf("appointment-%d-confirmation.pdf", appointment.ID), doc)
+}
+
+// VisitSummaryPDF prints the after-visit summary a patient takes home: the
+// visit's diagnoses and vital signs, the patient's current medications and
+// allergies, and their upcoming appointments. Clinical notes are left out.
+func (s *printoutService) VisitSummaryPDF(appointmentID uint) (*Printout, error) {
+	appointment, err := s.appointmentRepo.FindByID(appointmentID)
+	if err != nil {
+		return nil, ErrAppointmentNotFound
+	}
+	if appointment.Status != models.StatusCompleted {
+		return nil, ErrVisitNotCompleted
+	}
+
+	diagnoses, err := s.problemRepo.FindAppointmentDiagnoses(appointment.ID)
+	if err != nil {
+		return nil, err
+	}
+	vitals, err := s.vitalsRepo.FindByAppointmentID(appointment.ID)
+	if err != nil {
+		return nil, err
+	}
+	medications, err := s.medicationRepo.FindStatementsByPatientID(appointment.PatientID, true)
+	if err != nil {
+		return nil, err
+	}
+	allergies, err := s.allergyRepo.FindByPatientID(appointment.PatientID)
+	if err != nil {
+		return nil, err
+	}
+	appointments, err := s.appointmentRepo.FindByPatientID(appointment.PatientID)
+	if err != nil {
+		return nil, err
+	}
+
+	doc := printout.Document{
+		Title:     "After-Visit Summary",
+		Reference: []string{"Visit on " + printDate(appointment.Date)},
+	}
+	var patientFields []printout.Field
+	if appointment.Patient != nil {
+		patientFields = []printout.Field{
+			{Label: "Patient", Value: patientName(appointment.Patient)},
+			{Label: "Date of birth", Value: printDate(appointment.Patient.DateOfBirth)},
+			{Label: "Medical record number", Value: appointment.Patient.MRN},
+		}
+	}
+	doc.Blocks = append(doc.Blocks, printout.Fields{Items: append(patientFields, s.visitFields(appointment)...)})
+
+	diagnosisTable := printout.Table{
+		Columns: []printout.Column{{Title: "Code", Width: 1}, {Title: "Diagnosis", Width: 5}},
+		Empty:   "No diagnoses were recorded for this visit.",
+	}
+	for _, diagnosis := range diagnoses {
+		diagnosisTable.Rows = append(diagnosisTable.Rows, []string{diagnosis.Code, diagnosis.Description})
+	}
+	doc.Blocks = append(doc.Blocks, printout.Heading{Text: "Diagnoses"}, diagnosisTable)
+
+	doc.Blocks = append(doc.Blocks, printout.Heading{Text: "Vital signs"})
+	if len(vitals) == 0 {
+		doc.Blocks = append(doc.Blocks, printout.Paragraph{Text: "No vital signs were recorded at this visit."})
+	} else {
+		doc.Blocks = append(doc.Blocks, printout.Fields{Items: vitalFields(&vitals[len(vitals)-1]), Columns: 4})
+	}
+
+	medicationTable := printout.Table{
+		Columns: []printout.Column{{Title: "Medication", Width: 3}, {Title: "Dose", Width: 1.5}, {Title: "Route", Width: 1.2}, {Title: "How often", Width: 2}},
+		Empty:   "No current medications on record.",
+	}
+	for _, medication := range medications {
+		medicationTable.Rows = append(medicationTable.Rows, []string{medication.Drug, medication.Dose, humanize(string(medication.Route)), medication.Frequency})
+	}
+	doc.Blocks = append(doc.Blocks, printout.Heading{Text: "Your medications"}, medicationTable)
+
+	allergyTable := printout.Table{
+		Columns: []printout.Column{{Title: "Allergy", Width: 2}, {Title: "Reaction", Width: 3}, {Title: "Severity", Width: 1.2}},
+		Empty:   "No known allergies.",
+	}
+	for _, allergy := range allergies {
+		if allergy.Status == models.AllergyActive {
+			allergyTable.Rows = append(allergyTable.Rows, []string{allergy.Substance, allergy.Reaction, humanize(string(allergy.Severity))})
+		}
+	}
+	doc.Blocks = append(doc.Blocks, printout.Heading{Text: "Allergies"}, allergyTable)
+
+	upcomingTable := printout.Table{
+		Columns: []printout.Column{{Title: "Date", Width: 1.6}, {Title: "Time", Width: 0.8}, {Title: "With", Width: 2}, {Title: "Where", Width: 2}},
+		Empty:   "No upcoming appointments are booked.",
+	}
+	for _, upcoming := range upcomingAppointments(appointments, time.Now()) {
+		upcomingTable.Rows = append(upcomingTable.Rows, []string{printDate(upcoming.Date), upcoming.Time, doctorName(&upcoming), locationName(&upcoming)})
+	}
+	doc.Blocks = append(doc.Blocks, printout.Heading{Text: "Upcoming appointments"}, upcomingTable)
+
+	closing := "This summary lists what was recorded at your visit. If you have questions about your care, contact the clinic"
+	if phone := s.contactPhone(appointment); phone != "" {
+		closing += " at " + phone
+	}
+	closing += ". In an emergency, call 911."
+	doc.Blocks = append(doc.Blocks, printout.Paragraph{Text: closing, Muted: true})
+
+	return s.render(fmt.Sprintf("visit-summary-%d.pdf", appointment.ID), doc)
+}
+
+func (s *printoutService) render(fileName string, doc printout.Document) (*Printout, error) {
+	branding := s.branding
+	if branding.ClinicName == "" && s.tenantID != 0 {
+		if tenant, err := s.tenantRepo.FindByID(s.tenantID); err == nil {
+			branding.ClinicName = tenant.Name
+		}
+	}
+
+	content, err := printout.Render(branding, doc)
+	if err != nil {
+		return nil, err
+	}
+	return &Printout{FileName: fileName, Content: content}, nil
+}
+
+// visitFields describes when, where and with whom an appointment is.
+func (s *printoutService) visitFields(appointment *models.Appointment) []printout.Field {
+	visitType := ""
+	if appointment.AppointmentType != nil {
+		visitType = appointment.AppointmentType.Name
+	}
+	fields := []printout.Field{
+		{Label: "Date", Value: appointment.Date.Format("Monday, January 2, 2006")},
+		{Label: "Time", Value: fmt.Sprintf("%s (%d minutes)", appointment.Time, appointment.DurationMinutes)},
+		{Label: "Provider", Value: doctorName(appointment)},
+		{Label: "Visit type", Value: visitType},
+		{Label: "Location", Value: locationName(appointment)},
+	}
+	if appointment.Location != nil && appointment.Location.Address != "" {
+		fields = append(fields, printout.Field{Label: "Address", Value: appointment.Location.Address})
+	}
+	return fields
+}
+
+// contactPhone is the number patients should call about an appointment:
+// its location's, or the clinic's.
+func (s *printoutService) contactPhone(appointment *models.Appointment) string {
+	if appointment.Location != nil && appointment.Location.Phone != "" {
+		return appointment.Location.Phone
+	}
+	return s.branding.Phone
+}
+
+func vitalFields(vitals *models.VitalSigns) []printout.Field {
+	var fields []printout.Field
+	if vitals.SystolicBP != nil && vitals.DiastolicBP != nil {
+		fields = append(fields, printout.Field{
+			Label: "Blood pressure",
+			Value: fmt.Sprintf("%d/%d %s", *vitals.SystolicBP, *vitals.DiastolicBP, models.VitalRanges[models.VitalSystolicBP].Unit),
+		})
+	}
+	for _, line := range vitalLines {
+		value, ok := vitals.Value(line.measurement)
+		if !ok {
+			continue
+		}
+		fields = append(fields, printout.Field{
+			Label: line.label,
+			Value: strconv.FormatFloat(value, 'f', -1, 64) + " " + models.VitalRanges[line.measurement].Unit,
+		})
+	}
+	return fields
+}
+
+// upcomingAppointments returns the booked appointments from today on,
+// soonest first.
+func upcomingAppointments(appointments []models.Appointment, now time.Time) []models.Appointment {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	var upcoming []models.Appointment
+	for _, appointment := range appointments {
+		if appointment.Status == models.StatusScheduled && !appointment.Date.Before(today) {
+			upcoming = append(upcoming, appointment)
+		}
+	}
+	sort.SliceStable(upcoming, func(i, j int) bool {
+		if !upcoming[i].Date.Equal(upcoming[j].Date) {
+			return upcoming[i].Date.Before(upcoming[j].Date)
+		}
+		return upcoming[i].Time < upcoming[j].Time
+	})
+	return upcoming
+}
+
+func patientName(patient *models.Patient) string {
+	return patient.FirstName + " " + patient.LastName
+}
+
+func doctorName(appointment *models.Appointment) string {
+	if appointment.Doctor == nil {
+		return ""
+	}
+	if appointment.Doctor.Specialty != "" {
+		return appointment.Doctor.Name + ", " + appointment.Doctor.Specialty
+	}
+	return appointment.Doctor.Name
+}
+
+func locationName(appointment *models.Appointment) string {
+	if appointment.Location == nil {
+		return ""
+	}
+	return appointment.Location.Name
+}
+
+func paymentDescription(payment models.Payment) string {
+	if payment.Kind == models.PaymentRefund {
+		return "Refund (" + humanize(string(payment.Method)) + ")"
+	}
+	return humanize(string(payment.Method)) + " payment"
+}
+
+// signedAmount is the payment's effect on what has been paid: negative for
+// refunds.
+func signedAmount(payment models.Payment) int64 {
+	if payment.Kind == models.PaymentRefund {
+		return -payment.AmountCents
+	}
+	return payment.AmountCents
+}
+
+// formatDollars prints cents as dollars with thousands separators, e.g.
+// $1,234.50 or -$20.00.
+func formatDollars(cents int64) string {
+	sign := ""
+	if cents < 0 {
+		sign, cents = "-", -cents
+	}
+	dollars := strconv.FormatInt(cents/100, 10)
+	for i := len(dollars) - 3; i > 0; i -= 3 {
+		dollars = dollars[:i] + "," + dollars[i:]
+	}
+	return fmt.Sprintf("%s$%s.%02d", sign, dollars, cents%100)
+}
+
+func printDate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format("Jan 2, 2006")
+}
+
+// humanize turns a stored code such as "checked_in" into "Checked in".
+func humanize(code string) string {
+	if code == "" {
+		return ""
+	}
+	text := strings.ReplaceAll(code, "_", " ")
+	return strings.ToUpper(text[:1]) + text[1:]
+}
@@ -0,0 +1,268 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"healthcare-portal/internal/models"
+	"healthcare-portal/internal/repository"
+)
+
+// maxAppointmentDiagnoses matches the number of diagnosis codes a
+// professional claim can carry.
+const maxAppointmentDiagnoses = 12
+
+// DiagnosisEntry is a code attached to an appointment. AddToProblemList also
+// records it on the patient's problem list if it is not already active there.
+type DiagnosisEntry struct {
+	Code             string
+	AddToProblemList bool
+}
+
+type ProblemService interface {
+	WithTenant(tenantID uint) ProblemService
+	CreateProblem(problem *models.Problem) error
+	GetProblemByID(id uint) (*models.Problem, error)
+	GetPatientProblems(patientID uint, status models.ProblemStatus) ([]models.Problem, error)
+	UpdateProblem(problem *models.Problem) error
+	ResolveProblem(id uint, resolvedDate *time.Time) (*models.Problem, error)
+	DeleteProblem(id uint) error
+	GetAppointmentDiagnoses(appointmentID uint) ([]models.AppointmentDiagnosis, error)
+	SetAppointmentDiagnoses(appointmentID, recordedBy uint, entries []DiagnosisEntry) ([]models.AppointmentDiagnosis, error)
+}
+
+type problemService struct {
+	problemRepo     repository.ProblemRepository
+	patientRepo     repository.PatientRepository
+	appointmentRepo repository.AppointmentRepository
+	encounterRepo   repository.EncounterRepository
+	codeRepo        repository.DiagnosisCodeRepository
+}
+
+func NewProblemService(problemRepo repository.ProblemRepository, patientRepo repository.PatientRepository, appointmentRepo repository.AppointmentRepository, encounterRepo repository.EncounterRepository, codeRepo repository.DiagnosisCodeRepository) ProblemService {
+	return &problemService{
+		problemRepo:     problemRepo,
+		patientRepo:     patientRepo,
+		appointmentRepo: appointmentRepo,
+		encounterRepo:   encounterRepo,
+		codeRepo:        codeRepo,
+	}
+}
+
+// WithTenant returns a service limited to tenantID's patients. The code
+// table is shared.
+func (s *problemService) WithTenant(tenantID uint) ProblemService {
+	return &problemService{
+		problemRepo:     s.problemRepo.WithTenant(tenantID),
+		patientRepo:     s.patientRepo.WithTenant(tenantID),
+		appointmentRepo: s.appointmentRepo.WithTenant(tenantID),
+		encounterRepo:   s.encounterRepo.WithTenant(tenantID),
+		codeRepo:        s.codeRepo,
+	}
+}
+
+// CreateProblem adds a coded diagnosis to the patient's problem list. The
+// description defaults to the code table's.
+func (s *problemService) CreateProblem(problem *models.Problem) error {
+	if _, err := s.patientRepo.FindByID(problem.PatientID); err != nil {
+		return errors.New("patient not found")
+	}
+	if err := s.normalizeProblem(problem); err != nil {
+		return err
+	}
+	if err := s.checkDuplicate(problem); err != nil {
+		return err
+	}
+	return s.problemRepo.Create(problem)
+}
+
+func (s *problemService) GetProblemByID(id uint) (*models.Problem, error) {
+	return s.problemRepo.FindByID(id)
+}
+
+func (s *problemService) GetPatientProblems(patientID uint, status models.ProblemStatus) ([]models.Problem, error) {
+	if _, err := s.patientRepo.FindByID(patientID); err != nil {
+		return nil, errors.New("patient not found")
+	}
+	return s.problemRepo.FindByPatientID(patientID, status)
+}
+
+func (s *problemService) UpdateProblem(problem *models.Problem) error {
+	if err := s.normalizeProblem(problem); err != nil {
+		return err
+	}
+	if err := s.checkDuplicate(problem); err != nil {
+		return err
+	}
+	return s.problemRepo.Update(problem)
+}
+
+// ResolveProblem marks a problem resolved, today unless a date is given.
+func (s *problemService) ResolveProblem(id uint, resolvedDate *time.Time) (*models.Problem, error) {
+	problem, err := s.problemRepo.FindByID(id)
+	if err != nil {
+		return nil, errors.New("problem not found")
+	}
+	if problem.Status == models.ProblemResolved {
+		return nil, errors.New("problem is already resolved")
+	}
+
+	problem.Status = models.ProblemResolved
+	problem.ResolvedDate = resolvedDate
+	if err := s.normalizeProblem(problem); err != nil {
+		return nil, err
+	}
+	if err := s.problemRepo.Update(problem); err != nil {
+		return nil, err
+	}
+	return problem, nil
+}
+
+func (s *problemService) DeleteProblem(id uint) error {
+	return s.problemRepo.Delete(id)
+}
+
+func (s *problemService) GetAppointmentDiagnoses(appointmentID uint) ([]models.AppointmentDiagnosis, error) {
+	if _, err := s.appointmentRepo.FindByID(appointmentID); err != nil {
+		return nil, errors.New("appointment not found")
+	}
+	return s.problemRepo.FindAppointmentDiagnoses(appointmentID)
+}
+
+// SetAppointmentDiagnoses replaces the diagnoses of a completed appointment.
+// The first entry is the primary diagnosis. Entries flagged for the problem
+// list are added there, linked to the appointment's encounter if one exists.
+func (s *problemService) SetAppointmentDiagnoses(appointmentID, recordedBy uint, entries []DiagnosisEntry) ([]models.AppointmentDiagnosis, error) {
+	appointment, err := s.appointmentRepo.FindByID(appointmentID)
+	if err != nil {
+		return nil, errors.New("appointment not found")
+	}
+	if appointment.Status != models.StatusCompleted {
+		return nil, errors.New("diagnoses can only be attached to completed appointments")
+	}
+	if len(entries) > maxAppointmentDiagnoses {
+		return nil, fmt.Errorf("an appointment can have at most %d diagnoses", maxAppointmentDiagnoses)
+	}
+
+	diagnoses := make([]models.AppointmentDiagnosis, 0, len(entries))
+	seen := make(map[string]bool, len(entries))
+	for i, entry := range entries {
+		code, err := s.lookupCode(entry.Code)
+		if err != nil {
+			return nil, err
+		}
+		if seen[code.Code] {
+			return nil, fmt.Errorf("diagnosis %s is listed twice", code.Code)
+		}
+		seen[code.Code] = true
+		diagnoses = append(diagnoses, models.AppointmentDiagnosis{
+			AppointmentID: appointment.ID,
+			Code:          code.Code,
+			Description:   code.Description,
+			Rank:          i + 1,
+		})
+	}
+
+	if err := s.problemRepo.ReplaceAppointmentDiagnoses(appointment.ID, diagnoses); err != nil {
+		return nil, err
+	}
+
+	var encounterID *uint
+	if encounter, err := s.encounterRepo.FindByAppointmentID(appointment.ID); err == nil {
+		encounterID = &encounter.ID
+	}
+	for i, entry := range entries {
+		if !entry.AddToProblemList {
+			continue
+		}
+		onset := appointment.Date
+		problem := &models.Problem{
+			PatientID:   appointment.PatientID,
+			Code:        diagnoses[i].Code,
+			Description: diagnoses[i].Description,
+			Status:      models.ProblemActive,
+			OnsetDate:   &onset,
+			EncounterID: encounterID,
+			RecordedBy:  recordedBy,
+		}
+		if err := s.checkDuplicate(problem); err != nil {
+			continue
+		}
+		if err := s.problemRepo.Create(problem); err != nil {
+			return nil, err
+		}
+	}
+
+	return diagnoses, nil
+}
+
+func (s *problemService) lookupCode(code string) (*models.DiagnosisCode, error) {
+	diagnosisCode, err := s.codeRepo.FindByCode(NormalizeDiagnosisCode(code))
+	if err != nil {
+		return nil, fmt.Errorf("unknown ICD-10 code %q", code)
+	}
+	return diagnosisCode, nil
+}
+
+// checkDuplicate rejects a second active entry for the same code.
+func (s *problemService) checkDuplicate(problem *models.Problem) error {
+	if problem.Status != models.ProblemActive {
+		return nil
+	}
+	active, err := s.problemRepo.FindByPatientID(problem.PatientID, models.ProblemActive)
+	if err != nil {
+		return err
+	}
+	for _, other := range active {
+		if other.ID != problem.ID && other.Code == problem.Code {
+			return fmt.Errorf("%s is already an active problem", problem.Code)
+		}
+	}
+	return nil
+}
+
+func (s *problemService) normalizeProblem(problem *models.Problem) error {
+	code, err := s.lookupCode(problem.Code)
+	if err != nil {
+		return err
+	}
+	problem.Code = code.Code
+	problem.Description = strings.TrimSpace(problem.Description)
+	if problem.Description == "" {
+		problem.Description = code.Description
+	}
+	problem.Notes = strings.TrimSpace(problem.Notes)
+
+	if problem.Status == "" {
+		problem.Status = models.ProblemActive
+	}
+	switch problem.Status {
+	case models.ProblemActive:
+		problem.ResolvedDate = nil
+	case models.ProblemResolved:
+		if problem.ResolvedDate == nil {
+			today := time.Now().Truncate(24 * time.Hour)
+			problem.ResolvedDate = &today
+		}
+	default:
+		return errors.New("status must be active or resolved")
+	}
+
+	now := time.Now()
+	if problem.OnsetDate != nil && problem.OnsetDate.After(now) {
+		return errors.New("onset date cannot be in the future")
+	}
+	if problem.OnsetDate != nil && problem.ResolvedDate != nil && problem.ResolvedDate.Before(*problem.OnsetDate) {
+		return errors.New("resolved date cannot be before the onset date")
+	}
+
+	if problem.EncounterID != nil {
+		encounter, err := s.encounterRepo.FindByID(*problem.EncounterID)
+		if err != nil || encounter.PatientID != problem.PatientID {
+			return errors.New("encounter not found for this patient")
+		}
+	}
+	return nil
+}
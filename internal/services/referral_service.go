@@ -0,0 +1,294 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"healthcare-portal/internal/models"
+	"healthcare-portal/internal/repository"
+)
+
+var (
+	ErrReferralNotFound    = errors.New("referral not found")
+	ErrNotReferralReceiver = errors.New("only the receiving doctor can respond to this referral")
+	ErrNotReferralParty    = errors.New("only the referring or receiving doctor can close this referral")
+	ErrReferralState       = errors.New("referral cannot move to that state")
+)
+
+// referralTransitions lists the states each referral state may move to.
+var referralTransitions = map[models.ReferralStatus][]models.ReferralStatus{
+	models.ReferralSent:      {models.ReferralAccepted, models.ReferralDeclined, models.ReferralClosed},
+	models.ReferralAccepted:  {models.ReferralScheduled, models.ReferralClosed},
+	models.ReferralDeclined:  {models.ReferralClosed},
+	models.ReferralScheduled: {models.ReferralClosed},
+}
+
+type ReferralService interface {
+	WithTenant(tenantID uint) ReferralService
+	CreateReferral(referral *models.Referral, documentIDs []uint) error
+	GetReferralByID(id uint) (*models.Referral, error)
+	GetPatientReferrals(patientID uint) ([]models.Referral, error)
+	GetIncomingReferrals(doctorID uint, status models.ReferralStatus) ([]models.Referral, error)
+	GetOutgoingReferrals(doctorID uint, status models.ReferralStatus) ([]models.Referral, error)
+	AcceptReferral(id, doctorID uint, note string) (*models.Referral, error)
+	DeclineReferral(id, doctorID uint, reason string) (*models.Referral, error)
+	BookAppointment(id uint, appointment *models.Appointment) (*models.Referral, error)
+	CloseReferral(id, doctorID uint, note string) (*models.Referral, error)
+}
+
+type referralService struct {
+	referralRepo       repository.ReferralRepository
+	patientRepo        repository.PatientRepository
+	userRepo           repository.UserRepository
+	documentRepo       repository.DocumentRepository
+	appointmentService AppointmentService
+}
+
+func NewReferralService(referralRepo repository.ReferralRepository, patientRepo repository.PatientRepository, userRepo repository.UserRepository, documentRepo repository.DocumentRepository, appointmentService AppointmentService) ReferralService {
+	return &referralService{
+		referralRepo:       referralRepo,
+		patientRepo:        patientRepo,
+		userRepo:           userRepo,
+		documentRepo:       documentRepo,
+		appointmentService: appointmentService,
+	}
+}
+
+// WithTenant returns a service limited to tenantID's patients and staff.
+func (s *referralService) WithTenant(tenantID uint) ReferralService {
+	return &referralService{
+		referralRepo:       s.referralRepo.WithTenant(tenantID),
+		patientRepo:        s.patientRepo.WithTenant(tenantID),
+		userRepo:           s.userRepo.WithTenant(tenantID),
+		documentRepo:       s.documentRepo.WithTenant(tenantID),
+		appointmentService: s.appointmentService.WithTenant(tenantID),
+	}
+}
+
+// CreateReferral sends a referral to a colleague or to a specialty. The
+// documents must already be attached to the patient.
+func (s *referralService) CreateReferral(referral *models.Referral, documentIDs []uint) error {
+	if _, err := s.patientRepo.FindByID(referral.PatientID); err != nil {
+		return errors.New("patient not found")
+	}
+	referral.Reason = strings.TrimSpace(referral.Reason)
+	if referral.Reason == "" {
+		return errors.New("reason is required")
+	}
+	referral.Specialty = normalizeSpecialty(referral.Specialty)
+
+	if referral.ReceivingDoctorID != nil {
+		receiver, err := s.userRepo.FindByID(*referral.ReceivingDoctorID)
+		if err != nil || receiver.Role != models.RoleDoctor {
+			return errors.New("receiving doctor not found")
+		}
+		if *referral.ReceivingDoctorID == referral.ReferringDoctorID {
+			return errors.New("cannot refer a patient to yourself")
+		}
+		if referral.Specialty == "" {
+			referral.Specialty = normalizeSpecialty(receiver.Specialty)
+		}
+	} else if referral.Specialty == "" {
+		return errors.New("a receiving doctor or specialty is required")
+	}
+
+	if referral.Urgency == "" {
+		referral.Urgency = models.ReferralRoutine
+	}
+	switch referral.Urgency {
+	case models.ReferralRoutine, models.ReferralUrgent, models.ReferralEmergent:
+	default:
+		return errors.New("urgency must be routine, urgent or emergent")
+	}
+
+	referral.Attachments = nil
+	seen := make(map[uint]bool)
+	for _, documentID := range documentIDs {
+		if seen[documentID] {
+			continue
+		}
+		seen[documentID] = true
+		document, err := s.documentRepo.FindByID(documentID)
+		if err != nil || document.PatientID != referral.PatientID {
+			return fmt.Errorf("document %d not found for this patient", documentID)
+		}
+		referral.Attachments = append(referral.Attachments, models.ReferralAttachment{DocumentID: documentID})
+	}
+
+	referral.Status = models.ReferralSent
+	if err := s.referralRepo.Create(referral); err != nil {
+		return err
+	}
+	return s.reload(referral)
+}
+
+func (s *referralService) GetReferralByID(id uint) (*models.Referral, error) {
+	referral, err := s.referralRepo.FindByID(id)
+	if err != nil {
+		return nil, ErrReferralNotFound
+	}
+	return referral, nil
+}
+
+func (s *referralService) GetPatientReferrals(patientID uint) ([]models.Referral, error) {
+	if _, err := s.patientRepo.FindByID(patientID); err != nil {
+		return nil, errors.New("patient not found")
+	}
+	return s.referralRepo.FindByPatientID(patientID)
+}
+
+// GetIncomingReferrals returns the doctor's referral inbox: referrals
+// addressed to them and unclaimed referrals to their specialty.
+func (s *referralService) GetIncomingReferrals(doctorID uint, status models.ReferralStatus) ([]models.Referral, error) {
+	doctor, err := s.userRepo.FindByID(doctorID)
+	if err != nil {
+		return nil, errors.New("doctor not found")
+	}
+	return s.referralRepo.FindIncoming(doctorID, normalizeSpecialty(doctor.Specialty), status)
+}
+
+func (s *referralService) GetOutgoingReferrals(doctorID uint, status models.ReferralStatus) ([]models.Referral, error) {
+	return s.referralRepo.FindOutgoing(doctorID, status)
+}
+
+// AcceptReferral takes the referral on. A referral sent to a specialty is
+// claimed by the first doctor of that specialty to accept it.
+func (s *referralService) AcceptReferral(id, doctorID uint, note string) (*models.Referral, error) {
+	referral, err := s.receivable(id, doctorID)
+	if err != nil {
+		return nil, err
+	}
+	if err := transitionReferral(referral, models.ReferralAccepted); err != nil {
+		return nil, err
+	}
+	referral.ReceivingDoctorID = &doctorID
+	s.respond(referral, note)
+	return referral, s.save(referral)
+}
+
+func (s *referralService) DeclineReferral(id, doctorID uint, reason string) (*models.Referral, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, errors.New("a reason is required to decline a referral")
+	}
+	referral, err := s.receivable(id, doctorID)
+	if err != nil {
+		return nil, err
+	}
+	if err := transitionReferral(referral, models.ReferralDeclined); err != nil {
+		return nil, err
+	}
+	if referral.ReceivingDoctorID == nil {
+		referral.ReceivingDoctorID = &doctorID
+	}
+	s.respond(referral, reason)
+	return referral, s.save(referral)
+}
+
+// BookAppointment books the visit for an accepted referral with the
+// receiving doctor through AppointmentService, so the usual availability,
+// location and resource checks apply.
+func (s *referralService) BookAppointment(id uint, appointment *models.Appointment) (*models.Referral, error) {
+	referral, err := s.GetReferralByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if referral.Status != models.ReferralAccepted {
+		return nil, fmt.Errorf("%w: only accepted referrals can be booked", ErrReferralState)
+	}
+
+	appointment.PatientID = referral.PatientID
+	appointment.DoctorID = *referral.ReceivingDoctorID
+	appointment.Status = models.StatusScheduled
+	if strings.TrimSpace(appointment.Notes) == "" {
+		appointment.Notes = "Referral: " + referral.Reason
+	}
+	if err := s.appointmentService.CreateAppointment(appointment); err != nil {
+		return nil, err
+	}
+
+	if err := transitionReferral(referral, models.ReferralScheduled); err != nil {
+		return nil, err
+	}
+	referral.AppointmentID = &appointment.ID
+	return referral, s.save(referral)
+}
+
+// CloseReferral ends the referral. The referring doctor can withdraw it at
+// any point; the receiving doctor closes it once the patient has been seen.
+func (s *referralService) CloseReferral(id, doctorID uint, note string) (*models.Referral, error) {
+	referral, err := s.GetReferralByID(id)
+	if err != nil {
+		return nil, err
+	}
+	isSender := referral.ReferringDoctorID == doctorID
+	isReceiver := referral.ReceivingDoctorID != nil && *referral.ReceivingDoctorID == doctorID
+	if !isSender && !isReceiver {
+		return nil, ErrNotReferralParty
+	}
+	if err := transitionReferral(referral, models.ReferralClosed); err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	referral.ClosedAt = &now
+	if note = strings.TrimSpace(note); note != "" {
+		referral.ResponseNote = note
+	}
+	return referral, s.save(referral)
+}
+
+// receivable loads a referral that doctorID may respond to: one addressed
+// to them, or an unclaimed one addressed to their specialty.
+func (s *referralService) receivable(id, doctorID uint) (*models.Referral, error) {
+	referral, err := s.GetReferralByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if referral.ReceivingDoctorID != nil {
+		if *referral.ReceivingDoctorID != doctorID {
+			return nil, ErrNotReferralReceiver
+		}
+		return referral, nil
+	}
+	doctor, err := s.userRepo.FindByID(doctorID)
+	if err != nil || doctorID == referral.ReferringDoctorID || normalizeSpecialty(doctor.Specialty) != referral.Specialty {
+		return nil, ErrNotReferralReceiver
+	}
+	return referral, nil
+}
+
+func (s *referralService) respond(referral *models.Referral, note string) {
+	now := time.Now()
+	referral.RespondedAt = &now
+	referral.ResponseNote = strings.TrimSpace(note)
+}
+
+// save writes the referral and reloads it so the response carries the
+// receiving doctor and appointment.
+func (s *referralService) save(referral *models.Referral) error {
+	if err := s.referralRepo.Update(referral); err != nil {
+		return err
+	}
+	return s.reload(referral)
+}
+
+func (s *referralService) reload(referral *models.Referral) error {
+	loaded, err := s.referralRepo.FindByID(referral.ID)
+	if err != nil {
+		return err
+	}
+	*referral = *loaded
+	return nil
+}
+
+func transitionReferral(referral *models.Referral, to models.ReferralStatus) error {
+	for _, allowed := range referralTransitions[referral.Status] {
+		if allowed == to {
+			referral.Status = to
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: cannot move a %s referral to %s", ErrReferralState, referral.Status, to)
+}
@@ -0,0 +1,159 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"healthcare-portal/internal/models"
+	"healthcare-portal/internal/repository"
+)
+
+var (
+	ErrRelatedPersonNotFound = errors.New("related person not found")
+	ErrGuardianRequired      = errors.New("patients under 18 must have at least one guardian")
+)
+
+type RelatedPersonService interface {
+	WithTenant(tenantID uint) RelatedPersonService
+	CreateRelatedPerson(person *models.RelatedPerson) error
+	GetRelatedPersonByID(patientID, id uint) (*models.RelatedPerson, error)
+	GetPatientRelatedPersons(patientID uint) ([]models.RelatedPerson, error)
+	UpdateRelatedPerson(person *models.RelatedPerson) error
+	DeleteRelatedPerson(patientID, id uint) error
+}
+
+type relatedPersonService struct {
+	relatedPersonRepo repository.RelatedPersonRepository
+	patientRepo       repository.PatientRepository
+}
+
+func NewRelatedPersonService(relatedPersonRepo repository.RelatedPersonRepository, patientRepo repository.PatientRepository) RelatedPersonService {
+	return &relatedPersonService{relatedPersonRepo: relatedPersonRepo, patientRepo: patientRepo}
+}
+
+// WithTenant returns a service that only sees tenantID's related persons.
+func (s *relatedPersonService) WithTenant(tenantID uint) RelatedPersonService {
+	return &relatedPersonService{
+		relatedPersonRepo: s.relatedPersonRepo.WithTenant(tenantID),
+		patientRepo:       s.patientRepo.WithTenant(tenantID),
+	}
+}
+
+func (s *relatedPersonService) CreateRelatedPerson(person *models.RelatedPerson) error {
+	if _, err := s.patientRepo.FindByID(person.PatientID); err != nil {
+		return ErrPatientNotFound
+	}
+	if err := normalizeRelatedPerson(person); err != nil {
+		return err
+	}
+	return s.relatedPersonRepo.Create(person)
+}
+
+func (s *relatedPersonService) GetRelatedPersonByID(patientID, id uint) (*models.RelatedPerson, error) {
+	person, err := s.relatedPersonRepo.FindByID(id)
+	if err != nil || person.PatientID != patientID {
+		return nil, ErrRelatedPersonNotFound
+	}
+	return person, nil
+}
+
+func (s *relatedPersonService) GetPatientRelatedPersons(patientID uint) ([]models.RelatedPerson, error) {
+	if _, err := s.patientRepo.FindByID(patientID); err != nil {
+		return nil, ErrPatientNotFound
+	}
+	return s.relatedPersonRepo.FindByPatientID(patientID)
+}
+
+// UpdateRelatedPerson saves changes to a contact. A minor's last guardian
+// cannot stop being a guardian.
+func (s *relatedPersonService) UpdateRelatedPerson(person *models.RelatedPerson) error {
+	existing, err := s.GetRelatedPersonByID(person.PatientID, person.ID)
+	if err != nil {
+		return err
+	}
+	if err := normalizeRelatedPerson(person); err != nil {
+		return err
+	}
+	if existing.IsGuardian && !person.IsGuardian {
+		if err := s.ensureGuardianRemains(person.PatientID); err != nil {
+			return err
+		}
+	}
+	person.TenantID = existing.TenantID
+	person.CreatedBy = existing.CreatedBy
+	person.CreatedAt = existing.CreatedAt
+	return s.relatedPersonRepo.Update(person)
+}
+
+// DeleteRelatedPerson removes a contact. A minor's last guardian cannot be
+// removed.
+func (s *relatedPersonService) DeleteRelatedPerson(patientID, id uint) error {
+	person, err := s.GetRelatedPersonByID(patientID, id)
+	if err != nil {
+		return err
+	}
+	if person.IsGuardian {
+		if err := s.ensureGuardianRemains(patientID); err != nil {
+			return err
+		}
+	}
+	return s.relatedPersonRepo.Delete(id)
+}
+
+// ensureGuardianRemains fails when the patient is a minor and one guardian
+// is about to go, but they only have one.
+func (s *relatedPersonService) ensureGuardianRemains(patientID uint) error {
+	patient, err := s.patientRepo.FindByID(patientID)
+	if err != nil {
+		return ErrPatientNotFound
+	}
+	if !patient.IsMinorOn(time.Now()) {
+		return nil
+	}
+	guardians, err := s.relatedPersonRepo.CountGuardians(patientID)
+	if err != nil {
+		return err
+	}
+	if guardians <= 1 {
+		return ErrGuardianRequired
+	}
+	return nil
+}
+
+// normalizeRelatedPerson trims the contact's fields and checks it can be
+// reached. Legal guardians are always guardians.
+func normalizeRelatedPerson(person *models.RelatedPerson) error {
+	person.Name = strings.TrimSpace(person.Name)
+	person.Relationship = models.Relationship(strings.ToLower(strings.TrimSpace(string(person.Relationship))))
+	person.MobilePhone = strings.TrimSpace(person.MobilePhone)
+	person.HomePhone = strings.TrimSpace(person.HomePhone)
+	person.WorkPhone = strings.TrimSpace(person.WorkPhone)
+	person.Email = strings.TrimSpace(person.Email)
+	person.Address = strings.TrimSpace(person.Address)
+	person.Notes = strings.TrimSpace(person.Notes)
+
+	if person.Name == "" {
+		return errors.New("related person name is required")
+	}
+	if !person.Relationship.IsValid() {
+		return errors.New("invalid relationship")
+	}
+	if person.MobilePhone == "" && person.HomePhone == "" && person.WorkPhone == "" {
+		return errors.New("related person needs at least one phone number")
+	}
+	if person.Relationship == models.RelationshipLegalGuardian {
+		person.IsGuardian = true
+	}
+	return nil
+}
+
+// hasGuardian reports whether any of persons is a guardian.
+func hasGuardian(persons []models.RelatedPerson) bool {
+	for _, person := range persons {
+		if person.IsGuardian {
+			return true
+		}
+	}
+	return false
+}
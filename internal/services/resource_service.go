@@ -0,0 +1,95 @@
+package services
+
+import (
+	"errors"
+	"strings"
+
+	"healthcare-portal/internal/models"
+	"healthcare-portal/internal/repository"
+)
+
+type ResourceService interface {
+	WithTenant(tenantID uint) ResourceService
+	CreateResource(resource *models.Resource, capabilities []string) error
+	GetResourceByID(id uint) (*models.Resource, error)
+	GetAllResources() ([]models.Resource, error)
+	UpdateResource(resource *models.Resource, capabilities []string) error
+	DeleteResource(id uint) error
+}
+
+type resourceService struct {
+	resourceRepo repository.ResourceRepository
+}
+
+func NewResourceService(resourceRepo repository.ResourceRepository) ResourceService {
+	return &resourceService{resourceRepo: resourceRepo}
+}
+
+// WithTenant returns a service that only sees tenantID's resources.
+func (s *resourceService) WithTenant(tenantID uint) ResourceService {
+	return &resourceService{resourceRepo: s.resourceRepo.WithTenant(tenantID)}
+}
+
+func (s *resourceService) CreateResource(resource *models.Resource, capabilities []string) error {
+	if err := validateResourceKind(resource.Kind); err != nil {
+		return err
+	}
+
+	resource.Capabilities = nil
+	for _, capability := range normalizeCapabilities(capabilities) {
+		resource.Capabilities = append(resource.Capabilities, models.ResourceCapability{Capability: capability})
+	}
+
+	return s.resourceRepo.Create(resource)
+}
+
+func (s *resourceService) GetResourceByID(id uint) (*models.Resource, error) {
+	return s.resourceRepo.FindByID(id)
+}
+
+func (s *resourceService) GetAllResources() ([]models.Resource, error) {
+	return s.resourceRepo.FindAll()
+}
+
+// UpdateResource saves the resource and, when capabilities is non-nil,
+// replaces its capability list.
+func (s *resourceService) UpdateResource(resource *models.Resource, capabilities []string) error {
+	if err := validateResourceKind(resource.Kind); err != nil {
+		return err
+	}
+
+	if err := s.resourceRepo.Update(resource); err != nil {
+		return err
+	}
+	if capabilities == nil {
+		return nil
+	}
+	return s.resourceRepo.ReplaceCapabilities(resource.ID, normalizeCapabilities(capabilities))
+}
+
+func (s *resourceService) DeleteResource(id uint) error {
+	return s.resourceRepo.Delete(id)
+}
+
+func validateResourceKind(kind models.ResourceKind) error {
+	if kind != models.ResourceKindRoom && kind != models.ResourceKindEquipment {
+		return errors.New("invalid resource kind")
+	}
+	return nil
+}
+
+// normalizeCapabilities lower-cases, trims and de-duplicates capability names
+// so that "Ultrasound" and "ultrasound " match the same requirement.
+func normalizeCapabilities(capabilities []string) []string {
+	seen := make(map[string]bool)
+	normalized := make([]string, 0, len(capabilities))
+	for _, capability := range capabilities {
+		capability = strings.ToLower(strings.TrimSpace(capability))
+		if capability == "" || seen[capability] {
+			continue
+		}
+		seen[capability] = true
+		normalized = append(normalized, capability)
+	}
+	return normalized
+}
@@ -0,0 +1,48 @@
+package services
+
+import (
+	"errors"
+	"strings"
+
+	"healthcare-portal/internal/models"
+	"healthcare-portal/internal/repository"
+)
+
+type TenantService interface {
+	GetTenantByID(id uint) (*models.Tenant, error)
+	ResolveTenant(slug string) (*models.Tenant, error)
+}
+
+type tenantService struct {
+	tenantRepo repository.TenantRepository
+}
+
+func NewTenantService(tenantRepo repository.TenantRepository) TenantService {
+	return &tenantService{tenantRepo: tenantRepo}
+}
+
+func (s *tenantService) GetTenantByID(id uint) (*models.Tenant, error) {
+	return s.tenantRepo.FindByID(id)
+}
+
+// ResolveTenant finds an active tenant by slug. An empty slug resolves to the
+// default tenant so single-practice deployments need no extra input.
+func (s *tenantService) ResolveTenant(slug string) (*models.Tenant, error) {
+	var (
+		tenant *models.Tenant
+		err    error
+	)
+	slug = strings.ToLower(strings.TrimSpace(slug))
+	if slug == "" {
+		tenant, err = s.tenantRepo.FindByID(models.DefaultTenantID)
+	} else {
+		tenant, err = s.tenantRepo.FindBySlug(slug)
+	}
+	if err != nil {
+		return nil, errors.New("tenant not found")
+	}
+	if !tenant.IsActive {
+		return nil, errors.New("tenant is not active")
+	}
+	return tenant, nil
+}
@@ -0,0 +1,283 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"healthcare-portal/internal/models"
+	"healthcare-portal/internal/repository"
+)
+
+// VitalsReading is a set of measurements as entered, with the units they
+// were taken in. Empty units default to metric.
+type VitalsReading struct {
+	RecordedAt       *time.Time
+	SystolicBP       *int
+	DiastolicBP      *int
+	HeartRate        *int
+	RespiratoryRate  *int
+	Temperature      *float64
+	TemperatureUnit  string // "C" or "F"
+	OxygenSaturation *int
+	Weight           *float64
+	WeightUnit       string // "kg" or "lb"
+	Height           *float64
+	HeightUnit       string // "cm" or "in"
+	Notes            string
+}
+
+type VitalsService interface {
+	WithTenant(tenantID uint) VitalsService
+	RecordVitals(patientID, recordedBy uint, reading VitalsReading) (*models.VitalSigns, error)
+	RecordVisitVitals(appointmentID, recordedBy uint, reading VitalsReading) (*models.VitalSigns, error)
+	GetVitalsByID(id uint) (*models.VitalSigns, error)
+	GetAppointmentVitals(appointmentID uint) ([]models.VitalSigns, error)
+	GetPatientVitals(patientID uint, from, to *time.Time) ([]models.VitalSigns, error)
+	GetVitalsSeries(patientID uint, measurement string, from, to *time.Time) ([]models.VitalPoint, error)
+	DeleteVitals(id uint) error
+}
+
+type vitalsService struct {
+	vitalsRepo      repository.VitalsRepository
+	patientRepo     repository.PatientRepository
+	appointmentRepo repository.AppointmentRepository
+}
+
+func NewVitalsService(vitalsRepo repository.VitalsRepository, patientRepo repository.PatientRepository, appointmentRepo repository.AppointmentRepository) VitalsService {
+	return &vitalsService{
+		vitalsRepo:      vitalsRepo,
+		patientRepo:     patientRepo,
+		appointmentRepo: appointmentRepo,
+	}
+}
+
+// WithTenant returns a service limited to tenantID's patients.
+func (s *vitalsService) WithTenant(tenantID uint) VitalsService {
+	return &vitalsService{
+		vitalsRepo:      s.vitalsRepo.WithTenant(tenantID),
+		patientRepo:     s.patientRepo.WithTenant(tenantID),
+		appointmentRepo: s.appointmentRepo.WithTenant(tenantID),
+	}
+}
+
+// RecordVitals stores measurements taken outside a visit.
+func (s *vitalsService) RecordVitals(patientID, recordedBy uint, reading VitalsReading) (*models.VitalSigns, error) {
+	if _, err := s.patientRepo.FindByID(patientID); err != nil {
+		return nil, errors.New("patient not found")
+	}
+	return s.record(patientID, nil, recordedBy, reading)
+}
+
+// RecordVisitVitals stores measurements taken for an appointment. A
+// scheduled appointment is checked in, so vitals taken at arrival show the
+// patient as ready for the doctor.
+func (s *vitalsService) RecordVisitVitals(appointmentID, recordedBy uint, reading VitalsReading) (*models.VitalSigns, error) {
+	appointment, err := s.appointmentRepo.FindByID(appointmentID)
+	if err != nil {
+		return nil, errors.New("appointment not found")
+	}
+	if appointment.Status == models.StatusCancelled {
+		return nil, errors.New("cannot record vitals for a cancelled appointment")
+	}
+	if appointment.Status == models.StatusScheduled {
+		if err := checkIn(s.appointmentRepo, appointment); err != nil {
+			return nil, err
+		}
+	}
+	return s.record(appointment.PatientID, &appointment.ID, recordedBy, reading)
+}
+
+func (s *vitalsService) record(patientID uint, appointmentID *uint, recordedBy uint, reading VitalsReading) (*models.VitalSigns, error) {
+	vitals, err := reading.toVitals()
+	if err != nil {
+		return nil, err
+	}
+	vitals.PatientID = patientID
+	vitals.AppointmentID = appointmentID
+	vitals.RecordedBy = recordedBy
+
+	// Adults are rarely re-measured for height, so BMI falls back to the
+	// last height on file.
+	if vitals.WeightKg != nil && vitals.HeightCm == nil {
+		height, err := s.vitalsRepo.FindLatestHeight(patientID)
+		if err != nil {
+			return nil, err
+		}
+		if height != nil {
+			vitals.HeightCm = height
+			vitals.ComputeBMI()
+			vitals.HeightCm = nil
+		}
+	}
+
+	if err := s.vitalsRepo.Create(vitals); err != nil {
+		return nil, err
+	}
+	vitals.Evaluate()
+	return vitals, nil
+}
+
+func (s *vitalsService) GetVitalsByID(id uint) (*models.VitalSigns, error) {
+	return s.vitalsRepo.FindByID(id)
+}
+
+func (s *vitalsService) GetAppointmentVitals(appointmentID uint) ([]models.VitalSigns, error) {
+	if _, err := s.appointmentRepo.FindByID(appointmentID); err != nil {
+		return nil, errors.New("appointment not found")
+	}
+	return s.vitalsRepo.FindByAppointmentID(appointmentID)
+}
+
+func (s *vitalsService) GetPatientVitals(patientID uint, from, to *time.Time) ([]models.VitalSigns, error) {
+	if _, err := s.patientRepo.FindByID(patientID); err != nil {
+		return nil, errors.New("patient not found")
+	}
+	return s.vitalsRepo.FindByPatientID(patientID, from, to)
+}
+
+// GetVitalsSeries returns one measurement over time, skipping readings where
+// it was not taken.
+func (s *vitalsService) GetVitalsSeries(patientID uint, measurement string, from, to *time.Time) ([]models.VitalPoint, error) {
+	limits, ok := models.VitalRanges[measurement]
+	if !ok {
+		return nil, fmt.Errorf("unknown measurement %q", measurement)
+	}
+
+	vitals, err := s.GetPatientVitals(patientID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	points := []models.VitalPoint{}
+	for _, v := range vitals {
+		value, ok := v.Value(measurement)
+		if !ok {
+			continue
+		}
+		level, _ := limits.Classify(value)
+		points = append(points, models.VitalPoint{
+			VitalSignsID: v.ID,
+			RecordedAt:   v.RecordedAt,
+			Value:        value,
+			Unit:         limits.Unit,
+			Flag:         level,
+		})
+	}
+	return points, nil
+}
+
+// DeleteVitals removes a reading entered in error.
+func (s *vitalsService) DeleteVitals(id uint) error {
+	return s.vitalsRepo.Delete(id)
+}
+
+// toVitals converts the reading to metric units and checks that every value
+// is physiologically plausible.
+func (r VitalsReading) toVitals() (*models.VitalSigns, error) {
+	vitals := &models.VitalSigns{
+		SystolicBP:       r.SystolicBP,
+		DiastolicBP:      r.DiastolicBP,
+		HeartRate:        r.HeartRate,
+		RespiratoryRate:  r.RespiratoryRate,
+		OxygenSaturation: r.OxygenSaturation,
+		Notes:            strings.TrimSpace(r.Notes),
+		RecordedAt:       time.Now(),
+	}
+	if r.RecordedAt != nil {
+		if r.RecordedAt.After(time.Now().Add(5 * time.Minute)) {
+			return nil, errors.New("recorded_at cannot be in the future")
+		}
+		vitals.RecordedAt = *r.RecordedAt
+	}
+
+	var err error
+	if vitals.TemperatureC, err = convertUnit(r.Temperature, r.TemperatureUnit, "c", "temperature", map[string]func(float64) float64{
+		"c": func(v float64) float64 { return v },
+		"f": func(v float64) float64 { return (v - 32) * 5 / 9 },
+	}); err != nil {
+		return nil, err
+	}
+	if vitals.WeightKg, err = convertUnit(r.Weight, r.WeightUnit, "kg", "weight", map[string]func(float64) float64{
+		"kg": func(v float64) float64 { return v },
+		"lb": func(v float64) float64 { return v * 0.45359237 },
+	}); err != nil {
+		return nil, err
+	}
+	if vitals.HeightCm, err = convertUnit(r.Height, r.HeightUnit, "cm", "height", map[string]func(float64) float64{
+		"cm": func(v float64) float64 { return v },
+		"in": func(v float64) float64 { return v * 2.54 },
+	}); err != nil {
+		return nil, err
+	}
+
+	if vitals.SystolicBP == nil && vitals.DiastolicBP == nil && vitals.HeartRate == nil &&
+		vitals.RespiratoryRate == nil && vitals.TemperatureC == nil && vitals.OxygenSaturation == nil &&
+		vitals.WeightKg == nil && vitals.HeightCm == nil {
+		return nil, errors.New("at least one measurement is required")
+	}
+	if (vitals.SystolicBP == nil) != (vitals.DiastolicBP == nil) {
+		return nil, errors.New("blood pressure needs both systolic and diastolic values")
+	}
+	if vitals.SystolicBP != nil && *vitals.DiastolicBP >= *vitals.SystolicBP {
+		return nil, errors.New("diastolic pressure must be lower than systolic")
+	}
+
+	checks := []struct {
+		name     string
+		value    float64
+		set      bool
+		min, max float64
+	}{
+		{"systolic_bp", intOrZero(vitals.SystolicBP), vitals.SystolicBP != nil, 40, 300},
+		{"diastolic_bp", intOrZero(vitals.DiastolicBP), vitals.DiastolicBP != nil, 20, 200},
+		{"heart_rate", intOrZero(vitals.HeartRate), vitals.HeartRate != nil, 20, 300},
+		{"respiratory_rate", intOrZero(vitals.RespiratoryRate), vitals.RespiratoryRate != nil, 2, 80},
+		{"temperature", floatOrZero(vitals.TemperatureC), vitals.TemperatureC != nil, 25, 45},
+		{"oxygen_saturation", intOrZero(vitals.OxygenSaturation), vitals.OxygenSaturation != nil, 50, 100},
+		{"weight", floatOrZero(vitals.WeightKg), vitals.WeightKg != nil, 0.2, 500},
+		{"height", floatOrZero(vitals.HeightCm), vitals.HeightCm != nil, 20, 275},
+	}
+	for _, check := range checks {
+		if check.set && (check.value < check.min || check.value > check.max) {
+			return nil, fmt.Errorf("%s is out of the plausible range", check.name)
+		}
+	}
+
+	vitals.ComputeBMI()
+	return vitals, nil
+}
+
+// convertUnit converts an optional value to the stored unit, rounded to one
+// decimal. An empty unit means the value is already in defaultUnit.
+func convertUnit(value *float64, unit, defaultUnit, name string, conversions map[string]func(float64) float64) (*float64, error) {
+	if value == nil {
+		return nil, nil
+	}
+	unit = strings.ToLower(strings.TrimSpace(unit))
+	if unit == "" {
+		unit = defaultUnit
+	}
+	convert, ok := conversions[unit]
+	if !ok {
+		return nil, fmt.Errorf("unsupported %s unit %q", name, unit)
+	}
+	converted := math.Round(convert(*value)*10) / 10
+	return &converted, nil
+}
+
+func intOrZero(p *int) float64 {
+	if p == nil {
+		return 0
+	}
+	return float64(*p)
+}
+
+func floatOrZero(p *float64) float64 {
+	if p == nil {
+		return 0
+	}
+	return *p
+}
@@ -0,0 +1,37 @@
+// Package storage holds the blob stores that keep uploaded file content
+// outside the database.
+package storage
+
+import (
+	"errors"
+	"io"
+	"path"
+	"strings"
+)
+
+// ErrNotFound is returned by Get and Delete when no blob exists for a key.
+var ErrNotFound = errors.New("blob not found")
+
+// BlobStore keeps opaque file content by key. Keys follow S3 object key
+// rules ("patients/12/3f9c..."), so an S3-compatible bucket can back the
+// store as well as the local filesystem.
+type BlobStore interface {
+	// Put stores content under key, replacing any existing blob. If reading
+	// content fails, nothing is left behind under key.
+	Put(key string, content io.Reader, contentType string) error
+	// Get opens the blob for reading. The caller closes it.
+	Get(key string) (io.ReadCloser, error)
+	Delete(key string) error
+}
+
+// ValidateKey rejects keys that are empty, absolute or that climb out of the
+// store with "..".
+func ValidateKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return errors.New("invalid blob key")
+	}
+	if path.Clean(key) != key || key == "." || strings.HasPrefix(key, "../") || key == ".." {
+		return errors.New("invalid blob key")
+	}
+	return nil
+}
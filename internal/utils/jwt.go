@@ -9,9 +9,10 @@ import (
 )
 
 type Claims struct {
-    UserID uint   `json:"user_id"`
-    Email  string `json:"email"`
-    Role   string `json:"role"`
+    UserID   uint   `json:"user_id"`
+    Email    string `json:"email"`
+    Role     string `json:"role"`
+    TenantID uint   `json:"tenant_id"`
     jwt.RegisteredClaims
 }
 
@@ -21,11 +22,12 @@ type PasswordResetClaims struct {
     jwt.RegisteredClaims
 }
 
-func GenerateJWT(userID uint, email string, role string) (string, error) {
+func GenerateJWT(userID uint, email string, role string, tenantID uint) (string, error) {
     claims := &Claims{
-        UserID: userID,
-        Email:  email,
-        Role:   role,
+        UserID:   userID,
+        Email:    email,
+        Role:     role,
+        TenantID: tenantID,
         RegisteredClaims: jwt.RegisteredClaims{
             ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)),
             IssuedAt:  jwt.NewNumericDate(time.Now()),
@@ -0,0 +1,192 @@
+// Package x12 reads and writes ASC X12 interchanges, the envelope format used
+// for electronic claims (837) and remittance advice (835). It deals only with
+// segments and delimiters; the meaning of each transaction is left to the
+// caller.
+package x12
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrNotInterchange is returned by Parse when the data does not begin with a
+// complete ISA segment.
+var ErrNotInterchange = errors.New("x12: data does not start with an ISA interchange header")
+
+// isaLength is the fixed length of an ISA segment, including its terminator.
+const isaLength = 106
+
+// Delimiters are the separators of an interchange. Parse reads them from the
+// ISA header; Writer uses them as given.
+type Delimiters struct {
+	Element    byte
+	Component  byte
+	Repetition byte
+	Segment    byte
+}
+
+// DefaultDelimiters are the separators most clearinghouses expect.
+var DefaultDelimiters = Delimiters{Element: '*', Component: ':', Repetition: '^', Segment: '~'}
+
+// Segment is one parsed segment. Segment[0] is the segment ID and the
+// elements follow, so positions match the X12 reference numbers (NM103 is
+// Segment[3]).
+type Segment []string
+
+// ID returns the segment identifier, e.g. "CLP".
+func (s Segment) ID() string {
+	if len(s) == 0 {
+		return ""
+	}
+	return s[0]
+}
+
+// Element returns the element at position i, or "" when the segment is
+// shorter.
+func (s Segment) Element(i int) string {
+	if i <= 0 || i >= len(s) {
+		return ""
+	}
+	return s[i]
+}
+
+// Component returns component j (1-based) of the composite element at
+// position i.
+func (s Segment) Component(i, j int, d Delimiters) string {
+	parts := strings.Split(s.Element(i), string(d.Component))
+	if j <= 0 || j > len(parts) {
+		return ""
+	}
+	return parts[j-1]
+}
+
+// Parse splits an interchange into segments using the delimiters declared
+// in its ISA header. Line breaks between segments are ignored.
+func Parse(data []byte) ([]Segment, Delimiters, error) {
+	text := strings.TrimLeft(string(data), " \t\r\n\ufeff")
+	if len(text) < isaLength || !strings.HasPrefix(text, "ISA") {
+		return nil, Delimiters{}, ErrNotInterchange
+	}
+	d := Delimiters{
+		Element:    text[3],
+		Repetition: text[82],
+		Component:  text[104],
+		Segment:    text[105],
+	}
+	if strings.Count(text[:isaLength], string(d.Element)) != 16 {
+		return nil, Delimiters{}, ErrNotInterchange
+	}
+
+	var segments []Segment
+	for _, raw := range strings.Split(text, string(d.Segment)) {
+		raw = strings.Trim(raw, "\r\n")
+		if strings.TrimSpace(raw) == "" {
+			continue
+		}
+		segments = append(segments, Segment(strings.Split(raw, string(d.Element))))
+	}
+	if last := segments[len(segments)-1]; last.ID() != "IEA" {
+		return nil, Delimiters{}, fmt.Errorf("x12: interchange ends with %s instead of IEA", last.ID())
+	}
+	return segments, d, nil
+}
+
+// Composite is an element made of components, such as the procedure code
+// "HC:99213" in SV101.
+type Composite []string
+
+// Writer builds an interchange one segment at a time.
+type Writer struct {
+	d        Delimiters
+	b        strings.Builder
+	segments int
+}
+
+func NewWriter(d Delimiters) *Writer {
+	return &Writer{d: d}
+}
+
+// Segment appends a segment. Elements are strings or Composites; anything
+// else is formatted with fmt.Sprint. Delimiter characters inside values are
+// replaced with spaces, and trailing empty elements are dropped as the
+// standard requires.
+func (w *Writer) Segment(id string, elements ...interface{}) {
+	values := make([]string, len(elements))
+	for i, element := range elements {
+		switch v := element.(type) {
+		case Composite:
+			parts := make([]string, len(v))
+			for j, part := range v {
+				parts[j] = w.clean(part)
+			}
+			values[i] = strings.TrimRight(strings.Join(parts, string(w.d.Component)), string(w.d.Component))
+		case string:
+			values[i] = w.clean(v)
+		default:
+			values[i] = w.clean(fmt.Sprint(v))
+		}
+	}
+	for len(values) > 0 && values[len(values)-1] == "" {
+		values = values[:len(values)-1]
+	}
+
+	w.b.WriteString(id)
+	for _, value := range values {
+		w.b.WriteByte(w.d.Element)
+		w.b.WriteString(value)
+	}
+	w.b.WriteByte(w.d.Segment)
+	w.b.WriteByte('\n')
+	w.segments++
+}
+
+// ISA writes the interchange header. Sender and receiver IDs use the
+// mutually defined qualifier ZZ, control is the interchange control number
+// repeated in IEA02, and usage is "P" for production or "T" for test.
+func (w *Writer) ISA(sender, receiver string, at time.Time, control int, usage string) {
+	elements := []string{
+		"00", pad("", 10), "00", pad("", 10),
+		"ZZ", pad(w.clean(sender), 15), "ZZ", pad(w.clean(receiver), 15),
+		at.Format("060102"), at.Format("1504"),
+		string(w.d.Repetition), "00501", fmt.Sprintf("%09d", control), "0", usage,
+		string(w.d.Component),
+	}
+	w.b.WriteString("ISA")
+	for _, element := range elements {
+		w.b.WriteByte(w.d.Element)
+		w.b.WriteString(element)
+	}
+	w.b.WriteByte(w.d.Segment)
+	w.b.WriteByte('\n')
+	w.segments++
+}
+
+// Segments returns how many segments have been written, for the counts in
+// SE01.
+func (w *Writer) Segments() int {
+	return w.segments
+}
+
+func (w *Writer) String() string {
+	return w.b.String()
+}
+
+func (w *Writer) clean(value string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case rune(w.d.Element), rune(w.d.Component), rune(w.d.Repetition), rune(w.d.Segment), '\r', '\n':
+			return ' '
+		}
+		return r
+	}, strings.TrimSpace(value))
+}
+
+// pad left-justifies value in a fixed-width ISA element.
+func pad(value string, width int) string {
+	if len(value) > width {
+		return value[:width]
+	}
+	return value + strings.Repeat(" ", width-len(value))
+}
@@ -0,0 +1,86 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"healthcare-portal/internal/models"
+	"healthcare-portal/internal/repository"
+	"healthcare-portal/internal/services"
+)
+
+func TestParseLegacyAllergies(t *testing.T) {
+	t.Run("No known allergies", func(t *testing.T) {
+		assert.Empty(t, services.ParseLegacyAllergies("None"))
+		assert.Empty(t, services.ParseLegacyAllergies("NKDA"))
+		assert.Empty(t, services.ParseLegacyAllergies(""))
+	})
+
+	t.Run("Lists, reactions and severity", func(t *testing.T) {
+		parsed := services.ParseLegacyAllergies("Penicillin (rash, hives); Peanuts - severe anaphylaxis, Dust and Pollen, penicillin")
+		require.Len(t, parsed, 4)
+
+		assert.Equal(t, "Penicillin", parsed[0].Substance)
+		assert.Equal(t, models.AllergyMedication, parsed[0].Category)
+		assert.Equal(t, "rash, hives", parsed[0].Reaction)
+		assert.Equal(t, models.SeverityUnknown, parsed[0].Severity)
+
+		assert.Equal(t, "Peanuts", parsed[1].Substance)
+		assert.Equal(t, models.AllergyFood, parsed[1].Category)
+		assert.Equal(t, models.SeveritySevere, parsed[1].Severity)
+
+		assert.Equal(t, "Dust", parsed[2].Substance)
+		assert.Equal(t, models.AllergyEnvironment, parsed[2].Category)
+		assert.Equal(t, "Pollen", parsed[3].Substance)
+
+		for _, allergy := range parsed {
+			assert.Equal(t, models.AllergyUnconfirmed, allergy.Verification)
+			assert.Equal(t, models.AllergySourceLegacy, allergy.Source)
+		}
+	})
+}
+
+func TestAllergyService(t *testing.T) {
+	db := setupSchedulingDB(t)
+	patient := &models.Patient{FirstName: "Bob", LastName: "Williams", Email: "bob@example.com", Phone: "555-0100", Allergies: "Penicillin, Dust"}
+	require.NoError(t, db.Create(patient).Error)
+
+	allergyService := services.NewAllergyService(repository.NewAllergyRepository(db), repository.NewPatientRepository(db)).WithTenant(1)
+
+	t.Run("Import is repeatable", func(t *testing.T) {
+		imported, err := allergyService.ImportLegacyAllergies(patient.ID, 7)
+		require.NoError(t, err)
+		require.Len(t, imported, 2)
+
+		imported, err = allergyService.ImportLegacyAllergies(patient.ID, 7)
+		require.NoError(t, err)
+		assert.Empty(t, imported)
+	})
+
+	t.Run("Confirm candidate", func(t *testing.T) {
+		allergies, err := allergyService.GetPatientAllergies(patient.ID)
+		require.NoError(t, err)
+		require.Len(t, allergies, 2)
+
+		confirmed, err := allergyService.ConfirmAllergy(allergies[0].ID, 3)
+		require.NoError(t, err)
+		assert.Equal(t, models.AllergyConfirmed, confirmed.Verification)
+		require.NotNil(t, confirmed.ConfirmedBy)
+		assert.Equal(t, uint(3), *confirmed.ConfirmedBy)
+
+		_, err = allergyService.ConfirmAllergy(allergies[0].ID, 3)
+		assert.Error(t, err)
+	})
+
+	t.Run("Create validates and rejects duplicates", func(t *testing.T) {
+		assert.Error(t, allergyService.CreateAllergy(&models.Allergy{PatientID: patient.ID, Substance: "Latex", Category: "plant"}))
+		assert.Error(t, allergyService.CreateAllergy(&models.Allergy{PatientID: patient.ID, Substance: "penicillin", Category: models.AllergyMedication}))
+
+		latex := &models.Allergy{PatientID: patient.ID, Substance: "Latex", Category: models.AllergyEnvironment, Reaction: "Contact dermatitis", RecordedBy: 3}
+		require.NoError(t, allergyService.CreateAllergy(latex))
+		assert.Equal(t, models.AllergyConfirmed, latex.Verification)
+		assert.Equal(t, models.SeverityUnknown, latex.Severity)
+	})
+}
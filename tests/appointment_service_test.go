@@ -0,0 +1,203 @@
+package tests
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"healthcare-portal/internal/config"
+	"healthcare-portal/internal/models"
+	"healthcare-portal/internal/repository"
+	"healthcare-portal/internal/services"
+)
+
+func setupSchedulingDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+
+	err = db.AutoMigrate(
+		&models.Tenant{}, &models.User{}, &models.Patient{}, &models.Appointment{},
+		&models.Resource{}, &models.ResourceCapability{}, &models.AppointmentResource{},
+		&models.AppointmentType{}, &models.AppointmentTypeRequirement{}, &models.AppointmentTypeSpecialty{},
+		&models.Location{}, &models.DoctorSchedule{},
+		&models.Encounter{}, &models.EncounterAddendum{}, &models.Allergy{},
+		&models.MedicationStatement{}, &models.Prescription{}, &models.PrescriptionWarning{},
+		&models.VitalSigns{}, &models.DiagnosisCode{}, &models.Problem{}, &models.AppointmentDiagnosis{},
+		&models.LabOrder{}, &models.LabResult{}, &models.Immunization{},
+		&models.Document{}, &models.Referral{}, &models.ReferralAttachment{},
+		&models.CarePlan{}, &models.CarePlanGoal{}, &models.CareTask{},
+		&models.PatientMerge{}, &models.PatientIdentifier{}, &models.MRNSequence{}, &models.RelatedPerson{},
+		&models.ConsentText{}, &models.Consent{}, &models.PatientSearchTerm{},
+		&models.InsurancePayer{}, &models.InsurancePlan{}, &models.Coverage{}, &models.EligibilityCheck{},
+		&models.Charge{}, &models.Invoice{}, &models.Payment{}, &models.NumberSequence{},
+		&models.Claim{}, &models.ClaimLine{}, &models.ClaimDiagnosis{}, &models.ClaimBatch{},
+		&models.Remittance{}, &models.RemittanceClaim{},
+	)
+	require.NoError(t, err)
+
+	return db
+}
+
+func newAppointmentService(db *gorm.DB) services.AppointmentService {
+	return services.NewAppointmentService(
+		repository.NewAppointmentRepository(db),
+		repository.NewPatientRepository(db),
+		repository.NewUserRepository(db),
+		repository.NewResourceRepository(db),
+		repository.NewAppointmentTypeRepository(db),
+		repository.NewLocationRepository(db),
+		repository.NewDoctorScheduleRepository(db),
+		config.SchedulingConfig{DayStart: "09:00", DayEnd: "12:00", SlotMinutes: 15},
+		newBillingService(db),
+	)
+}
+
+func createPatients(t *testing.T, db *gorm.DB, n int) {
+	for i := 1; i <= n; i++ {
+		patient := &models.Patient{FirstName: "Patient", LastName: fmt.Sprint(i), Email: fmt.Sprintf("patient%d@example.com", i), Phone: fmt.Sprintf("555-000%d", i)}
+		require.NoError(t, db.Create(patient).Error)
+	}
+}
+
+func TestAppointmentServiceResourceBooking(t *testing.T) {
+	db := setupSchedulingDB(t)
+	appointmentRepo := repository.NewAppointmentRepository(db)
+	resourceService := services.NewResourceService(repository.NewResourceRepository(db)).WithTenant(1)
+	appointmentTypeService := services.NewAppointmentTypeService(repository.NewAppointmentTypeRepository(db), appointmentRepo).WithTenant(1)
+	appointmentService := newAppointmentService(db).WithTenant(1)
+
+	createPatients(t, db, 5)
+	for _, email := range []string{"doc1@example.com", "doc2@example.com", "doc3@example.com"} {
+		require.NoError(t, db.Create(&models.User{Email: email, Password: "x", Name: email, Role: models.RoleDoctor, IsActive: true}).Error)
+	}
+
+	require.NoError(t, resourceService.CreateResource(&models.Resource{Name: "Ultrasound 1", Kind: models.ResourceKindEquipment, IsActive: true}, []string{"Ultrasound"}))
+
+	scan := &models.AppointmentType{
+		Code:            "scan",
+		Name:            "Ultrasound scan",
+		DurationMinutes: 45,
+		IsActive:        true,
+		Requirements:    []models.AppointmentTypeRequirement{{Capability: "ultrasound"}},
+	}
+	require.NoError(t, appointmentTypeService.CreateAppointmentType(scan))
+
+	date := time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)
+
+	t.Run("Allocates required resource", func(t *testing.T) {
+		appointment := &models.Appointment{PatientID: 1, DoctorID: 1, AppointmentTypeID: &scan.ID, Date: date, Time: "09:00", Status: models.StatusScheduled}
+		require.NoError(t, appointmentService.CreateAppointment(appointment))
+		assert.Len(t, appointment.Resources, 1)
+	})
+
+	t.Run("Rejects when resource is taken", func(t *testing.T) {
+		appointment := &models.Appointment{PatientID: 2, DoctorID: 2, AppointmentTypeID: &scan.ID, Date: date, Time: "09:00", Status: models.StatusScheduled}
+		assert.Error(t, appointmentService.CreateAppointment(appointment))
+	})
+
+	t.Run("Rejects double-booked patient", func(t *testing.T) {
+		appointment := &models.Appointment{PatientID: 1, DoctorID: 3, Date: date, Time: "09:00", Status: models.StatusScheduled}
+		assert.Error(t, appointmentService.CreateAppointment(appointment))
+	})
+
+	t.Run("Rejects overlap with a longer visit", func(t *testing.T) {
+		appointment := &models.Appointment{PatientID: 2, DoctorID: 2, AppointmentTypeID: &scan.ID, Date: date, Time: "09:30", Status: models.StatusScheduled}
+		assert.Error(t, appointmentService.CreateAppointment(appointment))
+	})
+
+	t.Run("Allows a slot after the visit ends", func(t *testing.T) {
+		appointment := &models.Appointment{PatientID: 2, DoctorID: 2, AppointmentTypeID: &scan.ID, Date: date, Time: "09:45", Status: models.StatusScheduled}
+		assert.NoError(t, appointmentService.CreateAppointment(appointment))
+		assert.Equal(t, 45, appointment.DurationMinutes)
+	})
+
+	t.Run("Rejects doctor outside allowed specialties", func(t *testing.T) {
+		cardiology := &models.AppointmentType{
+			Code:            "echo",
+			Name:            "Echocardiogram",
+			DurationMinutes: 30,
+			IsActive:        true,
+			Specialties:     []models.AppointmentTypeSpecialty{{Specialty: "Cardiology"}},
+		}
+		require.NoError(t, appointmentTypeService.CreateAppointmentType(cardiology))
+
+		doctor := &models.User{Email: "gp@example.com", Password: "x", Name: "Dr. GP", Role: models.RoleDoctor, Specialty: "general practice", IsActive: true}
+		require.NoError(t, db.Create(doctor).Error)
+
+		appointment := &models.Appointment{PatientID: 5, DoctorID: doctor.ID, AppointmentTypeID: &cardiology.ID, Date: date, Time: "11:00", Status: models.StatusScheduled}
+		assert.Error(t, appointmentService.CreateAppointment(appointment))
+	})
+}
+
+func TestAppointmentServiceLocations(t *testing.T) {
+	db := setupSchedulingDB(t)
+	appointmentService := newAppointmentService(db).WithTenant(1)
+	locationService := services.NewLocationService(
+		repository.NewLocationRepository(db),
+		repository.NewDoctorScheduleRepository(db),
+		repository.NewUserRepository(db),
+	).WithTenant(1)
+
+	createPatients(t, db, 2)
+	doctor := &models.User{Email: "doc@example.com", Password: "x", Name: "Dr. Who", Role: models.RoleDoctor, IsActive: true}
+	require.NoError(t, db.Create(doctor).Error)
+
+	north := &models.Location{Code: "north", Name: "North Clinic", TimeZone: "America/New_York", IsActive: true}
+	south := &models.Location{Code: "south", Name: "South Clinic", TimeZone: "America/Chicago", IsActive: true}
+	require.NoError(t, locationService.CreateLocation(north))
+	require.NoError(t, locationService.CreateLocation(south))
+
+	// 2025-03-10 is a Monday
+	require.NoError(t, locationService.CreateSchedule(&models.DoctorSchedule{
+		DoctorID: doctor.ID, LocationID: north.ID, DayOfWeek: time.Monday, StartTime: "09:00", EndTime: "10:00",
+	}))
+	assert.Error(t, locationService.CreateSchedule(&models.DoctorSchedule{
+		DoctorID: doctor.ID, LocationID: south.ID, DayOfWeek: time.Monday, StartTime: "09:30", EndTime: "11:00",
+	}))
+
+	monday := time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)
+
+	t.Run("Infers the scheduled location", func(t *testing.T) {
+		appointment := &models.Appointment{PatientID: 1, DoctorID: doctor.ID, Date: monday, Time: "09:00", Status: models.StatusScheduled}
+		require.NoError(t, appointmentService.CreateAppointment(appointment))
+		require.NotNil(t, appointment.LocationID)
+		assert.Equal(t, north.ID, *appointment.LocationID)
+	})
+
+	t.Run("Rejects a location the doctor is not scheduled at", func(t *testing.T) {
+		appointment := &models.Appointment{PatientID: 2, DoctorID: doctor.ID, LocationID: &south.ID, Date: monday, Time: "09:30", Status: models.StatusScheduled}
+		assert.Error(t, appointmentService.CreateAppointment(appointment))
+	})
+
+	t.Run("Rejects time outside the schedule", func(t *testing.T) {
+		appointment := &models.Appointment{PatientID: 2, DoctorID: doctor.ID, Date: monday, Time: "10:00", Status: models.StatusScheduled}
+		assert.Error(t, appointmentService.CreateAppointment(appointment))
+	})
+
+	t.Run("Offers only free slots inside the schedule", func(t *testing.T) {
+		slots, err := appointmentService.FindAvailableSlots(models.SlotQuery{Date: monday, LocationID: &north.ID})
+		require.NoError(t, err)
+		var times []string
+		for _, slot := range slots {
+			times = append(times, slot.Time)
+		}
+		assert.Equal(t, []string{"09:30"}, times)
+	})
+
+	t.Run("Filters appointments by location", func(t *testing.T) {
+		atNorth, err := appointmentService.GetAppointmentsByDate(monday, &north.ID)
+		require.NoError(t, err)
+		assert.Len(t, atNorth, 1)
+		require.NotNil(t, atNorth[0].StartsAt)
+		assert.Equal(t, "America/New_York", atNorth[0].StartsAt.Location().String())
+
+		atSouth, err := appointmentService.GetAppointmentsByDate(monday, &south.ID)
+		require.NoError(t, err)
+		assert.Empty(t, atSouth)
+	})
+}
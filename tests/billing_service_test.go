@@ -0,0 +1,281 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"healthcare-portal/internal/config"
+	"healthcare-portal/internal/models"
+	"healthcare-portal/internal/repository"
+	"healthcare-portal/internal/services"
+)
+
+func newBillingService(db *gorm.DB) services.BillingService {
+	return services.NewBillingService(
+		repository.NewBillingRepository(db),
+		repository.NewPatientRepository(db),
+		config.BillingConfig{PaymentTermsDays: 30},
+	)
+}
+
+func TestBillingService(t *testing.T) {
+	db := setupSchedulingDB(t)
+	createPatients(t, db, 2)
+	require.NoError(t, db.Create(&models.User{Email: "doc@example.com", Password: "x", Name: "Doc", Role: models.RoleDoctor, IsActive: true}).Error)
+	billing := newBillingService(db).WithTenant(1)
+	appointments := newAppointmentService(db).WithTenant(1)
+
+	visit := &models.AppointmentType{Code: "99213", Name: "Office visit", DurationMinutes: 15, PriceCents: 12000, IsActive: true}
+	require.NoError(t, db.Create(visit).Error)
+	free := &models.AppointmentType{Code: "FOLLOWUP", Name: "Free follow-up", DurationMinutes: 15, IsActive: true}
+	require.NoError(t, db.Create(free).Error)
+
+	appointment := &models.Appointment{PatientID: 1, DoctorID: 1, AppointmentTypeID: &visit.ID, Date: date(2026, 3, 2), Time: "09:00", Status: models.StatusScheduled}
+	require.NoError(t, db.Create(appointment).Error)
+
+	t.Run("Completing an appointment charges its type's price once", func(t *testing.T) {
+		require.NoError(t, appointments.UpdateAppointmentStatus(appointment.ID, models.StatusCompleted))
+		require.NoError(t, appointments.UpdateAppointmentStatus(appointment.ID, models.StatusCompleted))
+
+		charges, err := billing.GetPatientCharges(1, "")
+		require.NoError(t, err)
+		require.Len(t, charges, 1)
+		assert.Equal(t, int64(12000), charges[0].AmountCents)
+		assert.Equal(t, "99213", charges[0].Code)
+		assert.Equal(t, models.ChargePending, charges[0].Status)
+		require.NotNil(t, charges[0].AppointmentID)
+		assert.Equal(t, appointment.ID, *charges[0].AppointmentID)
+
+		freeVisit := &models.Appointment{PatientID: 1, DoctorID: 1, AppointmentTypeID: &free.ID, Date: date(2026, 3, 3), Time: "09:00", Status: models.StatusScheduled}
+		require.NoError(t, db.Create(freeVisit).Error)
+		require.NoError(t, appointments.UpdateAppointmentStatus(freeVisit.ID, models.StatusCompleted))
+		charges, err = billing.GetPatientCharges(1, models.ChargePending)
+		require.NoError(t, err)
+		assert.Len(t, charges, 1, "free appointment types are not charged")
+	})
+
+	t.Run("A charged appointment cannot be cancelled", func(t *testing.T) {
+		err := appointments.UpdateAppointmentStatus(appointment.ID, models.StatusCancelled)
+		assert.EqualError(t, err, "cannot change a completed appointment to cancelled")
+		assert.Error(t, appointments.UpdateAppointmentStatus(appointment.ID, models.StatusScheduled))
+
+		completed, err := appointments.GetAppointmentByID(appointment.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.StatusCompleted, completed.Status)
+		charges, err := billing.GetPatientCharges(1, models.ChargePending)
+		require.NoError(t, err)
+		assert.Len(t, charges, 1)
+	})
+
+	t.Run("Type report revenue is what visits were charged", func(t *testing.T) {
+		require.NoError(t, db.Model(visit).Update("price_cents", 15000).Error)
+		defer db.Model(visit).Update("price_cents", 12000)
+
+		types := services.NewAppointmentTypeService(repository.NewAppointmentTypeRepository(db), repository.NewAppointmentRepository(db)).WithTenant(1)
+		report, err := types.GetReport(date(2026, 3, 1), date(2026, 4, 1))
+		require.NoError(t, err)
+		revenue := map[string]int64{}
+		for _, row := range report {
+			revenue[row.Code] = row.RevenueCents
+		}
+		assert.Equal(t, int64(12000), revenue["99213"], "a later price change does not alter past revenue")
+		assert.Zero(t, revenue["FOLLOWUP"])
+	})
+
+	t.Run("Manual charges are validated and can be voided while pending", func(t *testing.T) {
+		assert.Error(t, billing.CreateCharge(&models.Charge{PatientID: 1, Description: " "}))
+		assert.Error(t, billing.CreateCharge(&models.Charge{PatientID: 1, Description: "Sling", UnitPriceCents: -1}))
+		assert.ErrorIs(t, billing.CreateCharge(&models.Charge{PatientID: 999, Description: "Sling"}), services.ErrPatientNotFound)
+
+		mistake := &models.Charge{PatientID: 1, Description: "Duplicate entry", UnitPriceCents: 500}
+		require.NoError(t, billing.CreateCharge(mistake))
+		_, err := billing.VoidCharge(mistake.ID, "")
+		assert.Error(t, err)
+		voided, err := billing.VoidCharge(mistake.ID, "entered twice")
+		require.NoError(t, err)
+		assert.Equal(t, models.ChargeVoided, voided.Status)
+		_, err = billing.VoidCharge(mistake.ID, "again")
+		assert.ErrorIs(t, err, services.ErrChargeNotPending)
+	})
+
+	var invoice *models.Invoice
+	t.Run("Invoices total the charges with discount and tax", func(t *testing.T) {
+		supplies := &models.Charge{PatientID: 1, Code: "A4565", Description: "Sling", Quantity: 2, UnitPriceCents: 1250}
+		require.NoError(t, billing.CreateCharge(supplies))
+		assert.Equal(t, int64(2500), supplies.AmountCents)
+
+		_, err := billing.CreateInvoice(services.InvoiceDraft{PatientID: 1, DiscountCents: 1000})
+		assert.Error(t, err, "a discount needs a reason")
+		_, err = billing.CreateInvoice(services.InvoiceDraft{PatientID: 1, DiscountCents: 20000, DiscountReason: "too much"})
+		assert.Error(t, err)
+
+		rate := 825
+		invoice, err = billing.CreateInvoice(services.InvoiceDraft{PatientID: 1, DiscountCents: 1500, DiscountReason: "Prompt pay", TaxRateBasisPoints: &rate, CreatedBy: 1})
+		require.NoError(t, err)
+		assert.Equal(t, "INV-000001", invoice.Number)
+		assert.Equal(t, int64(14500), invoice.SubtotalCents)
+		assert.Equal(t, int64(1073), invoice.TaxCents, "8.25% of 130.00 rounds to 10.73")
+		assert.Equal(t, int64(14073), invoice.TotalCents)
+		assert.Equal(t, invoice.TotalCents, invoice.BalanceCents)
+		assert.Equal(t, models.InvoiceOpen, invoice.Status)
+		assert.Equal(t, invoice.IssuedAt.AddDate(0, 0, 30).Unix(), invoice.DueDate.Unix())
+		require.Len(t, invoice.Charges, 2)
+		for _, charge := range invoice.Charges {
+			assert.Equal(t, models.ChargeInvoiced, charge.Status)
+		}
+
+		_, err = billing.CreateInvoice(services.InvoiceDraft{PatientID: 1})
+		assert.ErrorIs(t, err, services.ErrNothingToInvoice)
+		_, err = billing.CreateInvoice(services.InvoiceDraft{PatientID: 1, ChargeIDs: []uint{supplies.ID}})
+		assert.ErrorIs(t, err, services.ErrChargesNotAvailable)
+	})
+
+	t.Run("Payments and refunds move the balance", func(t *testing.T) {
+		_, err := billing.RecordPayment(&models.Payment{InvoiceID: invoice.ID, Method: "bitcoin", AmountCents: 100})
+		assert.Error(t, err)
+		_, err = billing.RecordPayment(&models.Payment{InvoiceID: invoice.ID, Method: models.PaymentCard, AmountCents: invoice.TotalCents + 1})
+		assert.ErrorIs(t, err, services.ErrPaymentExceedsOwed)
+
+		updated, err := billing.RecordPayment(&models.Payment{InvoiceID: invoice.ID, Method: "Card", AmountCents: 4073})
+		require.NoError(t, err)
+		assert.Equal(t, int64(10000), updated.BalanceCents)
+		assert.Equal(t, models.InvoiceOpen, updated.Status)
+
+		updated, err = billing.RecordPayment(&models.Payment{InvoiceID: invoice.ID, Method: models.PaymentCash, AmountCents: 10000})
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), updated.BalanceCents)
+		assert.Equal(t, models.InvoicePaid, updated.Status)
+		require.Len(t, updated.Payments, 2)
+		assert.Equal(t, uint(1), updated.Payments[0].PatientID)
+
+		_, err = billing.RecordPayment(&models.Payment{InvoiceID: invoice.ID, Kind: models.PaymentRefund, Method: models.PaymentCash, AmountCents: 20000})
+		assert.ErrorIs(t, err, services.ErrRefundExceedsPaid)
+		updated, err = billing.RecordPayment(&models.Payment{InvoiceID: invoice.ID, Kind: models.PaymentRefund, Method: models.PaymentCash, AmountCents: 2500})
+		require.NoError(t, err)
+		assert.Equal(t, int64(2500), updated.BalanceCents)
+		assert.Equal(t, int64(11573), updated.PaidCents)
+		assert.Equal(t, models.InvoiceOpen, updated.Status, "a refund reopens a paid invoice")
+
+		_, err = billing.VoidInvoice(invoice.ID, "wrong patient")
+		assert.ErrorIs(t, err, services.ErrInvoiceHasPayments)
+	})
+
+	t.Run("Balances sum invoices and unbilled charges", func(t *testing.T) {
+		require.NoError(t, billing.CreateCharge(&models.Charge{PatientID: 1, Description: "Copy of records", UnitPriceCents: 800}))
+
+		balance, err := billing.GetPatientBalance(1)
+		require.NoError(t, err)
+		assert.Equal(t, int64(14073), balance.InvoicedCents)
+		assert.Equal(t, int64(11573), balance.PaidCents)
+		assert.Equal(t, int64(2500), balance.BalanceCents)
+		assert.Equal(t, int64(800), balance.UnbilledCents)
+		assert.Equal(t, int64(1), balance.OpenInvoices)
+	})
+
+	t.Run("Voiding an invoice releases its charges", func(t *testing.T) {
+		require.NoError(t, billing.CreateCharge(&models.Charge{PatientID: 2, Description: "Flu shot", UnitPriceCents: 3000}))
+		second, err := billing.CreateInvoice(services.InvoiceDraft{PatientID: 2})
+		require.NoError(t, err)
+		assert.Equal(t, "INV-000002", second.Number)
+
+		voided, err := billing.VoidInvoice(second.ID, "raised in error")
+		require.NoError(t, err)
+		assert.Equal(t, models.InvoiceVoid, voided.Status)
+		assert.Empty(t, voided.Charges)
+		_, err = billing.RecordPayment(&models.Payment{InvoiceID: second.ID, Method: models.PaymentCash, AmountCents: 100})
+		assert.ErrorIs(t, err, services.ErrInvoiceVoid)
+
+		pending, err := billing.GetPatientCharges(2, models.ChargePending)
+		require.NoError(t, err)
+		assert.Len(t, pending, 1)
+		balance, err := billing.GetPatientBalance(2)
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), balance.InvoicedCents)
+		assert.Equal(t, int64(3000), balance.UnbilledCents)
+	})
+
+	t.Run("Billing is scoped to the tenant", func(t *testing.T) {
+		other := newBillingService(db).WithTenant(2)
+		_, err := other.GetInvoiceByID(invoice.ID)
+		assert.ErrorIs(t, err, services.ErrInvoiceNotFound)
+		_, err = other.GetPatientBalance(1)
+		assert.ErrorIs(t, err, services.ErrPatientNotFound)
+	})
+}
+
+func TestBillingRepositoryGuardsInvoiceTotals(t *testing.T) {
+	db := setupSchedulingDB(t)
+	createPatients(t, db, 1)
+	billing := newBillingService(db).WithTenant(1)
+	billingRepo := repository.NewBillingRepository(db).WithTenant(1)
+
+	require.NoError(t, billing.CreateCharge(&models.Charge{PatientID: 1, Description: "Visit", UnitPriceCents: 10000}))
+	invoice, err := billing.CreateInvoice(services.InvoiceDraft{PatientID: 1})
+	require.NoError(t, err)
+
+	t.Run("A payment checked against a stale balance is rejected when saved", func(t *testing.T) {
+		// Both payments were checked against the 100.00 balance before
+		// either was saved, as concurrent requests would be.
+		first := &models.Payment{InvoiceID: invoice.ID, PatientID: 1, Kind: models.PaymentReceived, Method: models.PaymentCard, AmountCents: 6000}
+		second := &models.Payment{InvoiceID: invoice.ID, PatientID: 1, Kind: models.PaymentReceived, Method: models.PaymentCard, AmountCents: 6000}
+		require.NoError(t, billingRepo.CreatePayment(first))
+		assert.ErrorIs(t, billingRepo.CreatePayment(second), services.ErrPaymentExceedsOwed)
+
+		updated, err := billing.GetInvoiceByID(invoice.ID)
+		require.NoError(t, err)
+		assert.Equal(t, int64(6000), updated.PaidCents)
+		assert.Equal(t, int64(4000), updated.BalanceCents)
+		assert.Len(t, updated.Payments, 1, "the rejected payment is not stored")
+
+		assert.ErrorIs(t, billingRepo.CreatePayment(&models.Payment{InvoiceID: invoice.ID, PatientID: 1, Kind: models.PaymentRefund, Method: models.PaymentCard, AmountCents: 6001}), services.ErrRefundExceedsPaid)
+		require.NoError(t, billingRepo.CreatePayment(&models.Payment{InvoiceID: invoice.ID, PatientID: 1, Kind: models.PaymentReceived, Method: models.PaymentCash, AmountCents: 4000}))
+		updated, err = billing.GetInvoiceByID(invoice.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.InvoicePaid, updated.Status)
+	})
+
+	t.Run("An invoice is not voided once a payment has landed", func(t *testing.T) {
+		assert.ErrorIs(t, billingRepo.VoidInvoice(invoice.ID, "raised in error"), services.ErrInvoiceHasPayments)
+		require.NoError(t, billingRepo.CreatePayment(&models.Payment{InvoiceID: invoice.ID, PatientID: 1, Kind: models.PaymentRefund, Method: models.PaymentCash, AmountCents: 10000}))
+		require.NoError(t, billingRepo.VoidInvoice(invoice.ID, "raised in error"))
+		assert.ErrorIs(t, billingRepo.VoidInvoice(invoice.ID, "again"), services.ErrInvoiceVoid)
+		assert.ErrorIs(t, billingRepo.CreatePayment(&models.Payment{InvoiceID: invoice.ID, PatientID: 1, Kind: models.PaymentReceived, Method: models.PaymentCash, AmountCents: 100}), services.ErrInvoiceVoid)
+
+		charges, err := billing.GetPatientCharges(1, models.ChargePending)
+		require.NoError(t, err)
+		assert.Len(t, charges, 1, "voiding returns the charges to pending")
+	})
+
+	t.Run("An appointment keeps a single live charge", func(t *testing.T) {
+		appointmentID := uint(42)
+		charge := func() *models.Charge {
+			return &models.Charge{PatientID: 1, AppointmentID: &appointmentID, Description: "Visit", Quantity: 1, UnitPriceCents: 12000, AmountCents: 12000, Status: models.ChargePending}
+		}
+		first := charge()
+		created, err := billingRepo.CreateAppointmentCharge(first)
+		require.NoError(t, err)
+		assert.True(t, created)
+		created, err = billingRepo.CreateAppointmentCharge(charge())
+		require.NoError(t, err)
+		assert.False(t, created, "a second charge for the appointment is not saved")
+
+		first.Status = models.ChargeVoided
+		require.NoError(t, billingRepo.UpdateCharge(first))
+		created, err = billingRepo.CreateAppointmentCharge(charge())
+		require.NoError(t, err)
+		assert.True(t, created, "a voided charge can be replaced")
+	})
+
+	t.Run("Invoice numbers are counted per tenant", func(t *testing.T) {
+		second, err := billing.CreateInvoice(services.InvoiceDraft{PatientID: 1})
+		require.NoError(t, err)
+		assert.Equal(t, "INV-000002", second.Number)
+
+		other := &models.Invoice{PatientID: 1, IssuedAt: date(2026, 3, 2), DueDate: date(2026, 4, 1)}
+		require.NoError(t, repository.NewBillingRepository(db).WithTenant(2).CreateInvoice(other, nil))
+		assert.Equal(t, "INV-000001", other.Number)
+	})
+}
@@ -0,0 +1,177 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"healthcare-portal/internal/models"
+	"healthcare-portal/internal/repository"
+	"healthcare-portal/internal/services"
+)
+
+func TestCarePlanService(t *testing.T) {
+	db := setupSchedulingDB(t)
+	createPatients(t, db, 2)
+	otherTenantPatient := &models.Patient{TenantID: 2, FirstName: "Other", LastName: "Tenant", Email: "other@example.com", Phone: "555-0100"}
+	require.NoError(t, db.Create(otherTenantPatient).Error)
+	doctor := &models.User{Email: "doc@example.com", Password: "x", Name: "Dr. Who", Role: models.RoleDoctor, IsActive: true}
+	nurse := &models.User{Email: "nurse@example.com", Password: "x", Name: "Nurse Joy", Role: models.RoleNurse, IsActive: true}
+	require.NoError(t, db.Create(doctor).Error)
+	require.NoError(t, db.Create(nurse).Error)
+	problem := &models.Problem{PatientID: 2, Code: "E11.9", Description: "Type 2 diabetes", Status: models.ProblemActive}
+	require.NoError(t, db.Create(problem).Error)
+
+	newService := func() services.CarePlanService {
+		return services.NewCarePlanService(
+			repository.NewCarePlanRepository(db),
+			repository.NewPatientRepository(db),
+			repository.NewUserRepository(db),
+			repository.NewProblemRepository(db),
+			newAppointmentService(db),
+		)
+	}
+	carePlanService := newService().WithTenant(1)
+
+	now := time.Now().UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	t.Run("Creates plan with goals and tasks", func(t *testing.T) {
+		wrongProblem := &models.CarePlan{PatientID: 1, ProblemID: &problem.ID, Title: "Diabetes"}
+		assert.Error(t, carePlanService.CreateCarePlan(wrongProblem))
+
+		plan := &models.CarePlan{
+			PatientID: 2, ProblemID: &problem.ID, Title: " Diabetes ", CreatedBy: doctor.ID,
+			Goals: []models.CarePlanGoal{{Description: "HbA1c below target", Target: "< 7%"}},
+			Tasks: []models.CareTask{
+				{Title: "Repeat HbA1c", DueDate: today.AddDate(0, 3, 0), AssignedTo: &nurse.ID},
+				{Title: "Foot exam", DueDate: today.AddDate(0, 0, -1), AssignedTo: &nurse.ID, DoctorID: &doctor.ID},
+			},
+		}
+		require.NoError(t, carePlanService.CreateCarePlan(plan))
+		assert.Equal(t, "Diabetes", plan.Title)
+		assert.Equal(t, models.CarePlanActive, plan.Status)
+		require.Len(t, plan.Goals, 1)
+		assert.Equal(t, models.CareGoalInProgress, plan.Goals[0].Status)
+		require.Len(t, plan.Tasks, 2)
+
+		// Tasks come back soonest first, stamped with the plan's tenant.
+		footExam, hba1c := plan.Tasks[0], plan.Tasks[1]
+		assert.Equal(t, "Foot exam", footExam.Title)
+		assert.Equal(t, uint(1), hba1c.TenantID)
+		assert.Equal(t, uint(2), hba1c.PatientID)
+		assert.Equal(t, models.CareTaskPending, hba1c.Status)
+		assert.Equal(t, today.AddDate(0, 3, -14), hba1c.EarliestDate.UTC())
+		// A task recorded after its due date gets a one-day window.
+		assert.Equal(t, today.AddDate(0, 0, -1), footExam.EarliestDate.UTC())
+
+		badTask := models.CareTask{Title: "Call", DueDate: today, EarliestDate: today.AddDate(0, 0, 1)}
+		assert.Error(t, carePlanService.AddTask(plan.ID, &badTask))
+		unknownAssignee := uint(999)
+		assert.Error(t, carePlanService.AddTask(plan.ID, &models.CareTask{Title: "Call", DueDate: today, AssignedTo: &unknownAssignee}))
+	})
+
+	t.Run("Worker marks past due tasks overdue", func(t *testing.T) {
+		dueToday := models.CareTask{Title: "Phone follow-up", DueDate: today, AssignedTo: &doctor.ID}
+		plans, err := carePlanService.GetPatientCarePlans(2, models.CarePlanActive)
+		require.NoError(t, err)
+		require.Len(t, plans, 1)
+		require.NoError(t, carePlanService.AddTask(plans[0].ID, &dueToday))
+
+		overdue, err := services.NewCareTaskWorker(newService().AllTenants(), time.Hour).Sweep()
+		require.NoError(t, err)
+		require.Len(t, overdue, 1)
+		assert.Equal(t, "Foot exam", overdue[0].Title)
+
+		// A second sweep finds nothing new.
+		overdue, err = services.NewCareTaskWorker(newService().AllTenants(), time.Hour).Sweep()
+		require.NoError(t, err)
+		assert.Empty(t, overdue)
+
+		nurseOverdue, err := carePlanService.GetTasks(models.CareTaskFilter{AssignedTo: &nurse.ID, Statuses: []models.CareTaskStatus{models.CareTaskOverdue}})
+		require.NoError(t, err)
+		require.Len(t, nurseOverdue, 1)
+		require.NotNil(t, nurseOverdue[0].Patient)
+
+		// Rescheduling into the future puts it back to pending.
+		task := nurseOverdue[0]
+		task.DueDate = today.AddDate(0, 0, 7)
+		task.EarliestDate = time.Time{}
+		require.NoError(t, carePlanService.UpdateTask(&task))
+		assert.Equal(t, models.CareTaskPending, task.Status)
+	})
+
+	t.Run("Suggests and books appointments in the window", func(t *testing.T) {
+		plans, err := carePlanService.GetPatientCarePlans(2, "")
+		require.NoError(t, err)
+		task := models.CareTask{
+			Title: "Review results", DoctorID: &doctor.ID,
+			EarliestDate: time.Date(2030, 1, 8, 0, 0, 0, 0, time.UTC), DueDate: time.Date(2030, 1, 10, 0, 0, 0, 0, time.UTC),
+		}
+		require.NoError(t, carePlanService.AddTask(plans[0].ID, &task))
+
+		// Another patient already has the first slot of the window.
+		appointmentService := newAppointmentService(db).WithTenant(1)
+		require.NoError(t, appointmentService.CreateAppointment(&models.Appointment{PatientID: 1, DoctorID: doctor.ID, Date: time.Date(2030, 1, 8, 0, 0, 0, 0, time.UTC), Time: "09:00"}))
+
+		suggestions, err := carePlanService.SuggestAppointments(task.ID, time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC), 3)
+		require.NoError(t, err)
+		require.Len(t, suggestions, 3)
+		assert.Equal(t, "2030-01-08", suggestions[0].Date)
+		assert.Equal(t, "09:30", suggestions[0].Time)
+		assert.Equal(t, doctor.ID, suggestions[0].DoctorID)
+
+		// Past the due date, slots come from the next two weeks instead.
+		suggestions, err = carePlanService.SuggestAppointments(task.ID, time.Date(2030, 2, 1, 0, 0, 0, 0, time.UTC), 1)
+		require.NoError(t, err)
+		require.Len(t, suggestions, 1)
+		assert.Equal(t, "2030-02-01", suggestions[0].Date)
+
+		booked, err := carePlanService.BookTask(task.ID, &models.Appointment{Date: time.Date(2030, 1, 8, 0, 0, 0, 0, time.UTC), Time: "09:30"})
+		require.NoError(t, err)
+		assert.Equal(t, models.CareTaskScheduled, booked.Status)
+		require.NotNil(t, booked.Appointment)
+		assert.Equal(t, doctor.ID, booked.Appointment.DoctorID)
+		assert.Equal(t, "Follow-up: Review results", booked.Appointment.Notes)
+
+		_, err = carePlanService.BookTask(task.ID, &models.Appointment{Date: time.Date(2030, 1, 9, 0, 0, 0, 0, time.UTC), Time: "09:30"})
+		assert.Error(t, err)
+
+		completed, err := carePlanService.CompleteTask(task.ID, nurse.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.CareTaskCompleted, completed.Status)
+		_, err = carePlanService.CompleteTask(task.ID, nurse.ID)
+		assert.ErrorIs(t, err, services.ErrCareTaskClosed)
+	})
+
+	t.Run("Closing a plan cancels its open tasks", func(t *testing.T) {
+		plans, err := carePlanService.GetPatientCarePlans(2, "")
+		require.NoError(t, err)
+		plan := &models.CarePlan{ID: plans[0].ID, Title: plans[0].Title, Status: models.CarePlanCompleted}
+		require.NoError(t, carePlanService.UpdateCarePlan(plan))
+
+		for _, task := range plan.Tasks {
+			if task.Title == "Review results" {
+				assert.Equal(t, models.CareTaskCompleted, task.Status)
+				continue
+			}
+			assert.Equal(t, models.CareTaskCancelled, task.Status, task.Title)
+		}
+		assert.Error(t, carePlanService.AddTask(plan.ID, &models.CareTask{Title: "Late", DueDate: today}))
+	})
+
+	t.Run("Plans and tasks are scoped to the tenant", func(t *testing.T) {
+		otherTenant := newService().WithTenant(2)
+		plan := &models.CarePlan{PatientID: otherTenantPatient.ID, Title: "Hypertension", Tasks: []models.CareTask{{Title: "BP check", DueDate: today.AddDate(0, 1, 0)}}}
+		require.NoError(t, otherTenant.CreateCarePlan(plan))
+		assert.Equal(t, uint(2), plan.Tasks[0].TenantID)
+
+		_, err := carePlanService.GetTaskByID(plan.Tasks[0].ID)
+		assert.ErrorIs(t, err, services.ErrCareTaskNotFound)
+		_, err = otherTenant.GetCarePlanByID(1)
+		assert.ErrorIs(t, err, services.ErrCarePlanNotFound)
+		assert.Error(t, otherTenant.CreateCarePlan(&models.CarePlan{PatientID: 1, Title: "Not mine"}))
+	})
+}
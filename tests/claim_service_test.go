@@ -0,0 +1,277 @@
+package tests
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"healthcare-portal/internal/config"
+	"healthcare-portal/internal/models"
+	"healthcare-portal/internal/repository"
+	"healthcare-portal/internal/services"
+	"healthcare-portal/internal/x12"
+)
+
+var testClaimsConfig = config.ClaimsConfig{
+	SubmitterID:           "SUB123",
+	SubmitterName:         "Main Street Clinic",
+	ContactName:           "Billing Office",
+	ContactPhone:          "(555) 010-0000",
+	ReceiverID:            "CLEARHOUSE",
+	ReceiverName:          "Clearing House",
+	BillingProviderName:   "Main Street Clinic",
+	BillingProviderNPI:    "1234567893",
+	BillingProviderTaxID:  "12-3456789",
+	BillingProviderStreet: "1 Main St",
+	BillingProviderCity:   "Springfield",
+	BillingProviderState:  "IL",
+	BillingProviderZip:    "62701",
+	Usage:                 "T",
+}
+
+func newClaimService(db *gorm.DB, claims config.ClaimsConfig) services.ClaimService {
+	return services.NewClaimService(
+		repository.NewClaimRepository(db),
+		repository.NewAppointmentRepository(db),
+		repository.NewProblemRepository(db),
+		repository.NewInsuranceRepository(db),
+		repository.NewBillingRepository(db),
+		claims,
+	)
+}
+
+// remittance835 builds an 835 paying claims, one CLP line each.
+func remittance835(trace string, clps ...string) []byte {
+	body := []string{
+		"ST*835*0001~",
+		"BPR*I*90*C*ACH*CCP*01*999999999*DA*123456*1512345678**01*999988880*DA*98765*20260320~",
+		"TRN*1*" + trace + "*1512345678~",
+		"N1*PR*ACME HEALTH~",
+		"N1*PE*MAIN STREET CLINIC*XX*1234567893~",
+		"LX*1~",
+	}
+	body = append(body, clps...)
+	body = append(body, fmt.Sprintf("SE*%d*0001~", len(body)+1))
+	isa := "ISA*00*          *00*          *ZZ*ACME           *ZZ*SUB123         *260320*1200*^*00501*000000007*0*T*:~"
+	return []byte(isa + "\nGS*HP*ACME*SUB123*20260320*1200*7*X*005010X221A1~\n" +
+		strings.Join(body, "\n") + "\nGE*1*7~\nIEA*1*000000007~\n")
+}
+
+func TestClaimService(t *testing.T) {
+	db := setupSchedulingDB(t)
+	createPatients(t, db, 2)
+	require.NoError(t, db.Create(&models.User{Email: "doc@example.com", Password: "x", Name: "Doc", Role: models.RoleDoctor, IsActive: true}).Error)
+	require.NoError(t, db.Model(&models.Patient{}).Where("id IN ?", []uint{1, 2}).Updates(map[string]interface{}{
+		"address": "12 Oak Ave, Springfield, IL 62704", "date_of_birth": date(1980, 4, 5), "gender": "female",
+	}).Error)
+
+	payer := &models.InsurancePayer{Name: "Acme Health", PayerCode: "ACME1", IsActive: true}
+	require.NoError(t, db.Create(payer).Error)
+	plan := &models.InsurancePlan{PayerID: payer.ID, Name: "Acme PPO", PlanType: models.PlanPPO, IsActive: true}
+	require.NoError(t, db.Create(plan).Error)
+	require.NoError(t, db.Create(&models.Coverage{PatientID: 1, PlanID: plan.ID, MemberID: "M123", GroupNumber: "G9", Priority: 1,
+		SubscriberRelationship: models.SubscriberSelf, EffectiveFrom: date(2026, 1, 1)}).Error)
+	parentDOB := date(1975, 2, 3)
+	require.NoError(t, db.Create(&models.Coverage{PatientID: 2, PlanID: plan.ID, MemberID: "M999", Priority: 1,
+		SubscriberRelationship: models.SubscriberChild, SubscriberName: "Pat Parent", SubscriberDateOfBirth: &parentDOB,
+		EffectiveFrom: date(2026, 1, 1)}).Error)
+
+	types := services.NewAppointmentTypeService(repository.NewAppointmentTypeRepository(db), repository.NewAppointmentRepository(db)).WithTenant(1)
+	assert.Error(t, types.CreateAppointmentType(&models.AppointmentType{Code: "consult", Name: "Consult", DurationMinutes: 15, ProcedureCode: "office-visit", IsActive: true}),
+		"a procedure code that is not a CPT or HCPCS code is rejected")
+	visit := &models.AppointmentType{Code: "office-visit", Name: "Office visit", DurationMinutes: 15, PriceCents: 12000, ProcedureCode: " 99213 ", IsActive: true}
+	require.NoError(t, types.CreateAppointmentType(visit))
+	assert.Equal(t, "99213", visit.ProcedureCode)
+	appointments := newAppointmentService(db).WithTenant(1)
+	completedVisit := func(patientID uint, day int) *models.Appointment {
+		appointment := &models.Appointment{PatientID: patientID, DoctorID: 1, AppointmentTypeID: &visit.ID, Date: date(2026, 3, day), Time: "09:00", Status: models.StatusScheduled}
+		require.NoError(t, db.Create(appointment).Error)
+		require.NoError(t, appointments.UpdateAppointmentStatus(appointment.ID, models.StatusCompleted))
+		return appointment
+	}
+	first := completedVisit(1, 2)
+	second := completedVisit(2, 3)
+
+	claims := newClaimService(db, testClaimsConfig).WithTenant(1)
+	billing := newBillingService(db).WithTenant(1)
+
+	var claim *models.Claim
+	t.Run("Claims are validated when generated and can be regenerated", func(t *testing.T) {
+		scheduled := &models.Appointment{PatientID: 1, DoctorID: 1, Date: date(2026, 3, 9), Time: "09:00", Status: models.StatusScheduled}
+		require.NoError(t, db.Create(scheduled).Error)
+		_, err := claims.GenerateClaim(scheduled.ID, 1)
+		assert.ErrorIs(t, err, services.ErrAppointmentNotCompleted)
+		_, err = claims.GenerateClaim(9999, 1)
+		assert.ErrorIs(t, err, services.ErrAppointmentNotFound)
+
+		claim, err = claims.GenerateClaim(first.ID, 1)
+		require.NoError(t, err)
+		assert.Equal(t, models.ClaimInvalid, claim.Status)
+		assert.Contains(t, claim.ValidationErrors, "the appointment has no diagnoses")
+
+		require.NoError(t, db.Create(&models.AppointmentDiagnosis{AppointmentID: first.ID, Code: "E11.9", Description: "Type 2 diabetes", Rank: 1}).Error)
+		require.NoError(t, db.Create(&models.AppointmentDiagnosis{AppointmentID: first.ID, Code: "I10", Description: "Hypertension", Rank: 2}).Error)
+		regenerated, err := claims.GenerateClaim(first.ID, 1)
+		require.NoError(t, err)
+		assert.Equal(t, claim.ID, regenerated.ID)
+		assert.Equal(t, "CLM-000001", regenerated.ControlNumber)
+		assert.Equal(t, models.ClaimReady, regenerated.Status)
+		assert.Empty(t, regenerated.ValidationErrors)
+		assert.Equal(t, int64(12000), regenerated.TotalChargeCents)
+		require.Len(t, regenerated.Lines, 1)
+		assert.Equal(t, "99213", regenerated.Lines[0].ProcedureCode)
+		assert.Equal(t, "1,2", regenerated.Lines[0].DiagnosisPointers)
+		require.Len(t, regenerated.Diagnoses, 2)
+		claim = regenerated
+
+		unconfigured, err := newClaimService(db, config.ClaimsConfig{}).WithTenant(1).GenerateClaim(first.ID, 1)
+		require.NoError(t, err)
+		assert.Equal(t, models.ClaimInvalid, unconfigured.Status)
+		assert.Contains(t, unconfigured.ValidationErrors, "the billing provider NPI is missing or invalid")
+		claim, err = claims.GenerateClaim(first.ID, 1)
+		require.NoError(t, err)
+	})
+
+	t.Run("Ready claims are batched into an 837P file", func(t *testing.T) {
+		require.NoError(t, db.Create(&models.AppointmentDiagnosis{AppointmentID: second.ID, Code: "J06.9", Description: "URI", Rank: 1}).Error)
+		childClaim, err := claims.GenerateClaim(second.ID, 1)
+		require.NoError(t, err)
+		require.Equal(t, models.ClaimReady, childClaim.Status, childClaim.ValidationErrors)
+
+		batch, err := claims.CreateBatch(nil, 1)
+		require.NoError(t, err)
+		assert.Equal(t, 2, batch.ClaimCount)
+		assert.Equal(t, int64(24000), batch.TotalChargeCents)
+		require.Len(t, batch.Claims, 2)
+		assert.Equal(t, models.ClaimSubmitted, batch.Claims[0].Status)
+
+		segments, _, err := x12.Parse([]byte(batch.Content))
+		require.NoError(t, err)
+		ids := make([]string, len(segments))
+		for i, segment := range segments {
+			ids[i] = segment.ID()
+		}
+		assert.Equal(t, "ISA", ids[0])
+		assert.Equal(t, "IEA", ids[len(ids)-1])
+		for i, segment := range segments {
+			if segment.ID() == "SE" {
+				start := 0
+				for j, id := range ids {
+					if id == "ST" {
+						start = j
+					}
+				}
+				assert.Equal(t, fmt.Sprint(i-start+1), segment.Element(1), "SE01 counts ST through SE")
+			}
+		}
+		assert.Contains(t, batch.Content, "NM1*85*2*Main Street Clinic*****XX*1234567893~")
+		assert.Contains(t, batch.Content, "REF*EI*123456789~")
+		assert.Contains(t, batch.Content, "SBR*P*18*G9******12~")
+		assert.Contains(t, batch.Content, "NM1*IL*1*1*Patient****MI*M123~")
+		assert.Contains(t, batch.Content, "N4*Springfield*IL*62704~")
+		assert.Contains(t, batch.Content, "CLM*CLM-000001*120***11:B:1*Y*A*Y*Y~")
+		assert.Contains(t, batch.Content, "HI*ABK:E119*ABF:I10~")
+		assert.Contains(t, batch.Content, "SV1*HC:99213*120*UN*1***1:2~")
+		assert.Contains(t, batch.Content, "DTP*472*D8*20260302~")
+		assert.Contains(t, batch.Content, "NM1*IL*1*Parent*Pat****MI*M999~")
+		assert.Contains(t, batch.Content, "PAT*19~")
+
+		_, err = claims.GenerateClaim(first.ID, 1)
+		assert.ErrorIs(t, err, services.ErrClaimAlreadySubmitted)
+		_, err = claims.CreateBatch(nil, 1)
+		assert.ErrorIs(t, err, services.ErrNothingToSubmit)
+		_, err = claims.CreateBatch([]uint{claim.ID}, 1)
+		assert.ErrorIs(t, err, services.ErrClaimsNotReady)
+	})
+
+	t.Run("Remittances post insurance payments to invoices", func(t *testing.T) {
+		invoice, err := billing.CreateInvoice(services.InvoiceDraft{PatientID: 1})
+		require.NoError(t, err)
+
+		file := remittance835("EFT0001",
+			"CLP*CLM-000001*1*120*90*20*12*PAYER123~",
+			"CAS*CO*45*10~",
+			"SVC*HC:99213*120*90~",
+			"CAS*PR*2*20~",
+			"CLP*CLM-000002*4*120*0*0*12*PAYER124~",
+			"CLP*CLM-999999*1*50*50*0*12*PAYER125~",
+		)
+		remittances, err := claims.ImportRemittance("acme.835", file, 1)
+		require.NoError(t, err)
+		require.Len(t, remittances, 1)
+		remittance := remittances[0]
+		assert.Equal(t, "EFT0001", remittance.TraceNumber)
+		assert.Equal(t, "ACME HEALTH", remittance.PayerName)
+		assert.Equal(t, int64(9000), remittance.TotalPaidCents)
+		require.Len(t, remittance.Claims, 3)
+		assert.Equal(t, models.RemittancePosted, remittance.Claims[0].Outcome)
+		assert.Equal(t, int64(1000), remittance.Claims[0].AdjustmentCents)
+		assert.Equal(t, models.RemittanceDenied, remittance.Claims[1].Outcome)
+		assert.Equal(t, models.RemittanceUnmatched, remittance.Claims[2].Outcome)
+
+		paid, err := claims.GetClaimByID(claim.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.ClaimPaid, paid.Status)
+		assert.Equal(t, int64(9000), paid.PaidCents)
+		assert.Equal(t, int64(2000), paid.PatientResponsibilityCents)
+		assert.Equal(t, "PAYER123", paid.PayerClaimNumber)
+		assert.Equal(t, int64(9000), paid.Lines[0].PaidCents)
+
+		invoice, err = billing.GetInvoiceByID(invoice.ID)
+		require.NoError(t, err)
+		assert.Equal(t, int64(3000), invoice.BalanceCents)
+		require.Len(t, invoice.Payments, 1)
+		assert.Equal(t, models.PaymentInsurance, invoice.Payments[0].Method)
+		assert.Equal(t, "EFT0001", invoice.Payments[0].Reference)
+
+		denied, err := claims.GetPatientClaims(2)
+		require.NoError(t, err)
+		assert.Equal(t, models.ClaimDenied, denied[0].Status)
+		resubmitted, err := claims.GenerateClaim(second.ID, 1)
+		require.NoError(t, err)
+		assert.NotEqual(t, denied[0].ID, resubmitted.ID, "a denied claim is replaced by a new one")
+
+		_, err = claims.ImportRemittance("acme.835", file, 1)
+		assert.ErrorIs(t, err, services.ErrRemittanceImported)
+		_, err = claims.ImportRemittance("junk.txt", []byte("not an interchange"), 1)
+		assert.ErrorIs(t, err, services.ErrInvalidRemittance)
+	})
+
+	t.Run("Payments on uninvoiced charges are left for staff", func(t *testing.T) {
+		third := completedVisit(1, 10)
+		require.NoError(t, db.Create(&models.AppointmentDiagnosis{AppointmentID: third.ID, Code: "I10", Description: "Hypertension", Rank: 1}).Error)
+		thirdClaim, err := claims.GenerateClaim(third.ID, 1)
+		require.NoError(t, err)
+		_, err = claims.CreateBatch([]uint{thirdClaim.ID}, 1)
+		require.NoError(t, err)
+
+		remittances, err := claims.ImportRemittance("acme2.835", remittance835("EFT0002", "CLP*"+thirdClaim.ControlNumber+"*1*120*100*20*12*PAYER200~"), 1)
+		require.NoError(t, err)
+		assert.Equal(t, models.RemittanceNotPosted, remittances[0].Claims[0].Outcome)
+		assert.Contains(t, remittances[0].Claims[0].Note, "not on an invoice")
+	})
+
+	t.Run("A file repeating a trace number is rejected", func(t *testing.T) {
+		single := string(remittance835("EFT0003", "CLP*CLM-999998*1*50*50*0*12*PAYER300~"))
+		advice := single[strings.Index(single, "ST*835"):strings.Index(single, "GE*")]
+		repeated := strings.Replace(single, "GE*1*7~", advice+"GE*2*7~", 1)
+
+		_, err := claims.ImportRemittance("repeated.835", []byte(repeated), 1)
+		assert.ErrorIs(t, err, services.ErrInvalidRemittance)
+		remittances, err := claims.ImportRemittance("single.835", []byte(single), 1)
+		require.NoError(t, err, "nothing from the rejected file was imported")
+		assert.Len(t, remittances, 1)
+	})
+
+	t.Run("Claims are scoped to the tenant", func(t *testing.T) {
+		other := newClaimService(db, testClaimsConfig).WithTenant(2)
+		_, err := other.GetClaimByID(claim.ID)
+		assert.ErrorIs(t, err, services.ErrClaimNotFound)
+		_, err = other.GenerateClaim(first.ID, 1)
+		assert.ErrorIs(t, err, services.ErrAppointmentNotFound)
+	})
+}
@@ -0,0 +1,248 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"healthcare-portal/internal/models"
+	"healthcare-portal/internal/repository"
+	"healthcare-portal/internal/services"
+)
+
+func newConsentService(db *gorm.DB) services.ConsentService {
+	return services.NewConsentService(repository.NewConsentRepository(db), repository.NewPatientRepository(db), repository.NewRelatedPersonRepository(db))
+}
+
+// recordingNotifier keeps the messages it is asked to send.
+type recordingNotifier struct {
+	sent []string
+}
+
+func (n *recordingNotifier) Send(channel services.NotificationChannel, to, message string) error {
+	n.sent = append(n.sent, string(channel)+":"+to)
+	return nil
+}
+
+func TestConsentService(t *testing.T) {
+	db := setupSchedulingDB(t)
+	consentService := newConsentService(db).WithTenant(1)
+	createPatients(t, db, 2)
+
+	publish := func(consentType models.ConsentType, reconsent bool) models.ConsentText {
+		text := models.ConsentText{Type: consentType, Title: "Reminders", Body: "You may contact me.", RequiresReconsent: reconsent}
+		require.NoError(t, consentService.PublishText(&text))
+		return text
+	}
+
+	t.Run("Consent needs a published text", func(t *testing.T) {
+		consent := &models.Consent{PatientID: 1, Type: models.ConsentSMSReminders}
+		assert.ErrorIs(t, consentService.GrantConsent(consent), services.ErrConsentTextNotFound)
+		assert.Error(t, consentService.GrantConsent(&models.Consent{PatientID: 1, Type: "marketing"}))
+	})
+
+	t.Run("Granting again supersedes the active consent", func(t *testing.T) {
+		first := publish(models.ConsentSMSReminders, false)
+		assert.Equal(t, 1, first.Version)
+
+		consent := &models.Consent{PatientID: 1, Type: " SMS_Reminders ", RecordedBy: 7}
+		require.NoError(t, consentService.GrantConsent(consent))
+		assert.Equal(t, models.ConsentGranted, consent.Status)
+		assert.Equal(t, first.ID, consent.ConsentTextID)
+
+		second := publish(models.ConsentSMSReminders, false)
+		assert.Equal(t, 2, second.Version)
+		again := &models.Consent{PatientID: 1, Type: models.ConsentSMSReminders, RecordedBy: 7}
+		require.NoError(t, consentService.GrantConsent(again))
+		assert.Equal(t, 2, again.TextVersion)
+
+		active, err := consentService.GetPatientConsents(1, false)
+		require.NoError(t, err)
+		require.Len(t, active, 1)
+		assert.Equal(t, again.ID, active[0].ID)
+
+		history, err := consentService.GetPatientConsents(1, true)
+		require.NoError(t, err)
+		require.Len(t, history, 2)
+		superseded, err := consentService.GetConsentByID(1, consent.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.ConsentRevoked, superseded.Status)
+		assert.Equal(t, "superseded", superseded.RevocationReason)
+		assert.NotNil(t, superseded.RevokedAt)
+
+		allowed, err := consentService.HasConsent(1, models.ConsentSMSReminders)
+		require.NoError(t, err)
+		assert.True(t, allowed)
+	})
+
+	t.Run("Revoking withdraws consent", func(t *testing.T) {
+		publish(models.ConsentEmailReminders, false)
+		consent := &models.Consent{PatientID: 2, Type: models.ConsentEmailReminders}
+		require.NoError(t, consentService.GrantConsent(consent))
+
+		revoked, err := consentService.RevokeConsent(2, consent.ID, 9, "Asked to stop emails")
+		require.NoError(t, err)
+		assert.Equal(t, models.ConsentRevoked, revoked.Status)
+		assert.Equal(t, uint(9), *revoked.RevokedBy)
+
+		_, err = consentService.RevokeConsent(2, consent.ID, 9, "")
+		assert.ErrorIs(t, err, services.ErrConsentAlreadyRevoked)
+		_, err = consentService.RevokeConsent(1, consent.ID, 9, "")
+		assert.ErrorIs(t, err, services.ErrConsentNotFound)
+
+		allowed, err := consentService.HasConsent(2, models.ConsentEmailReminders)
+		require.NoError(t, err)
+		assert.False(t, allowed)
+	})
+
+	t.Run("A text requiring reconsent retires older consents", func(t *testing.T) {
+		publish(models.ConsentResearch, false)
+		consent := &models.Consent{PatientID: 2, Type: models.ConsentResearch}
+		require.NoError(t, consentService.GrantConsent(consent))
+
+		future := publish(models.ConsentResearch, true)
+		require.NoError(t, db.Model(&future).Update("effective_from", time.Now().Add(24*time.Hour)).Error)
+		allowed, err := consentService.HasConsent(2, models.ConsentResearch)
+		require.NoError(t, err)
+		assert.True(t, allowed, "reconsent only applies once the new text is in effect")
+
+		require.NoError(t, db.Model(&future).Update("effective_from", time.Now().Add(-time.Minute)).Error)
+		allowed, err = consentService.HasConsent(2, models.ConsentResearch)
+		require.NoError(t, err)
+		assert.False(t, allowed)
+
+		consents, err := consentService.GetPatientConsents(2, false)
+		require.NoError(t, err)
+		require.Len(t, consents, 1)
+		assert.True(t, consents[0].NeedsReconsent)
+
+		ids, err := consentService.ConsentedPatientIDs(models.ConsentResearch)
+		require.NoError(t, err)
+		assert.Empty(t, ids)
+	})
+
+	t.Run("Minors consent through a guardian", func(t *testing.T) {
+		child := &models.Patient{FirstName: "Tim", LastName: "Tiny", Email: "tim@example.com", Phone: "555-0101", DateOfBirth: time.Now().AddDate(-10, 0, 0)}
+		require.NoError(t, db.Create(child).Error)
+		uncle := &models.RelatedPerson{PatientID: child.ID, Name: "Uncle Bob", Relationship: models.RelationshipOther, MobilePhone: "555-0200"}
+		mother := &models.RelatedPerson{PatientID: child.ID, Name: "Tina Tiny", Relationship: models.RelationshipParent, MobilePhone: "555-0201", IsGuardian: true}
+		require.NoError(t, db.Create(uncle).Error)
+		require.NoError(t, db.Create(mother).Error)
+
+		consent := &models.Consent{PatientID: child.ID, Type: models.ConsentSMSReminders}
+		assert.ErrorIs(t, consentService.GrantConsent(consent), services.ErrGuardianConsentRequired)
+		consent.RelatedPersonID = &uncle.ID
+		assert.ErrorIs(t, consentService.GrantConsent(consent), services.ErrGuardianConsentRequired)
+		consent.RelatedPersonID = &mother.ID
+		require.NoError(t, consentService.GrantConsent(consent))
+	})
+
+	t.Run("Consents are scoped to the tenant", func(t *testing.T) {
+		otherTenant := newConsentService(db).WithTenant(2)
+		_, err := otherTenant.GetPatientConsents(1, true)
+		assert.ErrorIs(t, err, services.ErrPatientNotFound)
+		texts, err := otherTenant.GetTexts("")
+		require.NoError(t, err)
+		assert.Empty(t, texts)
+	})
+}
+
+func TestAppointmentRemindersRespectConsent(t *testing.T) {
+	db := setupSchedulingDB(t)
+	consentService := newConsentService(db)
+	createPatients(t, db, 3)
+	doctor := &models.User{Email: "doc@example.com", Password: "x", Name: "Doc", Role: models.RoleDoctor, IsActive: true}
+	require.NoError(t, db.Create(doctor).Error)
+
+	scoped := consentService.WithTenant(1)
+	for _, consentType := range []models.ConsentType{models.ConsentSMSReminders, models.ConsentEmailReminders} {
+		require.NoError(t, scoped.PublishText(&models.ConsentText{Type: consentType, Title: "Reminders", Body: "You may remind me."}))
+	}
+	require.NoError(t, scoped.GrantConsent(&models.Consent{PatientID: 1, Type: models.ConsentSMSReminders}))
+	require.NoError(t, scoped.GrantConsent(&models.Consent{PatientID: 1, Type: models.ConsentEmailReminders}))
+	require.NoError(t, scoped.GrantConsent(&models.Consent{PatientID: 2, Type: models.ConsentEmailReminders}))
+
+	now := time.Date(2030, 3, 4, 15, 0, 0, 0, time.UTC)
+	for patientID := uint(1); patientID <= 3; patientID++ {
+		appointment := &models.Appointment{PatientID: patientID, DoctorID: doctor.ID, Date: date(2030, 3, 5), Time: "09:30", Status: models.StatusScheduled}
+		require.NoError(t, db.Create(appointment).Error)
+	}
+	cancelled := &models.Appointment{PatientID: 1, DoctorID: doctor.ID, Date: date(2030, 3, 5), Time: "11:00", Status: models.StatusCancelled}
+	require.NoError(t, db.Create(cancelled).Error)
+
+	notifier := &recordingNotifier{}
+	worker := services.NewAppointmentReminderWorker(repository.NewAppointmentRepository(db).AllTenants(), consentService.AllTenants(), notifier, time.Hour)
+
+	result, err := worker.Sweep(now)
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Sent)
+	assert.Equal(t, 1, result.Skipped)
+	assert.ElementsMatch(t, []string{"sms:555-0001", "email:patient1@example.com", "email:patient2@example.com"}, notifier.sent)
+
+	// Reminded appointments are not reminded again; the patient without
+	// consent is still checked on every sweep.
+	notifier.sent = nil
+	result, err = worker.Sweep(now.Add(time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, 0, result.Sent)
+	assert.Equal(t, 1, result.Skipped)
+	assert.Empty(t, notifier.sent)
+}
+
+func TestDeidentifiedExportRespectsConsent(t *testing.T) {
+	db := setupSchedulingDB(t)
+	consentService := newConsentService(db)
+	scoped := consentService.WithTenant(1)
+
+	consenting := &models.Patient{FirstName: "Rita", LastName: "Research", Email: "rita@example.com", Phone: "555-0301", DateOfBirth: date(1975, 8, 9), Gender: "female", MRN: "MRN-00000018"}
+	elderly := &models.Patient{FirstName: "Olga", LastName: "Old", Email: "olga@example.com", Phone: "555-0302", DateOfBirth: time.Now().AddDate(-95, 0, 0), Gender: "female"}
+	declining := &models.Patient{FirstName: "Dan", LastName: "Declined", Email: "dan@example.com", Phone: "555-0303", DateOfBirth: date(1980, 1, 1), Gender: "male"}
+	for _, patient := range []*models.Patient{consenting, elderly, declining} {
+		require.NoError(t, db.Create(patient).Error)
+	}
+
+	onset := date(2019, 5, 6)
+	require.NoError(t, db.Create(&models.Problem{TenantID: 1, PatientID: consenting.ID, Code: "E11.9", Description: "Type 2 diabetes mellitus without complications", OnsetDate: &onset}).Error)
+	require.NoError(t, db.Create(&models.Problem{TenantID: 1, PatientID: declining.ID, Code: "I10", Description: "Essential (primary) hypertension"}).Error)
+	require.NoError(t, db.Create(&models.Immunization{TenantID: 1, PatientID: consenting.ID, VaccineCode: "MMR", VaccineName: "Measles, mumps and rubella", DoseNumber: 1, AdministeredAt: date(1976, 9, 1)}).Error)
+
+	require.NoError(t, scoped.PublishText(&models.ConsentText{Type: models.ConsentResearch, Title: "Research", Body: "My de-identified data may be used for research."}))
+	for _, patient := range []*models.Patient{consenting, elderly, declining} {
+		require.NoError(t, scoped.GrantConsent(&models.Consent{PatientID: patient.ID, Type: models.ConsentResearch}))
+	}
+	declined, err := scoped.GetPatientConsents(declining.ID, false)
+	require.NoError(t, err)
+	_, err = scoped.RevokeConsent(declining.ID, declined[0].ID, 1, "Withdrew from research")
+	require.NoError(t, err)
+
+	exportService := services.NewExportService(consentService, repository.NewPatientRepository(db), repository.NewProblemRepository(db), repository.NewImmunizationRepository(db), "test-key")
+	records, err := exportService.WithTenant(1).DeidentifiedPatients()
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+
+	rita := records[0]
+	assert.Len(t, rita.Pseudonym, 32)
+	require.NotNil(t, rita.BirthYear)
+	assert.Equal(t, 1975, *rita.BirthYear)
+	require.Len(t, rita.Problems, 1)
+	assert.Equal(t, "E11.9", rita.Problems[0].Code)
+	assert.Equal(t, 2019, *rita.Problems[0].OnsetYear)
+	require.Len(t, rita.Immunizations, 1)
+	assert.Equal(t, 1976, rita.Immunizations[0].AdministeredYear)
+
+	olga := records[1]
+	assert.Nil(t, olga.BirthYear)
+	assert.True(t, olga.AgeOver89)
+	assert.NotEqual(t, rita.Pseudonym, olga.Pseudonym)
+
+	again, err := exportService.WithTenant(1).DeidentifiedPatients()
+	require.NoError(t, err)
+	assert.Equal(t, rita.Pseudonym, again[0].Pseudonym, "pseudonyms are stable across exports")
+
+	otherTenant, err := exportService.WithTenant(2).DeidentifiedPatients()
+	require.NoError(t, err)
+	assert.Empty(t, otherTenant)
+}
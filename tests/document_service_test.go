@@ -0,0 +1,142 @@
+package tests
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"healthcare-portal/internal/models"
+	"healthcare-portal/internal/repository"
+	"healthcare-portal/internal/services"
+	"healthcare-portal/internal/storage"
+)
+
+func TestLocalStore(t *testing.T) {
+	store, err := storage.NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+
+	require.NoError(t, store.Put("patients/1/abc", strings.NewReader("hello"), "text/plain"))
+	blob, err := store.Get("patients/1/abc")
+	require.NoError(t, err)
+	content, _ := io.ReadAll(blob)
+	blob.Close()
+	assert.Equal(t, "hello", string(content))
+
+	for _, key := range []string{"", "../escape", "/etc/passwd", "patients/../../escape", "patients//1"} {
+		assert.Error(t, store.Put(key, strings.NewReader("x"), ""), key)
+	}
+
+	require.NoError(t, store.Delete("patients/1/abc"))
+	_, err = store.Get("patients/1/abc")
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+}
+
+func TestDocumentService(t *testing.T) {
+	db := setupSchedulingDB(t)
+	createPatients(t, db, 1)
+	dir := t.TempDir()
+	store, err := storage.NewLocalStore(dir)
+	require.NoError(t, err)
+	documentService := services.NewDocumentService(repository.NewDocumentRepository(db), repository.NewPatientRepository(db), store, 1024).WithTenant(1)
+
+	pdf := []byte("%PDF-1.4\n1 0 obj << /Type /Catalog >> endobj\n%%EOF\n")
+
+	t.Run("Upload sniffs type and checksums content", func(t *testing.T) {
+		document := &models.Document{PatientID: 1, FileName: `C:\scans\referral.txt`, Category: models.DocumentReferralLetter, UploadedBy: 1}
+		require.NoError(t, documentService.UploadDocument(document, bytes.NewReader(pdf)))
+
+		sum := sha256.Sum256(pdf)
+		assert.Equal(t, "application/pdf", document.ContentType)
+		assert.Equal(t, int64(len(pdf)), document.SizeBytes)
+		assert.Equal(t, hex.EncodeToString(sum[:]), document.SHA256)
+		assert.Equal(t, "referral.txt", document.FileName)
+		assert.Equal(t, "referral", document.Title)
+		assert.Equal(t, uint(1), document.TenantID)
+
+		_, content, err := documentService.OpenDocument(document.ID)
+		require.NoError(t, err)
+		downloaded, err := io.ReadAll(content)
+		content.Close()
+		require.NoError(t, err)
+		assert.Equal(t, pdf, downloaded)
+
+		documents, err := documentService.GetPatientDocuments(1, models.DocumentReferralLetter)
+		require.NoError(t, err)
+		assert.Len(t, documents, 1)
+		documents, err = documentService.GetPatientDocuments(1, models.DocumentIdentification)
+		require.NoError(t, err)
+		assert.Empty(t, documents)
+	})
+
+	t.Run("Rejects disallowed, empty and oversized uploads", func(t *testing.T) {
+		html := &models.Document{PatientID: 1, Title: "Page"}
+		assert.ErrorIs(t, documentService.UploadDocument(html, strings.NewReader("<html><script>alert(1)</script></html>")), services.ErrDocumentType)
+
+		empty := &models.Document{PatientID: 1, Title: "Empty"}
+		assert.ErrorIs(t, documentService.UploadDocument(empty, strings.NewReader("")), services.ErrDocumentEmpty)
+
+		large := &models.Document{PatientID: 1, Title: "Large"}
+		content := append(append([]byte{}, pdf...), bytes.Repeat([]byte("x"), 2048)...)
+		assert.ErrorIs(t, documentService.UploadDocument(large, bytes.NewReader(content)), services.ErrDocumentTooLarge)
+
+		// Only the first upload's blob is on disk.
+		var blobs int
+		filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+			if err == nil && !info.IsDir() {
+				blobs++
+			}
+			return nil
+		})
+		assert.Equal(t, 1, blobs)
+
+		badCategory := &models.Document{PatientID: 1, Title: "Scan", Category: "xray"}
+		assert.Error(t, documentService.UploadDocument(badCategory, bytes.NewReader(pdf)))
+		missingPatient := &models.Document{PatientID: 99, Title: "Scan"}
+		assert.Error(t, documentService.UploadDocument(missingPatient, bytes.NewReader(pdf)))
+	})
+
+	t.Run("Download detects tampered content", func(t *testing.T) {
+		document := &models.Document{PatientID: 1, Title: "ID card", Category: models.DocumentIdentification}
+		require.NoError(t, documentService.UploadDocument(document, bytes.NewReader(pdf)))
+		require.NoError(t, store.Put(document.StorageKey, bytes.NewReader(append([]byte{}, pdf[:len(pdf)-2]...)), "application/pdf"))
+
+		_, content, err := documentService.OpenDocument(document.ID)
+		require.NoError(t, err)
+		_, err = io.ReadAll(content)
+		content.Close()
+		assert.ErrorIs(t, err, services.ErrDocumentChecksum)
+	})
+
+	t.Run("Documents are scoped to the tenant", func(t *testing.T) {
+		documents, err := documentService.GetPatientDocuments(1, "")
+		require.NoError(t, err)
+		require.NotEmpty(t, documents)
+
+		other := services.NewDocumentService(repository.NewDocumentRepository(db), repository.NewPatientRepository(db), store, 1024).WithTenant(2)
+		_, err = other.GetDocumentByID(documents[0].ID)
+		assert.ErrorIs(t, err, services.ErrDocumentNotFound)
+		_, _, err = other.OpenDocument(documents[0].ID)
+		assert.ErrorIs(t, err, services.ErrDocumentNotFound)
+	})
+
+	t.Run("Update and delete", func(t *testing.T) {
+		documents, err := documentService.GetPatientDocuments(1, "")
+		require.NoError(t, err)
+		document := &models.Document{ID: documents[0].ID, Title: "Renamed", Category: models.DocumentImagingReport}
+		require.NoError(t, documentService.UpdateDocument(document))
+		assert.Equal(t, "Renamed", document.Title)
+		assert.NotEmpty(t, document.SHA256)
+
+		require.NoError(t, documentService.DeleteDocument(document.ID))
+		_, err = documentService.GetDocumentByID(document.ID)
+		assert.ErrorIs(t, err, services.ErrDocumentNotFound)
+	})
+}
@@ -0,0 +1,101 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"healthcare-portal/internal/models"
+	"healthcare-portal/internal/repository"
+	"healthcare-portal/internal/services"
+)
+
+func TestEncounterService(t *testing.T) {
+	db := setupSchedulingDB(t)
+	createPatients(t, db, 1)
+	appointmentService := newAppointmentService(db).WithTenant(1)
+	encounterService := services.NewEncounterService(
+		repository.NewEncounterRepository(db),
+		repository.NewAppointmentRepository(db),
+		repository.NewPatientRepository(db),
+	).WithTenant(1)
+
+	doctor := &models.User{Email: "doc@example.com", Password: "x", Name: "Dr. Who", Role: models.RoleDoctor, IsActive: true}
+	colleague := &models.User{Email: "colleague@example.com", Password: "x", Name: "Dr. Other", Role: models.RoleDoctor, IsActive: true}
+	require.NoError(t, db.Create(doctor).Error)
+	require.NoError(t, db.Create(colleague).Error)
+
+	book := func(date time.Time, at string) *models.Appointment {
+		appointment := &models.Appointment{PatientID: 1, DoctorID: doctor.ID, Date: date, Time: at, Status: models.StatusScheduled}
+		require.NoError(t, appointmentService.CreateAppointment(appointment))
+		return appointment
+	}
+	later := book(time.Date(2025, 3, 12, 0, 0, 0, 0, time.UTC), "09:00")
+	earlier := book(time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC), "14:00")
+
+	var encounter *models.Encounter
+
+	t.Run("Only the treating doctor can document", func(t *testing.T) {
+		_, err := encounterService.CreateEncounter(later.ID, colleague.ID, services.SOAPNote{})
+		assert.ErrorIs(t, err, services.ErrNotTreatingDoctor)
+
+		encounter, err = encounterService.CreateEncounter(later.ID, doctor.ID, services.SOAPNote{Subjective: "Cough for 3 days"})
+		require.NoError(t, err)
+		assert.Equal(t, models.EncounterDraft, encounter.Status)
+		assert.Equal(t, uint(1), encounter.PatientID)
+
+		_, err = encounterService.CreateEncounter(later.ID, doctor.ID, services.SOAPNote{})
+		assert.Error(t, err)
+	})
+
+	t.Run("Sign requires an assessment and locks the note", func(t *testing.T) {
+		_, err := encounterService.SignEncounter(encounter.ID, doctor.ID)
+		assert.Error(t, err)
+
+		_, err = encounterService.UpdateEncounter(encounter.ID, doctor.ID, services.SOAPNote{
+			Subjective: "Cough for 3 days",
+			Objective:  "Chest clear",
+			Assessment: "Viral URTI",
+			Plan:       "Fluids, review in a week",
+		})
+		require.NoError(t, err)
+
+		_, err = encounterService.SignEncounter(encounter.ID, colleague.ID)
+		assert.ErrorIs(t, err, services.ErrNotTreatingDoctor)
+
+		signed, err := encounterService.SignEncounter(encounter.ID, doctor.ID)
+		require.NoError(t, err)
+		assert.True(t, signed.IsSigned())
+		assert.NotNil(t, signed.SignedAt)
+
+		_, err = encounterService.UpdateEncounter(encounter.ID, doctor.ID, services.SOAPNote{Assessment: "Changed"})
+		assert.ErrorIs(t, err, services.ErrEncounterLocked)
+	})
+
+	t.Run("Addenda after signing", func(t *testing.T) {
+		_, err := encounterService.AddAddendum(encounter.ID, colleague.ID, "Swab came back negative")
+		require.NoError(t, err)
+
+		stored, err := encounterService.GetEncounterByID(encounter.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "Viral URTI", stored.Assessment)
+		require.Len(t, stored.Addenda, 1)
+		assert.Equal(t, colleague.ID, stored.Addenda[0].AuthorID)
+	})
+
+	t.Run("Timeline is chronological", func(t *testing.T) {
+		draft, err := encounterService.CreateEncounter(earlier.ID, doctor.ID, services.SOAPNote{})
+		require.NoError(t, err)
+
+		_, err = encounterService.AddAddendum(draft.ID, doctor.ID, "Too early")
+		assert.ErrorIs(t, err, services.ErrEncounterNotSigned)
+
+		timeline, err := encounterService.GetPatientTimeline(1)
+		require.NoError(t, err)
+		require.Len(t, timeline, 2)
+		assert.Equal(t, earlier.ID, timeline[0].AppointmentID)
+		assert.Equal(t, later.ID, timeline[1].AppointmentID)
+	})
+}
@@ -0,0 +1,178 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"healthcare-portal/internal/models"
+	"healthcare-portal/internal/repository"
+	"healthcare-portal/internal/services"
+)
+
+func loadImmunizationSchedule(t *testing.T) *services.ImmunizationSchedule {
+	schedule, err := services.LoadImmunizationSchedule("../data/immunization_schedule.json")
+	require.NoError(t, err)
+	return schedule
+}
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func forecastByCode(forecast []models.VaccineForecast) map[string]models.VaccineForecast {
+	byCode := make(map[string]models.VaccineForecast, len(forecast))
+	for _, f := range forecast {
+		byCode[f.VaccineCode] = f
+	}
+	return byCode
+}
+
+func TestParseAgeInterval(t *testing.T) {
+	interval, err := services.ParseAgeInterval("4y6m")
+	require.NoError(t, err)
+	assert.Equal(t, date(2028, time.July, 1), interval.From(date(2024, time.January, 1)))
+
+	interval, err = services.ParseAgeInterval("6w")
+	require.NoError(t, err)
+	assert.Equal(t, date(2024, time.February, 12), interval.From(date(2024, time.January, 1)))
+
+	for _, bad := range []string{"", "6x", "m2"} {
+		_, err := services.ParseAgeInterval(bad)
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestImmunizationForecast(t *testing.T) {
+	schedule := loadImmunizationSchedule(t)
+
+	t.Run("Infant", func(t *testing.T) {
+		dob := date(2024, time.January, 1)
+		asOf := date(2024, time.March, 15)
+
+		forecast := forecastByCode(schedule.Forecast(dob, nil, asOf))
+		assert.Equal(t, models.VaccineOverdue, forecast["HepB"].Status)
+		assert.Equal(t, 1, forecast["HepB"].DoseNumber)
+		assert.Equal(t, models.VaccineDue, forecast["DTaP"].Status)
+		assert.Equal(t, date(2024, time.March, 1), *forecast["DTaP"].DueDate)
+		assert.Equal(t, models.VaccineUpcoming, forecast["MMR"].Status)
+		assert.Equal(t, models.VaccineUpcoming, forecast["Flu"].Status)
+		assert.Equal(t, date(2024, time.July, 1), *forecast["Flu"].DueDate)
+
+		given := []models.Immunization{{VaccineCode: "hepb", AdministeredAt: dob}}
+		forecast = forecastByCode(schedule.Forecast(dob, given, asOf))
+		assert.Equal(t, models.VaccineDue, forecast["HepB"].Status)
+		assert.Equal(t, 2, forecast["HepB"].DoseNumber)
+		assert.Equal(t, 1, forecast["HepB"].DosesGiven)
+	})
+
+	t.Run("Adult", func(t *testing.T) {
+		dob := date(1970, time.January, 1)
+		asOf := date(2024, time.June, 1)
+		given := []models.Immunization{
+			{VaccineCode: "MMR", AdministeredAt: date(1971, time.January, 15)},
+			{VaccineCode: "MMR", AdministeredAt: date(1974, time.February, 1)},
+			{VaccineCode: "Tdap", AdministeredAt: date(1981, time.June, 1)},
+			{VaccineCode: "Flu", AdministeredAt: date(2023, time.October, 1)},
+		}
+
+		forecast := forecastByCode(schedule.Forecast(dob, given, asOf))
+		// Childhood series that were never finished age out.
+		assert.NotContains(t, forecast, "RV")
+		assert.NotContains(t, forecast, "DTaP")
+		assert.NotContains(t, forecast, "HPV")
+
+		assert.Equal(t, models.VaccineComplete, forecast["MMR"].Status)
+		assert.Nil(t, forecast["MMR"].DueDate)
+
+		// Tdap boosters fall due ten years after the last dose.
+		assert.Equal(t, models.VaccineOverdue, forecast["Tdap"].Status)
+		assert.Equal(t, date(1991, time.June, 1), *forecast["Tdap"].DueDate)
+		assert.Equal(t, models.VaccineUpcoming, forecast["Flu"].Status)
+		assert.Equal(t, date(2024, time.October, 1), *forecast["Flu"].DueDate)
+		assert.Equal(t, models.VaccineOverdue, forecast["Zoster"].Status)
+	})
+
+	t.Run("Catch-up respects minimum interval", func(t *testing.T) {
+		dob := date(1970, time.January, 1)
+		given := []models.Immunization{{VaccineCode: "Zoster", AdministeredAt: date(2024, time.May, 1)}}
+
+		forecast := forecastByCode(schedule.Forecast(dob, given, date(2024, time.June, 1)))
+		zoster := forecast["Zoster"]
+		assert.Equal(t, models.VaccineUpcoming, zoster.Status)
+		assert.Equal(t, date(2024, time.June, 26), *zoster.DueDate)
+		assert.True(t, zoster.OverdueDate.After(*zoster.DueDate))
+	})
+}
+
+func TestImmunizationService(t *testing.T) {
+	db := setupSchedulingDB(t)
+	createPatients(t, db, 2)
+	require.NoError(t, db.Model(&models.Patient{}).Where("id = ?", 1).Update("date_of_birth", date(2024, time.January, 1)).Error)
+	nurse := &models.User{Email: "nurse@example.com", Password: "x", Name: "Nurse Joy", Role: models.RoleNurse, IsActive: true}
+	require.NoError(t, db.Create(nurse).Error)
+
+	immunizationService := services.NewImmunizationService(repository.NewImmunizationRepository(db), repository.NewPatientRepository(db), loadImmunizationSchedule(t)).WithTenant(1)
+
+	t.Run("Doses are numbered in order", func(t *testing.T) {
+		for _, day := range []int{1, 2} {
+			dose := &models.Immunization{PatientID: 1, VaccineCode: "hepb", AdministeredAt: date(2024, time.Month(day), 1), Source: models.ImmunizationHistorical}
+			require.NoError(t, immunizationService.RecordImmunization(dose))
+			assert.Equal(t, day, dose.DoseNumber)
+			assert.Equal(t, "HepB", dose.VaccineCode)
+			assert.Equal(t, "Hepatitis B", dose.VaccineName)
+		}
+
+		given, err := immunizationService.GetPatientImmunizations(1)
+		require.NoError(t, err)
+		assert.Len(t, given, 2)
+	})
+
+	t.Run("Administered doses need lot and administering user", func(t *testing.T) {
+		dose := &models.Immunization{PatientID: 1, VaccineCode: "DTaP", AdministeredAt: date(2024, time.March, 1), Site: models.SiteLeftThigh}
+		assert.EqualError(t, immunizationService.RecordImmunization(dose), "lot number is required")
+
+		dose.LotNumber = "AB123"
+		assert.EqualError(t, immunizationService.RecordImmunization(dose), "administered by is required")
+
+		dose.AdministeredBy = &nurse.ID
+		expired := date(2024, time.February, 1)
+		dose.ExpirationDate = &expired
+		assert.EqualError(t, immunizationService.RecordImmunization(dose), "vaccine lot was expired when administered")
+
+		dose.ExpirationDate = nil
+		require.NoError(t, immunizationService.RecordImmunization(dose))
+		assert.Equal(t, models.ImmunizationAdministered, dose.Source)
+
+		found, err := immunizationService.GetImmunizationByID(dose.ID)
+		require.NoError(t, err)
+		require.NotNil(t, found.AdministeredByUser)
+		assert.Equal(t, "Nurse Joy", found.AdministeredByUser.Name)
+	})
+
+	t.Run("Rejects invalid doses", func(t *testing.T) {
+		unknown := &models.Immunization{PatientID: 1, VaccineCode: "YF", AdministeredAt: date(2024, time.March, 1), Source: models.ImmunizationHistorical}
+		assert.Error(t, immunizationService.RecordImmunization(unknown))
+		unknown.VaccineName = "Yellow fever"
+		assert.NoError(t, immunizationService.RecordImmunization(unknown))
+
+		beforeBirth := &models.Immunization{PatientID: 1, VaccineCode: "HepB", AdministeredAt: date(2023, time.December, 1), Source: models.ImmunizationHistorical}
+		assert.Error(t, immunizationService.RecordImmunization(beforeBirth))
+
+		badSite := &models.Immunization{PatientID: 1, VaccineCode: "HepB", AdministeredAt: date(2024, time.March, 1), Source: models.ImmunizationHistorical, Site: "buttock"}
+		assert.Error(t, immunizationService.RecordImmunization(badSite))
+	})
+
+	t.Run("Forecast uses recorded doses", func(t *testing.T) {
+		forecast, err := immunizationService.GetPatientForecast(1, date(2024, time.March, 15))
+		require.NoError(t, err)
+		byCode := forecastByCode(forecast)
+		assert.Equal(t, 3, byCode["HepB"].DoseNumber)
+		assert.Equal(t, 2, byCode["DTaP"].DoseNumber)
+
+		_, err = immunizationService.GetPatientForecast(2, date(2024, time.March, 15))
+		assert.Error(t, err, "patient without a date of birth")
+	})
+}
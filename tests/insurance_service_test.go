@@ -0,0 +1,159 @@
+package tests
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"healthcare-portal/internal/models"
+	"healthcare-portal/internal/repository"
+	"healthcare-portal/internal/services"
+)
+
+// failingChecker stands in for a clearinghouse that is down.
+type failingChecker struct{}
+
+func (failingChecker) Name() string { return "clearinghouse" }
+
+func (failingChecker) Check(services.EligibilityRequest) (*services.EligibilityResult, error) {
+	return nil, errors.New("payer did not respond")
+}
+
+func newInsuranceService(db *gorm.DB, checker services.EligibilityChecker) services.InsuranceService {
+	return services.NewInsuranceService(
+		repository.NewInsuranceRepository(db),
+		repository.NewPatientRepository(db),
+		repository.NewAppointmentRepository(db),
+		checker,
+	)
+}
+
+func TestInsuranceService(t *testing.T) {
+	db := setupSchedulingDB(t)
+	createPatients(t, db, 1)
+	require.NoError(t, db.Create(&models.User{Email: "doc@example.com", Password: "x", Name: "Doc", Role: models.RoleDoctor, IsActive: true}).Error)
+	insurance := newInsuranceService(db, services.LocalEligibilityChecker{}).WithTenant(1)
+
+	payer := &models.InsurancePayer{Name: " Acme Health ", PayerCode: "acme1", IsActive: true}
+	require.NoError(t, insurance.CreatePayer(payer))
+	assert.Equal(t, "Acme Health", payer.Name)
+	assert.Equal(t, "ACME1", payer.PayerCode)
+
+	ppo := &models.InsurancePlan{PayerID: payer.ID, Name: "Acme PPO", PlanType: "PPO", CopayCents: 2500, IsActive: true}
+	require.NoError(t, insurance.CreatePlan(ppo))
+	hmo := &models.InsurancePlan{PayerID: payer.ID, Name: "Acme HMO", PlanType: models.PlanHMO, IsActive: true}
+	require.NoError(t, insurance.CreatePlan(hmo))
+
+	t.Run("Plans are validated and belong to a payer", func(t *testing.T) {
+		assert.Error(t, insurance.CreatePlan(&models.InsurancePlan{PayerID: payer.ID, Name: "Odd", PlanType: "cash"}))
+		assert.ErrorIs(t, insurance.CreatePlan(&models.InsurancePlan{PayerID: 999, Name: "Lost", PlanType: models.PlanPPO}), services.ErrPayerNotFound)
+
+		found, err := insurance.GetPayerByID(payer.ID)
+		require.NoError(t, err)
+		require.Len(t, found.Plans, 2)
+		assert.Equal(t, "Acme HMO", found.Plans[0].Name)
+
+		_, err = newInsuranceService(db, services.LocalEligibilityChecker{}).WithTenant(2).GetPayerByID(payer.ID)
+		assert.ErrorIs(t, err, services.ErrPayerNotFound)
+	})
+
+	var primary models.Coverage
+	t.Run("Coverages are validated and ordered by priority", func(t *testing.T) {
+		primary = models.Coverage{PatientID: 1, PlanID: ppo.ID, MemberID: " M123 ", GroupNumber: "G9", EffectiveFrom: date(2026, 1, 1)}
+		require.NoError(t, insurance.AddCoverage(&primary))
+		assert.Equal(t, "M123", primary.MemberID)
+		assert.Equal(t, 1, primary.Priority)
+		assert.Equal(t, models.SubscriberSelf, primary.SubscriberRelationship)
+
+		spouseDOB := date(1979, 3, 3)
+		secondary := models.Coverage{PatientID: 1, PlanID: hmo.ID, MemberID: "S456", Priority: 2,
+			SubscriberRelationship: models.SubscriberSpouse, EffectiveFrom: date(2026, 1, 1)}
+		assert.Error(t, insurance.AddCoverage(&secondary), "a spouse subscriber needs a name and date of birth")
+		secondary.SubscriberName = "Sam Spouse"
+		secondary.SubscriberDateOfBirth = &spouseDOB
+		require.NoError(t, insurance.AddCoverage(&secondary))
+
+		clash := models.Coverage{PatientID: 1, PlanID: hmo.ID, MemberID: "X1", EffectiveFrom: date(2026, 6, 1)}
+		assert.ErrorIs(t, insurance.AddCoverage(&clash), services.ErrCoveragePriorityUsed)
+
+		ended := date(2025, 12, 31)
+		old := models.Coverage{PatientID: 1, PlanID: hmo.ID, MemberID: "OLD", EffectiveFrom: date(2024, 1, 1), EffectiveTo: &ended}
+		require.NoError(t, insurance.AddCoverage(&old))
+
+		backwards := models.Coverage{PatientID: 1, PlanID: hmo.ID, MemberID: "B", Priority: 3, EffectiveFrom: date(2026, 1, 1), EffectiveTo: &ended}
+		assert.Error(t, insurance.AddCoverage(&backwards))
+
+		coverages, err := insurance.GetPatientCoverages(1, nil)
+		require.NoError(t, err)
+		require.Len(t, coverages, 3)
+		assert.Equal(t, []string{"M123", "OLD", "S456"}, []string{coverages[0].MemberID, coverages[1].MemberID, coverages[2].MemberID})
+		require.NotNil(t, coverages[0].Plan)
+		assert.Equal(t, "Acme Health", coverages[0].Plan.Payer.Name)
+
+		day := date(2026, 3, 1)
+		inForce, err := insurance.GetPatientCoverages(1, &day)
+		require.NoError(t, err)
+		assert.Len(t, inForce, 2)
+	})
+
+	appointment := &models.Appointment{PatientID: 1, DoctorID: 1, Date: date(2026, 3, 2), Time: "09:00", Status: models.StatusScheduled}
+	require.NoError(t, db.Create(appointment).Error)
+
+	t.Run("Eligibility checks are recorded against the appointment", func(t *testing.T) {
+		checks, err := insurance.CheckAppointmentEligibility(appointment.ID, 1)
+		require.NoError(t, err)
+		require.Len(t, checks, 2)
+		assert.Equal(t, primary.ID, checks[0].CoverageID)
+		assert.Equal(t, models.EligibilityActive, checks[0].Status)
+		require.NotNil(t, checks[0].CopayCents)
+		assert.Equal(t, int64(2500), *checks[0].CopayCents)
+		assert.Equal(t, "local", checks[0].Source)
+
+		hmo.IsActive = false
+		require.NoError(t, insurance.UpdatePlan(hmo))
+		checks, err = insurance.CheckAppointmentEligibility(appointment.ID, 1)
+		require.NoError(t, err)
+		assert.Equal(t, models.EligibilityInactive, checks[1].Status)
+
+		checks, err = newInsuranceService(db, failingChecker{}).WithTenant(1).CheckAppointmentEligibility(appointment.ID, 1)
+		require.NoError(t, err)
+		assert.Equal(t, models.EligibilityError, checks[0].Status)
+		assert.Equal(t, "payer did not respond", checks[0].Message)
+
+		history, err := insurance.GetAppointmentEligibility(appointment.ID)
+		require.NoError(t, err)
+		assert.Len(t, history, 6)
+		assert.Equal(t, "clearinghouse", history[0].Source)
+		require.NotNil(t, history[0].Coverage)
+	})
+
+	t.Run("Appointments outside any coverage cannot be checked", func(t *testing.T) {
+		early := &models.Appointment{PatientID: 1, DoctorID: 1, Date: date(2023, 5, 1), Time: "09:00", Status: models.StatusScheduled}
+		require.NoError(t, db.Create(early).Error)
+		_, err := insurance.CheckAppointmentEligibility(early.ID, 1)
+		assert.ErrorIs(t, err, services.ErrNoCoverage)
+
+		_, err = insurance.CheckAppointmentEligibility(9999, 1)
+		assert.ErrorIs(t, err, services.ErrAppointmentNotFound)
+	})
+
+	t.Run("Coverages can be ended and removed", func(t *testing.T) {
+		ended := date(2026, 2, 28)
+		update := primary
+		update.Plan = nil
+		update.EffectiveTo = &ended
+		require.NoError(t, insurance.UpdateCoverage(&update))
+
+		replacement := models.Coverage{PatientID: 1, PlanID: ppo.ID, MemberID: "NEW", EffectiveFrom: date(2026, 3, 1)}
+		require.NoError(t, insurance.AddCoverage(&replacement))
+
+		_, err := insurance.GetCoverageByID(2, replacement.ID)
+		assert.ErrorIs(t, err, services.ErrCoverageNotFound)
+		require.NoError(t, insurance.DeleteCoverage(1, replacement.ID))
+		_, err = insurance.GetCoverageByID(1, replacement.ID)
+		assert.ErrorIs(t, err, services.ErrCoverageNotFound)
+	})
+}
@@ -0,0 +1,109 @@
+package tests
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"healthcare-portal/internal/models"
+	"healthcare-portal/internal/repository"
+	"healthcare-portal/internal/services"
+)
+
+func loadInteractionChecker(t *testing.T) *services.InteractionChecker {
+	rules, err := services.LoadInteractionRules("../data/interaction_rules.json")
+	require.NoError(t, err)
+	return services.NewInteractionChecker(rules)
+}
+
+func TestInteractionChecker(t *testing.T) {
+	checker := loadInteractionChecker(t)
+	active := func(drug string) models.MedicationStatement {
+		return models.MedicationStatement{Drug: drug, Status: models.MedicationActive}
+	}
+	allergy := func(substance string, severity models.AllergySeverity) models.Allergy {
+		return models.Allergy{Substance: substance, Severity: severity, Status: models.AllergyActive}
+	}
+
+	t.Run("Allergy to the drug class", func(t *testing.T) {
+		warnings := checker.Check("Amoxicillin", []models.Allergy{allergy("Penicillin", models.SeveritySevere)}, nil)
+		require.Len(t, warnings, 1)
+		assert.Equal(t, models.InteractionDrugAllergy, warnings[0].Type)
+		assert.Equal(t, models.InteractionContraindicated, warnings[0].Severity)
+	})
+
+	t.Run("Cross-sensitivity", func(t *testing.T) {
+		warnings := checker.Check("Cephalexin", []models.Allergy{allergy("Penicillin", models.SeverityMild)}, nil)
+		require.Len(t, warnings, 1)
+		assert.Equal(t, models.InteractionCrossSensitivity, warnings[0].Type)
+		assert.Equal(t, models.InteractionModerate, warnings[0].Severity)
+	})
+
+	t.Run("Inactive allergies and unrelated drugs are ignored", func(t *testing.T) {
+		resolved := allergy("Penicillin", models.SeveritySevere)
+		resolved.Status = models.AllergyResolved
+		assert.Empty(t, checker.Check("Amoxicillin", []models.Allergy{resolved}, nil))
+		assert.Empty(t, checker.Check("Penicillamine", []models.Allergy{allergy("Penicillin", models.SeveritySevere)}, nil))
+	})
+
+	t.Run("Drug-drug interactions in either order, most severe first", func(t *testing.T) {
+		warnings := checker.Check("Ibuprofen", nil, []models.MedicationStatement{active("Lisinopril"), active("Warfarin")})
+		require.Len(t, warnings, 2)
+		assert.Equal(t, "Warfarin", warnings[0].Conflict)
+		assert.Equal(t, models.InteractionMajor, warnings[0].Severity)
+		assert.Equal(t, "Lisinopril", warnings[1].Conflict)
+
+		warnings = checker.Check("Warfarin", nil, []models.MedicationStatement{active("Naproxen 250 mg")})
+		require.Len(t, warnings, 1)
+		assert.Equal(t, models.InteractionDrugDrug, warnings[0].Type)
+	})
+
+	t.Run("Stopped medications and duplicates", func(t *testing.T) {
+		stopped := active("Warfarin")
+		stopped.Status = models.MedicationStopped
+		assert.Empty(t, checker.Check("Ibuprofen", nil, []models.MedicationStatement{stopped}))
+
+		warnings := checker.Check("metformin", nil, []models.MedicationStatement{active("Metformin")})
+		require.Len(t, warnings, 1)
+		assert.Equal(t, models.InteractionDuplicateTherapy, warnings[0].Type)
+	})
+}
+
+func TestPrescriptionOverride(t *testing.T) {
+	db := setupSchedulingDB(t)
+	createPatients(t, db, 1)
+	doctor := &models.User{Email: "doc@example.com", Password: "x", Name: "Dr. Who", Role: models.RoleDoctor, IsActive: true}
+	require.NoError(t, db.Create(doctor).Error)
+	require.NoError(t, db.Create(&models.Allergy{PatientID: 1, Substance: "Penicillin", Category: models.AllergyMedication, Severity: models.SeveritySevere, Status: models.AllergyActive}).Error)
+
+	medicationService := services.NewMedicationService(
+		repository.NewMedicationRepository(db),
+		repository.NewPatientRepository(db),
+		repository.NewEncounterRepository(db),
+		repository.NewAllergyRepository(db),
+		repository.NewTenantRepository(db),
+		loadInteractionChecker(t),
+	).WithTenant(1)
+
+	prescription := &models.Prescription{PatientID: 1, DoctorID: doctor.ID, Drug: "Amoxicillin", Dose: "500 mg", Frequency: "three times daily"}
+
+	warnings, err := medicationService.CheckPrescription(prescription)
+	require.NoError(t, err)
+	require.Len(t, warnings, 1)
+
+	err = medicationService.CreatePrescription(prescription)
+	var warningsErr *services.InteractionWarningsError
+	require.True(t, errors.As(err, &warningsErr))
+	assert.Len(t, warningsErr.Warnings, 1)
+
+	prescription.OverrideReason = "Tolerated amoxicillin in 2023 under observation"
+	require.NoError(t, medicationService.CreatePrescription(prescription))
+
+	stored, err := medicationService.GetPrescriptionByID(prescription.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Tolerated amoxicillin in 2023 under observation", stored.OverrideReason)
+	require.Len(t, stored.Warnings, 1)
+	assert.Equal(t, "Penicillin", stored.Warnings[0].Conflict)
+}
@@ -0,0 +1,170 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"healthcare-portal/internal/models"
+	"healthcare-portal/internal/repository"
+	"healthcare-portal/internal/services"
+)
+
+func TestParseReferenceRange(t *testing.T) {
+	cases := []struct {
+		text      string
+		low, high *float64
+		numeric   bool
+	}{
+		{"3.5-5.1", floatRef(3.5), floatRef(5.1), true},
+		{"3.5 - 5.1", floatRef(3.5), floatRef(5.1), true},
+		{"-2-2", floatRef(-2), floatRef(2), true},
+		{"<200", nil, floatRef(200), true},
+		{">= 60", floatRef(60), nil, true},
+		{"Negative", nil, nil, false},
+		{"", nil, nil, false},
+	}
+	for _, tc := range cases {
+		low, high, numeric := services.ParseReferenceRange(tc.text)
+		assert.Equal(t, tc.low, low, tc.text)
+		assert.Equal(t, tc.high, high, tc.text)
+		assert.Equal(t, tc.numeric, numeric, tc.text)
+	}
+}
+
+func floatRef(v float64) *float64 { return &v }
+
+func TestLabService(t *testing.T) {
+	db := setupSchedulingDB(t)
+	createPatients(t, db, 1)
+	doctor := &models.User{Email: "doc@example.com", Password: "x", Name: "Dr. Who", Role: models.RoleDoctor, IsActive: true}
+	other := &models.User{Email: "other@example.com", Password: "x", Name: "Dr. Other", Role: models.RoleDoctor, IsActive: true}
+	require.NoError(t, db.Create(doctor).Error)
+	require.NoError(t, db.Create(other).Error)
+
+	labService := services.NewLabService(repository.NewLabRepository(db), repository.NewPatientRepository(db), repository.NewEncounterRepository(db)).WithTenant(1)
+
+	order := func(name string) *models.LabOrder {
+		o := &models.LabOrder{PatientID: 1, DoctorID: doctor.ID, TestName: name}
+		require.NoError(t, labService.CreateOrder(o))
+		return o
+	}
+
+	t.Run("Results are flagged", func(t *testing.T) {
+		bmp := order("Basic metabolic panel")
+		assert.Equal(t, models.LabOrdered, bmp.Status)
+		assert.Equal(t, models.LabRoutine, bmp.Priority)
+
+		resulted, err := labService.EnterResults(bmp.ID, 0, []models.LabResult{
+			{Analyte: "Sodium", Value: "140", Unit: "mmol/L", ReferenceRange: "135-145"},
+			{Analyte: "Potassium", Value: "6.8", Unit: "mmol/L", ReferenceRange: "3.5-5.1", CriticalHigh: floatRef(6.5)},
+			{Analyte: "Glucose", Value: "65", Unit: "mg/dL", ReferenceRange: "70-99"},
+			{Analyte: "Creatinine", Value: "1.4", Unit: "mg/dL", ReferenceRange: "<1.3"},
+		}, true)
+		require.NoError(t, err)
+		assert.Equal(t, models.LabResulted, resulted.Status)
+		assert.True(t, resulted.Abnormal)
+		require.Len(t, resulted.Results, 4)
+		assert.Equal(t, models.LabFlagNormal, resulted.Results[0].Flag)
+		assert.Equal(t, models.LabFlagCriticalHigh, resulted.Results[1].Flag)
+		assert.Equal(t, models.LabFlagLow, resulted.Results[2].Flag)
+		assert.Equal(t, models.LabFlagHigh, resulted.Results[3].Flag)
+
+		_, err = labService.EnterResults(bmp.ID, 0, []models.LabResult{{Analyte: "Chloride", Value: "101"}}, false)
+		assert.Error(t, err)
+	})
+
+	t.Run("Qualitative results", func(t *testing.T) {
+		ua := order("Urinalysis")
+		resulted, err := labService.EnterResults(ua.ID, 0, []models.LabResult{
+			{Analyte: "Nitrite", Value: "negative", ReferenceRange: "Negative"},
+			{Analyte: "Leukocyte esterase", Value: "Positive", ReferenceRange: "Negative"},
+		}, false)
+		require.NoError(t, err)
+		assert.Equal(t, models.LabPartial, resulted.Status)
+		assert.Equal(t, models.LabFlagNormal, resulted.Results[0].Flag)
+		assert.Equal(t, models.LabFlagAbnormal, resulted.Results[1].Flag)
+	})
+
+	t.Run("Inbox and acknowledgement", func(t *testing.T) {
+		lipids := order("Lipid panel")
+		_, err := labService.EnterResults(lipids.ID, 0, []models.LabResult{{Analyte: "LDL", Value: "90", ReferenceRange: "<100"}}, true)
+		require.NoError(t, err)
+		order("Hemoglobin A1c")
+
+		inbox, err := labService.GetReviewInbox(doctor.ID)
+		require.NoError(t, err)
+		require.Len(t, inbox, 3)
+		assert.Equal(t, "Basic metabolic panel", inbox[0].TestName)
+		assert.Equal(t, "Urinalysis", inbox[1].TestName)
+		assert.Equal(t, "Lipid panel", inbox[2].TestName)
+
+		_, err = labService.AcknowledgeOrder(lipids.ID, other.ID)
+		assert.Error(t, err)
+		acknowledged, err := labService.AcknowledgeOrder(lipids.ID, doctor.ID)
+		require.NoError(t, err)
+		require.NotNil(t, acknowledged.ReviewedBy)
+		assert.Equal(t, doctor.ID, *acknowledged.ReviewedBy)
+
+		inbox, err = labService.GetReviewInbox(doctor.ID)
+		require.NoError(t, err)
+		assert.Len(t, inbox, 2)
+
+		// More results for a partial order put it back in the inbox.
+		urinalysis := inbox[1]
+		_, err = labService.AcknowledgeOrder(urinalysis.ID, doctor.ID)
+		require.NoError(t, err)
+		_, err = labService.EnterResults(urinalysis.ID, 0, []models.LabResult{{Analyte: "Culture", Value: "No growth", ReferenceRange: "No growth"}}, true)
+		require.NoError(t, err)
+		inbox, err = labService.GetReviewInbox(doctor.ID)
+		require.NoError(t, err)
+		assert.Len(t, inbox, 2)
+	})
+
+	t.Run("Values beyond the assay limits and with separators are compared", func(t *testing.T) {
+		cardiac := order("Cardiac panel")
+		resulted, err := labService.EnterResults(cardiac.ID, 0, []models.LabResult{
+			{Analyte: "Troponin I", Value: ">1000", Unit: "ng/L", ReferenceRange: "0-40", CriticalHigh: floatRef(100)},
+			{Analyte: "BNP", Value: "1,200", Unit: "pg/mL", ReferenceRange: "<100"},
+			{Analyte: "CRP", Value: "<0.01", Unit: "mg/dL", ReferenceRange: "<0.5"},
+			{Analyte: "eGFR", Value: ">60", Unit: "mL/min", ReferenceRange: ">=60"},
+			{Analyte: "Ferritin", Value: "<5", Unit: "ng/mL", ReferenceRange: "5-300"},
+			{Analyte: "D-dimer", Value: "<600", Unit: "ng/mL", ReferenceRange: "0-500"},
+			{Analyte: "Lactate", Value: "hemolyzed", Unit: "mmol/L", ReferenceRange: "0.5-2.2"},
+		}, true)
+		require.NoError(t, err)
+		require.Len(t, resulted.Results, 7)
+		flags := map[string]models.LabFlag{}
+		for _, result := range resulted.Results {
+			flags[result.Analyte] = result.Flag
+		}
+		assert.Equal(t, models.LabFlagCriticalHigh, flags["Troponin I"])
+		assert.Equal(t, models.LabFlagHigh, flags["BNP"])
+		assert.Equal(t, models.LabFlagNormal, flags["CRP"])
+		assert.Equal(t, models.LabFlagNormal, flags["eGFR"])
+		assert.Equal(t, models.LabFlagLow, flags["Ferritin"])
+		assert.Equal(t, models.LabFlagAbnormal, flags["D-dimer"], "below 600 may still be above the range")
+		assert.Equal(t, models.LabFlagAbnormal, flags["Lactate"], "unreadable values are sent for review")
+		require.NotNil(t, resulted.Results[1].NumericValue)
+		assert.Equal(t, 1200.0, *resulted.Results[1].NumericValue)
+	})
+
+	t.Run("Cancellation and validation", func(t *testing.T) {
+		cbc := order("Complete blood count")
+		_, err := labService.CancelOrder(cbc.ID, other.ID)
+		assert.Error(t, err)
+		cancelled, err := labService.CancelOrder(cbc.ID, doctor.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.LabCancelled, cancelled.Status)
+		_, err = labService.EnterResults(cbc.ID, 0, []models.LabResult{{Analyte: "WBC", Value: "7.0"}}, true)
+		assert.Error(t, err)
+
+		assert.Error(t, labService.CreateOrder(&models.LabOrder{PatientID: 42, DoctorID: doctor.ID, TestName: "TSH"}))
+		assert.Error(t, labService.CreateOrder(&models.LabOrder{PatientID: 1, DoctorID: doctor.ID, TestName: "TSH", Priority: "whenever"}))
+
+		tsh := order("TSH")
+		_, err = labService.EnterResults(tsh.ID, 0, []models.LabResult{{Analyte: "TSH"}}, true)
+		assert.Error(t, err)
+	})
+}
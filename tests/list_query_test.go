@@ -0,0 +1,175 @@
+package tests
+
+import (
+	"fmt"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"healthcare-portal/internal/models"
+	"healthcare-portal/internal/query"
+	"healthcare-portal/internal/repository"
+)
+
+func listRequest(t *testing.T, rawQuery string) query.Request {
+	values, err := url.ParseQuery(rawQuery)
+	require.NoError(t, err)
+	req, err := query.ParseRequest(values)
+	require.NoError(t, err)
+	return req
+}
+
+func TestListQuery(t *testing.T) {
+	db := setupSchedulingDB(t)
+	createPatients(t, db, 2)
+	for _, email := range []string{"doc1@example.com", "doc2@example.com"} {
+		require.NoError(t, db.Create(&models.User{Email: email, Password: "x", Name: email, Role: models.RoleDoctor, IsActive: true}).Error)
+	}
+
+	statuses := []models.AppointmentStatus{models.StatusScheduled, models.StatusCompleted, models.StatusCancelled}
+	for i := 0; i < 15; i++ {
+		appointment := &models.Appointment{
+			PatientID: uint(i%2 + 1),
+			DoctorID:  uint(i%2 + 1),
+			Date:      date(2026, 3, 1+i/3),
+			Time:      fmt.Sprintf("%02d:00", 9+i%3),
+			Status:    statuses[i%3],
+		}
+		require.NoError(t, db.Create(appointment).Error)
+	}
+	appointments := repository.NewAppointmentRepository(db).WithTenant(1)
+
+	// walk follows next cursors from the first page and returns every ID.
+	walk := func(rawQuery string) []uint {
+		ids := []uint{}
+		req := listRequest(t, rawQuery)
+		for pages := 0; pages < 20; pages++ {
+			page, err := appointments.List(req)
+			require.NoError(t, err)
+			for _, appointment := range page.Data {
+				ids = append(ids, appointment.ID)
+			}
+			if page.Meta.NextCursor == "" {
+				return ids
+			}
+			req.Cursor = page.Meta.NextCursor
+		}
+		t.Fatal("cursor pagination did not end")
+		return nil
+	}
+
+	t.Run("Parameters are parsed and checked", func(t *testing.T) {
+		_, err := query.ParseRequest(url.Values{"limit": {"0"}})
+		assert.ErrorIs(t, err, query.ErrInvalidQuery)
+
+		for _, rawQuery := range []string{"colour=red", "sort=password", "status=lost", "doctor_id=abc", "date_from=tomorrow", "date=2026-03-01", "cursor=nonsense"} {
+			_, err := appointments.List(listRequest(t, rawQuery))
+			assert.ErrorIs(t, err, query.ErrInvalidQuery, rawQuery)
+		}
+
+		page, err := appointments.List(listRequest(t, "limit=1000000"))
+		require.NoError(t, err)
+		assert.Equal(t, 100, page.Meta.Limit)
+		assert.Len(t, page.Data, 15)
+		assert.Empty(t, page.Meta.NextCursor)
+	})
+
+	t.Run("The default sort is newest first and preloads relations", func(t *testing.T) {
+		page, err := appointments.List(listRequest(t, "limit=4"))
+		require.NoError(t, err)
+		assert.Equal(t, int64(15), page.Meta.Total)
+		assert.Equal(t, "-date,-time", page.Meta.Sort)
+		require.Len(t, page.Data, 4)
+		assert.Equal(t, date(2026, 3, 5), page.Data[0].Date.UTC())
+		assert.Equal(t, "11:00", page.Data[0].Time)
+		assert.Equal(t, "10:00", page.Data[1].Time)
+		assert.NotNil(t, page.Data[0].Patient)
+		assert.NotNil(t, page.Data[0].Doctor)
+		assert.NotEmpty(t, page.Meta.NextCursor)
+		assert.Empty(t, page.Meta.PrevCursor)
+	})
+
+	t.Run("Cursors walk every row exactly once", func(t *testing.T) {
+		all, err := appointments.List(listRequest(t, "sort=status,-date&limit=100"))
+		require.NoError(t, err)
+		expected := []uint{}
+		for _, appointment := range all.Data {
+			expected = append(expected, appointment.ID)
+		}
+		require.Len(t, expected, 15)
+		assert.Equal(t, expected, walk("sort=status,-date&limit=4"))
+		assert.Equal(t, expected, walk("sort=status,-date&limit=1"))
+	})
+
+	t.Run("Previous cursors step back", func(t *testing.T) {
+		first, err := appointments.List(listRequest(t, "sort=doctor_id,time&limit=5"))
+		require.NoError(t, err)
+		second, err := appointments.List(listRequest(t, "sort=doctor_id,time&limit=5&cursor="+first.Meta.NextCursor))
+		require.NoError(t, err)
+		require.NotEmpty(t, second.Meta.PrevCursor)
+
+		back, err := appointments.List(listRequest(t, "sort=doctor_id,time&limit=5&cursor="+second.Meta.PrevCursor))
+		require.NoError(t, err)
+		assert.Equal(t, first.Data, back.Data)
+		assert.Empty(t, back.Meta.PrevCursor)
+		assert.Equal(t, first.Meta.NextCursor, back.Meta.NextCursor)
+	})
+
+	t.Run("Filters combine", func(t *testing.T) {
+		page, err := appointments.List(listRequest(t, "status=scheduled,completed&doctor_id=1&date_from=2026-03-02&date_to=2026-03-04"))
+		require.NoError(t, err)
+		assert.Equal(t, int64(len(page.Data)), page.Meta.Total)
+		require.NotEmpty(t, page.Data)
+		for _, appointment := range page.Data {
+			assert.Contains(t, []models.AppointmentStatus{models.StatusScheduled, models.StatusCompleted}, appointment.Status)
+			assert.Equal(t, uint(1), appointment.DoctorID)
+			assert.False(t, appointment.Date.Before(date(2026, 3, 2)))
+			assert.False(t, appointment.Date.After(date(2026, 3, 4)))
+		}
+	})
+
+	t.Run("A cursor only fits the list it came from", func(t *testing.T) {
+		page, err := appointments.List(listRequest(t, "status=scheduled&limit=2"))
+		require.NoError(t, err)
+		require.NotEmpty(t, page.Meta.NextCursor)
+
+		_, err = appointments.List(listRequest(t, "status=cancelled&limit=2&cursor="+page.Meta.NextCursor))
+		assert.ErrorIs(t, err, query.ErrInvalidQuery)
+		_, err = appointments.List(listRequest(t, "status=scheduled&sort=time&limit=2&cursor="+page.Meta.NextCursor))
+		assert.ErrorIs(t, err, query.ErrInvalidQuery)
+	})
+
+	t.Run("Page numbers still work", func(t *testing.T) {
+		page, err := appointments.List(listRequest(t, "sort=date,time&limit=4&page=4"))
+		require.NoError(t, err)
+		assert.Len(t, page.Data, 3)
+		assert.Equal(t, 4, page.Meta.Page)
+		assert.Empty(t, page.Meta.NextCursor)
+		assert.NotEmpty(t, page.Meta.PrevCursor)
+	})
+
+	t.Run("Lists are scoped to the tenant", func(t *testing.T) {
+		page, err := repository.NewAppointmentRepository(db).WithTenant(2).List(query.Request{})
+		require.NoError(t, err)
+		assert.Zero(t, page.Meta.Total)
+		assert.Empty(t, page.Data)
+	})
+
+	t.Run("Patients filter by gender and birth date", func(t *testing.T) {
+		patients := repository.NewPatientRepository(db).WithTenant(1)
+		require.NoError(t, patients.Create(&models.Patient{FirstName: "Ada", LastName: "Byron", Email: "ada@example.com", Phone: "555-1", Gender: "Female", DateOfBirth: date(1990, 12, 10)}))
+		require.NoError(t, patients.Create(&models.Patient{FirstName: "Alan", LastName: "Turing", Email: "alan@example.com", Phone: "555-2", Gender: "male", DateOfBirth: date(1985, 6, 23)}))
+
+		page, err := patients.List(listRequest(t, "gender=FEMALE&born_from=1990-12-10&born_to=1990-12-10"))
+		require.NoError(t, err)
+		require.Len(t, page.Data, 1)
+		assert.Equal(t, "Ada", page.Data[0].FirstName)
+
+		page, err = patients.List(listRequest(t, "born_from=1980-01-01&sort=-last_name"))
+		require.NoError(t, err)
+		require.Len(t, page.Data, 2)
+		assert.Equal(t, "Turing", page.Data[0].LastName)
+	})
+}
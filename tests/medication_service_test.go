@@ -0,0 +1,96 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"healthcare-portal/internal/models"
+	"healthcare-portal/internal/repository"
+	"healthcare-portal/internal/services"
+)
+
+func newMedicationService(db *gorm.DB) services.MedicationService {
+	return services.NewMedicationService(
+		repository.NewMedicationRepository(db),
+		repository.NewPatientRepository(db),
+		repository.NewEncounterRepository(db),
+		repository.NewAllergyRepository(db),
+		repository.NewTenantRepository(db),
+		nil,
+	)
+}
+
+func TestMedicationService(t *testing.T) {
+	db := setupSchedulingDB(t)
+	require.NoError(t, db.Create(&models.Tenant{Name: "Riverside Family Practice", Slug: "riverside", IsActive: true}).Error)
+	createPatients(t, db, 2)
+	doctor := &models.User{Email: "doc@example.com", Password: "x", Name: "Dr. Who", Role: models.RoleDoctor, IsActive: true}
+	require.NoError(t, db.Create(doctor).Error)
+
+	medicationService := newMedicationService(db).WithTenant(1)
+
+	t.Run("Prescribing adds to the medication list", func(t *testing.T) {
+		prescription := &models.Prescription{PatientID: 1, DoctorID: doctor.ID, Drug: "Amoxicillin", Dose: "500 mg", Frequency: "three times daily", DurationDays: 7, Refills: 1}
+		require.NoError(t, medicationService.CreatePrescription(prescription))
+		assert.Equal(t, models.RouteOral, prescription.Route)
+
+		active, err := medicationService.GetPatientMedications(1, true)
+		require.NoError(t, err)
+		require.Len(t, active, 1)
+		assert.Equal(t, "Amoxicillin", active[0].Drug)
+		require.NotNil(t, active[0].PrescriptionID)
+		assert.Equal(t, prescription.ID, *active[0].PrescriptionID)
+
+		refilled, err := medicationService.RefillPrescription(prescription.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.PrescriptionCompleted, refilled.Status)
+		_, err = medicationService.RefillPrescription(prescription.ID)
+		assert.Error(t, err)
+	})
+
+	t.Run("Cancelling stops the medication", func(t *testing.T) {
+		prescription := &models.Prescription{PatientID: 2, DoctorID: doctor.ID, Drug: "Lisinopril", Dose: "10 mg", Frequency: "once daily", Quantity: "30 tablets", Refills: 5}
+		require.NoError(t, medicationService.CreatePrescription(prescription))
+
+		_, err := medicationService.CancelPrescription(prescription.ID, doctor.ID+1)
+		assert.Error(t, err)
+
+		_, err = medicationService.CancelPrescription(prescription.ID, doctor.ID)
+		require.NoError(t, err)
+
+		active, err := medicationService.GetPatientMedications(2, true)
+		require.NoError(t, err)
+		assert.Empty(t, active)
+	})
+
+	t.Run("Encounter must match patient and doctor", func(t *testing.T) {
+		encounter := &models.Encounter{AppointmentID: 99, PatientID: 2, DoctorID: doctor.ID}
+		require.NoError(t, db.Create(encounter).Error)
+
+		prescription := &models.Prescription{PatientID: 1, DoctorID: doctor.ID, EncounterID: &encounter.ID, Drug: "Ibuprofen", Dose: "400 mg", Frequency: "as needed"}
+		assert.Error(t, medicationService.CreatePrescription(prescription))
+	})
+
+	t.Run("Validation", func(t *testing.T) {
+		assert.Error(t, medicationService.CreatePrescription(&models.Prescription{PatientID: 1, DoctorID: doctor.ID, Drug: "Metformin", Frequency: "twice daily"}))
+		assert.Error(t, medicationService.CreatePrescription(&models.Prescription{PatientID: 1, DoctorID: doctor.ID, Drug: "Metformin", Dose: "500 mg", Frequency: "twice daily", Route: "nasal spray"}))
+		assert.Error(t, medicationService.CreateStatement(&models.MedicationStatement{PatientID: 42, Drug: "Metformin"}))
+	})
+
+	t.Run("Printable prescription", func(t *testing.T) {
+		prescription := &models.Prescription{PatientID: 1, DoctorID: doctor.ID, Drug: "Metformin", Dose: "500 mg", Frequency: "twice daily with meals", Quantity: "60 tablets", Refills: 2}
+		require.NoError(t, medicationService.CreatePrescription(prescription))
+
+		text, err := medicationService.PrintPrescription(prescription.ID)
+		require.NoError(t, err)
+		assert.Contains(t, text, "Riverside Family Practice")
+		assert.Contains(t, text, "Rx: Metformin 500 mg")
+		assert.Contains(t, text, "Sig: oral, twice daily with meals")
+		assert.Contains(t, text, "Dispense: 60 tablets")
+		assert.Contains(t, text, "Patient: Patient 1")
+		assert.Contains(t, text, "Prescriber: Dr. Who")
+	})
+}
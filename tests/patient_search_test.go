@@ -0,0 +1,158 @@
+package tests
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"healthcare-portal/internal/models"
+	"healthcare-portal/internal/repository"
+	"healthcare-portal/internal/search"
+	"healthcare-portal/internal/services"
+)
+
+func TestSearchText(t *testing.T) {
+	assert.Equal(t, "J525", search.Soundex("Johnson"))
+	assert.Equal(t, "J525", search.Soundex("Jonson"))
+	assert.Equal(t, "R163", search.Soundex("Robert"))
+	assert.Equal(t, "R163", search.Soundex("Rupert"))
+	assert.Equal(t, "A261", search.Soundex("Ashcraft"))
+	assert.Equal(t, "T522", search.Soundex("Tymczak"))
+	assert.Equal(t, "P236", search.Soundex("Pfister"))
+
+	assert.Equal(t, []string{"obrien", "smith", "jones"}, search.Words("O'Brien Smith-Jones"))
+	assert.Equal(t, []string{"jose", "munoz"}, search.Words("José Muñoz"))
+	assert.Equal(t, []string{"  c", " ca", "cat", "at "}, search.Trigrams("Cat"))
+	assert.Equal(t, 1.0, search.Similarity("smith", "Smith"))
+	assert.Greater(t, search.Similarity("katherine", "catherine"), search.MinSimilarity)
+	assert.Less(t, search.Similarity("smith", "jones"), search.MinSimilarity)
+
+	terms := search.ParseQuery("  Jon  SMITH jon 555-0101 Jon.Smith@Example.com ")
+	assert.Equal(t, []search.Term{
+		{Text: "jon", Kind: search.TermWord},
+		{Text: "smith", Kind: search.TermWord},
+		{Text: "555", Kind: search.TermNumber},
+		{Text: "0101", Kind: search.TermNumber},
+		{Text: "jon.smith@example.com", Kind: search.TermEmail},
+	}, terms)
+}
+
+func TestPatientSearch(t *testing.T) {
+	db := setupSchedulingDB(t)
+	patientRepo := repository.NewPatientRepository(db)
+	patients := patientRepo.WithTenant(1)
+
+	register := func(patient *models.Patient) *models.Patient {
+		require.NoError(t, patients.Create(patient))
+		return patient
+	}
+	johnson := register(&models.Patient{FirstName: "Mary", LastName: "Johnson", Email: "mary.j@example.com", Phone: "(555) 123-4567", DateOfBirth: date(1980, 4, 5), MRN: "MRN-00000018"})
+	johnston := register(&models.Patient{FirstName: "Marie", LastName: "Johnston", Email: "marie@example.com", Phone: "555-765-4321", DateOfBirth: date(1975, 1, 2)})
+	catherine := register(&models.Patient{FirstName: "Catherine", LastName: "O'Brien", Email: "cob@example.com", Phone: "555-000-1111", DateOfBirth: date(1980, 4, 5)})
+	jose := register(&models.Patient{FirstName: "José", LastName: "Muñoz", Email: "jose@example.com", Phone: "555-000-2222"})
+	for i := 0; i < 25; i++ {
+		register(&models.Patient{FirstName: "Sam", LastName: "Smith", Email: fmt.Sprintf("sam%d@example.com", i), Phone: "555-999-0000"})
+	}
+
+	find := func(query models.PatientSearchQuery) []uint {
+		page, err := patients.Search(query)
+		require.NoError(t, err)
+		ids := []uint{}
+		for _, hit := range page.Hits {
+			ids = append(ids, hit.Patient.ID)
+		}
+		return ids
+	}
+
+	t.Run("Misspelt names still match", func(t *testing.T) {
+		assert.Equal(t, []uint{johnson.ID}, find(models.PatientSearchQuery{Text: "Jonson"}))
+		assert.Equal(t, []uint{catherine.ID}, find(models.PatientSearchQuery{Text: "katherine obrian"}))
+		assert.Equal(t, []uint{jose.ID}, find(models.PatientSearchQuery{Text: "jose munoz"}))
+	})
+
+	t.Run("Every word must match and exact matches rank first", func(t *testing.T) {
+		page, err := patients.Search(models.PatientSearchQuery{Text: "mary johnson"})
+		require.NoError(t, err)
+		require.NotEmpty(t, page.Hits)
+		assert.Equal(t, johnson.ID, page.Hits[0].Patient.ID)
+		assert.InDelta(t, 0.95, page.Hits[0].Score, 1e-9)
+		assert.Equal(t, []string{"last_name", "first_name"}, page.Hits[0].MatchedFields)
+		for _, hit := range page.Hits[1:] {
+			assert.Less(t, hit.Score, page.Hits[0].Score)
+		}
+
+		assert.Empty(t, find(models.PatientSearchQuery{Text: "mary zebra"}))
+	})
+
+	t.Run("Prefixes, phones, emails and MRNs match", func(t *testing.T) {
+		assert.Equal(t, []uint{johnson.ID, johnston.ID}, find(models.PatientSearchQuery{Text: "john"}))
+		assert.Equal(t, []uint{johnson.ID}, find(models.PatientSearchQuery{Text: "4567"}))
+		assert.Equal(t, []uint{johnson.ID}, find(models.PatientSearchQuery{Text: "(555) 123-4567"}))
+		assert.Equal(t, []uint{johnson.ID}, find(models.PatientSearchQuery{Text: "mary.j@example.com"}))
+		assert.Equal(t, []uint{johnson.ID}, find(models.PatientSearchQuery{Text: "18"}))
+		assert.Equal(t, []uint{johnson.ID}, find(models.PatientSearchQuery{Text: "mrn-00000018"}))
+	})
+
+	t.Run("Date of birth and MRN filters", func(t *testing.T) {
+		dob := date(1980, 4, 5)
+		assert.Equal(t, []uint{johnson.ID}, find(models.PatientSearchQuery{Text: "jonson", DateOfBirth: &dob}))
+		assert.Equal(t, []uint{johnson.ID, catherine.ID}, find(models.PatientSearchQuery{DateOfBirth: &dob}))
+		assert.Equal(t, []uint{johnson.ID}, find(models.PatientSearchQuery{MRN: "mrn-00000018"}))
+	})
+
+	t.Run("Results are paginated", func(t *testing.T) {
+		page, err := patients.Search(models.PatientSearchQuery{Text: "smith", Page: 2, Limit: 10})
+		require.NoError(t, err)
+		assert.Equal(t, int64(25), page.Total)
+		assert.Len(t, page.Hits, 10)
+		assert.Equal(t, 2, page.Page)
+
+		page, err = patients.Search(models.PatientSearchQuery{Text: "smith", Page: 3, Limit: 10})
+		require.NoError(t, err)
+		assert.Len(t, page.Hits, 5)
+	})
+
+	t.Run("The index follows updates and deletes", func(t *testing.T) {
+		johnston.LastName = "Peterson"
+		require.NoError(t, patients.Update(johnston))
+		assert.Equal(t, []uint{johnston.ID}, find(models.PatientSearchQuery{Text: "petersen"}))
+		assert.Equal(t, []uint{johnson.ID}, find(models.PatientSearchQuery{Text: "johnston"}))
+
+		require.NoError(t, patients.Delete(johnston.ID))
+		assert.Empty(t, find(models.PatientSearchQuery{Text: "peterson"}))
+		var remaining int64
+		require.NoError(t, db.Model(&models.PatientSearchTerm{}).Where("patient_id = ?", johnston.ID).Count(&remaining).Error)
+		assert.Zero(t, remaining)
+	})
+
+	t.Run("Search is scoped to the tenant", func(t *testing.T) {
+		other, err := patientRepo.WithTenant(2).Search(models.PatientSearchQuery{Text: "johnson"})
+		require.NoError(t, err)
+		assert.Empty(t, other.Hits)
+	})
+
+	t.Run("The service needs criteria", func(t *testing.T) {
+		service := newPatientService(db).WithTenant(1)
+		_, err := service.SearchPatients(models.PatientSearchQuery{Text: " j "})
+		assert.ErrorIs(t, err, services.ErrSearchCriteria)
+
+		page, err := service.SearchPatients(models.PatientSearchQuery{Text: "smith", Limit: 1000})
+		require.NoError(t, err)
+		assert.Equal(t, 100, page.Limit)
+	})
+
+	t.Run("Broad searches keep the best ranked candidates", func(t *testing.T) {
+		for i := 0; i < 480; i++ {
+			register(&models.Patient{FirstName: "Ann", LastName: "Smithers", Email: fmt.Sprintf("ann%d@example.com", i), Phone: "555-888-0000"})
+		}
+		page, err := patients.Search(models.PatientSearchQuery{Text: "smith", Limit: 30})
+		require.NoError(t, err)
+		assert.Equal(t, int64(500), page.Total, "the total stops at the candidate limit")
+		require.Len(t, page.Hits, 30)
+		for _, hit := range page.Hits[:25] {
+			assert.Equal(t, "Smith", hit.Patient.LastName, "exact matches are kept and ranked first")
+		}
+	})
+}
@@ -0,0 +1,289 @@
+package tests
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"healthcare-portal/internal/config"
+	"healthcare-portal/internal/models"
+	"healthcare-portal/internal/repository"
+	"healthcare-portal/internal/services"
+)
+
+var testMRNConfig = config.MRNConfig{Prefix: "MRN", Digits: 7, CheckDigit: true, PerLocation: true}
+
+func newPatientService(db *gorm.DB) services.PatientService {
+	identifierRepo := repository.NewPatientIdentifierRepository(db)
+	generator := services.NewMRNGenerator(identifierRepo, repository.NewLocationRepository(db), testMRNConfig)
+	return services.NewPatientService(repository.NewPatientRepository(db), identifierRepo, repository.NewRelatedPersonRepository(db), generator)
+}
+
+func TestPatientMatcher(t *testing.T) {
+	matcher := services.PatientMatcher{}
+	john := models.Patient{ID: 1, FirstName: "John", LastName: "Smith", DateOfBirth: date(1980, 4, 5), Phone: "(555) 123-4567"}
+
+	t.Run("Typos still match", func(t *testing.T) {
+		typo := &models.Patient{FirstName: "Jon", LastName: "Smith", DateOfBirth: date(1980, 5, 4), Phone: "+1 555.123.4567"}
+		candidates := matcher.Candidates(typo, []models.Patient{john})
+		require.Len(t, candidates, 1)
+		assert.Equal(t, uint(1), candidates[0].Patient.ID)
+		assert.Equal(t, models.MatchProbable, candidates[0].Confidence)
+		assert.Equal(t, []string{"last name", "similar first name", "similar date of birth", "phone"}, candidates[0].Reasons)
+	})
+
+	t.Run("Name alone is only possible", func(t *testing.T) {
+		nameOnly := &models.Patient{FirstName: "john", LastName: "SMITH"}
+		candidates := matcher.Candidates(nameOnly, []models.Patient{john})
+		require.Len(t, candidates, 1)
+		assert.Equal(t, models.MatchPossible, candidates[0].Confidence)
+	})
+
+	t.Run("Swapped names and exact date are probable", func(t *testing.T) {
+		swapped := &models.Patient{FirstName: "Smith", LastName: "John", DateOfBirth: date(1980, 4, 5)}
+		candidates := matcher.Candidates(swapped, []models.Patient{john})
+		require.Len(t, candidates, 1)
+		assert.Equal(t, models.MatchProbable, candidates[0].Confidence)
+	})
+
+	t.Run("Different people do not match", func(t *testing.T) {
+		sibling := &models.Patient{FirstName: "Mary", LastName: "Smith", DateOfBirth: date(1984, 9, 1), Phone: "555-123-4567"}
+		assert.Empty(t, matcher.Candidates(sibling, []models.Patient{john}))
+
+		stranger := &models.Patient{FirstName: "Jane", LastName: "Doe", DateOfBirth: date(1980, 4, 5)}
+		assert.Empty(t, matcher.Candidates(stranger, []models.Patient{john}))
+	})
+
+	assert.Equal(t, "5551234567", services.NormalizePhone("+1 (555) 123-4567"))
+}
+
+func TestPatientServiceDuplicatesAndMerge(t *testing.T) {
+	db := setupSchedulingDB(t)
+	doctor := &models.User{Email: "doc@example.com", Password: "x", Name: "Dr. Who", Role: models.RoleDoctor, IsActive: true}
+	admin := &models.User{Email: "admin@example.com", Password: "x", Name: "Admin", Role: models.RoleAdmin, IsActive: true}
+	require.NoError(t, db.Create(doctor).Error)
+	require.NoError(t, db.Create(admin).Error)
+
+	patientService := newPatientService(db).WithTenant(1)
+
+	original := &models.Patient{
+		FirstName: "John", LastName: "Smith", Email: "john@example.com", Phone: "555-123-4567",
+		DateOfBirth: date(1980, 4, 5), Address: "1 Main St", Allergies: "Penicillin",
+	}
+	require.NoError(t, patientService.CreatePatient(original))
+
+	var typo *models.Patient
+	t.Run("Create reports possible duplicates", func(t *testing.T) {
+		typo = &models.Patient{FirstName: "Jon", LastName: "Smith", Phone: "(555) 123 4567", DateOfBirth: date(1980, 4, 5), Allergies: "Latex"}
+		err := patientService.CreatePatient(typo)
+		var duplicatesErr *services.PossibleDuplicatesError
+		require.True(t, errors.As(err, &duplicatesErr))
+		require.Len(t, duplicatesErr.Candidates, 1)
+		assert.Equal(t, original.ID, duplicatesErr.Candidates[0].Patient.ID)
+		assert.Equal(t, models.MatchProbable, duplicatesErr.Candidates[0].Confidence)
+		assert.Zero(t, typo.ID)
+
+		typo.DuplicateOverrideReason = "  Patient insists this is a new registration "
+		require.NoError(t, patientService.CreatePatient(typo))
+		assert.Equal(t, "Patient insists this is a new registration", typo.DuplicateOverrideReason)
+
+		candidates, err := patientService.GetDuplicates(typo.ID)
+		require.NoError(t, err)
+		require.Len(t, candidates, 1)
+		assert.Equal(t, original.ID, candidates[0].Patient.ID)
+	})
+
+	t.Run("Merge moves records and leaves a tombstone", func(t *testing.T) {
+		appointment := &models.Appointment{PatientID: original.ID, DoctorID: doctor.ID, Date: date(2030, 1, 8), Time: "09:00"}
+		require.NoError(t, db.Create(appointment).Error)
+		problem := &models.Problem{PatientID: original.ID, Code: "E11.9", Description: "Type 2 diabetes", Status: models.ProblemActive}
+		require.NoError(t, db.Create(problem).Error)
+
+		_, err := patientService.MergePatients(typo.ID, typo.ID, admin.ID, "same")
+		assert.ErrorIs(t, err, services.ErrMergeSamePatient)
+
+		merge, err := patientService.MergePatients(typo.ID, original.ID, admin.ID, "Registered twice at front desk")
+		require.NoError(t, err)
+		assert.Equal(t, map[string]int64{"appointments": 1, "problems": 1}, merge.MovedRecords)
+		assert.Equal(t, "john@example.com", merge.Snapshot.Email)
+
+		var moved models.Appointment
+		require.NoError(t, db.First(&moved, appointment.ID).Error)
+		assert.Equal(t, typo.ID, moved.PatientID)
+
+		survivor, err := patientService.GetPatientByID(typo.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "john@example.com", survivor.Email)
+		assert.Equal(t, "1 Main St", survivor.Address)
+		assert.Equal(t, "Latex\nPenicillin", survivor.Allergies)
+		assert.Equal(t, admin.ID, survivor.LastUpdatedBy)
+
+		_, err = patientService.GetPatientByID(original.ID)
+		var mergedErr *services.PatientMergedError
+		require.True(t, errors.As(err, &mergedErr))
+		assert.Equal(t, typo.ID, mergedErr.MergedIntoID)
+
+		merges, err := patientService.GetPatientMerges(typo.ID)
+		require.NoError(t, err)
+		require.Len(t, merges, 1)
+		assert.Equal(t, original.ID, merges[0].MergedPatientID)
+		assert.Equal(t, "Smith", merges[0].Snapshot.LastName)
+		assert.Equal(t, int64(1), merges[0].MovedRecords["appointments"])
+		assert.WithinDuration(t, time.Now(), merges[0].CreatedAt, time.Minute)
+
+		// The tombstoned record no longer shows up as a duplicate.
+		candidates, err := patientService.GetDuplicates(typo.ID)
+		require.NoError(t, err)
+		assert.Empty(t, candidates)
+		_, err = patientService.MergePatients(typo.ID, original.ID, admin.ID, "again")
+		assert.ErrorIs(t, err, services.ErrPatientNotFound)
+
+		// The merged-away MRN still finds the survivor.
+		found, err := patientService.LookupPatients("mrn", original.MRN)
+		require.NoError(t, err)
+		require.Len(t, found, 1)
+		assert.Equal(t, typo.ID, found[0].ID)
+	})
+
+	t.Run("Merging is limited to the tenant", func(t *testing.T) {
+		other := &models.Patient{TenantID: 2, FirstName: "John", LastName: "Smith", Email: "john@example.com", Phone: "555-123-4567", DateOfBirth: date(1980, 4, 5)}
+		require.NoError(t, db.Create(other).Error)
+
+		candidates, err := patientService.GetDuplicates(typo.ID)
+		require.NoError(t, err)
+		assert.Empty(t, candidates)
+		_, err = patientService.MergePatients(typo.ID, other.ID, admin.ID, "wrong tenant")
+		assert.ErrorIs(t, err, services.ErrPatientNotFound)
+	})
+}
+
+func TestPatientMergeSupersedesClashingConsents(t *testing.T) {
+	db := setupSchedulingDB(t)
+	createPatients(t, db, 2)
+	consentService := newConsentService(db).WithTenant(1)
+	for _, consentType := range []models.ConsentType{models.ConsentSMSReminders, models.ConsentTreatment} {
+		require.NoError(t, consentService.PublishText(&models.ConsentText{Type: consentType, Title: "Consent", Body: "I agree."}))
+	}
+	grant := func(patientID uint, consentType models.ConsentType) *models.Consent {
+		consent := &models.Consent{PatientID: patientID, Type: consentType, RecordedBy: 7}
+		require.NoError(t, consentService.GrantConsent(consent))
+		return consent
+	}
+	// Each record holds the newer grant of one of the two types.
+	oldSMS := grant(2, models.ConsentSMSReminders)
+	newSMS := grant(1, models.ConsentSMSReminders)
+	oldTreatment := grant(1, models.ConsentTreatment)
+	newTreatment := grant(2, models.ConsentTreatment)
+
+	merge, err := newPatientService(db).WithTenant(1).MergePatients(1, 2, 9, "Registered twice")
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), merge.MovedRecords["consents"])
+
+	consents, err := consentService.GetPatientConsents(1, true)
+	require.NoError(t, err)
+	require.Len(t, consents, 4)
+	status := make(map[uint]models.Consent)
+	for _, consent := range consents {
+		status[consent.ID] = consent
+	}
+	assert.Equal(t, models.ConsentGranted, status[newSMS.ID].Status)
+	assert.Equal(t, models.ConsentGranted, status[newTreatment.ID].Status)
+	for _, superseded := range []*models.Consent{oldSMS, oldTreatment} {
+		assert.Equal(t, models.ConsentRevoked, status[superseded.ID].Status)
+		assert.Equal(t, "superseded by patient merge", status[superseded.ID].RevocationReason)
+		require.NotNil(t, status[superseded.ID].RevokedBy)
+		assert.Equal(t, uint(9), *status[superseded.ID].RevokedBy)
+	}
+}
+
+func TestPatientIdentifiers(t *testing.T) {
+	db := setupSchedulingDB(t)
+	location := &models.Location{Code: "main", Name: "Main Street Clinic", TimeZone: "UTC"}
+	require.NoError(t, db.Create(location).Error)
+	patientService := newPatientService(db).WithTenant(1)
+
+	register := func(service services.PatientService, first, last string, locationID *uint, identifiers ...models.PatientIdentifier) *models.Patient {
+		patient := &models.Patient{FirstName: first, LastName: last, Phone: "555-01" + first[:2], Email: first + "@example.com", Identifiers: identifiers}
+		patient.RegistrationLocationID = locationID
+		require.NoError(t, service.CreatePatient(patient))
+		return patient
+	}
+
+	t.Run("MRNs carry prefix, location and check digit", func(t *testing.T) {
+		first := register(patientService, "Alice", "Adams", nil)
+		second := register(patientService, "Bob", "Brown", nil)
+		atMain := register(patientService, "Carol", "Clark", &location.ID)
+		assert.Equal(t, "MRN-00000018", first.MRN)
+		assert.Equal(t, "MRN-00000026", second.MRN)
+		assert.Equal(t, "MRN-MAIN-00000018", atMain.MRN)
+
+		otherTenant := register(newPatientService(db).WithTenant(2), "Dave", "Dunn", nil)
+		assert.Equal(t, "MRN-00000018", otherTenant.MRN)
+
+		generator := services.NewMRNGenerator(repository.NewPatientIdentifierRepository(db), repository.NewLocationRepository(db), testMRNConfig)
+		assert.True(t, generator.Valid(atMain.MRN))
+		assert.False(t, generator.Valid("MRN-00000081"))
+		assert.False(t, generator.Valid("MRN-"))
+
+		found, err := patientService.LookupPatients("MRN", " mrn-main-00000018 ")
+		require.NoError(t, err)
+		require.Len(t, found, 1)
+		assert.Equal(t, atMain.ID, found[0].ID)
+
+		_, err = patientService.LookupPatients("mrn", "MRN-MAIN-00000019")
+		assert.ErrorIs(t, err, services.ErrInvalidMRN)
+
+		noLocation := &models.Patient{FirstName: "Eve", LastName: "Evans", Phone: "555-0199", Email: "eve@example.com", RegistrationLocationID: new(uint)}
+		assert.Error(t, patientService.CreatePatient(noLocation))
+	})
+
+	t.Run("Identifiers are unique per system and searchable", func(t *testing.T) {
+		patient := register(patientService, "Frank", "Foster", nil,
+			models.PatientIdentifier{System: " National_ID ", Value: "123-45 6789"},
+		)
+		require.Len(t, patient.Identifiers, 1)
+		assert.Equal(t, "national_id", patient.Identifiers[0].System)
+		assert.Equal(t, "123456789", patient.Identifiers[0].Value)
+		assert.Equal(t, uint(1), patient.Identifiers[0].TenantID)
+
+		member := &models.PatientIdentifier{PatientID: patient.ID, System: models.IdentifierInsurerMemberID, Value: "abc123", Assigner: "Acme Health"}
+		require.NoError(t, patientService.AddIdentifier(member))
+
+		other := register(patientService, "Grace", "Green", nil)
+		taken := &models.PatientIdentifier{PatientID: other.ID, System: "national_id", Value: "123456789"}
+		assert.ErrorIs(t, patientService.AddIdentifier(taken), services.ErrIdentifierInUse)
+		// The same value in another system is a different identifier.
+		passport := &models.PatientIdentifier{PatientID: other.ID, System: models.IdentifierPassport, Value: "123456789"}
+		require.NoError(t, patientService.AddIdentifier(passport))
+		assert.Error(t, patientService.AddIdentifier(&models.PatientIdentifier{PatientID: other.ID, System: "mrn", Value: "MRN-1"}))
+		assert.Error(t, patientService.AddIdentifier(&models.PatientIdentifier{PatientID: other.ID, System: "bad system", Value: "1"}))
+
+		found, err := patientService.LookupPatients("national_id", "123 45 6789")
+		require.NoError(t, err)
+		require.Len(t, found, 1)
+		assert.Equal(t, patient.ID, found[0].ID)
+
+		found, err = patientService.LookupPatients("", "123456789")
+		require.NoError(t, err)
+		assert.Len(t, found, 2)
+
+		found, err = patientService.LookupPatients("", "ABC123")
+		require.NoError(t, err)
+		require.Len(t, found, 1)
+		assert.Len(t, found[0].Identifiers, 2)
+
+		assert.ErrorIs(t, patientService.RemoveIdentifier(other.ID, member.ID), services.ErrIdentifierNotFound)
+		require.NoError(t, patientService.RemoveIdentifier(patient.ID, member.ID))
+		identifiers, err := patientService.GetIdentifiers(patient.ID)
+		require.NoError(t, err)
+		assert.Len(t, identifiers, 1)
+
+		found, err = newPatientService(db).WithTenant(2).LookupPatients("national_id", "123456789")
+		require.NoError(t, err)
+		assert.Empty(t, found)
+	})
+}
@@ -0,0 +1,267 @@
+package tests
+
+import (
+	"bytes"
+	"compress/zlib"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"healthcare-portal/internal/config"
+	"healthcare-portal/internal/models"
+	"healthcare-portal/internal/printout"
+	"healthcare-portal/internal/repository"
+	"healthcare-portal/internal/services"
+)
+
+func newPrintoutService(db *gorm.DB, branding printout.Branding) services.PrintoutService {
+	return services.NewPrintoutService(
+		newBillingService(db),
+		repository.NewPatientRepository(db),
+		repository.NewAppointmentRepository(db),
+		repository.NewProblemRepository(db),
+		repository.NewVitalsRepository(db),
+		repository.NewMedicationRepository(db),
+		repository.NewAllergyRepository(db),
+		repository.NewTenantRepository(db),
+		branding,
+	)
+}
+
+var (
+	pdfStream = regexp.MustCompile(`(?s)/Length (\d+) >>\nstream\n`)
+	pdfText   = regexp.MustCompile(`\(((?:\\.|[^\\)])*)\) Tj`)
+	pdfObject = regexp.MustCompile(`^(\d+) 0 obj\n`)
+)
+
+// readPDF checks the file's structure — every cross-reference entry must
+// point at its object — and returns the text drawn on its pages, one
+// string per line drawn, along with the page count.
+func readPDF(t *testing.T, data []byte) (text []string, pages int) {
+	t.Helper()
+	require.True(t, bytes.HasPrefix(data, []byte("%PDF-1.4\n")))
+	require.True(t, bytes.HasSuffix(data, []byte("%%EOF\n")))
+
+	trailer := data[bytes.LastIndex(data, []byte("startxref\n"))+len("startxref\n"):]
+	xref, err := strconv.Atoi(string(trailer[:bytes.IndexByte(trailer, '\n')]))
+	require.NoError(t, err)
+	require.True(t, bytes.HasPrefix(data[xref:], []byte("xref\n0 ")))
+	entries := strings.Split(string(data[xref:]), "\n")[3:]
+	for i, entry := range entries {
+		if !strings.HasSuffix(entry, " n ") {
+			break
+		}
+		offset, err := strconv.Atoi(entry[:10])
+		require.NoError(t, err)
+		match := pdfObject.FindSubmatch(data[offset:])
+		require.NotNil(t, match, "xref entry %d", i+1)
+		assert.Equal(t, strconv.Itoa(i+1), string(match[1]))
+	}
+
+	for _, loc := range pdfStream.FindAllSubmatchIndex(data, -1) {
+		length, _ := strconv.Atoi(string(data[loc[2]:loc[3]]))
+		zr, err := zlib.NewReader(bytes.NewReader(data[loc[1] : loc[1]+length]))
+		require.NoError(t, err)
+		content, err := io.ReadAll(zr)
+		require.NoError(t, err)
+		for _, match := range pdfText.FindAllSubmatch(content, -1) {
+			text = append(text, unescapePDF(string(match[1])))
+		}
+	}
+	return text, bytes.Count(data, []byte("/Type /Page /Parent"))
+}
+
+func unescapePDF(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		if s[i] >= '0' && s[i] <= '7' {
+			code, _ := strconv.ParseUint(s[i:i+3], 8, 8)
+			b.WriteRune(rune(code))
+			i += 2
+			continue
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+func TestPrintoutService(t *testing.T) {
+	db := setupSchedulingDB(t)
+	require.NoError(t, db.Create(&models.Tenant{Name: "Riverside Family Practice", Slug: "riverside", IsActive: true}).Error)
+	require.NoError(t, db.Create(&models.Tenant{Name: "Other Clinic", Slug: "other", IsActive: true}).Error)
+	createPatients(t, db, 1)
+	require.NoError(t, db.Create(&models.User{Email: "doc@example.com", Password: "x", Name: "Dr. Grey", Specialty: "Family Medicine", Role: models.RoleDoctor, IsActive: true}).Error)
+	location := &models.Location{Code: "MAIN", Name: "Main Street", Address: "1 Main St, Springfield, IL 62701", Phone: "(555) 010-2000", TimeZone: "UTC"}
+	require.NoError(t, db.Create(location).Error)
+
+	branding, err := services.LoadBranding(config.BrandingConfig{Phone: "(555) 010-0000", AccentColor: "#1F6FB2", Footer: "Tax ID 12-3456789"})
+	require.NoError(t, err)
+	printouts := newPrintoutService(db, branding).WithTenant(1)
+	billing := newBillingService(db).WithTenant(1)
+
+	require.NoError(t, billing.CreateCharge(&models.Charge{PatientID: 1, Code: "99213", Description: "Office visit (established)", UnitPriceCents: 123456}))
+	invoice, err := billing.CreateInvoice(services.InvoiceDraft{PatientID: 1, Notes: "Thank you"})
+	require.NoError(t, err)
+	_, err = billing.RecordPayment(&models.Payment{InvoiceID: invoice.ID, Method: models.PaymentCard, AmountCents: 50000, Reference: "AUTH-1"})
+	require.NoError(t, err)
+	invoice, err = billing.GetInvoiceByID(invoice.ID)
+	require.NoError(t, err)
+	payment := invoice.Payments[0]
+
+	t.Run("Invoices print their charges, payments and balance under the clinic's name", func(t *testing.T) {
+		printed, err := printouts.InvoicePDF(invoice.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "INV-000001.pdf", printed.FileName)
+
+		text, pages := readPDF(t, printed.Content)
+		assert.Equal(t, 1, pages)
+		assert.Contains(t, text, "Riverside Family Practice", "the tenant's name stands in for an unset clinic name")
+		assert.Contains(t, text, "(555) 010-0000")
+		assert.Contains(t, text, "INV-000001")
+		assert.Contains(t, text, "Office visit (established)")
+		assert.Contains(t, text, "$1,234.56")
+		assert.Contains(t, text, "Card payment")
+		assert.Contains(t, text, "$734.56")
+		assert.Contains(t, text, "Tax ID 12-3456789")
+		assert.Contains(t, text, "Page 1 of 1")
+	})
+
+	t.Run("Long invoices run onto more pages with the table header repeated", func(t *testing.T) {
+		var ids []uint
+		for i := 0; i < 60; i++ {
+			charge := &models.Charge{PatientID: 1, Description: "Supply item", UnitPriceCents: 100}
+			require.NoError(t, billing.CreateCharge(charge))
+			ids = append(ids, charge.ID)
+		}
+		long, err := billing.CreateInvoice(services.InvoiceDraft{PatientID: 1, ChargeIDs: ids})
+		require.NoError(t, err)
+
+		printed, err := printouts.InvoicePDF(long.ID)
+		require.NoError(t, err)
+		text, pages := readPDF(t, printed.Content)
+		require.Greater(t, pages, 1)
+		assert.Contains(t, text, "Page 2 of "+strconv.Itoa(pages))
+
+		headers := 0
+		for _, line := range text {
+			if line == "Unit price" {
+				headers++
+			}
+		}
+		assert.Equal(t, pages, headers)
+	})
+
+	t.Run("Receipts show the balance left after the payment", func(t *testing.T) {
+		printed, err := printouts.ReceiptPDF(invoice.ID, payment.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "receipt-INV-000001-1.pdf", printed.FileName)
+
+		text, _ := readPDF(t, printed.Content)
+		assert.Contains(t, text, "Payment Receipt")
+		assert.Contains(t, text, "Patient 1")
+		assert.Contains(t, text, "$500.00")
+		assert.Contains(t, text, "$734.56")
+		assert.Contains(t, text, "AUTH-1")
+
+		_, err = printouts.ReceiptPDF(invoice.ID, 999)
+		assert.ErrorIs(t, err, services.ErrPaymentNotFound)
+	})
+
+	visitType := &models.AppointmentType{Code: "PHYS", Name: "Annual physical", DurationMinutes: 30, PreparationInstructions: "Do not eat for 8 hours before your visit.", IsActive: true}
+	require.NoError(t, db.Create(visitType).Error)
+	booked := &models.Appointment{PatientID: 1, DoctorID: 1, AppointmentTypeID: &visitType.ID, LocationID: &location.ID, Date: date(2026, 3, 2), Time: "09:30", DurationMinutes: 30, Status: models.StatusScheduled, Notes: "Prefers mornings"}
+	require.NoError(t, db.Create(booked).Error)
+
+	t.Run("Confirmations describe the booking and its preparation", func(t *testing.T) {
+		printed, err := printouts.AppointmentConfirmationPDF(booked.ID)
+		require.NoError(t, err)
+		text, _ := readPDF(t, printed.Content)
+		assert.Contains(t, text, "Appointment Confirmation")
+		assert.Contains(t, text, "Monday, March 2, 2026")
+		assert.Contains(t, text, "09:30 (30 minutes)")
+		assert.Contains(t, text, "Dr. Grey, Family Medicine")
+		assert.Contains(t, text, "Do not eat for 8 hours before your visit.")
+		assert.NotContains(t, strings.Join(text, "\n"), "Prefers mornings", "staff notes stay off patient printouts")
+
+		_, err = printouts.VisitSummaryPDF(booked.ID)
+		assert.ErrorIs(t, err, services.ErrVisitNotCompleted)
+		_, err = printouts.AppointmentConfirmationPDF(999)
+		assert.ErrorIs(t, err, services.ErrAppointmentNotFound)
+	})
+
+	t.Run("Visit summaries list diagnoses, vitals, medications and allergies", func(t *testing.T) {
+		require.NoError(t, db.Model(booked).Update("status", models.StatusCompleted).Error)
+		require.NoError(t, db.Create(&models.AppointmentDiagnosis{AppointmentID: booked.ID, Code: "I10", Description: "Essential (primary) hypertension", Rank: 1}).Error)
+		systolic, diastolic, temperature := 142, 91, 36.8
+		require.NoError(t, db.Create(&models.VitalSigns{PatientID: 1, AppointmentID: &booked.ID, RecordedAt: date(2026, 3, 2), SystolicBP: &systolic, DiastolicBP: &diastolic, TemperatureC: &temperature}).Error)
+		require.NoError(t, db.Create(&models.MedicationStatement{PatientID: 1, Drug: "Lisinopril", Dose: "10 mg", Route: "oral", Frequency: "Once daily", Status: models.MedicationActive}).Error)
+		require.NoError(t, db.Create(&models.Allergy{PatientID: 1, Substance: "Penicillin", Category: "medication", Reaction: "Hives", Severity: "moderate", Status: models.AllergyActive}).Error)
+
+		_, err := printouts.AppointmentConfirmationPDF(booked.ID)
+		assert.ErrorIs(t, err, services.ErrAppointmentNotBooked)
+
+		printed, err := printouts.VisitSummaryPDF(booked.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "visit-summary-"+strconv.Itoa(int(booked.ID))+".pdf", printed.FileName)
+		text, _ := readPDF(t, printed.Content)
+		assert.Contains(t, text, "After-Visit Summary")
+		assert.Contains(t, text, "Essential (primary) hypertension")
+		assert.Contains(t, text, "142/91 mmHg")
+		assert.Contains(t, text, "36.8 °C")
+		assert.Contains(t, text, "Lisinopril")
+		assert.Contains(t, text, "Penicillin")
+		assert.Contains(t, text, "No upcoming appointments are booked.")
+	})
+
+	t.Run("Other tenants cannot print the records", func(t *testing.T) {
+		other := newPrintoutService(db, branding).WithTenant(2)
+		_, err := other.InvoicePDF(invoice.ID)
+		assert.ErrorIs(t, err, services.ErrInvoiceNotFound)
+		_, err = other.ReceiptPDF(invoice.ID, payment.ID)
+		assert.ErrorIs(t, err, services.ErrInvoiceNotFound)
+		_, err = other.VisitSummaryPDF(booked.ID)
+		assert.ErrorIs(t, err, services.ErrAppointmentNotFound)
+	})
+
+	t.Run("Branding is validated and the logo embedded", func(t *testing.T) {
+		_, err := services.LoadBranding(config.BrandingConfig{AccentColor: "blue"})
+		assert.Error(t, err)
+		_, err = services.LoadBranding(config.BrandingConfig{AccentColor: "#000000", LogoFile: filepath.Join(t.TempDir(), "missing.png")})
+		assert.Error(t, err)
+
+		logo := image.NewRGBA(image.Rect(0, 0, 40, 20))
+		for x := 0; x < 40; x++ {
+			logo.Set(x, 10, color.RGBA{R: 200, A: 255})
+		}
+		path := filepath.Join(t.TempDir(), "logo.png")
+		f, err := os.Create(path)
+		require.NoError(t, err)
+		require.NoError(t, png.Encode(f, logo))
+		require.NoError(t, f.Close())
+
+		branded, err := services.LoadBranding(config.BrandingConfig{ClinicName: "Main Street Clinic", AccentColor: "#000000", LogoFile: path})
+		require.NoError(t, err)
+		printed, err := newPrintoutService(db, branded).WithTenant(1).InvoicePDF(invoice.ID)
+		require.NoError(t, err)
+		assert.Contains(t, string(printed.Content), "/Subtype /Image /Width 40 /Height 20")
+		text, _ := readPDF(t, printed.Content)
+		assert.Contains(t, text, "Main Street Clinic")
+		assert.NotContains(t, text, "Riverside Family Practice")
+	})
+}
@@ -0,0 +1,162 @@
+package tests
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"healthcare-portal/internal/models"
+	"healthcare-portal/internal/repository"
+	"healthcare-portal/internal/services"
+)
+
+func TestParseDiagnosisCodes(t *testing.T) {
+	codes, err := services.ParseDiagnosisCodes(strings.NewReader("code,description\nE119,Type 2 diabetes mellitus without complications\ni10,\"Essential (primary) hypertension\"\n"))
+	require.NoError(t, err)
+	assert.Equal(t, []models.DiagnosisCode{
+		{Code: "E11.9", Description: "Type 2 diabetes mellitus without complications"},
+		{Code: "I10", Description: "Essential (primary) hypertension"},
+	}, codes)
+
+	codes, err = services.ParseDiagnosisCodes(strings.NewReader("J069    Acute upper respiratory infection, unspecified\n"))
+	require.NoError(t, err)
+	assert.Equal(t, "J06.9", codes[0].Code)
+	assert.Equal(t, "Acute upper respiratory infection, unspecified", codes[0].Description)
+
+	_, err = services.ParseDiagnosisCodes(strings.NewReader("I10 Hypertension\nnot-a-code Something\n"))
+	assert.Error(t, err)
+
+	file, err := os.Open("../data/icd10_codes.txt")
+	require.NoError(t, err)
+	defer file.Close()
+	codes, err = services.ParseDiagnosisCodes(file)
+	require.NoError(t, err)
+	assert.NotEmpty(t, codes)
+}
+
+func TestProblemService(t *testing.T) {
+	db := setupSchedulingDB(t)
+	createPatients(t, db, 2)
+	doctor := &models.User{Email: "doc@example.com", Password: "x", Name: "Dr. Who", Role: models.RoleDoctor, IsActive: true}
+	require.NoError(t, db.Create(doctor).Error)
+
+	codeRepo := repository.NewDiagnosisCodeRepository(db)
+	codeService := services.NewDiagnosisCodeService(codeRepo)
+	_, err := codeService.ImportCodes([]models.DiagnosisCode{
+		{Code: "E11.9", Description: "Type 2 diabetes mellitus without complications"},
+		{Code: "E11.65", Description: "Type 2 diabetes mellitus with hyperglycemia"},
+		{Code: "E10.9", Description: "Type 1 diabetes mellitus without complications"},
+		{Code: "I10", Description: "Essential (primary) hypertension"},
+		{Code: "J06.9", Description: "Acute upper respiratory infection, unspecified"},
+	})
+	require.NoError(t, err)
+
+	problemService := services.NewProblemService(
+		repository.NewProblemRepository(db),
+		repository.NewPatientRepository(db),
+		repository.NewAppointmentRepository(db),
+		repository.NewEncounterRepository(db),
+		codeRepo,
+	).WithTenant(1)
+
+	t.Run("Code search", func(t *testing.T) {
+		codes, err := codeService.SearchCodes("e11", 0)
+		require.NoError(t, err)
+		assert.Len(t, codes, 2)
+
+		codes, err = codeService.SearchCodes("E119", 0)
+		require.NoError(t, err)
+		require.Len(t, codes, 1)
+		assert.Equal(t, "E11.9", codes[0].Code)
+
+		codes, err = codeService.SearchCodes("diabetes without", 0)
+		require.NoError(t, err)
+		assert.Len(t, codes, 2)
+
+		codes, err = codeService.SearchCodes("Hypertension", 0)
+		require.NoError(t, err)
+		require.Len(t, codes, 1)
+		assert.Equal(t, "I10", codes[0].Code)
+
+		// Reloading refreshes descriptions instead of failing.
+		_, err = codeService.ImportCodes([]models.DiagnosisCode{{Code: "I10", Description: "Essential hypertension"}})
+		require.NoError(t, err)
+		code, err := codeService.LookupCode("i10")
+		require.NoError(t, err)
+		assert.Equal(t, "Essential hypertension", code.Description)
+	})
+
+	t.Run("Problem list", func(t *testing.T) {
+		onset := time.Date(2020, 5, 1, 0, 0, 0, 0, time.UTC)
+		problem := &models.Problem{PatientID: 1, Code: "e119", OnsetDate: &onset}
+		require.NoError(t, problemService.CreateProblem(problem))
+		assert.Equal(t, "E11.9", problem.Code)
+		assert.Equal(t, "Type 2 diabetes mellitus without complications", problem.Description)
+		assert.Equal(t, models.ProblemActive, problem.Status)
+
+		assert.Error(t, problemService.CreateProblem(&models.Problem{PatientID: 1, Code: "E11.9"}))
+		assert.Error(t, problemService.CreateProblem(&models.Problem{PatientID: 1, Code: "Z99.99"}))
+		assert.Error(t, problemService.CreateProblem(&models.Problem{PatientID: 42, Code: "I10"}))
+
+		resolved, err := problemService.ResolveProblem(problem.ID, nil)
+		require.NoError(t, err)
+		assert.Equal(t, models.ProblemResolved, resolved.Status)
+		assert.NotNil(t, resolved.ResolvedDate)
+
+		// Once resolved the condition can be recorded again.
+		require.NoError(t, problemService.CreateProblem(&models.Problem{PatientID: 1, Code: "E11.9"}))
+
+		active, err := problemService.GetPatientProblems(1, models.ProblemActive)
+		require.NoError(t, err)
+		assert.Len(t, active, 1)
+		all, err := problemService.GetPatientProblems(1, "")
+		require.NoError(t, err)
+		assert.Len(t, all, 2)
+	})
+
+	t.Run("Diagnoses on completed appointments", func(t *testing.T) {
+		scheduled := &models.Appointment{PatientID: 2, DoctorID: doctor.ID, Date: time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC), Time: "09:00", Status: models.StatusScheduled}
+		require.NoError(t, db.Create(scheduled).Error)
+		_, err := problemService.SetAppointmentDiagnoses(scheduled.ID, doctor.ID, []services.DiagnosisEntry{{Code: "J06.9"}})
+		assert.Error(t, err)
+
+		completed := &models.Appointment{PatientID: 2, DoctorID: doctor.ID, Date: time.Date(2024, 4, 2, 0, 0, 0, 0, time.UTC), Time: "09:00", Status: models.StatusCompleted}
+		require.NoError(t, db.Create(completed).Error)
+		encounter := &models.Encounter{AppointmentID: completed.ID, PatientID: 2, DoctorID: doctor.ID}
+		require.NoError(t, db.Create(encounter).Error)
+
+		_, err = problemService.SetAppointmentDiagnoses(completed.ID, doctor.ID, []services.DiagnosisEntry{{Code: "I10"}, {Code: "I10"}})
+		assert.Error(t, err)
+
+		diagnoses, err := problemService.SetAppointmentDiagnoses(completed.ID, doctor.ID, []services.DiagnosisEntry{
+			{Code: "J06.9"},
+			{Code: "I10", AddToProblemList: true},
+		})
+		require.NoError(t, err)
+		require.Len(t, diagnoses, 2)
+		assert.Equal(t, 1, diagnoses[0].Rank)
+		assert.Equal(t, "J06.9", diagnoses[0].Code)
+
+		problems, err := problemService.GetPatientProblems(2, models.ProblemActive)
+		require.NoError(t, err)
+		require.Len(t, problems, 1)
+		assert.Equal(t, "I10", problems[0].Code)
+		require.NotNil(t, problems[0].EncounterID)
+		assert.Equal(t, encounter.ID, *problems[0].EncounterID)
+
+		// Replacing the list keeps the problem list intact.
+		diagnoses, err = problemService.SetAppointmentDiagnoses(completed.ID, doctor.ID, []services.DiagnosisEntry{{Code: "I10", AddToProblemList: true}})
+		require.NoError(t, err)
+		assert.Len(t, diagnoses, 1)
+		stored, err := problemService.GetAppointmentDiagnoses(completed.ID)
+		require.NoError(t, err)
+		assert.Len(t, stored, 1)
+		problems, err = problemService.GetPatientProblems(2, "")
+		require.NoError(t, err)
+		assert.Len(t, problems, 1)
+	})
+}
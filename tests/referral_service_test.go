@@ -0,0 +1,165 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"healthcare-portal/internal/models"
+	"healthcare-portal/internal/repository"
+	"healthcare-portal/internal/services"
+)
+
+func TestReferralService(t *testing.T) {
+	db := setupSchedulingDB(t)
+	createPatients(t, db, 2)
+	gp := &models.User{Email: "gp@example.com", Password: "x", Name: "Dr. General", Role: models.RoleDoctor, IsActive: true}
+	cardio := &models.User{Email: "cardio@example.com", Password: "x", Name: "Dr. Heart", Role: models.RoleDoctor, Specialty: "cardiology", IsActive: true}
+	cardio2 := &models.User{Email: "cardio2@example.com", Password: "x", Name: "Dr. Beat", Role: models.RoleDoctor, Specialty: "cardiology", IsActive: true}
+	derm := &models.User{Email: "derm@example.com", Password: "x", Name: "Dr. Skin", Role: models.RoleDoctor, Specialty: "dermatology", IsActive: true}
+	for _, user := range []*models.User{gp, cardio, cardio2, derm} {
+		require.NoError(t, db.Create(user).Error)
+	}
+	letter := &models.Document{PatientID: 1, Title: "Letter", FileName: "letter.pdf", ContentType: "application/pdf", SHA256: "x", StorageKey: "patients/1/letter"}
+	otherPatients := &models.Document{PatientID: 2, Title: "ID", FileName: "id.png", ContentType: "image/png", SHA256: "y", StorageKey: "patients/2/id"}
+	require.NoError(t, db.Create(letter).Error)
+	require.NoError(t, db.Create(otherPatients).Error)
+
+	referralService := services.NewReferralService(
+		repository.NewReferralRepository(db),
+		repository.NewPatientRepository(db),
+		repository.NewUserRepository(db),
+		repository.NewDocumentRepository(db),
+		newAppointmentService(db),
+	).WithTenant(1)
+
+	t.Run("Validates new referrals", func(t *testing.T) {
+		noTarget := &models.Referral{PatientID: 1, ReferringDoctorID: gp.ID, Reason: "Chest pain"}
+		assert.Error(t, referralService.CreateReferral(noTarget, nil))
+
+		self := &models.Referral{PatientID: 1, ReferringDoctorID: gp.ID, ReceivingDoctorID: &gp.ID, Reason: "Chest pain"}
+		assert.Error(t, referralService.CreateReferral(self, nil))
+
+		wrongDocument := &models.Referral{PatientID: 1, ReferringDoctorID: gp.ID, Specialty: "Cardiology", Reason: "Chest pain"}
+		assert.Error(t, referralService.CreateReferral(wrongDocument, []uint{otherPatients.ID}))
+
+		badUrgency := &models.Referral{PatientID: 1, ReferringDoctorID: gp.ID, Specialty: "Cardiology", Reason: "Chest pain", Urgency: "asap"}
+		assert.Error(t, referralService.CreateReferral(badUrgency, nil))
+	})
+
+	t.Run("Specialty referral is claimed, booked and closed", func(t *testing.T) {
+		referral := &models.Referral{PatientID: 1, ReferringDoctorID: gp.ID, Specialty: " Cardiology ", Reason: "Exertional chest pain"}
+		require.NoError(t, referralService.CreateReferral(referral, []uint{letter.ID, letter.ID}))
+		assert.Equal(t, models.ReferralSent, referral.Status)
+		assert.Equal(t, models.ReferralRoutine, referral.Urgency)
+		assert.Equal(t, "cardiology", referral.Specialty)
+		require.Len(t, referral.Attachments, 1)
+		assert.Equal(t, "Letter", referral.Attachments[0].Document.Title)
+
+		// Both cardiologists see it; the dermatologist does not.
+		for _, doctor := range []*models.User{cardio, cardio2} {
+			inbox, err := referralService.GetIncomingReferrals(doctor.ID, models.ReferralSent)
+			require.NoError(t, err)
+			assert.Len(t, inbox, 1, doctor.Name)
+		}
+		inbox, err := referralService.GetIncomingReferrals(derm.ID, "")
+		require.NoError(t, err)
+		assert.Empty(t, inbox)
+
+		_, err = referralService.AcceptReferral(referral.ID, derm.ID, "")
+		assert.ErrorIs(t, err, services.ErrNotReferralReceiver)
+		_, err = referralService.BookAppointment(referral.ID, &models.Appointment{Date: time.Date(2030, 1, 7, 0, 0, 0, 0, time.UTC), Time: "10:00"})
+		assert.ErrorIs(t, err, services.ErrReferralState)
+
+		accepted, err := referralService.AcceptReferral(referral.ID, cardio.ID, "Will see next week")
+		require.NoError(t, err)
+		assert.Equal(t, models.ReferralAccepted, accepted.Status)
+		require.NotNil(t, accepted.ReceivingDoctor)
+		assert.Equal(t, cardio.ID, accepted.ReceivingDoctor.ID)
+		assert.NotNil(t, accepted.RespondedAt)
+
+		// Once claimed it leaves the other cardiologist's inbox.
+		inbox, err = referralService.GetIncomingReferrals(cardio2.ID, "")
+		require.NoError(t, err)
+		assert.Empty(t, inbox)
+		_, err = referralService.AcceptReferral(referral.ID, cardio2.ID, "")
+		assert.ErrorIs(t, err, services.ErrNotReferralReceiver)
+
+		scheduled, err := referralService.BookAppointment(referral.ID, &models.Appointment{Date: time.Date(2030, 1, 7, 0, 0, 0, 0, time.UTC), Time: "10:00", CreatedBy: gp.ID})
+		require.NoError(t, err)
+		assert.Equal(t, models.ReferralScheduled, scheduled.Status)
+		require.NotNil(t, scheduled.Appointment)
+		assert.Equal(t, cardio.ID, scheduled.Appointment.DoctorID)
+		assert.Equal(t, uint(1), scheduled.Appointment.PatientID)
+		assert.Equal(t, "Referral: Exertional chest pain", scheduled.Appointment.Notes)
+
+		_, err = referralService.CloseReferral(referral.ID, derm.ID, "")
+		assert.ErrorIs(t, err, services.ErrNotReferralParty)
+		closed, err := referralService.CloseReferral(referral.ID, cardio.ID, "Seen, stress test normal")
+		require.NoError(t, err)
+		assert.Equal(t, models.ReferralClosed, closed.Status)
+		assert.NotNil(t, closed.ClosedAt)
+
+		_, err = referralService.CloseReferral(referral.ID, gp.ID, "")
+		assert.ErrorIs(t, err, services.ErrReferralState)
+	})
+
+	t.Run("Booking goes through appointment availability", func(t *testing.T) {
+		referral := &models.Referral{PatientID: 2, ReferringDoctorID: gp.ID, ReceivingDoctorID: &cardio.ID, Reason: "Palpitations", Urgency: models.ReferralUrgent}
+		require.NoError(t, referralService.CreateReferral(referral, nil))
+		assert.Equal(t, "cardiology", referral.Specialty)
+		_, err := referralService.AcceptReferral(referral.ID, cardio.ID, "")
+		require.NoError(t, err)
+
+		// Dr. Heart is already booked at 10:00 on that day.
+		_, err = referralService.BookAppointment(referral.ID, &models.Appointment{Date: time.Date(2030, 1, 7, 0, 0, 0, 0, time.UTC), Time: "10:00"})
+		assert.EqualError(t, err, "doctor is not available at this time")
+		unchanged, err := referralService.GetReferralByID(referral.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.ReferralAccepted, unchanged.Status)
+		assert.Nil(t, unchanged.AppointmentID)
+	})
+
+	t.Run("Declining needs a reason and ends with the sender", func(t *testing.T) {
+		referral := &models.Referral{PatientID: 2, ReferringDoctorID: gp.ID, ReceivingDoctorID: &derm.ID, Reason: "Rash"}
+		require.NoError(t, referralService.CreateReferral(referral, nil))
+
+		_, err := referralService.DeclineReferral(referral.ID, derm.ID, " ")
+		assert.Error(t, err)
+		declined, err := referralService.DeclineReferral(referral.ID, derm.ID, "Manage in primary care")
+		require.NoError(t, err)
+		assert.Equal(t, models.ReferralDeclined, declined.Status)
+		assert.Equal(t, "Manage in primary care", declined.ResponseNote)
+
+		_, err = referralService.AcceptReferral(referral.ID, derm.ID, "")
+		assert.ErrorIs(t, err, services.ErrReferralState)
+
+		outgoing, err := referralService.GetOutgoingReferrals(gp.ID, models.ReferralDeclined)
+		require.NoError(t, err)
+		require.Len(t, outgoing, 1)
+		assert.Equal(t, referral.ID, outgoing[0].ID)
+
+		// Urgent referrals come first in the receiving doctor's inbox.
+		inbox, err := referralService.GetIncomingReferrals(cardio.ID, "")
+		require.NoError(t, err)
+		require.Len(t, inbox, 2)
+		assert.Equal(t, models.ReferralUrgent, inbox[0].Urgency)
+	})
+
+	t.Run("Referrals are scoped to the tenant", func(t *testing.T) {
+		other := services.NewReferralService(
+			repository.NewReferralRepository(db),
+			repository.NewPatientRepository(db),
+			repository.NewUserRepository(db),
+			repository.NewDocumentRepository(db),
+			newAppointmentService(db),
+		).WithTenant(2)
+		_, err := other.GetReferralByID(1)
+		assert.ErrorIs(t, err, services.ErrReferralNotFound)
+		inbox, err := other.GetIncomingReferrals(cardio.ID, "")
+		assert.Error(t, err)
+		assert.Empty(t, inbox)
+	})
+}
@@ -0,0 +1,119 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"healthcare-portal/internal/models"
+	"healthcare-portal/internal/repository"
+	"healthcare-portal/internal/services"
+)
+
+func TestPatientAge(t *testing.T) {
+	patient := &models.Patient{DateOfBirth: date(2010, 6, 15)}
+	assert.Equal(t, 17, patient.AgeOn(date(2028, 6, 14)))
+	assert.True(t, patient.IsMinorOn(date(2028, 6, 14)))
+	assert.Equal(t, 18, patient.AgeOn(date(2028, 6, 15)))
+	assert.False(t, patient.IsMinorOn(date(2028, 6, 15)))
+
+	unknown := &models.Patient{}
+	assert.Equal(t, -1, unknown.AgeOn(date(2028, 6, 15)))
+	assert.False(t, unknown.IsMinorOn(date(2028, 6, 15)))
+}
+
+func TestRelatedPersonService(t *testing.T) {
+	db := setupSchedulingDB(t)
+	patientService := newPatientService(db).WithTenant(1)
+	relatedPersonService := services.NewRelatedPersonService(
+		repository.NewRelatedPersonRepository(db),
+		repository.NewPatientRepository(db),
+	).WithTenant(1)
+
+	tenYearsAgo := time.Now().AddDate(-10, 0, 0)
+	child := &models.Patient{FirstName: "Tim", LastName: "Tiny", Email: "tim@example.com", Phone: "555-0101", DateOfBirth: tenYearsAgo}
+	adult := &models.Patient{FirstName: "Anna", LastName: "Adult", Email: "anna@example.com", Phone: "555-0102", DateOfBirth: date(1980, 1, 1)}
+	var mother, aunt models.RelatedPerson
+
+	t.Run("Minors are registered with a guardian", func(t *testing.T) {
+		assert.ErrorIs(t, patientService.CreatePatient(child), services.ErrGuardianRequired)
+
+		child.RelatedPersons = []models.RelatedPerson{{Name: "Uncle Bob", Relationship: "sibling", MobilePhone: "555-0200"}}
+		assert.ErrorIs(t, patientService.CreatePatient(child), services.ErrGuardianRequired)
+
+		child.RelatedPersons = []models.RelatedPerson{{Name: "Tina Tiny", Relationship: " Legal_Guardian ", MobilePhone: "555-0201", ConsentToShare: true}}
+		require.NoError(t, patientService.CreatePatient(child))
+		require.Len(t, child.RelatedPersons, 1)
+		mother = child.RelatedPersons[0]
+		assert.True(t, mother.IsGuardian)
+		assert.Equal(t, models.RelationshipLegalGuardian, mother.Relationship)
+		assert.Equal(t, uint(1), mother.TenantID)
+
+		require.NoError(t, patientService.CreatePatient(adult))
+	})
+
+	t.Run("Contacts are validated", func(t *testing.T) {
+		noPhone := &models.RelatedPerson{PatientID: adult.ID, Name: "Ned", Relationship: models.RelationshipFriend, Email: "ned@example.com"}
+		assert.Error(t, relatedPersonService.CreateRelatedPerson(noPhone))
+		badRelationship := &models.RelatedPerson{PatientID: adult.ID, Name: "Ned", Relationship: "neighbour", HomePhone: "555-0300"}
+		assert.Error(t, relatedPersonService.CreateRelatedPerson(badRelationship))
+		unknownPatient := &models.RelatedPerson{PatientID: 999, Name: "Ned", Relationship: models.RelationshipFriend, HomePhone: "555-0300"}
+		assert.ErrorIs(t, relatedPersonService.CreateRelatedPerson(unknownPatient), services.ErrPatientNotFound)
+	})
+
+	t.Run("A minor keeps at least one guardian", func(t *testing.T) {
+		aunt = models.RelatedPerson{PatientID: child.ID, Name: "Alice Aunt", Relationship: models.RelationshipOther, WorkPhone: "555-0202", IsGuardian: true, IsEmergencyContact: true}
+		require.NoError(t, relatedPersonService.CreateRelatedPerson(&aunt))
+
+		persons, err := relatedPersonService.GetPatientRelatedPersons(child.ID)
+		require.NoError(t, err)
+		require.Len(t, persons, 2)
+		// Guardians who are also emergency contacts come first.
+		assert.Equal(t, aunt.ID, persons[0].ID)
+
+		require.NoError(t, relatedPersonService.DeleteRelatedPerson(child.ID, mother.ID))
+		assert.ErrorIs(t, relatedPersonService.DeleteRelatedPerson(child.ID, aunt.ID), services.ErrGuardianRequired)
+
+		demoted := aunt
+		demoted.IsGuardian = false
+		assert.ErrorIs(t, relatedPersonService.UpdateRelatedPerson(&demoted), services.ErrGuardianRequired)
+
+		renamed := aunt
+		renamed.Name = "Alice Aunt-Smith"
+		require.NoError(t, relatedPersonService.UpdateRelatedPerson(&renamed))
+		stored, err := relatedPersonService.GetRelatedPersonByID(child.ID, aunt.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "Alice Aunt-Smith", stored.Name)
+		assert.Equal(t, aunt.CreatedAt.Unix(), stored.CreatedAt.Unix())
+
+		_, err = relatedPersonService.GetRelatedPersonByID(adult.ID, aunt.ID)
+		assert.ErrorIs(t, err, services.ErrRelatedPersonNotFound)
+	})
+
+	t.Run("Adults may drop their only guardian", func(t *testing.T) {
+		guardian := &models.RelatedPerson{PatientID: adult.ID, Name: "Court Appointee", Relationship: models.RelationshipLegalGuardian, WorkPhone: "555-0400"}
+		require.NoError(t, relatedPersonService.CreateRelatedPerson(guardian))
+		require.NoError(t, relatedPersonService.DeleteRelatedPerson(adult.ID, guardian.ID))
+	})
+
+	t.Run("Correcting a birth date to a minor needs a guardian", func(t *testing.T) {
+		stored, err := patientService.GetPatientByID(adult.ID)
+		require.NoError(t, err)
+		stored.DateOfBirth = tenYearsAgo
+		assert.ErrorIs(t, patientService.UpdatePatient(stored), services.ErrGuardianRequired)
+
+		parent := &models.RelatedPerson{PatientID: adult.ID, Name: "Paul Parent", Relationship: models.RelationshipParent, MobilePhone: "555-0500", IsGuardian: true}
+		require.NoError(t, relatedPersonService.CreateRelatedPerson(parent))
+		require.NoError(t, patientService.UpdatePatient(stored))
+	})
+
+	t.Run("Contacts are scoped to the tenant", func(t *testing.T) {
+		otherTenant := services.NewRelatedPersonService(repository.NewRelatedPersonRepository(db), repository.NewPatientRepository(db)).WithTenant(2)
+		_, err := otherTenant.GetPatientRelatedPersons(child.ID)
+		assert.ErrorIs(t, err, services.ErrPatientNotFound)
+		_, err = otherTenant.GetRelatedPersonByID(child.ID, aunt.ID)
+		assert.ErrorIs(t, err, services.ErrRelatedPersonNotFound)
+	})
+}
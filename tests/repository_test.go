@@ -15,7 +15,7 @@ func setupTestDB(t *testing.T) *gorm.DB {
     db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
     assert.NoError(t, err)
 
-    err = db.AutoMigrate(&models.User{}, &models.Patient{})
+    err = db.AutoMigrate(&models.User{}, &models.Patient{}, &models.PatientSearchTerm{})
     assert.NoError(t, err)
 
     return db
@@ -23,7 +23,7 @@ func setupTestDB(t *testing.T) *gorm.DB {
 
 func TestUserRepository(t *testing.T) {
     db := setupTestDB(t)
-    userRepo := repository.NewUserRepository(db)
+    userRepo := repository.NewUserRepository(db).WithTenant(1)
 
     t.Run("Create User", func(t *testing.T) {
         user := &models.User{
@@ -51,11 +51,22 @@ func TestUserRepository(t *testing.T) {
         assert.NotNil(t, user)
         assert.Equal(t, uint(1), user.ID)
     })
+
+    t.Run("Repositories without a tenant fail closed", func(t *testing.T) {
+        unscoped := repository.NewUserRepository(db)
+        _, err := unscoped.FindByID(1)
+        assert.ErrorIs(t, err, repository.ErrTenantRequired)
+        assert.ErrorIs(t, unscoped.Create(&models.User{Email: "other@example.com", Password: "x", Name: "Other", Role: models.RoleNurse}), repository.ErrTenantRequired)
+
+        user, err := unscoped.AllTenants().FindByEmail("test@example.com")
+        assert.NoError(t, err)
+        assert.NotNil(t, user)
+    })
 }
 
 func TestPatientRepository(t *testing.T) {
     db := setupTestDB(t)
-    patientRepo := repository.NewPatientRepository(db)
+    patientRepo := repository.NewPatientRepository(db).WithTenant(1)
 
     t.Run("Create Patient", func(t *testing.T) {
         patient := &models.Patient{
@@ -81,9 +92,9 @@ func TestPatientRepository(t *testing.T) {
     })
 
     t.Run("Search Patients", func(t *testing.T) {
-        patients, err := patientRepo.Search("John")
+        results, err := patientRepo.Search(models.PatientSearchQuery{Text: "John"})
         assert.NoError(t, err)
-        assert.Len(t, patients, 1)
-        assert.Equal(t, "John", patients[0].FirstName)
+        assert.Len(t, results.Hits, 1)
+        assert.Equal(t, "John", results.Hits[0].Patient.FirstName)
     })
 }
\ No newline at end of file
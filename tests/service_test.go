@@ -6,7 +6,9 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
 	"healthcare-portal/internal/models"
+	"healthcare-portal/internal/repository"
 	"healthcare-portal/internal/services"
 )
 
@@ -15,6 +17,14 @@ type MockUserRepository struct {
 	mock.Mock
 }
 
+func (m *MockUserRepository) WithTenant(tenantID uint) repository.UserRepository {
+	return m
+}
+
+func (m *MockUserRepository) AllTenants() repository.UserRepository {
+	return m
+}
+
 func (m *MockUserRepository) Create(user *models.User) error {
 	args := m.Called(user)
 	return args.Error(0)
@@ -81,6 +91,7 @@ func TestAuthService(t *testing.T) {
 			Role:     models.RoleDoctor,
 		}
 
+		mockRepo.On("FindByEmail", "new@example.com").Return(nil, gorm.ErrRecordNotFound)
 		mockRepo.On("Create", mock.AnythingOfType("*models.User")).Return(nil)
 
 		err := authService.Register(newUser)
@@ -0,0 +1,121 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"healthcare-portal/internal/models"
+	"healthcare-portal/internal/repository"
+	"healthcare-portal/internal/services"
+)
+
+func TestTenantIsolation(t *testing.T) {
+	db := setupSchedulingDB(t)
+
+	acme := &models.Tenant{Name: "Acme Clinic", Slug: "acme", IsActive: true}
+	globex := &models.Tenant{Name: "Globex Health", Slug: "globex", IsActive: true}
+	require.NoError(t, db.Create(acme).Error)
+	require.NoError(t, db.Create(globex).Error)
+
+	patients := repository.NewPatientRepository(db)
+	acmePatients := patients.WithTenant(acme.ID)
+	globexPatients := patients.WithTenant(globex.ID)
+
+	alice := &models.Patient{FirstName: "Alice", LastName: "Johnson", Email: "alice@example.com", Phone: "555-0101"}
+	bob := &models.Patient{FirstName: "Bob", LastName: "Johnson", Email: "bob@example.com", Phone: "555-0102"}
+	require.NoError(t, acmePatients.Create(alice))
+	require.NoError(t, globexPatients.Create(bob))
+
+	t.Run("Create stamps the tenant", func(t *testing.T) {
+		assert.Equal(t, acme.ID, alice.TenantID)
+		assert.Equal(t, globex.ID, bob.TenantID)
+	})
+
+	t.Run("Email is unique per tenant only", func(t *testing.T) {
+		twin := &models.Patient{FirstName: "Alice", LastName: "Other", Email: "alice@example.com", Phone: "555-0103"}
+		require.NoError(t, globexPatients.Create(twin))
+		require.NoError(t, globexPatients.Delete(twin.ID))
+	})
+
+	t.Run("FindAll", func(t *testing.T) {
+		found, total, err := acmePatients.FindAll(10, 0)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), total)
+		require.Len(t, found, 1)
+		assert.Equal(t, alice.ID, found[0].ID)
+	})
+
+	t.Run("FindByID", func(t *testing.T) {
+		_, err := acmePatients.FindByID(bob.ID)
+		assert.Error(t, err)
+
+		found, err := globexPatients.FindByID(bob.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "Bob", found.FirstName)
+	})
+
+	t.Run("Search", func(t *testing.T) {
+		found, err := acmePatients.Search(models.PatientSearchQuery{Text: "johnson"})
+		require.NoError(t, err)
+		require.Len(t, found.Hits, 1)
+		assert.Equal(t, alice.ID, found.Hits[0].Patient.ID)
+
+		found, err = acmePatients.Search(models.PatientSearchQuery{Text: "bob"})
+		require.NoError(t, err)
+		assert.Empty(t, found.Hits)
+	})
+
+	t.Run("Delete across tenants is a no-op", func(t *testing.T) {
+		require.NoError(t, acmePatients.Delete(bob.ID))
+		_, err := globexPatients.FindByID(bob.ID)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Appointments", func(t *testing.T) {
+		doctor := &models.User{Email: "doc@acme.com", Password: "x", Name: "Dr. Acme", Role: models.RoleDoctor, IsActive: true, TenantID: acme.ID}
+		require.NoError(t, db.Create(doctor).Error)
+
+		appointmentService := newAppointmentService(db)
+		acmeAppointments := appointmentService.WithTenant(acme.ID)
+		globexAppointments := appointmentService.WithTenant(globex.ID)
+
+		date := time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)
+		appointment := &models.Appointment{PatientID: alice.ID, DoctorID: doctor.ID, Date: date, Time: "09:00", Status: models.StatusScheduled}
+		require.NoError(t, acmeAppointments.CreateAppointment(appointment))
+		assert.Equal(t, acme.ID, appointment.TenantID)
+
+		// Another practice can neither see the booking nor book its doctor
+		_, err := globexAppointments.GetAppointmentByID(appointment.ID)
+		assert.Error(t, err)
+		found, err := globexAppointments.GetAppointmentsByDate(date, nil)
+		require.NoError(t, err)
+		assert.Empty(t, found)
+		assert.Error(t, globexAppointments.CreateAppointment(&models.Appointment{
+			PatientID: bob.ID, DoctorID: doctor.ID, Date: date, Time: "10:00", Status: models.StatusScheduled,
+		}))
+
+		// nor book another practice's patient with its own doctor
+		assert.Error(t, acmeAppointments.CreateAppointment(&models.Appointment{
+			PatientID: bob.ID, DoctorID: doctor.ID, Date: date, Time: "11:00", Status: models.StatusScheduled,
+		}))
+
+		assert.EqualError(t, globexAppointments.UpdateAppointmentStatus(appointment.ID, models.StatusCancelled), "appointment not found")
+		stored, err := acmeAppointments.GetAppointmentByID(appointment.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.StatusScheduled, stored.Status)
+	})
+
+	t.Run("Resolve tenant by slug", func(t *testing.T) {
+		tenantService := services.NewTenantService(repository.NewTenantRepository(db))
+
+		tenant, err := tenantService.ResolveTenant("globex")
+		require.NoError(t, err)
+		assert.Equal(t, globex.ID, tenant.ID)
+
+		_, err = tenantService.ResolveTenant("initech")
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,153 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"healthcare-portal/internal/models"
+	"healthcare-portal/internal/repository"
+	"healthcare-portal/internal/services"
+)
+
+func TestVitalsService(t *testing.T) {
+	db := setupSchedulingDB(t)
+	createPatients(t, db, 1)
+	doctor := &models.User{Email: "doc@example.com", Password: "x", Name: "Dr. Who", Role: models.RoleDoctor, IsActive: true}
+	require.NoError(t, db.Create(doctor).Error)
+
+	appointmentRepo := repository.NewAppointmentRepository(db).WithTenant(1)
+	vitalsService := services.NewVitalsService(repository.NewVitalsRepository(db), repository.NewPatientRepository(db), appointmentRepo).WithTenant(1)
+
+	intPtr := func(v int) *int { return &v }
+	floatPtr := func(v float64) *float64 { return &v }
+	day := func(d int) *time.Time {
+		at := time.Date(2024, 3, d, 9, 0, 0, 0, time.UTC)
+		return &at
+	}
+
+	t.Run("Units are converted and BMI calculated", func(t *testing.T) {
+		vitals, err := vitalsService.RecordVitals(1, 0, services.VitalsReading{
+			RecordedAt:      day(1),
+			Temperature:     floatPtr(98.6),
+			TemperatureUnit: "F",
+			Weight:          floatPtr(176),
+			WeightUnit:      "lb",
+			Height:          floatPtr(70),
+			HeightUnit:      "in",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 37.0, *vitals.TemperatureC)
+		assert.Equal(t, 79.8, *vitals.WeightKg)
+		assert.Equal(t, 177.8, *vitals.HeightCm)
+		assert.Equal(t, 25.2, *vitals.BMI)
+		require.Len(t, vitals.Flags, 1)
+		assert.Equal(t, models.VitalBMI, vitals.Flags[0].Measurement)
+		assert.Equal(t, models.FlagHigh, vitals.Flags[0].Level)
+	})
+
+	t.Run("BMI uses the last height on file", func(t *testing.T) {
+		vitals, err := vitalsService.RecordVitals(1, 0, services.VitalsReading{RecordedAt: day(10), Weight: floatPtr(72)})
+		require.NoError(t, err)
+		assert.Nil(t, vitals.HeightCm)
+		require.NotNil(t, vitals.BMI)
+		assert.Equal(t, 22.8, *vitals.BMI)
+	})
+
+	t.Run("Abnormal values are flagged", func(t *testing.T) {
+		vitals, err := vitalsService.RecordVitals(1, 0, services.VitalsReading{
+			RecordedAt:       day(20),
+			SystolicBP:       intPtr(190),
+			DiastolicBP:      intPtr(95),
+			HeartRate:        intPtr(72),
+			OxygenSaturation: intPtr(93),
+		})
+		require.NoError(t, err)
+
+		levels := map[string]models.VitalFlagLevel{}
+		for _, flag := range vitals.Flags {
+			levels[flag.Measurement] = flag.Level
+		}
+		assert.Equal(t, map[string]models.VitalFlagLevel{
+			models.VitalSystolicBP:       models.FlagCriticalHigh,
+			models.VitalDiastolicBP:      models.FlagHigh,
+			models.VitalOxygenSaturation: models.FlagLow,
+		}, levels)
+
+		loaded, err := vitalsService.GetVitalsByID(vitals.ID)
+		require.NoError(t, err)
+		assert.Len(t, loaded.Flags, 3)
+	})
+
+	t.Run("Validation", func(t *testing.T) {
+		_, err := vitalsService.RecordVitals(1, 0, services.VitalsReading{})
+		assert.Error(t, err)
+		_, err = vitalsService.RecordVitals(1, 0, services.VitalsReading{SystolicBP: intPtr(120)})
+		assert.Error(t, err)
+		_, err = vitalsService.RecordVitals(1, 0, services.VitalsReading{HeartRate: intPtr(900)})
+		assert.Error(t, err)
+		_, err = vitalsService.RecordVitals(1, 0, services.VitalsReading{Weight: floatPtr(70), WeightUnit: "stone"})
+		assert.Error(t, err)
+		_, err = vitalsService.RecordVitals(42, 0, services.VitalsReading{HeartRate: intPtr(70)})
+		assert.Error(t, err)
+	})
+
+	t.Run("Time series with date filtering", func(t *testing.T) {
+		points, err := vitalsService.GetVitalsSeries(1, models.VitalWeight, nil, nil)
+		require.NoError(t, err)
+		require.Len(t, points, 2)
+		assert.Equal(t, 79.8, points[0].Value)
+		assert.Equal(t, 72.0, points[1].Value)
+		assert.Equal(t, "kg", points[0].Unit)
+
+		points, err = vitalsService.GetVitalsSeries(1, models.VitalWeight, day(5), nil)
+		require.NoError(t, err)
+		require.Len(t, points, 1)
+
+		all, err := vitalsService.GetPatientVitals(1, day(5), day(15))
+		require.NoError(t, err)
+		assert.Len(t, all, 1)
+
+		_, err = vitalsService.GetVitalsSeries(1, "mood", nil, nil)
+		assert.Error(t, err)
+	})
+
+	t.Run("Recording visit vitals checks the patient in", func(t *testing.T) {
+		appointment := &models.Appointment{PatientID: 1, DoctorID: doctor.ID, Date: *day(25), Time: "09:00", Status: models.StatusScheduled}
+		require.NoError(t, db.Create(appointment).Error)
+
+		vitals, err := vitalsService.RecordVisitVitals(appointment.ID, 0, services.VitalsReading{HeartRate: intPtr(80)})
+		require.NoError(t, err)
+		require.NotNil(t, vitals.AppointmentID)
+		assert.Equal(t, uint(1), vitals.PatientID)
+
+		stored, err := appointmentRepo.FindByID(appointment.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.StatusCheckedIn, stored.Status)
+
+		visitVitals, err := vitalsService.GetAppointmentVitals(appointment.ID)
+		require.NoError(t, err)
+		assert.Len(t, visitVitals, 1)
+
+		cancelled := &models.Appointment{PatientID: 1, DoctorID: doctor.ID, Date: *day(26), Time: "09:00", Status: models.StatusCancelled}
+		require.NoError(t, db.Create(cancelled).Error)
+		_, err = vitalsService.RecordVisitVitals(cancelled.ID, 0, services.VitalsReading{HeartRate: intPtr(80)})
+		assert.Error(t, err)
+	})
+
+	t.Run("Check-in only applies to scheduled appointments", func(t *testing.T) {
+		appointmentService := newAppointmentService(db).WithTenant(1)
+		appointment := &models.Appointment{PatientID: 1, DoctorID: doctor.ID, Date: *day(27), Time: "10:00", Status: models.StatusCompleted}
+		require.NoError(t, db.Create(appointment).Error)
+		_, err := appointmentService.CheckInAppointment(appointment.ID)
+		assert.Error(t, err)
+
+		appointment = &models.Appointment{PatientID: 1, DoctorID: doctor.ID, Date: *day(27), Time: "11:00", Status: models.StatusScheduled}
+		require.NoError(t, db.Create(appointment).Error)
+		checkedIn, err := appointmentService.CheckInAppointment(appointment.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.StatusCheckedIn, checkedIn.Status)
+	})
+}